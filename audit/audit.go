@@ -0,0 +1,27 @@
+// Package audit carries who makes a request through its context, so the services can record
+// the actor of every change in the audit log (see services.recordAudit).
+package audit
+
+import (
+	"context"
+)
+
+// Actor identifies who made a change and from where.
+type Actor struct {
+	UserID    string // Authenticated user; empty for changes made by the system, e.g., seeding
+	IPAddress string
+	RequestID string // See middleware.RequestLogger
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx whose changes are made by actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor of ctx, or the zero Actor (the system) if there is none.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
@@ -0,0 +1,142 @@
+// Package bruteforce detects password guessing: failed logins are counted per client IP and
+// per account over a sliding window, and sources failing too often are locked out for a
+// while. Counting per account catches attacks spread over many IPs, counting per IP those
+// spread over many accounts.
+package bruteforce
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Kinds of sources failed logins are counted for.
+const (
+	KindIP      = "ip"      // Client IP address
+	KindAccount = "account" // Email or phone number logged in with, whether or not it exists
+)
+
+// Policy defines when a source is locked out and for how long.
+type Policy struct {
+	Threshold  int           // Failures within Window that lock the source out; 0 disables lockouts
+	Window     time.Duration // How long failures are remembered
+	Lockout    time.Duration // Lockout on reaching Threshold, doubled with every further failure
+	MaxLockout time.Duration // Longest lockout
+}
+
+// lockout returns how long a source with failures failures is locked out, 0 if it isn't.
+func (p Policy) lockout(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+	lockout := p.Lockout
+	for i := p.Threshold; i < failures && lockout < p.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > p.MaxLockout {
+		lockout = p.MaxLockout
+	}
+	return lockout
+}
+
+// Source is a locked out source, as listed for admins.
+type Source struct {
+	Kind        string    `json:"kind"`         // KindIP or KindAccount
+	Value       string    `json:"value"`        // IP address or account
+	Failures    int       `json:"failures"`     // Failures within the window when it was locked out
+	LockedUntil time.Time `json:"locked_until"` // When logins are accepted again
+}
+
+// Guard applies an IP and an account policy to logins. A nil *Guard lets every login through.
+type Guard struct {
+	store   Store
+	ip      Policy
+	account Policy
+}
+
+// NewGuard returns a Guard keeping its counts in store.
+func NewGuard(store Store, ip, account Policy) *Guard {
+	return &Guard{store: store, ip: ip, account: account}
+}
+
+// Check returns how long a login of account from ip has to wait because either is locked
+// out, 0 if it may proceed.
+func (g *Guard) Check(ctx context.Context, ip, account string) (time.Duration, error) {
+	if g == nil {
+		return 0, nil
+	}
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range g.keys(ip, account) {
+		until, err := g.store.LockedUntil(ctx, key, now)
+		if err != nil {
+			return 0, err
+		}
+		if d := until.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed login of account from ip, locking either out once it reaches the
+// threshold of its policy.
+func (g *Guard) Fail(ctx context.Context, ip, account string) error {
+	if g == nil {
+		return nil
+	}
+	now := time.Now()
+	for _, key := range g.keys(ip, account) {
+		policy := g.policy(key)
+		if policy.Threshold <= 0 {
+			continue
+		}
+		failures, err := g.store.AddFailure(ctx, key, now, policy.Window)
+		if err != nil {
+			return err
+		}
+		if lockout := policy.lockout(failures); lockout > 0 {
+			kind, value, _ := strings.Cut(key, ":")
+			source := Source{Kind: kind, Value: value, Failures: failures, LockedUntil: now.Add(lockout)}
+			if err := g.store.Lock(ctx, key, source); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Succeed forgets the failed logins of account, after it logged in. Those of the IP are kept,
+// as an attacker may own one of the accounts it tries.
+func (g *Guard) Succeed(ctx context.Context, account string) error {
+	if g == nil {
+		return nil
+	}
+	return g.store.ClearFailures(ctx, accountKey(account))
+}
+
+// Throttled lists the sources that are currently locked out.
+func (g *Guard) Throttled(ctx context.Context) ([]Source, error) {
+	if g == nil {
+		return []Source{}, nil
+	}
+	return g.store.Locks(ctx, time.Now())
+}
+
+// keys returns the store keys of the sources of a login.
+func (g *Guard) keys(ip, account string) []string {
+	return []string{KindIP + ":" + ip, accountKey(account)}
+}
+
+// policy returns the policy of the source of key.
+func (g *Guard) policy(key string) Policy {
+	if strings.HasPrefix(key, KindIP+":") {
+		return g.ip
+	}
+	return g.account
+}
+
+// accountKey returns the store key of account; emails are compared case-insensitively.
+func accountKey(account string) string {
+	return KindAccount + ":" + strings.ToLower(account)
+}
@@ -0,0 +1,106 @@
+package bruteforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store in Redis, shared by all instances. The failures of a key are kept in a
+// sorted set scored by time, so the window slides by dropping the lower scores. Lockouts are
+// kept as keys expiring with them, and indexed in a sorted set scored by their end, for Locks.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore returns a RedisStore keeping its keys under prefix.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// AddFailure implements Store.
+func (s *RedisStore) AddFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	failuresKey := s.prefix + "failures:" + key
+	pipe := s.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, failuresKey, "-inf", strconv.FormatInt(now.Add(-window).UnixNano(), 10))
+	pipe.ZAdd(ctx, failuresKey, redis.Z{Score: float64(now.UnixNano()), Member: now.UnixNano()})
+	count := pipe.ZCard(ctx, failuresKey)
+	pipe.Expire(ctx, failuresKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return int(count.Val()), nil
+}
+
+// ClearFailures implements Store.
+func (s *RedisStore) ClearFailures(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+"failures:"+key).Err(); err != nil {
+		return fmt.Errorf("failed to clear login failures: %w", err)
+	}
+	return nil
+}
+
+// Lock implements Store.
+func (s *RedisStore) Lock(ctx context.Context, key string, source Source) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.prefix+"lock:"+key, data, time.Until(source.LockedUntil))
+	pipe.ZAdd(ctx, s.prefix+"locks", redis.Z{Score: float64(source.LockedUntil.Unix()), Member: key})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to lock out %s: %w", key, err)
+	}
+	return nil
+}
+
+// LockedUntil implements Store.
+func (s *RedisStore) LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error) {
+	data, err := s.client.Get(ctx, s.prefix+"lock:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read lockout of %s: %w", key, err)
+	}
+	var source Source
+	if err := json.Unmarshal(data, &source); err != nil || !source.LockedUntil.After(now) {
+		return time.Time{}, err
+	}
+	return source.LockedUntil, nil
+}
+
+// Locks implements Store.
+func (s *RedisStore) Locks(ctx context.Context, now time.Time) ([]Source, error) {
+	locksKey := s.prefix + "locks"
+	if err := s.client.ZRemRangeByScore(ctx, locksKey, "-inf", strconv.FormatInt(now.Unix(), 10)).Err(); err != nil {
+		return nil, fmt.Errorf("failed to drop expired lockouts: %w", err)
+	}
+	keys, err := s.client.ZRevRange(ctx, locksKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lockouts: %w", err)
+	}
+	sources := []Source{}
+	for _, key := range keys {
+		data, err := s.client.Get(ctx, s.prefix+"lock:"+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue // Expired meanwhile
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read lockout of %s: %w", key, err)
+		}
+		var source Source
+		if err := json.Unmarshal(data, &source); err != nil {
+			return nil, fmt.Errorf("failed to decode lockout of %s: %w", key, err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
@@ -0,0 +1,120 @@
+package bruteforce
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store keeps the failures and lockouts of sources, identified by keys such as "ip:10.0.0.1".
+type Store interface {
+	// AddFailure records a failure of key at now and returns the failures of key since now-window.
+	AddFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	// ClearFailures forgets the failures of key.
+	ClearFailures(ctx context.Context, key string) error
+	// Lock locks key out until source.LockedUntil.
+	Lock(ctx context.Context, key string, source Source) error
+	// LockedUntil returns when the lockout of key ends, the zero time if it isn't locked out at now.
+	LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error)
+	// Locks returns the sources locked out at now, the longest locked out first.
+	Locks(ctx context.Context, now time.Time) ([]Source, error)
+}
+
+// MemoryStore is a Store in memory, for a single instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	failures  map[string][]time.Time // Failure times per key, oldest first
+	windows   map[string]time.Duration
+	locks     map[string]Source
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		failures: map[string][]time.Time{},
+		windows:  map[string]time.Duration{},
+		locks:    map[string]Source{},
+	}
+}
+
+// AddFailure implements Store.
+func (s *MemoryStore) AddFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	s.failures[key] = append(since(s.failures[key], now.Add(-window)), now)
+	s.windows[key] = window
+	return len(s.failures[key]), nil
+}
+
+// ClearFailures implements Store.
+func (s *MemoryStore) ClearFailures(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, key)
+	delete(s.windows, key)
+	return nil
+}
+
+// Lock implements Store.
+func (s *MemoryStore) Lock(ctx context.Context, key string, source Source) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks[key] = source
+	return nil
+}
+
+// LockedUntil implements Store.
+func (s *MemoryStore) LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lock, ok := s.locks[key]; ok && lock.LockedUntil.After(now) {
+		return lock.LockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+// Locks implements Store.
+func (s *MemoryStore) Locks(ctx context.Context, now time.Time) ([]Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	sources := make([]Source, 0, len(s.locks))
+	for _, lock := range s.locks {
+		if lock.LockedUntil.After(now) {
+			sources = append(sources, lock)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].LockedUntil.After(sources[j].LockedUntil) })
+	return sources, nil
+}
+
+// sweep drops the expired failures and lockouts, at most once a minute, so sources that
+// stopped trying don't stay in memory.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, times := range s.failures {
+		if times = since(times, now.Add(-s.windows[key])); len(times) > 0 {
+			s.failures[key] = times
+		} else {
+			delete(s.failures, key)
+			delete(s.windows, key)
+		}
+	}
+	for key, lock := range s.locks {
+		if !lock.LockedUntil.After(now) {
+			delete(s.locks, key)
+		}
+	}
+}
+
+// since returns the times after start, of times sorted oldest first.
+func since(times []time.Time, start time.Time) []time.Time {
+	i := sort.Search(len(times), func(i int) bool { return times[i].After(start) })
+	return times[i:]
+}
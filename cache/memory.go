@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory keeps values in the memory of the instance for a TTL. It suits small data that changes
+// rarely but is read on hot paths, such as the roles, where a round trip to Redis would cost as
+// much as the query it saves. Invalidate only reaches the instance it is called on: other
+// instances serve their entries until the TTL ends. A nil *Memory caches nothing.
+type Memory[K comparable, V any] struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	entries    map[K]memoryEntry[V]
+	generation uint64 // Bumped by Invalidate, so loads started before don't store stale values
+	lastSweep  time.Time
+}
+
+type memoryEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// NewMemory returns an empty cache keeping entries for ttl, or nil, which caches nothing, if
+// ttl isn't positive.
+func NewMemory[K comparable, V any](ttl time.Duration) *Memory[K, V] {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Memory[K, V]{ttl: ttl, entries: map[K]memoryEntry[V]{}}
+}
+
+// Fetch returns the value cached under key, or the result of load, which is then cached.
+// Errors of load aren't cached. Concurrent misses of a key may each call load.
+func (m *Memory[K, V]) Fetch(key K, load func() (V, error)) (V, error) {
+	if m == nil {
+		return load()
+	}
+	now := time.Now()
+	m.mu.Lock()
+	if entry, ok := m.entries[key]; ok && now.Before(entry.expires) {
+		m.mu.Unlock()
+		return entry.value, nil
+	}
+	generation := m.generation
+	m.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.generation == generation {
+		m.sweep(now)
+		m.entries[key] = memoryEntry[V]{value: value, expires: now.Add(m.ttl)}
+	}
+	return value, nil
+}
+
+// Invalidate drops all entries.
+func (m *Memory[K, V]) Invalidate() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = map[K]memoryEntry[V]{}
+	m.generation++
+}
+
+// sweep drops the expired entries, at most once per TTL, so keys that are no longer read
+// don't stay in memory.
+func (m *Memory[K, V]) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < m.ttl {
+		return
+	}
+	m.lastSweep = now
+	for key, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// QueryCache keeps the results of read-heavy queries, such as role lists, in Redis for a TTL.
+// Entries belong to a group (e.g., "roles"). Invalidate drops all entries of a group at once by
+// bumping the group's generation, which is part of the entry keys: stale entries are never read
+// again and expire on their own. A nil *QueryCache caches nothing.
+type QueryCache struct {
+	storage *RedisStorage
+	ttl     time.Duration
+}
+
+// NewQueryCache returns a cache that keeps entries in storage for ttl.
+func NewQueryCache(storage *RedisStorage, ttl time.Duration) *QueryCache {
+	return &QueryCache{storage: storage, ttl: ttl}
+}
+
+// Fetch returns the value cached under key in group, or the result of load, which is then
+// cached. Values are gob encoded, so all exported fields survive, including those hidden from
+// JSON. Redis failures are logged and fall back to load: a broken cache only slows requests down.
+func Fetch[T any](ctx context.Context, c *QueryCache, group, key string, load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+	logger := logging.FromContext(ctx)
+
+	redisCtx, cancel := context.WithTimeout(ctx, operationTimeout)
+	entryKey, err := c.entryKey(redisCtx, group, key)
+	var data []byte
+	if err == nil {
+		data, err = c.storage.client.Get(redisCtx, entryKey).Bytes()
+	}
+	cancel()
+
+	switch {
+	case err == nil:
+		var value T
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err == nil {
+			return value, nil
+		}
+		logger.Warn("Error decoding cached entry, reloading it", "group", group, "key", key, "error", err)
+	case !errors.Is(err, redis.Nil):
+		logger.Warn("Error reading from the cache", "group", group, "key", key, "error", err)
+		return load() // Don't cache under a key that may be stale
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		logger.Warn("Error encoding entry for the cache", "group", group, "key", key, "error", err)
+		return value, nil
+	}
+
+	redisCtx, cancel = context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+	if err := c.storage.client.Set(redisCtx, entryKey, buf.Bytes(), c.ttl).Err(); err != nil {
+		logger.Warn("Error writing to the cache", "group", group, "key", key, "error", err)
+	}
+	return value, nil
+}
+
+// Invalidate drops all entries of groups. Failures are logged: the entries are then served
+// until they expire.
+func (c *QueryCache) Invalidate(ctx context.Context, groups ...string) {
+	if c == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+	for _, group := range groups {
+		if err := c.storage.client.Incr(ctx, c.generationKey(group)).Err(); err != nil {
+			logging.FromContext(ctx).Warn("Error invalidating cache group, entries are served until they expire", "group", group, "error", err)
+		}
+	}
+}
+
+// entryKey returns the Redis key of key in the current generation of group.
+func (c *QueryCache) entryKey(ctx context.Context, group, key string) (string, error) {
+	generation, err := c.storage.client.Get(ctx, c.generationKey(group)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	return fmt.Sprintf("%scache:%s:%d:%s", c.storage.prefix, group, generation, key), nil
+}
+
+// generationKey returns the Redis key of the generation counter of group.
+func (c *QueryCache) generationKey(group string) string {
+	return c.storage.prefix + "cache:" + group + ":generation"
+}
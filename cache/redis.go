@@ -0,0 +1,103 @@
+// Package cache provides the shared key-value stores of the application, such as the Redis
+// store used to share rate limit counters between instances, and the query cache of
+// read-heavy lists.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// operationTimeout bounds every Redis round trip, so a stalled Redis slows requests down
+// instead of blocking them.
+const operationTimeout = 2 * time.Second
+
+// RedisStorage is a key-value store in Redis. It implements fiber.Storage, so it can back
+// Fiber middleware such as the rate limiter. All keys are stored under a prefix, so several
+// applications (or environments) can share a Redis database.
+type RedisStorage struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStorage connects to the Redis server at url (e.g., "redis://:password@localhost:6379/0")
+// and stores keys under prefix.
+func NewRedisStorage(url, prefix string) (*RedisStorage, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &RedisStorage{client: client, prefix: prefix}, nil
+}
+
+// Client returns the underlying Redis client.
+func (s *RedisStorage) Client() *redis.Client {
+	return s.client
+}
+
+// Ping checks that the Redis server answers.
+func (s *RedisStorage) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+	return s.client.Ping(ctx).Err()
+}
+
+// Get returns the value of key, or nil if it doesn't exist.
+func (s *RedisStorage) Get(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+	val, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return val, err
+}
+
+// Set stores val under key. A zero exp keeps the key until it is deleted.
+func (s *RedisStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+	return s.client.Set(ctx, s.prefix+key, val, exp).Err()
+}
+
+// Delete removes key.
+func (s *RedisStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// Reset removes all keys under the prefix of the store.
+func (s *RedisStorage) Reset() error {
+	ctx := context.Background()
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := s.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// Close closes the connection to Redis.
+func (s *RedisStorage) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/realtime"
+	"github.com/anpsniper/anpbayu-be/routes"
+	"github.com/anpsniper/anpbayu-be/seeds"
+	"github.com/anpsniper/anpbayu-be/server"
+)
+
+// newRootCommand returns the command line of the binary. Operators can run migrations and
+// seeding on their own, e.g., from a deployment job, rather than on server start. Without a
+// command the server is started, as "serve" does.
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "anpbayu-be",
+		Short:         "Backend API of anpbayu",
+		SilenceUsage:  true, // Errors of the commands aren't usage errors
+		SilenceErrors: true, // Logged by main
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setup()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve()
+		},
+	}
+	root.AddCommand(serveCommand(), migrateCommand(), seedCommand(), seedFakeCommand(), routesCommand(), openAPICommand(), createSuperuserCommand())
+	return root
+}
+
+func serveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Apply pending migrations and start the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve()
+		},
+	}
+}
+
+func migrateCommand() *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back database migrations",
+	}
+	migrate.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatabase(func(db *database.DB) error {
+				return database.MigrateUp(db)
+			})
+		},
+	}, &cobra.Command{
+		Use:   "down [steps]",
+		Short: "Roll back the last applied migrations (1 by default)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps := 1
+			if len(args) > 0 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("steps must be a positive number, got %q", args[0])
+				}
+				steps = n
+			}
+			return withDatabase(func(db *database.DB) error {
+				return database.MigrateDown(db, steps)
+			})
+		},
+	})
+	return migrate
+}
+
+func seedCommand() *cobra.Command {
+	var env, file string
+	cmd := &cobra.Command{
+		Use:   "seed [env]",
+		Short: "Seed the fixtures of env (SEED_ENV by default), and those of --file",
+		Long: `Seed the fixtures of env (SEED_ENV by default) from SEED_DIR or the built-in fixtures,
+then those of --file, a single file with roles, users, posts and products, e.g., to populate a
+demo environment. Records that exist already are left untouched, so seeding can be repeated.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				env = args[0]
+			}
+			if env == "" {
+				env = config.AppConfig.SeedEnv // Loaded once the command runs
+			}
+			return withDatabase(func(db *database.DB) error {
+				if err := runSeed(db, env); err != nil {
+					return err
+				}
+				if file == "" {
+					return nil
+				}
+				slog.Info("Seeding fixture file", "file", file)
+				if err := seeds.RunFile(context.Background(), db, file); err != nil {
+					return fmt.Errorf("failed to seed fixture file %s: %w", file, err)
+				}
+				slog.Info("Fixture file seeded successfully", "file", file)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&env, "env", "", "fixture set to seed, e.g., development or production (default SEED_ENV)")
+	cmd.Flags().StringVar(&file, "file", "", "fixture file (.yaml, .yml or .json) to seed after those of env")
+	return cmd
+}
+
+func seedFakeCommand() *cobra.Command {
+	var opts seeds.FakeOptions
+	cmd := &cobra.Command{
+		Use:   "seed-fake",
+		Short: "Populate the development database with generated users, posts and products",
+		Long: `Populate the database with realistic generated data for load and UI testing: users of
+the "user" role, who all log in with the password "` + seeds.FakePassword + `", posts written by them and
+products. Only runs when APP_ENV is development.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if config.AppConfig.AppEnv != config.EnvDevelopment {
+				return fmt.Errorf("seed-fake only runs in the %s environment, APP_ENV is %s", config.EnvDevelopment, config.AppConfig.AppEnv)
+			}
+			fixtures, err := seeds.Fake(opts)
+			if err != nil {
+				return err
+			}
+			return withDatabase(func(db *database.DB) error {
+				slog.Info("Seeding fake data", "users", opts.Users, "posts", opts.Posts, "products", opts.Products)
+				if err := seeds.Apply(context.Background(), db, fixtures); err != nil {
+					return fmt.Errorf("failed to seed fake data: %w", err)
+				}
+				slog.Info("Fake data seeded successfully")
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&opts.Users, "users", 100, "number of users to generate")
+	cmd.Flags().IntVar(&opts.Posts, "posts", 500, "number of posts to generate")
+	cmd.Flags().IntVar(&opts.Products, "products", 0, "number of products to generate")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 0, "seed of the generator, to generate the same data again (default random)")
+	return cmd
+}
+
+func routesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "List the routes served by the HTTP server with their middleware",
+		Long: `List the routes served by the HTTP server, each with the chain of handlers a request runs
+through, e.g., to audit which routes require a JWT and which roles (HasRole). Middleware
+applied to every route is listed once above the routes.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(func(app *fiber.App) error {
+				printRoutes(app)
+				return nil
+			})
+		},
+	}
+}
+
+func openAPICommand() *cobra.Command {
+	openapi := &cobra.Command{
+		Use:   "openapi",
+		Short: "Work with the OpenAPI description of the API",
+	}
+	var output, format string
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "Write the OpenAPI spec served at /docs/openapi.json, e.g., for client code generation",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = "json"
+				if ext := filepath.Ext(output); ext == ".yaml" || ext == ".yml" {
+					format = "yaml"
+				}
+			}
+			if format != "json" && format != "yaml" {
+				return fmt.Errorf("format must be json or yaml, got %q", format)
+			}
+			return withApp(func(app *fiber.App) error {
+				spec := routes.OpenAPISpec(app)
+				var data []byte
+				var err error
+				if format == "yaml" {
+					data, err = spec.YAML()
+				} else {
+					data, err = json.MarshalIndent(spec, "", "  ")
+					data = append(data, '\n')
+				}
+				if err != nil {
+					return fmt.Errorf("failed to encode spec: %w", err)
+				}
+				if output == "" || output == "-" {
+					_, err = os.Stdout.Write(data)
+					return err
+				}
+				return os.WriteFile(output, data, 0o644)
+			})
+		},
+	}
+	export.Flags().StringVarP(&output, "output", "o", "", "file to write the spec to; stdout if empty or -")
+	export.Flags().StringVarP(&format, "format", "f", "", "json or yaml; derived from the extension of --output by default")
+	openapi.AddCommand(export)
+	return openapi
+}
+
+// withApp runs fn with the app serve runs, for commands describing its routes. Neither the
+// database nor anything else is connected to, apart from Redis when configured: services only
+// query the database while requests are served.
+func withApp(fn func(app *fiber.App) error) error {
+	dialect, err := database.DialectFor(config.AppConfig.DBDialect)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := sql.Open(dialect.DriverName(), config.AppConfig.DBURL) // Connects lazily, so never
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer sqlDB.Close()
+	db := database.NewDB(sqlDB, dialect)
+
+	hub := realtime.NewHub()
+	sched, err := server.NewScheduler(db, hub)
+	if err != nil {
+		return fmt.Errorf("failed to schedule tasks: %w", err)
+	}
+	app, redisStore, err := server.New(db, nil, sched, hub)
+	if err != nil {
+		return err
+	}
+	if redisStore != nil {
+		defer redisStore.Close()
+	}
+	return fn(app)
+}
+
+// withDatabase runs fn with a connection to the database, whose schema is left as it is.
+func withDatabase(fn func(db *database.DB) error) error {
+	db, pool, err := database.ConnectDatabase(&config.AppConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.CloseDatabase(db, pool)
+	return fn(db)
+}
+
+// routeChain is a route with the handlers a request to it runs through, see middleware.Describe.
+type routeChain struct {
+	method, path string
+	handlers     []string
+}
+
+// printRoutes writes the method, path and handler chain of the routes of app to stdout, sorted
+// by path. HEAD routes, which Fiber adds for every GET route, are left out.
+func printRoutes(app *fiber.App) {
+	// Middleware registered for every path before the first route applies to every route. Use
+	// registers middleware for every method, so they are counted in the GET routes, which come
+	// first, and skipped in the routes of the other methods.
+	var global []string
+	globalUses := -1
+	var chains []routeChain
+	for _, stack := range app.Stack() {
+		var uses []*fiber.Route
+		for _, route := range stack {
+			if route.Method == fiber.MethodHead {
+				break
+			}
+			// Fiber doesn't export whether a route was registered with Use
+			if reflect.ValueOf(route).Elem().FieldByName("use").Bool() {
+				uses = append(uses, route)
+				continue
+			}
+			if globalUses < 0 {
+				for globalUses = 0; globalUses < len(uses) && uses[globalUses].Path == "/"; globalUses++ {
+					global = append(global, describeHandlers(uses[globalUses].Handlers)...)
+				}
+			}
+
+			chain := routeChain{method: route.Method, path: route.Path}
+			for _, use := range uses[globalUses:] {
+				if use.Path == "/" || route.Path == use.Path || strings.HasPrefix(route.Path, strings.TrimSuffix(use.Path, "/")+"/") {
+					chain.handlers = append(chain.handlers, describeHandlers(use.Handlers)...)
+				}
+			}
+			chain.handlers = append(chain.handlers, describeHandlers(route.Handlers)...)
+			chains = append(chains, chain)
+		}
+	}
+
+	sort.SliceStable(chains, func(i, j int) bool {
+		if chains[i].path != chains[j].path {
+			return chains[i].path < chains[j].path
+		}
+		return chains[i].method < chains[j].method
+	})
+	fmt.Printf("Every route: %s\n\n", strings.Join(global, " > "))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLERS")
+	for _, chain := range chains {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", chain.method, chain.path, strings.Join(chain.handlers, " > "))
+	}
+	w.Flush()
+}
+
+func describeHandlers(handlers []fiber.Handler) []string {
+	names := make([]string, len(handlers))
+	for i, handler := range handlers {
+		names[i] = middleware.Describe(handler)
+	}
+	return names
+}
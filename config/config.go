@@ -2,77 +2,500 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/mail"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv" // For loading .env files
+
+	"github.com/anpsniper/anpbayu-be/scheduler"
+)
+
+// Supported values of the APP_ENV setting.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Defaults that are only meant for local development; production refuses to start with them.
+// They must match the default tags of Config.
+const (
+	defaultJWTSecret = "supersecretjwtkey"
 )
 
 // Config holds all application-wide configurations.
+// Each setting is read from the environment variable named by its env tag; see loadEnv for
+// the tags. Settings whose default depends on other settings are filled in by LoadConfig.
 type Config struct {
-	AppPort        string
-	FrontendOrigin string
-	AuthEmail      string
-	AuthPassword   string
-	JWTSecret      string
-	DBURL          string // <--- THIS LINE IS CRUCIAL AND MUST BE PRESENT
+	AppEnv         string `env:"APP_ENV" default:"development"` // Deployment environment: "development" (default), "staging" or "production"
+	AppPort        string `env:"APP_PORT" default:"8080"`
+	FrontendOrigin string `env:"FRONTEND_ORIGIN" default:"http://localhost:3000"`
+	JWTSecret      string `env:"JWT_SECRET" default:"supersecretjwtkey"`
+	DBURL          string `env:"DB_URL" required:"production"`                      // <--- THIS LINE IS CRUCIAL AND MUST BE PRESENT
+	DBDialect      string `env:"DB_DIALECT" default:"postgres"`                     // Database dialect: "postgres" (default), "sqlite" or "mysql"
+	DBSchema       string `env:"DB_SCHEMA"`                                         // PostgreSQL schema all tables are created and queried in; the search_path of DB_URL applies when empty
+	UploadDir      string `env:"UPLOAD_DIR" default:"./uploads"`                    // Directory where uploaded files (e.g., post attachments) are stored
+	UploadMaxSize  int64  `env:"UPLOAD_MAX_SIZE_MB" default:"10" min:"1" unit:"MB"` // Maximum accepted size of a single uploaded file, in bytes
+	BodyLimit      int64  `env:"BODY_LIMIT_MB" min:"1" unit:"MB"`                   // Maximum accepted size of a request body, in bytes
+	SiteTitle      string `env:"SITE_TITLE" default:"anpbayu"`                      // Title of the public site, used in RSS/Atom feeds
+	SiteURL        string `env:"SITE_URL"`                                          // Public URL of the site, used to build post links in feeds
+	SiteDesc       string `env:"SITE_DESCRIPTION"`                                  // Short site description, used in RSS feeds
+
+	UploadBlockedExtensions []string `env:"UPLOAD_BLOCKED_EXTENSIONS" default:".exe,.dll,.bat,.cmd,.msi,.scr,.vbs,.ps1,.sh,.jar,.php,.phtml,.jsp,.asp,.aspx,.cgi,.js,.html,.htm,.svg"` // Extensions uploaded file names must not have, whatever their content
+
+	LogFormat              string   `env:"LOG_FORMAT"`                                                                                   // "json" or "console"; defaults to "json" in production and "console" elsewhere
+	AccessLogSamplePercent int      `env:"ACCESS_LOG_SAMPLE_PERCENT" default:"100" min:"0" max:"100"`                                    // Percentage of successful requests written to the access log; 0 logs only server errors
+	AccessLogExclude       []string `env:"ACCESS_LOG_EXCLUDE" default:"/health,/healthz,/readyz,/startupz,/metrics,/metrics/prometheus"` // Paths never written to the access log
+	MetricsToken           string   `env:"METRICS_TOKEN"`                                                                                // Bearer token scrapers must send to /metrics; without one, the metrics are open outside production and not served in production
+
+	CompressionLevel   string `env:"COMPRESSION_LEVEL" default:"default"`               // Response compression: "off", "speed", "default" or "best"
+	CompressionMinSize int    `env:"COMPRESSION_MIN_SIZE_BYTES" default:"1024" min:"0"` // Responses smaller than this are sent uncompressed
+
+	TLSCertFile         string   `env:"TLS_CERT_FILE"`                            // PEM certificate to serve HTTPS with; HTTPS is off when neither this nor TLSAutocertDomains is set
+	TLSKeyFile          string   `env:"TLS_KEY_FILE"`                             // PEM private key of TLSCertFile
+	TLSAutocertDomains  []string `env:"TLS_AUTOCERT_DOMAINS"`                     // Domains to obtain Let's Encrypt certificates for automatically
+	TLSAutocertEmail    string   `env:"TLS_AUTOCERT_EMAIL"`                       // Contact email registered with Let's Encrypt
+	TLSAutocertCacheDir string   `env:"TLS_AUTOCERT_CACHE_DIR" default:"./certs"` // Directory where obtained certificates are kept across restarts
+	TLSHTTPPort         string   `env:"TLS_HTTP_PORT" default:"80"`               // Plain HTTP port answering ACME challenges and redirecting to HTTPS in autocert mode
+
+	RateLimitStore string `env:"RATE_LIMIT_STORE" default:"memory"` // Where rate limit counters are kept: "memory" (per instance) or "redis" (shared by all instances)
+	RedisURL       string `env:"REDIS_URL"`                         // Redis server, e.g., "redis://:password@localhost:6379/0"; required by the Redis rate limit store and cache
+
+	// Failed logins are counted per client IP and per account (email or phone number) in the
+	// rate limit store. A source reaching its threshold within the window is locked out, for a
+	// period doubling with every further failure. A zero threshold disables the lockouts.
+	BruteForceWindow           time.Duration `env:"BRUTE_FORCE_WINDOW_MINUTES" default:"15" min:"1" unit:"m"`      // How long failed logins are remembered
+	BruteForceIPThreshold      int           `env:"BRUTE_FORCE_IP_THRESHOLD" default:"20" min:"0"`                 // Failed logins of a client IP, whatever the account, before it is locked out
+	BruteForceAccountThreshold int           `env:"BRUTE_FORCE_ACCOUNT_THRESHOLD" default:"5" min:"0"`             // Failed logins to an account, whatever the IP, before it is locked out
+	BruteForceLockout          time.Duration `env:"BRUTE_FORCE_LOCKOUT_SECONDS" default:"30" min:"1" unit:"s"`     // First lockout
+	BruteForceMaxLockout       time.Duration `env:"BRUTE_FORCE_MAX_LOCKOUT_MINUTES" default:"60" min:"1" unit:"m"` // Longest lockout
+
+	CacheStore string        `env:"CACHE_STORE" default:"none"`                      // Cache of read-heavy lists, such as roles and users: "none" or "redis" (shared by all instances)
+	CacheTTL   time.Duration `env:"CACHE_TTL_SECONDS" default:"60" min:"1" unit:"s"` // How long cached lists are served before they are read again, unless invalidated by a change
+
+	RoleCacheTTL time.Duration `env:"ROLE_CACHE_TTL_SECONDS" default:"30" min:"0" unit:"s"` // How long each instance keeps the roles in memory; changes through another instance show after it. 0 disables the role cache
+
+	TenantBaseDomain string `env:"TENANT_BASE_DOMAIN"` // Domain whose subdomains select a tenant (e.g., "example.com"); empty disables subdomain resolution
+
+	TokenDelivery      string   `env:"TOKEN_DELIVERY" default:"body"`           // How login hands out the JWT: "body" (JSON response) or "cookie" (a Secure, httpOnly cookie out of reach of scripts)
+	JWTExtraClaims     []string `env:"JWT_EXTRA_CLAIMS"`                        // name=value claims added to every token and required of every token, e.g., "iss=anpbayu,aud=backoffice"
+	AuthCookieName     string   `env:"AUTH_COOKIE_NAME" default:"access_token"` // Name of the cookie of TOKEN_DELIVERY=cookie
+	AuthCookieDomain   string   `env:"AUTH_COOKIE_DOMAIN"`                      // Domain of the cookie, e.g., "example.com" to share it with the frontend's subdomain; empty limits it to the API host
+	AuthCookieSameSite string   `env:"AUTH_COOKIE_SAMESITE" default:"Lax"`      // SameSite attribute of the cookie: "Strict", "Lax" or "None" (frontends on another site, requires AUTH_COOKIE_CSRF)
+	AuthCookieCSRF     bool     `env:"AUTH_COOKIE_CSRF" default:"true"`         // Requests authenticated with the cookie must send its CSRF token in X-CSRF-Token to change anything
+
+	TrustedProxies string `env:"TRUSTED_PROXIES"` // Networks of the reverse proxies whose X-Forwarded-For header is trusted (see ParseNetworks); empty uses the address of the connection
+
+	SentryDSN     string `env:"SENTRY_DSN"`     // DSN of the Sentry (or GlitchTip) project panics and server errors are reported to; empty disables reporting
+	SentryRelease string `env:"SENTRY_RELEASE"` // Release reported errors are attributed to, e.g., the deployed git commit
+
+	PaymentCurrency     string `env:"PAYMENT_CURRENCY" default:"idr"`      // ISO 4217 currency orders are charged in
+	PaymentReturnURL    string `env:"PAYMENT_RETURN_URL"`                  // Frontend URL customers return to after checkout; the order ID is appended
+	StripeSecretKey     string `env:"STRIPE_SECRET_KEY"`                   // Stripe API secret key; Stripe is disabled when empty
+	StripeWebhookSecret string `env:"STRIPE_WEBHOOK_SECRET"`               // Stripe webhook endpoint signing secret
+	MidtransServerKey   string `env:"MIDTRANS_SERVER_KEY"`                 // Midtrans server key; Midtrans is disabled when empty
+	MidtransProduction  bool   `env:"MIDTRANS_PRODUCTION" default:"false"` // Use the Midtrans production API instead of the sandbox
+
+	ReservationTTL time.Duration `env:"RESERVATION_TTL_MINUTES" default:"30" min:"1" unit:"m"` // How long an unpaid order holds its stock before it is released
+
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL_HOURS" default:"24" min:"1" unit:"h"` // How long responses to requests with an Idempotency-Key are replayed
+
+	StorageDriver     string        `env:"STORAGE_DRIVER" default:"local"`                        // Where uploaded files are kept: "local" (UPLOAD_DIR) or "s3" (an S3 or MinIO bucket)
+	StorageURLTTL     time.Duration `env:"STORAGE_URL_TTL_MINUTES" default:"15" min:"1" unit:"m"` // How long presigned download URLs of uploaded files stay valid at least
+	S3Endpoint        string        `env:"S3_ENDPOINT"`                                           // S3 API endpoint, e.g., "http://localhost:9000" for MinIO; AWS S3 of S3_REGION when empty
+	S3Region          string        `env:"S3_REGION" default:"us-east-1"`                         // Region of S3_BUCKET
+	S3Bucket          string        `env:"S3_BUCKET"`                                             // Bucket uploaded files are stored in
+	S3AccessKeyID     string        `env:"S3_ACCESS_KEY_ID"`                                      // Credentials S3 requests are signed with
+	S3SecretAccessKey string        `env:"S3_SECRET_ACCESS_KEY"`                                  // Secret of S3_ACCESS_KEY_ID
+	S3PathStyle       bool          `env:"S3_PATH_STYLE" default:"false"`                         // Address the bucket in the URL path instead of the host name, as MinIO needs
+
+	WebhookTimeout              time.Duration `env:"WEBHOOK_TIMEOUT_SECONDS" default:"10" min:"1" max:"60" unit:"s"` // How long an outgoing webhook waits for the endpoint to answer
+	WebhookMaxAttempts          int           `env:"WEBHOOK_MAX_ATTEMPTS" default:"8" min:"1"`                       // Attempts to deliver an event, with exponential backoff between them, before it is marked failed
+	WebhookAllowPrivateNetworks bool          `env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" default:"false"`                 // Let webhook endpoints be on loopback or private addresses, for local receivers; refused in production
+
+	SMTPHost     string `env:"SMTP_HOST"`                              // SMTP server notifications are emailed through; email notifications are disabled when empty
+	SMTPPort     string `env:"SMTP_PORT" default:"587"`                // SMTP server port; STARTTLS is used when the server offers it
+	SMTPUsername string `env:"SMTP_USERNAME"`                          // SMTP credentials; no authentication when empty
+	SMTPPassword string `env:"SMTP_PASSWORD"`                          // Password of SMTP_USERNAME
+	MailFrom     string `env:"MAIL_FROM" default:"no-reply@localhost"` // Sender address of emails, e.g., "anpbayu <no-reply@example.com>"
+
+	TwilioAccountSID string        `env:"TWILIO_ACCOUNT_SID"`                                    // Twilio account SMS login codes are sent through; SMS login is disabled when empty
+	TwilioAuthToken  string        `env:"TWILIO_AUTH_TOKEN"`                                     // Auth token of TWILIO_ACCOUNT_SID
+	TwilioFrom       string        `env:"TWILIO_FROM"`                                           // Sender phone number in E.164 format, e.g., "+15005550006"
+	OTPTTL           time.Duration `env:"OTP_TTL_MINUTES" default:"5" min:"1" max:"60" unit:"m"` // How long an SMS login code stays valid
+	OTPMaxAttempts   int           `env:"OTP_MAX_ATTEMPTS" default:"5" min:"1"`                  // Wrong guesses after which an SMS login code is invalidated
+
+	// Users are alerted of logins from a device or country they didn't log in from before
+	GeoIPDBPath    string `env:"GEOIP_DB_PATH"`    // MaxMind database (e.g., GeoLite2-Country.mmdb) the countries of logins are looked up in; only devices are compared when empty
+	LoginRevokeURL string `env:"LOGIN_REVOKE_URL"` // Frontend page of the "this wasn't me" link of the alerts, which posts its token to /login/revoke; defaults to FRONTEND_ORIGIN + "/login/revoke"
+
+	// Users are invited to organizations by email, with a link to the frontend
+	InvitationURL string        `env:"INVITATION_URL"`                                   // Frontend page of the invitation links, which posts their token to /api/organizations/invitations/accept; defaults to FRONTEND_ORIGIN + "/invitations/accept"
+	InvitationTTL time.Duration `env:"INVITATION_TTL_DAYS" default:"7" min:"1" unit:"d"` // How long an invitation to an organization can be accepted
+
+	// Schedules of the recurring tasks (see scheduler): standard cron expressions such as
+	// "30 3 * * *", descriptors such as "@hourly", or "off" to only run them on demand
+	ScheduleSessionCleanup string        `env:"SCHEDULE_SESSION_CLEANUP" default:"@hourly"`       // Deletes expired sessions and tokens
+	ScheduleLogRetention   string        `env:"SCHEDULE_LOG_RETENTION" default:"30 3 * * *"`      // Purges login logs, failed logins, audit logs and settled webhook deliveries older than LOG_RETENTION_DAYS
+	SchedulePostPublishing string        `env:"SCHEDULE_POST_PUBLISHING" default:"* * * * *"`     // Notifies authors when their scheduled posts go live
+	ScheduleLowStockCheck  string        `env:"SCHEDULE_LOW_STOCK_CHECK" default:"0 8 * * *"`     // Sends admins a summary of the products running low
+	LogRetention           time.Duration `env:"LOG_RETENTION_DAYS" default:"90" min:"1" unit:"d"` // How long login logs, failed logins, audit logs and webhook delivery history are kept
+	LogRetentionMode       string        `env:"LOG_RETENTION_MODE" default:"delete"`              // "delete" old log entries, or "anonymize" old audit log entries (keeping the changes, not who made them) and delete the others
+
+	DBStatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT_SECONDS" default:"15" min:"0" unit:"s"` // Server-side limit for a single SQL statement; 0 disables it
+	DBQueryTimeout     time.Duration `env:"DB_QUERY_TIMEOUT_SECONDS" default:"30" min:"0" unit:"s"`     // Deadline for all database work of a single request; 0 disables it
+	DBConnectAttempts  int           `env:"DB_CONNECT_ATTEMPTS" default:"8" min:"1"`                    // Connection attempts at startup, with exponential backoff between them
+	DBBreakerThreshold int           `env:"DB_BREAKER_THRESHOLD" default:"5" min:"0"`                   // Consecutive connection failures that make requests fail fast with 503; 0 disables it
+	DBBreakerCooldown  time.Duration `env:"DB_BREAKER_COOLDOWN_SECONDS" default:"10" min:"1" unit:"s"`  // Time between trial requests while the database is considered down
+
+	BcryptCost      int `env:"BCRYPT_COST" default:"10" min:"4" max:"31"` // bcrypt cost of password hashes; higher is slower to hash and to crack
+	DefaultPageSize int `env:"PAGE_SIZE_DEFAULT" default:"10" min:"1"`    // Page size of list endpoints when the request doesn't send a limit
+	MaxPageSize     int `env:"PAGE_SIZE_MAX" default:"100" min:"1"`       // Largest page size list endpoints accept
+
+	SeedOnStartup bool   `env:"SEED_ON_STARTUP"` // Seed the fixtures of SeedEnv every time the server starts; seeding is idempotent. On by default in development only
+	SeedEnv       string `env:"SEED_ENV"`        // Fixture set to seed (e.g., "development", "production"); APP_ENV by default
+	SeedDir       string `env:"SEED_DIR"`        // Directory with one fixture subdirectory per environment; empty uses the built-in fixtures
+
+	DocsEnabled bool `env:"DOCS_ENABLED" default:"true"` // Serve the OpenAPI spec and Swagger UI at /docs
 }
 
 // AppConfig is a global instance of the Config struct.
 // It will hold the loaded configuration values.
 var AppConfig Config
 
+// IsProduction reports whether the application runs in the production environment.
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == EnvProduction
+}
+
 // LoadConfig reads configuration from environment variables or .env file.
-// It should be called once at the start of the application.
+// It should be called once at the start of the application. Missing and malformed settings
+// are collected and returned together, followed by the checks of Validate.
 func LoadConfig() error {
-	// Attempt to load .env file.
-	// If it fails, log a message but continue, as variables might be set directly in the environment.
-	err := godotenv.Load()
+	// Load the .env files of the environment.
+	// If there are none, continue, as variables might be set directly in the environment.
+	if err := loadEnvFiles(); err != nil {
+		return err
+	}
+
+	var cfg Config
+	if err := loadEnv(&cfg, os.Getenv("APP_ENV")); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	// FRONTEND_ORIGIN lists the origins allowed by CORS (see ParseOrigins); the first one
+	// without a wildcard is where the frontend lives and is used to build links to it.
+	if origins, err := ParseOrigins(cfg.FrontendOrigin); err == nil { // Invalid lists are reported by loadRuntime
+		cfg.FrontendOrigin = origins[0]
+		for _, origin := range origins {
+			if !strings.Contains(origin, "*") {
+				cfg.FrontendOrigin = origin
+				break
+			}
+		}
+	}
+
+	// Provide a default DB_URL (a DSN in the format of the dialect's driver) for local development.
+	// Production must name its database, see the required tag of DBURL.
+	if cfg.DBURL == "" && !cfg.IsProduction() {
+		switch cfg.DBDialect {
+		case "sqlite":
+			cfg.DBURL = "file:anpbayu.db"
+		case "mysql":
+			cfg.DBURL = "root:password@tcp(localhost:3306)/mydatabase"
+		default:
+			cfg.DBURL = "host=localhost user=postgres password=password dbname=mydatabase port=5432 sslmode=disable"
+		}
+		slog.Info("DB_URL not set, using the local development database", "dialect", cfg.DBDialect)
+	}
+
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = "console" // Readable in a terminal
+		if cfg.IsProduction() {
+			cfg.LogFormat = "json" // For log aggregation
+		}
+	}
+	if cfg.BodyLimit == 0 {
+		cfg.BodyLimit = cfg.UploadMaxSize + 1<<20 // Leaves headroom above the upload limit for multipart boundaries and other form fields
+	}
+	if cfg.SiteURL == "" {
+		cfg.SiteURL = cfg.FrontendOrigin // Posts are usually rendered by the frontend
+	}
+	if cfg.SiteDesc == "" {
+		cfg.SiteDesc = "Latest posts from " + cfg.SiteTitle
+	}
+	if cfg.PaymentReturnURL == "" {
+		cfg.PaymentReturnURL = cfg.FrontendOrigin + "/orders" // Frontend order page
+	}
+	if cfg.LoginRevokeURL == "" {
+		cfg.LoginRevokeURL = cfg.FrontendOrigin + "/login/revoke"
+	}
+	if cfg.InvitationURL == "" {
+		cfg.InvitationURL = cfg.FrontendOrigin + "/invitations/accept"
+	}
+
+	// Seeding on every start suits local development; other environments opt in with
+	// SEED_ON_STARTUP, and seed their own fixture set unless SEED_ENV names another one.
+	if strings.TrimSpace(os.Getenv("SEED_ON_STARTUP")) == "" {
+		cfg.SeedOnStartup = cfg.AppEnv == EnvDevelopment
+	}
+	if cfg.SeedEnv == "" {
+		cfg.SeedEnv = cfg.AppEnv
+	}
+
+	if cfg.TenantBaseDomain == "" {
+		slog.Info("TENANT_BASE_DOMAIN not set, tenants are only resolved from JWTs")
+	}
+	if cfg.MetricsToken == "" && cfg.IsProduction() {
+		slog.Info("METRICS_TOKEN not set, /metrics is not served")
+	}
+	if cfg.StripeSecretKey == "" {
+		slog.Info("STRIPE_SECRET_KEY not set, Stripe payments are disabled")
+	}
+	if cfg.MidtransServerKey == "" {
+		slog.Info("MIDTRANS_SERVER_KEY not set, Midtrans payments are disabled")
+	}
+	if cfg.SMTPHost == "" {
+		slog.Info("SMTP_HOST not set, email notifications are disabled")
+	}
+	if cfg.TwilioAccountSID == "" {
+		slog.Info("TWILIO_ACCOUNT_SID not set, SMS login codes are disabled")
+	}
+	if cfg.GeoIPDBPath == "" {
+		slog.Info("GEOIP_DB_PATH not set, login alerts only consider devices")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	runtimeSettings, err := loadRuntime(cfg.AppEnv)
 	if err != nil {
-		log.Println("No .env file found or error loading .env file, assuming environment variables are set externally.")
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	AppConfig = cfg
+	current.Store(runtimeSettings)
+
+	slog.Info("Configuration loaded successfully")
+	return nil
+}
+
+// envFileVars records the variables loadEnvFiles set from the files, so a reload may replace
+// them while variables of the real environment stay untouched.
+var envFileVars = map[string]bool{}
+
+// loadEnvFiles layers .env and .env.<APP_ENV> (e.g., .env.production) into the process
+// environment, with later files overriding earlier ones. APP_ENV is taken from the real
+// environment or, failing that, from .env. Variables set in the real environment always win
+// over the files, so deployments can still override single settings. It is run again by Reload.
+func loadEnvFiles() error {
+	files := []string{".env"}
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		base, err := readEnvFile(".env")
+		if err != nil {
+			return err
+		}
+		env = base["APP_ENV"]
+	}
+	if env != "" {
+		files = append(files, ".env."+env)
+	}
+
+	merged := map[string]string{}
+	loaded := []string{}
+	for _, file := range files {
+		vars, err := readEnvFile(file)
+		if err != nil {
+			return err
+		}
+		if vars == nil {
+			continue
+		}
+		for key, value := range vars {
+			merged[key] = value
+		}
+		loaded = append(loaded, file)
+	}
+	if len(loaded) == 0 && len(envFileVars) == 0 {
+		slog.Info("No .env files found, assuming environment variables are set externally")
+		return nil
+	}
+
+	for key, value := range merged {
+		if _, set := os.LookupEnv(key); set && !envFileVars[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s from env files: %w", key, err)
+		}
+		envFileVars[key] = true
+	}
+	// On reload, variables removed from the files fall back to their defaults
+	for key := range envFileVars {
+		if _, ok := merged[key]; !ok {
+			os.Unsetenv(key)
+			delete(envFileVars, key)
+		}
+	}
+	slog.Info("Loaded environment files", "files", loaded)
+	return nil
+}
+
+// readEnvFile parses a .env file. A missing file yields no variables.
+func readEnvFile(name string) (map[string]string, error) {
+	vars, err := godotenv.Read(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return vars, nil
+}
+
+// Validate checks the configuration and returns every problem found at once, so a broken
+// deployment can be fixed in one go. It covers the rules that span several settings or that
+// the tags of Config can't express; in production it refuses the development defaults of
+// secrets and credentials.
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.AppEnv {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		errs = append(errs, fmt.Errorf("APP_ENV must be %q, %q or %q, got %q", EnvDevelopment, EnvStaging, EnvProduction, c.AppEnv))
+	}
+
+	switch c.DBDialect {
+	case "postgres", "sqlite", "mysql":
+	default:
+		errs = append(errs, fmt.Errorf("DB_DIALECT must be \"postgres\", \"sqlite\" or \"mysql\", got %q", c.DBDialect))
+	}
+
+	if c.DefaultPageSize > c.MaxPageSize {
+		errs = append(errs, fmt.Errorf("PAGE_SIZE_DEFAULT (%d) must not exceed PAGE_SIZE_MAX (%d)", c.DefaultPageSize, c.MaxPageSize))
+	}
+	if c.BodyLimit < c.UploadMaxSize {
+		errs = append(errs, fmt.Errorf("BODY_LIMIT_MB (%d bytes) must not be below UPLOAD_MAX_SIZE_MB (%d bytes)", c.BodyLimit, c.UploadMaxSize))
 	}
 
-	// Populate the AppConfig struct from environment variables.
-	// Provide default values if environment variables are not set.
+	if c.LogFormat != "json" && c.LogFormat != "console" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be \"json\" or \"console\", got %q", c.LogFormat))
+	}
 
-	AppConfig.AppPort = os.Getenv("APP_PORT")
-	if AppConfig.AppPort == "" {
-		AppConfig.AppPort = "8080" // Default port
-		log.Printf("APP_PORT not set, defaulting to %s", AppConfig.AppPort)
+	switch c.CompressionLevel {
+	case "off", "speed", "default", "best":
+	default:
+		errs = append(errs, fmt.Errorf("COMPRESSION_LEVEL must be \"off\", \"speed\", \"default\" or \"best\", got %q", c.CompressionLevel))
 	}
 
-	AppConfig.FrontendOrigin = os.Getenv("FRONTEND_ORIGIN")
-	if AppConfig.FrontendOrigin == "" {
-		AppConfig.FrontendOrigin = "http://localhost:3000" // Default frontend origin for CORS
-		log.Printf("FRONTEND_ORIGIN not set, defaulting to %s", AppConfig.FrontendOrigin)
+	switch c.TokenDelivery {
+	case "body", "cookie":
+	default:
+		errs = append(errs, fmt.Errorf("TOKEN_DELIVERY must be \"body\" or \"cookie\", got %q", c.TokenDelivery))
+	}
+	switch c.AuthCookieSameSite {
+	case "Strict", "Lax", "None":
+	default:
+		errs = append(errs, fmt.Errorf("AUTH_COOKIE_SAMESITE must be \"Strict\", \"Lax\" or \"None\", got %q", c.AuthCookieSameSite))
+	}
+	if c.AuthCookieSameSite == "None" && !c.AuthCookieCSRF {
+		errs = append(errs, errors.New("AUTH_COOKIE_SAMESITE can only be \"None\" with AUTH_COOKIE_CSRF on, as browsers then send the cookie with the requests of any site"))
 	}
 
-	AppConfig.AuthEmail = os.Getenv("AUTH_EMAIL")
-	if AppConfig.AuthEmail == "" {
-		AppConfig.AuthEmail = "user@example.com" // Default authentication email for seeding
-		log.Printf("AUTH_EMAIL not set, defaulting to %s", AppConfig.AuthEmail)
+	if _, err := ParseNetworks(c.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %w", err))
 	}
 
-	AppConfig.AuthPassword = os.Getenv("AUTH_PASSWORD")
-	if AppConfig.AuthPassword == "" {
-		AppConfig.AuthPassword = "password123" // Default authentication password for seeding
-		log.Printf("AUTH_PASSWORD not set, defaulting to default password")
+	switch c.RateLimitStore {
+	case "memory":
+	case "redis":
+		if c.RedisURL == "" {
+			errs = append(errs, errors.New("REDIS_URL must be set when RATE_LIMIT_STORE is \"redis\""))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_STORE must be \"memory\" or \"redis\", got %q", c.RateLimitStore))
 	}
 
-	AppConfig.JWTSecret = os.Getenv("JWT_SECRET")
-	if AppConfig.JWTSecret == "" {
-		AppConfig.JWTSecret = "supersecretjwtkey" // Default JWT secret
-		log.Printf("JWT_SECRET not set, defaulting to default secret")
+	switch c.CacheStore {
+	case "none":
+	case "redis":
+		if c.RedisURL == "" {
+			errs = append(errs, errors.New("REDIS_URL must be set when CACHE_STORE is \"redis\""))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("CACHE_STORE must be \"none\" or \"redis\", got %q", c.CacheStore))
 	}
 
-	// Load database URL
-	AppConfig.DBURL = os.Getenv("DB_URL")
-	if AppConfig.DBURL == "" {
-		// Provide a default DB_URL for local development if not set
-		AppConfig.DBURL = "host=localhost user=postgres password=password dbname=mydatabase port=5432 sslmode=disable"
-		log.Printf("DB_URL not set, defaulting to: %s", AppConfig.DBURL)
+	switch c.StorageDriver {
+	case "local":
+	case "s3":
+		if c.S3Bucket == "" || c.S3AccessKeyID == "" || c.S3SecretAccessKey == "" {
+			errs = append(errs, errors.New("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set when STORAGE_DRIVER is \"s3\""))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER must be \"local\" or \"s3\", got %q", c.StorageDriver))
 	}
 
-	log.Println("Configuration loaded successfully.")
+	if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+		errs = append(errs, fmt.Errorf("MAIL_FROM must be an email address, got %q", c.MailFrom))
+	}
+
+	if c.TwilioAccountSID != "" && (c.TwilioAuthToken == "" || c.TwilioFrom == "") {
+		errs = append(errs, errors.New("TWILIO_AUTH_TOKEN and TWILIO_FROM must be set when TWILIO_ACCOUNT_SID is"))
+	}
+
+	switch c.LogRetentionMode {
+	case "delete", "anonymize":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_RETENTION_MODE must be \"delete\" or \"anonymize\", got %q", c.LogRetentionMode))
+	}
+
+	for _, schedule := range []struct{ name, value string }{
+		{"SCHEDULE_SESSION_CLEANUP", c.ScheduleSessionCleanup},
+		{"SCHEDULE_LOG_RETENTION", c.ScheduleLogRetention},
+		{"SCHEDULE_POST_PUBLISHING", c.SchedulePostPublishing},
+		{"SCHEDULE_LOW_STOCK_CHECK", c.ScheduleLowStockCheck},
+	} {
+		if err := scheduler.Validate(schedule.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a cron expression or \"off\", got %q: %w", schedule.name, schedule.value, err))
+		}
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if c.TLSCertFile != "" && len(c.TLSAutocertDomains) > 0 {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS are mutually exclusive"))
+	}
+
+	for _, claim := range c.JWTExtraClaims {
+		if name, _, ok := strings.Cut(claim, "="); !ok || strings.TrimSpace(name) == "" {
+			errs = append(errs, fmt.Errorf("JWT_EXTRA_CLAIMS must be name=value pairs, got %q", claim))
+		}
+	}
+
+	if c.IsProduction() {
+		if c.JWTSecret == defaultJWTSecret {
+			errs = append(errs, errors.New("JWT_SECRET must be set to a non-default value in production"))
+		}
+		if c.WebhookAllowPrivateNetworks {
+			errs = append(errs, errors.New("WEBHOOK_ALLOW_PRIVATE_NETWORKS must not be set in production"))
+		}
+		if c.SeedOnStartup && c.SeedEnv == EnvDevelopment {
+			errs = append(errs, errors.New("SEED_ENV must not be \"development\" in production when SEED_ON_STARTUP is on: its accounts have known passwords"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
 	return nil
 }
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadEnv populates the struct dst points to from environment variables, as declared by the
+// tags of its fields:
+//
+//	env:"NAME"                 the variable to read; fields without it are left alone
+//	default:"value"            used when the variable is unset or empty
+//	required:"true"            the variable must be set; the default is not used
+//	required:"production,..."  the variable must be set when running in one of these APP_ENVs
+//	unit:"s", unit:"m", ...    for durations, the unit of plain numbers (e.g., "30" for 30 seconds)
+//	unit:"MB"                  for integers, the value is given in megabytes and stored in bytes
+//	min:"n", max:"n"           bounds of numbers, in the unit they are given in
+//
+// Supported field types are string, bool, int, int64, time.Duration and []string
+// (comma-separated, blanks dropped). Every missing or invalid variable is reported, joined
+// into one error, so a broken deployment can be fixed in one go.
+func loadEnv(dst any, appEnv string) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	var errs []error
+	var defaulted []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		raw := strings.TrimSpace(os.Getenv(name))
+		if raw == "" {
+			if isRequired(field.Tag.Get("required"), appEnv) {
+				errs = append(errs, fmt.Errorf("%s is required", name))
+				continue
+			}
+			def, ok := field.Tag.Lookup("default")
+			if !ok {
+				v.Field(i).Set(reflect.Zero(field.Type))
+				continue
+			}
+			raw = def
+			defaulted = append(defaulted, name)
+		}
+
+		if err := setField(v.Field(i), field.Tag, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if len(defaulted) > 0 {
+		slog.Info("Settings not set, using defaults", "settings", defaulted)
+	}
+	return errors.Join(errs...)
+}
+
+// isRequired interprets the required tag for the environment appEnv.
+func isRequired(tag, appEnv string) bool {
+	if tag == "" {
+		return false
+	}
+	if b, err := strconv.ParseBool(tag); err == nil {
+		return b
+	}
+	for _, env := range strings.Split(tag, ",") {
+		if strings.TrimSpace(env) == appEnv {
+			return true
+		}
+	}
+	return false
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// durationUnits are the values of the unit tag of durations.
+var durationUnits = map[string]time.Duration{"ms": time.Millisecond, "s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}
+
+// setField parses raw into field according to its type and tags.
+func setField(field reflect.Value, tag reflect.StructTag, raw string) error {
+	unit := tag.Get("unit")
+
+	switch {
+	case field.Type() == durationType:
+		scale, ok := durationUnits[unit]
+		if !ok {
+			scale = time.Second
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be a whole number of %s, got %q", unitName(unit), raw)
+		}
+		if err := checkBounds(tag, n); err != nil {
+			return err
+		}
+		field.SetInt(n * int64(scale))
+
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", raw)
+		}
+		if err := checkBounds(tag, n); err != nil {
+			return err
+		}
+		if unit == "MB" {
+			n <<= 20
+		}
+		field.SetInt(n)
+
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		field.SetBool(b)
+
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// checkBounds checks n against the min and max tags.
+func checkBounds(tag reflect.StructTag, n int64) error {
+	if s, ok := tag.Lookup("min"); ok {
+		if lo, _ := strconv.ParseInt(s, 10, 64); n < lo {
+			return fmt.Errorf("must be at least %d, got %d", lo, n)
+		}
+	}
+	if s, ok := tag.Lookup("max"); ok {
+		if hi, _ := strconv.ParseInt(s, 10, 64); n > hi {
+			return fmt.Errorf("must be at most %d, got %d", hi, n)
+		}
+	}
+	return nil
+}
+
+// unitName spells out a duration unit for error messages.
+func unitName(unit string) string {
+	switch unit {
+	case "ms":
+		return "milliseconds"
+	case "m":
+		return "minutes"
+	case "h":
+		return "hours"
+	case "d":
+		return "days"
+	default:
+		return "seconds"
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultFrontendOrigin is the origin of the frontend's development server.
+// It must match the default tag of Config.FrontendOrigin.
+const defaultFrontendOrigin = "http://localhost:3000"
+
+// Log levels of the LOG_LEVEL setting.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// Runtime holds the settings that can change while the server runs. Unlike Config, which is
+// read once at startup, it is replaced as a whole by Reload, on SIGHUP or through the admin
+// endpoint. Readers get it from Current and must not modify it.
+type Runtime struct {
+	FrontendOrigins     []string        // Origins allowed by CORS, from the comma-separated FRONTEND_ORIGIN; see ParseOrigins
+	RateLimit           int             `env:"RATE_LIMIT_PER_MINUTE" default:"0" min:"0"`              // Requests per minute and client IP on the API; 0 disables the limit
+	LoginRateLimit      int             `env:"LOGIN_RATE_LIMIT_PER_MINUTE" default:"10" min:"0"`       // Login attempts per minute and client IP; 0 disables the limit
+	AdminWriteRateLimit int             `env:"ADMIN_WRITE_RATE_LIMIT_PER_MINUTE" default:"60" min:"0"` // Modifying requests per minute and administrator; 0 disables the limit
+	QuotaPerHour        int             `env:"QUOTA_PER_HOUR" default:"0" min:"0"`                     // Requests per clock hour and authenticated user; 0 disables the quota
+	QuotaPerDay         int             `env:"QUOTA_PER_DAY" default:"0" min:"0"`                      // Requests per UTC day and authenticated user; 0 disables the quota
+	LogLevel            string          `env:"LOG_LEVEL" default:"info"`                               // "debug", "info" (default), "warn" or "error"
+	Features            map[string]bool // Enabled feature flags, from the comma-separated FEATURE_FLAGS
+	IPAllowlist         []*net.IPNet    // Networks allowed to use the application, from the comma-separated IP_ALLOWLIST; every network if empty, see ParseNetworks
+	IPDenylist          []*net.IPNet    // Networks refused, from the comma-separated IP_DENYLIST; takes precedence over IPAllowlist
+	AdminIPAllowlist    []*net.IPNet    // Networks allowed to use the admin routes (e.g., /api/users), from the comma-separated ADMIN_IP_ALLOWLIST; every network if empty
+}
+
+var (
+	current atomic.Pointer[Runtime]
+
+	reloadMu  sync.Mutex
+	onReloads []func(*Runtime)
+)
+
+// Current returns the runtime settings in effect.
+func Current() *Runtime {
+	if r := current.Load(); r != nil {
+		return r
+	}
+	return &Runtime{LogLevel: LogLevelInfo, Features: map[string]bool{}}
+}
+
+// FeatureEnabled reports whether the feature flag name is enabled.
+func (r *Runtime) FeatureEnabled(name string) bool {
+	return r.Features[name]
+}
+
+// OnReload registers fn to be called with the new settings after every successful Reload,
+// e.g., to rebuild middleware that was configured from the previous settings.
+func OnReload(fn func(*Runtime)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	onReloads = append(onReloads, fn)
+}
+
+// Reload re-reads the .env files and the runtime settings and makes them current.
+// Structural settings such as the port or the database are only read at startup.
+// On error the previous settings stay in effect.
+func Reload() (*Runtime, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := loadEnvFiles(); err != nil {
+		return nil, err
+	}
+	r, err := loadRuntime(AppConfig.AppEnv)
+	if err != nil {
+		return nil, err
+	}
+	current.Store(r)
+	for _, fn := range onReloads {
+		fn(r)
+	}
+	slog.Info("Runtime configuration reloaded", "log_level", r.LogLevel, "rate_limit", r.RateLimit, "login_rate_limit", r.LoginRateLimit, "admin_write_rate_limit", r.AdminWriteRateLimit, "quota_per_hour", r.QuotaPerHour, "quota_per_day", r.QuotaPerDay, "origins", r.FrontendOrigins)
+	return r, nil
+}
+
+// ParseOrigins parses a comma-separated list of CORS origins such as
+// "https://app.example.com, https://*.example.com". An origin is a scheme (http or https) and
+// a host with an optional port, without a path. A "*." prefix on the host allows every
+// subdomain of it, e.g. https://*.example.com allows https://acme.example.com but not
+// https://example.com itself. A bare "*" is refused, since the API allows credentials.
+func ParseOrigins(list string) ([]string, error) {
+	origins := []string{}
+	for _, origin := range strings.Split(list, ",") {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			return nil, fmt.Errorf("wildcard origin \"*\" is not allowed together with credentials, list the origins instead")
+		}
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" ||
+			strings.Contains(u.Host, "*") || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return nil, fmt.Errorf("invalid origin %q, expected e.g. https://app.example.com or https://*.example.com", origin)
+		}
+		origins = append(origins, strings.TrimSuffix(origin, "/"))
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("no origins given")
+	}
+	return origins, nil
+}
+
+// ParseNetworks parses a comma-separated list of networks in CIDR notation, such as
+// "10.0.0.0/8, 2001:db8::/32". Single addresses (e.g., "203.0.113.7") stand for a network of
+// just that address. An empty list gives no networks.
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q, expected e.g. 10.0.0.0/8", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// loadRuntime reads the runtime settings from the environment.
+func loadRuntime(appEnv string) (*Runtime, error) {
+	r := &Runtime{Features: map[string]bool{}}
+	if err := loadEnv(r, appEnv); err != nil {
+		return nil, err
+	}
+
+	origins := os.Getenv("FRONTEND_ORIGIN")
+	if origins == "" {
+		origins = defaultFrontendOrigin
+	}
+	var err error
+	if r.FrontendOrigins, err = ParseOrigins(origins); err != nil {
+		return nil, fmt.Errorf("FRONTEND_ORIGIN: %w", err)
+	}
+
+	r.LogLevel = strings.ToLower(r.LogLevel)
+	switch r.LogLevel {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		return nil, fmt.Errorf("LOG_LEVEL must be %q, %q, %q or %q, got %q", LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, r.LogLevel)
+	}
+
+	for name, target := range map[string]*[]*net.IPNet{
+		"IP_ALLOWLIST":       &r.IPAllowlist,
+		"IP_DENYLIST":        &r.IPDenylist,
+		"ADMIN_IP_ALLOWLIST": &r.AdminIPAllowlist,
+	} {
+		if *target, err = ParseNetworks(os.Getenv(name)); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for _, flag := range strings.Split(os.Getenv("FEATURE_FLAGS"), ",") {
+		if flag = strings.TrimSpace(flag); flag != "" {
+			r.Features[flag] = true
+		}
+	}
+	return r, nil
+}
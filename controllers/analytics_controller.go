@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// Time ranges of the analytics endpoints.
+const (
+	defaultAnalyticsRange = 30 * 24 * time.Hour  // When from is omitted
+	maxAnalyticsRange     = 366 * 24 * time.Hour // Longest range, to bound the rows aggregated
+)
+
+// AnalyticsController handles the admin endpoints for usage statistics.
+type AnalyticsController struct {
+	AnalyticsService services.AnalyticsServiceInterface // AnalyticsService dependency (interface)
+}
+
+// NewAnalyticsController creates and returns a new AnalyticsController instance.
+func NewAnalyticsController(analyticsService services.AnalyticsServiceInterface) *AnalyticsController {
+	return &AnalyticsController{
+		AnalyticsService: analyticsService,
+	}
+}
+
+// GetLoginAnalytics returns the logins, active users and average session duration per day or
+// week between from and to, by default over the last 30 days.
+// Example: GET /api/analytics/logins?group_by=week&from=2024-01-01T00:00:00Z
+func (c *AnalyticsController) GetLoginAnalytics(ctx *fiber.Ctx) error {
+	from, to, invalid := timeRangeParams(ctx)
+	if invalid != "" {
+		return invalidParam(ctx, invalid, "datetime", "invalid_time_param", invalid)
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := to.Add(-defaultAnalyticsRange)
+		from = &start
+	}
+	if !from.Before(*to) || to.Sub(*from) > maxAnalyticsRange {
+		return invalidParam(ctx, "from", "range", "invalid_analytics_range")
+	}
+
+	groupBy := ctx.Query("group_by", models.AnalyticsGroupDay)
+	if groupBy != models.AnalyticsGroupDay && groupBy != models.AnalyticsGroupWeek {
+		return invalidParam(ctx, "group_by", "oneof", "invalid_analytics_group_by")
+	}
+
+	analytics, err := c.AnalyticsService.GetLoginAnalytics(ctx.UserContext(), *from, *to, groupBy)
+	if err != nil {
+		requestLogger(ctx).Error("Error computing login analytics", "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_login_analytics"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "login_analytics_retrieved_successfully"),
+		"data":    analytics,
+	})
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// allowedAttachmentTypes maps the accepted (sniffed) MIME types to the extension files are stored with:
+// images and PDF documents.
+var allowedAttachmentTypes = func() map[string]string {
+	types := map[string]string{"application/pdf": ".pdf"}
+	for contentType, ext := range allowedImageTypes {
+		types[contentType] = ext
+	}
+	return types
+}()
+
+// AttachmentController handles uploads and listing of post attachments.
+type AttachmentController struct {
+	PostService       services.PostServiceInterface
+	AttachmentService services.AttachmentServiceInterface
+	Storage           storage.Storage
+	MaxSize           int64 // Maximum accepted file size in bytes
+}
+
+// NewAttachmentController creates and returns a new AttachmentController instance.
+func NewAttachmentController(postService services.PostServiceInterface, attachmentService services.AttachmentServiceInterface, store storage.Storage, maxSize int64) *AttachmentController {
+	return &AttachmentController{
+		PostService:       postService,
+		AttachmentService: attachmentService,
+		Storage:           store,
+		MaxSize:           maxSize,
+	}
+}
+
+// GetAttachments lists all attachments of a post.
+func (c *AttachmentController) GetAttachments(ctx *fiber.Ctx) error {
+	postID := ctx.Params("id")
+
+	attachments, err := c.AttachmentService.GetAttachmentsByPostID(ctx.UserContext(), postID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching attachments for post", "post_id", postID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_attachments"),
+		})
+	}
+
+	for i := range attachments {
+		attachments[i].URL = c.Storage.URL(attachments[i].StorageKey)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "attachments_retrieved_successfully"),
+		"data":    attachments,
+	})
+}
+
+// UploadAttachment handles POST /api/posts/:id/attachments with a multipart "file" field.
+// Only the post's author or an admin may upload.
+func (c *AttachmentController) UploadAttachment(ctx *fiber.Ctx) error {
+	postID := ctx.Params("id")
+
+	post, err := c.PostService.GetPostByID(ctx.UserContext(), postID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching post for attachment upload", "post_id", postID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_post"),
+		})
+	}
+	if post == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_not_found"),
+		})
+	}
+	if !canModifyPost(ctx, post) {
+		return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_attach_forbidden"),
+		})
+	}
+
+	file, err := receiveUpload(ctx, "file", c.MaxSize, allowedAttachmentTypes)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	attachment := &models.Attachment{
+		ID:          uuid.New().String(),
+		PostID:      postID,
+		UserID:      userID,
+		FileName:    file.FileName,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+	}
+	attachment.StorageKey = fmt.Sprintf("posts/%s/%s%s", postID, attachment.ID, file.Ext)
+
+	if err := c.Storage.Save(ctx.UserContext(), attachment.StorageKey, file, file.Size, file.ContentType); err != nil {
+		requestLogger(ctx).Error("Error storing attachment for post", "post_id", postID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_store_file"),
+		})
+	}
+
+	if err := c.AttachmentService.CreateAttachment(ctx.UserContext(), attachment); err != nil {
+		requestLogger(ctx).Error("Error saving attachment metadata for post", "post_id", postID, "error", err)
+		if delErr := c.Storage.Delete(ctx.UserContext(), attachment.StorageKey); delErr != nil {
+			requestLogger(ctx).Warn("Failed to remove orphaned file", "storage_key", attachment.StorageKey, "error", delErr)
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_save_attachment"),
+		})
+	}
+
+	attachment.URL = c.Storage.URL(attachment.StorageKey)
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "attachment_uploaded_successfully"),
+		"data":    attachment,
+	})
+}
+
+// DeleteAttachment removes an attachment and its stored file.
+// Only the post's author or an admin may delete it.
+func (c *AttachmentController) DeleteAttachment(ctx *fiber.Ctx) error {
+	postID := ctx.Params("id")
+	attachmentID := ctx.Params("attachmentId")
+
+	attachment, err := c.AttachmentService.GetAttachmentByID(ctx.UserContext(), attachmentID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching attachment", "attachment_id", attachmentID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_attachment"),
+		})
+	}
+	if attachment == nil || attachment.PostID != postID {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "attachment_not_found"),
+		})
+	}
+
+	post, err := c.PostService.GetPostByID(ctx.UserContext(), postID)
+	if err != nil || post == nil {
+		requestLogger(ctx).Error("Error fetching post for attachment deletion", "post_id", postID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_attachment"),
+		})
+	}
+	if !canModifyPost(ctx, post) {
+		return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "attachment_delete_forbidden"),
+		})
+	}
+
+	if err := c.AttachmentService.DeleteAttachment(ctx.UserContext(), attachmentID); err != nil {
+		requestLogger(ctx).Error("Error deleting attachment", "attachment_id", attachmentID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_attachment"),
+		})
+	}
+	if err := c.Storage.Delete(ctx.UserContext(), attachment.StorageKey); err != nil {
+		requestLogger(ctx).Warn("Failed to remove stored file", "storage_key", attachment.StorageKey, "error", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "attachment_deleted_successfully"),
+	})
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// AuditController handles the admin endpoint for the audit log.
+type AuditController struct {
+	AuditService services.AuditServiceInterface // AuditService dependency (interface)
+}
+
+// NewAuditController creates and returns a new AuditController instance.
+func NewAuditController(auditService services.AuditServiceInterface) *AuditController {
+	return &AuditController{
+		AuditService: auditService,
+	}
+}
+
+// GetAuditLogs lists the audit log entries matching the filters in the query, newest first.
+// With a cursor parameter (empty for the first page) it pages by keyset instead of by page
+// number, like GetUserLogs. Asked for a spreadsheet (see spreadsheetFormat), it answers every
+// matching entry instead, with the snapshots as JSON.
+// Example: GET /api/audit-logs?entity_type=post&action=update&from=2024-01-01T00:00:00Z&page=1
+func (c *AuditController) GetAuditLogs(ctx *fiber.Ctx) error {
+	filter := models.AuditLogFilter{
+		ActorID:    ctx.Query("actor_id"),
+		Action:     ctx.Query("action"),
+		EntityType: ctx.Query("entity_type"),
+		EntityID:   ctx.Query("entity_id"),
+	}
+	var invalid string
+	if filter.From, filter.To, invalid = timeRangeParams(ctx); invalid != "" {
+		return invalidParam(ctx, invalid, "datetime", "invalid_time_param", invalid)
+	}
+	if format, invalid := spreadsheetFormat(ctx); invalid != "" {
+		return invalidParam(ctx, "format", "oneof", "unsupported_export_format", invalid)
+	} else if format != "" {
+		return streamFile(ctx, "audit-logs", format, auditLogExportHeader, c.exportAuditLogs(filter))
+	}
+	page, limit := paginationParams(ctx)
+	after, keyset, ok := cursorParam(ctx)
+	if !ok {
+		return invalidParam(ctx, "cursor", "cursor", "invalid_cursor")
+	}
+
+	if keyset {
+		logs, next, err := c.AuditService.GetAuditLogsAfter(ctx.UserContext(), filter, after, limit)
+		if err != nil {
+			requestLogger(ctx).Error("Error fetching audit logs", "error", err)
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "failed_to_retrieve_audit_logs"),
+			})
+		}
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success":    true,
+			"message":    msg(ctx, "audit_logs_retrieved_successfully"),
+			"data":       logs,
+			"nextCursor": nextCursor(next),
+		})
+	}
+
+	logs, totalPages, totalItems, err := c.AuditService.GetAuditLogs(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching audit logs", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_audit_logs"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "audit_logs_retrieved_successfully"),
+		"data":        logs,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// auditLogExportHeader is the header of the spreadsheets of the audit log.
+var auditLogExportHeader = []string{"id", "created_at", "actor_id", "action", "entity_type", "entity_id", "before", "after", "ip_address", "request_id"}
+
+// exportAuditLogs returns the writer of the entries matching filter to a spreadsheet, newest
+// first, paging by keyset so that entries logged meanwhile don't shift the pages.
+func (c *AuditController) exportAuditLogs(filter models.AuditLogFilter) func(ctx context.Context, out exportWriter) error {
+	return func(ctx context.Context, out exportWriter) error {
+		var after *models.Cursor
+		for {
+			logs, next, err := c.AuditService.GetAuditLogsAfter(ctx, filter, after, spreadsheetPageSize)
+			if err != nil {
+				return err
+			}
+			for _, log := range logs {
+				row := []interface{}{log.ID, log.CreatedAt, orNil(log.ActorID), log.Action, log.EntityType, log.EntityID, string(log.Before), string(log.After), log.IPAddress, log.RequestID}
+				if err := out.Write(log, row); err != nil {
+					return err
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			after = next
+		}
+	}
+}
@@ -1,118 +1,383 @@
 package controllers
 
 import (
-	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/anpsniper/anpbayu-be/bruteforce"
 	"github.com/anpsniper/anpbayu-be/middleware" // Assuming JWT generation is here
+	"github.com/anpsniper/anpbayu-be/models"
 	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/sms"
+	"github.com/anpsniper/anpbayu-be/tenancy"
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthController handles user authentication and session management.
 type AuthController struct {
-	UserService services.UserServiceInterface
+	UserService    services.UserServiceInterface
+	SessionService services.SessionServiceInterface     // Sessions of the issued tokens, checked by middleware.JWT
+	OTPService     services.OTPServiceInterface         // One-time codes of the SMS login
+	SMS            sms.Sender                           // Gateway the login codes are sent through; nil disables the SMS login
+	OTPTTL         time.Duration                        // How long a login code stays valid, as told in the SMS
+	Guard          *bruteforce.Guard                    // Locks out the IPs and accounts failing to log in too often; nil disables it
+	DeviceService  services.DeviceServiceInterface      // Alerts users of logins from new devices and countries
+	FailedLogins   services.FailedLoginServiceInterface // Records the failed login attempts for incident response
 }
 
 // NewAuthController creates and returns a new AuthController instance.
-func NewAuthController(userService services.UserServiceInterface) *AuthController {
+func NewAuthController(userService services.UserServiceInterface, sessionService services.SessionServiceInterface,
+	otpService services.OTPServiceInterface, smsSender sms.Sender, otpTTL time.Duration, guard *bruteforce.Guard,
+	deviceService services.DeviceServiceInterface, failedLoginService services.FailedLoginServiceInterface) *AuthController {
 	return &AuthController{
-		UserService: userService,
+		UserService:    userService,
+		SessionService: sessionService,
+		OTPService:     otpService,
+		SMS:            smsSender,
+		OTPTTL:         otpTTL,
+		Guard:          guard,
+		DeviceService:  deviceService,
+		FailedLogins:   failedLoginService,
 	}
 }
 
 // LoginRequest defines the structure for login requests.
 type LoginRequest struct {
-	Email    string `json:"email"` // Changed to email as per your service layer
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"` // Changed to email as per your service layer
+	Password string `json:"password" validate:"required"`
 }
 
-// Login handles user authentication and generates a JWT.
+// Login handles user authentication and generates a JWT. IPs and accounts failing to log in
+// too often are locked out for a while, see bruteforce.
 func (c *AuthController) Login(ctx *fiber.Ctx) error {
 	req := new(LoginRequest)
 	if err := ctx.BodyParser(req); err != nil {
-		log.Printf("Error parsing login request body: %v", err)
-		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": "Invalid request body"})
+		requestLogger(ctx).Error("Error parsing login request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": msg(ctx, "invalid_request_body")})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	if wait := c.lockout(ctx, req.Email); wait > 0 {
+		return c.lockedOut(ctx, req.Email, wait)
 	}
 
-	user, err := c.UserService.GetUserByEmail(req.Email) // Fetch by email
+	user, err := c.UserService.GetUserByEmail(ctx.UserContext(), req.Email) // Fetch by email
 	if err != nil {
-		log.Printf("Error getting user by email %s: %v", req.Email, err)
-		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": "Internal server error"})
+		requestLogger(ctx).Error("Error getting user by email", "email", req.Email, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
 	}
 	if user == nil {
-		log.Printf("Login failed: User with email %s not found.", req.Email)
-		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{"status": "error", "message": "Invalid credentials"})
+		requestLogger(ctx).Warn("Login failed: user not found", "email", req.Email)
+		return c.loginFailed(ctx, req.Email, models.LoginFailureUnknownAccount, "invalid_credentials")
+	}
+
+	// On a tenant's subdomain only that tenant's users may log in
+	if tenantID, ok := tenancy.FromContext(ctx.UserContext()); ok && tenantID != user.TenantID {
+		requestLogger(ctx).Warn("Login failed: user does not belong to tenant", "email", req.Email, "tenant_id", tenantID)
+		return c.loginFailed(ctx, req.Email, models.LoginFailureWrongTenant, "invalid_credentials")
 	}
 
 	// Compare the provided password with the hashed password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		log.Printf("Login failed for user %s: Invalid password.", req.Email)
-		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{"status": "error", "message": "Invalid credentials"})
+		requestLogger(ctx).Warn("Login failed: invalid password", "email", req.Email)
+		return c.loginFailed(ctx, req.Email, models.LoginFailureInvalidPassword, "invalid_credentials")
+	}
+
+	return c.logIn(ctx, user, req.Email)
+}
+
+// lockout returns how long logins to account (the email or phone number logged in with) have
+// to wait, because it or the client IP is locked out after too many failed logins. The lockouts
+// can't be checked while their store is unavailable; logins are let through then.
+func (c *AuthController) lockout(ctx *fiber.Ctx, account string) time.Duration {
+	wait, err := c.Guard.Check(ctx.UserContext(), middleware.ClientIP(ctx), account)
+	if err != nil {
+		requestLogger(ctx).Error("Error checking login lockouts", "error", err)
+		return 0
+	}
+	return wait
+}
+
+// lockedOut answers a login to account, locked out for wait, with 429 Too Many Requests.
+func (c *AuthController) lockedOut(ctx *fiber.Ctx, account string, wait time.Duration) error {
+	seconds := int(wait.Seconds()) + 1
+	requestLogger(ctx).Warn("Login rejected: locked out after too many failures", "account", account, "retry_after", seconds)
+	c.recordFailedLogin(ctx, account, models.LoginFailureLockedOut)
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return ctx.Status(http.StatusTooManyRequests).JSON(fiber.Map{"status": "error", "message": msg(ctx, "too_many_failed_logins", seconds)})
+}
+
+// loginFailed records a failed login to account from the client IP for reason (one of the
+// models.LoginFailure* constants), which locks either out once it failed too often, and answers
+// 401 Unauthorized with the message of key.
+func (c *AuthController) loginFailed(ctx *fiber.Ctx, account, reason, key string) error {
+	if err := c.Guard.Fail(ctx.UserContext(), middleware.ClientIP(ctx), account); err != nil {
+		requestLogger(ctx).Error("Error recording failed login", "error", err)
 	}
+	c.recordFailedLogin(ctx, account, reason)
+	return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{"status": "error", "message": msg(ctx, key)})
+}
 
-	// Generate JWT token
-	// Assuming GenerateJWT takes userID and a slice of roles (strings)
-	token, err := middleware.GenerateJWT(user.ID, user.Email, []string{user.RoleName}) // Pass user.RoleName as a slice
+// recordFailedLogin records a failed login to account for reason in the failed login log. The
+// login is answered anyway, so errors are only logged.
+func (c *AuthController) recordFailedLogin(ctx *fiber.Ctx, account, reason string) {
+	err := c.FailedLogins.RecordFailedLogin(ctx.UserContext(), &models.FailedLogin{
+		Account:   truncate(account, 255),
+		Reason:    reason,
+		IPAddress: middleware.ClientIP(ctx),
+		UserAgent: truncate(ctx.Get(fiber.HeaderUserAgent), 255),
+	})
 	if err != nil {
-		log.Printf("Error generating JWT: %v", err)
-		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": "Failed to generate token"})
+		requestLogger(ctx).Error("Error logging failed login", "reason", reason, "error", err)
 	}
+}
 
-	// NEW DEBUG LOG: Confirming the role name before sending to frontend
-	log.Printf("DEBUG: User %s (ID: %s) has RoleName: '%s'", user.Email, user.ID, user.RoleName)
+// logIn answers a successful login of user with account: it forgets the failed logins to the
+// account, generates the JWT, creates its session and logs the login event, which is alerted
+// to the user when it comes from a new device or country.
+func (c *AuthController) logIn(ctx *fiber.Ctx, user *models.User, account string) error {
+	if err := c.Guard.Succeed(ctx.UserContext(), account); err != nil {
+		requestLogger(ctx).Error("Error clearing failed logins", "error", err)
+	}
 
-	// NEW: Log the login event and get the log ID
-	logID, err := c.UserService.CreateUserLoginLog(user.ID)
+	token, err := middleware.GenerateJWT(ctx.UserContext(), middleware.TokenSubject{
+		UserID:       user.ID,
+		TenantID:     user.TenantID,
+		Email:        user.Email,
+		Roles:        []string{user.RoleName},
+		TokenVersion: user.TokenVersion,
+	})
 	if err != nil {
-		log.Printf("Warning: Failed to create login log for user %s: %v", user.ID, err)
-		// Do not return error to client, as login itself was successful
+		requestLogger(ctx).Error("Error generating JWT", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "failed_to_generate_token")})
 	}
 
-	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+	// The token is only accepted while its session exists, see middleware.JWT. The session
+	// and the login log entry are created together, or the login fails.
+	session := models.NewSession(user.ID, token, time.Now().Add(middleware.TokenTTL))
+	session.Device = truncate(ctx.Get(fiber.HeaderUserAgent), 255)
+	session.IPAddress = middleware.ClientIP(ctx)
+	logID, err := c.UserService.CreateLoginSession(ctx.UserContext(), session)
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "failed_to_generate_token")})
+	}
+
+	// Confirming the role name before sending to frontend (LOG_LEVEL=debug)
+	requestLogger(ctx).Debug("Login role", "user_id", user.ID, "email", user.Email, "role_name", user.RoleName)
+
+	if err := c.DeviceService.RecordLogin(ctx.UserContext(), user, session.Device, session.IPAddress); err != nil {
+		requestLogger(ctx).Warn("Failed to check the device of the login", "user_id", user.ID, "error", err)
+	}
+
+	response := fiber.Map{
 		"status":            "success",
-		"message":           "Login successful",
+		"message":           msg(ctx, "login_successful"),
 		"token":             token,
 		"user_id":           user.ID,
+		"tenant_id":         user.TenantID,
 		"username":          user.Username, // Ensure this is populated if you want it in session.user.name
 		"email":             user.Email,
 		"role_id":           user.RoleID,
 		"roles":             []string{user.RoleName}, // Return roles as array for frontend
 		"last_login_log_id": logID,                   // Return log ID to frontend for logout tracking
-	})
+	}
+	// With TOKEN_DELIVERY=cookie the token is kept out of reach of scripts; they get the CSRF
+	// token to send with the requests changing anything instead
+	if middleware.TokenCookies() {
+		middleware.SetTokenCookie(ctx, token, session.ExpiresAt)
+		delete(response, "token")
+		response["csrf_token"] = middleware.CSRFToken(token)
+	}
+	return ctx.Status(http.StatusOK).JSON(response)
+}
+
+// OTPRequest defines the structure for requests of an SMS login code.
+type OTPRequest struct {
+	Phone string `json:"phone" validate:"required,e164"` // e.g., "+6281234567890"
+}
+
+// RequestOTP sends a one-time login code by SMS to the user with the given phone number. The
+// answer is the same whether or not the number belongs to a user, so it can't be used to find
+// out which numbers are registered. A new code is only sent once a minute.
+func (c *AuthController) RequestOTP(ctx *fiber.Ctx) error {
+	if c.SMS == nil {
+		return ctx.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"status": "error", "message": msg(ctx, "sms_login_disabled")})
+	}
+
+	req := new(OTPRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing login code request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": msg(ctx, "invalid_request_body")})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	if wait := c.lockout(ctx, req.Phone); wait > 0 {
+		return c.lockedOut(ctx, req.Phone, wait)
+	}
+
+	user, err := c.UserService.GetUserByPhone(ctx.UserContext(), req.Phone)
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
+	}
+	sent := fiber.Map{"status": "success", "message": msg(ctx, "login_code_sent")}
+	if user == nil {
+		requestLogger(ctx).Warn("Login code not sent: phone number not found")
+		return ctx.Status(http.StatusOK).JSON(sent)
+	}
+	if tenantID, ok := tenancy.FromContext(ctx.UserContext()); ok && tenantID != user.TenantID {
+		requestLogger(ctx).Warn("Login code not sent: user does not belong to tenant", "user_id", user.ID, "tenant_id", tenantID)
+		return ctx.Status(http.StatusOK).JSON(sent)
+	}
+
+	code, err := c.OTPService.CreateOTP(ctx.UserContext(), user.ID)
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
+	}
+	if code == "" {
+		requestLogger(ctx).Info("Login code not sent: the previous one was sent less than a minute ago", "user_id", user.ID)
+		return ctx.Status(http.StatusOK).JSON(sent)
+	}
+
+	body := msg(ctx, "login_code_sms", code, int(c.OTPTTL.Minutes()))
+	if err := c.SMS.Send(ctx.UserContext(), req.Phone, body); err != nil {
+		requestLogger(ctx).Error("Error sending login code", "user_id", user.ID, "gateway", c.SMS.Name(), "error", err)
+		return ctx.Status(http.StatusBadGateway).JSON(fiber.Map{"status": "error", "message": msg(ctx, "failed_to_send_login_code")})
+	}
+	return ctx.Status(http.StatusOK).JSON(sent)
+}
+
+// OTPLoginRequest defines the structure for logins with an SMS login code.
+type OTPLoginRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+	Code  string `json:"code" validate:"required,len=6"` // Code received by SMS from POST /login/otp
+}
+
+// LoginWithOTP logs in the user with the given phone number when code is the login code last
+// sent to it. It answers like Login.
+func (c *AuthController) LoginWithOTP(ctx *fiber.Ctx) error {
+	if c.SMS == nil {
+		return ctx.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"status": "error", "message": msg(ctx, "sms_login_disabled")})
+	}
+
+	req := new(OTPLoginRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing login code login request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": msg(ctx, "invalid_request_body")})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	if wait := c.lockout(ctx, req.Phone); wait > 0 {
+		return c.lockedOut(ctx, req.Phone, wait)
+	}
+
+	user, err := c.UserService.GetUserByPhone(ctx.UserContext(), req.Phone)
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
+	}
+	if user == nil {
+		requestLogger(ctx).Warn("Login failed: phone number not found")
+		return c.loginFailed(ctx, req.Phone, models.LoginFailureUnknownAccount, "invalid_login_code")
+	}
+	if tenantID, ok := tenancy.FromContext(ctx.UserContext()); ok && tenantID != user.TenantID {
+		requestLogger(ctx).Warn("Login failed: user does not belong to tenant", "user_id", user.ID, "tenant_id", tenantID)
+		return c.loginFailed(ctx, req.Phone, models.LoginFailureWrongTenant, "invalid_login_code")
+	}
+
+	valid, err := c.OTPService.VerifyOTP(ctx.UserContext(), user.ID, req.Code)
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
+	}
+	if !valid {
+		requestLogger(ctx).Warn("Login failed: invalid login code", "user_id", user.ID)
+		return c.loginFailed(ctx, req.Phone, models.LoginFailureInvalidCode, "invalid_login_code")
+	}
+
+	return c.logIn(ctx, user, req.Phone)
+}
+
+// RevokeLoginRequest defines the structure for "this wasn't me" requests of login alerts.
+type RevokeLoginRequest struct {
+	Token string `json:"token" validate:"required,len=64"` // Token of the link in the alert
+}
+
+// RevokeLogin handles the "this wasn't me" link of an alert of a login from a new device or
+// country: the user is logged out everywhere. It needs no token, as the user may be locked out.
+func (c *AuthController) RevokeLogin(ctx *fiber.Ctx) error {
+	req := new(RevokeLoginRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing revoke login request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": msg(ctx, "invalid_request_body")})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if err := c.DeviceService.RevokeDevice(ctx.UserContext(), req.Token); err != nil {
+		requestLogger(ctx).Error("Error revoking login", "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"status": "success", "message": msg(ctx, "login_revoked")})
 }
 
 // LogoutRequest defines the structure for logout requests.
 type LogoutRequest struct {
-	LastLoginLogID int `json:"last_login_log_id"` // The ID of the login log to update
+	LastLoginLogID int `json:"last_login_log_id" validate:"required,gt=0"` // The ID of the login log to update
 }
 
-// Logout handles user logout and updates the logout timestamp in the log.
+// Logout handles user logout: the session of the token is deleted, so the token isn't accepted
+// anymore, and the logout timestamp is recorded in the log.
 // This endpoint assumes the frontend sends the last_login_log_id obtained during login.
 func (c *AuthController) Logout(ctx *fiber.Ctx) error {
 	req := new(LogoutRequest)
 	if err := ctx.BodyParser(req); err != nil {
-		log.Printf("Error parsing logout request body: %v", err)
-		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": "Invalid request body"})
+		requestLogger(ctx).Error("Error parsing logout request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": msg(ctx, "invalid_request_body")})
 	}
 
-	// NEW DEBUG LOG: Log the received LastLoginLogID
-	log.Printf("DEBUG: Received logout request for LastLoginLogID: %d", req.LastLoginLogID)
+	// Log the received LastLoginLogID (LOG_LEVEL=debug)
+	requestLogger(ctx).Debug("Received logout request", "last_login_log_id", req.LastLoginLogID)
 
-	if req.LastLoginLogID == 0 {
-		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": "last_login_log_id is required for logout logging"})
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
 	}
 
-	err := c.UserService.UpdateUserLogoutLog(req.LastLoginLogID)
+	if token, ok := middleware.GetTokenFromJWT(ctx); ok {
+		if err := c.SessionService.DeleteSession(ctx.UserContext(), token); err != nil {
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{"status": "error", "message": msg(ctx, "internal_server_error")})
+		}
+	}
+	if middleware.TokenCookies() {
+		middleware.ClearTokenCookie(ctx)
+	}
+
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	err := c.UserService.UpdateUserLogoutLog(ctx.UserContext(), userID, req.LastLoginLogID)
 	if err != nil {
-		log.Printf("Warning: Failed to update logout log for ID %d: %v", req.LastLoginLogID, err)
+		requestLogger(ctx).Warn("Failed to update logout log", "last_login_log_id", req.LastLoginLogID, "error", err)
 		// Log the error but still return success to the client for logout
-		return ctx.Status(http.StatusOK).JSON(fiber.Map{"status": "success", "message": "Logout successful, but log update failed."})
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{"status": "success", "message": msg(ctx, "logout_successful_log_update_failed")})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"status":  "success",
-		"message": "Logout successful and log updated.",
+		"message": msg(ctx, "logout_successful"),
 	})
 }
+
+// truncate shortens s to at most n characters, e.g., to fit a VARCHAR(n) column.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
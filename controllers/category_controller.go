@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/utils"
+)
+
+// CategoryController handles category-related requests.
+type CategoryController struct {
+	CategoryService services.CategoryServiceInterface // CategoryService dependency (interface)
+}
+
+// NewCategoryController creates and returns a new CategoryController instance.
+func NewCategoryController(categoryService services.CategoryServiceInterface) *CategoryController {
+	return &CategoryController{
+		CategoryService: categoryService,
+	}
+}
+
+// GetAllCategories retrieves all categories with search and pagination.
+func (c *CategoryController) GetAllCategories(ctx *fiber.Ctx) error {
+	search := ctx.Query("search", "")
+	page, limit := paginationParams(ctx)
+
+	categories, totalPages, totalItems, err := c.CategoryService.GetAllCategories(ctx.UserContext(), search, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching all categories", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_categories"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "categories_retrieved_successfully"),
+		"data":        categories,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetCategoryByID retrieves a single category by its ID.
+func (c *CategoryController) GetCategoryByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	category, err := c.CategoryService.GetCategoryByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching category", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_category"),
+		})
+	}
+	if category == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "category_not_found"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "category_retrieved_successfully"),
+		"data":    category,
+	})
+}
+
+// CategoryRequest represents the expected structure for creating or updating a category.
+type CategoryRequest struct {
+	Name        string  `json:"name" validate:"max=100"` // Required when creating; optional when updating
+	Slug        string  `json:"slug" validate:"max=120"` // Optional; derived from name when empty
+	Description *string `json:"description"`
+}
+
+// CreateCategory creates a new category.
+func (c *CategoryController) CreateCategory(ctx *fiber.Ctx) error {
+	req := new(CategoryRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create category request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	slug := utils.Slugify(req.Slug)
+	if slug == "" {
+		slug = utils.Slugify(req.Name)
+	}
+	if req.Name == "" || slug == "" {
+		return invalidParam(ctx, "name", "required", "category_name_is_required")
+	}
+
+	existingCategory, err := c.CategoryService.GetCategoryBySlug(ctx.UserContext(), slug)
+	if err != nil {
+		requestLogger(ctx).Error("Error checking for existing category slug", "slug", slug, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "internal_server_error"),
+		})
+	}
+	if existingCategory != nil {
+		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "category_with_this_slug_already_exists"),
+		})
+	}
+
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+	newCategory := models.NewCategory(req.Name, slug, description)
+	if err := c.CategoryService.CreateCategory(ctx.UserContext(), newCategory); err != nil {
+		requestLogger(ctx).Error("Error creating category", "name", req.Name, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_category"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "category_created_successfully"),
+		"data":    newCategory,
+	})
+}
+
+// UpdateCategory updates an existing category's information.
+func (c *CategoryController) UpdateCategory(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	existingCategory, err := c.CategoryService.GetCategoryByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing category for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_category_for_update"),
+		})
+	}
+	if existingCategory == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "category_not_found_for_update"),
+		})
+	}
+
+	req := new(CategoryRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update category request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if req.Name != "" {
+		existingCategory.Name = req.Name
+	}
+	if req.Description != nil {
+		existingCategory.Description = *req.Description
+	}
+	if slug := utils.Slugify(req.Slug); slug != "" && slug != existingCategory.Slug {
+		conflictCategory, err := c.CategoryService.GetCategoryBySlug(ctx.UserContext(), slug)
+		if err != nil {
+			requestLogger(ctx).Error("Error checking for category slug conflict", "slug", slug, "error", err)
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "internal_server_error"),
+			})
+		}
+		if conflictCategory != nil {
+			return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "category_with_this_slug_already_exists"),
+			})
+		}
+		existingCategory.Slug = slug
+	}
+
+	if err := c.CategoryService.UpdateCategory(ctx.UserContext(), existingCategory); err != nil {
+		requestLogger(ctx).Error("Error updating category", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_category"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "category_updated_successfully"),
+		"data":    existingCategory,
+	})
+}
+
+// DeleteCategory deletes a category by its ID. Its posts become uncategorized.
+func (c *CategoryController) DeleteCategory(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.CategoryService.DeleteCategory(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error deleting category", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_category"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "category_deleted_successfully"),
+	})
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+)
+
+// ConfigController lets admins reload the runtime settings without restarting the server.
+type ConfigController struct{}
+
+// NewConfigController creates and returns a new ConfigController instance.
+func NewConfigController() *ConfigController {
+	return &ConfigController{}
+}
+
+// ReloadConfig re-reads the runtime settings (CORS origins, rate limit, log level and feature
+// flags), like sending the process SIGHUP does.
+// Example: POST /api/admin/config/reload
+func (c *ConfigController) ReloadConfig(ctx *fiber.Ctx) error {
+	settings, err := config.Reload()
+	if err != nil {
+		requestLogger(ctx).Error("Error reloading configuration", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_reload_configuration", err),
+		})
+	}
+
+	features := []string{}
+	for name := range settings.Features {
+		features = append(features, name)
+	}
+	sort.Strings(features)
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "configuration_reloaded_successfully"),
+		"data": fiber.Map{
+			"frontend_origins": settings.FrontendOrigins,
+			"rate_limit":       settings.RateLimit,
+			"log_level":        settings.LogLevel,
+			"feature_flags":    features,
+		},
+	})
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/openapi"
+)
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at the spec served by GetSpec.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%TITLE% API documentation</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "%SPEC_URL%", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>`
+
+// DocsController serves the OpenAPI description of the API and Swagger UI to browse it.
+type DocsController struct {
+	Title   string                   // Title of the Swagger UI page
+	Build   func() *openapi.Document // Builds the spec of all registered routes
+	SpecURL string                   // Path the spec is served at, loaded by Swagger UI
+
+	once sync.Once
+	spec *openapi.Document
+}
+
+// NewDocsController creates and returns a new DocsController instance.
+func NewDocsController(title string, build func() *openapi.Document, specURL string) *DocsController {
+	return &DocsController{
+		Title:   title,
+		Build:   build,
+		SpecURL: specURL,
+	}
+}
+
+// GetSpec returns the OpenAPI 3 spec of all registered routes. It is built on the first
+// request, once all routes have been registered, and kept for the lifetime of the process.
+// Example: GET /docs/openapi.json
+func (c *DocsController) GetSpec(ctx *fiber.Ctx) error {
+	c.once.Do(func() {
+		c.spec = c.Build()
+	})
+	return ctx.Status(http.StatusOK).JSON(c.spec)
+}
+
+// GetUI serves Swagger UI for the spec.
+// Example: GET /docs
+func (c *DocsController) GetUI(ctx *fiber.Ctx) error {
+	page := strings.NewReplacer("%TITLE%", html.EscapeString(c.Title), "%SPEC_URL%", c.SpecURL).Replace(swaggerUIPage)
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.Status(http.StatusOK).SendString(page)
+}
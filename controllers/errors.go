@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// domainStatuses maps the kinds of domain errors to HTTP statuses.
+var domainStatuses = map[error]int{
+	services.ErrNotFound:   http.StatusNotFound,
+	services.ErrConflict:   http.StatusConflict,
+	services.ErrValidation: http.StatusUnprocessableEntity,
+}
+
+// ErrorHandler is the error handler of the Fiber app. Handlers return the domain errors of the
+// services as they are, and it responds with the status of their kind and their message;
+// constraint violations of the database count as domain errors (see services.AsDomainError). Fiber
+// errors keep their status; any other error is answered with 500 Internal Server Error, without
+// revealing its details.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	status, message := http.StatusInternalServerError, msg(ctx, "internal_server_error")
+
+	var fiberErr *fiber.Error
+	if domainErr, ok := services.AsDomainError(err); ok {
+		if s, ok := domainStatuses[domainErr.Kind]; ok {
+			status, message = s, capitalize(domainErr.Message)
+		}
+	} else if errors.As(err, &fiberErr) {
+		status, message = fiberErr.Code, fiberErr.Message
+	}
+	if status >= http.StatusInternalServerError {
+		requestLogger(ctx).Error("Request failed", "status", status, "error", err)
+		middleware.ReportError(ctx, err)
+	}
+
+	if status == http.StatusUnprocessableEntity {
+		// Same shape as the responses to invalid request bodies (see validationFailed)
+		return ctx.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": message,
+			"errors":  []FieldError{{Code: "invalid", Message: message}},
+		})
+	}
+	return ctx.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": message,
+	})
+}
+
+// isDomainError reports whether err is a domain error that ErrorHandler answers with a client
+// error status, so handlers can return it instead of responding themselves.
+func isDomainError(err error) bool {
+	_, ok := services.AsDomainError(err)
+	return ok
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// Formats of the exports, chosen with the format query parameter; csv is the default.
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+	exportFormatXLSX = "xlsx"
+)
+
+// Media types of the spreadsheets list endpoints answer with, see spreadsheetFormat.
+const (
+	mimeTextCSV = "text/csv"
+	mimeXLSX    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// exportContentTypes are the Content-Type headers of the files of each format.
+var exportContentTypes = map[string]string{
+	exportFormatCSV:  mimeTextCSV + "; charset=utf-8",
+	exportFormatJSON: fiber.MIMEApplicationJSONCharsetUTF8,
+	exportFormatXLSX: mimeXLSX,
+}
+
+// spreadsheetPageSize is the number of records read at once by list endpoints answering with a
+// spreadsheet of every matching record.
+const spreadsheetPageSize = 500
+
+// exportWriter writes the records of an export as they are read, so exports of any size are
+// sent in chunks rather than built in memory.
+type exportWriter interface {
+	// Write writes a record: v as an element of a JSON array, or row, whose fields are in the
+	// order of the header, as a line of a CSV file or a spreadsheet. Fields are strings,
+	// numbers, times, decimals or nil for empty cells.
+	Write(v interface{}, row []interface{}) error
+	// Close ends the file and flushes it.
+	Close() error
+}
+
+// newExportWriter returns a writer of an export in format to w. CSV files and spreadsheets
+// start with header.
+func newExportWriter(w *bufio.Writer, format string, header []string) (exportWriter, error) {
+	switch format {
+	case exportFormatJSON:
+		return &jsonExportWriter{w: w, enc: json.NewEncoder(w)}, nil
+	case exportFormatXLSX:
+		return newXLSXExportWriter(w, header)
+	}
+	out := csv.NewWriter(w)
+	out.Write(header) // Failures of the underlying writer are reported by Close
+	return &csvExportWriter{out: out}, nil
+}
+
+// orNil returns the value p points to, or nil for an empty field when p is nil.
+func orNil[T any](p *T) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// csvExportWriter writes an export as a CSV file.
+type csvExportWriter struct {
+	out *csv.Writer
+}
+
+func (w *csvExportWriter) Write(_ interface{}, row []interface{}) error {
+	fields := make([]string, len(row))
+	for i, value := range row {
+		fields[i] = csvField(value)
+	}
+	return w.out.Write(fields)
+}
+
+func (w *csvExportWriter) Close() error {
+	w.out.Flush()
+	return w.out.Error()
+}
+
+// csvField formats a field of a CSV row: times in RFC 3339 and in UTC, nil as an empty field.
+func csvField(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonExportWriter writes an export as a JSON array, one element per line.
+type jsonExportWriter struct {
+	w       *bufio.Writer
+	enc     *json.Encoder
+	started bool // The opening bracket is written
+}
+
+func (w *jsonExportWriter) Write(v interface{}, _ []interface{}) error {
+	separator := ","
+	if !w.started {
+		separator, w.started = "[", true
+	}
+	if _, err := w.w.WriteString(separator); err != nil {
+		return err
+	}
+	return w.enc.Encode(v)
+}
+
+func (w *jsonExportWriter) Close() error {
+	if !w.started {
+		w.w.WriteString("[")
+	}
+	w.w.WriteString("]\n")
+	return w.w.Flush()
+}
+
+// xlsxExportWriter writes an export as an Excel workbook of a single sheet. The workbook is a
+// ZIP archive that can only be written once complete: rows are kept by the stream writer of
+// excelize, which moves them to a temporary file past a few megabytes, until Close.
+type xlsxExportWriter struct {
+	w    *bufio.Writer
+	file *excelize.File
+	rows *excelize.StreamWriter
+	next int // Number of the next row
+}
+
+func newXLSXExportWriter(w *bufio.Writer, header []string) (*xlsxExportWriter, error) {
+	file := excelize.NewFile()
+	rows, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	out := &xlsxExportWriter{w: w, file: file, rows: rows, next: 1}
+	cells := make([]interface{}, len(header))
+	for i, name := range header {
+		cells[i] = name
+	}
+	if err := out.writeRow(cells); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *xlsxExportWriter) Write(_ interface{}, row []interface{}) error {
+	cells := make([]interface{}, len(row))
+	for i, value := range row {
+		switch value := value.(type) {
+		case time.Time:
+			cells[i] = value.UTC() // Excel has no time zones
+		case decimal.Decimal:
+			cells[i] = value.InexactFloat64()
+		default:
+			cells[i] = value
+		}
+	}
+	return w.writeRow(cells)
+}
+
+func (w *xlsxExportWriter) writeRow(cells []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, w.next)
+	if err != nil {
+		return err
+	}
+	w.next++
+	return w.rows.SetRow(cell, cells)
+}
+
+func (w *xlsxExportWriter) Close() error {
+	defer w.file.Close() // Removes the temporary files
+	if err := w.rows.Flush(); err != nil {
+		return err
+	}
+	if err := w.file.Write(w.w); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// streamExport answers the request with the file of an export, named after name and the
+// current date, in the format of the format query parameter. write writes the records to out
+// once the handler returned, so it mustn't use ctx, and with a context that isn't cancelled
+// with the request's deadline. Its error can only be logged, as the response is under way by
+// then: the file is left truncated, which makes a JSON export invalid.
+func streamExport(ctx *fiber.Ctx, name string, header []string, write func(ctx context.Context, out exportWriter) error) error {
+	format := ctx.Query("format", exportFormatCSV)
+	if _, ok := exportContentTypes[format]; !ok {
+		return invalidParam(ctx, "format", "oneof", "unsupported_export_format", format)
+	}
+	return streamFile(ctx, name, format, header, write)
+}
+
+// spreadsheetFormat returns the format of the spreadsheet a list endpoint is asked for, csv or
+// xlsx, instead of the usual page of JSON: with the format query parameter or, without one,
+// with the Accept header. format is empty for JSON. invalid is the format parameter when it is
+// none of csv, xlsx or json, to be answered with the "unsupported_export_format" message.
+func spreadsheetFormat(ctx *fiber.Ctx) (format, invalid string) {
+	ctx.Vary(fiber.HeaderAccept)
+	switch format := ctx.Query("format"); format {
+	case "":
+	case exportFormatCSV, exportFormatXLSX:
+		return format, ""
+	case exportFormatJSON:
+		return "", ""
+	default:
+		return "", format
+	}
+
+	switch ctx.Accepts(fiber.MIMEApplicationJSON, mimeTextCSV, mimeXLSX) {
+	case mimeTextCSV:
+		return exportFormatCSV, ""
+	case mimeXLSX:
+		return exportFormatXLSX, ""
+	}
+	return "", ""
+}
+
+// eachPage calls read with the numbers of the pages of a list, from the first one to the last
+// one, which read returns the number of, so list endpoints can write every matching record of
+// services paging by page number to a spreadsheet.
+func eachPage(read func(page int) (totalPages int, err error)) error {
+	for page := 1; ; page++ {
+		totalPages, err := read(page)
+		if err != nil || page >= totalPages {
+			return err
+		}
+	}
+}
+
+// streamFile answers the request with the file of an export or a spreadsheet in format, like
+// streamExport.
+func streamFile(ctx *fiber.Ctx, name, format string, header []string, write func(ctx context.Context, out exportWriter) error) error {
+	logger := requestLogger(ctx)
+	exportCtx := context.WithoutCancel(ctx.UserContext())
+	ctx.Set(fiber.HeaderContentType, exportContentTypes[format])
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, time.Now().UTC().Format("20060102"), format))
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		out, err := newExportWriter(w, format, header)
+		if err == nil {
+			err = write(exportCtx, out)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			logger.Error("Error exporting "+name, "error", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// FailedLoginController handles the admin endpoint for the failed login attempts.
+type FailedLoginController struct {
+	FailedLoginService services.FailedLoginServiceInterface // FailedLoginService dependency (interface)
+}
+
+// NewFailedLoginController creates and returns a new FailedLoginController instance.
+func NewFailedLoginController(failedLoginService services.FailedLoginServiceInterface) *FailedLoginController {
+	return &FailedLoginController{
+		FailedLoginService: failedLoginService,
+	}
+}
+
+// GetFailedLogins lists the failed login attempts matching the filters in the query, newest first.
+// Example: GET /api/failed-logins?ip_address=203.0.113.7&reason=invalid_password&from=2024-01-01T00:00:00Z&page=1
+func (c *FailedLoginController) GetFailedLogins(ctx *fiber.Ctx) error {
+	filter := models.FailedLoginFilter{
+		Account:   ctx.Query("account"),
+		IPAddress: ctx.Query("ip_address"),
+		Reason:    ctx.Query("reason"),
+	}
+	var invalid string
+	if filter.From, filter.To, invalid = timeRangeParams(ctx); invalid != "" {
+		return invalidParam(ctx, invalid, "datetime", "invalid_time_param", invalid)
+	}
+	page, limit := paginationParams(ctx)
+
+	attempts, totalPages, totalItems, err := c.FailedLoginService.GetFailedLogins(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching failed logins", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_failed_logins"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "failed_logins_retrieved_successfully"),
+		"data":        attempts,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
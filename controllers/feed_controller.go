@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/feeds"
+	"github.com/anpsniper/anpbayu-be/markdown"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// syndicationFeedSize is the number of most recent posts included in the RSS/Atom feeds.
+const syndicationFeedSize = 20
+
+// feedCacheControl lets browsers and shared caches (CDNs, reverse proxies) reuse feed responses briefly.
+const feedCacheControl = "public, max-age=60"
+
+// FeedController serves the public, unauthenticated post feed.
+type FeedController struct {
+	PostService services.PostServiceInterface
+}
+
+// NewFeedController creates and returns a new FeedController instance.
+func NewFeedController(postService services.PostServiceInterface) *FeedController {
+	return &FeedController{
+		PostService: postService,
+	}
+}
+
+// GetFeed returns published posts with author username and comment counts.
+// Example: GET /feed?page=1&limit=10&render=html
+func (c *FeedController) GetFeed(ctx *fiber.Ctx) error {
+	page, limit := paginationParams(ctx)
+	if limit > 50 {
+		limit = 50 // The feed is public, so keep pages small
+	}
+
+	items, totalPages, totalItems, err := c.PostService.GetPublishedFeed(ctx.UserContext(), page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching public feed", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_feed"),
+		})
+	}
+
+	if wantsRenderedHTML(ctx) {
+		if err := renderFeedItems(items); err != nil {
+			requestLogger(ctx).Error("Error rendering public feed", "error", err)
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "failed_to_render_feed"),
+			})
+		}
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, feedCacheControl)
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "feed_retrieved_successfully"),
+		"data":        items,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetRSS serves the most recent published posts as an RSS 2.0 feed (GET /feed.rss).
+func (c *FeedController) GetRSS(ctx *fiber.Ctx) error {
+	return c.serveSyndication(ctx, "application/rss+xml; charset=utf-8", feeds.BuildRSS)
+}
+
+// GetAtom serves the most recent published posts as an Atom 1.0 feed (GET /feed.atom).
+func (c *FeedController) GetAtom(ctx *fiber.Ctx) error {
+	return c.serveSyndication(ctx, "application/atom+xml; charset=utf-8", feeds.BuildAtom)
+}
+
+func (c *FeedController) serveSyndication(ctx *fiber.Ctx, contentType string, build func(feeds.Site, []models.FeedItem) ([]byte, error)) error {
+	items, _, _, err := c.PostService.GetPublishedFeed(ctx.UserContext(), 1, syndicationFeedSize)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching posts for syndication feed", "error", err)
+		return ctx.Status(http.StatusInternalServerError).SendString("Failed to generate feed")
+	}
+
+	// Feed readers display HTML, so always ship the rendered content
+	if err := renderFeedItems(items); err != nil {
+		requestLogger(ctx).Error("Error rendering syndication feed", "error", err)
+		return ctx.Status(http.StatusInternalServerError).SendString("Failed to generate feed")
+	}
+
+	site := feeds.Site{
+		Title:       config.AppConfig.SiteTitle,
+		URL:         config.AppConfig.SiteURL,
+		Description: config.AppConfig.SiteDesc,
+		SelfURL:     ctx.BaseURL() + ctx.Path(),
+	}
+	body, err := build(site, items)
+	if err != nil {
+		requestLogger(ctx).Error("Error generating syndication feed", "error", err)
+		return ctx.Status(http.StatusInternalServerError).SendString("Failed to generate feed")
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderCacheControl, feedCacheControl)
+	return ctx.Status(http.StatusOK).Send(body)
+}
+
+// renderFeedItems fills in the sanitized HTML of every feed item.
+func renderFeedItems(items []models.FeedItem) error {
+	for i := range items {
+		html, err := markdown.RenderHTML(items[i].Content)
+		if err != nil {
+			return err
+		}
+		items[i].ContentHTML = html
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// fieldsParam reads the fields query parameter of a list or detail endpoint returning records
+// like record: the comma-separated JSON names of the fields to return, among those of record
+// (see models.FieldsOf). id is always returned. Without the parameter fields is nil, selecting
+// every field. invalid is the first name that isn't a field of record, if any, to be answered
+// with the "invalid_field" message.
+func fieldsParam(ctx *fiber.Ctx, record interface{}) (fields models.Fields, invalid string) {
+	value := ctx.Query("fields")
+	if value == "" {
+		return nil, ""
+	}
+	allowed := models.FieldsOf(record)
+	fields = models.Fields{"id"}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || fields.Has(name) {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return nil, name
+		}
+		fields = append(fields, name)
+	}
+	return fields, ""
+}
+
+// selectFields returns data, a record or a slice of records, with only the selected fields of
+// each record. With every field selected, data is returned as is.
+func selectFields(data interface{}, fields models.Fields) interface{} {
+	if fields == nil {
+		return data
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return data // Fails to render the same way
+	}
+	project := func(record map[string]json.RawMessage) map[string]json.RawMessage {
+		for name := range record {
+			if !fields.Has(name) {
+				delete(record, name)
+			}
+		}
+		return record
+	}
+
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &records); err == nil {
+		for _, record := range records {
+			project(record)
+		}
+		return records
+	}
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &record); err != nil {
+		return data
+	}
+	return project(record)
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+const (
+	graphQLMaxDepth       = 10       // Maximum nesting of selections in a query
+	graphQLMaxQueryLength = 16 << 10 // Maximum length of a query in bytes
+)
+
+// Codes of the errors in GraphQL responses, in their "extensions.code".
+const (
+	graphQLUnauthenticated = "UNAUTHENTICATED"
+	graphQLForbidden       = "FORBIDDEN"
+	graphQLNotFound        = "NOT_FOUND"
+	graphQLBadInput        = "BAD_USER_INPUT"
+	graphQLConflict        = "CONFLICT"
+	graphQLInternal        = "INTERNAL"
+)
+
+// graphQLCodes maps the kinds of domain errors to GraphQL error codes.
+var graphQLCodes = map[error]string{
+	services.ErrNotFound:   graphQLNotFound,
+	services.ErrConflict:   graphQLConflict,
+	services.ErrValidation: graphQLBadInput,
+}
+
+// GraphQLController serves the GraphQL API, a single query surface over the users, roles,
+// posts and products that the REST endpoints expose, backed by the same services.
+type GraphQLController struct {
+	Schema *graphql.Schema
+}
+
+// NewGraphQLController creates and returns a new GraphQLController instance.
+func NewGraphQLController(userService services.UserServiceInterface, roleService services.RoleServiceInterface,
+	postService services.PostServiceInterface, productService services.ProductServiceInterface, store storage.Storage) *GraphQLController {
+	resolver := &graphQLResolver{
+		UserService:    userService,
+		RoleService:    roleService,
+		PostService:    postService,
+		ProductService: productService,
+		Storage:        store,
+	}
+	return &GraphQLController{
+		Schema: graphql.MustParseSchema(graphQLSchema, resolver,
+			graphql.UseStringDescriptions(),
+			graphql.MaxDepth(graphQLMaxDepth),
+			graphql.MaxQueryLength(graphQLMaxQueryLength),
+		),
+	}
+}
+
+// GraphQLRequest represents the expected structure of a GraphQL request.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Execute runs a GraphQL query or mutation for the authenticated user. As usual for GraphQL,
+// the response is 200 OK with the errors of the fields that failed next to the data of the
+// others; only malformed requests are rejected with a client error status.
+// Example: POST /graphql {"query": "{ me { username role { name } } }"}
+func (c *GraphQLController) Execute(ctx *fiber.Ctx) error {
+	req := new(GraphQLRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing GraphQL request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	// Resolvers authorize and translate with the request they are resolving
+	execCtx := context.WithValue(ctx.UserContext(), graphQLRequestKey{}, ctx)
+	response := c.Schema.Exec(execCtx, req.Query, req.OperationName, req.Variables)
+	return ctx.Status(http.StatusOK).JSON(response)
+}
+
+// graphQLRequestKey is the context key of the request a GraphQL query is resolved for.
+type graphQLRequestKey struct{}
+
+// graphQLRequest returns the request a GraphQL query is resolved for.
+func graphQLRequest(ctx context.Context) *fiber.Ctx {
+	return ctx.Value(graphQLRequestKey{}).(*fiber.Ctx)
+}
+
+// graphQLError is an error of a field in a GraphQL response, with its code (and the invalid
+// fields of validation errors) in its extensions.
+type graphQLError struct {
+	Message string
+	Code    string
+	Fields  []FieldError
+}
+
+func (e *graphQLError) Error() string {
+	return e.Message
+}
+
+// Extensions returns the "extensions" of the error in the response.
+func (e *graphQLError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if e.Fields != nil {
+		extensions["fields"] = e.Fields
+	}
+	return extensions
+}
+
+// newGraphQLError returns an error with the given code and the message with the given key.
+func newGraphQLError(ctx context.Context, code, key string, args ...interface{}) *graphQLError {
+	return &graphQLError{Message: msg(graphQLRequest(ctx), key, args...), Code: code}
+}
+
+// graphQLFailure turns an error of a service into a GraphQL error, as ErrorHandler does for
+// REST: domain errors keep their message, any other error is logged and masked.
+func graphQLFailure(ctx context.Context, err error, logMessage string, args ...interface{}) error {
+	if domainErr, ok := services.AsDomainError(err); ok {
+		if code, ok := graphQLCodes[domainErr.Kind]; ok {
+			return &graphQLError{Message: capitalize(domainErr.Message), Code: code}
+		}
+	}
+	requestLogger(graphQLRequest(ctx)).Error(logMessage, append(args, "error", err)...)
+	return newGraphQLError(ctx, graphQLInternal, "internal_server_error")
+}
@@ -0,0 +1,547 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/markdown"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// graphQLResolver resolves the queries and mutations of graphQLSchema.
+type graphQLResolver struct {
+	UserService    services.UserServiceInterface
+	RoleService    services.RoleServiceInterface
+	PostService    services.PostServiceInterface
+	ProductService services.ProductServiceInterface
+	Storage        storage.Storage
+}
+
+// pageArgs are the pagination arguments of list queries.
+type pageArgs struct {
+	Page  *int32
+	Limit *int32
+}
+
+// params returns the page and limit to query, with the defaults and cap of paginationParams.
+func (a pageArgs) params(ctx context.Context) (page, limit int) {
+	page, limit = paginationParams(graphQLRequest(ctx))
+	if a.Page != nil && *a.Page > 0 {
+		page = int(*a.Page)
+	}
+	if a.Limit != nil && *a.Limit > 0 {
+		limit = min(int(*a.Limit), limit)
+	}
+	return page, limit
+}
+
+// requirePermission fails unless a role of the authenticated user has permission (one of the
+// models.Permission* constants), as middleware.HasPermission does for the REST routes.
+func (r *graphQLResolver) requirePermission(ctx context.Context, permission string) error {
+	roles, ok := middleware.GetUserRolesFromJWT(graphQLRequest(ctx))
+	if !ok {
+		return newGraphQLError(ctx, graphQLForbidden, "graphql_forbidden")
+	}
+	granted, err := r.RoleService.HasPermission(ctx, roles, permission)
+	if err != nil {
+		return graphQLFailure(ctx, err, "Error checking permission", "permission", permission)
+	}
+	if !granted {
+		return newGraphQLError(ctx, graphQLForbidden, "graphql_forbidden")
+	}
+	return nil
+}
+
+// requireAdminNetwork fails unless the request comes from ADMIN_IP_ALLOWLIST, like the admin
+// route groups, whose IP filter doesn't guard /graphql.
+func requireAdminNetwork(ctx context.Context) error {
+	c := graphQLRequest(ctx)
+	if !middleware.IPAllowed(c, config.Current().AdminIPAllowlist) {
+		requestLogger(c).Warn("GraphQL admin query from a blocked IP address rejected", "ip", middleware.ClientIP(c))
+		return newGraphQLError(ctx, graphQLForbidden, "ip_address_not_allowed")
+	}
+	return nil
+}
+
+// --- Queries ---
+
+// Me resolves the authenticated user.
+func (r *graphQLResolver) Me(ctx context.Context) (*userResolver, error) {
+	userID, ok := middleware.GetUserIDFromJWT(graphQLRequest(ctx))
+	if !ok {
+		return nil, newGraphQLError(ctx, graphQLUnauthenticated, "user_id_not_found_in_token")
+	}
+	user, err := r.UserService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching authenticated user", "user_id", userID)
+	}
+	if user == nil {
+		return nil, newGraphQLError(ctx, graphQLNotFound, "user_not_found")
+	}
+	return &userResolver{r, user}, nil
+}
+
+// Users resolves a page of users (users.manage).
+func (r *graphQLResolver) Users(ctx context.Context, args struct {
+	Search *string
+	RoleID *graphql.ID
+	pageArgs
+}) (*userPageResolver, error) {
+	if err := requireAdminNetwork(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.requirePermission(ctx, models.PermissionUsersManage); err != nil {
+		return nil, err
+	}
+	page, limit := args.params(ctx)
+	roleID := ""
+	if args.RoleID != nil {
+		roleID = string(*args.RoleID)
+	}
+	users, totalPages, totalItems, err := r.UserService.GetAllUsers(ctx, deref(args.Search), roleID, "", page, limit)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching all users")
+	}
+	result := &userPageResolver{pageInfo: pageInfo{page, totalPages, totalItems}}
+	for i := range users {
+		result.items = append(result.items, &userResolver{r, &users[i]})
+	}
+	return result, nil
+}
+
+// User resolves a user by ID (users.manage), or null if there is none.
+func (r *graphQLResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	if err := requireAdminNetwork(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.requirePermission(ctx, models.PermissionUsersManage); err != nil {
+		return nil, err
+	}
+	user, err := r.UserService.GetUserByID(ctx, string(args.ID))
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching user", "id", args.ID)
+	}
+	if user == nil {
+		return nil, nil
+	}
+	return &userResolver{r, user}, nil
+}
+
+// Roles resolves a page of roles (roles.manage).
+func (r *graphQLResolver) Roles(ctx context.Context, args struct {
+	Search *string
+	pageArgs
+}) (*rolePageResolver, error) {
+	if err := requireAdminNetwork(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.requirePermission(ctx, models.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+	page, limit := args.params(ctx)
+	roles, totalPages, totalItems, err := r.RoleService.GetAllRoles(ctx, deref(args.Search), page, limit)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching all roles")
+	}
+	result := &rolePageResolver{pageInfo: pageInfo{page, totalPages, totalItems}}
+	for i := range roles {
+		result.items = append(result.items, &roleResolver{&roles[i]})
+	}
+	return result, nil
+}
+
+// Role resolves a role by ID (roles.manage), or null if there is none.
+func (r *graphQLResolver) Role(ctx context.Context, args struct{ ID graphql.ID }) (*roleResolver, error) {
+	if err := requireAdminNetwork(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.requirePermission(ctx, models.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+	role, err := r.RoleService.GetRoleByID(ctx, string(args.ID))
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching role", "id", args.ID)
+	}
+	if role == nil {
+		return nil, nil
+	}
+	return &roleResolver{role}, nil
+}
+
+// Posts resolves a page of posts.
+func (r *graphQLResolver) Posts(ctx context.Context, args struct {
+	Search   *string
+	Tag      *string
+	Category *string
+	pageArgs
+}) (*postPageResolver, error) {
+	page, limit := args.params(ctx)
+	posts, totalPages, totalItems, err := r.PostService.GetAllPosts(ctx, deref(args.Search), deref(args.Tag), deref(args.Category), nil, page, limit)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching all posts")
+	}
+	result := &postPageResolver{pageInfo: pageInfo{page, totalPages, totalItems}}
+	for i := range posts {
+		result.items = append(result.items, &postResolver{&posts[i]})
+	}
+	return result, nil
+}
+
+// Post resolves a post by ID, or null if there is none.
+func (r *graphQLResolver) Post(ctx context.Context, args struct{ ID graphql.ID }) (*postResolver, error) {
+	post, err := r.PostService.GetPostByID(ctx, string(args.ID))
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching post", "id", args.ID)
+	}
+	if post == nil {
+		return nil, nil
+	}
+	return &postResolver{post}, nil
+}
+
+// Products resolves a page of products. Archived products are only listed to admins.
+func (r *graphQLResolver) Products(ctx context.Context, args struct {
+	Search      *string
+	Category    *string
+	StockStatus *string
+	Archived    *string
+	pageArgs
+}) (*productPageResolver, error) {
+	filter := models.ProductFilter{
+		Search:   deref(args.Search),
+		Category: deref(args.Category),
+		Archived: models.ArchivedExclude,
+	}
+	if args.StockStatus != nil {
+		filter.StockStatus = strings.ToLower(*args.StockStatus) // e.g., IN_STOCK to in_stock
+	}
+	if args.Archived != nil && *args.Archived != "EXCLUDE" {
+		if !middleware.UserHasRole(graphQLRequest(ctx), "admin") {
+			return nil, newGraphQLError(ctx, graphQLForbidden, "only_admins_can_list_archived_products")
+		}
+		filter.Archived = models.ArchivedOnly
+		if *args.Archived == "INCLUDE" {
+			filter.Archived = models.ArchivedInclude
+		}
+	}
+
+	page, limit := args.params(ctx)
+	products, totalPages, totalItems, err := r.ProductService.GetAllProducts(ctx, filter, page, limit)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching all products")
+	}
+	result := &productPageResolver{pageInfo: pageInfo{page, totalPages, totalItems}}
+	for i := range products {
+		result.items = append(result.items, &productResolver{r, &products[i]})
+	}
+	return result, nil
+}
+
+// Product resolves a product by ID, or null if there is none.
+func (r *graphQLResolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	product, err := r.ProductService.GetProductByID(ctx, string(args.ID))
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching product", "id", args.ID)
+	}
+	if product == nil {
+		return nil, nil
+	}
+	return &productResolver{r, product}, nil
+}
+
+// --- Mutations ---
+
+// postInput is the PostInput of post mutations.
+type postInput struct {
+	Title       string
+	Content     string
+	CategoryID  *graphql.ID
+	Tags        *[]string
+	Published   *bool
+	PublishedAt *graphql.Time
+}
+
+// request returns the input as the PostRequest of the REST endpoints, validated the same way.
+func (in postInput) request(ctx context.Context) (*PostRequest, error) {
+	req := &PostRequest{Title: in.Title, Content: in.Content, Published: in.Published}
+	if in.CategoryID != nil {
+		categoryID := string(*in.CategoryID)
+		req.CategoryID = &categoryID
+	}
+	if in.Tags != nil {
+		req.Tags = *in.Tags
+	}
+	if in.PublishedAt != nil {
+		req.PublishedAt = &in.PublishedAt.Time
+	}
+	if errs := validationErrors(graphQLRequest(ctx), req); errs != nil {
+		err := newGraphQLError(ctx, graphQLBadInput, "validation_failed")
+		err.Fields = errs
+		return nil, err
+	}
+	return req, nil
+}
+
+// CreatePost creates a post authored by the authenticated user.
+func (r *graphQLResolver) CreatePost(ctx context.Context, args struct{ Input postInput }) (*postResolver, error) {
+	userID, ok := middleware.GetUserIDFromJWT(graphQLRequest(ctx))
+	if !ok {
+		return nil, newGraphQLError(ctx, graphQLUnauthenticated, "user_id_not_found_in_token")
+	}
+	req, err := args.Input.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newPost := models.NewPost(userID, req.Title, req.Content)
+	newPost.CategoryID = emptyToNil(req.CategoryID)
+	if req.Tags != nil {
+		newPost.Tags = req.Tags
+	}
+	applyPublication(newPost, req)
+
+	if err := r.PostService.CreatePost(ctx, newPost); err != nil {
+		return nil, graphQLFailure(ctx, err, "Error creating post", "title", req.Title)
+	}
+	// Reload to return the resolved category name and normalized tags
+	created, err := r.PostService.GetPostByID(ctx, newPost.ID)
+	if err != nil || created == nil {
+		created = newPost
+	}
+	return &postResolver{created}, nil
+}
+
+// UpdatePost updates a post. Only the author or an admin may update it.
+func (r *graphQLResolver) UpdatePost(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input postInput
+}) (*postResolver, error) {
+	id := string(args.ID)
+	existingPost, err := r.modifiablePost(ctx, id, "post_modify_forbidden")
+	if err != nil {
+		return nil, err
+	}
+	req, err := args.Input.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingPost.Title = req.Title
+	existingPost.Content = req.Content
+	existingPost.CategoryID = emptyToNil(req.CategoryID)
+	if req.Tags != nil {
+		existingPost.Tags = req.Tags
+	}
+	applyPublication(existingPost, req)
+
+	if err := r.PostService.UpdatePost(ctx, existingPost); err != nil {
+		return nil, graphQLFailure(ctx, err, "Error updating post", "id", id)
+	}
+	updated, err := r.PostService.GetPostByID(ctx, id)
+	if err != nil || updated == nil {
+		updated = existingPost
+	}
+	return &postResolver{updated}, nil
+}
+
+// DeletePost moves a post to the trash. Only the author or an admin may delete it.
+func (r *graphQLResolver) DeletePost(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id := string(args.ID)
+	if _, err := r.modifiablePost(ctx, id, "post_delete_forbidden"); err != nil {
+		return false, err
+	}
+	if err := r.PostService.DeletePost(ctx, id); err != nil {
+		return false, graphQLFailure(ctx, err, "Error deleting post", "id", id)
+	}
+	return true, nil
+}
+
+// modifiablePost fetches a post the authenticated user is about to change, failing with the
+// message with the key forbidden unless they are its author or an admin (see canModifyPost).
+func (r *graphQLResolver) modifiablePost(ctx context.Context, id, forbidden string) (*models.Post, error) {
+	post, err := r.PostService.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching existing post", "id", id)
+	}
+	if post == nil {
+		return nil, newGraphQLError(ctx, graphQLNotFound, "post_not_found")
+	}
+	if !canModifyPost(graphQLRequest(ctx), post) {
+		return nil, newGraphQLError(ctx, graphQLForbidden, forbidden)
+	}
+	return post, nil
+}
+
+// --- Types ---
+
+// pageInfo resolves the pagination fields of the page types.
+type pageInfo struct {
+	page, totalPages, totalItems int
+}
+
+func (p pageInfo) CurrentPage() int32 { return int32(p.page) }
+func (p pageInfo) TotalPages() int32  { return int32(p.totalPages) }
+func (p pageInfo) TotalItems() int32  { return int32(p.totalItems) }
+
+type userPageResolver struct {
+	pageInfo
+	items []*userResolver
+}
+
+func (p *userPageResolver) Items() []*userResolver { return p.items }
+
+type rolePageResolver struct {
+	pageInfo
+	items []*roleResolver
+}
+
+func (p *rolePageResolver) Items() []*roleResolver { return p.items }
+
+type postPageResolver struct {
+	pageInfo
+	items []*postResolver
+}
+
+func (p *postPageResolver) Items() []*postResolver { return p.items }
+
+type productPageResolver struct {
+	pageInfo
+	items []*productResolver
+}
+
+func (p *productPageResolver) Items() []*productResolver { return p.items }
+
+// userResolver resolves the fields of a User.
+type userResolver struct {
+	root *graphQLResolver
+	user *models.User
+}
+
+func (u *userResolver) ID() graphql.ID          { return graphql.ID(u.user.ID) }
+func (u *userResolver) Username() string        { return u.user.Username }
+func (u *userResolver) Email() string           { return u.user.Email }
+func (u *userResolver) Version() int32          { return int32(u.user.Version) }
+func (u *userResolver) CreatedAt() graphql.Time { return graphql.Time{Time: u.user.CreatedAt} }
+func (u *userResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: u.user.UpdatedAt} }
+
+func (u *userResolver) RoleName() string {
+	if u.user.RoleName == "" && u.user.Role != nil {
+		return u.user.Role.Name
+	}
+	return u.user.RoleName
+}
+
+func (u *userResolver) AvatarURL() *string {
+	return optionalString(fileURL(u.root.Storage, u.user.AvatarKey))
+}
+
+// Role resolves the role of the user, loading it unless it was eagerly loaded with the user.
+func (u *userResolver) Role(ctx context.Context) (*roleResolver, error) {
+	if u.user.Role != nil {
+		return &roleResolver{u.user.Role}, nil
+	}
+	role, err := u.root.RoleService.GetRoleByID(ctx, u.user.RoleID)
+	if err != nil {
+		return nil, graphQLFailure(ctx, err, "Error fetching role of user", "user_id", u.user.ID)
+	}
+	if role == nil {
+		return nil, nil
+	}
+	return &roleResolver{role}, nil
+}
+
+// roleResolver resolves the fields of a Role.
+type roleResolver struct {
+	role *models.Role
+}
+
+func (r *roleResolver) ID() graphql.ID          { return graphql.ID(r.role.ID) }
+func (r *roleResolver) Name() string            { return r.role.Name }
+func (r *roleResolver) Description() string     { return r.role.Description }
+func (r *roleResolver) Version() int32          { return int32(r.role.Version) }
+func (r *roleResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.role.CreatedAt} }
+func (r *roleResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.role.UpdatedAt} }
+
+// postResolver resolves the fields of a Post.
+type postResolver struct {
+	post *models.Post
+}
+
+func (p *postResolver) ID() graphql.ID             { return graphql.ID(p.post.ID) }
+func (p *postResolver) AuthorID() graphql.ID       { return graphql.ID(p.post.UserID) }
+func (p *postResolver) Title() string              { return p.post.Title }
+func (p *postResolver) Content() string            { return p.post.Content }
+func (p *postResolver) CategoryName() *string      { return optionalString(p.post.CategoryName) }
+func (p *postResolver) Tags() []string             { return p.post.Tags }
+func (p *postResolver) PublishedAt() *graphql.Time { return optionalTime(p.post.PublishedAt) }
+func (p *postResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: p.post.CreatedAt} }
+func (p *postResolver) UpdatedAt() graphql.Time    { return graphql.Time{Time: p.post.UpdatedAt} }
+
+func (p *postResolver) CategoryID() *graphql.ID {
+	if p.post.CategoryID == nil {
+		return nil
+	}
+	id := graphql.ID(*p.post.CategoryID)
+	return &id
+}
+
+// ContentHTML renders the Markdown content, only for the queries that select it.
+func (p *postResolver) ContentHTML(ctx context.Context) (string, error) {
+	html, err := markdown.RenderHTML(p.post.Content)
+	if err != nil {
+		return "", graphQLFailure(ctx, err, "Error rendering post", "id", p.post.ID)
+	}
+	return html, nil
+}
+
+// productResolver resolves the fields of a Product.
+type productResolver struct {
+	root    *graphQLResolver
+	product *models.Product
+}
+
+func (p *productResolver) ID() graphql.ID            { return graphql.ID(p.product.ID) }
+func (p *productResolver) Name() string              { return p.product.Name }
+func (p *productResolver) Description() string       { return p.product.Description }
+func (p *productResolver) Price() string             { return p.product.Price.StringFixed(models.MoneyScale) }
+func (p *productResolver) Stock() int32              { return int32(p.product.Stock) }
+func (p *productResolver) Category() string          { return p.product.Category }
+func (p *productResolver) ArchivedAt() *graphql.Time { return optionalTime(p.product.ArchivedAt) }
+func (p *productResolver) Version() int32            { return int32(p.product.Version) }
+func (p *productResolver) CreatedAt() graphql.Time   { return graphql.Time{Time: p.product.CreatedAt} }
+func (p *productResolver) UpdatedAt() graphql.Time   { return graphql.Time{Time: p.product.UpdatedAt} }
+
+func (p *productResolver) ImageURL() *string {
+	return optionalString(fileURL(p.root.Storage, p.product.ImageKey))
+}
+
+// deref returns the string s points to, or "" if s is nil.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// optionalString returns nil for "", so empty values resolve to null.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optionalTime returns t as a GraphQL time, or nil if t is nil.
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
@@ -0,0 +1,150 @@
+package controllers
+
+// graphQLSchema is the schema served at /graphql. Lists are paginated like the REST
+// endpoints, and the same roles apply: users and roles are for admins only, archived
+// products for admins only, and posts may only be changed by their author or an admin.
+const graphQLSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+scalar Time
+
+type Query {
+	"The authenticated user"
+	me: User!
+	"Users, most recent first (admin)"
+	users(search: String, roleId: ID, page: Int, limit: Int): UserPage!
+	"A user by ID (admin)"
+	user(id: ID!): User
+	"Roles (admin)"
+	roles(search: String, page: Int, limit: Int): RolePage!
+	"A role by ID (admin)"
+	role(id: ID!): Role
+	"Posts, filtered by tag and category slugs"
+	posts(search: String, tag: String, category: String, page: Int, limit: Int): PostPage!
+	"A post by ID"
+	post(id: ID!): Post
+	"Products; archived ones are only listed to admins who ask for them"
+	products(search: String, category: String, stockStatus: StockStatus, archived: ArchivedFilter, page: Int, limit: Int): ProductPage!
+	"A product by ID"
+	product(id: ID!): Product
+}
+
+type Mutation {
+	"Create a post authored by the authenticated user"
+	createPost(input: PostInput!): Post!
+	"Update a post (author or admin)"
+	updatePost(id: ID!, input: PostInput!): Post!
+	"Move a post to the trash (author or admin)"
+	deletePost(id: ID!): Boolean!
+}
+
+type User {
+	id: ID!
+	username: String!
+	email: String!
+	role: Role
+	roleName: String!
+	avatarUrl: String
+	version: Int!
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type Role {
+	id: ID!
+	name: String!
+	description: String!
+	version: Int!
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type Post {
+	id: ID!
+	authorId: ID!
+	title: String!
+	"Markdown source"
+	content: String!
+	"Sanitized HTML rendered from content"
+	contentHtml: String!
+	categoryId: ID
+	categoryName: String
+	tags: [String!]!
+	"Null for drafts; in the future for scheduled posts"
+	publishedAt: Time
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type Product {
+	id: ID!
+	name: String!
+	description: String!
+	"Decimal amount with 2 decimal places, as a string to keep it exact"
+	price: String!
+	stock: Int!
+	category: String!
+	archivedAt: Time
+	imageUrl: String
+	version: Int!
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+enum StockStatus {
+	IN_STOCK
+	OUT_OF_STOCK
+	LOW_STOCK
+}
+
+enum ArchivedFilter {
+	"Only active products (default)"
+	EXCLUDE
+	"Only archived products (admin)"
+	ONLY
+	"Active and archived products (admin)"
+	INCLUDE
+}
+
+type UserPage {
+	items: [User!]!
+	currentPage: Int!
+	totalPages: Int!
+	totalItems: Int!
+}
+
+type RolePage {
+	items: [Role!]!
+	currentPage: Int!
+	totalPages: Int!
+	totalItems: Int!
+}
+
+type PostPage {
+	items: [Post!]!
+	currentPage: Int!
+	totalPages: Int!
+	totalItems: Int!
+}
+
+type ProductPage {
+	items: [Product!]!
+	currentPage: Int!
+	totalPages: Int!
+	totalItems: Int!
+}
+
+input PostInput {
+	title: String!
+	content: String!
+	categoryId: ID
+	tags: [String!]
+	"Publish now (true) or revert to a draft (false); omit to keep the current state"
+	published: Boolean
+	"Explicit, possibly future, publication time; takes precedence over published"
+	publishedAt: Time
+}
+`
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/anpsniper/anpbayu-be/cache"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// healthPingTimeout bounds how long the health check waits for the database to answer.
+const healthPingTimeout = 2 * time.Second
+
+// HealthController reports whether the backend and its dependencies are reachable, through
+// /health and the Kubernetes-style probes /healthz, /readyz and /startupz.
+type HealthController struct {
+	DB      *database.DB
+	Pool    *pgxpool.Pool       // nil unless the database is PostgreSQL
+	Cache   *cache.RedisStorage // nil unless Redis is used
+	started atomic.Bool         // Set by MarkStarted once the server accepts requests
+}
+
+// NewHealthController creates and returns a new HealthController instance.
+func NewHealthController(db *database.DB, pool *pgxpool.Pool, cache *cache.RedisStorage) *HealthController {
+	return &HealthController{
+		DB:    db,
+		Pool:  pool,
+		Cache: cache,
+	}
+}
+
+// MarkStarted records that the startup of the server has finished, see GetStartup.
+func (c *HealthController) MarkStarted() {
+	c.started.Store(true)
+}
+
+// GetHealth pings the database and reports connection pool statistics.
+// It responds with 503 Service Unavailable when the database can't be reached,
+// so load balancers and orchestrators stop routing traffic to this instance.
+// Example: GET /health
+func (c *HealthController) GetHealth(ctx *fiber.Ctx) error {
+	pingCtx, cancel := context.WithTimeout(ctx.UserContext(), healthPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.DB.PingContext(pingCtx)
+	latency := time.Since(start)
+
+	database := fiber.Map{
+		"status":     "up",
+		"latency_ms": latency.Milliseconds(),
+		"pool":       c.poolStats(),
+	}
+	if err != nil {
+		requestLogger(ctx).Warn("Health check: database ping failed", "error", err)
+		database["status"] = "down"
+		database["error"] = "database unreachable"
+		return ctx.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+			"status":   "unhealthy",
+			"message":  msg(ctx, "database_is_unreachable"),
+			"time":     time.Now().Format(time.RFC3339),
+			"database": database,
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"status":   "healthy",
+		"message":  msg(ctx, "backend_running"),
+		"time":     time.Now().Format(time.RFC3339),
+		"database": database,
+	})
+}
+
+// GetLiveness reports that the process is alive and able to serve requests. It checks no
+// dependencies, so an outage of the database doesn't get the instance restarted.
+// Example: GET /healthz
+func (c *HealthController) GetLiveness(ctx *fiber.Ctx) error {
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"status": "alive",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetReadiness checks every dependency needed to serve requests: the database is reachable,
+// its schema is migrated to the latest version, and the cache (Redis, when used) answers.
+// It responds with 503 Service Unavailable, and the state of each dependency, if one fails,
+// so traffic is routed to other instances until it recovers.
+// Example: GET /readyz
+func (c *HealthController) GetReadiness(ctx *fiber.Ctx) error {
+	checkCtx, cancel := context.WithTimeout(ctx.UserContext(), healthPingTimeout)
+	defer cancel()
+
+	checks := fiber.Map{
+		"database":   c.checkDatabase(checkCtx),
+		"migrations": c.checkMigrations(checkCtx),
+		"cache":      c.checkCache(checkCtx),
+	}
+	return probeResponse(ctx, "ready", "not_ready", checks)
+}
+
+// GetStartup reports whether the server has finished starting up: migrations are applied and
+// the routes are registered. Until then it responds with 503 Service Unavailable, so the
+// orchestrator holds off the other probes instead of restarting a slow start.
+// Example: GET /startupz
+func (c *HealthController) GetStartup(ctx *fiber.Ctx) error {
+	checkCtx, cancel := context.WithTimeout(ctx.UserContext(), healthPingTimeout)
+	defer cancel()
+
+	server := fiber.Map{"status": "up"}
+	if !c.started.Load() {
+		server["status"] = "down"
+		server["error"] = "starting"
+	}
+	checks := fiber.Map{
+		"server":     server,
+		"migrations": c.checkMigrations(checkCtx),
+	}
+	return probeResponse(ctx, "started", "starting", checks)
+}
+
+// probeResponse responds with 200 OK and okStatus if every check is up (or disabled), and with
+// 503 Service Unavailable and failedStatus otherwise.
+func probeResponse(ctx *fiber.Ctx, okStatus, failedStatus string, checks fiber.Map) error {
+	code, status := http.StatusOK, okStatus
+	for _, check := range checks {
+		if check.(fiber.Map)["status"] == "down" {
+			code, status = http.StatusServiceUnavailable, failedStatus
+		}
+	}
+	return ctx.Status(code).JSON(fiber.Map{
+		"status": status,
+		"time":   time.Now().Format(time.RFC3339),
+		"checks": checks,
+	})
+}
+
+// checkDatabase pings the database.
+func (c *HealthController) checkDatabase(ctx context.Context) fiber.Map {
+	start := time.Now()
+	err := c.DB.PingContext(ctx)
+	check := fiber.Map{
+		"status":     "up",
+		"latency_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Readiness check: database ping failed", "error", err)
+		check["status"] = "down"
+		check["error"] = "database unreachable"
+	}
+	return check
+}
+
+// checkMigrations compares the schema version of the database with the embedded migrations.
+func (c *HealthController) checkMigrations(ctx context.Context) fiber.Map {
+	schema, err := database.CheckSchema(ctx, c.DB)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Probe: reading the schema version failed", "error", err)
+		return fiber.Map{"status": "down", "error": "schema version unavailable"}
+	}
+	check := fiber.Map{
+		"status":  "up",
+		"version": schema.Version,
+		"latest":  schema.Latest,
+		"dirty":   schema.Dirty,
+	}
+	if !schema.UpToDate() {
+		check["status"] = "down"
+		check["error"] = "migrations pending"
+	}
+	return check
+}
+
+// checkCache pings Redis, if it is used.
+func (c *HealthController) checkCache(ctx context.Context) fiber.Map {
+	if c.Cache == nil {
+		return fiber.Map{"status": "disabled"}
+	}
+	start := time.Now()
+	err := c.Cache.Ping(ctx)
+	check := fiber.Map{
+		"status":     "up",
+		"latency_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Readiness check: cache ping failed", "error", err)
+		check["status"] = "down"
+		check["error"] = "cache unreachable"
+	}
+	return check
+}
+
+// poolStats summarizes the connection pool: open, in-use and idle connections, and how often
+// (and how long) callers had to wait for a free connection.
+func (c *HealthController) poolStats() fiber.Map {
+	if c.Pool != nil {
+		// The pgx pool manages the connections; the *sql.DB wrapper on top of it keeps none idle
+		stat := c.Pool.Stat()
+		return fiber.Map{
+			"open":             stat.TotalConns(),
+			"in_use":           stat.AcquiredConns(),
+			"idle":             stat.IdleConns(),
+			"max_open":         stat.MaxConns(),
+			"wait_count":       stat.EmptyAcquireCount(),
+			"wait_duration_ms": stat.AcquireDuration().Milliseconds(),
+		}
+	}
+
+	stats := c.DB.Stats()
+	return fiber.Map{
+		"open":             stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+		"max_open":         stats.MaxOpenConnections,
+		"wait_count":       stats.WaitCount,
+		"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+	}
+}
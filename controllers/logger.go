@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// requestLogger returns the logger of the request (see middleware.RequestLogger), with the
+// route pattern of the handler (e.g., "/api/posts/:id") added.
+func requestLogger(ctx *fiber.Ctx) *slog.Logger {
+	return logging.FromContext(ctx.UserContext()).With("route", ctx.Route().Path)
+}
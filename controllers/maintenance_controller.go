@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// MaintenanceController lets admins switch maintenance mode on and off, e.g., around deployments.
+type MaintenanceController struct {
+	SettingService  services.SettingServiceInterface // SettingService dependency (interface)
+	MaintenanceMode *middleware.MaintenanceMode      // Middleware applying the mode, updated at once on changes
+}
+
+// NewMaintenanceController creates and returns a new MaintenanceController instance.
+func NewMaintenanceController(settingService services.SettingServiceInterface, maintenanceMode *middleware.MaintenanceMode) *MaintenanceController {
+	return &MaintenanceController{
+		SettingService:  settingService,
+		MaintenanceMode: maintenanceMode,
+	}
+}
+
+// MaintenanceRequest represents the expected structure for enabling or disabling maintenance mode.
+type MaintenanceRequest struct {
+	Enabled *bool      `json:"enabled" validate:"required"`
+	Message string     `json:"message" validate:"max=500"` // Shown to rejected clients
+	EndsAt  *time.Time `json:"ends_at"`                    // Planned end, announced in the Retry-After header
+}
+
+// GetMaintenanceMode retrieves the state of maintenance mode.
+// Example: GET /api/admin/maintenance
+func (c *MaintenanceController) GetMaintenanceMode(ctx *fiber.Ctx) error {
+	mode, err := c.SettingService.GetMaintenanceMode(ctx.UserContext())
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching maintenance mode", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_maintenance_mode"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "maintenance_mode_retrieved_successfully"),
+		"data":    mode,
+	})
+}
+
+// SetMaintenanceMode enables or disables maintenance mode. While it is enabled, everyone but
+// admins is answered with 503 Service Unavailable.
+// Example: PUT /api/admin/maintenance {"enabled": true, "message": "Upgrading", "ends_at": "2024-01-01T02:00:00Z"}
+func (c *MaintenanceController) SetMaintenanceMode(ctx *fiber.Ctx) error {
+	req := new(MaintenanceRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing maintenance mode request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	mode := &models.MaintenanceMode{Enabled: *req.Enabled}
+	if mode.Enabled {
+		mode.Message = req.Message
+		mode.EndsAt = req.EndsAt
+	}
+	if err := c.SettingService.SetMaintenanceMode(ctx.UserContext(), mode); err != nil {
+		requestLogger(ctx).Error("Error setting maintenance mode", "enabled", mode.Enabled, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_set_maintenance_mode"),
+		})
+	}
+	c.MaintenanceMode.Set(mode)
+	requestLogger(ctx).Info("Maintenance mode changed", "enabled", mode.Enabled)
+
+	key := "maintenance_mode_disabled"
+	if mode.Enabled {
+		key = "maintenance_mode_enabled"
+	}
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, key),
+		"data":    mode,
+	})
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/i18n"
+	"github.com/anpsniper/anpbayu-be/middleware"
+)
+
+// msg returns the message with the given key (see i18n/locales) in the language of the request,
+// formatted with args.
+func msg(ctx *fiber.Ctx, key string, args ...interface{}) string {
+	return i18n.T(middleware.Language(ctx), key, args...)
+}
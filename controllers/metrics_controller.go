@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/anpsniper/anpbayu-be/jobs"
+)
+
+// MetricsController exposes runtime metrics of the backend, such as database pool statistics.
+type MetricsController struct {
+	DB        *sql.DB
+	Pool      *pgxpool.Pool        // nil unless the database is PostgreSQL
+	Cleanup   *jobs.CleanupMetrics // Expired rows deleted by the session-cleanup task
+	Retention *jobs.CleanupMetrics // Log entries purged by the log-retention task
+}
+
+// NewMetricsController creates and returns a new MetricsController instance.
+func NewMetricsController(db *sql.DB, pool *pgxpool.Pool, cleanup, retention *jobs.CleanupMetrics) *MetricsController {
+	return &MetricsController{
+		DB:        db,
+		Pool:      pool,
+		Cleanup:   cleanup,
+		Retention: retention,
+	}
+}
+
+// GetMetrics returns a snapshot of the database connection pool statistics, of the expired
+// rows deleted per table (see jobs.ExpiredCleanup) and of the log entries purged per table (see
+// jobs.LogRetention).
+// Acquire counts are cumulative since startup; durations are reported in milliseconds.
+// Without a pgx pool (SQLite/MySQL), the database/sql pool statistics are reported instead.
+// Example: GET /metrics
+func (c *MetricsController) GetMetrics(ctx *fiber.Ctx) error {
+	if c.Pool == nil {
+		stats := c.DB.Stats()
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data": fiber.Map{
+				"database_pool": fiber.Map{
+					"acquired_conns":      stats.InUse,
+					"idle_conns":          stats.Idle,
+					"total_conns":         stats.OpenConnections,
+					"max_conns":           stats.MaxOpenConnections,
+					"wait_count":          stats.WaitCount,
+					"wait_duration_ms":    stats.WaitDuration.Milliseconds(),
+					"max_idle_closed":     stats.MaxIdleClosed,
+					"max_lifetime_closed": stats.MaxLifetimeClosed,
+				},
+				"expired_cleanup": c.Cleanup.Snapshot(),
+				"log_retention":   c.Retention.Snapshot(),
+			},
+		})
+	}
+
+	stat := c.Pool.Stat()
+
+	var avgAcquireWaitMs float64
+	if waited := stat.EmptyAcquireCount(); waited > 0 {
+		// Only acquires that found the pool empty had to wait for a connection
+		avgAcquireWaitMs = float64(stat.AcquireDuration().Milliseconds()) / float64(waited)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"database_pool": fiber.Map{
+				"acquire_count":              stat.AcquireCount(),
+				"empty_acquire_count":        stat.EmptyAcquireCount(),
+				"canceled_acquire_count":     stat.CanceledAcquireCount(),
+				"acquire_duration_ms":        stat.AcquireDuration().Milliseconds(),
+				"avg_acquire_wait_ms":        avgAcquireWaitMs,
+				"acquired_conns":             stat.AcquiredConns(),
+				"idle_conns":                 stat.IdleConns(),
+				"constructing_conns":         stat.ConstructingConns(),
+				"total_conns":                stat.TotalConns(),
+				"max_conns":                  stat.MaxConns(),
+				"new_conns_count":            stat.NewConnsCount(),
+				"max_lifetime_destroy_count": stat.MaxLifetimeDestroyCount(),
+				"max_idle_destroy_count":     stat.MaxIdleDestroyCount(),
+			},
+			"expired_cleanup": c.Cleanup.Snapshot(),
+			"log_retention":   c.Retention.Snapshot(),
+		},
+	})
+}
+
+// prometheusHandler serves the metrics of the default Prometheus registry.
+var prometheusHandler = adaptor.HTTPHandler(promhttp.Handler())
+
+// GetPrometheusMetrics returns the metrics registered with Prometheus in its text format, for
+// scraping: the durations of the database statements by operation and the database pool
+// statistics (see database.RegisterMetrics), along with the Go runtime metrics.
+// Example: GET /metrics/prometheus
+func (c *MetricsController) GetPrometheusMetrics(ctx *fiber.Ctx) error {
+	return prometheusHandler(ctx)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// NotificationController handles the notifications of the authenticated user.
+type NotificationController struct {
+	NotificationService services.NotificationServiceInterface // NotificationService dependency (interface)
+}
+
+// NewNotificationController creates and returns a new NotificationController instance.
+func NewNotificationController(notificationService services.NotificationServiceInterface) *NotificationController {
+	return &NotificationController{
+		NotificationService: notificationService,
+	}
+}
+
+// UnreadCountResponse is the data of GET /api/notifications/unread-count.
+type UnreadCountResponse struct {
+	Unread int `json:"unread"`
+}
+
+// MarkAllReadResponse is the data of POST /api/notifications/read-all.
+type MarkAllReadResponse struct {
+	Marked int64 `json:"marked"` // Number of notifications that were unread
+}
+
+// GetNotifications lists the notifications of the authenticated user, newest first.
+// Example: GET /api/notifications?unread=true&page=1&limit=10
+func (c *NotificationController) GetNotifications(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+	unreadOnly := ctx.QueryBool("unread", false)
+	page, limit := paginationParams(ctx)
+
+	notificationList, totalPages, totalItems, err := c.NotificationService.GetNotifications(ctx.UserContext(), userID, unreadOnly, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching notifications", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_notifications"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "notifications_retrieved_successfully"),
+		"data":        notificationList,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetUnreadCount returns the number of unread notifications of the authenticated user, e.g.,
+// for a badge.
+func (c *NotificationController) GetUnreadCount(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	unread, err := c.NotificationService.CountUnread(ctx.UserContext(), userID)
+	if err != nil {
+		requestLogger(ctx).Error("Error counting unread notifications", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_notifications"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "notifications_retrieved_successfully"),
+		"data":    UnreadCountResponse{Unread: unread},
+	})
+}
+
+// MarkRead marks a notification of the authenticated user read.
+func (c *NotificationController) MarkRead(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	if err := c.NotificationService.MarkRead(ctx.UserContext(), userID, id); err != nil {
+		requestLogger(ctx).Error("Error marking notification read", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_mark_notifications_read"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "notification_marked_read"),
+	})
+}
+
+// MarkAllRead marks all notifications of the authenticated user read.
+func (c *NotificationController) MarkAllRead(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	marked, err := c.NotificationService.MarkAllRead(ctx.UserContext(), userID)
+	if err != nil {
+		requestLogger(ctx).Error("Error marking all notifications read", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_mark_notifications_read"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "notifications_marked_read"),
+		"data":    MarkAllReadResponse{Marked: marked},
+	})
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// OrderController handles placing and viewing orders.
+type OrderController struct {
+	OrderService   services.OrderServiceInterface
+	Currency       string        // Currency new orders are placed in
+	ReservationTTL time.Duration // How long an unpaid order holds its stock
+}
+
+// NewOrderController creates and returns a new OrderController instance.
+func NewOrderController(orderService services.OrderServiceInterface, currency string, reservationTTL time.Duration) *OrderController {
+	return &OrderController{
+		OrderService:   orderService,
+		Currency:       currency,
+		ReservationTTL: reservationTTL,
+	}
+}
+
+// CreateOrderRequest represents the expected structure for placing an order.
+type CreateOrderRequest struct {
+	Items []models.OrderItemRequest `json:"items" validate:"required,min=1,unique=ProductID,dive"` // Each product may only appear once per order
+}
+
+// GetMyOrders lists the authenticated user's orders (GET /api/orders).
+func (c *OrderController) GetMyOrders(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+	page, limit := paginationParams(ctx)
+
+	orders, totalPages, totalItems, err := c.OrderService.GetOrdersByUser(ctx.UserContext(), userID, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching orders for user", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_orders"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "orders_retrieved_successfully"),
+		"data":        orders,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetOrderByID retrieves a single order. Only its owner or an admin may see it.
+func (c *OrderController) GetOrderByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	order, err := c.OrderService.GetOrderByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching order", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_order"),
+		})
+	}
+	if order == nil || !canViewOrder(ctx, order) {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "order_not_found"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "order_retrieved_successfully"),
+		"data":    order,
+	})
+}
+
+// CreateOrder places a pending order for the authenticated user (POST /api/orders).
+func (c *OrderController) CreateOrder(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	req := new(CreateOrderRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create order request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	order := models.NewOrder(userID, c.Currency, c.ReservationTTL)
+	if err := c.OrderService.CreateOrder(ctx.UserContext(), order, req.Items); err != nil {
+		requestLogger(ctx).Error("Error creating order for user", "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_order"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "order_created_successfully"),
+		"data":    order,
+	})
+}
+
+// canViewOrder reports whether the authenticated user owns the order or is an admin.
+func canViewOrder(ctx *fiber.Ctx, order *models.Order) bool {
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	return order.UserID == userID || middleware.UserHasRole(ctx, "admin")
+}
@@ -0,0 +1,508 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/utils"
+)
+
+// OrganizationController handles organization-related requests. Organizations are visible to
+// their members and managed by their members with the models.OrganizationAdminRole role; admins
+// may see and manage every organization of the tenant.
+type OrganizationController struct {
+	OrganizationService services.OrganizationServiceInterface // OrganizationService dependency (interface)
+	UserService         services.UserServiceInterface         // Looks up the email addresses of users accepting invitations
+}
+
+// NewOrganizationController creates and returns a new OrganizationController instance.
+func NewOrganizationController(organizationService services.OrganizationServiceInterface, userService services.UserServiceInterface) *OrganizationController {
+	return &OrganizationController{
+		OrganizationService: organizationService,
+		UserService:         userService,
+	}
+}
+
+// OrganizationRequest represents the expected structure for creating or updating an organization.
+type OrganizationRequest struct {
+	Name string `json:"name" validate:"max=100"` // Required when creating; optional when updating
+	Slug string `json:"slug" validate:"max=120"` // Optional; derived from name when empty
+}
+
+// InvitationRequest represents the expected structure for inviting a user to an organization.
+type InvitationRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"required,max=50"` // Name of a role, e.g., "user"
+}
+
+// AcceptInvitationRequest represents the expected structure for accepting an invitation.
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required,len=64"`
+}
+
+// MemberRoleRequest represents the expected structure for assigning a role to a member.
+type MemberRoleRequest struct {
+	Role string `json:"role" validate:"required,max=50"` // Name of a role, e.g., "admin"
+}
+
+// GetMyOrganizations lists the organizations the authenticated user is a member of, with their
+// role in each.
+func (c *OrganizationController) GetMyOrganizations(ctx *fiber.Ctx) error {
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	page, limit := paginationParams(ctx)
+
+	organizations, totalPages, totalItems, err := c.OrganizationService.GetOrganizations(ctx.UserContext(), userID, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching organizations", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_organizations"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "organizations_retrieved_successfully"),
+		"data":        organizations,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetOrganizationByID retrieves a single organization the authenticated user is a member of.
+func (c *OrganizationController) GetOrganizationByID(ctx *fiber.Ctx) error {
+	organization, _, err := c.organizationAccess(ctx)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching organization", "id", ctx.Params("id"), "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_organization"),
+		})
+	}
+	if organization == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "organization_not_found"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_retrieved_successfully"),
+		"data":    organization,
+	})
+}
+
+// CreateOrganization creates an organization, managed by the authenticated user.
+func (c *OrganizationController) CreateOrganization(ctx *fiber.Ctx) error {
+	req := new(OrganizationRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create organization request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	slug := utils.Slugify(req.Slug)
+	if slug == "" {
+		slug = utils.Slugify(req.Name)
+	}
+	if req.Name == "" || slug == "" {
+		return invalidParam(ctx, "name", "required", "organization_name_is_required")
+	}
+
+	organization := &models.Organization{Name: req.Name, Slug: slug}
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	if err := c.OrganizationService.CreateOrganization(ctx.UserContext(), organization, userID); err != nil {
+		requestLogger(ctx).Error("Error creating organization", "name", req.Name, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_organization"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_created_successfully"),
+		"data":    organization,
+	})
+}
+
+// UpdateOrganization renames an organization and optionally changes its slug.
+func (c *OrganizationController) UpdateOrganization(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	organization, role, err := c.organizationAccess(ctx)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing organization for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_organization"),
+		})
+	}
+	if organization == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "organization_not_found"),
+		})
+	}
+	if !canManageOrganization(ctx, role) {
+		return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "organization_manage_forbidden"),
+		})
+	}
+
+	req := new(OrganizationRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update organization request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if req.Name != "" {
+		organization.Name = req.Name
+	}
+	if slug := utils.Slugify(req.Slug); slug != "" {
+		organization.Slug = slug
+	}
+	if err := c.OrganizationService.UpdateOrganization(ctx.UserContext(), organization); err != nil {
+		requestLogger(ctx).Error("Error updating organization", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_organization"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_updated_successfully"),
+		"data":    organization,
+	})
+}
+
+// DeleteOrganization deletes an organization with its memberships and invitations.
+func (c *OrganizationController) DeleteOrganization(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if handled, err := c.requireOrganizationManager(ctx); handled {
+		return err
+	}
+
+	if err := c.OrganizationService.DeleteOrganization(ctx.UserContext(), id); err != nil {
+		requestLogger(ctx).Error("Error deleting organization", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_organization"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_deleted_successfully"),
+	})
+}
+
+// GetMembers lists the members of an organization with their roles in it.
+func (c *OrganizationController) GetMembers(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	organization, _, err := c.organizationAccess(ctx)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching organization", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_organization"),
+		})
+	}
+	if organization == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "organization_not_found"),
+		})
+	}
+
+	members, err := c.OrganizationService.GetMembers(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching organization members", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_organization_members"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_members_retrieved_successfully"),
+		"data":    members,
+	})
+}
+
+// SetMemberRole assigns a role in the organization to one of its members.
+func (c *OrganizationController) SetMemberRole(ctx *fiber.Ctx) error {
+	id, userID := ctx.Params("id"), ctx.Params("userId")
+	if handled, err := c.requireOrganizationManager(ctx); handled {
+		return err
+	}
+
+	req := new(MemberRoleRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing member role request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if err := c.OrganizationService.SetMemberRole(ctx.UserContext(), id, userID, req.Role); err != nil {
+		requestLogger(ctx).Error("Error assigning organization role", "id", id, "user_id", userID, "role", req.Role, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_assign_organization_role"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_role_assigned_successfully"),
+	})
+}
+
+// RemoveMember removes a member from an organization. Members may leave on their own.
+func (c *OrganizationController) RemoveMember(ctx *fiber.Ctx) error {
+	id, userID := ctx.Params("id"), ctx.Params("userId")
+	if self, _ := middleware.GetUserIDFromJWT(ctx); self != userID {
+		if handled, err := c.requireOrganizationManager(ctx); handled {
+			return err
+		}
+	}
+
+	if err := c.OrganizationService.RemoveMember(ctx.UserContext(), id, userID); err != nil {
+		requestLogger(ctx).Error("Error removing organization member", "id", id, "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_remove_organization_member"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "organization_member_removed_successfully"),
+	})
+}
+
+// GetInvitations lists the pending invitations of an organization.
+func (c *OrganizationController) GetInvitations(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if handled, err := c.requireOrganizationManager(ctx); handled {
+		return err
+	}
+
+	invitations, err := c.OrganizationService.GetInvitations(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching invitations", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_invitations"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "invitations_retrieved_successfully"),
+		"data":    invitations,
+	})
+}
+
+// Invite invites an email address to an organization. The invitation is emailed when SMTP is
+// configured; the response carries its token, which is never shown again, so the link can be
+// passed on otherwise.
+func (c *OrganizationController) Invite(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if handled, err := c.requireOrganizationManager(ctx); handled {
+		return err
+	}
+
+	req := new(InvitationRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing invitation request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	invitation := &models.OrganizationInvitation{OrganizationID: id, Email: req.Email, RoleName: req.Role, InvitedBy: &userID}
+	if err := c.OrganizationService.Invite(ctx.UserContext(), invitation); err != nil {
+		requestLogger(ctx).Error("Error inviting to organization", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_invitation"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "invitation_created_successfully"),
+		"data":    invitation,
+	})
+}
+
+// DeleteInvitation withdraws a pending invitation to an organization.
+func (c *OrganizationController) DeleteInvitation(ctx *fiber.Ctx) error {
+	id, invitationID := ctx.Params("id"), ctx.Params("invitationId")
+	if handled, err := c.requireOrganizationManager(ctx); handled {
+		return err
+	}
+
+	if err := c.OrganizationService.DeleteInvitation(ctx.UserContext(), id, invitationID); err != nil {
+		requestLogger(ctx).Error("Error deleting invitation", "id", id, "invitation_id", invitationID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_invitation"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "invitation_deleted_successfully"),
+	})
+}
+
+// AcceptInvitation makes the authenticated user a member of the organization they were invited
+// to. The invitation must have been sent to the email address of their account.
+func (c *OrganizationController) AcceptInvitation(ctx *fiber.Ctx) error {
+	req := new(AcceptInvitationRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing accept invitation request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	user, err := c.UserService.GetUserByID(ctx.UserContext(), userID)
+	if err != nil || user == nil {
+		requestLogger(ctx).Error("Error fetching user accepting invitation", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_accept_invitation"),
+		})
+	}
+
+	organization, err := c.OrganizationService.AcceptInvitation(ctx.UserContext(), req.Token, user.ID, user.Email)
+	if err != nil {
+		requestLogger(ctx).Warn("Error accepting invitation", "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_accept_invitation"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "invitation_accepted_successfully"),
+		"data":    organization,
+	})
+}
+
+// organizationAccess fetches the organization with the ID of the route together with the
+// authenticated user's role in it. The organization is nil if it doesn't exist or the user is
+// neither a member nor an admin; the role is "" for admins who aren't members.
+func (c *OrganizationController) organizationAccess(ctx *fiber.Ctx) (*models.Organization, string, error) {
+	id := ctx.Params("id")
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+
+	organization, err := c.OrganizationService.GetOrganizationByID(ctx.UserContext(), id)
+	if err != nil || organization == nil {
+		return nil, "", err
+	}
+	role, err := c.OrganizationService.GetMemberRole(ctx.UserContext(), id, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" && !middleware.UserHasRole(ctx, "admin") {
+		return nil, "", nil // Hidden from non-members
+	}
+	organization.Role = role
+	return organization, role, nil
+}
+
+// requireOrganizationManager answers the request itself, reporting handled, unless the
+// authenticated user may manage the organization with the ID of the route.
+func (c *OrganizationController) requireOrganizationManager(ctx *fiber.Ctx) (bool, error) {
+	organization, role, err := c.organizationAccess(ctx)
+	switch {
+	case err != nil:
+		requestLogger(ctx).Error("Error fetching organization", "id", ctx.Params("id"), "error", err)
+		return true, ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_organization"),
+		})
+	case organization == nil:
+		return true, ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "organization_not_found"),
+		})
+	case !canManageOrganization(ctx, role):
+		return true, ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "organization_manage_forbidden"),
+		})
+	}
+	return false, nil
+}
+
+// canManageOrganization reports whether the authenticated user, who has role in an
+// organization, may change it and its members: its admins and the admins of the tenant may.
+func canManageOrganization(ctx *fiber.Ctx, role string) bool {
+	return role == models.OrganizationAdminRole || middleware.UserHasRole(ctx, "admin")
+}
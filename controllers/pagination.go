@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// paginationParams reads the page and limit query parameters of a list endpoint.
+// A missing or invalid limit falls back to the configured default page size and larger
+// limits are capped at the configured maximum (PAGE_SIZE_DEFAULT and PAGE_SIZE_MAX).
+func paginationParams(ctx *fiber.Ctx) (page, limit int) {
+	page, err := strconv.Atoi(ctx.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err = strconv.Atoi(ctx.Query("limit", strconv.Itoa(config.AppConfig.DefaultPageSize)))
+	if err != nil || limit < 1 {
+		limit = config.AppConfig.DefaultPageSize
+	}
+	if limit > config.AppConfig.MaxPageSize {
+		limit = config.AppConfig.MaxPageSize
+	}
+	return page, limit
+}
+
+// timeRangeParams reads the from and to query parameters of a list endpoint, times in RFC 3339
+// format; missing ones are nil. invalid is the name of the first one that can't be parsed, if
+// any, to be answered with the "invalid_time_param" message.
+func timeRangeParams(ctx *fiber.Ctx) (from, to *time.Time, invalid string) {
+	bounds := []struct {
+		param string
+		time  **time.Time
+	}{{"from", &from}, {"to", &to}}
+	for _, bound := range bounds {
+		value := ctx.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, nil, bound.param
+		}
+		*bound.time = &t
+	}
+	return from, to, ""
+}
+
+// cursorParam reads the cursor query parameter of a list endpoint that can be paginated by
+// keyset as well as by page. keyset reports whether the parameter was given at all: an empty
+// cursor asks for the first page, and without one the endpoint pages by offset. ok is false if
+// the cursor can't be decoded, to be answered with the "invalid_cursor" message.
+func cursorParam(ctx *fiber.Ctx) (cursor *models.Cursor, keyset, ok bool) {
+	if !ctx.Context().QueryArgs().Has("cursor") {
+		return nil, false, true
+	}
+	value := ctx.Query("cursor")
+	if value == "" {
+		return nil, true, true
+	}
+	cursor, err := models.ParseCursor(value)
+	if err != nil {
+		return nil, true, false
+	}
+	return cursor, true, true
+}
+
+// nextCursor encodes the cursor of the next page for the response of a list paginated by
+// keyset: null on the last page.
+func nextCursor(cursor *models.Cursor) interface{} {
+	if cursor == nil {
+		return nil
+	}
+	return cursor.String()
+}
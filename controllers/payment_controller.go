@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/payments"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// PaymentController handles checkout initiation and payment provider webhooks.
+type PaymentController struct {
+	OrderService   services.OrderServiceInterface
+	PaymentService services.PaymentServiceInterface
+	Providers      payments.Registry
+	ReturnURL      string // Frontend URL customers return to; the order ID is appended
+}
+
+// NewPaymentController creates and returns a new PaymentController instance.
+func NewPaymentController(orderService services.OrderServiceInterface, paymentService services.PaymentServiceInterface, providers payments.Registry, returnURL string) *PaymentController {
+	return &PaymentController{
+		OrderService:   orderService,
+		PaymentService: paymentService,
+		Providers:      providers,
+		ReturnURL:      strings.TrimRight(returnURL, "/"),
+	}
+}
+
+// CheckoutRequest represents the expected structure for starting a checkout.
+type CheckoutRequest struct {
+	Provider string `json:"provider" validate:"required"` // Payment provider name (e.g., "stripe", "midtrans")
+}
+
+// Checkout starts a hosted checkout for an order (POST /api/orders/:id/checkout)
+// and returns the URL the customer must be redirected to.
+func (c *PaymentController) Checkout(ctx *fiber.Ctx) error {
+	orderID := ctx.Params("id")
+
+	req := new(CheckoutRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing checkout request body for order", "order_id", orderID, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	provider, err := c.Providers.Get(req.Provider)
+	if err != nil {
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "payment_provider_not_available"),
+		})
+	}
+
+	order, err := c.OrderService.GetOrderByID(ctx.UserContext(), orderID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching order for checkout", "order_id", orderID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_order"),
+		})
+	}
+	if order == nil || !canViewOrder(ctx, order) {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "order_not_found"),
+		})
+	}
+	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusFailed {
+		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "order_not_checkoutable"),
+		})
+	}
+	if order.ReservedUntil != nil && order.ReservedUntil.Before(time.Now()) {
+		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "order_reservation_expired"),
+		})
+	}
+
+	payment := &models.Payment{
+		OrderID:  order.ID,
+		Provider: provider.Name(),
+		Amount:   order.TotalAmount,
+		Currency: order.Currency,
+	}
+	if err := c.PaymentService.CreatePayment(ctx.UserContext(), payment); err != nil {
+		requestLogger(ctx).Error("Error creating payment for order", "order_id", orderID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_start_checkout"),
+		})
+	}
+
+	returnURL := c.ReturnURL + "/" + order.ID
+	session, err := provider.CreateCheckout(ctx.UserContext(), payments.CheckoutRequest{
+		PaymentID:   payment.ID,
+		OrderID:     order.ID,
+		Amount:      order.TotalAmount,
+		Currency:    order.Currency,
+		Description: "Order " + order.ID,
+		SuccessURL:  returnURL,
+		CancelURL:   returnURL,
+	})
+	if err != nil {
+		requestLogger(ctx).Error("Error creating checkout for order", "provider", provider.Name(), "order_id", orderID, "error", err)
+		return ctx.Status(http.StatusBadGateway).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "payment_provider_unavailable"),
+		})
+	}
+
+	payment.ProviderRef = session.ProviderRef
+	payment.CheckoutURL = session.RedirectURL
+	if err := c.PaymentService.AttachCheckout(ctx.UserContext(), payment); err != nil {
+		requestLogger(ctx).Error("Error saving checkout for payment", "payment_id", payment.ID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_start_checkout"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "checkout_started_successfully"),
+		"data":    payment,
+	})
+}
+
+// GetOrderPayments lists the checkout attempts of an order (GET /api/orders/:id/payments).
+func (c *PaymentController) GetOrderPayments(ctx *fiber.Ctx) error {
+	orderID := ctx.Params("id")
+
+	order, err := c.OrderService.GetOrderByID(ctx.UserContext(), orderID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching order for payments", "order_id", orderID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_order"),
+		})
+	}
+	if order == nil || !canViewOrder(ctx, order) {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "order_not_found"),
+		})
+	}
+
+	paymentList, err := c.PaymentService.GetPaymentsByOrderID(ctx.UserContext(), orderID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching payments for order", "order_id", orderID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_payments"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "payments_retrieved_successfully"),
+		"data":    paymentList,
+	})
+}
+
+// HandleWebhook receives payment notifications (POST /webhooks/payments/:provider).
+// The request is authenticated by the provider's signature, not by a JWT.
+func (c *PaymentController) HandleWebhook(ctx *fiber.Ctx) error {
+	provider, err := c.Providers.Get(ctx.Params("provider"))
+	if err != nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "unknown_payment_provider"),
+		})
+	}
+
+	event, err := provider.ParseWebhook(http.Header(ctx.GetReqHeaders()), ctx.Body())
+	if err != nil {
+		requestLogger(ctx).Warn("Rejected webhook", "provider", provider.Name(), "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_webhook"),
+		})
+	}
+	if event == nil {
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": msg(ctx, "event_ignored"),
+		})
+	}
+
+	payment, err := c.PaymentService.ApplyPaymentEvent(ctx.UserContext(), provider.Name(), event.ProviderRef, event.Status)
+	if err != nil {
+		requestLogger(ctx).Error("Error applying payment event", "provider", provider.Name(), "event_type", event.Type, "provider_ref", event.ProviderRef, "error", err)
+		if errors.Is(err, services.ErrNotFound) {
+			// Acknowledge events for checkouts we did not create, so the provider stops retrying
+			return ctx.Status(http.StatusOK).JSON(fiber.Map{
+				"success": true,
+				"message": msg(ctx, "event_ignored"),
+			})
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_process_webhook"),
+		})
+	}
+
+	requestLogger(ctx).Info("Payment status changed", "payment_id", payment.ID, "order_id", payment.OrderID, "payment_status", payment.Status, "provider", provider.Name(), "event_type", event.Type)
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "webhook_processed_successfully"),
+	})
+}
@@ -0,0 +1,316 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/markdown"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// PostController handles post-related requests.
+type PostController struct {
+	PostService services.PostServiceInterface // PostService dependency (interface)
+}
+
+// NewPostController creates and returns a new PostController instance.
+func NewPostController(postService services.PostServiceInterface) *PostController {
+	return &PostController{
+		PostService: postService,
+	}
+}
+
+// GetAllPosts retrieves posts with search, tag/category filtering and pagination.
+// Example: GET /api/posts?tag=go&category=news&page=1&limit=10&render=html
+func (c *PostController) GetAllPosts(ctx *fiber.Ctx) error {
+	search := ctx.Query("search", "")
+	tag := ctx.Query("tag", "")           // Tag slug
+	category := ctx.Query("category", "") // Category slug
+	page, limit := paginationParams(ctx)
+	fields, invalid := fieldsParam(ctx, models.Post{})
+	if invalid != "" {
+		return invalidParam(ctx, "fields", "oneof", "invalid_field", invalid)
+	}
+
+	posts, totalPages, totalItems, err := c.PostService.GetAllPosts(ctx.UserContext(), search, tag, category, fields, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching all posts", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_posts"),
+		})
+	}
+
+	if wantsRenderedHTML(ctx) {
+		for i := range posts {
+			if posts[i].ContentHTML, err = markdown.RenderHTML(posts[i].Content); err != nil {
+				requestLogger(ctx).Error("Error rendering post", "post_id", posts[i].ID, "error", err)
+				return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+					"success": false,
+					"message": msg(ctx, "failed_to_render_posts"),
+				})
+			}
+		}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "posts_retrieved_successfully"),
+		"data":        selectFields(posts, fields),
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetPostByID retrieves a single post by its ID. Pass ?render=html to also get sanitized HTML.
+func (c *PostController) GetPostByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	fields, invalid := fieldsParam(ctx, models.Post{})
+	if invalid != "" {
+		return invalidParam(ctx, "fields", "oneof", "invalid_field", invalid)
+	}
+
+	post, err := c.PostService.GetPostByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching post", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_post"),
+		})
+	}
+	if post == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_not_found"),
+		})
+	}
+
+	if wantsRenderedHTML(ctx) {
+		if post.ContentHTML, err = markdown.RenderHTML(post.Content); err != nil {
+			requestLogger(ctx).Error("Error rendering post", "id", id, "error", err)
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "failed_to_render_post"),
+			})
+		}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "post_retrieved_successfully"),
+		"data":    selectFields(post, fields),
+	})
+}
+
+// PostRequest represents the expected structure for creating or updating a post.
+type PostRequest struct {
+	Title      string   `json:"title" validate:"required,max=255"`
+	Content    string   `json:"content" validate:"required"`
+	CategoryID *string  `json:"category_id" validate:"omitempty,uuid"` // Optional; null or omitted leaves the post uncategorized
+	Tags       []string `json:"tags" validate:"dive,required,max=50"`  // Tag names; unknown tags are created automatically
+	// Publication: published_at sets an explicit (possibly future) time, while published
+	// true/false publishes now or reverts to a draft. Omitting both leaves the state unchanged.
+	Published   *bool      `json:"published"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// CreatePost creates a new post authored by the authenticated user.
+func (c *PostController) CreatePost(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	req := new(PostRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create post request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	newPost := models.NewPost(userID, req.Title, req.Content)
+	newPost.CategoryID = emptyToNil(req.CategoryID)
+	if req.Tags != nil {
+		newPost.Tags = req.Tags
+	}
+	applyPublication(newPost, req)
+
+	if err := c.PostService.CreatePost(ctx.UserContext(), newPost); err != nil {
+		requestLogger(ctx).Error("Error creating post", "title", req.Title, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_post"),
+		})
+	}
+
+	// Reload to return the resolved category name and normalized tags
+	created, err := c.PostService.GetPostByID(ctx.UserContext(), newPost.ID)
+	if err != nil || created == nil {
+		created = newPost
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "post_created_successfully"),
+		"data":    created,
+	})
+}
+
+// UpdatePost updates a post. Only the author or an admin may update it.
+func (c *PostController) UpdatePost(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	existingPost, err := c.PostService.GetPostByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing post for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_post_for_update"),
+		})
+	}
+	if existingPost == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_not_found_for_update"),
+		})
+	}
+	if !canModifyPost(ctx, existingPost) {
+		return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_modify_forbidden"),
+		})
+	}
+
+	req := new(PostRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update post request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	existingPost.Title = req.Title
+	existingPost.Content = req.Content
+	existingPost.CategoryID = emptyToNil(req.CategoryID)
+	if req.Tags != nil {
+		existingPost.Tags = req.Tags
+	}
+	applyPublication(existingPost, req)
+
+	if err := c.PostService.UpdatePost(ctx.UserContext(), existingPost); err != nil {
+		requestLogger(ctx).Error("Error updating post", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_post"),
+		})
+	}
+
+	updated, err := c.PostService.GetPostByID(ctx.UserContext(), id)
+	if err != nil || updated == nil {
+		updated = existingPost
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "post_updated_successfully"),
+		"data":    updated,
+	})
+}
+
+// DeletePost deletes a post. Only the author or an admin may delete it.
+func (c *PostController) DeletePost(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	existingPost, err := c.PostService.GetPostByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing post for deletion", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_post"),
+		})
+	}
+	if existingPost == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_not_found"),
+		})
+	}
+	if !canModifyPost(ctx, existingPost) {
+		return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "post_delete_forbidden"),
+		})
+	}
+
+	err = c.PostService.DeletePost(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error deleting post", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_post"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "post_deleted_successfully"),
+	})
+}
+
+// wantsRenderedHTML reports whether the client asked for sanitized HTML alongside the Markdown source (?render=html).
+func wantsRenderedHTML(ctx *fiber.Ctx) bool {
+	return ctx.Query("render") == "html"
+}
+
+// canModifyPost reports whether the authenticated user is the post's author or an admin.
+func canModifyPost(ctx *fiber.Ctx, post *models.Post) bool {
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	return post.UserID == userID || middleware.UserHasRole(ctx, "admin")
+}
+
+// applyPublication updates a post's publication time from the request's published/published_at fields.
+func applyPublication(post *models.Post, req *PostRequest) {
+	switch {
+	case req.PublishedAt != nil:
+		post.PublishedAt = req.PublishedAt
+	case req.Published != nil && !*req.Published:
+		post.PublishedAt = nil
+	case req.Published != nil && post.PublishedAt == nil:
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+}
+
+// emptyToNil treats an empty string pointer the same as an omitted value.
+func emptyToNil(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
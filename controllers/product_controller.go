@@ -0,0 +1,480 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// ProductController handles product-related requests.
+type ProductController struct {
+	ProductService services.ProductServiceInterface // ProductService dependency (interface)
+	Storage        storage.Storage                  // Where product images are kept
+	MaxSize        int64                            // Maximum accepted image size in bytes
+}
+
+// NewProductController creates and returns a new ProductController instance.
+func NewProductController(productService services.ProductServiceInterface, store storage.Storage, maxSize int64) *ProductController {
+	return &ProductController{
+		ProductService: productService,
+		Storage:        store,
+		MaxSize:        maxSize,
+	}
+}
+
+// GetAllProducts retrieves products with search, filtering, sorting and pagination.
+// Example: GET /api/products?min_price=10&max_price=100&stock_status=in_stock&category=books&sort=-price,name
+// Archived products are excluded unless an admin asks for them with archived=true or archived=all.
+// Asked for a spreadsheet (see spreadsheetFormat), it answers every matching product instead of a page.
+func (c *ProductController) GetAllProducts(ctx *fiber.Ctx) error {
+	page, limit := paginationParams(ctx)
+
+	filter := models.ProductFilter{
+		Search:      ctx.Query("search", ""),
+		Category:    ctx.Query("category", ""),
+		StockStatus: ctx.Query("stock_status", ""),
+		Archived:    ctx.Query("archived", models.ArchivedExclude),
+	}
+	var invalid string
+	if filter.Fields, invalid = fieldsParam(ctx, models.Product{}); invalid != "" {
+		return invalidParam(ctx, "fields", "oneof", "invalid_field", invalid)
+	}
+
+	switch filter.Archived {
+	case models.ArchivedExclude:
+	case models.ArchivedOnly, models.ArchivedInclude:
+		if !middleware.UserHasRole(ctx, "admin") {
+			return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "only_admins_can_list_archived_products"),
+			})
+		}
+	default:
+		return invalidParam(ctx, "archived", "oneof", "invalid_archived_filter")
+	}
+
+	for param, target := range map[string]**decimal.Decimal{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		if raw := ctx.Query(param); raw != "" {
+			value, err := decimal.NewFromString(raw)
+			if err != nil || value.IsNegative() {
+				return invalidParam(ctx, param, "number", "invalid_non_negative_number", param)
+			}
+			*target = &value
+		}
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && filter.MinPrice.GreaterThan(*filter.MaxPrice) {
+		return invalidParam(ctx, "min_price", "ltefield", "invalid_price_range")
+	}
+
+	switch filter.StockStatus {
+	case "", models.StockStatusInStock, models.StockStatusOutOfStock, models.StockStatusLowStock:
+	default:
+		return invalidParam(ctx, "stock_status", "oneof", "invalid_stock_status")
+	}
+
+	if sort := ctx.Query("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				filter.Sort = append(filter.Sort, field)
+			}
+		}
+		if err := services.ValidateProductSort(filter.Sort); err != nil {
+			return validationFailed(ctx, []FieldError{{Field: "sort", Code: "oneof", Message: capitalize(err.Error())}})
+		}
+	}
+
+	if format, invalid := spreadsheetFormat(ctx); invalid != "" {
+		return invalidParam(ctx, "format", "oneof", "unsupported_export_format", invalid)
+	} else if format != "" {
+		filter.Fields = nil // Spreadsheets have every column
+		return streamFile(ctx, "products", format, productExportHeader, c.exportProducts(filter))
+	}
+
+	products, totalPages, totalItems, err := c.ProductService.GetAllProducts(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching all products", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_products"),
+		})
+	}
+	for i := range products {
+		products[i].ImageURL = fileURL(c.Storage, products[i].ImageKey)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "products_retrieved_successfully"),
+		"data":        selectFields(products, filter.Fields),
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// productExportHeader is the header of the spreadsheets of products. Images are left out, as their
+// URLs expire.
+var productExportHeader = []string{"id", "name", "description", "price", "stock", "category", "archived_at", "version", "created_at", "updated_at"}
+
+// exportProducts returns the writer of the products matching filter to a spreadsheet, page by page.
+func (c *ProductController) exportProducts(filter models.ProductFilter) func(ctx context.Context, out exportWriter) error {
+	return func(ctx context.Context, out exportWriter) error {
+		return eachPage(func(page int) (int, error) {
+			products, totalPages, _, err := c.ProductService.GetAllProducts(ctx, filter, page, spreadsheetPageSize)
+			if err != nil {
+				return 0, err
+			}
+			for _, p := range products {
+				row := []interface{}{p.ID, p.Name, p.Description, p.Price, p.Stock, p.Category, orNil(p.ArchivedAt), p.Version, p.CreatedAt, p.UpdatedAt}
+				if err := out.Write(p, row); err != nil {
+					return 0, err
+				}
+			}
+			return totalPages, nil
+		})
+	}
+}
+
+// GetProductByID retrieves a single product by its ID.
+func (c *ProductController) GetProductByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	fields, invalid := fieldsParam(ctx, models.Product{})
+	if invalid != "" {
+		return invalidParam(ctx, "fields", "oneof", "invalid_field", invalid)
+	}
+
+	product, err := c.ProductService.GetProductByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching product", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_product"),
+		})
+	}
+	if product == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "product_not_found"),
+		})
+	}
+	product.ImageURL = fileURL(c.Storage, product.ImageKey)
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "product_retrieved_successfully"),
+		"data":    selectFields(product, fields),
+	})
+}
+
+// CreateProduct creates a new product.
+func (c *ProductController) CreateProduct(ctx *fiber.Ctx) error {
+	req := new(models.ProductCreateRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create product request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	newProduct := models.NewProduct(req.Name, req.Description, req.Category, req.Price, req.Stock)
+	if err := c.ProductService.CreateProduct(ctx.UserContext(), newProduct); err != nil {
+		requestLogger(ctx).Error("Error creating product", "name", req.Name, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_product"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "product_created_successfully"),
+		"data":    newProduct,
+	})
+}
+
+// UpdateProductRequest represents the expected structure for updating an existing product.
+// Stock is changed through stock adjustments only.
+type UpdateProductRequest struct {
+	Name        *string          `json:"name" validate:"omitnil,min=1,max=255"` // Use pointer to differentiate between zero value and not provided
+	Description *string          `json:"description"`
+	Price       *decimal.Decimal `json:"price" validate:"omitnil,price"`
+	Category    *string          `json:"category" validate:"omitnil,max=100"`
+	Version     *int             `json:"version" validate:"omitnil,gte=1"` // Version the client last read; a stale version is rejected with 409 Conflict
+}
+
+// UpdateProduct updates an existing product's information.
+func (c *ProductController) UpdateProduct(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	existingProduct, err := c.ProductService.GetProductByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing product for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_product_for_update"),
+		})
+	}
+	if existingProduct == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "product_not_found_for_update"),
+		})
+	}
+
+	req := new(UpdateProductRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update product request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if req.Name != nil {
+		existingProduct.Name = *req.Name
+	}
+	if req.Description != nil {
+		existingProduct.Description = *req.Description
+	}
+	if req.Category != nil {
+		existingProduct.Category = *req.Category
+	}
+	if req.Price != nil {
+		existingProduct.Price = *req.Price
+	}
+	if req.Version != nil {
+		existingProduct.Version = *req.Version
+	}
+
+	if err := c.ProductService.UpdateProduct(ctx.UserContext(), existingProduct); err != nil {
+		requestLogger(ctx).Error("Error updating product", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_product"),
+		})
+	}
+	existingProduct.ImageURL = fileURL(c.Storage, existingProduct.ImageKey)
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "product_updated_successfully"),
+		"data":    existingProduct,
+	})
+}
+
+// DeleteProduct moves a product to the trash by its ID.
+func (c *ProductController) DeleteProduct(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.ProductService.DeleteProduct(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error deleting product", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_product"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "product_deleted_successfully"),
+	})
+}
+
+// ArchiveProduct hides a product from listings and new orders (POST /api/products/:id/archive).
+func (c *ProductController) ArchiveProduct(ctx *fiber.Ctx) error {
+	return c.setArchived(ctx, true)
+}
+
+// UnarchiveProduct restores an archived product (POST /api/products/:id/unarchive).
+func (c *ProductController) UnarchiveProduct(ctx *fiber.Ctx) error {
+	return c.setArchived(ctx, false)
+}
+
+func (c *ProductController) setArchived(ctx *fiber.Ctx, archived bool) error {
+	id := ctx.Params("id")
+
+	var product *models.Product
+	var err error
+	if archived {
+		product, err = c.ProductService.ArchiveProduct(ctx.UserContext(), id)
+	} else {
+		product, err = c.ProductService.UnarchiveProduct(ctx.UserContext(), id)
+	}
+	if err != nil {
+		requestLogger(ctx).Error("Error changing archive state of product", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_product"),
+		})
+	}
+
+	product.ImageURL = fileURL(c.Storage, product.ImageKey)
+
+	message := msg(ctx, "product_unarchived_successfully")
+	if archived {
+		message = msg(ctx, "product_archived_successfully")
+	}
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    product,
+	})
+}
+
+// UploadImage handles POST /api/products/:id/image with a multipart "file" field, replacing
+// the product's image.
+func (c *ProductController) UploadImage(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	file, err := receiveUpload(ctx, "file", c.MaxSize, allowedImageTypes)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	key := fmt.Sprintf("products/%s/%s%s", id, uuid.New().String(), file.Ext)
+	if err := c.Storage.Save(ctx.UserContext(), key, file, file.Size, file.ContentType); err != nil {
+		requestLogger(ctx).Error("Error storing image of product", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_store_file"),
+		})
+	}
+	return c.setImage(ctx, id, &key, "product_image_uploaded_successfully")
+}
+
+// DeleteImage handles DELETE /api/products/:id/image, removing the product's image.
+func (c *ProductController) DeleteImage(ctx *fiber.Ctx) error {
+	return c.setImage(ctx, ctx.Params("id"), nil, "product_image_removed_successfully")
+}
+
+// setImage points the product at the image stored under key (nil removes the image), deletes
+// the replaced file and responds with the product. A newly stored file is deleted again when
+// the product can't be changed.
+func (c *ProductController) setImage(ctx *fiber.Ctx, id string, key *string, messageKey string) error {
+	previous, err := c.ProductService.SetProductImage(ctx.UserContext(), id, key)
+	if err != nil {
+		requestLogger(ctx).Error("Error changing image of product", "id", id, "error", err)
+		removeStoredFile(ctx, c.Storage, key)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_product_image"),
+		})
+	}
+	removeStoredFile(ctx, c.Storage, previous)
+
+	product, err := c.ProductService.GetProductByID(ctx.UserContext(), id)
+	if err != nil || product == nil {
+		requestLogger(ctx).Error("Error fetching product after image change", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_product"),
+		})
+	}
+	product.ImageURL = fileURL(c.Storage, product.ImageKey)
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, messageKey),
+		"data":    product,
+	})
+}
+
+// StockAdjustmentRequest represents the expected structure for adjusting a product's stock.
+type StockAdjustmentRequest struct {
+	Delta  int    `json:"delta" validate:"ne=0"`              // Amount to add (positive) or remove (negative)
+	Reason string `json:"reason" validate:"required,max=255"` // Why the stock changed (e.g., "restock", "damaged", "correction")
+}
+
+// AdjustStock handles POST /api/products/:id/stock-adjustments.
+func (c *ProductController) AdjustStock(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	req := new(StockAdjustmentRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing stock adjustment request body for product", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	actorID, _ := middleware.GetUserIDFromJWT(ctx)
+	movement, err := c.ProductService.AdjustStock(ctx.UserContext(), id, req.Delta, req.Reason, actorID)
+	if err != nil {
+		requestLogger(ctx).Error("Error adjusting stock for product", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_adjust_stock"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "stock_adjusted_successfully"),
+		"data":    movement,
+	})
+}
+
+// GetStockMovements lists the stock adjustment history of a product (GET /api/products/:id/stock-adjustments).
+func (c *ProductController) GetStockMovements(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	page, limit := paginationParams(ctx)
+
+	movements, totalPages, totalItems, err := c.ProductService.GetStockMovements(ctx.UserContext(), id, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching stock movements for product", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_stock_adjustments"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "stock_adjustments_retrieved_successfully"),
+		"data":        movements,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
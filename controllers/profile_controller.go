@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// maxPreferencesSize is the largest preferences object accepted, in bytes.
+const maxPreferencesSize = 16 << 10
+
+// ProfileController handles the requests of users about their own account.
+type ProfileController struct {
+	UserService services.UserServiceInterface // UserService dependency (interface)
+	Storage     storage.Storage               // Storage of the avatars
+}
+
+// NewProfileController creates and returns a new ProfileController instance.
+func NewProfileController(userService services.UserServiceInterface, store storage.Storage) *ProfileController {
+	return &ProfileController{
+		UserService: userService,
+		Storage:     store,
+	}
+}
+
+// GetProfile returns the account of the authenticated user, with their role, latest login and
+// preferences. A token of a user deleted since it was issued is answered with 401.
+func (c *ProfileController) GetProfile(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	profile, err := c.UserService.GetUserProfile(ctx.UserContext(), userID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching profile", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_profile"),
+		})
+	}
+	if profile == nil {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_no_longer_exists"),
+		})
+	}
+	profile.AvatarURL = fileURL(c.Storage, profile.AvatarKey)
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "profile_retrieved_successfully"),
+		"data":    profile,
+	})
+}
+
+// UpdatePreferences replaces the preferences of the authenticated user with the JSON object of
+// the request body.
+// Example: PUT /api/profile/preferences {"theme": "dark", "language": "id"}
+func (c *ProfileController) UpdatePreferences(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	var preferences map[string]json.RawMessage
+	body := ctx.Body()
+	if len(body) > maxPreferencesSize || json.Unmarshal(body, &preferences) != nil || preferences == nil {
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_preferences"),
+		})
+	}
+	data, err := json.Marshal(preferences) // Compacted
+	if err != nil {
+		return err
+	}
+
+	if err := c.UserService.UpdateUserPreferences(ctx.UserContext(), userID, data); err != nil {
+		requestLogger(ctx).Error("Error updating preferences", "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_preferences"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "preferences_updated_successfully"),
+		"data":    json.RawMessage(data),
+	})
+}
+
+// GetLogins lists the logins of the authenticated user, newest first, optionally between the
+// from and to times of the query.
+// Example: GET /api/profile/logins?from=2024-01-01T00:00:00Z&page=1
+func (c *ProfileController) GetLogins(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+	filter := models.UserLogFilter{UserID: userID}
+	var invalid string
+	if filter.From, filter.To, invalid = timeRangeParams(ctx); invalid != "" {
+		return invalidParam(ctx, invalid, "datetime", "invalid_time_param", invalid)
+	}
+	page, limit := paginationParams(ctx)
+
+	logs, totalPages, totalItems, err := c.UserService.GetUserLogs(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching logins", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_user_logs"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "user_logs_retrieved_successfully"),
+		"data":        logs,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/quota"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// QuotaController shows admins how much of their request quotas users have used, and lets
+// them reset it, e.g., for a user locked out by a runaway script.
+type QuotaController struct {
+	Tracker     *quota.Tracker                // Tracker of the quota middleware
+	UserService services.UserServiceInterface // Resolves users within the tenant of the admin
+}
+
+// NewQuotaController creates and returns a new QuotaController instance.
+func NewQuotaController(tracker *quota.Tracker, userService services.UserServiceInterface) *QuotaController {
+	return &QuotaController{Tracker: tracker, UserService: userService}
+}
+
+// userInTenant responds with 404 unless the user exists in the tenant of the admin, as the
+// quotas of users in other tenants are none of their business. It reports whether the
+// request may go on.
+func (c *QuotaController) userInTenant(ctx *fiber.Ctx, userID string) (bool, error) {
+	user, err := c.UserService.GetUserByID(ctx.UserContext(), userID)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching user", "id", userID, "error", err)
+		return false, ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_user"),
+		})
+	}
+	if user == nil {
+		return false, ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_not_found"),
+		})
+	}
+	return true, nil
+}
+
+// GetUsage returns the usage of a user in the current hour and day, under the quotas in effect.
+// Example: GET /api/admin/quotas/:userId
+func (c *QuotaController) GetUsage(ctx *fiber.Ctx) error {
+	userID := ctx.Params("userId")
+	if ok, err := c.userInTenant(ctx, userID); !ok {
+		return err
+	}
+
+	usage, err := c.Tracker.Usage(ctx.UserContext(), middleware.QuotaSubject(userID), middleware.QuotaLimits(config.Current()))
+	if err != nil {
+		requestLogger(ctx).Error("Error reading quota usage", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_quota_usage"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "quota_usage_retrieved_successfully"),
+		"data":    usage,
+	})
+}
+
+// ResetUsage forgets the requests of a user in the current hour and day.
+// Example: DELETE /api/admin/quotas/:userId
+func (c *QuotaController) ResetUsage(ctx *fiber.Ctx) error {
+	userID := ctx.Params("userId")
+	if ok, err := c.userInTenant(ctx, userID); !ok {
+		return err
+	}
+
+	if err := c.Tracker.Reset(ctx.UserContext(), middleware.QuotaSubject(userID)); err != nil {
+		requestLogger(ctx).Error("Error resetting quota usage", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_reset_quota_usage"),
+		})
+	}
+
+	requestLogger(ctx).Info("Quota usage reset", "user_id", userID)
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "quota_usage_reset_successfully"),
+	})
+}
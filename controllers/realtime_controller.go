@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/realtime"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+const (
+	wsWriteWait    = 10 * time.Second    // Time allowed to write a message to the client
+	wsPongWait     = 60 * time.Second    // Time allowed to read the next pong from the client
+	wsPingInterval = wsPongWait * 9 / 10 // Send pings to the client with this period; must be less than wsPongWait
+	wsMaxMessage   = 512                 // Maximum size of messages from the client, which are ignored anyway
+
+	sseHeartbeatInterval = 20 * time.Second // Send a comment to event stream clients with this period, so proxies keep the connection open
+	sseRetry             = 3 * time.Second  // Delay before event stream clients reconnect
+	sseMaxReplayed       = 100              // Maximum number of missed events sent to reconnecting event stream clients
+
+	sessionCheckInterval = time.Minute // Check the session of connected clients with this period, so logging out or revoking it disconnects them
+)
+
+// RealtimeController pushes events to connected clients, over WebSockets or Server-Sent Events,
+// and lets admins broadcast announcements to them.
+type RealtimeController struct {
+	Hub                 *realtime.Hub                         // Hub dependency
+	AnnouncementService services.AnnouncementServiceInterface // AnnouncementService dependency (interface)
+	EventService        services.EventServiceInterface        // EventService dependency (interface)
+	Sessions            services.SessionServiceInterface      // Sessions of connected clients, checked every sessionCheckInterval; nil skips the checks
+}
+
+// NewRealtimeController creates and returns a new RealtimeController instance.
+func NewRealtimeController(hub *realtime.Hub, announcementService services.AnnouncementServiceInterface, eventService services.EventServiceInterface, sessions services.SessionServiceInterface) *RealtimeController {
+	return &RealtimeController{
+		Hub:                 hub,
+		AnnouncementService: announcementService,
+		EventService:        eventService,
+		Sessions:            sessions,
+	}
+}
+
+// BroadcastRequest represents the expected structure for broadcasting an announcement.
+type BroadcastRequest struct {
+	Title   string `json:"title" validate:"required,max=200"`
+	Message string `json:"message" validate:"required,max=2000"`
+}
+
+// BroadcastResult is the data of responses to broadcasts.
+type BroadcastResult struct {
+	Announcement models.Announcement `json:"announcement"`
+	Delivered    int                 `json:"delivered"` // Number of connected clients it was pushed to
+}
+
+// Connect upgrades the request to a WebSocket connection, over which the events of the
+// authenticated user are pushed as JSON messages ({"type": "notification", "data": {...}})
+// until the client disconnects, its token expires or its session ends. Messages from the client
+// are ignored.
+// Example: GET /ws?access_token=... (see middleware.TokenFromQuery)
+func (c *RealtimeController) Connect(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return fiber.NewError(http.StatusUpgradeRequired, msg(ctx, "websocket_upgrade_required"))
+	}
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+	expiresAt, ok := middleware.GetTokenExpiryFromJWT(ctx)
+	if !ok {
+		expiresAt = time.Now().Add(24 * time.Hour) // Tokens without expiry are still checked again daily
+	}
+	tenantID := tenancy.ID(ctx.UserContext())
+	valid := c.sessionCheck(ctx)
+	logger := requestLogger(ctx)
+
+	return websocket.New(func(conn *websocket.Conn) {
+		c.serve(conn, c.Hub.Subscribe(userID, tenantID), expiresAt, valid, logger)
+	})(ctx)
+}
+
+// sessionCheck returns a function reporting whether the session the request was authenticated
+// with is still valid, like middleware.JWT checks it: the user hasn't logged out or revoked it,
+// and the token still carries the current token version of the user. Without Sessions, it
+// always reports true.
+func (c *RealtimeController) sessionCheck(ctx *fiber.Ctx) func() bool {
+	token, _ := middleware.GetTokenFromJWT(ctx)
+	version, _ := middleware.GetTokenVersionFromJWT(ctx)
+	checkCtx := context.WithoutCancel(ctx.UserContext()) // Used after the handler returned
+	logger := requestLogger(ctx)
+	return func() bool {
+		if c.Sessions == nil {
+			return true
+		}
+		session, err := c.Sessions.GetSession(checkCtx, token)
+		if err != nil {
+			logger.Error("Error checking session of realtime client", "error", err)
+			return true // Checked again on the next tick
+		}
+		return session != nil && session.UserTokenVersion == version
+	}
+}
+
+// serve pushes the events of subscriber to conn and pings the client, until the client goes
+// away, falls behind, its token expires or valid reports that its session ended.
+func (c *RealtimeController) serve(conn *websocket.Conn, subscriber *realtime.Subscriber, expiresAt time.Time, valid func() bool, logger *slog.Logger) {
+	defer c.Hub.Unsubscribe(subscriber)
+	logger.Debug("WebSocket client connected")
+
+	// Read in the background, to process pongs and notice when the client goes away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadLimit(wsMaxMessage)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	defer func() {
+		conn.Close()
+		<-gone // The connection is reused once serve returns
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	expiry := time.NewTimer(time.Until(expiresAt))
+	defer expiry.Stop()
+	check := time.NewTicker(sessionCheckInterval)
+	defer check.Stop()
+
+	closeWith := func(code int, reason string) {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+	}
+	for {
+		select {
+		case event, ok := <-subscriber.Events():
+			if !ok {
+				logger.Warn("Dropping WebSocket client that fell behind")
+				closeWith(websocket.CloseTryAgainLater, "too slow")
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				logger.Debug("Error writing to WebSocket client", "error", err)
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-expiry.C:
+			closeWith(websocket.ClosePolicyViolation, "token expired")
+			return
+		case <-check.C:
+			if !valid() {
+				closeWith(websocket.ClosePolicyViolation, "session ended")
+				return
+			}
+		case <-gone:
+			logger.Debug("WebSocket client disconnected")
+			return
+		}
+	}
+}
+
+// Stream sends the events of the authenticated user as Server-Sent Events, for clients that
+// can't use WebSockets, until the client disconnects, its token expires or its session ends,
+// after which reconnecting fails until the client logs in again. Each event carries
+// the ID of its notification or announcement; clients reconnecting with it in the
+// Last-Event-ID header (or the last_event_id query parameter) first receive the events they
+// missed. A comment is sent every sseHeartbeatInterval while there are no events.
+// Example: GET /api/events?access_token=... (see middleware.TokenFromQuery)
+func (c *RealtimeController) Stream(ctx *fiber.Ctx) error {
+	userID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+	expiresAt, ok := middleware.GetTokenExpiryFromJWT(ctx)
+	if !ok {
+		expiresAt = time.Now().Add(24 * time.Hour)
+	}
+	lastEventID := ctx.Get("Last-Event-ID", ctx.Query("last_event_id"))
+	valid := c.sessionCheck(ctx)
+	logger := requestLogger(ctx)
+
+	// Subscribe before looking up the missed events, so no event is lost in between
+	subscriber := c.Hub.Subscribe(userID, tenancy.ID(ctx.UserContext()))
+	missed, err := c.EventService.GetMissedEvents(ctx.UserContext(), userID, lastEventID, sseMaxReplayed)
+	if err != nil {
+		c.Hub.Unsubscribe(subscriber)
+		logger.Error("Error fetching missed events", "last_event_id", lastEventID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_events"),
+		})
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no") // Keep proxies such as nginx from buffering the stream
+	// The stream is written once the handler returned, so it mustn't use ctx
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer c.Hub.Unsubscribe(subscriber)
+		if err := streamEvents(w, subscriber, missed, expiresAt, valid); err != nil {
+			logger.Debug("Event stream client disconnected", "error", err)
+		}
+	})
+	return nil
+}
+
+// streamEvents writes the missed events and then those of subscriber to w, until writing fails,
+// the subscriber falls behind, expiresAt passes or valid reports that the session ended.
+func streamEvents(w *bufio.Writer, subscriber *realtime.Subscriber, missed []realtime.Event, expiresAt time.Time, valid func() bool) error {
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	replayed := map[string]bool{}
+	for _, event := range missed {
+		replayed[event.ID] = true
+		if err := writeEvent(w, event); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	expiry := time.NewTimer(time.Until(expiresAt))
+	defer expiry.Stop()
+	check := time.NewTicker(sessionCheckInterval)
+	defer check.Stop()
+	for {
+		var err error
+		select {
+		case event, ok := <-subscriber.Events():
+			if !ok {
+				return nil // Fell behind; the client reconnects and catches up with Last-Event-ID
+			}
+			if replayed[event.ID] {
+				continue // Pushed while the missed events were looked up
+			}
+			err = writeEvent(w, event)
+		case <-heartbeat.C:
+			_, err = w.WriteString(": heartbeat\n\n")
+		case <-expiry.C:
+			return nil
+		case <-check.C:
+			if !valid() {
+				return nil
+			}
+			continue
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeEvent writes event in the Server-Sent Events format, named after its type, with its data
+// as JSON.
+func writeEvent(w *bufio.Writer, event realtime.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// Broadcast stores an announcement and pushes it to every connected user of the tenant.
+// Example: POST /api/admin/broadcasts
+func (c *RealtimeController) Broadcast(ctx *fiber.Ctx) error {
+	req := new(BroadcastRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing broadcast request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	announcement := models.Announcement{Title: req.Title, Message: req.Message}
+	if userID, ok := middleware.GetUserIDFromJWT(ctx); ok {
+		announcement.CreatedBy = &userID
+	}
+	if err := c.AnnouncementService.CreateAnnouncement(ctx.UserContext(), &announcement); err != nil {
+		requestLogger(ctx).Error("Error creating announcement", "title", req.Title, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_broadcast_announcement"),
+		})
+	}
+
+	delivered := c.Hub.Broadcast(tenancy.ID(ctx.UserContext()), realtime.Event{ID: announcement.ID, Type: realtime.EventAnnouncement, Data: announcement})
+	requestLogger(ctx).Info("Broadcast announcement", "announcement_id", announcement.ID, "delivered", delivered)
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "announcement_broadcast_successfully"),
+		"data":    BroadcastResult{Announcement: announcement, Delivered: delivered},
+	})
+}
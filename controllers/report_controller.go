@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// ReportController handles content reports and the admin review queue.
+type ReportController struct {
+	ReportService services.ReportServiceInterface
+}
+
+// NewReportController creates and returns a new ReportController instance.
+func NewReportController(reportService services.ReportServiceInterface) *ReportController {
+	return &ReportController{
+		ReportService: reportService,
+	}
+}
+
+// CreateReportRequest represents the expected structure for reporting a post or comment.
+type CreateReportRequest struct {
+	Reason  string `json:"reason" validate:"required,max=50"` // Short reason code (e.g., "spam", "abuse", "off-topic")
+	Details string `json:"details" validate:"max=5000"`       // Optional free-form explanation
+}
+
+// ReportPost handles POST /api/posts/:id/report.
+func (c *ReportController) ReportPost(ctx *fiber.Ctx) error {
+	return c.createReport(ctx, models.ReportTargetPost)
+}
+
+// ReportComment handles POST /api/comments/:id/report.
+func (c *ReportController) ReportComment(ctx *fiber.Ctx) error {
+	return c.createReport(ctx, models.ReportTargetComment)
+}
+
+func (c *ReportController) createReport(ctx *fiber.Ctx, targetType string) error {
+	targetID := ctx.Params("id")
+
+	reporterID, ok := middleware.GetUserIDFromJWT(ctx)
+	if !ok {
+		return ctx.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "user_id_not_found_in_token"),
+		})
+	}
+
+	req := new(CreateReportRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing report request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	exists, err := c.ReportService.TargetExists(ctx.UserContext(), targetType, targetID)
+	if err != nil {
+		requestLogger(ctx).Error("Error checking report target", "target_type", targetType, "target_id", targetID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "internal_server_error"),
+		})
+	}
+	if !exists {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "report_target_not_found", capitalize(targetType)),
+		})
+	}
+
+	alreadyReported, err := c.ReportService.HasOpenReport(ctx.UserContext(), targetType, targetID, reporterID)
+	if err != nil {
+		requestLogger(ctx).Error("Error checking for existing report", "target_type", targetType, "target_id", targetID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "internal_server_error"),
+		})
+	}
+	if alreadyReported {
+		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "report_already_submitted", targetType),
+		})
+	}
+
+	report := models.NewReport(targetType, targetID, reporterID, req.Reason, req.Details)
+	if err := c.ReportService.CreateReport(ctx.UserContext(), report); err != nil {
+		requestLogger(ctx).Error("Error creating report", "target_type", targetType, "target_id", targetID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_report"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "report_submitted_successfully"),
+		"data":    report,
+	})
+}
+
+// GetAllReports returns the admin review queue.
+// Example: GET /api/reports?status=open&target_type=post&page=1&limit=20
+func (c *ReportController) GetAllReports(ctx *fiber.Ctx) error {
+	status := ctx.Query("status", models.ReportStatusOpen) // The queue shows open reports unless asked otherwise
+	if status == "all" {
+		status = ""
+	}
+	targetType := ctx.Query("target_type", "")
+	page, limit := paginationParams(ctx)
+
+	reports, totalPages, totalItems, err := c.ReportService.GetAllReports(ctx.UserContext(), status, targetType, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching reports", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_reports"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "reports_retrieved_successfully"),
+		"data":        reports,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// ResolveReportRequest represents the expected structure for closing a report.
+type ResolveReportRequest struct {
+	Status string `json:"status" validate:"omitempty,oneof=resolved dismissed"` // "resolved" (default) or "dismissed"
+	Note   string `json:"note" validate:"max=5000"`
+}
+
+// ResolveReport closes an open report (PUT /api/reports/:id/resolve).
+func (c *ReportController) ResolveReport(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	req := new(ResolveReportRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing resolve report request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	if req.Status == "" {
+		req.Status = models.ReportStatusResolved
+	}
+
+	report, err := c.ReportService.GetReportByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching report", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_report"),
+		})
+	}
+	if report == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "report_not_found"),
+		})
+	}
+	if report.Status != models.ReportStatusOpen {
+		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "report_has_already_been_closed"),
+		})
+	}
+
+	adminID, _ := middleware.GetUserIDFromJWT(ctx)
+	report.Status = req.Status
+	report.ResolvedBy = &adminID
+	report.ResolutionNote = req.Note
+
+	if err := c.ReportService.ResolveReport(ctx.UserContext(), report); err != nil {
+		requestLogger(ctx).Error("Error resolving report", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_resolve_report"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "report_resolved_successfully"),
+		"data":    report,
+	})
+}
+
+// capitalize upper-cases the first letter of an ASCII word (e.g., "post" -> "Post").
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// RetentionController lets admins check what the log retention policy purges before it does.
+type RetentionController struct {
+	MaintenanceService services.MaintenanceServiceInterface // MaintenanceService dependency (interface)
+	Retention          time.Duration                        // How long log entries are kept (LOG_RETENTION_DAYS)
+	Mode               string                               // What happens to older entries (LOG_RETENTION_MODE)
+}
+
+// NewRetentionController creates and returns a new RetentionController instance.
+func NewRetentionController(maintenanceService services.MaintenanceServiceInterface, retention time.Duration, mode string) *RetentionController {
+	return &RetentionController{
+		MaintenanceService: maintenanceService,
+		Retention:          retention,
+		Mode:               mode,
+	}
+}
+
+// PreviewRetention returns how many entries per table the log-retention task would delete or
+// anonymize if it ran now, without changing them.
+// Example: GET /api/admin/retention
+func (c *RetentionController) PreviewRetention(ctx *fiber.Ctx) error {
+	before := time.Now().Add(-c.Retention)
+	counts, err := c.MaintenanceService.CountPurgeableLogs(ctx.UserContext(), before, c.Mode)
+	if err != nil {
+		requestLogger(ctx).Error("Error counting purgeable log entries", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_preview_retention"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "retention_preview_retrieved_successfully"),
+		"data":    models.RetentionPreview{Mode: c.Mode, Before: before, Tables: counts},
+	})
+}
@@ -1,10 +1,10 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net/http"
-	"strconv" // For converting string to int
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -24,30 +24,29 @@ func NewRoleController(roleService services.RoleServiceInterface) *RoleControlle
 	}
 }
 
-// GetAllRoles retrieves all roles from the database with search and pagination.
+// GetAllRoles retrieves all roles from the database with search and pagination, or every role
+// matching the search as a spreadsheet (see spreadsheetFormat).
 func (c *RoleController) GetAllRoles(ctx *fiber.Ctx) error {
-	search := ctx.Query("search", "")                 // Get search term, default to empty string
-	page, err := strconv.Atoi(ctx.Query("page", "1")) // Get page number, default to 1
-	if err != nil || page < 1 {
-		page = 1
-	}
-	limit, err := strconv.Atoi(ctx.Query("limit", "10")) // Get limit per page, default to 10
-	if err != nil || limit < 1 {
-		limit = 10
+	search := ctx.Query("search", "") // Get search term, default to empty string
+	if format, invalid := spreadsheetFormat(ctx); invalid != "" {
+		return invalidParam(ctx, "format", "oneof", "unsupported_export_format", invalid)
+	} else if format != "" {
+		return streamFile(ctx, "roles", format, roleExportHeader, c.exportRoles(search))
 	}
+	page, limit := paginationParams(ctx)
 
-	roles, totalPages, totalItems, err := c.RoleService.GetAllRoles(search, page, limit)
+	roles, totalPages, totalItems, err := c.RoleService.GetAllRoles(ctx.UserContext(), search, page, limit)
 	if err != nil {
-		log.Printf("Error fetching all roles: %v", err)
+		requestLogger(ctx).Error("Error fetching all roles", "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to retrieve roles",
+			"message": msg(ctx, "failed_to_retrieve_roles"),
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success":     true,
-		"message":     "Roles retrieved successfully",
+		"message":     msg(ctx, "roles_retrieved_successfully"),
 		"data":        roles,
 		"currentPage": page,
 		"totalPages":  totalPages,
@@ -55,134 +54,165 @@ func (c *RoleController) GetAllRoles(ctx *fiber.Ctx) error {
 	})
 }
 
+// roleExportHeader is the header of the spreadsheets of roles.
+var roleExportHeader = []string{"id", "name", "description", "version", "created_at", "updated_at"}
+
+// exportRoles returns the writer of the roles matching search to a spreadsheet, page by page.
+func (c *RoleController) exportRoles(search string) func(ctx context.Context, out exportWriter) error {
+	return func(ctx context.Context, out exportWriter) error {
+		return eachPage(func(page int) (int, error) {
+			roles, totalPages, _, err := c.RoleService.GetAllRoles(ctx, search, page, spreadsheetPageSize)
+			if err != nil {
+				return 0, err
+			}
+			for _, role := range roles {
+				if err := out.Write(role, []interface{}{role.ID, role.Name, role.Description, role.Version, role.CreatedAt, role.UpdatedAt}); err != nil {
+					return 0, err
+				}
+			}
+			return totalPages, nil
+		})
+	}
+}
+
 // GetRoleByID retrieves a single role by their ID.
 func (c *RoleController) GetRoleByID(ctx *fiber.Ctx) error {
 	id := ctx.Params("id") // Get role ID from URL parameters
 
-	role, err := c.RoleService.GetRoleByID(id)
+	role, err := c.RoleService.GetRoleByID(ctx.UserContext(), id)
 	if err != nil {
-		log.Printf("Error fetching role by ID %s: %v", id, err)
+		requestLogger(ctx).Error("Error fetching role", "id", id, "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to retrieve role",
+			"message": msg(ctx, "failed_to_retrieve_role"),
 		})
 	}
 
 	if role == nil {
 		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
 			"success": false,
-			"message": "Role not found",
+			"message": msg(ctx, "role_not_found"),
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Role retrieved successfully",
+		"message": msg(ctx, "role_retrieved_successfully"),
 		"data":    role,
 	})
 }
 
 // CreateRoleRequest represents the expected structure for creating a new role.
 type CreateRoleRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string `json:"name" validate:"required,max=50"`
+	Description string `json:"description" validate:"max=1000"`
 }
 
 // CreateRole creates a new role in the database.
 func (c *RoleController) CreateRole(ctx *fiber.Ctx) error {
 	req := new(CreateRoleRequest)
 	if err := ctx.BodyParser(req); err != nil {
-		log.Printf("Error parsing create role request body: %v", err)
+		requestLogger(ctx).Error("Error parsing create role request body", "error", err)
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Invalid request body",
+			"message": msg(ctx, "invalid_request_body"),
 		})
 	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
 
 	// Check if role with the same name already exists
-	existingRole, err := c.RoleService.GetRoleByName(req.Name)
+	existingRole, err := c.RoleService.GetRoleByName(ctx.UserContext(), req.Name)
 	if err != nil {
-		log.Printf("Error checking for existing role name %s: %v", req.Name, err)
+		requestLogger(ctx).Error("Error checking for existing role name", "name", req.Name, "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Internal server error",
+			"message": msg(ctx, "internal_server_error"),
 		})
 	}
 	if existingRole != nil {
 		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
 			"success": false,
-			"message": "Role with this name already exists",
+			"message": msg(ctx, "role_with_this_name_already_exists"),
 		})
 	}
 
 	newRole := models.NewRole(req.Name, req.Description)
 
-	err = c.RoleService.CreateRole(newRole)
+	err = c.RoleService.CreateRole(ctx.UserContext(), newRole)
 	if err != nil {
-		log.Printf("Error creating role %s: %v", req.Name, err)
+		requestLogger(ctx).Error("Error creating role", "name", req.Name, "error", err)
+		if isDomainError(err) {
+			return err
+		}
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to create role",
+			"message": msg(ctx, "failed_to_create_role"),
 		})
 	}
 
 	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
 		"success": true,
-		"message": "Role created successfully",
+		"message": msg(ctx, "role_created_successfully"),
 		"data":    newRole,
 	})
 }
 
 // UpdateRoleRequest represents the expected structure for updating an existing role.
 type UpdateRoleRequest struct {
-	Name        *string `json:"name"` // Use pointer to differentiate between zero value and not provided
-	Description *string `json:"description"`
+	Name        *string `json:"name" validate:"omitnil,min=1,max=50"` // Use pointer to differentiate between zero value and not provided
+	Description *string `json:"description" validate:"omitnil,max=1000"`
+	Version     *int    `json:"version" validate:"omitnil,gte=1"` // Version the client last read; a stale version is rejected with 409 Conflict
 }
 
 // UpdateRole updates an existing role's information.
 func (c *RoleController) UpdateRole(ctx *fiber.Ctx) error {
 	id := ctx.Params("id") // Get role ID from URL parameters
 
-	existingRole, err := c.RoleService.GetRoleByID(id)
+	existingRole, err := c.RoleService.GetRoleByID(ctx.UserContext(), id)
 	if err != nil {
-		log.Printf("Error fetching existing role for update %s: %v", id, err)
+		requestLogger(ctx).Error("Error fetching existing role for update", "id", id, "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to retrieve role for update",
+			"message": msg(ctx, "failed_to_retrieve_role_for_update"),
 		})
 	}
 	if existingRole == nil {
 		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
 			"success": false,
-			"message": "Role not found for update",
+			"message": msg(ctx, "role_not_found_for_update"),
 		})
 	}
 
 	req := new(UpdateRoleRequest)
 	if err := ctx.BodyParser(req); err != nil {
-		log.Printf("Error parsing update role request body for ID %s: %v", id, err)
+		requestLogger(ctx).Error("Error parsing update role request body", "id", id, "error", err)
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Invalid request body",
+			"message": msg(ctx, "invalid_request_body"),
 		})
 	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
 
 	// Apply updates only if provided in the request
 	if req.Name != nil {
 		// Check for name conflict if name is being updated
 		if *req.Name != existingRole.Name {
-			conflictRole, err := c.RoleService.GetRoleByName(*req.Name)
+			conflictRole, err := c.RoleService.GetRoleByName(ctx.UserContext(), *req.Name)
 			if err != nil {
-				log.Printf("Error checking for role name conflict %s: %v", *req.Name, err)
+				requestLogger(ctx).Error("Error checking for role name conflict", "name", *req.Name, "error", err)
 				return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 					"success": false,
-					"message": "Internal server error",
+					"message": msg(ctx, "internal_server_error"),
 				})
 			}
 			if conflictRole != nil {
 				return ctx.Status(http.StatusConflict).JSON(fiber.Map{
 					"success": false,
-					"message": "Role with this name already exists",
+					"message": msg(ctx, "role_with_this_name_already_exists"),
 				})
 			}
 		}
@@ -191,19 +221,25 @@ func (c *RoleController) UpdateRole(ctx *fiber.Ctx) error {
 	if req.Description != nil {
 		existingRole.Description = *req.Description
 	}
+	if req.Version != nil {
+		existingRole.Version = *req.Version
+	}
 
-	err = c.RoleService.UpdateRole(existingRole)
+	err = c.RoleService.UpdateRole(ctx.UserContext(), existingRole)
 	if err != nil {
-		log.Printf("Error updating role %s: %v", id, err)
+		requestLogger(ctx).Error("Error updating role", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to update role",
+			"message": msg(ctx, "failed_to_update_role"),
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Role updated successfully",
+		"message": msg(ctx, "role_updated_successfully"),
 		"data":    existingRole,
 	})
 }
@@ -212,24 +248,156 @@ func (c *RoleController) UpdateRole(ctx *fiber.Ctx) error {
 func (c *RoleController) DeleteRole(ctx *fiber.Ctx) error {
 	id := ctx.Params("id") // Get role ID from URL parameters
 
-	err := c.RoleService.DeleteRole(id)
+	err := c.RoleService.DeleteRole(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error deleting role", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_role"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "role_deleted_successfully"),
+	})
+}
+
+// UpdatePermissionMatrixRequest represents the expected structure for granting and revoking
+// permissions of several roles at once.
+type UpdatePermissionMatrixRequest struct {
+	Changes []models.PermissionChange `json:"changes" validate:"required,min=1,max=500,dive"` // Applied all or none
+}
+
+// GetPermissionMatrix returns the grid of the permissions granted to every role, for admin UIs
+// to render and edit access control in one view.
+// Example: GET /api/roles/matrix
+func (c *RoleController) GetPermissionMatrix(ctx *fiber.Ctx) error {
+	matrix, err := c.RoleService.GetPermissionMatrix(ctx.UserContext())
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching permission matrix", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_permission_matrix"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "permission_matrix_retrieved_successfully"),
+		"data":    matrix,
+	})
+}
+
+// UpdatePermissionMatrix grants and revokes permissions of roles in one batch, and returns the
+// updated matrix. The permissions of the admin role can't be changed.
+// Example: PUT /api/roles/matrix
+func (c *RoleController) UpdatePermissionMatrix(ctx *fiber.Ctx) error {
+	req := new(UpdatePermissionMatrixRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing permission matrix request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if err := c.RoleService.UpdatePermissions(ctx.UserContext(), req.Changes); err != nil {
+		requestLogger(ctx).Error("Error updating permissions", "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_permissions"),
+		})
+	}
+
+	matrix, err := c.RoleService.GetPermissionMatrix(ctx.UserContext())
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching permission matrix", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_permission_matrix"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "permissions_updated_successfully"),
+		"data":    matrix,
+	})
+}
+
+// ExportRoles downloads every role with its permissions as a JSON file, which ImportRoles
+// accepts as is in another environment.
+// Example: GET /api/roles/export
+func (c *RoleController) ExportRoles(ctx *fiber.Ctx) error {
+	roles, err := c.RoleService.ExportRoles(ctx.UserContext())
+	if err != nil {
+		requestLogger(ctx).Error("Error exporting roles", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_export_roles"),
+		})
+	}
+
+	now := time.Now().UTC()
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="roles-%s.json"`, now.Format("20060102")))
+	return ctx.Status(http.StatusOK).JSON(models.RoleExport{ExportedAt: now, Roles: roles})
+}
+
+// RoleImportRequest represents the expected structure for importing roles: the document of
+// ExportRoles.
+type RoleImportRequest struct {
+	Roles []models.RoleDefinition `json:"roles" validate:"required,min=1,max=500,dive"`
+}
+
+// ImportRoles creates the roles of an export, all or none of them. The on_conflict query
+// parameter tells what to do with roles named like an existing one: skip them (default),
+// overwrite the existing role, or rename the imported one.
+// Example: POST /api/roles/import?on_conflict=rename
+func (c *RoleController) ImportRoles(ctx *fiber.Ctx) error {
+	onConflict := ctx.Query("on_conflict", models.RoleConflictSkip)
+	switch onConflict {
+	case models.RoleConflictSkip, models.RoleConflictOverwrite, models.RoleConflictRename:
+	default:
+		return invalidParam(ctx, "on_conflict", "oneof", "invalid_role_conflict_resolution", onConflict)
+	}
+
+	req := new(RoleImportRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing role import request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	results, err := c.RoleService.ImportRoles(ctx.UserContext(), req.Roles, onConflict)
 	if err != nil {
-		log.Printf("Error deleting role by ID %s: %v", id, err)
-		// Check for specific error types if needed, e.g., "role not found"
-		if err.Error() == fmt.Sprintf("role with ID %s not found for deletion", id) {
-			return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Role not found",
-			})
+		requestLogger(ctx).Error("Error importing roles", "error", err)
+		if isDomainError(err) {
+			return err
 		}
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to delete role",
+			"message": msg(ctx, "failed_to_import_roles"),
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Role deleted successfully",
+		"message": msg(ctx, "roles_imported_successfully"),
+		"data":    results,
 	})
 }
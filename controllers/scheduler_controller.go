@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/scheduler"
+)
+
+// SchedulerController lets admins inspect the scheduled tasks and run them on demand.
+type SchedulerController struct {
+	Scheduler *scheduler.Scheduler // Scheduler dependency
+}
+
+// NewSchedulerController creates and returns a new SchedulerController instance.
+func NewSchedulerController(sched *scheduler.Scheduler) *SchedulerController {
+	return &SchedulerController{
+		Scheduler: sched,
+	}
+}
+
+// GetTasks lists the scheduled tasks with their schedule, next run and last run.
+// Example: GET /api/admin/tasks
+func (c *SchedulerController) GetTasks(ctx *fiber.Ctx) error {
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "tasks_retrieved_successfully"),
+		"data":    c.Scheduler.Tasks(),
+	})
+}
+
+// RunTask runs a scheduled task now and responds once it finished, with its status.
+// Example: POST /api/admin/tasks/log-retention/run
+func (c *SchedulerController) RunTask(ctx *fiber.Ctx) error {
+	name := ctx.Params("name")
+
+	status, err := c.Scheduler.Run(ctx.UserContext(), name)
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownTask):
+		return fiber.NewError(http.StatusNotFound, msg(ctx, "task_not_found", name))
+	case errors.Is(err, scheduler.ErrRunning):
+		return fiber.NewError(http.StatusConflict, msg(ctx, "task_already_running", name))
+	case err != nil:
+		requestLogger(ctx).Error("Error running task", "task", name, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "task_failed", name),
+			"data":    status,
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "task_completed_successfully", name),
+		"data":    status,
+	})
+}
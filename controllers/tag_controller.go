@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/utils"
+)
+
+// TagController handles tag-related requests.
+type TagController struct {
+	TagService services.TagServiceInterface // TagService dependency (interface)
+}
+
+// NewTagController creates and returns a new TagController instance.
+func NewTagController(tagService services.TagServiceInterface) *TagController {
+	return &TagController{
+		TagService: tagService,
+	}
+}
+
+// GetAllTags retrieves all tags with search and pagination.
+func (c *TagController) GetAllTags(ctx *fiber.Ctx) error {
+	search := ctx.Query("search", "")
+	page, limit := paginationParams(ctx)
+
+	tags, totalPages, totalItems, err := c.TagService.GetAllTags(ctx.UserContext(), search, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching all tags", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_tags"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "tags_retrieved_successfully"),
+		"data":        tags,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetTagCloud returns the most used tags with their post counts.
+// Example: GET /api/tags/cloud?limit=30
+func (c *TagController) GetTagCloud(ctx *fiber.Ctx) error {
+	limit, err := strconv.Atoi(ctx.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	cloud, err := c.TagService.GetTagCloud(ctx.UserContext(), limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching tag cloud", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_tag_cloud"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "tag_cloud_retrieved_successfully"),
+		"data":    cloud,
+	})
+}
+
+// TagRequest represents the expected structure for creating or updating a tag.
+type TagRequest struct {
+	Name string `json:"name" validate:"max=50"` // Required when creating; optional when updating
+	Slug string `json:"slug" validate:"max=60"` // Optional; derived from name when empty
+}
+
+// CreateTag creates a new tag.
+func (c *TagController) CreateTag(ctx *fiber.Ctx) error {
+	req := new(TagRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create tag request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	slug := utils.Slugify(req.Slug)
+	if slug == "" {
+		slug = utils.Slugify(req.Name)
+	}
+	if req.Name == "" || slug == "" {
+		return invalidParam(ctx, "name", "required", "tag_name_is_required")
+	}
+
+	existingTag, err := c.TagService.GetTagBySlug(ctx.UserContext(), slug)
+	if err != nil {
+		requestLogger(ctx).Error("Error checking for existing tag slug", "slug", slug, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "internal_server_error"),
+		})
+	}
+	if existingTag != nil {
+		return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "tag_with_this_slug_already_exists"),
+		})
+	}
+
+	newTag := models.NewTag(req.Name, slug)
+	if err := c.TagService.CreateTag(ctx.UserContext(), newTag); err != nil {
+		requestLogger(ctx).Error("Error creating tag", "name", req.Name, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_tag"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "tag_created_successfully"),
+		"data":    newTag,
+	})
+}
+
+// UpdateTag renames a tag and optionally changes its slug.
+func (c *TagController) UpdateTag(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	existingTag, err := c.TagService.GetTagByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing tag for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_tag_for_update"),
+		})
+	}
+	if existingTag == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "tag_not_found_for_update"),
+		})
+	}
+
+	req := new(TagRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update tag request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if req.Name != "" {
+		existingTag.Name = req.Name
+	}
+	if slug := utils.Slugify(req.Slug); slug != "" && slug != existingTag.Slug {
+		conflictTag, err := c.TagService.GetTagBySlug(ctx.UserContext(), slug)
+		if err != nil {
+			requestLogger(ctx).Error("Error checking for tag slug conflict", "slug", slug, "error", err)
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "internal_server_error"),
+			})
+		}
+		if conflictTag != nil {
+			return ctx.Status(http.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"message": msg(ctx, "tag_with_this_slug_already_exists"),
+			})
+		}
+		existingTag.Slug = slug
+	}
+
+	if err := c.TagService.UpdateTag(ctx.UserContext(), existingTag); err != nil {
+		requestLogger(ctx).Error("Error updating tag", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_tag"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "tag_updated_successfully"),
+		"data":    existingTag,
+	})
+}
+
+// DeleteTag deletes a tag by its ID and detaches it from all posts.
+func (c *TagController) DeleteTag(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.TagService.DeleteTag(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error deleting tag", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_tag"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "tag_deleted_successfully"),
+	})
+}
@@ -0,0 +1,406 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// TeamController handles team-related requests. Teams are visible to their members and
+// managed by their owners; admins may see and manage every team of the tenant.
+type TeamController struct {
+	TeamService services.TeamServiceInterface // TeamService dependency (interface)
+}
+
+// NewTeamController creates and returns a new TeamController instance.
+func NewTeamController(teamService services.TeamServiceInterface) *TeamController {
+	return &TeamController{
+		TeamService: teamService,
+	}
+}
+
+// TeamRequest represents the expected structure for creating or updating a team.
+type TeamRequest struct {
+	Name        *string `json:"name" validate:"omitnil,min=1,max=100"` // Required when creating
+	Description *string `json:"description" validate:"omitnil,max=1000"`
+}
+
+// AddTeamMemberRequest represents the expected structure for adding a member to a team.
+type AddTeamMemberRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+	Role   string `json:"role" validate:"omitempty,oneof=owner member"` // Defaults to "member"
+}
+
+// UpdateTeamMemberRequest represents the expected structure for changing a member's role.
+type UpdateTeamMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner member"`
+}
+
+// GetMyTeams lists the teams the authenticated user is a member of, with their role in each.
+func (c *TeamController) GetMyTeams(ctx *fiber.Ctx) error {
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	page, limit := paginationParams(ctx)
+
+	teams, totalPages, totalItems, err := c.TeamService.GetTeams(ctx.UserContext(), userID, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching teams", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_teams"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "teams_retrieved_successfully"),
+		"data":        teams,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetTeamByID retrieves a single team the authenticated user is a member of.
+func (c *TeamController) GetTeamByID(ctx *fiber.Ctx) error {
+	team, _, err := c.teamAccess(ctx)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching team", "id", ctx.Params("id"), "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_team"),
+		})
+	}
+	if team == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "team_not_found"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_retrieved_successfully"),
+		"data":    team,
+	})
+}
+
+// CreateTeam creates a team, owned by the authenticated user.
+func (c *TeamController) CreateTeam(ctx *fiber.Ctx) error {
+	req := new(TeamRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create team request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	if req.Name == nil {
+		return invalidParam(ctx, "name", "required", "team_name_is_required")
+	}
+
+	team := &models.Team{Name: *req.Name}
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	if err := c.TeamService.CreateTeam(ctx.UserContext(), team, userID); err != nil {
+		requestLogger(ctx).Error("Error creating team", "name", team.Name, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_team"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_created_successfully"),
+		"data":    team,
+	})
+}
+
+// UpdateTeam changes the name or description of a team. Only its owners or an admin may do so.
+func (c *TeamController) UpdateTeam(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	team, role, err := c.teamAccess(ctx)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing team for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_team"),
+		})
+	}
+	if team == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "team_not_found"),
+		})
+	}
+	if !canManageTeam(ctx, role) {
+		return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "team_manage_forbidden"),
+		})
+	}
+
+	req := new(TeamRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update team request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if req.Name != nil {
+		team.Name = *req.Name
+	}
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+	if err := c.TeamService.UpdateTeam(ctx.UserContext(), team); err != nil {
+		requestLogger(ctx).Error("Error updating team", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_team"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_updated_successfully"),
+		"data":    team,
+	})
+}
+
+// DeleteTeam deletes a team and its memberships. Only its owners or an admin may do so.
+func (c *TeamController) DeleteTeam(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	if handled, err := c.requireTeamManager(ctx); handled {
+		return err
+	}
+
+	if err := c.TeamService.DeleteTeam(ctx.UserContext(), id); err != nil {
+		requestLogger(ctx).Error("Error deleting team", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_team"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_deleted_successfully"),
+	})
+}
+
+// GetMembers lists the members of a team the authenticated user is a member of.
+func (c *TeamController) GetMembers(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	team, _, err := c.teamAccess(ctx)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching team", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_team"),
+		})
+	}
+	if team == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "team_not_found"),
+		})
+	}
+
+	members, err := c.TeamService.GetMembers(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching team members", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_team_members"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_members_retrieved_successfully"),
+		"data":    members,
+	})
+}
+
+// AddMember adds a user of the tenant to a team. Only its owners or an admin may do so.
+func (c *TeamController) AddMember(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if handled, err := c.requireTeamManager(ctx); handled {
+		return err
+	}
+
+	req := new(AddTeamMemberRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing add team member request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+	if req.Role == "" {
+		req.Role = models.TeamRoleMember
+	}
+
+	if err := c.TeamService.AddMember(ctx.UserContext(), id, req.UserID, req.Role); err != nil {
+		requestLogger(ctx).Error("Error adding team member", "id", id, "user_id", req.UserID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_add_team_member"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_member_added_successfully"),
+	})
+}
+
+// UpdateMember changes the role of a member of a team. Only its owners or an admin may do so,
+// and the last owner can't be demoted.
+func (c *TeamController) UpdateMember(ctx *fiber.Ctx) error {
+	id, userID := ctx.Params("id"), ctx.Params("userId")
+	if handled, err := c.requireTeamManager(ctx); handled {
+		return err
+	}
+
+	req := new(UpdateTeamMemberRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update team member request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if err := c.TeamService.UpdateMemberRole(ctx.UserContext(), id, userID, req.Role); err != nil {
+		requestLogger(ctx).Error("Error updating team member", "id", id, "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_team_member"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_member_updated_successfully"),
+	})
+}
+
+// RemoveMember removes a member from a team. Owners and admins may remove anyone, and members
+// may leave, but the last owner can't be removed.
+func (c *TeamController) RemoveMember(ctx *fiber.Ctx) error {
+	id, userID := ctx.Params("id"), ctx.Params("userId")
+	if self, _ := middleware.GetUserIDFromJWT(ctx); self != userID {
+		if handled, err := c.requireTeamManager(ctx); handled {
+			return err
+		}
+	}
+
+	if err := c.TeamService.RemoveMember(ctx.UserContext(), id, userID); err != nil {
+		requestLogger(ctx).Error("Error removing team member", "id", id, "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_remove_team_member"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "team_member_removed_successfully"),
+	})
+}
+
+// teamAccess fetches the team with the ID of the route together with the authenticated user's
+// role in it. The team is nil if it doesn't exist or the user is neither a member nor an admin;
+// the role is "" for admins who aren't members.
+func (c *TeamController) teamAccess(ctx *fiber.Ctx) (*models.Team, string, error) {
+	id := ctx.Params("id")
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+
+	team, err := c.TeamService.GetTeamByID(ctx.UserContext(), id)
+	if err != nil || team == nil {
+		return nil, "", err
+	}
+	role, err := c.TeamService.GetMemberRole(ctx.UserContext(), id, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" && !middleware.UserHasRole(ctx, "admin") {
+		return nil, "", nil // Hidden from non-members
+	}
+	team.Role = role
+	return team, role, nil
+}
+
+// requireTeamManager answers the request itself, reporting handled, unless the authenticated
+// user may manage the team with the ID of the route.
+func (c *TeamController) requireTeamManager(ctx *fiber.Ctx) (bool, error) {
+	team, role, err := c.teamAccess(ctx)
+	switch {
+	case err != nil:
+		requestLogger(ctx).Error("Error fetching team", "id", ctx.Params("id"), "error", err)
+		return true, ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_team"),
+		})
+	case team == nil:
+		return true, ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "team_not_found"),
+		})
+	case !canManageTeam(ctx, role):
+		return true, ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "team_manage_forbidden"),
+		})
+	}
+	return false, nil
+}
+
+// canManageTeam reports whether the authenticated user, who has role in a team, may change it
+// and its members: owners and admins may.
+func canManageTeam(ctx *fiber.Ctx, role string) bool {
+	return role == models.TeamRoleOwner || middleware.UserHasRole(ctx, "admin")
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/bruteforce"
+)
+
+// ThrottleController shows admins the client IPs and accounts locked out after too many failed
+// logins, e.g., to spot an attack or answer a user who can't log in. Lockouts are kept before
+// anyone is known to belong to a tenant, so they are shown to the platform administrators only.
+type ThrottleController struct {
+	Guard *bruteforce.Guard // Guard of the login endpoints
+}
+
+// NewThrottleController creates and returns a new ThrottleController instance.
+func NewThrottleController(guard *bruteforce.Guard) *ThrottleController {
+	return &ThrottleController{Guard: guard}
+}
+
+// GetThrottled lists the sources that are currently locked out, the longest locked out first.
+// Example: GET /api/admin/throttled
+func (c *ThrottleController) GetThrottled(ctx *fiber.Ctx) error {
+	sources, err := c.Guard.Throttled(ctx.UserContext())
+	if err != nil {
+		requestLogger(ctx).Error("Error listing locked out sources", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_throttled_sources"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "throttled_sources_retrieved_successfully"),
+		"data":    sources,
+	})
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// TrashController handles the admin endpoints for soft deleted records.
+type TrashController struct {
+	TrashService services.TrashServiceInterface // TrashService dependency (interface)
+}
+
+// NewTrashController creates and returns a new TrashController instance.
+func NewTrashController(trashService services.TrashServiceInterface) *TrashController {
+	return &TrashController{
+		TrashService: trashService,
+	}
+}
+
+// GetTrashed lists the soft deleted records of a resource with pagination.
+// Example: GET /api/trash/posts?page=1&limit=10
+func (c *TrashController) GetTrashed(ctx *fiber.Ctx) error {
+	resource := ctx.Params("resource")
+	if !c.TrashService.IsTrashResource(resource) {
+		return unknownTrashResource(ctx, resource)
+	}
+
+	page, limit := paginationParams(ctx)
+
+	records, totalPages, totalItems, err := c.TrashService.GetTrashed(ctx.UserContext(), resource, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching trashed records", "resource", resource, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_trashed_records"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "trashed_records_retrieved_successfully"),
+		"data":        records,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// RestoreTrashed takes a record out of the trash.
+// Example: POST /api/trash/posts/:id/restore
+func (c *TrashController) RestoreTrashed(ctx *fiber.Ctx) error {
+	resource := ctx.Params("resource")
+	id := ctx.Params("id")
+	if !c.TrashService.IsTrashResource(resource) {
+		return unknownTrashResource(ctx, resource)
+	}
+	if resource == models.TrashResourceRoles && !tenancy.IsPlatform(ctx.UserContext()) {
+		return sharedTrashResource(ctx)
+	}
+
+	err := c.TrashService.Restore(ctx.UserContext(), resource, id)
+	if err != nil {
+		requestLogger(ctx).Error("Error restoring trashed record", "resource", resource, "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_restore_record"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "record_restored_successfully"),
+	})
+}
+
+// PurgeTrashed permanently deletes a record from the trash.
+// Example: DELETE /api/trash/posts/:id
+func (c *TrashController) PurgeTrashed(ctx *fiber.Ctx) error {
+	resource := ctx.Params("resource")
+	id := ctx.Params("id")
+	if !c.TrashService.IsTrashResource(resource) {
+		return unknownTrashResource(ctx, resource)
+	}
+	if resource == models.TrashResourceRoles && !tenancy.IsPlatform(ctx.UserContext()) {
+		return sharedTrashResource(ctx)
+	}
+
+	err := c.TrashService.Purge(ctx.UserContext(), resource, id)
+	if err != nil {
+		requestLogger(ctx).Error("Error purging trashed record", "resource", resource, "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_purge_record"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "record_purged_successfully"),
+	})
+}
+
+// sharedTrashResource responds with 403 Forbidden to the admins of tenants other than the
+// platform's changing the trash of roles, which every tenant shares (see middleware.PlatformOnly).
+func sharedTrashResource(ctx *fiber.Ctx) error {
+	return ctx.Status(http.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"message": msg(ctx, "only_platform_admins_can_change_roles"),
+	})
+}
+
+// unknownTrashResource responds with 404 Not Found for resources that have no trash.
+func unknownTrashResource(ctx *fiber.Ctx, resource string) error {
+	return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+		"success": false,
+		"message": msg(ctx, "unknown_trash_resource", resource),
+	})
+}
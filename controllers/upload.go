@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// allowedImageTypes maps the accepted (sniffed) MIME types of images to the extension files are stored with.
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// upload is a file received in a multipart form field. Reading it yields the whole file,
+// including the bytes read to sniff its content type.
+type upload struct {
+	io.Reader
+	file        multipart.File
+	FileName    string // Original file name as sent by the client
+	Size        int64  // File size in bytes
+	ContentType string // Sniffed MIME type
+	Ext         string // Extension the file is stored with, e.g., ".png"
+}
+
+// Close closes the uploaded file.
+func (u *upload) Close() error {
+	return u.file.Close()
+}
+
+// receiveUpload opens the file of the multipart form field, at most maxSize bytes large and of
+// one of the allowed types, which map sniffed MIME types to extensions. The client-provided
+// content type isn't trusted. Rejected files are returned as Fiber errors, which ErrorHandler
+// answers with their status and message. The caller must close the upload.
+func receiveUpload(ctx *fiber.Ctx, field string, maxSize int64, allowed map[string]string) (*upload, error) {
+	fileHeader, err := ctx.FormFile(field)
+	if err != nil {
+		return nil, fiber.NewError(http.StatusBadRequest, msg(ctx, "file_required"))
+	}
+	if fileHeader.Size > maxSize {
+		return nil, fiber.NewError(http.StatusRequestEntityTooLarge, msg(ctx, "file_too_large", maxSize))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		requestLogger(ctx).Error("Error opening uploaded file", "filename", fileHeader.Filename, "error", err)
+		return nil, fiber.NewError(http.StatusBadRequest, msg(ctx, "failed_to_read_uploaded_file"))
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		requestLogger(ctx).Error("Error reading uploaded file", "filename", fileHeader.Filename, "error", err)
+		return nil, fiber.NewError(http.StatusBadRequest, msg(ctx, "failed_to_read_uploaded_file"))
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	ext, ok := allowed[contentType]
+	if !ok {
+		file.Close()
+		return nil, fiber.NewError(http.StatusUnsupportedMediaType, msg(ctx, "file_type_not_allowed", contentType))
+	}
+
+	return &upload{
+		Reader:      io.MultiReader(bytes.NewReader(head), file),
+		file:        file,
+		FileName:    fileHeader.Filename,
+		Size:        fileHeader.Size,
+		ContentType: contentType,
+		Ext:         ext,
+	}, nil
+}
+
+// removeStoredFile deletes the file stored under key, if any. Failures are only logged: the
+// change that replaced or dropped the file has been made already.
+func removeStoredFile(ctx *fiber.Ctx, store storage.Storage, key *string) {
+	if key == nil {
+		return
+	}
+	if err := store.Delete(ctx.UserContext(), *key); err != nil {
+		requestLogger(ctx).Warn("Failed to remove stored file", "storage_key", *key, "error", err)
+	}
+}
+
+// fileURL returns the download URL of the file stored under key, or "" if key is nil.
+func fileURL(store storage.Storage, key *string) string {
+	if key == nil {
+		return ""
+	}
+	return store.URL(*key)
+}
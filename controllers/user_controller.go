@@ -1,52 +1,59 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
 
-	// For time.Now()
-	"github.com/gofiber/fiber/v2" // For generating UUIDs
-	"golang.org/x/crypto/bcrypt"  // For password hashing
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 
+	"github.com/anpsniper/anpbayu-be/middleware"
 	"github.com/anpsniper/anpbayu-be/models"   // Import models package for User struct
 	"github.com/anpsniper/anpbayu-be/services" // Import services package for UserServiceInterface
+	"github.com/anpsniper/anpbayu-be/storage"
 )
 
 // UserController handles user-related requests.
 type UserController struct {
 	UserService services.UserServiceInterface // UserService dependency (interface)
+	Storage     storage.Storage               // Where avatars are kept
+	MaxSize     int64                         // Maximum accepted avatar size in bytes
 }
 
 // NewUserController creates and returns a new UserController instance.
-func NewUserController(userService services.UserServiceInterface) *UserController {
+func NewUserController(userService services.UserServiceInterface, store storage.Storage, maxSize int64) *UserController {
 	return &UserController{
 		UserService: userService,
+		Storage:     store,
+		MaxSize:     maxSize,
 	}
 }
 
 func (c *UserController) GetAllUsers(ctx *fiber.Ctx) error {
-	log.Println("GetAllUsers endpoint hit.")
-
-	search := ctx.Query("search", "")                 // Get search term, default to empty string
-	roleID := ctx.Query("role_id", "")                // NEW: Get role_id, default to empty string
-	page, err := strconv.Atoi(ctx.Query("page", "1")) // Get page number, default to 1
-	if err != nil || page < 1 {
-		page = 1
+	requestLogger(ctx).Debug("GetAllUsers endpoint hit")
+
+	search := ctx.Query("search", "")  // Get search term, default to empty string
+	roleID := ctx.Query("role_id", "") // NEW: Get role_id, default to empty string
+	tag := ctx.Query("tag", "")        // Only users with this tag, when set
+	page, limit := paginationParams(ctx)
+	fields, invalid := fieldsParam(ctx, models.UserResponse{})
+	if invalid != "" {
+		return invalidParam(ctx, "fields", "oneof", "invalid_field", invalid)
 	}
-	limit, err := strconv.Atoi(ctx.Query("limit", "10")) // Get limit per page, default to 10
-	if err != nil || limit < 1 {
-		limit = 10
+	if format, invalid := spreadsheetFormat(ctx); invalid != "" {
+		return invalidParam(ctx, "format", "oneof", "unsupported_export_format", invalid)
+	} else if format != "" {
+		return streamFile(ctx, "users", format, userExportHeader, c.exportUsers(search, roleID, tag))
 	}
 
 	// Pass the new roleID parameter to the service layer
-	users, totalPages, totalItems, err := c.UserService.GetAllUsers(search, roleID, page, limit)
+	users, totalPages, totalItems, err := c.UserService.GetAllUsers(ctx.UserContext(), search, roleID, tag, page, limit)
 	if err != nil {
-		log.Printf("Error fetching all users: %v", err)
+		requestLogger(ctx).Error("Error fetching all users", "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to retrieve users",
+			"message": msg(ctx, "failed_to_retrieve_users"),
 		})
 	}
 
@@ -58,8 +65,12 @@ func (c *UserController) GetAllUsers(ctx *fiber.Ctx) error {
 			ID:        user.ID,
 			Username:  user.Username,
 			Email:     user.Email,
+			Phone:     user.Phone,
 			RoleID:    user.RoleID,
 			RoleName:  user.RoleName,
+			AvatarURL: fileURL(c.Storage, user.AvatarKey),
+			Version:   user.Version,
+			Tags:      user.Tags,
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: user.UpdatedAt,
 		}
@@ -68,8 +79,8 @@ func (c *UserController) GetAllUsers(ctx *fiber.Ctx) error {
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success":     true,
-		"message":     "Users retrieved successfully",
-		"data":        userResponses, // <-- Now returning userResponses
+		"message":     msg(ctx, "users_retrieved_successfully"),
+		"data":        selectFields(userResponses, fields), // <-- Now returning userResponses
 		"currentPage": page,
 		"totalPages":  totalPages,
 		"totalItems":  totalItems,
@@ -77,14 +88,14 @@ func (c *UserController) GetAllUsers(ctx *fiber.Ctx) error {
 }
 
 func (c *UserController) GetAllRoles(ctx *fiber.Ctx) error {
-	log.Println("GetAllRoles endpoint hit.")
+	requestLogger(ctx).Debug("GetAllRoles endpoint hit")
 
-	roles, err := c.UserService.GetAllRoles()
+	roles, err := c.UserService.GetAllRoles(ctx.UserContext())
 	if err != nil {
-		log.Printf("Error fetching all roles: %v", err)
+		requestLogger(ctx).Error("Error fetching all roles", "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to retrieve roles due to an internal error.",
+			"message": msg(ctx, "failed_to_retrieve_roles"),
 		})
 	}
 
@@ -101,7 +112,7 @@ func (c *UserController) GetAllRoles(ctx *fiber.Ctx) error {
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Roles retrieved successfully.",
+		"message": msg(ctx, "roles_retrieved_successfully"),
 		"data":    roleResponses, // Mengembalikan DTO yang dimapping
 	})
 }
@@ -109,71 +120,86 @@ func (c *UserController) GetAllRoles(ctx *fiber.Ctx) error {
 // GetUserByID retrieves a single user by their ID.
 func (c *UserController) GetUserByID(ctx *fiber.Ctx) error {
 	id := ctx.Params("id") // Get user ID from URL parameters
+	fields, invalid := fieldsParam(ctx, models.User{})
+	if invalid != "" {
+		return invalidParam(ctx, "fields", "oneof", "invalid_field", invalid)
+	}
 
-	user, err := c.UserService.GetUserByID(id)
+	user, err := c.UserService.GetUserByID(ctx.UserContext(), id)
 	if err != nil {
-		log.Printf("Error fetching user by ID %s: %v", id, err)
+		requestLogger(ctx).Error("Error fetching user", "id", id, "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to retrieve user",
+			"message": msg(ctx, "failed_to_retrieve_user"),
 		})
 	}
 
 	if user == nil {
 		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
 			"success": false,
-			"message": "User not found",
+			"message": msg(ctx, "user_not_found"),
 		})
 	}
 
 	// Do not return password hash
 	user.Password = ""
+	user.AvatarURL = fileURL(c.Storage, user.AvatarKey)
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "User retrieved successfully",
-		"data":    user,
+		"message": msg(ctx, "user_retrieved_successfully"),
+		"data":    selectFields(user, fields),
 	})
 }
 
 // CreateUserRequest represents the expected structure for creating a new user.
 type CreateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	RoleID   string `json:"role_id"` // Expecting role ID from frontend
+	Username string `json:"username" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Phone    string `json:"phone,omitempty" validate:"omitempty,e164"` // Optional, enables the SMS login
+	Password string `json:"password" validate:"required,min=8,max=72"` // bcrypt ignores everything after 72 bytes
+	RoleID   string `json:"role_id" validate:"required,uuid"`          // Expecting role ID from frontend
 }
 
 // CreateUser creates a new user in the database.
 func (c *UserController) CreateUser(ctx *fiber.Ctx) error {
 	req := new(CreateUserRequest)
 	if err := ctx.BodyParser(req); err != nil {
-		log.Printf("Error parsing create user request body: %v", err)
+		requestLogger(ctx).Error("Error parsing create user request body", "error", err)
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Invalid request body",
+			"message": msg(ctx, "invalid_request_body"),
 		})
 	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
 
 	// Hash the password before storing it
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := services.HashPassword(req.Password)
 	if err != nil {
-		log.Printf("Error hashing password for new user %s: %v", req.Email, err)
+		requestLogger(ctx).Error("Error hashing password for new user", "email", req.Email, "error", err)
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to process password",
+			"message": msg(ctx, "failed_to_process_password"),
 		})
 	}
 
 	// Create a new User model instance
-	newUser := models.NewUser(req.Username, req.Email, string(hashedPassword), req.RoleID)
+	newUser := models.NewUser(req.Username, req.Email, hashedPassword, req.RoleID)
+	if req.Phone != "" {
+		newUser.Phone = &req.Phone
+	}
 	// The ID, CreatedAt, UpdatedAt will be set by the service/database layer
 
-	err = c.UserService.CreateUser(newUser)
+	err = c.UserService.CreateUser(ctx.UserContext(), newUser)
 	if err != nil {
-		log.Printf("Error creating user %s: %v", req.Email, err)
+		requestLogger(ctx).Error("Error creating user", "email", req.Email, "error", err)
+		if isDomainError(err) {
+			return err
+		}
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to create user",
+			"message": msg(ctx, "failed_to_create_user"),
 		})
 	}
 
@@ -181,7 +207,7 @@ func (c *UserController) CreateUser(ctx *fiber.Ctx) error {
 	newUser.Password = ""
 	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
 		"success": true,
-		"message": "User created successfully",
+		"message": msg(ctx, "user_created_successfully"),
 		"data":    newUser,
 	})
 }
@@ -200,50 +226,41 @@ func (c *UserController) UpdateUser(ctx *fiber.Ctx) error {
 	if id == "" {
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "User ID is required",
+			"message": msg(ctx, "user_id_is_required"),
 		})
 	}
 
 	req := new(models.UpdateUserRequest) // Use models.UpdateUserRequest
 	if err := ctx.BodyParser(req); err != nil {
-		log.Printf("Error parsing update user request body for ID %s: %v", id, err)
+		requestLogger(ctx).Error("Error parsing update user request body", "id", id, "error", err)
 		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Invalid request body",
+			"message": msg(ctx, "invalid_request_body"),
 		})
 	}
 
 	req.ID = id // Set the ID from the URL parameter to the request struct
 
-	// Basic validation (ensure required fields for update are present)
-	if req.Username == "" || req.Email == "" || req.RoleID == "" {
-		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Username, email, and role ID are required for update",
-		})
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
 	}
 
 	// Pass the request directly to the service; conditional password update is handled in service.
-	err := c.UserService.UpdateUser(req)
+	err := c.UserService.UpdateUser(ctx.UserContext(), req)
 	if err != nil {
-		log.Printf("Error updating user %s: %v", id, err)
-		if err.Error() == fmt.Sprintf("user with ID %s not found for update", id) {
-			return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "User not found",
-				"error":   err.Error(),
-			})
+		requestLogger(ctx).Error("Error updating user", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
 		}
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to update user",
-			"error":   err.Error(),
+			"message": msg(ctx, "failed_to_update_user"),
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "User updated successfully",
+		"message": msg(ctx, "user_updated_successfully"),
 	})
 }
 
@@ -251,24 +268,291 @@ func (c *UserController) UpdateUser(ctx *fiber.Ctx) error {
 func (c *UserController) DeleteUser(ctx *fiber.Ctx) error {
 	id := ctx.Params("id") // Get user ID from URL parameters
 
-	err := c.UserService.DeleteUser(id)
+	err := c.UserService.DeleteUser(ctx.UserContext(), id)
 	if err != nil {
-		log.Printf("Error deleting user by ID %s: %v", id, err)
-		// Check for specific error types if needed, e.g., "user not found"
-		if err.Error() == fmt.Sprintf("user with ID %s not found for deletion", id) {
-			return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+		requestLogger(ctx).Error("Error deleting user", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_user"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_deleted_successfully"),
+	})
+}
+
+// GetUserLogs lists the logins of the users matching the filters in the query, newest first,
+// with the duration of the sessions that were logged out. With a cursor parameter (empty for
+// the first page) it pages by keyset instead of by page number, which stays fast deep into the
+// history, and answers the cursor of the next page instead of the page counts. Asked for a
+// spreadsheet (see spreadsheetFormat), it answers the whole history matching the filters instead.
+// Example: GET /api/user-logs?user_id=...&from=2024-01-01T00:00:00Z&page=1
+func (c *UserController) GetUserLogs(ctx *fiber.Ctx) error {
+	filter := models.UserLogFilter{UserID: ctx.Query("user_id")}
+	var invalid string
+	if filter.From, filter.To, invalid = timeRangeParams(ctx); invalid != "" {
+		return invalidParam(ctx, invalid, "datetime", "invalid_time_param", invalid)
+	}
+	if format, invalid := spreadsheetFormat(ctx); invalid != "" {
+		return invalidParam(ctx, "format", "oneof", "unsupported_export_format", invalid)
+	} else if format != "" {
+		return streamFile(ctx, "user-logs", format, userLogExportHeader, c.exportUserLogs(filter))
+	}
+	page, limit := paginationParams(ctx)
+	after, keyset, ok := cursorParam(ctx)
+	if !ok {
+		return invalidParam(ctx, "cursor", "cursor", "invalid_cursor")
+	}
+
+	if keyset {
+		logs, next, err := c.UserService.GetUserLogsAfter(ctx.UserContext(), filter, after, limit)
+		if err != nil {
+			requestLogger(ctx).Error("Error fetching user logs", "error", err)
+			if isDomainError(err) {
+				return err
+			}
+			return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 				"success": false,
-				"message": "User not found",
+				"message": msg(ctx, "failed_to_retrieve_user_logs"),
 			})
 		}
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success":    true,
+			"message":    msg(ctx, "user_logs_retrieved_successfully"),
+			"data":       logs,
+			"nextCursor": nextCursor(next),
+		})
+	}
+
+	logs, totalPages, totalItems, err := c.UserService.GetUserLogs(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching user logs", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_user_logs"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "user_logs_retrieved_successfully"),
+		"data":        logs,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// ExportUserLogs handles GET /api/user-logs/export, streaming the login history matching the
+// same filters as GetUserLogs as a CSV, JSON or XLSX file, for compliance reporting.
+func (c *UserController) ExportUserLogs(ctx *fiber.Ctx) error {
+	filter := models.UserLogFilter{UserID: ctx.Query("user_id")}
+	var invalid string
+	if filter.From, filter.To, invalid = timeRangeParams(ctx); invalid != "" {
+		return invalidParam(ctx, invalid, "datetime", "invalid_time_param", invalid)
+	}
+
+	return streamExport(ctx, "user-logs", userLogExportHeader, c.exportUserLogs(filter))
+}
+
+// userLogExportHeader is the header of the exports and spreadsheets of the login history.
+var userLogExportHeader = []string{"id", "user_id", "username", "login_at", "logout_at", "duration_seconds"}
+
+// exportUserLogs returns the writer of the logins matching filter to an export or spreadsheet.
+func (c *UserController) exportUserLogs(filter models.UserLogFilter) func(ctx context.Context, out exportWriter) error {
+	return func(ctx context.Context, out exportWriter) error {
+		return c.UserService.ExportUserLogs(ctx, filter, func(log *models.UserLog) error {
+			return out.Write(log, []interface{}{log.ID, log.UserID, log.Username, log.LoginAt, orNil(log.LogoutAt), orNil(log.DurationSeconds)})
+		})
+	}
+}
+
+// ExportUsers handles GET /api/users/export, streaming the users matching the same filters as
+// GetAllUsers as a CSV, JSON or XLSX file, by username.
+func (c *UserController) ExportUsers(ctx *fiber.Ctx) error {
+	search, roleID, tag := ctx.Query("search"), ctx.Query("role_id"), ctx.Query("tag")
+
+	return streamExport(ctx, "users", userExportHeader, c.exportUsers(search, roleID, tag))
+}
+
+// userExportHeader is the header of the exports and spreadsheets of users.
+var userExportHeader = []string{"id", "username", "email", "phone", "role_id", "role_name", "created_at", "updated_at"}
+
+// exportUsers returns the writer of the users matching the filters of GetAllUsers to an export
+// or spreadsheet.
+func (c *UserController) exportUsers(search, roleID, tag string) func(ctx context.Context, out exportWriter) error {
+	return func(ctx context.Context, out exportWriter) error {
+		return c.UserService.ExportUsers(ctx, search, roleID, tag, func(user *models.User) error {
+			record := models.UserExport{
+				ID:        user.ID,
+				Username:  user.Username,
+				Email:     user.Email,
+				Phone:     user.Phone,
+				RoleID:    user.RoleID,
+				RoleName:  user.RoleName,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			}
+			return out.Write(record, []interface{}{user.ID, user.Username, user.Email, orNil(user.Phone), user.RoleID, user.RoleName, user.CreatedAt, user.UpdatedAt})
+		})
+	}
+}
+
+// UserTagsRequest represents the expected structure for replacing the tags of a user.
+type UserTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=50,dive,required,max=50"` // Tags are lowercased; an empty list removes them all
+}
+
+// TagUsersRequest represents the expected structure for changing the tags of several users at once.
+type TagUsersRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=1000,unique,dive,uuid"`
+	Add     []string `json:"add" validate:"max=50,dive,required,max=50"`    // Tags attached to every user
+	Remove  []string `json:"remove" validate:"max=50,dive,required,max=50"` // Tags detached from every user
+}
+
+// GetUserTags lists the tags attached to users, with the number of users of each.
+func (c *UserController) GetUserTags(ctx *fiber.Ctx) error {
+	tags, err := c.UserService.GetUserTags(ctx.UserContext())
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching user tags", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_user_tags"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_tags_retrieved_successfully"),
+		"data":    tags,
+	})
+}
+
+// SetUserTags replaces the tags of a user.
+func (c *UserController) SetUserTags(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	req := new(UserTagsRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing user tags request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	tags, err := c.UserService.SetUserTags(ctx.UserContext(), id, req.Tags)
+	if err != nil {
+		requestLogger(ctx).Error("Error setting user tags", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_user_tags"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_tags_updated_successfully"),
+		"data":    tags,
+	})
+}
+
+// TagUsers attaches and detaches tags on several users at once, e.g. to select the users of a
+// staged rollout. Either every user is changed or none is.
+func (c *UserController) TagUsers(ctx *fiber.Ctx) error {
+	req := new(TagUsersRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing tag users request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	if err := c.UserService.TagUsers(ctx.UserContext(), req.UserIDs, req.Add, req.Remove); err != nil {
+		requestLogger(ctx).Error("Error tagging users", "users", len(req.UserIDs), "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_user_tags"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_tags_updated_successfully"),
+	})
+}
+
+// AvatarResponse is the data of responses to avatar changes.
+type AvatarResponse struct {
+	AvatarURL string `json:"avatar_url,omitempty"` // Presigned download URL of the new avatar; empty when it was removed
+}
+
+// UploadAvatar handles POST /api/profile/avatar with a multipart "file" field, replacing the
+// authenticated user's avatar.
+func (c *UserController) UploadAvatar(ctx *fiber.Ctx) error {
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+
+	file, err := receiveUpload(ctx, "file", c.MaxSize, allowedImageTypes)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	key := fmt.Sprintf("avatars/%s/%s%s", userID, uuid.New().String(), file.Ext)
+	if err := c.Storage.Save(ctx.UserContext(), key, file, file.Size, file.ContentType); err != nil {
+		requestLogger(ctx).Error("Error storing avatar of user", "id", userID, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_store_file"),
+		})
+	}
+	return c.setAvatar(ctx, userID, &key, "avatar_uploaded_successfully")
+}
+
+// DeleteAvatar handles DELETE /api/profile/avatar, removing the authenticated user's avatar.
+func (c *UserController) DeleteAvatar(ctx *fiber.Ctx) error {
+	userID, _ := middleware.GetUserIDFromJWT(ctx)
+	return c.setAvatar(ctx, userID, nil, "avatar_removed_successfully")
+}
+
+// setAvatar points the user at the avatar stored under key (nil removes the avatar) and
+// deletes the replaced file. A newly stored file is deleted again when the user can't be changed.
+func (c *UserController) setAvatar(ctx *fiber.Ctx, userID string, key *string, messageKey string) error {
+	previous, err := c.UserService.SetAvatar(ctx.UserContext(), userID, key)
+	if err != nil {
+		requestLogger(ctx).Error("Error changing avatar of user", "id", userID, "error", err)
+		removeStoredFile(ctx, c.Storage, key)
+		if isDomainError(err) {
+			return err
+		}
 		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to delete user",
+			"message": msg(ctx, "failed_to_update_avatar"),
 		})
 	}
+	removeStoredFile(ctx, c.Storage, previous)
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "User deleted successfully",
+		"message": msg(ctx, messageKey),
+		"data":    AvatarResponse{AvatarURL: fileURL(c.Storage, key)},
 	})
 }
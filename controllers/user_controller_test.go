@@ -0,0 +1,145 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/controllers"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/testutil"
+)
+
+const (
+	adminID = "0f6f4d4e-7a39-4d8e-9a52-1f1b2f1c0a01"
+	userID  = "0f6f4d4e-7a39-4d8e-9a52-1f1b2f1c0a02"
+	roleID  = "0f6f4d4e-7a39-4d8e-9a52-1f1b2f1c0a03"
+)
+
+// newUserApp serves the user routes of users behind the JWT middleware, like the server does.
+func newUserApp(t *testing.T, users *testutil.UserServiceMock) *fiber.App {
+	t.Helper()
+	app := testutil.NewApp(t)
+	testutil.RequireJWT(app)
+	controller := controllers.NewUserController(users, nil, 1<<20)
+	app.Get("/api/users", controller.GetAllUsers)
+	app.Get("/api/users/:id", controller.GetUserByID)
+	app.Post("/api/users", controller.CreateUser)
+	return app
+}
+
+func TestGetAllUsers(t *testing.T) {
+	users := &testutil.UserServiceMock{
+		GetAllUsersFunc: func(ctx context.Context, search, roleID, tag string, page, limit int) ([]models.User, int, int, error) {
+			if search != "ali" || page != 2 {
+				t.Errorf("GetAllUsers(search %q, page %d), want search \"ali\", page 2", search, page)
+			}
+			return []models.User{{ID: userID, Username: "alice", Email: "alice@example.com", Password: "hash", RoleID: roleID}}, 3, 21, nil
+		},
+	}
+	app := newUserApp(t, users)
+
+	resp := testutil.Do(t, app, testutil.Request{Method: http.MethodGet, Path: "/api/users?search=ali&page=2", Token: testutil.Token(t, adminID, "admin")}).
+		AssertStatus(t, http.StatusOK)
+	var got []models.UserResponse
+	resp.Data(t, &got)
+	if len(got) != 1 || got[0].ID != userID || got[0].Email != "alice@example.com" {
+		t.Fatalf("data = %+v, want alice", got)
+	}
+	if envelope := resp.Envelope(t); envelope.TotalPages != 3 || envelope.TotalItems != 21 {
+		t.Errorf("totalPages, totalItems = %d, %d, want 3, 21", envelope.TotalPages, envelope.TotalItems)
+	}
+}
+
+func TestGetAllUsersFailure(t *testing.T) {
+	users := &testutil.UserServiceMock{
+		GetAllUsersFunc: func(ctx context.Context, search, roleID, tag string, page, limit int) ([]models.User, int, int, error) {
+			return nil, 0, 0, errors.New("connection refused")
+		},
+	}
+	app := newUserApp(t, users)
+
+	resp := testutil.Do(t, app, testutil.Request{Method: http.MethodGet, Path: "/api/users", Token: testutil.Token(t, adminID, "admin")}).
+		AssertStatus(t, http.StatusInternalServerError)
+	if envelope := resp.Envelope(t); envelope.Success || envelope.Message == "" {
+		t.Errorf("envelope = %+v, want a failure with a message", envelope)
+	}
+}
+
+func TestGetAllUsersRequiresToken(t *testing.T) {
+	users := &testutil.UserServiceMock{}
+	app := newUserApp(t, users)
+
+	testutil.Do(t, app, testutil.Request{Method: http.MethodGet, Path: "/api/users"}).
+		AssertStatus(t, http.StatusBadRequest) // Missing or malformed JWT
+	if n := users.Calls("GetAllUsers"); n != 0 {
+		t.Errorf("GetAllUsers called %d times without a token", n)
+	}
+}
+
+func TestGetUserByIDNotFound(t *testing.T) {
+	users := &testutil.UserServiceMock{
+		GetUserByIDFunc: func(ctx context.Context, id string) (*models.User, error) {
+			return nil, nil
+		},
+	}
+	app := newUserApp(t, users)
+
+	testutil.Do(t, app, testutil.Request{Method: http.MethodGet, Path: "/api/users/" + userID, Token: testutil.Token(t, adminID, "admin")}).
+		AssertStatus(t, http.StatusNotFound)
+}
+
+func TestCreateUser(t *testing.T) {
+	users := &testutil.UserServiceMock{
+		CreateUserFunc: func(ctx context.Context, user *models.User) error {
+			if user.Password == "password123" {
+				t.Error("CreateUser got the plain password, want its hash")
+			}
+			user.ID = userID
+			return nil
+		},
+	}
+	app := newUserApp(t, users)
+
+	testutil.Do(t, app, testutil.Request{
+		Method: http.MethodPost,
+		Path:   "/api/users",
+		Body:   map[string]string{"username": "alice", "email": "alice@example.com", "password": "password123", "role_id": roleID},
+		Token:  testutil.Token(t, adminID, "admin"),
+	}).AssertStatus(t, http.StatusCreated)
+}
+
+func TestCreateUserInvalid(t *testing.T) {
+	users := &testutil.UserServiceMock{}
+	app := newUserApp(t, users)
+
+	testutil.Do(t, app, testutil.Request{
+		Method: http.MethodPost,
+		Path:   "/api/users",
+		Body:   map[string]string{"username": "alice", "password": "password123", "role_id": roleID},
+		Token:  testutil.Token(t, adminID, "admin"),
+	}).AssertFieldError(t, "email", "required")
+	if n := users.Calls("CreateUser"); n != 0 {
+		t.Errorf("CreateUser called %d times with an invalid request", n)
+	}
+}
+
+func TestCreateUserConflict(t *testing.T) {
+	users := &testutil.UserServiceMock{
+		CreateUserFunc: func(ctx context.Context, user *models.User) error {
+			return &services.DomainError{Kind: services.ErrConflict, Message: "email alice@example.com belongs to a user in the trash"}
+		},
+	}
+	app := newUserApp(t, users)
+
+	testutil.Do(t, app, testutil.Request{
+		Method: http.MethodPost,
+		Path:   "/api/users",
+		Body:   map[string]string{"username": "alice", "email": "alice@example.com", "password": "password123", "role_id": roleID},
+		Token:  testutil.Token(t, adminID, "admin"),
+	}).AssertStatus(t, http.StatusConflict)
+}
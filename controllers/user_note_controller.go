@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// UserNoteController handles the internal notes of the support staff on user accounts. Its
+// routes are only open to admins.
+type UserNoteController struct {
+	UserNoteService services.UserNoteServiceInterface // UserNoteService dependency (interface)
+}
+
+// NewUserNoteController creates and returns a new UserNoteController instance.
+func NewUserNoteController(userNoteService services.UserNoteServiceInterface) *UserNoteController {
+	return &UserNoteController{
+		UserNoteService: userNoteService,
+	}
+}
+
+// UserNoteRequest represents the expected structure for writing or editing a note on a user.
+type UserNoteRequest struct {
+	Content string `json:"content" validate:"required,max=5000"`
+}
+
+// GetNotes lists the notes on a user, newest first.
+func (c *UserNoteController) GetNotes(ctx *fiber.Ctx) error {
+	userID := ctx.Params("id")
+	page, limit := paginationParams(ctx)
+
+	notes, totalPages, totalItems, err := c.UserNoteService.GetNotes(ctx.UserContext(), userID, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching user notes", "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_user_notes"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "user_notes_retrieved_successfully"),
+		"data":        notes,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// CreateNote adds a note on a user, written by the authenticated admin.
+func (c *UserNoteController) CreateNote(ctx *fiber.Ctx) error {
+	userID := ctx.Params("id")
+	authorID, _ := middleware.GetUserIDFromJWT(ctx)
+
+	req := new(UserNoteRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create user note request body", "user_id", userID, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	note := &models.UserNote{UserID: userID, AuthorID: &authorID, Content: req.Content}
+	if err := c.UserNoteService.CreateNote(ctx.UserContext(), note); err != nil {
+		requestLogger(ctx).Error("Error creating user note", "user_id", userID, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_user_note"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_note_created_successfully"),
+		"data":    note,
+	})
+}
+
+// UpdateNote replaces the content of a note on a user.
+func (c *UserNoteController) UpdateNote(ctx *fiber.Ctx) error {
+	userID, id := ctx.Params("id"), ctx.Params("noteId")
+
+	req := new(UserNoteRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update user note request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	note := &models.UserNote{ID: id, UserID: userID, Content: req.Content}
+	if err := c.UserNoteService.UpdateNote(ctx.UserContext(), note); err != nil {
+		requestLogger(ctx).Error("Error updating user note", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_user_note"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_note_updated_successfully"),
+		"data":    note,
+	})
+}
+
+// DeleteNote deletes a note on a user.
+func (c *UserNoteController) DeleteNote(ctx *fiber.Ctx) error {
+	userID, id := ctx.Params("id"), ctx.Params("noteId")
+
+	if err := c.UserNoteService.DeleteNote(ctx.UserContext(), userID, id); err != nil {
+		requestLogger(ctx).Error("Error deleting user note", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_user_note"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "user_note_deleted_successfully"),
+	})
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/webhooks"
+)
+
+// validate checks request DTOs against their validate tags.
+var validate = newValidator()
+
+// newValidator returns a validator that reports fields by their JSON names and understands
+// the decimal amounts used for prices.
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	// price accepts amounts that are not negative and have at most 2 decimal places
+	_ = v.RegisterValidation("price", func(fl validator.FieldLevel) bool {
+		d, ok := fl.Field().Interface().(decimal.Decimal)
+		return ok && models.IsValidPrice(d)
+	})
+	// webhook_url refuses URLs of webhook endpoints on internal hosts, see webhooks.CheckURL
+	_ = v.RegisterValidation("webhook_url", func(fl validator.FieldLevel) bool {
+		return config.AppConfig.WebhookAllowPrivateNetworks || webhooks.CheckURL(fl.Field().String()) == nil
+	})
+	return v
+}
+
+// FieldError describes why a field of a request body or a query parameter was rejected.
+// Every 422 Unprocessable Entity response lists them under "errors", so clients can show
+// them next to their form fields; malformed bodies are answered with 400 Bad Request instead.
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path of the field, e.g., "items[0].quantity", or name of the query parameter; empty if not about one field
+	Code    string `json:"code"`    // Check that failed, e.g., "required" or "min"
+	Message string `json:"message"` // Human-readable explanation, in the language of the request
+}
+
+// validationErrors validates req against the validate tags of its fields and returns every
+// violation, explained in the language of the request, or nil if req is valid.
+func validationErrors(ctx *fiber.Ctx, req interface{}) []FieldError {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+	var violations validator.ValidationErrors
+	if !errors.As(err, &violations) {
+		return []FieldError{{Code: "invalid", Message: err.Error()}}
+	}
+
+	fieldErrors := make([]FieldError, 0, len(violations))
+	for _, violation := range violations {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldPath(violation),
+			Code:    violation.Tag(),
+			Message: ruleMessage(ctx, violation),
+		})
+	}
+	return fieldErrors
+}
+
+// validationFailed responds with 422 Unprocessable Entity and the field errors.
+func validationFailed(ctx *fiber.Ctx, fieldErrors []FieldError) error {
+	return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"success": false,
+		"message": msg(ctx, "validation_failed"),
+		"errors":  fieldErrors,
+	})
+}
+
+// invalidParam responds with 422 Unprocessable Entity for the query parameter or body field
+// that failed the check code, explained by the message with the given key.
+func invalidParam(ctx *fiber.Ctx, field, code, key string, args ...interface{}) error {
+	return validationFailed(ctx, []FieldError{{Field: field, Code: code, Message: msg(ctx, key, args...)}})
+}
+
+// fieldPath returns the JSON path of the violating field, without the name of the request type.
+func fieldPath(violation validator.FieldError) string {
+	_, path, found := strings.Cut(violation.Namespace(), ".")
+	if !found {
+		return violation.Field()
+	}
+	return path
+}
+
+// ruleMessage explains a violation in words, in the language of the request.
+func ruleMessage(ctx *fiber.Ctx, violation validator.FieldError) string {
+	param := violation.Param()
+	kind := violation.Kind()
+	if kind == reflect.Ptr {
+		kind = violation.Type().Elem().Kind()
+	}
+	unit := ""
+	switch kind {
+	case reflect.String:
+		unit = msg(ctx, "unit_characters")
+	case reflect.Slice, reflect.Map, reflect.Array:
+		unit = msg(ctx, "unit_items")
+	}
+
+	switch rule := violation.Tag(); rule {
+	case "required", "required_without":
+		return msg(ctx, "rule_required")
+	case "uuid", "uuid4":
+		return msg(ctx, "rule_uuid")
+	case "url", "http_url":
+		return msg(ctx, "rule_url")
+	case "oneof":
+		return msg(ctx, "rule_oneof", strings.Join(strings.Fields(param), ", "))
+	case "min", "max", "len":
+		return msg(ctx, "rule_"+rule, param, unit)
+	case "gt", "gte", "lt", "lte", "ne":
+		return msg(ctx, "rule_"+rule, param)
+	case "email", "e164", "unique", "price", "alphanum", "webhook_url":
+		return msg(ctx, "rule_"+rule)
+	default:
+		return msg(ctx, "rule_invalid", rule)
+	}
+}
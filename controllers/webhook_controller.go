@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// WebhookController handles the admin endpoints for outgoing webhooks.
+type WebhookController struct {
+	WebhookService services.WebhookServiceInterface // WebhookService dependency (interface)
+}
+
+// NewWebhookController creates and returns a new WebhookController instance.
+func NewWebhookController(webhookService services.WebhookServiceInterface) *WebhookController {
+	return &WebhookController{
+		WebhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest represents the expected structure for registering a webhook endpoint.
+// The oneof rule of Events must list webhooks.Events.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url,webhook_url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,unique,dive,oneof=user.created order.paid"`
+	Active *bool    `json:"active"` // Defaults to true
+}
+
+// UpdateWebhookRequest represents the expected structure for updating a webhook endpoint.
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" validate:"omitnil,http_url,webhook_url,max=2048"` // Use pointers to differentiate between zero value and not provided
+	Events []string `json:"events" validate:"omitnil,min=1,unique,dive,oneof=user.created order.paid"`
+	Active *bool    `json:"active"`
+}
+
+// GetWebhooks lists the webhooks of the tenant with pagination.
+func (c *WebhookController) GetWebhooks(ctx *fiber.Ctx) error {
+	page, limit := paginationParams(ctx)
+
+	webhookList, totalPages, totalItems, err := c.WebhookService.GetWebhooks(ctx.UserContext(), page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching webhooks", "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_webhooks"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "webhooks_retrieved_successfully"),
+		"data":        webhookList,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
+
+// GetWebhookByID retrieves a single webhook by its ID.
+func (c *WebhookController) GetWebhookByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	webhook, err := c.WebhookService.GetWebhookByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching webhook", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_webhook"),
+		})
+	}
+	if webhook == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "webhook_not_found"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "webhook_retrieved_successfully"),
+		"data":    webhook,
+	})
+}
+
+// CreateWebhook registers a webhook endpoint. The response carries its signing secret, which
+// is never shown again.
+func (c *WebhookController) CreateWebhook(ctx *fiber.Ctx) error {
+	req := new(CreateWebhookRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing create webhook request body", "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	webhook := &models.Webhook{URL: req.URL, Events: req.Events, Active: req.Active == nil || *req.Active}
+	if err := c.WebhookService.CreateWebhook(ctx.UserContext(), webhook); err != nil {
+		requestLogger(ctx).Error("Error creating webhook", "url", req.URL, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_create_webhook"),
+		})
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "webhook_created_successfully"),
+		"data":    webhook,
+	})
+}
+
+// UpdateWebhook changes the URL, events or active flag of a webhook.
+func (c *WebhookController) UpdateWebhook(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	webhook, err := c.WebhookService.GetWebhookByID(ctx.UserContext(), id)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching existing webhook for update", "id", id, "error", err)
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_webhook"),
+		})
+	}
+	if webhook == nil {
+		return ctx.Status(http.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "webhook_not_found"),
+		})
+	}
+
+	req := new(UpdateWebhookRequest)
+	if err := ctx.BodyParser(req); err != nil {
+		requestLogger(ctx).Error("Error parsing update webhook request body", "id", id, "error", err)
+		return ctx.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "invalid_request_body"),
+		})
+	}
+	if errs := validationErrors(ctx, req); errs != nil {
+		return validationFailed(ctx, errs)
+	}
+
+	// Apply updates only if provided in the request
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := c.WebhookService.UpdateWebhook(ctx.UserContext(), webhook); err != nil {
+		requestLogger(ctx).Error("Error updating webhook", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_update_webhook"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "webhook_updated_successfully"),
+		"data":    webhook,
+	})
+}
+
+// DeleteWebhook removes a webhook and its delivery history.
+func (c *WebhookController) DeleteWebhook(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	if err := c.WebhookService.DeleteWebhook(ctx.UserContext(), id); err != nil {
+		requestLogger(ctx).Error("Error deleting webhook", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_delete_webhook"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": msg(ctx, "webhook_deleted_successfully"),
+	})
+}
+
+// GetDeliveries lists the delivery history of a webhook, newest first, with pagination.
+// Example: GET /api/webhooks/:id/deliveries?status=failed&page=1
+func (c *WebhookController) GetDeliveries(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	status := ctx.Query("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed:
+	default:
+		return invalidParam(ctx, "status", "oneof", "invalid_webhook_delivery_status", status)
+	}
+	page, limit := paginationParams(ctx)
+
+	deliveries, totalPages, totalItems, err := c.WebhookService.GetDeliveries(ctx.UserContext(), id, status, page, limit)
+	if err != nil {
+		requestLogger(ctx).Error("Error fetching webhook deliveries", "id", id, "error", err)
+		if isDomainError(err) {
+			return err
+		}
+		return ctx.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": msg(ctx, "failed_to_retrieve_webhook_deliveries"),
+		})
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success":     true,
+		"message":     msg(ctx, "webhook_deliveries_retrieved_successfully"),
+		"data":        deliveries,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  totalItems,
+	})
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrCircuitOpen is returned by DB methods while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("database circuit breaker is open")
+
+// Breaker is a circuit breaker for the database connection. It opens after a number of
+// consecutive connection failures, so requests fail fast instead of waiting on a database
+// that is down. While open it lets a single trial through every cooldown; the first
+// successful query closes it again.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int           // Consecutive connection failures that open the breaker
+	cooldown  time.Duration // Time between trials while open
+	failures  int
+	open      bool
+	nextTrial time.Time
+}
+
+// NewBreaker returns a closed breaker that opens after threshold consecutive connection failures.
+// A threshold below 1 disables the breaker.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a request may use the database. While the breaker is open, it
+// allows one trial per cooldown.
+func (b *Breaker) Allow() bool {
+	if b == nil || b.threshold < 1 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return true
+	}
+	now := time.Now()
+	if now.Before(b.nextTrial) {
+		return false
+	}
+	b.nextTrial = now.Add(b.cooldown)
+	return true
+}
+
+// Open reports whether the breaker is open.
+func (b *Breaker) Open() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// RetryAfter returns how long until the breaker allows the next trial.
+func (b *Breaker) RetryAfter() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return 0
+	}
+	return max(time.Until(b.nextTrial), 0)
+}
+
+// Record updates the breaker with the outcome of a database call. Only connection failures
+// count against the database; query errors such as constraint violations or sql.ErrNoRows
+// prove it is reachable.
+func (b *Breaker) Record(err error) {
+	if b == nil || b.threshold < 1 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !isConnectionError(err) {
+		b.failures = 0
+		b.open = false
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold && !b.open {
+		b.open = true
+		b.nextTrial = time.Now().Add(b.cooldown)
+	}
+}
+
+// isConnectionError reports whether err means the database couldn't be reached at all.
+// Deadlines are excluded: they are usually hit by slow queries, not by a database that is down.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.As(err, &connectErr) ||
+		errors.As(err, &netErr)
+}
+
+// Backoff returns the delay before connection attempt number attempt (starting at 0): base
+// doubled per attempt and capped at maxDelay, with up to half of it replaced by random jitter
+// so restarting instances don't retry in lockstep.
+func Backoff(attempt int, base, maxDelay time.Duration) time.Duration {
+	delay := maxDelay
+	if attempt < 32 && base<<attempt > 0 && base<<attempt < maxDelay {
+		delay = base << attempt
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
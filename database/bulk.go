@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// bulkInsertMaxParams bounds the parameters of one multi-row INSERT of BulkInsert. It is far
+// below the limits of SQLite (32766) and MySQL (65535), as the SQLite driver binds parameters
+// in time quadratic in their number: larger statements made seeding slower, not faster.
+const bulkInsertMaxParams = 250
+
+// errNotPgx reports a connection that isn't a pgx one, e.g., of a DB opened by NewDB over
+// another driver, which BulkInsert then fills with INSERTs.
+var errNotPgx = errors.New("not a pgx connection")
+
+// BulkInsert inserts rows, whose values are in the order of columns, into table in as few
+// round trips as possible: for PostgreSQL, with a single COPY streamed through pgx, and for the
+// other dialects, with multi-row INSERTs in one transaction. Either way all rows are inserted or
+// none. It suits imports and seeding, where inserting thousands of rows one by one would be slow.
+// table and columns are written into the statement as is and must not come from users.
+func (db *DB) BulkInsert(ctx context.Context, table string, columns []string, rows [][]interface{}) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if db.dialect.Name() == DialectPostgres {
+		n, err := db.copyFrom(ctx, table, columns, rows)
+		if !errors.Is(err, errNotPgx) {
+			return n, err
+		}
+	}
+
+	batch := bulkInsertMaxParams / len(columns)
+	err := WithTx(ctx, db, func(tx *Tx) error {
+		for start := 0; start < len(rows); start += batch {
+			end := min(start+batch, len(rows))
+			values := make([]string, 0, end-start)
+			args := make([]interface{}, 0, (end-start)*len(columns))
+			for _, row := range rows[start:end] {
+				if len(row) != len(columns) {
+					return fmt.Errorf("row of %d values for %d columns of %s", len(row), len(columns), table)
+				}
+				values = append(values, "("+Placeholders(len(args)+1, len(columns))+")")
+				args = append(args, row...)
+			}
+			query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, strings.Join(columns, ", "), strings.Join(values, ", "))
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to bulk insert into %s: %w", table, err)
+	}
+	return int64(len(rows)), nil
+}
+
+// copyFrom inserts rows into table with COPY ... FROM STDIN on a pgx connection of the pool.
+// It returns errNotPgx if the pool doesn't hold pgx connections.
+func (db *DB) copyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) (int64, error) {
+	conn, err := db.Conn(ctx)
+	db.breaker.Record(err)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var n int64
+	start := time.Now()
+	err = conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errNotPgx
+		}
+		var err error
+		n, err = pgxConn.Conn().CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+		return err
+	})
+	if errors.Is(err, errNotPgx) {
+		return 0, err
+	}
+	observe(ctx, "COPY "+table, start, err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy into %s: %w", table, err)
+	}
+	return n, nil
+}
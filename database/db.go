@@ -1,179 +1,298 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/anpsniper/anpbayu-be/config" // Import your config package
-	_ "github.com/lib/pq"                    // PostgreSQL driver
+	"github.com/go-sql-driver/mysql"         // MySQL driver
+	"github.com/jackc/pgx/v5"                // PostgreSQL identifier quoting
+	"github.com/jackc/pgx/v5/pgxpool"        // PostgreSQL connection pool
+	"github.com/jackc/pgx/v5/stdlib"         // database/sql adapter for pgx
+	_ "modernc.org/sqlite"                   // SQLite driver
 )
 
-// DB is the global database connection pool.
-// It's exported so other packages (like models) can access it.
-var DB *sql.DB
+// DB is the connection pool handed to services through their constructors.
+// Queries are written with PostgreSQL-style $N placeholders and rebound for the configured dialect.
+type DB struct {
+	*sql.DB
+	dialect Dialect
+	breaker *Breaker // nil unless set by ConnectDatabase
+}
+
+// NewDB wraps an open database/sql pool that speaks the given dialect.
+func NewDB(db *sql.DB, dialect Dialect) *DB {
+	return &DB{DB: db, dialect: dialect}
+}
+
+// Dialect returns the dialect queries are rebound for.
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
+// Breaker returns the circuit breaker that tracks connection failures of the pool.
+// It is nil when the DB wasn't created by ConnectDatabase; a nil Breaker always allows requests.
+func (db *DB) Breaker() *Breaker {
+	return db.breaker
+}
+
+// ExecContext executes a statement that doesn't return rows.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = db.dialect.Rebind(query, args)
+	start := time.Now()
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	observe(ctx, query, start, err)
+	db.breaker.Record(err)
+	return result, err
+}
+
+// QueryContext executes a query that returns rows.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = db.dialect.Rebind(query, args)
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	observe(ctx, query, start, err) // Until the first row, which the rest usually follow shortly
+	db.breaker.Record(err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = db.dialect.Rebind(query, args)
+	start := time.Now()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	observe(ctx, query, start, row.Err())
+	db.breaker.Record(row.Err())
+	return row
+}
+
+// Exec is ExecContext with a background context.
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// Query is QueryContext with a background context.
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRow is QueryRowContext with a background context.
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+// InsertReturningID runs an INSERT into a table with an auto-incrementing id column
+// and returns the generated id.
+func (db *DB) InsertReturningID(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if db.dialect.SupportsReturning() {
+		var id int64
+		err := db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// BeginTx starts a transaction whose queries are rebound like the DB's.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	db.breaker.Record(err)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
+}
+
+// Delays between connection attempts of InitDatabase, see Backoff.
+const (
+	connectBackoffBase = 500 * time.Millisecond
+	connectBackoffMax  = 30 * time.Second
+)
+
+// InitDatabase connects to the database selected by DB_DIALECT, applies pending migrations
+// and returns the connection pool. For PostgreSQL the underlying *pgxpool.Pool is returned
+// as well, since it exposes pool statistics; it is nil for the other dialects.
+func InitDatabase(cfg *config.Config) (*DB, *pgxpool.Pool, error) {
+	db, pool, err := ConnectDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Bring the schema up to date. Schema changes are added as new files in database/migrations/<dialect>.
+	slog.Info("Applying database migrations")
+	if err := MigrateUp(db); err != nil {
+		CloseDatabase(db, pool)
+		return nil, nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return db, pool, nil
+}
 
-// InitDatabase initializes the PostgreSQL database connection and creates necessary tables.
-func InitDatabase(cfg *config.Config) error {
-	var err error
-	// Implement a retry mechanism for database connection
-	for i := 0; i < 5; i++ { // Try to connect 5 times
-		DB, err = sql.Open("postgres", cfg.DBURL)
+// ConnectDatabase connects to the database selected by DB_DIALECT like InitDatabase, but leaves
+// the schema as it is, e.g., for the migrate command to roll it back.
+func ConnectDatabase(cfg *config.Config) (*DB, *pgxpool.Pool, error) {
+	dialect, err := DialectFor(cfg.DBDialect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sqlDB *sql.DB
+	var pool *pgxpool.Pool
+	// Retry with exponential backoff, so a database that is still starting up gets time to come up
+	for attempt := 0; attempt < cfg.DBConnectAttempts; attempt++ {
+		sqlDB, pool, err = openDatabase(dialect, cfg.DBURL, cfg.DBSchema, cfg.DBStatementTimeout)
 		if err == nil {
-			err = DB.Ping() // Ping the database to verify the connection
-			if err == nil {
-				log.Println("Successfully connected to the database!")
-				break
+			slog.Info("Successfully connected to the database", "dialect", dialect.Name())
+			break
+		}
+		if attempt == cfg.DBConnectAttempts-1 {
+			slog.Error("Failed to connect to database", "attempt", attempt+1, "max_attempts", cfg.DBConnectAttempts, "error", err)
+			break
+		}
+		delay := Backoff(attempt, connectBackoffBase, connectBackoffMax)
+		slog.Warn("Failed to connect to database, retrying", "attempt", attempt+1, "max_attempts", cfg.DBConnectAttempts, "error", err, "retry_in", delay.Round(time.Millisecond))
+		time.Sleep(delay)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to the database after %d attempts: %w", cfg.DBConnectAttempts, err)
+	}
+
+	db := NewDB(sqlDB, dialect)
+	db.breaker = NewBreaker(cfg.DBBreakerThreshold, cfg.DBBreakerCooldown)
+	return db, pool, nil
+}
+
+// openDatabase opens and pings a connection pool for the dialect.
+// Tables are created and queried in schema, which only applies to PostgreSQL; MySQL uses the
+// database of the DSN and SQLite has no schemas.
+// A non-zero statementTimeout makes the server abort statements running longer than that.
+func openDatabase(dialect Dialect, dsn, schema string, statementTimeout time.Duration) (*sql.DB, *pgxpool.Pool, error) {
+	switch dialect.Name() {
+	case DialectPostgres:
+		return openPostgres(dsn, schema, statementTimeout)
+	case DialectMySQL:
+		mysqlConfig, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid database URL: %w", err)
+		}
+		mysqlConfig.ParseTime = true       // Scan DATETIME columns into time.Time
+		mysqlConfig.MultiStatements = true // Migration files contain several statements
+		mysqlConfig.ClientFoundRows = true // Report matched rather than changed rows, like the other dialects
+		if statementTimeout > 0 {
+			// MySQL can only limit the execution time of SELECT statements
+			if mysqlConfig.Params == nil {
+				mysqlConfig.Params = map[string]string{}
 			}
+			mysqlConfig.Params["max_execution_time"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
 		}
-		log.Printf("Failed to connect to database (attempt %d/5): %v. Retrying in 5 seconds...", i+1, err)
-		time.Sleep(5 * time.Second) // Wait before retrying
+		dsn = mysqlConfig.FormatDSN()
+	case DialectSQLite:
+		// SQLite has no statement timeout; long queries are interrupted by the request deadline instead.
+		// Foreign keys are off by default in SQLite and must be enabled per connection
+		if !strings.Contains(dsn, "foreign_keys") {
+			dsn = appendQueryParam(dsn, "_pragma=foreign_keys(1)")
+		}
+		// Store timestamps in a format SQLite's date functions and comparisons understand
+		if !strings.Contains(dsn, "_time_format") {
+			dsn = appendQueryParam(dsn, "_time_format=sqlite")
+		}
+	}
+
+	db, err := sql.Open(dialect.DriverName(), dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Ping(); err != nil { // Ping the database to verify the connection
+		db.Close()
+		return nil, nil, err
 	}
 
+	// Set connection pool settings
+	if dialect.Name() == DialectSQLite {
+		// SQLite allows a single writer; one connection avoids "database is locked" errors
+		// and keeps in-memory databases alive for the lifetime of the pool.
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(25)
+		db.SetMaxIdleConns(10)
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
+	return db, nil, nil
+}
+
+// openPostgres opens a pgx connection pool and wraps it in a *sql.DB.
+// When schema is set, every connection resolves unqualified table names in it, and it is created
+// if it doesn't exist; otherwise the search_path of dsn, if any, applies. A schema disagreeing
+// with the search_path of dsn is an error, as the tables would move out of sight.
+func openPostgres(dsn, schema string, statementTimeout time.Duration) (*sql.DB, *pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		return fmt.Errorf("could not connect to the database after multiple retries: %w", err)
+		return nil, nil, fmt.Errorf("invalid database URL: %w", err)
 	}
 
 	// Set connection pool settings
-	DB.SetMaxOpenConns(25)
-	DB.SetMaxIdleConns(10)
-	DB.SetConnMaxLifetime(5 * time.Minute)
-
-	// SQL DDL to create tables if they don't exist
-	createTablesSQL := `
-	-- Function to update 'updated_at' column
-	CREATE OR REPLACE FUNCTION update_updated_at_column()
-	RETURNS TRIGGER AS $$
-	BEGIN
-		NEW.updated_at = NOW();
-		RETURN NEW;
-	END;
-	$$ LANGUAGE plpgsql;
-
-	-- Create 'roles' table
-	CREATE TABLE IF NOT EXISTS roles (
-		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-		name VARCHAR(50) UNIQUE NOT NULL,
-		description TEXT,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Trigger for 'roles' table
-	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_roles_updated_at') THEN
-			CREATE TRIGGER update_roles_updated_at
-			BEFORE UPDATE ON roles
-			FOR EACH ROW
-			EXECUTE FUNCTION update_updated_at_column();
-		END IF;
-	END $$;
-
-	-- Create 'users' table
-	CREATE TABLE IF NOT EXISTS users (
-		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-		username VARCHAR(100) NOT NULL,
-		email VARCHAR(255) UNIQUE NOT NULL,
-		password_hash VARCHAR(255) NOT NULL,
-		role_id UUID NOT NULL, -- Foreign key to roles table
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		CONSTRAINT fk_users_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE RESTRICT
-	);
-
-	-- Trigger for 'users' table
-	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_users_updated_at') THEN
-			CREATE TRIGGER update_users_updated_at
-			BEFORE UPDATE ON users
-			FOR EACH ROW
-			EXECUTE FUNCTION update_updated_at_column();
-		END IF;
-	END $$;
-
-	-- Create 'posts' table
-	CREATE TABLE IF NOT EXISTS posts (
-		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-		user_id UUID NOT NULL,
-		title VARCHAR(255) NOT NULL,
-		content TEXT NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		CONSTRAINT fk_posts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-	);
-
-	-- Trigger for 'posts' table
-	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_posts_updated_at') THEN
-			CREATE TRIGGER update_posts_updated_at
-			BEFORE UPDATE ON posts
-			FOR EACH ROW
-			EXECUTE FUNCTION update_updated_at_column();
-		END IF;
-	END $$;
-
-	-- Create 'comments' table
-	CREATE TABLE IF NOT EXISTS comments (
-		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-		post_id UUID NOT NULL,
-		user_id UUID NOT NULL,
-		content TEXT NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		CONSTRAINT fk_comments_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
-		CONSTRAINT fk_comments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-	);
-
-	-- Trigger for 'comments' table
-	DO $$ BEGIN
-		IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_comments_updated_at') THEN
-			CREATE TRIGGER update_comments_updated_at
-			BEFORE UPDATE ON comments
-			FOR EACH ROW
-			EXECUTE FUNCTION update_updated_at_column();
-		END IF;
-	END $$;
-
-	-- Create 'sessions' table
-	CREATE TABLE IF NOT EXISTS sessions (
-		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-		user_id UUID NOT NULL,
-		token VARCHAR(255) UNIQUE NOT NULL,
-		expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-	);
-
-	-- Create 'user_logs' table
-	CREATE TABLE IF NOT EXISTS user_logs (
-		id serial4 NOT NULL,
-		user_id uuid NOT NULL,
-		login_at timestamptz DEFAULT now() NOT NULL,
-		logout_at timestamptz NULL,
-		CONSTRAINT user_logs_pkey PRIMARY KEY (id),
-		CONSTRAINT user_logs_user_id_fkey FOREIGN KEY (user_id) REFERENCES db_bayneta.users(id) ON DELETE CASCADE
-	);
-	`
-
-	log.Println("Creating tables if they do not exist...")
-	_, err = DB.Exec(createTablesSQL)
+	poolConfig.MaxConns = 25
+	poolConfig.MinConns = 2
+	poolConfig.MaxConnLifetime = 5 * time.Minute
+	poolConfig.MaxConnIdleTime = 5 * time.Minute
+	if statementTimeout > 0 {
+		// Set on every new connection, so a slow query is cancelled by the server itself
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
+	}
+	quotedSchema := pgx.Identifier{schema}.Sanitize()
+	if schema != "" {
+		if searchPath, ok := poolConfig.ConnConfig.RuntimeParams["search_path"]; ok && searchPath != schema && searchPath != quotedSchema {
+			return nil, nil, fmt.Errorf("DB_SCHEMA %q disagrees with search_path %q of the database URL", schema, searchPath)
+		}
+		// Queries and migrations don't qualify table names, so the schema decides where they live
+		poolConfig.ConnConfig.RuntimeParams["search_path"] = quotedSchema
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create tables: %w", err)
+		return nil, nil, err
+	}
+	if err := pool.Ping(context.Background()); err != nil { // Ping the database to verify the connection
+		pool.Close()
+		return nil, nil, err
+	}
+	if schema != "" {
+		if _, err := pool.Exec(context.Background(), "CREATE SCHEMA IF NOT EXISTS "+quotedSchema); err != nil {
+			pool.Close()
+			return nil, nil, fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
 	}
-	log.Println("Tables created or already exist.")
 
-	return nil
+	// Connections are managed by the pgx pool; the *sql.DB wrapper keeps no idle connections of its own.
+	return stdlib.OpenDBFromPool(pool), pool, nil
 }
 
-// CloseDatabase closes the global database connection.
-func CloseDatabase() {
-	if DB != nil {
-		err := DB.Close()
-		if err != nil {
-			log.Printf("Error closing database connection: %v", err)
-		} else {
-			log.Println("Database connection closed.")
+// appendQueryParam appends a key=value parameter to a URL-style DSN.
+func appendQueryParam(dsn, param string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+	return dsn + "?" + param
+}
+
+// CloseDatabase closes the connection pool, including the underlying pgx pool if there is one.
+func CloseDatabase(db *DB, pool *pgxpool.Pool) {
+	if db != nil {
+		if err := db.Close(); err != nil {
+			slog.Error("Error closing database connection", "error", err)
 		}
 	}
+	if pool != nil {
+		pool.Close()
+	}
+	slog.Info("Database connection closed")
 }
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Supported values of the DB_DIALECT setting.
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+	DialectMySQL    = "mysql"
+)
+
+// Dialect hides the SQL differences between the supported databases.
+// Queries are written once with PostgreSQL-style $N placeholders; DB and Tx rebind them
+// for the configured dialect before they reach the driver.
+type Dialect interface {
+	// Name returns the DB_DIALECT value of the dialect; it also names its migrations directory.
+	Name() string
+	// DriverName returns the database/sql driver the dialect connects with.
+	DriverName() string
+	// Rebind rewrites the $N placeholders of query into the dialect's own style,
+	// reordering args when the dialect only has positional placeholders.
+	Rebind(query string, args []interface{}) (string, []interface{})
+	// ILike returns a case-insensitive pattern match of column against the $n parameter.
+	ILike(column string, n int) string
+	// ForUpdate returns the row locking clause appended to a SELECT inside a transaction.
+	// With skipLocked, rows locked by other transactions are skipped instead of waited for.
+	ForUpdate(skipLocked bool) string
+	// InsertIgnore turns a plain INSERT statement into one that skips rows violating a unique constraint.
+	InsertIgnore(insert string) string
+	// SupportsReturning reports whether INSERT/UPDATE ... RETURNING is available.
+	SupportsReturning() bool
+}
+
+// DialectFor returns the dialect registered under name.
+func DialectFor(name string) (Dialect, error) {
+	switch name {
+	case DialectPostgres:
+		return postgresDialect{}, nil
+	case DialectSQLite:
+		return sqliteDialect{}, nil
+	case DialectMySQL:
+		return mysqlDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database dialect %q (expected %s, %s or %s)", name, DialectPostgres, DialectSQLite, DialectMySQL)
+	}
+}
+
+// postgresDialect is the reference dialect; queries are already written for it.
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string       { return DialectPostgres }
+func (postgresDialect) DriverName() string { return "pgx" }
+
+func (postgresDialect) Rebind(query string, args []interface{}) (string, []interface{}) {
+	return query, args
+}
+
+func (postgresDialect) ILike(column string, n int) string {
+	return fmt.Sprintf("%s ILIKE $%d", column, n)
+}
+
+func (postgresDialect) ForUpdate(skipLocked bool) string {
+	if skipLocked {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return " FOR UPDATE"
+}
+
+func (postgresDialect) InsertIgnore(insert string) string {
+	return insert + " ON CONFLICT DO NOTHING"
+}
+
+func (postgresDialect) SupportsReturning() bool { return true }
+
+// sqliteDialect targets SQLite for local development and testing.
+// SQLite serializes writers, so row locks are unnecessary and omitted.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string       { return DialectSQLite }
+func (sqliteDialect) DriverName() string { return "sqlite" }
+
+func (sqliteDialect) Rebind(query string, args []interface{}) (string, []interface{}) {
+	// SQLite understands numbered ?N parameters, so placeholders can be reused like in PostgreSQL
+	return rebindPlaceholders(query, func(n int) string { return "?" + strconv.Itoa(n) }), args
+}
+
+func (sqliteDialect) ILike(column string, n int) string {
+	return fmt.Sprintf("%s LIKE $%d", column, n) // LIKE is case-insensitive for ASCII in SQLite
+}
+
+func (sqliteDialect) ForUpdate(skipLocked bool) string { return "" }
+
+func (sqliteDialect) InsertIgnore(insert string) string {
+	return insert + " ON CONFLICT DO NOTHING"
+}
+
+func (sqliteDialect) SupportsReturning() bool { return true }
+
+// mysqlDialect targets MySQL 8.0 or newer.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string       { return DialectMySQL }
+func (mysqlDialect) DriverName() string { return "mysql" }
+
+func (mysqlDialect) Rebind(query string, args []interface{}) (string, []interface{}) {
+	// MySQL only has positional ? parameters, so every $N gets its own copy of the Nth argument
+	rebound := make([]interface{}, 0, len(args))
+	query = rebindPlaceholders(query, func(n int) string {
+		if n <= len(args) {
+			rebound = append(rebound, args[n-1])
+		}
+		return "?"
+	})
+	return query, rebound
+}
+
+func (mysqlDialect) ILike(column string, n int) string {
+	return fmt.Sprintf("%s LIKE $%d", column, n) // The default collations are case-insensitive
+}
+
+func (mysqlDialect) ForUpdate(skipLocked bool) string {
+	if skipLocked {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return " FOR UPDATE"
+}
+
+func (mysqlDialect) InsertIgnore(insert string) string {
+	return strings.Replace(insert, "INSERT", "INSERT IGNORE", 1)
+}
+
+func (mysqlDialect) SupportsReturning() bool { return false }
+
+// Placeholders returns count comma-separated placeholders starting at $start,
+// e.g. for building an IN (...) list: Placeholders(2, 3) returns "$2, $3, $4".
+func Placeholders(start, count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// rebindPlaceholders replaces every $N placeholder outside of quoted strings
+// with the result of placeholder(N).
+func rebindPlaceholders(query string, placeholder func(n int) string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	inQuote := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c == '\'' {
+			inQuote = !inQuote
+		}
+		if c != '$' || inQuote {
+			b.WriteByte(c)
+			continue
+		}
+		j := i + 1
+		for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(c) // A lone $ isn't a placeholder
+			continue
+		}
+		n, _ := strconv.Atoi(query[i+1 : j])
+		b.WriteString(placeholder(n))
+		i = j - 1
+	}
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queryDuration measures the statements of every DB and Tx, by operation (see WithOperation)
+// and outcome. It is exported once registered by RegisterMetrics.
+var queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "db_query_duration_seconds",
+	Help:    "Duration of database statements, by operation and outcome (ok or error).",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+}, []string{"operation", "outcome"})
+
+type operationKey struct{}
+
+// WithOperation labels the statements run with ctx by operation in the query duration metrics,
+// e.g., "users.list". Statements without an operation are labeled by their table and kind,
+// e.g., "posts.select", or "posts.copy" for BulkInsert.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// RegisterMetrics registers the query duration histogram and the statistics of the connection
+// pool of db with registerer. For PostgreSQL, the statistics are read from pool, which manages
+// the connections of db. Registering again, e.g., for another server in the same process,
+// replaces the pool statistics.
+func RegisterMetrics(registerer prometheus.Registerer, db *DB, pool *pgxpool.Pool) error {
+	if err := register(registerer, queryDuration); err != nil {
+		return err
+	}
+	return register(registerer, &poolCollector{db: db.DB, pool: pool})
+}
+
+// register registers collector with registerer, replacing the collector of the same metrics
+// registered before.
+func register(registerer prometheus.Registerer, collector prometheus.Collector) error {
+	err := registerer.Register(collector)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		registerer.Unregister(registered.ExistingCollector)
+		return registerer.Register(collector)
+	}
+	return err
+}
+
+// observe records the duration of query, run with ctx since start and failed with err unless
+// it is nil. Queries finding no row succeeded.
+func observe(ctx context.Context, query string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		outcome = "error"
+	}
+	operation, ok := ctx.Value(operationKey{}).(string)
+	if !ok {
+		operation = statementLabel(query)
+	}
+	queryDuration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+// statementLabel labels a statement by its table and kind, e.g., "users.select" for
+// "SELECT ... FROM users u JOIN ...", or by its kind alone if the table can't be told.
+// Statements of other kinds are labeled "other", so the labels stay few.
+func statementLabel(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other"
+	}
+	kind := strings.ToLower(fields[0])
+	var tableAfter string // Keyword preceding the table
+	switch kind {
+	case "select", "delete":
+		tableAfter = "from"
+	case "insert":
+		tableAfter = "into"
+	case "update":
+		tableAfter = "update"
+	case "copy":
+		tableAfter = "copy"
+	default:
+		return "other"
+	}
+	for i := 0; i < len(fields)-1; i++ {
+		if strings.EqualFold(fields[i], tableAfter) {
+			table, _, _ := strings.Cut(fields[i+1], "(")
+			if table = strings.Trim(table, "\"`,;"); table != "" {
+				return strings.ToLower(table) + "." + kind
+			}
+			break
+		}
+	}
+	return kind
+}
+
+// poolCollector reports the statistics of a connection pool: those of the pgx pool for
+// PostgreSQL, and of the database/sql pool for the other dialects.
+type poolCollector struct {
+	db   *sql.DB
+	pool *pgxpool.Pool // nil unless the database is PostgreSQL
+}
+
+var (
+	poolOpenDesc    = prometheus.NewDesc("db_pool_open_connections", "Open connections of the database pool, in use or idle.", nil, nil)
+	poolInUseDesc   = prometheus.NewDesc("db_pool_in_use_connections", "Connections of the database pool in use.", nil, nil)
+	poolIdleDesc    = prometheus.NewDesc("db_pool_idle_connections", "Idle connections of the database pool.", nil, nil)
+	poolMaxDesc     = prometheus.NewDesc("db_pool_max_open_connections", "Most connections the database pool opens.", nil, nil)
+	poolWaitsDesc   = prometheus.NewDesc("db_pool_waits_total", "Connections requested from the database pool while none was idle.", nil, nil)
+	poolWaitSecDesc = prometheus.NewDesc("db_pool_wait_seconds_total", "Time spent waiting for connections of the database pool.", nil, nil)
+)
+
+// Describe implements prometheus.Collector.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{poolOpenDesc, poolInUseDesc, poolIdleDesc, poolMaxDesc, poolWaitsDesc, poolWaitSecDesc} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	var open, inUse, idle, maxOpen, waits float64
+	var waited time.Duration
+	if c.pool != nil {
+		stat := c.pool.Stat()
+		open, inUse, idle, maxOpen = float64(stat.TotalConns()), float64(stat.AcquiredConns()), float64(stat.IdleConns()), float64(stat.MaxConns())
+		waits, waited = float64(stat.EmptyAcquireCount()), stat.AcquireDuration()
+	} else {
+		stats := c.db.Stats()
+		open, inUse, idle, maxOpen = float64(stats.OpenConnections), float64(stats.InUse), float64(stats.Idle), float64(stats.MaxOpenConnections)
+		waits, waited = float64(stats.WaitCount), stats.WaitDuration
+	}
+	ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, open)
+	ch <- prometheus.MustNewConstMetric(poolInUseDesc, prometheus.GaugeValue, inUse)
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, idle)
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, maxOpen)
+	ch <- prometheus.MustNewConstMetric(poolWaitsDesc, prometheus.CounterValue, waits)
+	ch <- prometheus.MustNewConstMetric(poolWaitSecDesc, prometheus.CounterValue, waited.Seconds())
+}
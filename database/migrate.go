@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	migratemysql "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationFiles holds the versioned schema migrations, one directory per dialect
+// (migrations/postgres, migrations/sqlite, migrations/mysql). Files are named
+// NNNNNN_description.up.sql / NNNNNN_description.down.sql and every dialect must
+// have the same versions. Applied versions are tracked in the schema_migrations table.
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// MigrateUp applies all pending migrations.
+func MigrateUp(db *DB) error {
+	return runMigrations(db, func(m *migrate.Migrate) error { return m.Up() })
+}
+
+// MigrateDown rolls back the given number of applied migrations.
+func MigrateDown(db *DB, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1")
+	}
+	return runMigrations(db, func(m *migrate.Migrate) error { return m.Steps(-steps) })
+}
+
+// SchemaStatus describes the schema version of a database.
+type SchemaStatus struct {
+	Version uint `json:"version"` // Applied version; 0 if no migration was applied
+	Latest  uint `json:"latest"`  // Newest version embedded in the binary
+	Dirty   bool `json:"dirty"`   // A migration failed halfway and has to be fixed by hand
+}
+
+// UpToDate reports whether all embedded migrations have been applied cleanly.
+func (s SchemaStatus) UpToDate() bool {
+	return !s.Dirty && s.Version >= s.Latest
+}
+
+// CheckSchema reads the schema version of db from the schema_migrations table and compares it
+// with the newest migration embedded for its dialect.
+func CheckSchema(ctx context.Context, db *DB) (SchemaStatus, error) {
+	var status SchemaStatus
+	latest, err := latestMigration(db.Dialect().Name())
+	if err != nil {
+		return status, err
+	}
+	status.Latest = latest
+
+	var version int64
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &status.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return status, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	status.Version = uint(version)
+	return status, nil
+}
+
+// latestMigration returns the newest version among the embedded migrations of dialect.
+func latestMigration(dialect string) (uint, error) {
+	files, err := migrationFiles.ReadDir("migrations/" + dialect)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	var latest uint64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(file.Name(), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s", file.Name())
+		}
+		latest = max(latest, version)
+	}
+	return uint(latest), nil
+}
+
+// runMigrations runs fn against a migrator for the dialect of db.
+// The migrator is closed afterwards without closing the pool itself.
+func runMigrations(db *DB, fn func(m *migrate.Migrate) error) error {
+	driver, err := migrationDriver(db)
+	if err != nil {
+		return fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	source, err := iofs.New(migrationFiles, "migrations/"+db.Dialect().Name())
+	if err != nil {
+		driver.Close()
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, db.Dialect().Name(), driver)
+	if err != nil {
+		source.Close()
+		driver.Close()
+		return fmt.Errorf("failed to create migrator: %w", err)
+	}
+	defer m.Close()
+
+	if err := fn(m); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		slog.Info("Database schema has no migrations applied")
+	case err != nil:
+		return fmt.Errorf("failed to read schema version: %w", err)
+	default:
+		slog.Info("Database schema version", "version", version, "dirty", dirty)
+	}
+	return nil
+}
+
+// migrationDriver returns the migrate driver for the dialect of db. PostgreSQL and MySQL
+// are bound to a single connection from the pool so closing the driver only releases it.
+func migrationDriver(db *DB) (migratedb.Driver, error) {
+	ctx := context.Background()
+	switch db.Dialect().Name() {
+	case DialectSQLite:
+		// The SQLite pool has a single connection, so the driver has to share the pool
+		driver, err := migratesqlite.WithInstance(db.DB, &migratesqlite.Config{})
+		if err != nil {
+			return nil, err
+		}
+		return keepOpenDriver{driver}, nil
+	case DialectMySQL:
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connection for migrations: %w", err)
+		}
+		driver, err := migratemysql.WithConnection(ctx, conn, &migratemysql.Config{})
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return driver, nil
+	default:
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connection for migrations: %w", err)
+		}
+		driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return driver, nil
+	}
+}
+
+// keepOpenDriver is a migrate driver whose Close leaves the shared pool open.
+type keepOpenDriver struct {
+	migratedb.Driver
+}
+
+func (keepOpenDriver) Close() error { return nil }
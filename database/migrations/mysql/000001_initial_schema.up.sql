@@ -0,0 +1,204 @@
+-- Baseline schema for MySQL 8.0.16 or newer (CHECK constraints are enforced from that version).
+-- UUIDs are stored as CHAR(36) and generated by the application; updated_at is maintained
+-- with ON UPDATE instead of triggers.
+
+-- Create 'roles' table
+CREATE TABLE IF NOT EXISTS roles (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	name VARCHAR(50) UNIQUE NOT NULL,
+	description TEXT,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
+
+-- Create 'users' table
+CREATE TABLE IF NOT EXISTS users (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	username VARCHAR(100) NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	role_id CHAR(36) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_users_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE RESTRICT
+);
+
+-- Create 'categories' table
+CREATE TABLE IF NOT EXISTS categories (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	name VARCHAR(100) UNIQUE NOT NULL,
+	slug VARCHAR(120) UNIQUE NOT NULL,
+	description TEXT,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
+
+-- Create 'posts' table
+-- Posts are drafts until published_at is set; a future value schedules the post
+CREATE TABLE IF NOT EXISTS posts (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	user_id CHAR(36) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	content TEXT NOT NULL,
+	category_id CHAR(36) NULL,
+	published_at DATETIME(6) NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_posts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT fk_posts_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+-- Create 'tags' table
+CREATE TABLE IF NOT EXISTS tags (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	name VARCHAR(50) NOT NULL,
+	slug VARCHAR(60) UNIQUE NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
+
+-- Create 'post_tags' join table
+CREATE TABLE IF NOT EXISTS post_tags (
+	post_id CHAR(36) NOT NULL,
+	tag_id CHAR(36) NOT NULL,
+	PRIMARY KEY (post_id, tag_id),
+	CONSTRAINT fk_post_tags_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_post_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Create 'attachments' table (file metadata; contents live in the storage backend)
+CREATE TABLE IF NOT EXISTS attachments (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	post_id CHAR(36) NOT NULL,
+	user_id CHAR(36) NOT NULL,
+	file_name VARCHAR(255) NOT NULL,
+	content_type VARCHAR(100) NOT NULL,
+	size BIGINT NOT NULL,
+	storage_key VARCHAR(512) UNIQUE NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_attachments_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_attachments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create 'comments' table
+CREATE TABLE IF NOT EXISTS comments (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	post_id CHAR(36) NOT NULL,
+	user_id CHAR(36) NOT NULL,
+	content TEXT NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_comments_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_comments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create 'reports' table (user flags on posts/comments awaiting admin review)
+-- MySQL has no partial indexes: open_key is 1 for open reports and NULL otherwise,
+-- and NULLs never collide in a unique index, so only open reports are constrained.
+CREATE TABLE IF NOT EXISTS reports (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('post', 'comment')),
+	target_id CHAR(36) NOT NULL,
+	reporter_id CHAR(36) NOT NULL,
+	reason VARCHAR(50) NOT NULL,
+	details TEXT,
+	status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+	resolved_by CHAR(36) NULL,
+	resolution_note TEXT,
+	resolved_at DATETIME(6) NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	open_key TINYINT GENERATED ALWAYS AS (CASE WHEN status = 'open' THEN 1 END) VIRTUAL,
+	UNIQUE KEY idx_reports_open_unique (target_type, target_id, reporter_id, open_key),
+	CONSTRAINT fk_reports_reporter FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT fk_reports_resolver FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Create 'products' table
+-- Archived products are hidden from listings and new orders but kept for historic orders
+CREATE TABLE IF NOT EXISTS products (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	name VARCHAR(255) NOT NULL,
+	description TEXT,
+	price DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+	stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+	category VARCHAR(100) NULL,
+	archived_at DATETIME(6) NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
+
+-- Create 'stock_movements' table (audit trail of every stock change)
+CREATE TABLE IF NOT EXISTS stock_movements (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	product_id CHAR(36) NOT NULL,
+	delta INT NOT NULL,
+	reason VARCHAR(255) NOT NULL,
+	actor_id CHAR(36) NULL,
+	stock_after INT NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+	CONSTRAINT fk_stock_movements_actor FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Create 'orders' table
+-- Unpaid orders hold their stock until reserved_until; a background job releases it afterwards
+CREATE TABLE IF NOT EXISTS orders (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	user_id CHAR(36) NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed', 'cancelled')),
+	total_amount DECIMAL(12, 2) NOT NULL CHECK (total_amount >= 0),
+	currency VARCHAR(3) NOT NULL,
+	reserved_until DATETIME(6) NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	INDEX idx_orders_reserved_until (reserved_until),
+	CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
+);
+
+-- Create 'order_items' table (prices are copied from the product when ordering)
+CREATE TABLE IF NOT EXISTS order_items (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	order_id CHAR(36) NOT NULL,
+	product_id CHAR(36) NOT NULL,
+	product_name VARCHAR(255) NOT NULL,
+	quantity INT NOT NULL CHECK (quantity > 0),
+	unit_price DECIMAL(12, 2) NOT NULL CHECK (unit_price >= 0),
+	CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+	CONSTRAINT fk_order_items_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT
+);
+
+-- Create 'payments' table (one row per checkout attempt at a payment provider)
+CREATE TABLE IF NOT EXISTS payments (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	order_id CHAR(36) NOT NULL,
+	provider VARCHAR(20) NOT NULL,
+	provider_ref VARCHAR(255) NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+	amount DECIMAL(12, 2) NOT NULL CHECK (amount >= 0),
+	currency VARCHAR(3) NOT NULL,
+	checkout_url TEXT,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	UNIQUE KEY idx_payments_provider_ref (provider, provider_ref),
+	CONSTRAINT fk_payments_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+-- Create 'sessions' table
+CREATE TABLE IF NOT EXISTS sessions (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	user_id CHAR(36) NOT NULL,
+	token VARCHAR(255) UNIQUE NOT NULL,
+	expires_at DATETIME(6) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create 'user_logs' table
+CREATE TABLE IF NOT EXISTS user_logs (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id CHAR(36) NOT NULL,
+	login_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) NOT NULL,
+	logout_at DATETIME(6) NULL,
+	CONSTRAINT user_logs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
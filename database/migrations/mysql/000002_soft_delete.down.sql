@@ -0,0 +1,4 @@
+ALTER TABLE products DROP INDEX idx_products_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE posts DROP INDEX idx_posts_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE roles DROP INDEX idx_roles_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE users DROP INDEX idx_users_deleted_at, DROP COLUMN deleted_at;
@@ -0,0 +1,5 @@
+-- Soft delete: deleted rows keep their data and stay in the trash until they are purged
+ALTER TABLE users ADD COLUMN deleted_at DATETIME(6) NULL, ADD INDEX idx_users_deleted_at (deleted_at);
+ALTER TABLE roles ADD COLUMN deleted_at DATETIME(6) NULL, ADD INDEX idx_roles_deleted_at (deleted_at);
+ALTER TABLE posts ADD COLUMN deleted_at DATETIME(6) NULL, ADD INDEX idx_posts_deleted_at (deleted_at);
+ALTER TABLE products ADD COLUMN deleted_at DATETIME(6) NULL, ADD INDEX idx_products_deleted_at (deleted_at);
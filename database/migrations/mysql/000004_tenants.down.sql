@@ -0,0 +1,7 @@
+ALTER TABLE reports DROP FOREIGN KEY fk_reports_tenant, DROP COLUMN tenant_id;
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_tenant, DROP COLUMN tenant_id;
+ALTER TABLE products DROP FOREIGN KEY fk_products_tenant, DROP COLUMN tenant_id;
+ALTER TABLE posts DROP FOREIGN KEY fk_posts_tenant, DROP COLUMN tenant_id;
+ALTER TABLE users DROP FOREIGN KEY fk_users_tenant, DROP COLUMN tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,24 @@
+-- Multi-tenancy: users, posts, products, orders and reports belong to a tenant.
+-- Roles, categories and tags are shared by all tenants; comments, attachments, order items,
+-- payments and stock movements belong to the tenant of their parent row.
+CREATE TABLE IF NOT EXISTS tenants (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(63) UNIQUE NOT NULL, -- Subdomain the tenant is served under
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
+
+-- Existing rows are assigned to the default tenant (tenancy.DefaultTenantID)
+INSERT IGNORE INTO tenants (id, name, slug) VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default');
+
+ALTER TABLE users ADD COLUMN tenant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+	ADD CONSTRAINT fk_users_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE posts ADD COLUMN tenant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+	ADD CONSTRAINT fk_posts_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE products ADD COLUMN tenant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+	ADD CONSTRAINT fk_products_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE orders ADD COLUMN tenant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+	ADD CONSTRAINT fk_orders_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE reports ADD COLUMN tenant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+	ADD CONSTRAINT fk_reports_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT;
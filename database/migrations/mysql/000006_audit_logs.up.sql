@@ -0,0 +1,18 @@
+-- Audit log of the changes made to users, roles, posts and products. Entries don't reference
+-- the users or entities they describe, so they outlive them.
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id CHAR(36) PRIMARY KEY,
+	tenant_id CHAR(36) NOT NULL, -- Tenant the change was made for
+	actor_id CHAR(36), -- User who made the change; NULL for changes made by the system
+	action VARCHAR(20) NOT NULL, -- "create", "update" or "delete"
+	entity_type VARCHAR(50) NOT NULL, -- "user", "role", "post" or "product"
+	entity_id VARCHAR(36) NOT NULL,
+	before_data JSON, -- Snapshot of the entity before the change; NULL for creations
+	after_data JSON, -- Snapshot of the entity after the change; NULL for deletions
+	ip_address VARCHAR(45),
+	request_id VARCHAR(128),
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX idx_audit_logs_tenant_created_at (tenant_id, created_at),
+	INDEX idx_audit_logs_entity (entity_type, entity_id),
+	INDEX idx_audit_logs_actor_id (actor_id)
+);
@@ -0,0 +1,33 @@
+-- Outgoing webhooks: endpoints registered by the admins of a tenant, notified of the events
+-- they subscribe to (e.g., "user.created", "order.paid").
+CREATE TABLE IF NOT EXISTS webhooks (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	tenant_id CHAR(36) NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(255) NOT NULL, -- Key of the HMAC-SHA256 signature of every delivery
+	events TEXT NOT NULL, -- Comma-separated event types the endpoint subscribes to
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	INDEX idx_webhooks_tenant_id (tenant_id),
+	CONSTRAINT fk_webhooks_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+-- Delivery queue and history: one row per event and endpoint, retried with backoff until it
+-- succeeds or runs out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	webhook_id CHAR(36) NOT NULL,
+	event VARCHAR(100) NOT NULL,
+	payload LONGTEXT NOT NULL, -- JSON body, kept as sent so it matches its signature
+	status VARCHAR(20) NOT NULL DEFAULT 'pending', -- "pending", "succeeded" or "failed"
+	attempts INT NOT NULL DEFAULT 0,
+	next_attempt_at DATETIME(6) NULL, -- When a pending delivery is due; NULL once settled
+	response_status INT NULL, -- HTTP status of the last attempt; NULL if it got no response
+	last_error TEXT,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	delivered_at DATETIME(6) NULL,
+	INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+	INDEX idx_webhook_deliveries_webhook_created_at (webhook_id, created_at),
+	CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
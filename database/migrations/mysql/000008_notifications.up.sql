@@ -0,0 +1,17 @@
+-- In-app notifications of users, e.g., a welcome message or a low stock alert. They are also
+-- sent through the other configured channels, such as email.
+CREATE TABLE IF NOT EXISTS notifications (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	tenant_id CHAR(36) NOT NULL,
+	user_id CHAR(36) NOT NULL, -- Recipient
+	type VARCHAR(50) NOT NULL, -- e.g., "welcome" or "low_stock"
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL,
+	data TEXT, -- JSON details for clients, e.g., the ID of the product running low
+	read_at DATETIME(6) NULL, -- NULL while unread
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX idx_notifications_user_created_at (user_id, created_at),
+	INDEX idx_notifications_user_read_at (user_id, read_at),
+	CONSTRAINT fk_notifications_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+	CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
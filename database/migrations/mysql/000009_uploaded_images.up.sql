@@ -0,0 +1,3 @@
+-- Storage keys of user avatars and product images, see storage.Storage; NULL without an image
+ALTER TABLE users ADD COLUMN avatar_key VARCHAR(512);
+ALTER TABLE products ADD COLUMN image_key VARCHAR(512);
@@ -0,0 +1 @@
+ALTER TABLE posts DROP INDEX idx_posts_announcement, DROP COLUMN announced_at;
@@ -0,0 +1,4 @@
+-- Authors are notified once when their scheduled posts go live; announced_at records that the
+-- post has been announced. Posts published before are not announced anymore.
+ALTER TABLE posts ADD COLUMN announced_at DATETIME(6) NULL, ADD INDEX idx_posts_announcement (announced_at, published_at);
+UPDATE posts SET announced_at = published_at WHERE published_at IS NOT NULL;
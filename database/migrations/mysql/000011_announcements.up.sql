@@ -0,0 +1,13 @@
+-- Messages admins broadcast to the users of their tenant. They are kept so clients of the
+-- event stream (GET /api/events) that reconnect receive the ones they missed.
+CREATE TABLE IF NOT EXISTS announcements (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	tenant_id CHAR(36) NOT NULL,
+	title VARCHAR(200) NOT NULL,
+	message TEXT NOT NULL,
+	created_by CHAR(36) NULL, -- Admin who broadcast it
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX idx_announcements_tenant_created_at (tenant_id, created_at),
+	CONSTRAINT fk_announcements_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+	CONSTRAINT fk_announcements_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE sessions DROP COLUMN ip_address, DROP COLUMN device;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS login_otps;
+ALTER TABLE users DROP INDEX idx_users_phone, DROP COLUMN phone;
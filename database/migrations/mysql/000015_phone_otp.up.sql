@@ -0,0 +1,14 @@
+-- Optional phone numbers of users, in E.164 format, which they can log in with by a one-time
+-- code sent by SMS. A user has at most one pending code; code_hash holds its SHA-256 hash and
+-- attempts counts the wrong guesses, see OTPService.
+ALTER TABLE users ADD COLUMN phone VARCHAR(20) NULL, ADD UNIQUE INDEX idx_users_phone (phone);
+
+CREATE TABLE IF NOT EXISTS login_otps (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	user_id CHAR(36) UNIQUE NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	expires_at DATETIME(6) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	CONSTRAINT fk_login_otps_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,23 @@
+-- Teams group users of a tenant, so resources can be shared within a team. Owners manage the
+-- team and its members; a team always keeps at least one owner.
+CREATE TABLE IF NOT EXISTS teams (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	tenant_id CHAR(36) NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	description TEXT NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	INDEX idx_teams_tenant_id (tenant_id),
+	CONSTRAINT fk_teams_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+	team_id CHAR(36) NOT NULL,
+	user_id CHAR(36) NOT NULL,
+	role VARCHAR(20) NOT NULL DEFAULT 'member', -- "owner" or "member"
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (team_id, user_id),
+	INDEX idx_team_members_user_id (user_id), -- Finds the teams of a user, see services.teamScope
+	CONSTRAINT fk_team_members_team FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+	CONSTRAINT fk_team_members_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,42 @@
+-- Organizations are the customer accounts of a tenant (e.g., the companies using a B2B
+-- deployment). Users may belong to several of them, each time with a role of their own in the
+-- organization, which is independent of the role of their account.
+CREATE TABLE IF NOT EXISTS organizations (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	tenant_id CHAR(36) NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(120) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	CONSTRAINT uq_organizations_tenant_slug UNIQUE (tenant_id, slug),
+	CONSTRAINT fk_organizations_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+	organization_id CHAR(36) NOT NULL,
+	user_id CHAR(36) NOT NULL,
+	role_id CHAR(36) NOT NULL, -- Role in the organization
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (organization_id, user_id),
+	INDEX idx_organization_members_user_id (user_id),
+	CONSTRAINT fk_organization_members_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+	CONSTRAINT fk_organization_members_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT fk_organization_members_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE RESTRICT
+);
+
+-- Pending invitations by email. token holds the SHA-256 hash of the token of the link sent
+-- to the invitee; accepting deletes the invitation.
+CREATE TABLE IF NOT EXISTS organization_invitations (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	organization_id CHAR(36) NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	role_id CHAR(36) NOT NULL,
+	token VARCHAR(64) UNIQUE NOT NULL,
+	invited_by CHAR(36) NULL,
+	expires_at DATETIME(6) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	CONSTRAINT uq_organization_invitations_email UNIQUE (organization_id, email),
+	CONSTRAINT fk_organization_invitations_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+	CONSTRAINT fk_organization_invitations_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+	CONSTRAINT fk_organization_invitations_inviter FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,10 @@
+-- Labels admins attach to users to segment them (e.g., "beta", "vip"), for staged rollouts and
+-- support workflows. Tags are lowercase free-form strings, see services.normalizeUserTags.
+CREATE TABLE IF NOT EXISTS user_tags (
+	user_id CHAR(36) NOT NULL,
+	tag VARCHAR(50) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (user_id, tag),
+	INDEX idx_user_tags_tag (tag),
+	CONSTRAINT fk_user_tags_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+-- Internal notes of the support staff on user accounts, only visible to admins. author_id is
+-- kept NULL once the author's account is deleted, so the note survives them.
+CREATE TABLE IF NOT EXISTS user_notes (
+	id CHAR(36) PRIMARY KEY DEFAULT (UUID()),
+	user_id CHAR(36) NOT NULL,
+	author_id CHAR(36) NULL,
+	content TEXT NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	updated_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+	INDEX idx_user_notes_user_id (user_id, created_at),
+	CONSTRAINT fk_user_notes_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT fk_user_notes_author FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,14 @@
+-- Failed login attempts, kept apart from the logins of user_logs for incident response. Entries
+-- don't reference users: most attempts name accounts that don't exist.
+CREATE TABLE IF NOT EXISTS failed_logins (
+	id CHAR(36) PRIMARY KEY,
+	tenant_id CHAR(36) NOT NULL, -- Tenant the login was attempted on
+	account VARCHAR(255) NOT NULL, -- Email or phone number tried
+	reason VARCHAR(30) NOT NULL, -- "unknown_account", "wrong_tenant", "invalid_password", "invalid_code" or "locked_out"
+	ip_address VARCHAR(45),
+	user_agent VARCHAR(255),
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX idx_failed_logins_tenant_created_at (tenant_id, created_at),
+	INDEX idx_failed_logins_account (account),
+	INDEX idx_failed_logins_ip_address (ip_address)
+);
@@ -0,0 +1,9 @@
+-- Permissions granted to roles, each opening a group of admin endpoints (see models.Permissions).
+-- The admin role has every permission without rows here.
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id CHAR(36) NOT NULL,
+	permission VARCHAR(50) NOT NULL,
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (role_id, permission),
+	CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs ADD INDEX idx_audit_logs_tenant_created_at (tenant_id, created_at), DROP INDEX idx_audit_logs_tenant_created_at_id;
+ALTER TABLE user_logs DROP INDEX idx_user_logs_user_id_login_at_id, DROP INDEX idx_user_logs_login_at_id;
@@ -0,0 +1,4 @@
+-- Keyset pagination of the login history and the audit log: pages start after the
+-- (time, id) of the last row of the previous one, and these indexes serve them in order.
+ALTER TABLE user_logs ADD INDEX idx_user_logs_login_at_id (login_at, id), ADD INDEX idx_user_logs_user_id_login_at_id (user_id, login_at, id);
+ALTER TABLE audit_logs ADD INDEX idx_audit_logs_tenant_created_at_id (tenant_id, created_at, id), DROP INDEX idx_audit_logs_tenant_created_at;
@@ -0,0 +1 @@
+ALTER TABLE users DROP INDEX idx_users_lower_email, DROP INDEX idx_users_lower_username;
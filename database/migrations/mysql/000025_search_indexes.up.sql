@@ -0,0 +1,5 @@
+-- Indexes of the search and filter columns. users (email) and roles (name) are already
+-- indexed by their UNIQUE constraints and users (role_id) by its foreign key; the LOWER()
+-- functional indexes serve case-insensitive lookups. MySQL has no index for LIKE '%term%'
+-- searches, which stay table scans.
+ALTER TABLE users ADD INDEX idx_users_lower_username ((LOWER(username))), ADD INDEX idx_users_lower_email ((LOWER(email)));
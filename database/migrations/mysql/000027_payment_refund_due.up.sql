@@ -0,0 +1,2 @@
+-- A payment succeeding for an order another payment already paid has to be refunded
+ALTER TABLE payments ADD COLUMN refund_due BOOLEAN NOT NULL DEFAULT FALSE;
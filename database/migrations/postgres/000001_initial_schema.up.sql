@@ -0,0 +1,320 @@
+-- Baseline schema. Every statement is idempotent so databases created before
+-- versioned migrations were introduced can adopt this version as-is.
+
+-- Function to update 'updated_at' column
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+	NEW.updated_at = NOW();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create 'roles' table
+CREATE TABLE IF NOT EXISTS roles (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	name VARCHAR(50) UNIQUE NOT NULL,
+	description TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trigger for 'roles' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_roles_updated_at') THEN
+		CREATE TRIGGER update_roles_updated_at
+		BEFORE UPDATE ON roles
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'users' table
+CREATE TABLE IF NOT EXISTS users (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	username VARCHAR(100) NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	role_id UUID NOT NULL, -- Foreign key to roles table
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_users_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE RESTRICT
+);
+
+-- Trigger for 'users' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_users_updated_at') THEN
+		CREATE TRIGGER update_users_updated_at
+		BEFORE UPDATE ON users
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'posts' table
+CREATE TABLE IF NOT EXISTS posts (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	content TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_posts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Trigger for 'posts' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_posts_updated_at') THEN
+		CREATE TRIGGER update_posts_updated_at
+		BEFORE UPDATE ON posts
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'categories' table
+CREATE TABLE IF NOT EXISTS categories (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	name VARCHAR(100) UNIQUE NOT NULL,
+	slug VARCHAR(120) UNIQUE NOT NULL,
+	description TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trigger for 'categories' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_categories_updated_at') THEN
+		CREATE TRIGGER update_categories_updated_at
+		BEFORE UPDATE ON categories
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Posts optionally belong to a single category
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS category_id UUID NULL REFERENCES categories(id) ON DELETE SET NULL;
+
+-- Create 'tags' table
+CREATE TABLE IF NOT EXISTS tags (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	name VARCHAR(50) NOT NULL,
+	slug VARCHAR(60) UNIQUE NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trigger for 'tags' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_tags_updated_at') THEN
+		CREATE TRIGGER update_tags_updated_at
+		BEFORE UPDATE ON tags
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'post_tags' join table
+CREATE TABLE IF NOT EXISTS post_tags (
+	post_id UUID NOT NULL,
+	tag_id UUID NOT NULL,
+	CONSTRAINT post_tags_pkey PRIMARY KEY (post_id, tag_id),
+	CONSTRAINT fk_post_tags_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_post_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Posts are drafts until published_at is set; a future value schedules the post
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- Create 'attachments' table (file metadata; contents live in the storage backend)
+CREATE TABLE IF NOT EXISTS attachments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	post_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	file_name VARCHAR(255) NOT NULL,
+	content_type VARCHAR(100) NOT NULL,
+	size BIGINT NOT NULL,
+	storage_key VARCHAR(512) UNIQUE NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_attachments_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_attachments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create 'comments' table
+CREATE TABLE IF NOT EXISTS comments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	post_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	content TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_comments_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_comments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Trigger for 'comments' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_comments_updated_at') THEN
+		CREATE TRIGGER update_comments_updated_at
+		BEFORE UPDATE ON comments
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'reports' table (user flags on posts/comments awaiting admin review)
+CREATE TABLE IF NOT EXISTS reports (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('post', 'comment')),
+	target_id UUID NOT NULL,
+	reporter_id UUID NOT NULL,
+	reason VARCHAR(50) NOT NULL,
+	details TEXT,
+	status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+	resolved_by UUID NULL,
+	resolution_note TEXT,
+	resolved_at TIMESTAMP WITH TIME ZONE NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_reports_reporter FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT fk_reports_resolver FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- A user can only have one open report per target
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_unique ON reports (target_type, target_id, reporter_id) WHERE status = 'open';
+
+-- Trigger for 'reports' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_reports_updated_at') THEN
+		CREATE TRIGGER update_reports_updated_at
+		BEFORE UPDATE ON reports
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'products' table
+CREATE TABLE IF NOT EXISTS products (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	name VARCHAR(255) NOT NULL,
+	description TEXT,
+	price NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+	stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trigger for 'products' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_products_updated_at') THEN
+		CREATE TRIGGER update_products_updated_at
+		BEFORE UPDATE ON products
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Products can be filtered by a free-form category
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category VARCHAR(100) NULL;
+
+-- Archived products are hidden from listings and new orders but kept for historic orders
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- Create 'stock_movements' table (audit trail of every stock change)
+CREATE TABLE IF NOT EXISTS stock_movements (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	product_id UUID NOT NULL,
+	delta INTEGER NOT NULL,
+	reason VARCHAR(255) NOT NULL,
+	actor_id UUID NULL,
+	stock_after INTEGER NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+	CONSTRAINT fk_stock_movements_actor FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Create 'orders' table
+CREATE TABLE IF NOT EXISTS orders (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed', 'cancelled')),
+	total_amount NUMERIC(12, 2) NOT NULL CHECK (total_amount >= 0),
+	currency VARCHAR(3) NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
+);
+
+-- Trigger for 'orders' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_orders_updated_at') THEN
+		CREATE TRIGGER update_orders_updated_at
+		BEFORE UPDATE ON orders
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Unpaid orders hold their stock until this time; a background job releases it afterwards
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS reserved_until TIMESTAMP WITH TIME ZONE NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_reserved_until ON orders (reserved_until) WHERE reserved_until IS NOT NULL;
+
+-- Create 'order_items' table (prices are copied from the product when ordering)
+CREATE TABLE IF NOT EXISTS order_items (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	order_id UUID NOT NULL,
+	product_id UUID NOT NULL,
+	product_name VARCHAR(255) NOT NULL,
+	quantity INTEGER NOT NULL CHECK (quantity > 0),
+	unit_price NUMERIC(12, 2) NOT NULL CHECK (unit_price >= 0),
+	CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+	CONSTRAINT fk_order_items_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT
+);
+
+-- Create 'payments' table (one row per checkout attempt at a payment provider)
+CREATE TABLE IF NOT EXISTS payments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	order_id UUID NOT NULL,
+	provider VARCHAR(20) NOT NULL,
+	provider_ref VARCHAR(255) NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+	amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+	currency VARCHAR(3) NOT NULL,
+	checkout_url TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_payments_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+-- Webhook events are matched to payments by provider reference
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref ON payments (provider, provider_ref);
+
+-- Trigger for 'payments' table
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_payments_updated_at') THEN
+		CREATE TRIGGER update_payments_updated_at
+		BEFORE UPDATE ON payments
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Create 'sessions' table
+CREATE TABLE IF NOT EXISTS sessions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL,
+	token VARCHAR(255) UNIQUE NOT NULL,
+	expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create 'user_logs' table
+CREATE TABLE IF NOT EXISTS user_logs (
+	id serial4 NOT NULL,
+	user_id uuid NOT NULL,
+	login_at timestamptz DEFAULT now() NOT NULL,
+	logout_at timestamptz NULL,
+	CONSTRAINT user_logs_pkey PRIMARY KEY (id),
+	CONSTRAINT user_logs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+ALTER TABLE reports DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE products DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,35 @@
+-- Multi-tenancy: users, posts, products, orders and reports belong to a tenant.
+-- Roles, categories and tags are shared by all tenants; comments, attachments, order items,
+-- payments and stock movements belong to the tenant of their parent row.
+CREATE TABLE IF NOT EXISTS tenants (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(63) UNIQUE NOT NULL, -- Subdomain the tenant is served under
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_tenants_updated_at') THEN
+		CREATE TRIGGER update_tenants_updated_at
+		BEFORE UPDATE ON tenants
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Existing rows are assigned to the default tenant (tenancy.DefaultTenantID)
+INSERT INTO tenants (id, name, slug) VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id) ON DELETE RESTRICT;
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_posts_tenant_id ON posts (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_products_tenant_id ON products (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_id ON orders (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_reports_tenant_id ON reports (tenant_id);
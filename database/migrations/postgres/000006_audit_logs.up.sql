@@ -0,0 +1,19 @@
+-- Audit log of the changes made to users, roles, posts and products. Entries don't reference
+-- the users or entities they describe, so they outlive them.
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id UUID PRIMARY KEY,
+	tenant_id UUID NOT NULL, -- Tenant the change was made for
+	actor_id UUID, -- User who made the change; NULL for changes made by the system
+	action VARCHAR(20) NOT NULL, -- "create", "update" or "delete"
+	entity_type VARCHAR(50) NOT NULL, -- "user", "role", "post" or "product"
+	entity_id VARCHAR(36) NOT NULL,
+	before_data JSONB, -- Snapshot of the entity before the change; NULL for creations
+	after_data JSONB, -- Snapshot of the entity after the change; NULL for deletions
+	ip_address VARCHAR(45),
+	request_id VARCHAR(128),
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created_at ON audit_logs (tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
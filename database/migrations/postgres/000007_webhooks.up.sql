@@ -0,0 +1,42 @@
+-- Outgoing webhooks: endpoints registered by the admins of a tenant, notified of the events
+-- they subscribe to (e.g., "user.created", "order.paid").
+CREATE TABLE IF NOT EXISTS webhooks (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	url TEXT NOT NULL,
+	secret VARCHAR(255) NOT NULL, -- Key of the HMAC-SHA256 signature of every delivery
+	events TEXT NOT NULL, -- Comma-separated event types the endpoint subscribes to
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks (tenant_id);
+
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_webhooks_updated_at') THEN
+		CREATE TRIGGER update_webhooks_updated_at
+		BEFORE UPDATE ON webhooks
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+-- Delivery queue and history: one row per event and endpoint, retried with backoff until it
+-- succeeds or runs out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	event VARCHAR(100) NOT NULL,
+	payload TEXT NOT NULL, -- JSON body, kept as sent so it matches its signature
+	status VARCHAR(20) NOT NULL DEFAULT 'pending', -- "pending", "succeeded" or "failed"
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP WITH TIME ZONE, -- When a pending delivery is due; NULL once settled
+	response_status INTEGER, -- HTTP status of the last attempt; NULL if it got no response
+	last_error TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_created_at ON webhook_deliveries (webhook_id, created_at);
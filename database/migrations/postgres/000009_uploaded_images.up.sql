@@ -0,0 +1,3 @@
+-- Storage keys of user avatars and product images, see storage.Storage; NULL without an image
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(512);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS image_key VARCHAR(512);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_posts_announcement;
+ALTER TABLE posts DROP COLUMN IF EXISTS announced_at;
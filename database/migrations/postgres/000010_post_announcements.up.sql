@@ -0,0 +1,5 @@
+-- Authors are notified once when their scheduled posts go live; announced_at records that the
+-- post has been announced. Posts published before are not announced anymore.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS announced_at TIMESTAMP WITH TIME ZONE NULL;
+UPDATE posts SET announced_at = published_at WHERE published_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_announcement ON posts (announced_at, published_at);
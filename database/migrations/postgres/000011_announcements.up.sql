@@ -0,0 +1,12 @@
+-- Messages admins broadcast to the users of their tenant. They are kept so clients of the
+-- event stream (GET /api/events) that reconnect receive the ones they missed.
+CREATE TABLE IF NOT EXISTS announcements (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	title VARCHAR(200) NOT NULL,
+	message TEXT NOT NULL,
+	created_by UUID REFERENCES users(id) ON DELETE SET NULL, -- Admin who broadcast it
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_tenant_created_at ON announcements (tenant_id, created_at);
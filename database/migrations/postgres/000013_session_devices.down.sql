@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sessions_user;
+ALTER TABLE sessions DROP COLUMN IF EXISTS ip_address, DROP COLUMN IF EXISTS device;
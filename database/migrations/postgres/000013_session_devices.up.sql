@@ -0,0 +1,6 @@
+-- A session is created on every login and checked on every authenticated request, so deleting
+-- it logs the token out. token holds the SHA-256 hash of the JWT; device (the User-Agent) and
+-- ip_address describe the client that logged in.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS device VARCHAR(255) NULL;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NULL;
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id);
@@ -0,0 +1,15 @@
+-- Optional phone numbers of users, in E.164 format, which they can log in with by a one-time
+-- code sent by SMS. A user has at most one pending code; code_hash holds its SHA-256 hash and
+-- attempts counts the wrong guesses, see OTPService.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(20) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
+
+CREATE TABLE IF NOT EXISTS login_otps (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID UNIQUE NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_login_otps_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,32 @@
+-- Teams group users of a tenant, so resources can be shared within a team. Owners manage the
+-- team and its members; a team always keeps at least one owner.
+CREATE TABLE IF NOT EXISTS teams (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_tenant_id ON teams (tenant_id);
+
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_teams_updated_at') THEN
+		CREATE TRIGGER update_teams_updated_at
+		BEFORE UPDATE ON teams
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS team_members (
+	team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role VARCHAR(20) NOT NULL DEFAULT 'member', -- "owner" or "member"
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (team_id, user_id)
+);
+
+-- Finds the teams of a user, see services.teamScope
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id);
@@ -0,0 +1,45 @@
+-- Organizations are the customer accounts of a tenant (e.g., the companies using a B2B
+-- deployment). Users may belong to several of them, each time with a role of their own in the
+-- organization, which is independent of the role of their account.
+CREATE TABLE IF NOT EXISTS organizations (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(120) NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT uq_organizations_tenant_slug UNIQUE (tenant_id, slug)
+);
+
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_organizations_updated_at') THEN
+		CREATE TRIGGER update_organizations_updated_at
+		BEFORE UPDATE ON organizations
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS organization_members (
+	organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE RESTRICT, -- Role in the organization
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members (user_id);
+
+-- Pending invitations by email. token holds the SHA-256 hash of the token of the link sent
+-- to the invitee; accepting deletes the invitation.
+CREATE TABLE IF NOT EXISTS organization_invitations (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+	email VARCHAR(255) NOT NULL,
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	token VARCHAR(64) UNIQUE NOT NULL,
+	invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+	expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT uq_organization_invitations_email UNIQUE (organization_id, email)
+);
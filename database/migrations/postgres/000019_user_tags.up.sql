@@ -0,0 +1,10 @@
+-- Labels admins attach to users to segment them (e.g., "beta", "vip"), for staged rollouts and
+-- support workflows. Tags are lowercase free-form strings, see services.normalizeUserTags.
+CREATE TABLE IF NOT EXISTS user_tags (
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	tag VARCHAR(50) NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tags_tag ON user_tags (tag);
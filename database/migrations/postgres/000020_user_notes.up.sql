@@ -0,0 +1,21 @@
+-- Internal notes of the support staff on user accounts, only visible to admins. author_id is
+-- kept NULL once the author's account is deleted, so the note survives them.
+CREATE TABLE IF NOT EXISTS user_notes (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	content TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_notes_user_id ON user_notes (user_id, created_at);
+
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'update_user_notes_updated_at') THEN
+		CREATE TRIGGER update_user_notes_updated_at
+		BEFORE UPDATE ON user_notes
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	END IF;
+END $$;
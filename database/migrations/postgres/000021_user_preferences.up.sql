@@ -0,0 +1,3 @@
+-- Preferences of users (e.g., language or theme), a JSON object whose keys are chosen by the
+-- frontend; NULL until the user saves some, see GET /api/profile.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferences JSONB;
@@ -0,0 +1,11 @@
+-- pg_trgm is left installed, as other schemas of the database may use it
+DROP INDEX IF EXISTS idx_products_description_trgm;
+DROP INDEX IF EXISTS idx_products_name_trgm;
+DROP INDEX IF EXISTS idx_posts_content_trgm;
+DROP INDEX IF EXISTS idx_posts_title_trgm;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_username_trgm;
+
+DROP INDEX IF EXISTS idx_users_lower_email;
+DROP INDEX IF EXISTS idx_users_lower_username;
+DROP INDEX IF EXISTS idx_users_role_id;
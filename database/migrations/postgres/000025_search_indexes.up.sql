@@ -0,0 +1,16 @@
+-- Indexes of the search and filter columns. users (email) and roles (name) are already
+-- indexed by their UNIQUE constraints; users (role_id) serves the role filter and the
+-- role-name search of the user list, and the LOWER() indexes case-insensitive lookups.
+CREATE INDEX IF NOT EXISTS idx_users_role_id ON users (role_id);
+CREATE INDEX IF NOT EXISTS idx_users_lower_username ON users (LOWER(username));
+CREATE INDEX IF NOT EXISTS idx_users_lower_email ON users (LOWER(email));
+
+-- Trigram indexes serve the ILIKE '%term%' searches of the list endpoints, which no B-tree
+-- index can. Roles, categories and tags are small enough to be scanned.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_posts_title_trgm ON posts USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_posts_content_trgm ON posts USING GIN (content gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_description_trgm ON products USING GIN (description gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- Failed deliveries used to keep up to 1 KB of the endpoint's response in last_error, which
+-- let admins read internal services through their webhooks; only the status is kept now.
+UPDATE webhook_deliveries SET last_error = 'webhook endpoint returned ' || response_status WHERE last_error LIKE 'webhook endpoint returned %' AND response_status IS NOT NULL;
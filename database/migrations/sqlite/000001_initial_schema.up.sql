@@ -0,0 +1,246 @@
+-- Baseline schema for SQLite (local development and testing).
+-- UUIDs are stored as TEXT and generated by the application; timestamps are DATETIME.
+
+-- Create 'roles' table
+CREATE TABLE IF NOT EXISTS roles (
+	id TEXT PRIMARY KEY,
+	name VARCHAR(50) UNIQUE NOT NULL,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_roles_updated_at
+AFTER UPDATE ON roles FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE roles SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'users' table
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY,
+	username VARCHAR(100) NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE RESTRICT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_users_updated_at
+AFTER UPDATE ON users FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'categories' table
+CREATE TABLE IF NOT EXISTS categories (
+	id TEXT PRIMARY KEY,
+	name VARCHAR(100) UNIQUE NOT NULL,
+	slug VARCHAR(120) UNIQUE NOT NULL,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_categories_updated_at
+AFTER UPDATE ON categories FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE categories SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'posts' table
+-- Posts are drafts until published_at is set; a future value schedules the post
+CREATE TABLE IF NOT EXISTS posts (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	title VARCHAR(255) NOT NULL,
+	content TEXT NOT NULL,
+	category_id TEXT NULL REFERENCES categories(id) ON DELETE SET NULL,
+	published_at DATETIME NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_posts_updated_at
+AFTER UPDATE ON posts FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE posts SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'tags' table
+CREATE TABLE IF NOT EXISTS tags (
+	id TEXT PRIMARY KEY,
+	name VARCHAR(50) NOT NULL,
+	slug VARCHAR(60) UNIQUE NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_tags_updated_at
+AFTER UPDATE ON tags FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE tags SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'post_tags' join table
+CREATE TABLE IF NOT EXISTS post_tags (
+	post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (post_id, tag_id)
+);
+
+-- Create 'attachments' table (file metadata; contents live in the storage backend)
+CREATE TABLE IF NOT EXISTS attachments (
+	id TEXT PRIMARY KEY,
+	post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	file_name VARCHAR(255) NOT NULL,
+	content_type VARCHAR(100) NOT NULL,
+	size BIGINT NOT NULL,
+	storage_key VARCHAR(512) UNIQUE NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create 'comments' table
+CREATE TABLE IF NOT EXISTS comments (
+	id TEXT PRIMARY KEY,
+	post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_comments_updated_at
+AFTER UPDATE ON comments FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE comments SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'reports' table (user flags on posts/comments awaiting admin review)
+CREATE TABLE IF NOT EXISTS reports (
+	id TEXT PRIMARY KEY,
+	target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('post', 'comment')),
+	target_id TEXT NOT NULL,
+	reporter_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	reason VARCHAR(50) NOT NULL,
+	details TEXT,
+	status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+	resolved_by TEXT NULL REFERENCES users(id) ON DELETE SET NULL,
+	resolution_note TEXT,
+	resolved_at DATETIME NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user can only have one open report per target
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_unique ON reports (target_type, target_id, reporter_id) WHERE status = 'open';
+
+CREATE TRIGGER IF NOT EXISTS update_reports_updated_at
+AFTER UPDATE ON reports FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE reports SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'products' table
+-- Archived products are hidden from listings and new orders but kept for historic orders
+CREATE TABLE IF NOT EXISTS products (
+	id TEXT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	description TEXT,
+	price NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+	stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+	category VARCHAR(100) NULL,
+	archived_at DATETIME NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_products_updated_at
+AFTER UPDATE ON products FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'stock_movements' table (audit trail of every stock change)
+CREATE TABLE IF NOT EXISTS stock_movements (
+	id TEXT PRIMARY KEY,
+	product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	delta INTEGER NOT NULL,
+	reason VARCHAR(255) NOT NULL,
+	actor_id TEXT NULL REFERENCES users(id) ON DELETE SET NULL,
+	stock_after INTEGER NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create 'orders' table
+-- Unpaid orders hold their stock until reserved_until; a background job releases it afterwards
+CREATE TABLE IF NOT EXISTS orders (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed', 'cancelled')),
+	total_amount NUMERIC(12, 2) NOT NULL CHECK (total_amount >= 0),
+	currency VARCHAR(3) NOT NULL,
+	reserved_until DATETIME NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_reserved_until ON orders (reserved_until) WHERE reserved_until IS NOT NULL;
+
+CREATE TRIGGER IF NOT EXISTS update_orders_updated_at
+AFTER UPDATE ON orders FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE orders SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'order_items' table (prices are copied from the product when ordering)
+CREATE TABLE IF NOT EXISTS order_items (
+	id TEXT PRIMARY KEY,
+	order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+	product_id TEXT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+	product_name VARCHAR(255) NOT NULL,
+	quantity INTEGER NOT NULL CHECK (quantity > 0),
+	unit_price NUMERIC(12, 2) NOT NULL CHECK (unit_price >= 0)
+);
+
+-- Create 'payments' table (one row per checkout attempt at a payment provider)
+CREATE TABLE IF NOT EXISTS payments (
+	id TEXT PRIMARY KEY,
+	order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+	provider VARCHAR(20) NOT NULL,
+	provider_ref VARCHAR(255) NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+	amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+	currency VARCHAR(3) NOT NULL,
+	checkout_url TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Webhook events are matched to payments by provider reference
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref ON payments (provider, provider_ref);
+
+CREATE TRIGGER IF NOT EXISTS update_payments_updated_at
+AFTER UPDATE ON payments FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE payments SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Create 'sessions' table
+CREATE TABLE IF NOT EXISTS sessions (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	token VARCHAR(255) UNIQUE NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create 'user_logs' table
+CREATE TABLE IF NOT EXISTS user_logs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	login_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	logout_at DATETIME NULL
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_products_deleted_at;
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+DROP INDEX IF EXISTS idx_roles_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE products DROP COLUMN deleted_at;
+ALTER TABLE posts DROP COLUMN deleted_at;
+ALTER TABLE roles DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,10 @@
+-- Soft delete: deleted rows keep their data and stay in the trash until they are purged
+ALTER TABLE users ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE roles ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE posts ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE products ADD COLUMN deleted_at DATETIME NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at) WHERE deleted_at IS NOT NULL;
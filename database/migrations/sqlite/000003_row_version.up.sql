@@ -0,0 +1,5 @@
+-- Optimistic locking: every update through the API bumps the version and fails when the
+-- client's version is stale
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE roles ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_reports_tenant_id;
+DROP INDEX IF EXISTS idx_orders_tenant_id;
+DROP INDEX IF EXISTS idx_products_tenant_id;
+DROP INDEX IF EXISTS idx_posts_tenant_id;
+DROP INDEX IF EXISTS idx_users_tenant_id;
+
+ALTER TABLE reports DROP COLUMN tenant_id;
+ALTER TABLE orders DROP COLUMN tenant_id;
+ALTER TABLE products DROP COLUMN tenant_id;
+ALTER TABLE posts DROP COLUMN tenant_id;
+ALTER TABLE users DROP COLUMN tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,34 @@
+-- Multi-tenancy: users, posts, products, orders and reports belong to a tenant.
+-- Roles, categories and tags are shared by all tenants; comments, attachments, order items,
+-- payments and stock movements belong to the tenant of their parent row.
+CREATE TABLE IF NOT EXISTS tenants (
+	id TEXT PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(63) UNIQUE NOT NULL, -- Subdomain the tenant is served under
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_tenants_updated_at
+AFTER UPDATE ON tenants FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE tenants SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Existing rows are assigned to the default tenant (tenancy.DefaultTenantID)
+INSERT INTO tenants (id, name, slug) VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default')
+ON CONFLICT DO NOTHING;
+
+-- SQLite can't add a column with both a REFERENCES clause and a non-NULL default,
+-- so the tenant references aren't enforced by foreign keys here
+ALTER TABLE users ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE posts ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE products ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE orders ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE reports ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_posts_tenant_id ON posts (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_products_tenant_id ON products (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_id ON orders (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_reports_tenant_id ON reports (tenant_id);
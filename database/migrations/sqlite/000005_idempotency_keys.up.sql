@@ -0,0 +1,14 @@
+-- Responses to requests sent with an Idempotency-Key header, replayed when the request is retried.
+-- Keys are scoped to the user that sent them and kept for IDEMPOTENCY_KEY_TTL_HOURS.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	idempotency_key VARCHAR(255) NOT NULL,
+	request_hash CHAR(64) NOT NULL, -- SHA-256 of the method, path and body the key was first used with
+	status_code INTEGER, -- NULL while the first request is being processed
+	content_type VARCHAR(255),
+	response_body BLOB,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
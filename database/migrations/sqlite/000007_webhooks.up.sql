@@ -0,0 +1,39 @@
+-- Outgoing webhooks: endpoints registered by the admins of a tenant, notified of the events
+-- they subscribe to (e.g., "user.created", "order.paid").
+CREATE TABLE IF NOT EXISTS webhooks (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	url TEXT NOT NULL,
+	secret VARCHAR(255) NOT NULL, -- Key of the HMAC-SHA256 signature of every delivery
+	events TEXT NOT NULL, -- Comma-separated event types the endpoint subscribes to
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks (tenant_id);
+
+CREATE TRIGGER IF NOT EXISTS update_webhooks_updated_at
+AFTER UPDATE ON webhooks FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE webhooks SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Delivery queue and history: one row per event and endpoint, retried with backoff until it
+-- succeeds or runs out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id TEXT PRIMARY KEY,
+	webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	event VARCHAR(100) NOT NULL,
+	payload TEXT NOT NULL, -- JSON body, kept as sent so it matches its signature
+	status VARCHAR(20) NOT NULL DEFAULT 'pending', -- "pending", "succeeded" or "failed"
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at DATETIME, -- When a pending delivery is due; NULL once settled
+	response_status INTEGER, -- HTTP status of the last attempt; NULL if it got no response
+	last_error TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	delivered_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_created_at ON webhook_deliveries (webhook_id, created_at);
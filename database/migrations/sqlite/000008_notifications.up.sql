@@ -0,0 +1,16 @@
+-- In-app notifications of users, e.g., a welcome message or a low stock alert. They are also
+-- sent through the other configured channels, such as email.
+CREATE TABLE IF NOT EXISTS notifications (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Recipient
+	type VARCHAR(50) NOT NULL, -- e.g., "welcome" or "low_stock"
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL,
+	data TEXT, -- JSON details for clients, e.g., the ID of the product running low
+	read_at DATETIME, -- NULL while unread
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created_at ON notifications (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_read_at ON notifications (user_id, read_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_announcement;
+
+ALTER TABLE posts DROP COLUMN announced_at;
@@ -0,0 +1,7 @@
+-- Settings changed through the API while the server runs, shared by all instances (e.g.,
+-- "maintenance_mode"). Values are JSON documents.
+CREATE TABLE IF NOT EXISTS settings (
+	name VARCHAR(100) PRIMARY KEY,
+	value TEXT NOT NULL,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
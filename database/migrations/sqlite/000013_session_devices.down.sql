@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sessions_user;
+
+ALTER TABLE sessions DROP COLUMN ip_address;
+ALTER TABLE sessions DROP COLUMN device;
@@ -0,0 +1,3 @@
+-- Incremented whenever the role or password of a user changes. Tokens carry the version they
+-- were issued with and are rejected once it is outdated, see middleware.JWT.
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS login_otps;
+DROP INDEX IF EXISTS idx_users_phone;
+
+ALTER TABLE users DROP COLUMN phone;
@@ -0,0 +1,14 @@
+-- Optional phone numbers of users, in E.164 format, which they can log in with by a one-time
+-- code sent by SMS. A user has at most one pending code; code_hash holds its SHA-256 hash and
+-- attempts counts the wrong guesses, see OTPService.
+ALTER TABLE users ADD COLUMN phone VARCHAR(20) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
+
+CREATE TABLE IF NOT EXISTS login_otps (
+	id TEXT PRIMARY KEY,
+	user_id TEXT UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	code_hash VARCHAR(64) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,14 @@
+-- Devices (SHA-256 fingerprints of the User-Agent) and countries users logged in from. A
+-- login from a device or country not seen before is alerted to the user, with a link
+-- carrying revoke_token (its SHA-256 hash here) which logs the user out everywhere.
+CREATE TABLE IF NOT EXISTS known_devices (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	fingerprint VARCHAR(64) NOT NULL,
+	device VARCHAR(255) NOT NULL, -- User-Agent, for the alert and the user's review
+	country VARCHAR(2) NOT NULL DEFAULT '', -- ISO code, empty if unknown
+	revoke_token VARCHAR(64) UNIQUE NOT NULL,
+	first_seen_at DATETIME NOT NULL,
+	last_seen_at DATETIME NOT NULL,
+	UNIQUE (user_id, fingerprint, country)
+);
@@ -0,0 +1,29 @@
+-- Teams group users of a tenant, so resources can be shared within a team. Owners manage the
+-- team and its members; a team always keeps at least one owner.
+CREATE TABLE IF NOT EXISTS teams (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_tenant_id ON teams (tenant_id);
+
+CREATE TRIGGER IF NOT EXISTS update_teams_updated_at
+AFTER UPDATE ON teams FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE teams SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TABLE IF NOT EXISTS team_members (
+	team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role VARCHAR(20) NOT NULL DEFAULT 'member', -- "owner" or "member"
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (team_id, user_id)
+);
+
+-- Finds the teams of a user, see services.teamScope
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id);
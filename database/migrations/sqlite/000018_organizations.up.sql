@@ -0,0 +1,42 @@
+-- Organizations are the customer accounts of a tenant (e.g., the companies using a B2B
+-- deployment). Users may belong to several of them, each time with a role of their own in the
+-- organization, which is independent of the role of their account.
+CREATE TABLE IF NOT EXISTS organizations (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(120) NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (tenant_id, slug)
+);
+
+CREATE TRIGGER IF NOT EXISTS update_organizations_updated_at
+AFTER UPDATE ON organizations FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE organizations SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TABLE IF NOT EXISTS organization_members (
+	organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE RESTRICT, -- Role in the organization
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members (user_id);
+
+-- Pending invitations by email. token holds the SHA-256 hash of the token of the link sent
+-- to the invitee; accepting deletes the invitation.
+CREATE TABLE IF NOT EXISTS organization_invitations (
+	id TEXT PRIMARY KEY,
+	organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+	email VARCHAR(255) NOT NULL,
+	role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	token VARCHAR(64) UNIQUE NOT NULL,
+	invited_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (organization_id, email)
+);
@@ -0,0 +1,18 @@
+-- Internal notes of the support staff on user accounts, only visible to admins. author_id is
+-- kept NULL once the author's account is deleted, so the note survives them.
+CREATE TABLE IF NOT EXISTS user_notes (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	author_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+	content TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_notes_user_id ON user_notes (user_id, created_at);
+
+CREATE TRIGGER IF NOT EXISTS update_user_notes_updated_at
+AFTER UPDATE ON user_notes FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+	UPDATE user_notes SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,15 @@
+-- Failed login attempts, kept apart from the logins of user_logs for incident response. Entries
+-- don't reference users: most attempts name accounts that don't exist.
+CREATE TABLE IF NOT EXISTS failed_logins (
+	id TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL, -- Tenant the login was attempted on
+	account VARCHAR(255) NOT NULL, -- Email or phone number tried
+	reason VARCHAR(30) NOT NULL, -- "unknown_account", "wrong_tenant", "invalid_password", "invalid_code" or "locked_out"
+	ip_address VARCHAR(45),
+	user_agent VARCHAR(255),
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_failed_logins_tenant_created_at ON failed_logins (tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_failed_logins_account ON failed_logins (account);
+CREATE INDEX IF NOT EXISTS idx_failed_logins_ip_address ON failed_logins (ip_address);
@@ -0,0 +1,8 @@
+-- Permissions granted to roles, each opening a group of admin endpoints (see models.Permissions).
+-- The admin role has every permission without rows here.
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	permission VARCHAR(50) NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (role_id, permission)
+);
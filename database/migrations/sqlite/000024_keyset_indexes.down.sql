@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created_at ON audit_logs (tenant_id, created_at);
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created_at_id;
+
+DROP INDEX IF EXISTS idx_user_logs_user_id_login_at_id;
+DROP INDEX IF EXISTS idx_user_logs_login_at_id;
@@ -0,0 +1,7 @@
+-- Keyset pagination of the login history and the audit log: pages start after the
+-- (time, id) of the last row of the previous one, and these indexes serve them in order.
+CREATE INDEX IF NOT EXISTS idx_user_logs_login_at_id ON user_logs (login_at, id);
+CREATE INDEX IF NOT EXISTS idx_user_logs_user_id_login_at_id ON user_logs (user_id, login_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created_at_id ON audit_logs (tenant_id, created_at, id);
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created_at;
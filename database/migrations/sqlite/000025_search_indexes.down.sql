@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_lower_email;
+DROP INDEX IF EXISTS idx_users_lower_username;
+DROP INDEX IF EXISTS idx_users_role_id;
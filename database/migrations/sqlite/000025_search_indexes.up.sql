@@ -0,0 +1,7 @@
+-- Indexes of the search and filter columns. users (email) and roles (name) are already
+-- indexed by their UNIQUE constraints; users (role_id) serves the role filter and the
+-- role-name search of the user list, and the LOWER() indexes case-insensitive lookups.
+-- SQLite has no index for LIKE '%term%' searches, which stay table scans.
+CREATE INDEX IF NOT EXISTS idx_users_role_id ON users (role_id);
+CREATE INDEX IF NOT EXISTS idx_users_lower_username ON users (LOWER(username));
+CREATE INDEX IF NOT EXISTS idx_users_lower_email ON users (LOWER(email));
@@ -0,0 +1,2 @@
+-- The redacted responses can't be restored
+SELECT 1;
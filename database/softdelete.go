@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SoftDeleteTables lists the tables that follow the deleted_at convention.
+// Only these table names are ever interpolated into SQL by the soft delete helpers.
+var SoftDeleteTables = map[string]bool{
+	"users":    true,
+	"roles":    true,
+	"posts":    true,
+	"products": true,
+}
+
+// NotDeleted returns the condition that filters out soft deleted rows, e.g. "p.deleted_at IS NULL"
+// for alias "p". Pass an empty alias when the query doesn't alias the table.
+func NotDeleted(alias string) string {
+	if alias == "" {
+		return "deleted_at IS NULL"
+	}
+	return alias + ".deleted_at IS NULL"
+}
+
+// SoftDelete moves the row with the given ID of table to the trash by setting its deleted_at.
+// It reports false when no live row with that ID exists. Like the other helpers of this file,
+// it only touches rows of the request's tenant when table is tenant owned.
+func SoftDelete(ctx context.Context, db *DB, table, id string) (bool, error) {
+	if err := checkSoftDeleteTable(table); err != nil {
+		return false, err
+	}
+	query, args := scopeToTenant(ctx, table, "UPDATE "+table+" SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL", []interface{}{time.Now(), id})
+	return execAffected(ctx, db, query, args...)
+}
+
+// Restore takes the row with the given ID of table out of the trash.
+// It reports false when no trashed row with that ID exists.
+func Restore(ctx context.Context, db *DB, table, id string) (bool, error) {
+	if err := checkSoftDeleteTable(table); err != nil {
+		return false, err
+	}
+	query, args := scopeToTenant(ctx, table, "UPDATE "+table+" SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", []interface{}{id})
+	return execAffected(ctx, db, query, args...)
+}
+
+// Purge permanently deletes the trashed row with the given ID of table.
+// Live rows are never purged; they have to be soft deleted first. It reports false when no
+// trashed row with that ID exists.
+func Purge(ctx context.Context, db *DB, table, id string) (bool, error) {
+	if err := checkSoftDeleteTable(table); err != nil {
+		return false, err
+	}
+	query, args := scopeToTenant(ctx, table, "DELETE FROM "+table+" WHERE id = $1 AND deleted_at IS NOT NULL", []interface{}{id})
+	return execAffected(ctx, db, query, args...)
+}
+
+// Exists reports whether a live row with the given ID exists in table.
+func Exists(ctx context.Context, db *DB, table, id string) (bool, error) {
+	if err := checkSoftDeleteTable(table); err != nil {
+		return false, err
+	}
+	query, args := scopeToTenant(ctx, table, "SELECT 1 FROM "+table+" WHERE id = $1 AND deleted_at IS NULL", []interface{}{id})
+	var exists bool
+	err := db.QueryRowContext(ctx, "SELECT EXISTS ("+query+")", args...).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Trashed reports whether a trashed row of table has the given value in column. Trashed rows
+// keep their values reserved by the UNIQUE constraints, which span tenants, so unlike the
+// helpers above it looks at the rows of every tenant. column is interpolated into SQL and must
+// never come from input.
+func Trashed(ctx context.Context, db *DB, table, column string, value interface{}) (bool, error) {
+	if err := checkSoftDeleteTable(table); err != nil {
+		return false, err
+	}
+	var trashed bool
+	query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE " + column + " = $1 AND deleted_at IS NOT NULL)"
+	if err := db.QueryRowContext(ctx, query, value).Scan(&trashed); err != nil {
+		return false, err
+	}
+	return trashed, nil
+}
+
+func checkSoftDeleteTable(table string) error {
+	if !SoftDeleteTables[table] {
+		return fmt.Errorf("table %s does not support soft delete", table)
+	}
+	return nil
+}
+
+func execAffected(ctx context.Context, db *DB, query string, args ...interface{}) (bool, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
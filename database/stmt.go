@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// Stmt is a query prepared once and run many times, for hot paths such as login, where parsing
+// and planning the query on every request adds up under load. Like DB, it is written with $N
+// placeholders. database/sql prepares it again on each connection of the pool it runs on.
+// If preparing failed, the query is run unprepared instead.
+type Stmt struct {
+	stmt    *sql.Stmt // nil if preparing failed
+	query   string    // As written, with $N placeholders
+	rebound string    // As prepared, for the dialect
+	dialect Dialect
+	conn    unprepared // Runs the query when stmt is nil
+	breaker *Breaker   // nil for statements of a transaction
+}
+
+// unprepared runs queries that aren't prepared: a *DB or a *Tx.
+type unprepared interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Prepared prepares query on the DB. Services prepare the queries of their hot paths when
+// they are created, so a failure (e.g., of a database that is briefly unavailable) isn't
+// fatal: it is logged and the returned Stmt runs the query unprepared.
+func (db *DB) Prepared(ctx context.Context, query string) *Stmt {
+	rebound, _ := db.dialect.Rebind(query, nil)
+	s := &Stmt{query: query, rebound: rebound, dialect: db.dialect, conn: db, breaker: db.breaker}
+	stmt, err := db.DB.PrepareContext(ctx, rebound)
+	db.breaker.Record(err)
+	if err != nil {
+		slog.Warn("Failed to prepare statement, running it unprepared", "statement", statementLabel(rebound), "error", err)
+		return s
+	}
+	s.stmt = stmt
+	return s
+}
+
+// PreparedInsert prepares an INSERT into a table with an auto-incrementing id column, for
+// Stmt.InsertReturningID.
+func (db *DB) PreparedInsert(ctx context.Context, query string) *Stmt {
+	if db.dialect.SupportsReturning() {
+		query += " RETURNING id"
+	}
+	return db.Prepared(ctx, query)
+}
+
+// Stmt returns s bound to the transaction. It is closed when the transaction ends.
+func (tx *Tx) Stmt(ctx context.Context, s *Stmt) *Stmt {
+	bound := *s
+	bound.conn, bound.breaker = tx, nil
+	if s.stmt != nil {
+		bound.stmt = tx.Tx.StmtContext(ctx, s.stmt)
+	}
+	return &bound
+}
+
+// ExecContext executes the statement, which doesn't return rows, with args.
+func (s *Stmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	if s.stmt == nil {
+		return s.conn.ExecContext(ctx, s.query, args...)
+	}
+	_, args = s.dialect.Rebind(s.query, args)
+	start := time.Now()
+	result, err := s.stmt.ExecContext(ctx, args...)
+	observe(ctx, s.rebound, start, err)
+	s.breaker.Record(err)
+	return result, err
+}
+
+// QueryRowContext executes the statement, which is expected to return at most one row, with args.
+func (s *Stmt) QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row {
+	if s.stmt == nil {
+		return s.conn.QueryRowContext(ctx, s.query, args...)
+	}
+	_, args = s.dialect.Rebind(s.query, args)
+	start := time.Now()
+	row := s.stmt.QueryRowContext(ctx, args...)
+	observe(ctx, s.rebound, start, row.Err())
+	s.breaker.Record(row.Err())
+	return row
+}
+
+// InsertReturningID executes a statement prepared by PreparedInsert with args and returns the
+// generated id.
+func (s *Stmt) InsertReturningID(ctx context.Context, args ...interface{}) (int64, error) {
+	if s.dialect.SupportsReturning() {
+		var id int64
+		err := s.QueryRowContext(ctx, args...).Scan(&id)
+		return id, err
+	}
+	result, err := s.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// TenantTables lists the tables whose rows belong to a tenant.
+// Queries on them must be scoped to tenancy.ID(ctx) of the request.
+var TenantTables = map[string]bool{
+	"users":         true,
+	"posts":         true,
+	"products":      true,
+	"orders":        true,
+	"reports":       true,
+	"teams":         true,
+	"organizations": true,
+}
+
+// scopeToTenant restricts a query on table to the tenant of ctx by appending a tenant_id
+// condition with the next free placeholder. Queries on shared tables are returned unchanged.
+func scopeToTenant(ctx context.Context, table, query string, args []interface{}) (string, []interface{}) {
+	if !TenantTables[table] {
+		return query, args
+	}
+	args = append(args, tenancy.ID(ctx))
+	return query + " AND tenant_id = $" + strconv.Itoa(len(args)), args
+}
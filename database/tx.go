@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WithTx runs fn inside a transaction. The transaction is committed when fn returns nil and
+// rolled back when it returns an error or panics, so multi-statement writes are all-or-nothing.
+// Errors from fn are returned unchanged.
+func WithTx(ctx context.Context, db *DB, fn func(tx *Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // Re-throw after rolling back
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Tx is a transaction started from a DB. Like DB, it rebinds $N placeholders for the dialect.
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+// ExecContext executes a statement that doesn't return rows within the transaction.
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = tx.dialect.Rebind(query, args)
+	start := time.Now()
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	observe(ctx, query, start, err)
+	return result, err
+}
+
+// QueryContext executes a query that returns rows within the transaction.
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = tx.dialect.Rebind(query, args)
+	start := time.Now()
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	observe(ctx, query, start, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row within the transaction.
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = tx.dialect.Rebind(query, args)
+	start := time.Now()
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	observe(ctx, query, start, row.Err())
+	return row
+}
+
+// InsertReturningID runs an INSERT into a table with an auto-incrementing id column within
+// the transaction and returns the generated id.
+func (tx *Tx) InsertReturningID(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if tx.dialect.SupportsReturning() {
+		var id int64
+		err := tx.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Dialect returns the dialect the transaction's queries are rebound for.
+func (tx *Tx) Dialect() Dialect {
+	return tx.dialect
+}
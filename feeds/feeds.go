@@ -0,0 +1,193 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// Site describes the website the syndication feeds are published for.
+type Site struct {
+	Title       string // Feed title (e.g., "Bayu's Blog")
+	URL         string // Public URL of the website; post links are built as URL/posts/<id>
+	Description string // Short description used by RSS readers
+	SelfURL     string // Absolute URL the feed itself is served from
+}
+
+// postURL builds the public link of a post on the website.
+func (s Site) postURL(id string) string {
+	return strings.TrimSuffix(s.URL, "/") + "/posts/" + id
+}
+
+// --- RSS 2.0 (https://www.rssboard.org/rss-specification) ---
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// BuildRSS renders the given feed items as an RSS 2.0 document.
+func BuildRSS(site Site, items []models.FeedItem) ([]byte, error) {
+	channel := rssChannel{
+		Title:         site.Title,
+		Link:          site.URL,
+		Description:   site.Description,
+		LastBuildDate: lastUpdated(items).Format(time.RFC1123Z),
+		AtomLink:      rssLink{Href: site.SelfURL, Rel: "self", Type: "application/rss+xml"},
+	}
+
+	for _, item := range items {
+		link := site.postURL(item.ID)
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     item.PublishedAt.Format(time.RFC1123Z),
+			Categories:  itemCategories(item),
+			Description: itemContent(item),
+		})
+	}
+
+	return marshal(rssFeed{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: channel})
+}
+
+// --- Atom (RFC 4287) ---
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// BuildAtom renders the given feed items as an Atom 1.0 document.
+func BuildAtom(site Site, items []models.FeedItem) ([]byte, error) {
+	feed := atomFeed{
+		Title:   site.Title,
+		ID:      site.URL,
+		Updated: lastUpdated(items).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: site.URL},
+			{Href: site.SelfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	for _, item := range items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        "urn:uuid:" + item.ID,
+			Link:      atomLink{Href: site.postURL(item.ID)},
+			Published: item.PublishedAt.Format(time.RFC3339),
+			Updated:   item.PublishedAt.Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.AuthorUsername},
+			Content:   atomContent{Type: "text", Value: item.Content},
+		}
+		if item.ContentHTML != "" {
+			entry.Content = atomContent{Type: "html", Value: item.ContentHTML}
+		}
+		for _, term := range itemCategories(item) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: term})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshal(feed)
+}
+
+// itemContent prefers the rendered HTML of an item and falls back to its Markdown source.
+func itemContent(item models.FeedItem) string {
+	if item.ContentHTML != "" {
+		return item.ContentHTML
+	}
+	return item.Content
+}
+
+// itemCategories returns the category followed by the tags of a feed item.
+func itemCategories(item models.FeedItem) []string {
+	var categories []string
+	if item.CategoryName != "" {
+		categories = append(categories, item.CategoryName)
+	}
+	return append(categories, item.Tags...)
+}
+
+// lastUpdated returns the newest publication time, or now for an empty feed.
+func lastUpdated(items []models.FeedItem) time.Time {
+	if len(items) == 0 {
+		return time.Now().UTC()
+	}
+	return items[0].PublishedAt.UTC() // Items are ordered newest first
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,49 @@
+// Package geoip resolves the country of client IP addresses from a MaxMind database, such as
+// the free GeoLite2 Country or City database (https://dev.maxmind.com/geoip/geolite2-free-geolocation-data).
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Locator looks up countries in a MaxMind database. A nil *Locator knows no countries.
+type Locator struct {
+	reader *maxminddb.Reader
+}
+
+// Open opens the MaxMind database at path, or returns nil when path is empty. The database is
+// memory-mapped rather than read, and stays open for the lifetime of the process.
+func Open(path string) (*Locator, error) {
+	if path == "" {
+		return nil, nil
+	}
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	return &Locator{reader: reader}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip (e.g., "ID"), or an empty
+// string if it is unknown, e.g., for private addresses.
+func (l *Locator) Country(ip string) string {
+	if l == nil {
+		return ""
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := l.reader.Lookup(parsed, &record); err != nil {
+		return ""
+	}
+	return record.Country.ISOCode
+}
@@ -0,0 +1,88 @@
+// Package i18n translates the user-facing messages of the API. Messages are referenced by key
+// and looked up in the catalogs under locales/, one JSON file per language.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when a request accepts none of the supported languages, and for
+// messages missing from the catalog of a language.
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps languages to their messages by key.
+var catalogs = loadCatalogs()
+
+// loadCatalogs reads the embedded catalogs. They are part of the binary, so a broken one is a
+// programming error.
+func loadCatalogs() map[string]map[string]string {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read catalogs: %v", err))
+	}
+
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := localeFS.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read catalog %s: %v", file.Name(), err))
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: failed to parse catalog %s: %v", file.Name(), err))
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	if _, ok := loaded[DefaultLanguage]; !ok {
+		panic("i18n: no catalog for the default language " + DefaultLanguage)
+	}
+	return loaded
+}
+
+// T returns the message with the given key in language, formatted with args like fmt.Sprintf.
+// Messages missing from the catalog of language are taken from the default language; unknown
+// keys are returned as they are.
+func T(language, key string, args ...interface{}) string {
+	message, ok := catalogs[language][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLanguage][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Match returns the supported language preferred by an Accept-Language header value
+// (e.g., "id-ID,id;q=0.9,en;q=0.8"), or DefaultLanguage if it accepts none of them. Regional
+// variants match their language, so "en-GB" picks "en".
+func Match(acceptLanguage string) string {
+	best, bestQuality := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := catalogs[language]; ok && quality > bestQuality {
+			best, bestQuality = language, quality
+		}
+	}
+	return best
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+)
+
+// CleanupCount is how many rows of a table ExpiredCleanup or LogRetention deleted or anonymized.
+type CleanupCount struct {
+	Deleted     int64     `json:"deleted"`      // Since startup
+	LastDeleted int64     `json:"last_deleted"` // By the last run
+	LastRunAt   time.Time `json:"last_run_at"`
+}
+
+// CleanupMetrics counts the rows deleted by a cleanup task per table. It is safe for concurrent use.
+type CleanupMetrics struct {
+	mu     sync.Mutex
+	tables map[string]CleanupCount
+}
+
+// Cleanups counts the rows deleted by the session-cleanup task of the server, reported by /metrics.
+var Cleanups = &CleanupMetrics{}
+
+// Retention counts the log entries purged by the log-retention task of the server, reported by /metrics.
+var Retention = &CleanupMetrics{}
+
+// record adds the rows deleted per table by the run at at.
+func (m *CleanupMetrics) record(at time.Time, deleted map[string]int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tables == nil {
+		m.tables = map[string]CleanupCount{}
+	}
+	for table, n := range deleted {
+		count := m.tables[table]
+		count.Deleted += n
+		count.LastDeleted = n
+		count.LastRunAt = at
+		m.tables[table] = count
+	}
+}
+
+// Snapshot returns the counts of the tables cleaned up so far, by table name.
+func (m *CleanupMetrics) Snapshot() map[string]CleanupCount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]CleanupCount, len(m.tables))
+	for table, count := range m.tables {
+		snapshot[table] = count
+	}
+	return snapshot
+}
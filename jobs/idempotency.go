@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// StartIdempotencyKeyCleanup deletes idempotency keys older than ttl every interval in a
+// background goroutine, so their responses are no longer replayed. The returned function stops the job.
+func StartIdempotencyKeyCleanup(idempotencyService services.IdempotencyServiceInterface, ttl, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				runCtx, cancelRun := context.WithTimeout(ctx, interval)
+				deleted, err := idempotencyService.DeleteExpired(runCtx, time.Now().Add(-ttl))
+				cancelRun()
+				if err != nil {
+					slog.Error("Error deleting expired idempotency keys", "error", err)
+					continue
+				}
+				if deleted > 0 {
+					slog.Info("Deleted expired idempotency keys", "keys", deleted)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		cancel()
+	}
+}
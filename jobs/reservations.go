@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// StartReservationExpiry releases expired stock reservations every interval in a background goroutine.
+// The returned function stops the job.
+func StartReservationExpiry(orderService services.OrderServiceInterface, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				// A run must not overlap the next one, so it gets at most one interval to finish
+				runCtx, cancelRun := context.WithTimeout(ctx, interval)
+				released, err := orderService.ReleaseExpiredReservations(runCtx)
+				cancelRun()
+				if err != nil {
+					slog.Error("Error releasing expired stock reservations", "error", err)
+					continue
+				}
+				if released > 0 {
+					slog.Info("Released stock reservations of expired orders", "orders", released)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		cancel() // Also aborts a release that is still running
+	}
+}
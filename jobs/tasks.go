@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/scheduler"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// livePostBatchSize is how many scheduled posts that went live PostPublishing claims at a time.
+const livePostBatchSize = 100
+
+// ExpiredCleanup returns the scheduled task deleting expired sessions and tokens, counting the
+// deleted rows in metrics.
+func ExpiredCleanup(maintenanceService services.MaintenanceServiceInterface, metrics *CleanupMetrics) scheduler.Func {
+	return func(ctx context.Context) error {
+		now := time.Now()
+		deleted, err := maintenanceService.DeleteExpired(ctx, now)
+		metrics.record(now, deleted) // Tables cleaned up before an error count as well
+		if err != nil {
+			return err
+		}
+		for table, n := range deleted {
+			if n > 0 {
+				slog.Info("Deleted expired rows", "table", table, "rows", n)
+			}
+		}
+		return nil
+	}
+}
+
+// LogRetention returns the scheduled task purging login logs, failed logins, audit logs and
+// settled webhook deliveries older than retention in mode (see models.RetentionDelete), counting
+// the purged entries in metrics.
+func LogRetention(maintenanceService services.MaintenanceServiceInterface, retention time.Duration, mode string, metrics *CleanupMetrics) scheduler.Func {
+	return func(ctx context.Context) error {
+		now := time.Now()
+		counts, err := maintenanceService.PurgeLogs(ctx, now.Add(-retention), mode)
+		purged := make(map[string]int64, len(counts))
+		for _, count := range counts {
+			purged[count.Table] = count.Rows
+			if count.Rows > 0 {
+				slog.Info("Purged old log entries", "table", count.Table, "action", count.Action, "entries", count.Rows)
+			}
+		}
+		metrics.record(now, purged) // Tables purged before an error count as well
+		return err
+	}
+}
+
+// PostPublishing returns the scheduled task telling authors that their scheduled posts went
+// live. Posts are claimed before their authors are notified, so no author is told twice, not
+// even by several instances.
+func PostPublishing(postService services.PostServiceInterface, notificationService services.NotificationServiceInterface) scheduler.Func {
+	return func(ctx context.Context) error {
+		for {
+			posts, err := postService.ClaimLivePosts(ctx, time.Now(), livePostBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, post := range posts {
+				data, err := json.Marshal(map[string]interface{}{"post_id": post.ID})
+				if err != nil {
+					return fmt.Errorf("failed to marshal notification data: %w", err)
+				}
+				err = notificationService.Notify(ctx, post.UserID, &models.Notification{
+					Type:  models.NotificationTypePostPublished,
+					Title: "Your post is live",
+					Body:  fmt.Sprintf("Your scheduled post \"%s\" has been published.", post.Title),
+					Data:  data,
+				})
+				if err != nil {
+					// The post stays claimed: its author misses the notification rather than getting it twice
+					slog.Error("Error notifying author of published post", "post_id", post.ID, "user_id", post.UserID, "error", err)
+				}
+			}
+			if len(posts) > 0 {
+				slog.Info("Announced scheduled posts", "posts", len(posts))
+			}
+			if len(posts) < livePostBatchSize {
+				return nil
+			}
+		}
+	}
+}
+
+// LowStockCheck returns the scheduled task sending the admins of every tenant a summary of the
+// products running low.
+func LowStockCheck(notificationService services.NotificationServiceInterface) scheduler.Func {
+	return func(ctx context.Context) error {
+		notified, err := notificationService.NotifyLowStockSummary(ctx)
+		if err != nil {
+			return err
+		}
+		if notified > 0 {
+			slog.Info("Sent low stock summaries", "tenants", notified)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// webhookBatchSize bounds the deliveries sent at once by a run of the webhook delivery job.
+const webhookBatchSize = 20
+
+// StartWebhookDelivery sends the queued webhook deliveries that are due every interval in a
+// background goroutine, retrying failed ones with backoff until maxAttempts have been made.
+// A run keeps sending batches while deliveries are due. The returned function stops the job.
+func StartWebhookDelivery(webhookService services.WebhookServiceInterface, sender services.WebhookSender, maxAttempts int, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				for ctx.Err() == nil {
+					attempted, err := webhookService.DeliverDue(ctx, sender, maxAttempts, webhookBatchSize)
+					if err != nil {
+						slog.Error("Error delivering webhooks", "error", err)
+					}
+					if attempted < webhookBatchSize {
+						break // Nothing else is due
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		cancel() // Also aborts the deliveries being sent; they are retried after their claim expires
+	}
+}
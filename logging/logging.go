@@ -0,0 +1,69 @@
+// Package logging configures the application's structured logger (log/slog) and carries
+// request-scoped loggers through contexts.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Output formats of the LOG_FORMAT setting.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// level is the minimum level of the default logger; SetLevel changes it while the server runs.
+var level = new(slog.LevelVar)
+
+// Setup makes a logger writing to stderr in format ("json" or "console") the default of
+// log/slog. Output of the standard log package goes through it as well, at info level.
+func Setup(format, levelName string) error {
+	if err := SetLevel(levelName); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	case FormatConsole:
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("unknown log format %q, expected %q or %q", format, FormatJSON, FormatConsole)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel sets the minimum level of the default logger: "debug", "info", "warn" or "error".
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(name))); err != nil {
+		return fmt.Errorf("unknown log level %q", name)
+	}
+	level.Set(l)
+	return nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, e.g., the request logger set up by
+// middleware.RequestLogger, or the default logger if there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
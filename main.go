@@ -2,97 +2,155 @@ package main
 
 import (
 	// Added for sql.ErrNoRows
-	"log"
-	"net/http"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	jwtware "github.com/gofiber/contrib/jwt" // Use the base module path for jwtware (no /v5 here)
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-
-	// Import the JWT library itself
-	"github.com/anpsniper/anpbayu-be/config"      // Your config package
-	"github.com/anpsniper/anpbayu-be/controllers" // Import controllers package
-	"github.com/anpsniper/anpbayu-be/database"    // Your database package
-	"github.com/anpsniper/anpbayu-be/models"      // Your models package (User, Role, etc.)
-	"github.com/anpsniper/anpbayu-be/routes"      // Your routes package
-	"github.com/anpsniper/anpbayu-be/services"    // Import services package
+	"github.com/getsentry/sentry-go"
+
+	"github.com/anpsniper/anpbayu-be/config"   // Your config package
+	"github.com/anpsniper/anpbayu-be/database" // Your database package
+	"github.com/anpsniper/anpbayu-be/jobs"     // Background jobs
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/realtime" // Events pushed to connected clients
+	"github.com/anpsniper/anpbayu-be/seeds"    // Fixture seeding
+	"github.com/anpsniper/anpbayu-be/server"   // HTTP server
+	"github.com/anpsniper/anpbayu-be/services" // Import services package
+	"github.com/anpsniper/anpbayu-be/webhooks" // Outgoing webhooks
 )
 
 func main() {
-	// 1. Load application configurations from environment variables or .env file
+	// Without a command the server is started, see newRootCommand
+	if err := newRootCommand().Execute(); err != nil {
+		fatal("Command failed", "error", err)
+	}
+}
+
+// setup loads the application configuration from environment variables or the .env file and
+// sets up logging. It runs before every command.
+func setup() error {
 	if err := config.LoadConfig(); err != nil {
-		log.Fatalf("Failed to load application configuration: %v", err)
+		return fmt.Errorf("failed to load application configuration: %w", err)
+	}
+
+	// Log in the configured format (LOG_FORMAT) from here on; the level follows the runtime
+	// settings (LOG_LEVEL) and changes when they are reloaded.
+	if err := logging.Setup(config.AppConfig.LogFormat, config.Current().LogLevel); err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+	config.OnReload(func(settings *config.Runtime) {
+		_ = logging.SetLevel(settings.LogLevel) // Validated by config.Reload
+	})
+	return nil
+}
+
+// serve runs the HTTP server, along with the background jobs and recurring tasks, until it
+// stops.
+func serve() error {
+	// 1. Report panics and server errors to Sentry or GlitchTip when SENTRY_DSN is set, see
+	// middleware.ReportErrors
+	if config.AppConfig.SentryDSN != "" {
+		err := sentry.Init(sentry.ClientOptions{
+			Dsn:         config.AppConfig.SentryDSN,
+			Environment: config.AppConfig.AppEnv,
+			Release:     config.AppConfig.SentryRelease,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set up error reporting: %w", err)
+		}
+		defer sentry.Flush(2 * time.Second) // Send the events still queued
 	}
 
-	// 2. Initialize database connection (using database/sql as per your db.go)
-	if err := database.InitDatabase(&config.AppConfig); err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// 2. Initialize database connection for the configured dialect (DB_DIALECT, see db.go).
+	// The returned pool is injected into every service; there is no global connection.
+	db, pool, err := database.InitDatabase(&config.AppConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	// Ensure database connection is closed when the application exits
-	defer database.CloseDatabase()
-
-	// 3. Seed roles and example user
-	// These functions (in models/modelseed.go) are now compatible with database/sql
-	// and expect database.DB to be *sql.DB.
-	log.Println("Seeding roles...")
-	if err := models.SeedRoles(); err != nil {
-		log.Fatalf("Failed to seed roles: %v", err)
+	defer database.CloseDatabase(db, pool)
+
+	// 3. Seed roles, users and products from the fixture files (see seeds/fixtures)
+	if config.AppConfig.SeedOnStartup {
+		if err := runSeed(db, config.AppConfig.SeedEnv); err != nil {
+			return err
+		}
+	}
+
+	// Release the stock held by unpaid orders once their reservation expires
+	stopReservationExpiry := jobs.StartReservationExpiry(services.NewOrderService(db), time.Minute)
+	defer stopReservationExpiry()
+
+	// Forget the responses of idempotent requests once IDEMPOTENCY_KEY_TTL_HOURS have passed
+	stopIdempotencyKeyCleanup := jobs.StartIdempotencyKeyCleanup(services.NewIdempotencyService(db), config.AppConfig.IdempotencyKeyTTL, time.Hour)
+	defer stopIdempotencyKeyCleanup()
+
+	// Send the queued outgoing webhooks, retrying failed deliveries with backoff (WEBHOOK_TIMEOUT_SECONDS, WEBHOOK_MAX_ATTEMPTS, WEBHOOK_ALLOW_PRIVATE_NETWORKS)
+	webhookSender := webhooks.NewSender(config.AppConfig.WebhookTimeout, config.AppConfig.WebhookAllowPrivateNetworks)
+	stopWebhookDelivery := jobs.StartWebhookDelivery(services.NewWebhookService(db), webhookSender, config.AppConfig.WebhookMaxAttempts, 5*time.Second)
+	defer stopWebhookDelivery()
+
+	// Push events such as new notifications to the clients connected to /ws
+	hub := realtime.NewHub()
+
+	// Run the recurring tasks on their cron schedules (SCHEDULE_*); admins can also run them on demand
+	sched, err := server.NewScheduler(db, hub)
+	if err != nil {
+		return fmt.Errorf("failed to schedule tasks: %w", err)
 	}
-	log.Println("Roles seeded successfully.")
+	sched.Start()
+	defer sched.Stop()
 
-	log.Println("Seeding example user...")
-	if err := models.SeedExampleUser(); err != nil {
-		log.Fatalf("Failed to seed example user: %v", err)
+	// 4. Build the Fiber app with its middleware and routes (see server.New)
+	app, redisStore, err := server.New(db, pool, sched, hub)
+	if err != nil {
+		return err
 	}
-	log.Println("Example user seeded successfully.")
-
-	// 4. Initialize Fiber app
-	app := fiber.New()
-
-	// 5. Configure CORS middleware using configuration from config package
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     config.AppConfig.FrontendOrigin,
-		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		AllowCredentials: true,
-	}))
-
-	// 6. Health Check Endpoint (publicly accessible)
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.Status(http.StatusOK).JSON(fiber.Map{
-			"status":  "healthy",
-			"message": "GoFiber backend is running!",
-			"time":    time.Now().Format(time.RFC3339),
-		})
-	})
+	if redisStore != nil {
+		defer redisStore.Close()
+	}
+
+	// Reload the runtime settings on SIGHUP; POST /api/admin/config/reload does the same
+	go reloadOnSIGHUP()
+
+	// 5. Start the Fiber server, with HTTPS when TLS is configured (see tls.go)
+	if err := listen(app, &config.AppConfig); err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
+}
+
+// fatal logs msg with args at error level and exits the process.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// reloadOnSIGHUP reloads the runtime settings every time the process receives SIGHUP.
+func reloadOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if _, err := config.Reload(); err != nil {
+			slog.Error("Error reloading configuration on SIGHUP, keeping the previous settings", "error", err)
+		}
+	}
+}
 
-	// Initialize UserService and AuthController
-	userService := services.NewUserService()
-	authController := controllers.NewAuthController(userService)
-
-	// 7. Authentication Login Route (publicly accessible, handled by AuthController)
-	// This replaces the manual login handler that was here.
-	app.Post("/login", authController.Login) // Frontend should hit this endpoint directly
-
-	// 8. JWT Middleware (Applies to all routes defined AFTER this point)
-	// This middleware will protect all subsequent routes unless explicitly overridden.
-	app.Use(jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{Key: []byte(config.AppConfig.JWTSecret)},
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			if err.Error() == "Missing or malformed JWT" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or malformed JWT"})
-			}
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired JWT"})
-		},
-	}))
-
-	// 9. Setup all API routes (these will now be protected by the JWT middleware,
-	// and some will have additional role-based checks via `middleware.HasRole`).
-	// The /api/auth/logout route will also be handled by the authController within SetupAPIRoutes.
-	routes.SetupAPIRoutes(app)
-
-	// 10. Start the Fiber server
-	log.Printf("GoFiber API server starting on port %s...", config.AppConfig.AppPort)
-	log.Fatal(app.Listen(":" + config.AppConfig.AppPort))
+// runSeed seeds the fixtures of env. The development fixtures, whose accounts have known
+// passwords, are refused in production.
+func runSeed(db *database.DB, env string) error {
+	if config.AppConfig.IsProduction() && env == config.EnvDevelopment {
+		return fmt.Errorf("the %s fixtures can't be seeded in the %s environment", env, config.EnvProduction)
+	}
+	slog.Info("Seeding fixtures", "env", env)
+	if err := seeds.Run(context.Background(), db, env, config.AppConfig.SeedDir); err != nil {
+		return fmt.Errorf("failed to seed fixtures of %s: %w", env, err)
+	}
+	slog.Info("Fixtures seeded successfully", "env", env)
+	return nil
 }
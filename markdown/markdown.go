@@ -0,0 +1,34 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// renderer converts GitHub-flavoured Markdown (tables, strikethrough, autolinks, task lists) to HTML.
+// Raw HTML in the source is escaped by goldmark's default (unsafe rendering disabled).
+var renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// policy is the XSS-safe allowlist applied to the rendered HTML. The UGC policy keeps
+// formatting, links, images and tables but drops scripts, event handlers, styles and
+// javascript: URLs; links additionally get rel="nofollow noopener" and open in a new tab.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("code") // e.g., "language-go" for syntax highlighting
+	return p
+}()
+
+// RenderHTML renders Markdown source to sanitized HTML that is safe to embed in a page.
+func RenderHTML(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return policy.Sanitize(buf.String()), nil
+}
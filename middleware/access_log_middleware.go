@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// AccessLog logs one line per request with its method, path, route, status, latency, response
+// size (unless streamed), client IP and the authenticated user, using the request logger (see RequestLogger,
+// which must run before it). Only samplePercent percent of the successful requests are logged;
+// requests failing with a 5xx status are always logged. Requests for the paths in exclude
+// (e.g., /health, polled by load balancers) are never logged.
+func AccessLog(samplePercent int, exclude []string) fiber.Handler {
+	excluded := make(map[string]bool, len(exclude))
+	for _, path := range exclude {
+		excluded[path] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if excluded[c.Path()] {
+			return c.Next()
+		}
+
+		start := time.Now()
+		logger := logging.FromContext(c.UserContext())
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil { // The error handler writes the response after the middleware returns
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		if status < fiber.StatusInternalServerError && rand.IntN(100) >= samplePercent {
+			return err
+		}
+
+		attrs := []any{
+			"status", status,
+			"route", c.Route().Path,
+			"latency", time.Since(start),
+			"ip", ClientIP(c),
+		}
+		if !c.Response().IsBodyStream() { // Reading a streamed body would buffer it whole
+			attrs = append(attrs, "bytes", len(c.Response().Body()))
+		}
+		if userID, ok := GetUserIDFromJWT(c); ok {
+			attrs = append(attrs, "user_id", userID)
+		}
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(c.UserContext(), level, "Request completed", attrs...)
+		return err
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/anpsniper/anpbayu-be/audit"
+)
+
+// IdentifyActor attaches the authenticated user, the client IP and the request ID to
+// c.UserContext(), so the changes the request makes are attributed to them in the audit log.
+// It must run after the JWT middleware and RequestLogger.
+func IdentifyActor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		actor := audit.Actor{IPAddress: utils.CopyString(ClientIP(c))}
+		if userID, ok := GetUserIDFromJWT(c); ok {
+			actor.UserID = userID
+		}
+		if requestID, ok := c.Locals("requestid").(string); ok {
+			actor.RequestID = requestID
+		}
+		c.SetUserContext(audit.WithActor(c.UserContext(), actor))
+		return c.Next()
+	}
+}
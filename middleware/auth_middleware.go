@@ -1,24 +1,49 @@
 package middleware
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"  // Standard Fiber import path
 	"github.com/golang-jwt/jwt/v5" // Using v5 for JWT
+	"github.com/google/uuid"
 
 	"github.com/anpsniper/anpbayu-be/config" // Import your config package
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/services"
 )
 
-// GenerateJWT creates a new JWT token for the given user ID and roles.
-// Make sure this function exists and is exported (starts with a capital G).
-func GenerateJWT(userID string, email string, roles []string) (string, error) {
-	// Define your claims
+// TokenTTL is how long the tokens of GenerateJWT, and the sessions created along with them, are valid.
+const TokenTTL = 24 * time.Hour
+
+// GenerateJWT creates a new JWT token for subject, valid for TokenTTL. Besides the core claims
+// (user_id, email, roles, exp, iat and jti), the token carries the claims registered with
+// RegisterClaim, such as tenant_id and token_version.
+func GenerateJWT(ctx context.Context, subject TokenSubject) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": userID,
-		"email":   email,
-		"roles":   roles,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // Token expires in 24 hours
+		"user_id": subject.UserID,
+		"email":   subject.Email,
+		"roles":   subject.Roles,
+		"exp":     time.Now().Add(TokenTTL).Unix(), // Token expires in 24 hours
 		"iat":     time.Now().Unix(),
+		"jti":     uuid.New().String(), // Tokens issued within the same second differ, and so do their sessions
+	}
+	for _, claim := range registeredClaims() {
+		value, err := claim.Value(ctx, subject)
+		if err != nil {
+			return "", fmt.Errorf("failed to build claim %s: %w", claim.Name, err)
+		}
+		if value != nil {
+			claims[claim.Name] = value
+		}
 	}
 
 	// Create token
@@ -102,9 +127,210 @@ func GetUserRolesFromJWT(c *fiber.Ctx) ([]string, bool) {
 	return nil, false // roles not found or not a slice/string
 }
 
+// GetTokenVersionFromJWT returns the token version of the request's token (the "token_version"
+// claim), see GenerateJWT.
+// It assumes the jwtware.New middleware has already run and populated c.Locals("user").
+func GetTokenVersionFromJWT(c *fiber.Ctx) (int, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return 0, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	version, ok := claims["token_version"].(float64) // JSON numbers are decoded as float64
+	if !ok {
+		return 0, false
+	}
+	return int(version), true
+}
+
+// GetTokenExpiryFromJWT returns when the token of the request (the "exp" claim) expires.
+// It assumes the jwtware.New middleware has already run and populated c.Locals("user").
+func GetTokenExpiryFromJWT(c *fiber.Ctx) (time.Time, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return time.Time{}, false
+	}
+	expiresAt, err := token.Claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return time.Time{}, false
+	}
+	return expiresAt.Time, true
+}
+
+// GetTokenFromJWT returns the token the request was authenticated with, as sent by the client.
+// It assumes the jwtware.New middleware has already run and populated c.Locals("user").
+func GetTokenFromJWT(c *fiber.Ctx) (string, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok || token.Raw == "" {
+		return "", false
+	}
+	return token.Raw, true
+}
+
+// TokenFromQuery lets clients pass their token in the param query parameter of requests to
+// paths (e.g., "/ws?access_token=..."), as browsers can't set headers when opening WebSockets
+// or event streams. It copies the token to the Authorization header, so it must run before the
+// JWT middleware. Requests to other paths must send the header, keeping tokens out of URLs.
+func TokenFromQuery(param string, paths ...string) fiber.Handler {
+	allowed := map[string]bool{}
+	for _, path := range paths {
+		allowed[path] = true
+	}
+	return func(c *fiber.Ctx) error {
+		if allowed[c.Path()] && c.Get(fiber.HeaderAuthorization) == "" {
+			if token := c.Query(param); token != "" {
+				c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// TokenCookies reports whether tokens are handed out in a cookie rather than in the body of the
+// login response (TOKEN_DELIVERY=cookie).
+func TokenCookies() bool {
+	return config.AppConfig.TokenDelivery == "cookie"
+}
+
+// HeaderCSRFToken is the header carrying the CSRF token of requests authenticated with the
+// cookie of SetTokenCookie, see CSRFToken.
+const HeaderCSRFToken = "X-CSRF-Token"
+
+// SetTokenCookie sends token to the client in the cookie AUTH_COOKIE_NAME, expiring along with
+// the token. The cookie is Secure and httpOnly, so scripts can't read it, and browsers send it
+// back with the requests their SameSite setting allows. Its CSRF token goes along in the cookie
+// AUTH_COOKIE_NAME + "_csrf", which scripts of the frontend can read when it shares the site.
+func SetTokenCookie(c *fiber.Ctx, token string, expiresAt time.Time) {
+	csrfToken := ""
+	if token != "" {
+		csrfToken = CSRFToken(token)
+	}
+	for _, cookie := range []struct {
+		name, value string
+		httpOnly    bool
+	}{
+		{config.AppConfig.AuthCookieName, token, true},
+		{config.AppConfig.AuthCookieName + "_csrf", csrfToken, false},
+	} {
+		c.Cookie(&fiber.Cookie{
+			Name:     cookie.name,
+			Value:    cookie.value,
+			Path:     "/",
+			Domain:   config.AppConfig.AuthCookieDomain,
+			Expires:  expiresAt,
+			Secure:   true, // Browsers accept Secure cookies from http://localhost as well
+			HTTPOnly: cookie.httpOnly,
+			SameSite: config.AppConfig.AuthCookieSameSite,
+		})
+	}
+}
+
+// ClearTokenCookie makes the client delete the cookies of SetTokenCookie.
+func ClearTokenCookie(c *fiber.Ctx) {
+	SetTokenCookie(c, "", time.Unix(0, 0))
+}
+
+// CSRFToken returns the CSRF token of token, which requests authenticated with the cookie of
+// SetTokenCookie must send in the X-CSRF-Token header to change anything (AUTH_COOKIE_CSRF).
+// Other sites can make browsers send the cookie, but can't read it nor the login response: as
+// the CSRF token is signed with the JWT secret, they can't forge it either.
+func CSRFToken(token string) string {
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.JWTSecret))
+	mac.Write([]byte("csrf:" + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkCSRF reports whether c may go on as far as cross-site request forgery is concerned:
+// requests that only read (GET, HEAD, OPTIONS), that send their token in the Authorization
+// header, which other sites can't make browsers do, or that carry the CSRF token of the cookie
+// they are authenticated with.
+func checkCSRF(c *fiber.Ctx, token string) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	if c.Get(fiber.HeaderAuthorization) != "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(c.Get(HeaderCSRFToken)), []byte(CSRFToken(token))) == 1
+}
+
+// StaticToken rejects requests with 401 Unauthorized unless they send token as bearer token
+// in the Authorization header, e.g., for the metrics scraped by Prometheus (METRICS_TOKEN).
+func StaticToken(token string) fiber.Handler {
+	return Named("StaticToken", func(c *fiber.Ctx) error {
+		sent := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing or invalid bearer token"})
+		}
+		return c.Next()
+	})
+}
+
+// JWT returns the middleware authenticating requests with a bearer token signed with the JWT
+// secret (see GenerateJWT), or with the cookie of SetTokenCookie when TOKEN_DELIVERY is
+// "cookie". The token is put into c.Locals("user") for the helpers above. Requests authenticated
+// with the cookie must also send its CSRF token to change anything, unless AUTH_COOKIE_CSRF is off.
+// Tokens must carry the required registered claims (see RegisterClaim), with valid values.
+// Tokens are only accepted while the session created on login exists in sessions, so logging
+// out revokes them before they expire, and while they carry the current token version of their
+// user, so role and password changes take effect right away. A nil sessions skips both checks,
+// e.g., in tests.
+func JWT(sessions services.SessionServiceInterface) fiber.Handler {
+	tokenLookup := "header:" + fiber.HeaderAuthorization
+	if TokenCookies() {
+		tokenLookup += ",cookie:" + config.AppConfig.AuthCookieName // The header takes precedence, e.g., for API clients
+	}
+	csrf := TokenCookies() && config.AppConfig.AuthCookieCSRF
+	return Named("JWT", jwtware.New(jwtware.Config{
+		SigningKey:  jwtware.SigningKey{Key: []byte(config.AppConfig.JWTSecret)},
+		TokenLookup: tokenLookup,
+		AuthScheme:  "Bearer",
+		SuccessHandler: func(c *fiber.Ctx) error {
+			if token, ok := c.Locals("user").(*jwt.Token); ok {
+				if claims, ok := token.Claims.(jwt.MapClaims); ok {
+					if invalid := checkClaims(claims); invalid != "" {
+						return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token lacks a valid " + invalid + " claim, log in again"})
+					}
+				}
+			}
+			if csrf {
+				if token, _ := GetTokenFromJWT(c); !checkCSRF(c, token) {
+					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Missing or invalid " + HeaderCSRFToken + " header"})
+				}
+			}
+			if sessions == nil {
+				return c.Next()
+			}
+			token, _ := GetTokenFromJWT(c)
+			session, err := sessions.GetSession(c.UserContext(), token)
+			if err != nil {
+				logging.FromContext(c.UserContext()).Error("Error validating session", "error", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate session"})
+			}
+			if session == nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session expired or logged out"})
+			}
+			if version, ok := GetTokenVersionFromJWT(c); !ok || version != session.UserTokenVersion {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token outdated by a role or password change, log in again"})
+			}
+			return c.Next()
+		},
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			if errors.Is(err, jwtware.ErrJWTMissingOrMalformed) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or malformed JWT"})
+			}
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired JWT"})
+		},
+	}))
+}
+
 // JwtError is a custom error handler for the Fiber JWT middleware.
 func JwtError(c *fiber.Ctx, err error) error {
-	if err.Error() == "Missing or malformed JWT" {
+	if errors.Is(err, jwtware.ErrJWTMissingOrMalformed) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"status": "error", "message": "Missing or malformed JWT"})
 	}
 	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"status": "error", "message": "Invalid or expired JWT"})
@@ -114,7 +340,29 @@ func JwtError(c *fiber.Ctx, err error) error {
 // at least one of the required roles.
 // It should be used AFTER the main JWT authentication middleware.
 func HasRole(requiredRoles ...string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return Named("HasRole("+strings.Join(requiredRoles, ", ")+")", func(c *fiber.Ctx) error {
+		if _, ok := GetUserRolesFromJWT(c); !ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Forbidden: User roles not found in token or token invalid. (Ensure JWT middleware runs first)",
+			})
+		}
+
+		if UserHasRole(c, requiredRoles...) {
+			return c.Next() // User has at least one required role, proceed
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Forbidden: Insufficient role permissions",
+		})
+	})
+}
+
+// HasPermission is a Fiber middleware that checks if one of the roles of the authenticated user
+// has permission (one of the models.Permission* constants), as granted in the permission matrix
+// of roles. The admin role has every permission. Grants are checked on every request, so
+// changes apply at once. It should be used AFTER the main JWT authentication middleware.
+func HasPermission(roles services.RoleServiceInterface, permission string) fiber.Handler {
+	return Named("HasPermission("+permission+")", func(c *fiber.Ctx) error {
 		userRoles, ok := GetUserRolesFromJWT(c)
 		if !ok {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -122,16 +370,35 @@ func HasRole(requiredRoles ...string) fiber.Handler {
 			})
 		}
 
-		for _, requiredRole := range requiredRoles {
-			for _, userRole := range userRoles {
-				if userRole == requiredRole {
-					return c.Next() // User has at least one required role, proceed
-				}
-			}
+		granted, err := roles.HasPermission(c.UserContext(), userRoles, permission)
+		if err != nil {
+			logging.FromContext(c.UserContext()).Error("Error checking permission", "permission", permission, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check permissions"})
+		}
+		if granted {
+			return c.Next()
 		}
 
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "Forbidden: Insufficient role permissions",
 		})
+	})
+}
+
+// UserHasRole reports whether the authenticated user has at least one of the given roles.
+// Unlike HasRole it doesn't abort the request, so controllers can use it for ownership checks
+// (e.g., "the author or an admin may edit this post").
+func UserHasRole(c *fiber.Ctx, roles ...string) bool {
+	userRoles, ok := GetUserRolesFromJWT(c)
+	if !ok {
+		return false
+	}
+	for _, role := range roles {
+		for _, userRole := range userRoles {
+			if userRole == role {
+				return true
+			}
+		}
 	}
+	return false
 }
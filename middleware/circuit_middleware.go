@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/database"
+)
+
+// DatabaseCircuit rejects requests with 503 Service Unavailable while the database circuit
+// breaker is open, instead of letting them wait for connection timeouts. The Retry-After
+// header tells clients when the next trial request will be let through.
+func DatabaseCircuit(breaker *database.Breaker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if breaker.Allow() {
+			return c.Next()
+		}
+		retryAfter := int(math.Ceil(breaker.RetryAfter().Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "Database is unavailable, please retry later"})
+	}
+}
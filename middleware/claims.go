@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TokenSubject is the user a token is issued to by GenerateJWT.
+type TokenSubject struct {
+	UserID       string
+	TenantID     string
+	Email        string
+	Roles        []string
+	TokenVersion int // The user's models.User.TokenVersion; the token is rejected once it changes
+}
+
+// Claim is a claim GenerateJWT adds to every token, besides user_id, email, roles and the
+// registered claims exp, iat and jti.
+type Claim struct {
+	Name string
+	// Value returns the value of the claim in the token of subject; a nil value leaves the
+	// claim out of the token.
+	Value func(ctx context.Context, subject TokenSubject) (interface{}, error)
+	// Required makes JWT reject tokens without the claim, e.g., those issued before it was
+	// registered.
+	Required bool
+	// Valid, if set, makes JWT reject tokens whose claim has a value it refuses. Values are
+	// decoded from JSON: numbers are float64 and arrays []interface{}.
+	Valid func(value interface{}) bool
+}
+
+// coreClaims are the claims GenerateJWT always sets, which can't be registered.
+var coreClaims = map[string]bool{"user_id": true, "email": true, "roles": true, "exp": true, "iat": true, "jti": true}
+
+var (
+	claimsMu sync.RWMutex
+	claims   []Claim // Registered claims, in the order they were first registered
+)
+
+func init() {
+	// Scopes every request made with the token, see ResolveTenantFromJWT
+	_ = RegisterClaim(Claim{Name: "tenant_id", Required: true, Value: func(_ context.Context, subject TokenSubject) (interface{}, error) {
+		return subject.TenantID, nil
+	}})
+	// Outdated by role and password changes, see JWT
+	_ = RegisterClaim(Claim{Name: "token_version", Required: true, Value: func(_ context.Context, subject TokenSubject) (interface{}, error) {
+		return subject.TokenVersion, nil
+	}})
+}
+
+// RegisterClaim adds claim to the tokens GenerateJWT issues from now on, replacing the claim
+// with the same name, if any. Claims are meant to be registered at startup, before requests
+// are served. The names of the core claims (user_id, email, roles, exp, iat and jti) can't be
+// registered.
+func RegisterClaim(claim Claim) error {
+	if claim.Name == "" || claim.Value == nil {
+		return fmt.Errorf("claim %q must have a name and a value", claim.Name)
+	}
+	if coreClaims[claim.Name] {
+		return fmt.Errorf("claim %q is set by GenerateJWT and can't be registered", claim.Name)
+	}
+
+	claimsMu.Lock()
+	defer claimsMu.Unlock()
+	for i := range claims {
+		if claims[i].Name == claim.Name {
+			claims[i] = claim
+			return nil
+		}
+	}
+	claims = append(claims, claim)
+	return nil
+}
+
+// RegisterStaticClaims registers the name=value pairs of JWT_EXTRA_CLAIMS as required claims
+// with a fixed value, so tokens issued by another deployment sharing the JWT secret, or with
+// another configuration, are rejected.
+func RegisterStaticClaims(pairs []string) error {
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("claim %q must be a name=value pair", pair)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		err := RegisterClaim(Claim{
+			Name:     name,
+			Value:    func(context.Context, TokenSubject) (interface{}, error) { return value, nil },
+			Required: true,
+			Valid:    func(v interface{}) bool { return v == value },
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registeredClaims returns a copy of the registered claims.
+func registeredClaims() []Claim {
+	claimsMu.RLock()
+	defer claimsMu.RUnlock()
+	return append([]Claim(nil), claims...)
+}
+
+// checkClaims returns the name of the first registered claim the claims of a token lack while
+// it is required, or have with a value it refuses; it returns "" if the claims are acceptable.
+func checkClaims(tokenClaims map[string]interface{}) string {
+	for _, claim := range registeredClaims() {
+		value, ok := tokenClaims[claim.Name]
+		if (!ok && claim.Required) || (ok && claim.Valid != nil && !claim.Valid(value)) {
+			return claim.Name
+		}
+	}
+	return ""
+}
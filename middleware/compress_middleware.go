@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Compression levels of the COMPRESSION_LEVEL setting.
+const (
+	CompressionOff     = "off"
+	CompressionSpeed   = "speed"
+	CompressionDefault = "default"
+	CompressionBest    = "best"
+)
+
+// Compress compresses responses of at least minSize bytes with brotli or gzip (or deflate),
+// whichever the client accepts (Accept-Encoding), at level. Smaller responses are sent as they
+// are, since compressing them costs more time than it saves. Only compressible content types
+// such as JSON and text are compressed; streamed responses are always compressed, except event
+// streams, whose events must reach the client as soon as they are sent.
+func Compress(level string, minSize int) fiber.Handler {
+	var brotliLevel, gzipLevel int
+	switch level {
+	case CompressionSpeed:
+		brotliLevel, gzipLevel = fasthttp.CompressBrotliBestSpeed, fasthttp.CompressBestSpeed
+	case CompressionBest:
+		brotliLevel, gzipLevel = fasthttp.CompressBrotliBestCompression, fasthttp.CompressBestCompression
+	case CompressionDefault:
+		brotliLevel, gzipLevel = fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressDefaultCompression
+	default:
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	compressor := fasthttp.CompressHandlerBrotliLevel(func(*fasthttp.RequestCtx) {}, brotliLevel, gzipLevel)
+
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if strings.HasPrefix(string(c.Response().Header.ContentType()), "text/event-stream") {
+			return nil
+		}
+		// The size of a stream is unknown without reading it, which would defeat streaming
+		if !c.Response().IsBodyStream() && len(c.Response().Body()) < minSize {
+			return nil
+		}
+		compressor(c.Context())
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// descriptions holds the names given to handlers with Named, keyed by handlerKey.
+var descriptions sync.Map
+
+// closureSuffix matches the suffixes of the names of closures and method values, e.g., the
+// ".func1" of "middleware.HasRole.func1" and the "-fm" of "(*PostController).GetPost-fm".
+var closureSuffix = regexp.MustCompile(`((\.func\d+)|(-fm))+$`)
+
+// Named records description as the name Describe lists handler with, e.g., "HasRole(admin)" for
+// a closure that would otherwise only be known by the function creating it. It returns handler.
+func Named(description string, handler fiber.Handler) fiber.Handler {
+	descriptions.Store(handlerKey(handler), description)
+	return handler
+}
+
+// Describe returns a readable name of handler for listing routes (see the routes command):
+// the name given with Named, or else the package qualified name of the function, e.g.,
+// "middleware.RequestLogger" or "controllers.(*PostController).GetPost".
+func Describe(handler fiber.Handler) string {
+	if description, ok := descriptions.Load(handlerKey(handler)); ok {
+		return description.(string)
+	}
+	fn := runtime.FuncForPC(*(*uintptr)(handlerKey(handler)))
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return closureSuffix.ReplaceAllString(name, "")
+}
+
+// handlerKey identifies a handler by the closure its func value points to, which, unlike the
+// code pointer, differs between the handlers built by one function with different arguments.
+func handlerKey(handler fiber.Handler) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&handler))
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReportErrors sends the 5xx responses of later handlers to Sentry (or a compatible service
+// such as GlitchTip), tagged with the request ID, user ID and route. Errors returned by handlers
+// are reported by the error handler with ReportError, which knows the status they are answered
+// with, and panics by Recover, which must run after this middleware. Nothing is sent unless
+// sentry.Init was called with a DSN (SENTRY_DSN).
+func ReportErrors() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if status := c.Response().StatusCode(); err == nil && status >= fiber.StatusInternalServerError {
+			// Handlers that logged an error and answered it themselves
+			requestHub(c).CaptureMessage(fmt.Sprintf("%s %s answered %d", c.Method(), c.Route().Path, status))
+		}
+		return err
+	}
+}
+
+// ReportError sends err, which the request is answered with a 5xx status for, to Sentry.
+// Panics were already reported by Recover, with their stack trace.
+func ReportError(c *fiber.Ctx, err error) {
+	if c.Locals("panic") != nil {
+		return
+	}
+	requestHub(c).CaptureException(err)
+}
+
+// requestHub returns a Sentry hub whose scope describes the request.
+func requestHub(c *fiber.Ctx) *sentry.Hub {
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		if requestID, ok := c.Locals("requestid").(string); ok {
+			scope.SetTag("request_id", requestID)
+		}
+		scope.SetTag("route", c.Route().Path)
+		scope.SetTag("method", c.Method())
+		user := sentry.User{IPAddress: ClientIP(c)}
+		if userID, ok := GetUserIDFromJWT(c); ok {
+			user.ID = userID
+		}
+		scope.SetUser(user)
+		scope.SetContext("request", sentry.Context{
+			"method": c.Method(),
+			"path":   c.Path(), // Without the query, which may carry tokens (see TokenFromQuery)
+		})
+	})
+	return hub
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag adds a weak ETag, a hash of the body, to successful GET responses and answers 304 Not
+// Modified when the request's If-None-Match lists it, so clients polling lists and records only
+// download them again when they change. Streamed responses are left alone: hashing them would
+// mean buffering them. Register it after Compress, so the ETag describes the uncompressed body.
+func ETag() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		res := c.Response()
+		if res.StatusCode() != fiber.StatusOK || res.IsBodyStream() || len(res.Header.Peek(fiber.HeaderETag)) > 0 {
+			return nil
+		}
+		body := res.Body()
+		if len(body) == 0 {
+			return nil
+		}
+
+		hash := fnv.New64a()
+		_, _ = hash.Write(body)
+		etag := fmt.Sprintf(`W/"%x-%x"`, len(body), hash.Sum64())
+		c.Set(fiber.HeaderETag, etag)
+
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Context().ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// etagMatches reports whether an If-None-Match value (e.g., `W/"1a-2b", W/"3c-4d"` or "*")
+// lists etag, using the weak comparison of RFC 9110 section 13.1.2.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
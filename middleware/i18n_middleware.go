@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/i18n"
+)
+
+// Localize picks the language of the response messages from the Accept-Language header of
+// the request (see i18n.Match) and announces it in the Content-Language response header.
+func Localize() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		language := i18n.Match(c.Get(fiber.HeaderAcceptLanguage))
+		c.Locals("language", language)
+		c.Set(fiber.HeaderContentLanguage, language)
+		c.Vary(fiber.HeaderAcceptLanguage) // Caches must not serve a response in another language
+		return c.Next()
+	}
+}
+
+// Language returns the language picked by Localize for the request, or i18n.DefaultLanguage
+// if Localize didn't run.
+func Language(c *fiber.Ctx) string {
+	if language, ok := c.Locals("language").(string); ok {
+		return language
+	}
+	return i18n.DefaultLanguage
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// Headers of idempotent requests.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed" // "true" on responses replayed from a previous request
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency makes a mutating route safe to retry. A request with an Idempotency-Key header
+// is processed once per user and key: retries get the stored response of the first request
+// instead of creating a duplicate record. Reusing a key for a different request is rejected
+// with 422, and retrying while the first request is still processed with 409. Responses with
+// a server error status aren't stored, so the request can be retried. Requests without the
+// header are processed as usual. It must run after the JWT middleware.
+func Idempotency(idempotencyService services.IdempotencyServiceInterface) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Idempotency-Key must be at most 255 characters long"})
+		}
+		userID, ok := GetUserIDFromJWT(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized: User ID not found in token"})
+		}
+		logger := logging.FromContext(c.UserContext()).With("idempotency_key", key)
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+		hash.Write(c.Body())
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		existing, err := idempotencyService.Reserve(c.UserContext(), userID, key, requestHash)
+		if errors.Is(err, services.ErrConflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A request with this Idempotency-Key has just failed, retry it"})
+		}
+		if err != nil {
+			logger.Error("Error reserving idempotency key", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to process Idempotency-Key"})
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != requestHash:
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Idempotency-Key was already used for a different request"})
+			case !existing.Completed():
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A request with this Idempotency-Key is still being processed"})
+			}
+			logger.Debug("Replaying response of idempotent request", "status", existing.StatusCode)
+			c.Set(HeaderIdempotentReplayed, "true")
+			if existing.ContentType != "" {
+				c.Set(fiber.HeaderContentType, existing.ContentType)
+			}
+			return c.Status(existing.StatusCode).Send(existing.Body)
+		}
+
+		// Errors returned by handlers are turned into responses here instead of by Fiber, so
+		// the response that is stored is the one the client gets
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			if err := idempotencyService.Release(c.UserContext(), userID, key); err != nil {
+				logger.Error("Error releasing idempotency key", "error", err)
+			}
+			return nil
+		}
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if err := idempotencyService.Complete(c.UserContext(), userID, key, status, contentType, body); err != nil {
+			logger.Error("Error storing response of idempotent request", "error", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// RealIP determines the IP address of the client when the API runs behind reverse proxies.
+// Requests from the trusted networks (TRUSTED_PROXIES) carry the client's address in the
+// X-Forwarded-For header; it is read from the right, skipping the trusted proxies that
+// appended to it, so clients can't spoof their address by sending the header themselves.
+// Requests from other addresses are taken at face value. See ClientIP.
+func RealIP(trustedProxies []*net.IPNet) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(trustedProxies) > 0 {
+			c.Locals("clientip", forwardedClientIP(c.Context().RemoteIP(), c.Get(fiber.HeaderXForwardedFor), trustedProxies))
+		}
+		return c.Next()
+	}
+}
+
+// ClientIP returns the IP address of the client as determined by RealIP, or the address of
+// the connection if RealIP didn't run.
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals("clientip").(string); ok {
+		return ip
+	}
+	return c.IP()
+}
+
+// forwardedClientIP returns the rightmost address of the chain of remote and the addresses
+// in forwardedFor that isn't one of the trusted proxies.
+func forwardedClientIP(remote net.IP, forwardedFor string, trustedProxies []*net.IPNet) string {
+	ip := remote
+	if !containsIP(trustedProxies, ip) {
+		return ip.String()
+	}
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break // Malformed entries can't be trusted, nor anything left of them
+		}
+		ip = hop
+		if !containsIP(trustedProxies, hop) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// IPFilter rejects requests with 403 Forbidden unless their client IP (see ClientIP) is
+// in one of the allowed networks, or if it is in one of the denied ones. Denials win over
+// allowances; an empty allow list allows every address that isn't denied.
+func IPFilter(allow, deny []*net.IPNet) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(allow) == 0 && len(deny) == 0 {
+			return c.Next()
+		}
+		ip := net.ParseIP(ClientIP(c))
+		if containsIP(deny, ip) || !IPAllowed(c, allow) {
+			logging.FromContext(c.UserContext()).Warn("Request from a blocked IP address rejected", "ip", ip)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Access from your IP address is not allowed"})
+		}
+		return c.Next()
+	}
+}
+
+// IPAllowed reports whether the client IP of c (see ClientIP) is in one of the allowed networks,
+// or allow is empty, for handlers checking the allow list of IPFilter themselves.
+func IPAllowed(c *fiber.Ctx, allow []*net.IPNet) bool {
+	return len(allow) == 0 || containsIP(allow, net.ParseIP(ClientIP(c)))
+}
+
+// containsIP reports whether ip is in one of networks.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
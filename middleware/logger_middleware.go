@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// maxRequestIDLength bounds the length of request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// RequestLogger gives every request an ID and a logger carrying it, attached to c.UserContext()
+// (see logging.FromContext). The ID is taken from the X-Request-ID header, e.g., when set by a
+// proxy in front of the API, or generated, and is echoed in the X-Request-ID response header.
+// It should be the first middleware, so every later log line of the request carries the ID.
+func RequestLogger() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = utils.UUIDv4()
+		}
+		requestID = utils.CopyString(requestID) // Fiber reuses the request buffers once the handler returns
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.Locals("requestid", requestID)
+
+		logger := slog.Default().With(
+			"request_id", requestID,
+			"method", c.Method(),
+			"path", utils.CopyString(c.Path()),
+		)
+		c.SetUserContext(logging.NewContext(c.UserContext(), logger))
+		return c.Next()
+	}
+}
+
+// LogAuthenticatedUser adds the authenticated user (and their tenant) to the request logger.
+// It must run after the JWT middleware.
+func LogAuthenticatedUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := GetUserIDFromJWT(c)
+		if !ok {
+			return c.Next()
+		}
+		logger := logging.FromContext(c.UserContext()).With("user_id", userID)
+		if tenantID, ok := GetTenantIDFromJWT(c); ok {
+			logger = logger.With("tenant_id", tenantID)
+		}
+		c.SetUserContext(logging.NewContext(c.UserContext(), logger))
+		return c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// MaintenanceMode rejects the requests of everyone but admins with 503 Service Unavailable
+// while maintenance mode is enabled (see services.SettingService). The state is read from the
+// database at most once per refresh interval, so a change made on one instance reaches the
+// others within that interval; changes made on this instance apply at once, see Set.
+type MaintenanceMode struct {
+	settingService services.SettingServiceInterface
+	refresh        time.Duration
+
+	mu       sync.Mutex
+	mode     *models.MaintenanceMode
+	loadedAt time.Time
+}
+
+// NewMaintenanceMode creates and returns a new MaintenanceMode instance, starting out disabled
+// until the state is first read.
+func NewMaintenanceMode(settingService services.SettingServiceInterface, refresh time.Duration) *MaintenanceMode {
+	return &MaintenanceMode{
+		settingService: settingService,
+		refresh:        refresh,
+		mode:           &models.MaintenanceMode{},
+	}
+}
+
+// Set makes mode the current state, after it was stored with the setting service.
+func (m *MaintenanceMode) Set(mode *models.MaintenanceMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mode, m.loadedAt = mode, time.Now()
+}
+
+// Current returns the current state, reading it again once the refresh interval has passed.
+// If it can't be read, the last known state stays current.
+func (m *MaintenanceMode) Current(ctx context.Context) *models.MaintenanceMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Since(m.loadedAt) < m.refresh {
+		return m.mode
+	}
+	mode, err := m.settingService.GetMaintenanceMode(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to read maintenance mode, keeping the last known state", "error", err)
+	} else {
+		m.mode = mode
+	}
+	m.loadedAt = time.Now() // Don't retry failed reads on every request
+	return m.mode
+}
+
+// Handler returns the middleware. It must run after the JWT middleware, so admins are
+// recognized; on public routes every request counts as a non-admin one.
+func (m *MaintenanceMode) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		mode := m.Current(c.UserContext())
+		if !mode.Enabled || UserHasRole(c, "admin") {
+			return c.Next()
+		}
+		message := mode.Message
+		if message == "" {
+			message = "The service is down for maintenance, please retry later"
+		}
+		retryAfter := int(math.Ceil(mode.RetryAfter(time.Now()).Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": message})
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UUIDParams rejects requests whose route parameters with the given names aren't UUIDs with
+// 400 Bad Request, before the handler passes them to the database, where PostgreSQL would fail
+// the query instead of finding no record. Names the matched route doesn't have are ignored, so
+// one handler serves every route of an API.
+func UUIDParams(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, name := range names {
+			if value := c.Params(name); value != "" && !isUUID(value) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Malformed " + name + " parameter, expected a UUID"})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// isUUID reports whether value is a UUID in its canonical form, e.g.
+// "3f0bde2c-03a8-4e06-9d6b-107b50970e0e"; the braced and URN forms uuid.Parse accepts are not.
+func isUUID(value string) bool {
+	return len(value) == 36 && uuid.Validate(value) == nil
+}
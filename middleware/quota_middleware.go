@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/quota"
+)
+
+// Headers describing the quotas of the authenticated user, also sent with 429 responses.
+// Those of a window are only sent when it is limited.
+const (
+	headerQuotaHourlyLimit     = "X-Quota-Hourly-Limit"
+	headerQuotaHourlyRemaining = "X-Quota-Hourly-Remaining"
+	headerQuotaHourlyReset     = "X-Quota-Hourly-Reset"
+	headerQuotaDailyLimit      = "X-Quota-Daily-Limit"
+	headerQuotaDailyRemaining  = "X-Quota-Daily-Remaining"
+	headerQuotaDailyReset      = "X-Quota-Daily-Reset"
+)
+
+// QuotaLimits returns the quotas of every user under settings (QUOTA_PER_HOUR, QUOTA_PER_DAY).
+func QuotaLimits(settings *config.Runtime) quota.Limits {
+	return quota.Limits{Hourly: settings.QuotaPerHour, Daily: settings.QuotaPerDay}
+}
+
+// QuotaSubject returns the subject the requests of a user are counted for by tracker.
+func QuotaSubject(userID string) string {
+	return "user:" + userID
+}
+
+// Quota builds a middleware that counts the requests of every authenticated user with tracker
+// and rejects those over their hourly or daily quota under settings with 429 Too Many
+// Requests, until the window resets. It must run after the JWT middleware. When the counts
+// can't be read, e.g., with Redis down, requests are let through.
+func Quota(settings *config.Runtime, tracker *quota.Tracker) fiber.Handler {
+	limits := QuotaLimits(settings)
+	if limits.Hourly <= 0 && limits.Daily <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return func(c *fiber.Ctx) error {
+		userID, ok := GetUserIDFromJWT(c)
+		if !ok {
+			return c.Next()
+		}
+		usage, err := tracker.Use(c.UserContext(), QuotaSubject(userID), limits)
+		if err != nil {
+			logging.FromContext(c.UserContext()).Error("Error counting request against quota", "user_id", userID, "error", err)
+			return c.Next()
+		}
+
+		setQuotaHeaders(c, usage.Hourly, headerQuotaHourlyLimit, headerQuotaHourlyRemaining, headerQuotaHourlyReset)
+		setQuotaHeaders(c, usage.Daily, headerQuotaDailyLimit, headerQuotaDailyRemaining, headerQuotaDailyReset)
+		if window, exceeded := usage.Exceeded(); exceeded {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(secondsUntil(window.ResetsAt)))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Request quota exceeded, please retry later"})
+		}
+		return c.Next()
+	}
+}
+
+// setQuotaHeaders describes window in the limit, remaining and reset headers, the latter in
+// seconds like X-RateLimit-Reset.
+func setQuotaHeaders(c *fiber.Ctx, window quota.Window, limit, remaining, reset string) {
+	if window.Limit <= 0 {
+		return
+	}
+	c.Set(limit, strconv.Itoa(window.Limit))
+	c.Set(remaining, strconv.Itoa(window.Remaining))
+	c.Set(reset, strconv.Itoa(secondsUntil(window.ResetsAt)))
+}
+
+// secondsUntil returns the whole seconds until t, rounded up.
+func secondsUntil(t time.Time) int {
+	return int((time.Until(t) + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+
+	"github.com/anpsniper/anpbayu-be/config"
+)
+
+// Headers describing the rate limit of the client, also sent with 429 responses.
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimit builds a middleware that allows settings.RateLimit requests per minute and client IP
+// and rejects the rest with 429 Too Many Requests. A zero limit lets every request through.
+// The counters are kept in store, e.g., Redis to share them between instances, or in memory
+// when store is nil.
+func RateLimit(settings *config.Runtime, store fiber.Storage) fiber.Handler {
+	return perMinuteLimit(settings.RateLimit, store, "global", clientIP, "Too many requests, please retry later")
+}
+
+// LoginRateLimit builds a middleware that allows settings.LoginRateLimit login attempts per
+// minute and client IP, to slow down password guessing.
+func LoginRateLimit(settings *config.Runtime, store fiber.Storage) fiber.Handler {
+	return perMinuteLimit(settings.LoginRateLimit, store, "login", clientIP, "Too many login attempts, please retry later")
+}
+
+// AdminWriteRateLimit builds a middleware that allows administrators settings.AdminWriteRateLimit
+// modifying requests (POST, PUT, PATCH and DELETE) per minute, counted per user rather than
+// per IP. Requests of other users and reading requests are not limited by it. It must run
+// after the JWT middleware.
+func AdminWriteRateLimit(settings *config.Runtime, store fiber.Storage) fiber.Handler {
+	limit := perMinuteLimit(settings.AdminWriteRateLimit, store, "admin-write", jwtUserID, "Too many changes, please retry later")
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+		if !UserHasRole(c, "admin") {
+			return c.Next()
+		}
+		return limit(c)
+	}
+}
+
+// perMinuteLimit limits requests to max per minute and client, as identified by key; a zero
+// max disables the limit. The counters are stored under name, so limiters sharing a store
+// count separately.
+func perMinuteLimit(max int, store fiber.Storage, name string, key func(*fiber.Ctx) string, message string) fiber.Handler {
+	if max <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: time.Minute,
+		Storage:    store,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "ratelimit:" + name + ":" + key(c)
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			// The limiter only sets Retry-After on rejected requests; complete the headers it
+			// sends with accepted ones, so clients can rely on them either way
+			c.Set(headerRateLimitLimit, strconv.Itoa(max))
+			c.Set(headerRateLimitRemaining, "0")
+			c.Set(headerRateLimitReset, string(c.Response().Header.Peek(fiber.HeaderRetryAfter)))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": message})
+		},
+	})
+}
+
+// clientIP identifies clients by their IP address.
+func clientIP(c *fiber.Ctx) string {
+	return ClientIP(c)
+}
+
+// jwtUserID identifies clients by the authenticated user, falling back to the IP address.
+func jwtUserID(c *fiber.Ctx) string {
+	if userID, ok := GetUserIDFromJWT(c); ok {
+		return "user:" + userID
+	}
+	return "ip:" + ClientIP(c)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+	fiberrecover "github.com/gofiber/fiber/v2/middleware/recover"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// Recover turns panics of later handlers into errors, so the error handler answers them with
+// the standard JSON error response instead of the connection being dropped without one. The
+// panic is logged with its stack trace by the request logger, so with the request ID, and
+// reported to Sentry when it's configured (see ReportErrors).
+func Recover() fiber.Handler {
+	return fiberrecover.New(fiberrecover.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c *fiber.Ctx, value interface{}) {
+			c.Locals("panic", value) // Already reported, see ReportError
+			logging.FromContext(c.UserContext()).Error("Handler panicked", "panic", value, "stack", string(debug.Stack()))
+			requestHub(c).RecoverWithContext(c.UserContext(), value)
+		},
+	})
+}
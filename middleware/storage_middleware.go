@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// SignedDownloads guards the files of store served under prefix (e.g., "/uploads"): only
+// requests with the unexpired signature of a URL handed out by store.URL get through, so
+// files can't be downloaded by guessing or keeping their URLs.
+func SignedDownloads(prefix string, store *storage.LocalStorage) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimPrefix(c.Path(), strings.TrimSuffix(prefix, "/")+"/")
+		if !store.Verify(key, c.Query("expires"), c.Query("signature")) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden: Download URL is invalid or has expired"})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+
+	"github.com/anpsniper/anpbayu-be/config"
+)
+
+// Swappable is a middleware whose implementation can be replaced while the server runs.
+// Fiber middleware is configured when it is built, so settings that are reloaded at runtime
+// (see config.Reload) are applied by building a new handler and swapping it in.
+type Swappable struct {
+	handler atomic.Pointer[fiber.Handler]
+}
+
+// NewSwappable returns a Swappable that starts out running handler.
+func NewSwappable(handler fiber.Handler) *Swappable {
+	s := &Swappable{}
+	s.Swap(handler)
+	return s
+}
+
+// Swap makes handler serve all following requests.
+func (s *Swappable) Swap(handler fiber.Handler) {
+	s.handler.Store(&handler)
+}
+
+// Handler returns the middleware to register with Fiber. It is listed by Describe as the
+// handler it runs at the time.
+func (s *Swappable) Handler() fiber.Handler {
+	return Named(Describe(*s.handler.Load()), func(c *fiber.Ctx) error {
+		return (*s.handler.Load())(c)
+	})
+}
+
+// CORS builds the CORS middleware for the allowed origins of the runtime settings.
+func CORS(settings *config.Runtime) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(settings.FrontendOrigins, ","),
+		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, " + HeaderIdempotencyKey + ", " + HeaderCSRFToken,
+		ExposeHeaders:    HeaderIdempotentReplayed,
+		AllowCredentials: true,
+	})
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// ResolveTenantFromSubdomain resolves the tenant of the request from the subdomain of its host
+// below baseDomain (e.g., "acme" for "acme.example.com") and attaches it to c.UserContext().
+// Requests for the base domain itself keep the default tenant; an empty baseDomain disables
+// subdomain resolution. Unknown subdomains are rejected with 404.
+func ResolveTenantFromSubdomain(tenantService services.TenantServiceInterface, baseDomain string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if baseDomain == "" {
+			return c.Next()
+		}
+		slug, ok := strings.CutSuffix(c.Hostname(), "."+baseDomain)
+		if !ok || slug == "" || slug == "www" {
+			return c.Next()
+		}
+
+		tenant, err := tenantService.GetTenantBySlug(c.UserContext(), slug)
+		if err != nil {
+			logging.FromContext(c.UserContext()).Error("Error resolving tenant for subdomain", "slug", slug, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve tenant"})
+		}
+		if tenant == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tenant not found"})
+		}
+
+		c.SetUserContext(tenancy.WithTenant(c.UserContext(), tenant.ID))
+		return c.Next()
+	}
+}
+
+// ResolveTenantFromJWT attaches the tenant of the authenticated user (the "tenant_id" claim)
+// to c.UserContext(). It must run after the JWT middleware. A token issued for another tenant
+// than the one the subdomain resolved to is rejected with 403.
+func ResolveTenantFromJWT() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID, ok := GetTenantIDFromJWT(c)
+		if !ok {
+			return c.Next() // Tokens issued before multi-tenancy act for the resolved (or default) tenant
+		}
+		if resolved, ok := tenancy.FromContext(c.UserContext()); ok && resolved != tenantID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden: Token belongs to another tenant"})
+		}
+
+		c.SetUserContext(tenancy.WithTenant(c.UserContext(), tenantID))
+		return c.Next()
+	}
+}
+
+// PlatformOnly restricts the routes it guards to the users of the platform tenant (see
+// tenancy.IsPlatform), on top of their permission checks: roles, the permission matrix and
+// settings are shared by every tenant, whose admins mustn't change them for the others. It must
+// run after ResolveTenantFromJWT.
+func PlatformOnly() fiber.Handler {
+	return Named("PlatformOnly", func(c *fiber.Ctx) error {
+		if tenancy.IsPlatform(c.UserContext()) {
+			return c.Next()
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Forbidden: Only the administrators of the platform may change what all tenants share",
+		})
+	})
+}
+
+// GetTenantIDFromJWT extracts the tenant ID from the JWT claims in the Fiber context.
+// It assumes the jwtware.New middleware has already run and populated c.Locals("user").
+func GetTenantIDFromJWT(c *fiber.Ctx) (string, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return "", false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", false
+	}
+	tenantID, ok := claims["tenant_id"].(string)
+	return tenantID, ok && tenantID != ""
+}
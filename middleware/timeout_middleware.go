@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// QueryDeadline attaches a deadline to the request's user context (c.UserContext()).
+// Controllers pass that context to the services, so every database query of the request is
+// cancelled once the deadline passes instead of holding the request goroutine indefinitely.
+// A zero timeout disables the deadline.
+func QueryDeadline(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if timeout <= 0 {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// uploadFormOverhead is the room left above the file size limit for multipart boundaries,
+// part headers and other form fields.
+const uploadFormOverhead = 1 << 20
+
+// contentTypeAliases maps nonstandard MIME types clients declare to the ones
+// http.DetectContentType sniffs.
+var contentTypeAliases = map[string]string{
+	"image/jpg":   "image/jpeg",
+	"image/pjpeg": "image/jpeg",
+	"image/x-png": "image/png",
+}
+
+// Uploads screens multipart uploads before they reach the handler and the storage: the
+// request body may be at most maxFileSize (plus room for the form) large, and every file in
+// the form must be at most maxFileSize large, must not have a name with one of the blocked
+// extensions (e.g., ".php", also in "shell.php.jpg") and must have the content its declared
+// content type claims. Handlers still check the sniffed type against the types they accept.
+func Uploads(maxFileSize int64, blockedExtensions []string) fiber.Handler {
+	blocked := map[string]bool{}
+	for _, ext := range blockedExtensions {
+		blocked["."+strings.TrimPrefix(strings.ToLower(strings.TrimSpace(ext)), ".")] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if int64(c.Request().Header.ContentLength()) > maxFileSize+uploadFormOverhead || int64(len(c.Body())) > maxFileSize+uploadFormOverhead {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Request body is too large for an upload"})
+		}
+		if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+			return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "Uploads must be sent as multipart/form-data"})
+		}
+		form, err := c.MultipartForm()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Malformed multipart form"})
+		}
+
+		logger := logging.FromContext(c.UserContext())
+		for field, files := range form.File {
+			for _, fileHeader := range files {
+				if fileHeader.Size > maxFileSize {
+					return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Uploaded file is too large"})
+				}
+				if ext, ok := blockedExtension(fileHeader.Filename, blocked); ok {
+					logger.Warn("Upload with a blocked extension rejected", "field", field, "filename", fileHeader.Filename)
+					return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "Files with the extension " + ext + " are not allowed"})
+				}
+
+				file, err := fileHeader.Open()
+				if err != nil {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read the uploaded file"})
+				}
+				head := make([]byte, 512)
+				n, err := io.ReadFull(file, head)
+				file.Close()
+				if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read the uploaded file"})
+				}
+				declared, sniffed := mediaType(fileHeader.Header.Get(fiber.HeaderContentType)), mediaType(http.DetectContentType(head[:n]))
+				if declared != "" && declared != "application/octet-stream" && declared != sniffed {
+					logger.Warn("Upload with a mismatching content type rejected", "field", field, "filename", fileHeader.Filename, "declared", declared, "sniffed", sniffed)
+					return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "Declared content type " + declared + " doesn't match the content of the file (" + sniffed + ")"})
+				}
+			}
+		}
+		return c.Next()
+	}
+}
+
+// blockedExtension returns the first extension of name that is blocked, looking at every
+// extension so double extensions such as "shell.php.jpg" can't hide one.
+func blockedExtension(name string, blocked map[string]bool) (string, bool) {
+	parts := strings.Split(strings.ToLower(name), ".")
+	for _, part := range parts[1:] {
+		if ext := "." + strings.TrimSpace(part); blocked[ext] {
+			return ext, true
+		}
+	}
+	return "", false
+}
+
+// mediaType returns the lowercase media type of a Content-Type value, without parameters.
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	if alias, ok := contentTypeAliases[mediaType]; ok {
+		return alias
+	}
+	return mediaType
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Periods the login analytics can be grouped by.
+const (
+	AnalyticsGroupDay  = "day"
+	AnalyticsGroupWeek = "week" // ISO weeks, starting on Monday
+)
+
+// LoginAnalytics summarizes the logins of the users of a tenant over a time range, in UTC.
+type LoginAnalytics struct {
+	From                  time.Time         `json:"from"`
+	To                    time.Time         `json:"to"`
+	GroupBy               string            `json:"group_by"`                // AnalyticsGroupDay or AnalyticsGroupWeek
+	Logins                int               `json:"logins"`                  // Logins over the whole range
+	ActiveUsers           int               `json:"active_users"`            // Users who logged in over the whole range
+	AverageSessionSeconds *float64          `json:"average_session_seconds"` // Of the sessions that were logged out; nil without any
+	Periods               []LoginPeriodStat `json:"periods"`                 // Every day or week of the range, oldest first
+}
+
+// LoginPeriodStat summarizes the logins of a day or week. ActiveUsers are the daily or weekly
+// active users, depending on the grouping.
+type LoginPeriodStat struct {
+	Start                 time.Time `json:"start"`
+	Logins                int       `json:"logins"`
+	ActiveUsers           int       `json:"active_users"`
+	AverageSessionSeconds *float64  `json:"average_session_seconds"` // Of the sessions of the period's logins that were logged out; nil without any
+}
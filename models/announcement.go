@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Announcement is a message an admin broadcasts to the users of their tenant. It is pushed to
+// their connected clients (see realtime) rather than stored per user like a Notification.
+type Announcement struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	CreatedBy *string   `json:"created_by"` // ID of the admin who broadcast it; nil once they are deleted
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment represents a file (e.g., an image) uploaded to a post.
+type Attachment struct {
+	ID          string    `json:"id"`           // Unique identifier for the attachment (UUID)
+	PostID      string    `json:"post_id"`      // Foreign key to the posts table
+	UserID      string    `json:"user_id"`      // User who uploaded the file
+	FileName    string    `json:"file_name"`    // Original file name as sent by the client
+	ContentType string    `json:"content_type"` // Sniffed MIME type of the file
+	Size        int64     `json:"size"`         // File size in bytes
+	StorageKey  string    `json:"-"`            // Key of the file in the storage backend, never exposed
+	URL         string    `json:"url"`          // Public download URL, resolved from StorageKey
+	CreatedAt   time.Time `json:"created_at"`   // Timestamp when the file was uploaded
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Types of the entities whose changes are recorded in the audit log.
+const (
+	AuditEntityUser     = "user"
+	AuditEntityRole     = "role"
+	AuditEntityPost     = "post"
+	AuditEntityProduct  = "product"
+	AuditEntitySetting  = "setting"
+	AuditEntityUserNote = "user_note"
+)
+
+// AuditLog records a change of an entity: who made it, and the entity before and after it.
+type AuditLog struct {
+	ID         string          `json:"id"`
+	TenantID   string          `json:"tenant_id"`
+	ActorID    *string         `json:"actor_id"` // nil for changes made by the system, e.g., seeding
+	Action     string          `json:"action"`   // One of the AuditAction* constants
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"` // JSON snapshot of the entity; null for creations
+	After      json.RawMessage `json:"after"`  // JSON snapshot of the entity; null for deletions
+	IPAddress  string          `json:"ip_address"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditLogFilter narrows down the audit log; empty fields don't filter.
+type AuditLogFilter struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time // Changes made at or after this time
+	To         *time.Time // Changes made before this time
+}
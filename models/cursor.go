@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Cursor is a position in a list paginated by keyset, such as the login history or the audit
+// log: the time and ID of the last item of a page. The next page starts after it, which stays
+// fast however deep the page, unlike an OFFSET.
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+// String encodes the cursor for clients, who send it back as is.
+func (c Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// ParseCursor decodes a cursor encoded by Cursor.String.
+func ParseCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	timestamp, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, errors.New("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return &Cursor{Time: t, ID: id}, nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Reasons of failed login attempts.
+const (
+	LoginFailureUnknownAccount  = "unknown_account"  // No user has the email or phone number tried
+	LoginFailureWrongTenant     = "wrong_tenant"     // The user belongs to another tenant than the subdomain logged in on
+	LoginFailureInvalidPassword = "invalid_password" // The password is wrong
+	LoginFailureInvalidCode     = "invalid_code"     // The SMS login code is wrong or expired
+	LoginFailureLockedOut       = "locked_out"       // The account or IP is locked out after too many failures
+)
+
+// FailedLogin records a failed login attempt.
+type FailedLogin struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Account   string    `json:"account"` // Email or phone number tried
+	Reason    string    `json:"reason"`  // One of the LoginFailure* constants
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FailedLoginFilter narrows down the failed login attempts; empty fields don't filter.
+type FailedLoginFilter struct {
+	Account   string
+	IPAddress string
+	Reason    string
+	From      *time.Time // Attempts made at or after this time
+	To        *time.Time // Attempts made before this time
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Fields is the selection of fields of the fields query parameter of list and detail
+// endpoints, by their JSON names, so clients can ask for only the fields they need. Services
+// skip reading wide columns that aren't selected. nil selects every field.
+type Fields []string
+
+// Has reports whether the field named name is selected.
+func (f Fields) Has(name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, field := range f {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldsOf returns the JSON names of the fields of v, a struct or a pointer to one, in order:
+// the fields that can be selected of the records it describes.
+func FieldsOf(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotencyKey records a request sent with an Idempotency-Key header and, once it has been
+// processed, its response, which is replayed when the request is retried with the same key.
+type IdempotencyKey struct {
+	UserID      string    `json:"user_id"` // Keys are scoped to the user that sent them
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"` // SHA-256 of the method, path and body of the first request
+	StatusCode  int       `json:"status_code"`  // 0 while the first request is being processed
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Completed reports whether the response of the first request has been stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// MoneyScale is the number of decimal places stored for prices and amounts (NUMERIC(12, 2)).
+const MoneyScale = 2
+
+func init() {
+	// Money is serialized as a plain JSON number (e.g., 12.5) so existing clients keep working;
+	// the digits are exact, unlike a float64. Quoted strings are accepted on input as well.
+	decimal.MarshalJSONWithoutQuotes = true
+}
+
+// IsValidPrice reports whether amount is non-negative and has no more than MoneyScale decimal places.
+func IsValidPrice(amount decimal.Decimal) bool {
+	return !amount.IsNegative() && amount.Equal(amount.Round(MoneyScale))
+}
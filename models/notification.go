@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of notifications.
+const (
+	NotificationTypeWelcome       = "welcome"        // Sent to a user when their account is created
+	NotificationTypeLowStock      = "low_stock"      // Sent to the admins when a product's stock falls to LowStockThreshold, and daily while products run low
+	NotificationTypePostPublished = "post_published" // Sent to the author when a scheduled post goes live
+	NotificationTypeNewLogin      = "new_login"      // Sent to a user when they log in from a device or country they didn't use before
+	NotificationTypeInvitation    = "invitation"     // Emailed to the invitees of an organization, who may not have an account yet
+)
+
+// Notification is a message to a user, shown in the app and sent through the other configured
+// channels, such as email.
+type Notification struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"` // Recipient
+	Type      string          `json:"type"`    // One of the NotificationType* constants
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data,omitempty"` // Details for clients, e.g., {"product_id": "..."}
+	ReadAt    *time.Time      `json:"read_at"`        // nil while unread
+	CreatedAt time.Time       `json:"created_at"`
+}
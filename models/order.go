@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Order statuses.
+const (
+	OrderStatusPending   = "pending"   // Created, waiting for payment
+	OrderStatusPaid      = "paid"      // Payment confirmed by the provider
+	OrderStatusFailed    = "failed"    // Payment failed or expired; the order may be checked out again
+	OrderStatusCancelled = "cancelled" // Cancelled before payment, e.g., because its stock reservation expired
+)
+
+// Order represents a customer's order of one or more products.
+type Order struct {
+	ID            string          `json:"id"`
+	UserID        string          `json:"user_id"`                  // Customer who placed the order
+	Status        string          `json:"status"`                   // One of the OrderStatus* constants
+	TotalAmount   decimal.Decimal `json:"total_amount"`             // Sum of all line totals at the time of ordering
+	Currency      string          `json:"currency"`                 // ISO 4217 code (e.g., "idr")
+	ReservedUntil *time.Time      `json:"reserved_until,omitempty"` // Unpaid orders release their stock after this; nil once paid or cancelled
+	Items         []OrderItem     `json:"items"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// OrderItem is a single product line of an order. UnitPrice is copied from the product
+// when the order is placed, so later price changes do not affect existing orders.
+type OrderItem struct {
+	ID          string          `json:"id"`
+	OrderID     string          `json:"order_id"`
+	ProductID   string          `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+}
+
+// OrderItemRequest represents one requested product line when placing an order.
+type OrderItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	Quantity  int    `json:"quantity" validate:"gte=1"`
+}
+
+// NewOrder creates a new pending Order for the user whose stock stays reserved for reservationTTL.
+// The ID, items and total are filled in by the service.
+func NewOrder(userID, currency string, reservationTTL time.Duration) *Order {
+	now := time.Now()
+	reservedUntil := now.Add(reservationTTL)
+	return &Order{
+		ID:            "", // ID should be generated by the database/service
+		UserID:        userID,
+		Status:        OrderStatusPending,
+		Currency:      currency,
+		ReservedUntil: &reservedUntil,
+		Items:         []OrderItem{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
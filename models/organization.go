@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// OrganizationAdminRole is the role, in an organization, of the members who manage it: they
+// invite users, assign roles and remove members.
+const OrganizationAdminRole = "admin"
+
+// Organization is a customer account of a tenant (e.g., a company), whose members each have a
+// role in it. A user may belong to several organizations.
+type Organization struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`           // URL-friendly identifier, unique within the tenant
+	Role      string    `json:"role,omitempty"` // Role of the requesting user in the organization, if a member
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMember is a user's membership of an organization.
+type OrganizationMember struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	RoleID   string    `json:"role_id"`
+	RoleName string    `json:"role_name"` // Role in the organization
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// OrganizationInvitation is a pending invitation of an email address to an organization.
+type OrganizationInvitation struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Email          string    `json:"email"`
+	RoleID         string    `json:"role_id"`
+	RoleName       string    `json:"role_name"`       // Role the invitee gets in the organization
+	InvitedBy      *string   `json:"invited_by"`      // ID of the inviting user; nil once deleted
+	Token          string    `json:"token,omitempty"` // Token of the acceptance link; only returned when the invitation is created
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
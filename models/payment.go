@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Payment statuses. They match the normalized statuses of the payments package.
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+)
+
+// Payment records a single checkout attempt for an order at a payment provider.
+type Payment struct {
+	ID          string          `json:"id"`
+	OrderID     string          `json:"order_id"`
+	Provider    string          `json:"provider"`     // Payment provider name (e.g., "stripe", "midtrans")
+	ProviderRef string          `json:"provider_ref"` // Provider-side reference used to match webhook events
+	Status      string          `json:"status"`       // One of the PaymentStatus* constants
+	Amount      decimal.Decimal `json:"amount"`
+	Currency    string          `json:"currency"`
+	CheckoutURL string          `json:"checkout_url"` // Hosted payment page the customer is redirected to
+	RefundDue   bool            `json:"refund_due"`   // Succeeded for an order another payment had already paid, so the money has to be refunded
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
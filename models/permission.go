@@ -0,0 +1,67 @@
+package models
+
+// Permissions roles can be granted, each opening a group of admin endpoints. The admin role
+// (AdminRole) has every permission, which can't be revoked.
+const (
+	PermissionUsersManage    = "users.manage"    // Users, their tags and notes
+	PermissionRolesManage    = "roles.manage"    // Roles and their permissions; holders can grant themselves any permission
+	PermissionReportsReview  = "reports.review"  // Review queue of reported posts and comments
+	PermissionCatalogManage  = "catalog.manage"  // Tags, categories and products
+	PermissionTrashManage    = "trash.manage"    // Restoring and purging trashed records
+	PermissionLogsView       = "logs.view"       // Login history, login analytics, audit log and failed logins
+	PermissionWebhooksManage = "webhooks.manage" // Webhooks and their deliveries
+	PermissionSystemManage   = "system.manage"   // Runtime settings, scheduled tasks, maintenance mode, broadcasts and quotas
+)
+
+// AdminRole is the name of the role having every permission.
+const AdminRole = "admin"
+
+// Permission describes a permission roles can be granted.
+type Permission struct {
+	Name        string `json:"name"` // One of the Permission* constants
+	Description string `json:"description"`
+}
+
+// Permissions are the permissions roles can be granted, in the order of the columns of the
+// permission matrix.
+var Permissions = []Permission{
+	{PermissionUsersManage, "Manage users, their tags and notes"},
+	{PermissionRolesManage, "Manage roles and their permissions"},
+	{PermissionReportsReview, "Review reported posts and comments"},
+	{PermissionCatalogManage, "Manage tags, categories and products"},
+	{PermissionTrashManage, "Restore and purge trashed records"},
+	{PermissionLogsView, "View the login history, login analytics, audit log and failed logins"},
+	{PermissionWebhooksManage, "Manage webhooks and inspect their deliveries"},
+	{PermissionSystemManage, "Manage runtime settings, scheduled tasks, maintenance mode, broadcasts and quotas"},
+}
+
+// IsPermission reports whether name is one of the Permissions.
+func IsPermission(name string) bool {
+	for _, permission := range Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionMatrix is the grid of the permissions granted to every role.
+type PermissionMatrix struct {
+	Permissions []Permission      `json:"permissions"` // Columns, see Permissions
+	Roles       []RolePermissions `json:"roles"`       // Rows, by role name
+}
+
+// RolePermissions is a row of the permission matrix.
+type RolePermissions struct {
+	RoleID   string          `json:"role_id"`
+	RoleName string          `json:"role_name"`
+	Grants   map[string]bool `json:"grants"` // By permission name, for every permission
+	Locked   bool            `json:"locked"` // The admin role, which has every permission
+}
+
+// PermissionChange grants a permission to a role, or revokes it.
+type PermissionChange struct {
+	RoleID     string `json:"role_id" validate:"required,uuid"`
+	Permission string `json:"permission" validate:"required,max=50"`
+	Granted    bool   `json:"granted"`
+}
@@ -2,39 +2,107 @@ package models
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // Product represents a product record in the database.
 // This struct will be used for storing and retrieving product data.
 type Product struct {
-	ID          string    `json:"id"`          // Unique identifier for the product
-	Name        string    `json:"name"`        // Name of the product
-	Description string    `json:"description"` // Description of the product
-	Price       float64   `json:"price"`       // Price of the product
-	Stock       int       `json:"stock"`       // Current stock quantity
-	CreatedAt   time.Time `json:"created_at"`  // Timestamp when the product was created
-	UpdatedAt   time.Time `json:"updated_at"`  // Timestamp when the product record was last updated
-	// Add other product-related fields as needed (e.g., Category, ImageURL, etc.)
+	ID          string          `json:"id"`                    // Unique identifier for the product
+	Name        string          `json:"name"`                  // Name of the product
+	Description string          `json:"description"`           // Description of the product
+	Price       decimal.Decimal `json:"price"`                 // Price of the product, exact to MoneyScale decimal places
+	Stock       int             `json:"stock"`                 // Current stock quantity
+	Category    string          `json:"category"`              // Free-form product category (e.g., "electronics")
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"` // Set when the product is archived; archived products can't be ordered
+	ImageKey    *string         `json:"-"`                     // Key of the image in the storage backend, never exposed; nil without an image
+	ImageURL    string          `json:"image_url,omitempty"`   // Presigned download URL of the image, resolved from ImageKey
+	Version     int             `json:"version"`               // Incremented by every update; used for optimistic locking
+	CreatedAt   time.Time       `json:"created_at"`            // Timestamp when the product was created
+	UpdatedAt   time.Time       `json:"updated_at"`            // Timestamp when the product record was last updated
+}
+
+// Stock status filter values for product listings.
+const (
+	StockStatusInStock    = "in_stock"     // stock > 0
+	StockStatusOutOfStock = "out_of_stock" // stock = 0
+	StockStatusLowStock   = "low_stock"    // 0 < stock <= LowStockThreshold
+)
+
+// Archive filter values for product listings.
+const (
+	ArchivedExclude = "false" // Only active products (default)
+	ArchivedOnly    = "true"  // Only archived products
+	ArchivedInclude = "all"   // Active and archived products
+)
+
+// LowStockThreshold is the stock level at or below which a product counts as running low.
+const LowStockThreshold = 5
+
+// ProductFilter holds the optional filters and sort order for listing products.
+type ProductFilter struct {
+	Search      string           // Matches name or description (case-insensitive)
+	Category    string           // Exact category match
+	MinPrice    *decimal.Decimal // Inclusive lower price bound
+	MaxPrice    *decimal.Decimal // Inclusive upper price bound
+	StockStatus string           // One of the StockStatus* constants
+	Archived    string           // One of the Archived* constants; archived products are excluded by default
+	Sort        []string         // Sort fields in priority order; a "-" prefix sorts descending (e.g., "-price")
+	Fields      Fields           // Fields to return; the descriptions aren't read unless selected
 }
 
 // ProductCreateRequest represents the expected payload for creating a new product.
 // This would be used in a product creation controller.
 type ProductCreateRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
+	Name        string          `json:"name" validate:"required,max=255"`
+	Description string          `json:"description"`
+	Price       decimal.Decimal `json:"price" validate:"price"`
+	Stock       int             `json:"stock" validate:"gte=0"`
+	Category    string          `json:"category" validate:"max=100"`
 }
 
 // ProductResponse represents the structure of a product object returned in API responses.
 // It typically mirrors the Product struct but can be customized if certain fields
 // should be omitted or transformed for public consumption.
 type ProductResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       decimal.Decimal `json:"price"`
+	Stock       int             `json:"stock"`
+	Category    string          `json:"category"`
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"`
+	ImageURL    string          `json:"image_url,omitempty"`
+	Version     int             `json:"version"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// StockMovement records a single change to a product's stock level and who made it.
+type StockMovement struct {
+	ID         string    `json:"id"`
+	ProductID  string    `json:"product_id"`
+	Delta      int       `json:"delta"`       // Positive for restocks, negative for removals
+	Reason     string    `json:"reason"`      // Why the stock changed (e.g., "restock", "damaged", "correction")
+	ActorID    string    `json:"actor_id"`    // User who made the adjustment
+	StockAfter int       `json:"stock_after"` // Product stock right after this movement
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewProduct creates a new Product instance with default creation/update timestamps.
+// The ID should be generated by the database/service.
+func NewProduct(name, description, category string, price decimal.Decimal, stock int) *Product {
+	now := time.Now()
+	return &Product{
+		ID:          "", // ID should be generated by the database/service
+		Name:        name,
+		Description: description,
+		Price:       price,
+		Stock:       stock,
+		Category:    category,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// Report target types.
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+)
+
+// Report statuses. Reports start open and are closed by an admin as resolved (action taken) or dismissed.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Report represents a user's flag on a post or comment, waiting for admin review.
+type Report struct {
+	ID             string     `json:"id"`
+	TargetType     string     `json:"target_type"` // "post" or "comment"
+	TargetID       string     `json:"target_id"`   // ID of the reported post or comment
+	ReporterID     string     `json:"reporter_id"` // User who filed the report
+	Reason         string     `json:"reason"`      // Short reason code (e.g., "spam", "abuse")
+	Details        string     `json:"details"`     // Optional free-form explanation
+	Status         string     `json:"status"`
+	ResolvedBy     *string    `json:"resolved_by"`     // Admin who closed the report
+	ResolutionNote string     `json:"resolution_note"` // Admin's note when closing the report
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewReport creates a new open Report instance with default creation/update timestamps.
+func NewReport(targetType, targetID, reporterID, reason, details string) *Report {
+	now := time.Now()
+	return &Report{
+		ID:         "", // ID should be generated by the database/service
+		TargetType: targetType,
+		TargetID:   targetID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+		Status:     ReportStatusOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
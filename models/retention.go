@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Modes of the log retention policy (LOG_RETENTION_MODE), i.e., what happens to the log
+// entries older than the retention period.
+const (
+	RetentionDelete    = "delete"    // Entries are deleted
+	RetentionAnonymize = "anonymize" // Audit log entries are kept without who made the changes and from where; other entries are deleted
+)
+
+// RetentionCount is how many entries of a table the log retention policy deleted or anonymized,
+// or would.
+type RetentionCount struct {
+	Table  string `json:"table"`
+	Action string `json:"action"` // "delete" or "anonymize"
+	Rows   int64  `json:"rows"`
+}
+
+// RetentionPreview describes what a run of the log-retention task would do now.
+type RetentionPreview struct {
+	Mode   string           `json:"mode"`   // "delete" or "anonymize"
+	Before time.Time        `json:"before"` // Entries recorded before this time are purged
+	Tables []RetentionCount `json:"tables"`
+}
@@ -9,6 +9,7 @@ type Role struct {
 	ID          string    `json:"id"`          // Unique identifier for the role (UUID)
 	Name        string    `json:"name"`        // Name of the role (e.g., "admin", "user")
 	Description string    `json:"description"` // Description of the role
+	Version     int       `json:"version"`     // Incremented by every update; used for optimistic locking
 	CreatedAt   time.Time `json:"created_at"`  // Timestamp when the role was created
 	UpdatedAt   time.Time `json:"updated_at"`  // Timestamp when the role was last updated
 }
@@ -26,7 +27,45 @@ func NewRole(name, description string) *Role {
 		ID:          "", // ID should be generated by the database/service
 		Name:        name,
 		Description: description,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
+
+// Ways of resolving conflicts between imported roles and existing roles of the same name.
+const (
+	RoleConflictSkip      = "skip"      // The existing role is kept as is
+	RoleConflictOverwrite = "overwrite" // The existing role takes the description and permissions of the imported one
+	RoleConflictRename    = "rename"    // The imported role is created under a free name, e.g., "support-2"
+)
+
+// What the role import did with an imported role.
+const (
+	RoleImportCreated     = "created"
+	RoleImportSkipped     = "skipped"
+	RoleImportOverwritten = "overwritten"
+	RoleImportRenamed     = "renamed"
+)
+
+// RoleDefinition is a role with its permissions, as moved between environments by the role
+// export and import.
+type RoleDefinition struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=1000"`
+	Permissions []string `json:"permissions" validate:"max=50,unique,dive,required,max=50"` // Ignored for the admin role, which has every permission
+}
+
+// RoleExport is the document of the role export, which the role import accepts as is.
+type RoleExport struct {
+	ExportedAt time.Time        `json:"exported_at"`
+	Roles      []RoleDefinition `json:"roles"`
+}
+
+// RoleImportResult is what the role import did with an imported role.
+type RoleImportResult struct {
+	Name    string `json:"name"`     // Name of the imported role
+	RoleID  string `json:"role_id"`  // Role created or overwritten, or the existing role that was skipped
+	SavedAs string `json:"saved_as"` // Name of RoleID; differs from Name when renamed
+	Action  string `json:"action"`   // One of the RoleImport* constants
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Names of the settings stored in the settings table.
+const (
+	SettingMaintenanceMode = "maintenance_mode"
+)
+
+// DefaultMaintenanceRetryAfter is the Retry-After sent during maintenance without a planned end.
+const DefaultMaintenanceRetryAfter = 5 * time.Minute
+
+// MaintenanceMode is the state of maintenance mode, during which the API answers everyone but
+// admins with 503 Service Unavailable, e.g., while a deployment migrates the database.
+type MaintenanceMode struct {
+	Enabled   bool       `json:"enabled"`
+	Message   string     `json:"message,omitempty"` // Shown to rejected clients; a generic message if empty
+	EndsAt    *time.Time `json:"ends_at,omitempty"` // Planned end, announced in Retry-After; nil if unknown
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// RetryAfter returns how long rejected clients should wait before retrying, at now.
+func (m *MaintenanceMode) RetryAfter(now time.Time) time.Duration {
+	if m.EndsAt == nil || !m.EndsAt.After(now) {
+		return DefaultMaintenanceRetryAfter
+	}
+	return m.EndsAt.Sub(now)
+}
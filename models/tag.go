@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag represents a free-form label that can be attached to many posts.
+type Tag struct {
+	ID        string    `json:"id"`         // Unique identifier for the tag (UUID)
+	Name      string    `json:"name"`       // Display name of the tag (e.g., "Go")
+	Slug      string    `json:"slug"`       // URL-friendly identifier used for filtering (e.g., "go")
+	CreatedAt time.Time `json:"created_at"` // Timestamp when the tag was created
+	UpdatedAt time.Time `json:"updated_at"` // Timestamp when the tag was last updated
+}
+
+// TagCloudItem is a tag together with the number of posts it is attached to.
+type TagCloudItem struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int    `json:"post_count"`
+}
+
+// Category represents a single category a post can belong to.
+type Category struct {
+	ID          string    `json:"id"`          // Unique identifier for the category (UUID)
+	Name        string    `json:"name"`        // Display name of the category (e.g., "News")
+	Slug        string    `json:"slug"`        // URL-friendly identifier used for filtering (e.g., "news")
+	Description string    `json:"description"` // Description of the category
+	CreatedAt   time.Time `json:"created_at"`  // Timestamp when the category was created
+	UpdatedAt   time.Time `json:"updated_at"`  // Timestamp when the category was last updated
+}
+
+// NewTag creates a new Tag instance with default creation/update timestamps.
+// The ID should be generated by the database/service.
+func NewTag(name, slug string) *Tag {
+	now := time.Now()
+	return &Tag{
+		ID:        "", // ID should be generated by the database/service
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewCategory creates a new Category instance with default creation/update timestamps.
+// The ID should be generated by the database/service.
+func NewCategory(name, slug, description string) *Category {
+	now := time.Now()
+	return &Category{
+		ID:          "", // ID should be generated by the database/service
+		Name:        name,
+		Slug:        slug,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
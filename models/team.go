@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Roles of team members.
+const (
+	TeamRoleOwner  = "owner"  // Manages the team and its members
+	TeamRoleMember = "member" // Sees the team and the resources shared within it
+)
+
+// Team is a group of users of a tenant that resources can be shared within.
+type Team struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Role        string    `json:"role,omitempty"` // Role of the requesting user, when listing their teams
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TeamMember is a user's membership of a team.
+type TeamMember struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"` // TeamRoleOwner or TeamRoleMember
+	JoinedAt time.Time `json:"joined_at"`
+}
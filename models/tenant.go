@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Tenant is an isolated customer of the application. Users, posts, products, orders and
+// reports belong to exactly one tenant and are never visible to the others.
+type Tenant struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"` // Subdomain the tenant is served under
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Resources whose deleted records are kept in the trash until they are purged.
+const (
+	TrashResourceUsers    = "users"
+	TrashResourceRoles    = "roles"
+	TrashResourcePosts    = "posts"
+	TrashResourceProducts = "products"
+)
+
+// TrashedRecord is a soft deleted record as listed in the trash.
+type TrashedRecord struct {
+	ID        string    `json:"id"`         // ID of the deleted record
+	Type      string    `json:"type"`       // Resource the record belongs to (e.g., "posts")
+	Label     string    `json:"label"`      // Human readable name of the record (user email, role name, post title, product name)
+	DeletedAt time.Time `json:"deleted_at"` // Timestamp when the record was moved to the trash
+}
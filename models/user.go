@@ -1,32 +1,79 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // User represents a user in the system.
 type User struct {
 	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"` // Tenant the user belongs to
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
-	Password  string    `json:"-"`              // Password should not be marshaled to JSON
-	RoleID    string    `json:"role_id"`        // Foreign key to the roles table
-	Role      *Role     `json:"role,omitempty"` // Embedded Role struct for eager loading, omitempty to exclude if nil
-	RoleName  string    `json:"-"`              // This field is not directly mapped to DB column, but can be populated
+	Phone     *string   `json:"phone"`                // E.164 phone number SMS login codes are sent to; nil without one
+	Password  string    `json:"-"`                    // Password should not be marshaled to JSON
+	RoleID    string    `json:"role_id"`              // Foreign key to the roles table
+	Role      *Role     `json:"role,omitempty"`       // Embedded Role struct for eager loading, omitempty to exclude if nil
+	RoleName  string    `json:"-"`                    // This field is not directly mapped to DB column, but can be populated
+	AvatarKey *string   `json:"-"`                    // Key of the avatar in the storage backend, never exposed; nil without an avatar
+	AvatarURL string    `json:"avatar_url,omitempty"` // Presigned download URL of the avatar, resolved from AvatarKey
+	Version   int       `json:"version"`              // Incremented by every update; used for optimistic locking
+	Tags      []string  `json:"tags,omitempty"`       // Labels attached by admins, via the user_tags table; only loaded by the admin lookups
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	TokenVersion int `json:"-"` // Incremented when the role or password changes; tokens issued with an older version are rejected
 }
 
 type UserResponse struct {
 	ID        string    `json:"id"`
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
+	Phone     *string   `json:"phone"`
+	RoleID    string    `json:"role_id"`
+	RoleName  string    `json:"role_name"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	Version   int       `json:"version"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UserExport is a user in an export of the users, see GET /api/users/export.
+type UserExport struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Phone     *string   `json:"phone"`
 	RoleID    string    `json:"role_id"`
 	RoleName  string    `json:"role_name"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// UserProfile is the account of the authenticated user, as shown to them.
+type UserProfile struct {
+	ID          string          `json:"id"`
+	Username    string          `json:"username"`
+	Email       string          `json:"email"`
+	Phone       *string         `json:"phone"`
+	RoleID      string          `json:"role_id"`
+	RoleName    string          `json:"role_name"`
+	AvatarKey   *string         `json:"-"`
+	AvatarURL   string          `json:"avatar_url,omitempty"` // Presigned download URL of the avatar, resolved from AvatarKey
+	LastLoginAt *time.Time      `json:"last_login_at"`        // Latest login, which may be the one of the current session; nil if the user never logged in
+	Preferences json.RawMessage `json:"preferences"`          // JSON object saved by the frontend; {} until the user saves some
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// UserTagCount is a tag attached to users together with the number of users it is attached to.
+type UserTagCount struct {
+	Tag       string `json:"tag"`
+	UserCount int    `json:"user_count"`
+}
+
 type LstRoleResponse struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -34,12 +81,30 @@ type LstRoleResponse struct {
 
 // Post represents a blog post or an article.
 type Post struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Title        string     `json:"title"`
+	Content      string     `json:"content"`                 // Markdown source
+	ContentHTML  string     `json:"content_html,omitempty"`  // Sanitized HTML, only populated when requested with ?render=html
+	CategoryID   *string    `json:"category_id"`             // Nullable foreign key to the categories table
+	CategoryName string     `json:"category_name,omitempty"` // Populated from the categories join, not a DB column
+	Tags         []string   `json:"tags"`                    // Tag names attached via the post_tags table
+	PublishedAt  *time.Time `json:"published_at"`            // Nil for drafts; a future time schedules the post
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// FeedItem is a published post as shown in the public feed.
+type FeedItem struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`                // Markdown source
+	ContentHTML    string    `json:"content_html,omitempty"` // Sanitized HTML, only populated when requested with ?render=html
+	AuthorUsername string    `json:"author_username"`
+	CategoryName   string    `json:"category_name,omitempty"`
+	Tags           []string  `json:"tags"`
+	CommentCount   int       `json:"comment_count"`
+	PublishedAt    time.Time `json:"published_at"`
 }
 
 // Comment represents a comment on a post.
@@ -52,21 +117,39 @@ type Comment struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// Session represents a user session.
+// Session represents a user session, created on login. A token is only accepted while its
+// session exists and hasn't expired, so deleting the session logs the token out.
 type Session struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
-	Token     string    `json:"token"`
+	Token     string    `json:"-"`          // Only its SHA-256 hash is stored
+	Device    string    `json:"device"`     // User-Agent of the client that logged in
+	IPAddress string    `json:"ip_address"` // Client IP of the login
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
+
+	UserTokenVersion int `json:"-"` // Current User.TokenVersion of the session's user
+}
+
+// KnownDevice is a device and country a user logged in from. Logins from others are alerted to the user.
+type KnownDevice struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Fingerprint string    `json:"-"`       // SHA-256 hash of the User-Agent
+	Device      string    `json:"device"`  // User-Agent
+	Country     string    `json:"country"` // ISO 3166-1 alpha-2 code; empty if unknown
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
 }
 
 type UpdateUserRequest struct {
 	ID       string  `json:"id"` // User ID is required for the update operation
-	Username string  `json:"username"`
-	Email    string  `json:"email"`
-	Password *string `json:"password,omitempty"` // Use pointer to make it optional.
-	RoleID   string  `json:"role_id"`
+	Username string  `json:"username" validate:"required,max=100"`
+	Email    string  `json:"email" validate:"required,email,max=255"`
+	Password *string `json:"password,omitempty" validate:"omitempty,min=8,max=72"` // Use pointer to make it optional.
+	Phone    *string `json:"phone,omitempty" validate:"omitempty,e164"`            // Left unchanged when omitted; an empty string removes it
+	RoleID   string  `json:"role_id" validate:"required,uuid"`
+	Version  *int    `json:"version,omitempty"` // Version the client last read; the update fails with a conflict when it is stale
 }
 
 // NewUser creates a new User instance with default creation/update timestamps.
@@ -79,6 +162,7 @@ func NewUser(username, email, password, roleID string) *User {
 		Email:     email,
 		Password:  password,
 		RoleID:    roleID,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -92,6 +176,7 @@ func NewPost(userID, title, content string) *Post {
 		UserID:    userID,
 		Title:     title,
 		Content:   content,
+		Tags:      []string{},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -4,8 +4,17 @@ import "time"
 
 // UserLog represents a single login/logout session record in the database.
 type UserLog struct {
-	ID       int        `json:"id"`        // Auto-incrementing primary key
-	UserID   string     `json:"user_id"`   // Foreign key to the users table
-	LoginAt  time.Time  `json:"login_at"`  // Timestamp of login
-	LogoutAt *time.Time `json:"logout_at"` // Nullable timestamp of logout
+	ID              int        `json:"id"`                 // Auto-incrementing primary key
+	UserID          string     `json:"user_id"`            // Foreign key to the users table
+	Username        string     `json:"username,omitempty"` // Username of the user, in the admin listing
+	LoginAt         time.Time  `json:"login_at"`           // Timestamp of login
+	LogoutAt        *time.Time `json:"logout_at"`          // Nullable timestamp of logout
+	DurationSeconds *int64     `json:"duration_seconds"`   // Time between login and logout; nil until the user logs out
+}
+
+// UserLogFilter narrows down the login history; empty fields don't filter.
+type UserLogFilter struct {
+	UserID string
+	From   *time.Time // Logins at or after this time
+	To     *time.Time // Logins before this time
 }
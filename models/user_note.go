@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// UserNote is an internal note of the support staff on a user account, only visible to admins.
+type UserNote struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`     // Account the note is about
+	AuthorID   *string   `json:"author_id"`   // nil once the author's account is deleted
+	AuthorName string    `json:"author_name"` // Username of the author
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"   // Queued, or waiting for a retry
+	WebhookDeliverySucceeded = "succeeded" // The endpoint answered with a 2xx status
+	WebhookDeliveryFailed    = "failed"    // All attempts failed
+)
+
+// Webhook is an endpoint registered by an admin to be notified of events of their tenant.
+type Webhook struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // Signing secret; only returned when the webhook is created
+	Events    []string  `json:"events"`           // Event types the endpoint subscribes to, see webhooks.Events
+	Active    bool      `json:"active"`           // Inactive endpoints get no new deliveries
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is an event queued for, or delivered to, an endpoint.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // One of the WebhookDelivery* constants
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"` // When a pending delivery is attempted next
+	ResponseStatus *int            `json:"response_status"` // HTTP status of the last attempt; nil if it got no response
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
@@ -0,0 +1,37 @@
+// Package notifications delivers the notifications of users through channels other than the
+// app itself, such as email. Notifications are stored for the app by the NotificationService
+// of the services package, which hands them to every configured channel.
+package notifications
+
+import (
+	"context"
+
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// Recipient is the user a notification is delivered to.
+type Recipient struct {
+	UserID   string
+	Username string
+	Email    string
+}
+
+// Channel is implemented by every way of delivering notifications.
+type Channel interface {
+	// Name returns the identifier of the channel (e.g., "email"), used in logs.
+	Name() string
+	// Send delivers notification to recipient.
+	Send(ctx context.Context, recipient Recipient, notification *models.Notification) error
+}
+
+// Channels returns the configured channels among channels. Nil channels (not configured)
+// are skipped.
+func Channels(channels ...Channel) []Channel {
+	configured := []Channel{}
+	for _, channel := range channels {
+		if channel != nil {
+			configured = append(configured, channel)
+		}
+	}
+	return configured
+}
@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// smtpTimeout bounds a whole SMTP conversation when the context has no earlier deadline.
+const smtpTimeout = 30 * time.Second
+
+// EmailChannel sends notifications as plain text emails through an SMTP server. The
+// connection is upgraded with STARTTLS when the server offers it.
+type EmailChannel struct {
+	Host     string // SMTP server host
+	Port     string // SMTP server port (e.g., "587")
+	Username string // SMTP credentials; no authentication when empty
+	Password string
+	From     string // Sender address (e.g., "anpbayu <no-reply@example.com>")
+}
+
+// NewEmailChannel creates an EmailChannel, or returns nil when no SMTP host is configured.
+func NewEmailChannel(host, port, username, password, from string) Channel {
+	if host == "" {
+		return nil
+	}
+	return &EmailChannel{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+// Name returns "email".
+func (c *EmailChannel) Name() string { return "email" }
+
+// Send emails notification to the address of recipient; recipients without one are skipped.
+func (c *EmailChannel) Send(ctx context.Context, recipient Recipient, notification *models.Notification) error {
+	if recipient.Email == "" {
+		return nil
+	}
+	from, err := mail.ParseAddress(c.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", c.From, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.Host, c.Port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with SMTP server: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(recipient.Email); err != nil {
+		return fmt.Errorf("SMTP server rejected recipient: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start email data: %w", err)
+	}
+	if _, err := w.Write(c.message(from, recipient, notification)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected email: %w", err)
+	}
+	return client.Quit()
+}
+
+// message builds the RFC 5322 message of notification.
+func (c *EmailChannel) message(from *mail.Address, recipient Recipient, notification *models.Notification) []byte {
+	to := mail.Address{Name: recipient.Username, Address: recipient.Email}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(notification.Body) // The SMTP data writer turns bare \n line endings into \r\n
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/realtime"
+)
+
+// RealtimeChannel pushes notifications to the connected clients of their recipient, so the
+// app shows them without polling.
+type RealtimeChannel struct {
+	Hub *realtime.Hub
+}
+
+// NewRealtimeChannel creates a RealtimeChannel pushing notifications through hub.
+func NewRealtimeChannel(hub *realtime.Hub) Channel {
+	return &RealtimeChannel{Hub: hub}
+}
+
+// Name returns "realtime".
+func (c *RealtimeChannel) Name() string { return "realtime" }
+
+// Send pushes notification to the clients of recipient connected to this instance; recipients
+// without connected clients see it the next time they list their notifications.
+func (c *RealtimeChannel) Send(ctx context.Context, recipient Recipient, notification *models.Notification) error {
+	c.Hub.SendToUser(recipient.UserID, realtime.Event{ID: notification.ID, Type: realtime.EventNotification, Data: notification})
+	return nil
+}
@@ -0,0 +1,354 @@
+// Package openapi builds the OpenAPI 3 description of the API at runtime, from the routes
+// registered with Fiber and the request and response types documented for them, so the
+// contract can't drift from the routes that are actually served.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// mimeXLSX is the media type of the Excel spreadsheets of Operation.Spreadsheet.
+const mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Info describes the API as a whole.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Parameter is a path, query or header parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path", "query" or "header"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Query returns an optional query parameter of type typ ("string", "integer" or "boolean").
+func Query(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+// Operation documents a route. The request and response types are given as values, e.g.,
+// Body: controllers.PostRequest{}, and described by reflection on their json and validate tags.
+type Operation struct {
+	Summary     string
+	Description string
+	Tag         string      // Group of the operation; derived from the path if empty
+	Roles       []string    // Roles allowed to call the operation; any authenticated user if empty
+	Permission  string      // Permission allowing the roles it is granted to to call the operation as well, see models.Permissions
+	Platform    bool        // Only the users of the platform tenant may call the operation, see middleware.PlatformOnly
+	Query       []Parameter // Query parameters; path parameters are added from the route
+	Body        interface{} // Value of the JSON request body type
+	Upload      string      // Name of the multipart form field of an uploaded file
+	Idempotent  bool        // Accepts an Idempotency-Key header, see middleware.Idempotency
+	Status      int         // Status of a successful response; 200 if zero
+	Data        interface{} // Value of the type of the "data" field of a successful response
+	Paginated   bool        // The successful response carries currentPage, totalPages and totalItems
+	Keyset      bool        // Also paginated by keyset: takes a cursor parameter and the response carries nextCursor
+	Spreadsheet bool        // Also answers every matching record as a CSV or XLSX file, asked for with the format parameter or the Accept header
+	Response    interface{} // Value of the type of the whole successful response, for routes without the usual envelope
+	ContentType string      // Content type of a successful response that isn't JSON, e.g., "application/rss+xml"
+}
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat"`
+}
+
+type operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+// ErrorResponse is the body of error responses.
+type ErrorResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is the body of 422 responses to invalid request bodies and query
+// parameters.
+type ValidationErrorResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+// FieldError describes why a field of a request body or a query parameter was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// builder collects the component schemas while a document is built.
+type builder struct {
+	components     map[string]*Schema
+	componentTypes map[string]reflect.Type
+}
+
+// Build describes routes (see fiber.App.GetRoutes), using the operations documented in docs,
+// keyed by method and path as registered (e.g., "GET /api/posts/:id"). Routes under a path for
+// which secured returns true require a bearer token. Wildcard routes, such as static files,
+// are left out.
+func Build(info Info, routes []fiber.Route, docs map[string]Operation, secured func(path string) bool) *Document {
+	b := &builder{components: map[string]*Schema{}, componentTypes: map[string]reflect.Type{}}
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]map[string]*operation{},
+		Components: components{
+			Schemas: b.components,
+			SecuritySchemes: map[string]securityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead || strings.Contains(route.Path, "*") {
+			continue
+		}
+		path := normalizePath(route.Path)
+		op := docs[route.Method+" "+path]
+		openAPIPath := toOpenAPIPath(path)
+		if doc.Paths[openAPIPath] == nil {
+			doc.Paths[openAPIPath] = map[string]*operation{}
+		}
+		doc.Paths[openAPIPath][strings.ToLower(route.Method)] = b.operation(route.Method, path, route.Params, op, secured(path))
+	}
+	return doc
+}
+
+// operation describes one route.
+func (b *builder) operation(method, path string, params []string, op Operation, secured bool) *operation {
+	tag := op.Tag
+	if tag == "" {
+		tag = pathTag(path)
+	}
+	o := &operation{
+		Tags:        []string{tag},
+		Summary:     op.Summary,
+		Description: op.Description,
+		Responses:   map[string]response{},
+	}
+	if len(op.Roles) > 0 && op.Permission != "" {
+		o.Description = strings.TrimSpace(o.Description + "\n\nRequires one of the roles: " + strings.Join(op.Roles, ", ") + ", or the permission " + op.Permission + ".")
+	} else if len(op.Roles) > 0 {
+		o.Description = strings.TrimSpace(o.Description + "\n\nRequires one of the roles: " + strings.Join(op.Roles, ", ") + ".")
+	}
+	if op.Platform {
+		o.Description = strings.TrimSpace(o.Description + "\n\nOnly the users of the platform tenant may call it, as what it changes is shared by every tenant.")
+	}
+
+	uuidParams := false
+	for _, param := range params {
+		schema := &Schema{Type: "string"}
+		if isIDParam(param) {
+			schema.Format, uuidParams = "uuid", true
+		}
+		o.Parameters = append(o.Parameters, Parameter{Name: param, In: "path", Required: true, Schema: schema})
+	}
+	o.Parameters = append(o.Parameters, op.Query...)
+	if op.Keyset {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        "cursor",
+			In:          "query",
+			Description: "Pages by keyset instead of by page number: the nextCursor of the previous page, or empty for the first page. The response then carries nextCursor instead of the page counts",
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	if op.Spreadsheet {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        "format",
+			In:          "query",
+			Description: "csv or xlsx for a spreadsheet of every matching record, regardless of the pagination parameters, instead of a page of JSON (json). Without it, the format is negotiated with the Accept header: text/csv, " + mimeXLSX + " or application/json",
+			Schema:      &Schema{Type: "string", Enum: []string{"json", "csv", "xlsx"}},
+		})
+	}
+	if op.Idempotent {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        "Idempotency-Key",
+			In:          "header",
+			Description: "Unique key of the request; retries with the same key get the response of the first request",
+			Schema:      &Schema{Type: "string", MaxLength: intPtr(255)},
+		})
+		o.Responses["409"] = b.errorResponse("A request with the same Idempotency-Key is still being processed", ErrorResponse{})
+	}
+
+	switch {
+	case op.Body != nil:
+		o.RequestBody = &requestBody{Required: true, Content: map[string]mediaType{
+			fiber.MIMEApplicationJSON: {Schema: b.schemaOf(reflect.TypeOf(op.Body))},
+		}}
+		o.Responses["400"] = b.errorResponse("Malformed request body", ErrorResponse{})
+		o.Responses["422"] = b.errorResponse("Invalid fields in the request body", ValidationErrorResponse{})
+	case op.Upload != "":
+		form := &Schema{Type: "object", Required: []string{op.Upload}, Properties: map[string]*Schema{
+			op.Upload: {Type: "string", Format: "binary"},
+		}}
+		o.RequestBody = &requestBody{Required: true, Content: map[string]mediaType{
+			fiber.MIMEMultipartForm: {Schema: form},
+		}}
+		o.Responses["400"] = b.errorResponse("Missing or invalid file", ErrorResponse{})
+		o.Responses["413"] = b.errorResponse("File too large", ErrorResponse{})
+		o.Responses["415"] = b.errorResponse("File type not allowed, blocked extension or content not matching the declared type", ErrorResponse{})
+	}
+	if len(op.Query) > 0 || op.Keyset || op.Spreadsheet {
+		description := "Invalid query parameters"
+		if _, ok := o.Responses["422"]; ok {
+			description = "Invalid fields in the request body or invalid query parameters"
+		}
+		o.Responses["422"] = b.errorResponse(description, ValidationErrorResponse{})
+	}
+	if uuidParams {
+		if bad, ok := o.Responses["400"]; ok {
+			bad.Description += ", or malformed ID in the path"
+			o.Responses["400"] = bad
+		} else {
+			o.Responses["400"] = b.errorResponse("Malformed ID in the path", ErrorResponse{})
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	o.Responses[strconv.Itoa(status)] = b.successResponse(op)
+	if method == fiber.MethodGet {
+		// See middleware.ETag
+		o.Responses["304"] = response{Description: "Not modified since the ETag sent in If-None-Match"}
+	}
+
+	if secured {
+		o.Security = []map[string][]string{{"bearerAuth": {}}}
+		o.Responses["401"] = b.errorResponse("Missing, invalid or expired token", ErrorResponse{})
+	}
+	if len(op.Roles) > 0 {
+		o.Responses["403"] = b.errorResponse("The user lacks the required role or permission", ErrorResponse{})
+	}
+	if len(params) > 0 {
+		o.Responses["404"] = b.errorResponse("Not found", ErrorResponse{})
+	}
+	o.Responses["500"] = b.errorResponse("Internal server error", ErrorResponse{})
+	return o
+}
+
+// successResponse describes the successful response of op: by default the envelope
+// {success, message, data}, with pagination fields for paginated lists.
+func (b *builder) successResponse(op Operation) response {
+	if op.ContentType != "" {
+		return response{Description: "Success", Content: map[string]mediaType{
+			op.ContentType: {Schema: &Schema{Type: "string"}},
+		}}
+	}
+	if op.Response != nil {
+		return response{Description: "Success", Content: map[string]mediaType{
+			fiber.MIMEApplicationJSON: {Schema: b.schemaOf(reflect.TypeOf(op.Response))},
+		}}
+	}
+
+	envelope := &Schema{Type: "object", Required: []string{"success", "message"}, Properties: map[string]*Schema{
+		"success": {Type: "boolean"},
+		"message": {Type: "string"},
+	}}
+	if op.Data != nil {
+		envelope.Properties["data"] = b.schemaOf(reflect.TypeOf(op.Data))
+	}
+	if op.Paginated {
+		for _, field := range []string{"currentPage", "totalPages", "totalItems"} {
+			envelope.Properties[field] = &Schema{Type: "integer"}
+		}
+	}
+	if op.Keyset {
+		envelope.Properties["nextCursor"] = &Schema{Type: "string", Nullable: true}
+	}
+	content := map[string]mediaType{fiber.MIMEApplicationJSON: {Schema: envelope}}
+	if op.Spreadsheet {
+		content["text/csv"] = mediaType{Schema: &Schema{Type: "string"}}
+		content[mimeXLSX] = mediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	return response{Description: "Success", Content: content}
+}
+
+// errorResponse describes an error response with the body type of v.
+func (b *builder) errorResponse(description string, v interface{}) response {
+	return response{Description: description, Content: map[string]mediaType{
+		fiber.MIMEApplicationJSON: {Schema: b.schemaOf(reflect.TypeOf(v))},
+	}}
+}
+
+// normalizePath drops the trailing slash of group roots, e.g., "/api/users/".
+func normalizePath(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// toOpenAPIPath turns Fiber parameters into OpenAPI ones, e.g., "/posts/:id" into "/posts/{id}".
+func toOpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimSuffix(segment[1:], "?") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIDParam reports whether the path parameter name holds a record ID, which must be a UUID
+// (see middleware.UUIDParams).
+func isIDParam(name string) bool {
+	return name == "id" || strings.HasSuffix(name, "Id")
+}
+
+// pathTag derives the group of an operation from its path, e.g., "posts" for "/api/posts/:id".
+func pathTag(path string) string {
+	segments := strings.FieldsFunc(strings.TrimPrefix(path, "/api"), func(r rune) bool { return r == '/' })
+	if len(segments) == 0 || strings.HasPrefix(segments[0], ":") {
+		return "general"
+	}
+	return segments[0]
+}
@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Schema is an OpenAPI 3 schema object, limited to what the API's types need.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	decimalType    = reflect.TypeOf(decimal.Decimal{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf returns the schema of t as encoding/json marshals it. Named structs are added to
+// components and referenced, so each type is described once.
+func (b *builder) schemaOf(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema *Schema
+	switch {
+	case t == timeType:
+		schema = &Schema{Type: "string", Format: "date-time"}
+	case t == decimalType:
+		schema = &Schema{Type: "number"} // See models/money.go: decimals are marshaled as numbers
+	case t == rawMessageType:
+		schema = &Schema{} // Embedded JSON: any value
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := b.componentName(t)
+		if _, ok := b.components[name]; !ok {
+			b.components[name] = nil // Reserve the name, so recursive types terminate
+			b.components[name] = b.structSchema(t)
+		}
+		schema = &Schema{Ref: "#/components/schemas/" + name}
+		if nullable {
+			// Siblings of $ref are ignored, so wrap the reference to mark it nullable
+			return &Schema{Nullable: true, AllOf: []*Schema{schema}}
+		}
+		return schema
+	case t.Kind() == reflect.Struct:
+		schema = b.structSchema(t)
+	case t.Kind() == reflect.String:
+		schema = &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		schema = &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = &Schema{Type: "number"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			schema = &Schema{Type: "string", Format: "byte"}
+		} else {
+			schema = &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+		}
+	case t.Kind() == reflect.Map:
+		schema = &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	default:
+		schema = &Schema{} // interface{} and the like: any value
+	}
+	schema.Nullable = nullable
+	return schema
+}
+
+// componentName returns the name of a struct type in components, qualified with its package if
+// another package has a type of the same name.
+func (b *builder) componentName(t reflect.Type) string {
+	name := t.Name()
+	if owner, ok := b.componentTypes[name]; ok && owner != t {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	b.componentTypes[name] = t
+	return name
+}
+
+// structSchema describes the JSON object of a struct, with the constraints of its validate tags.
+func (b *builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			// Embedded structs are flattened into the object, as encoding/json does
+			embedded := b.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := b.schemaOf(field.Type)
+		if applyRules(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+// applyRules adds the constraints of a validate tag (see go-playground/validator) to schema
+// and reports whether the field is required. Rules after "dive" apply to the items of a list.
+func applyRules(schema *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			if schema.Items == nil {
+				return required
+			}
+			target = schema.Items
+		case "required":
+			if target == schema {
+				required = true
+			} else if target.Type == "string" {
+				target.MinLength = intPtr(1)
+			}
+		case "email":
+			target.Format = "email"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "url", "http_url":
+			target.Format = "uri"
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "unique":
+			target.UniqueItems = true
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			setBound(target, name, n)
+		case "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch name {
+			case "gt":
+				target.Minimum, target.ExclusiveMinimum = &n, true
+			case "gte":
+				target.Minimum = &n
+			case "lt":
+				target.Maximum, target.ExclusiveMaximum = &n, true
+			case "lte":
+				target.Maximum = &n
+			}
+		case "price":
+			zero := 0.0
+			target.Minimum = &zero
+			target.Description = "Amount with at most 2 decimal places"
+		}
+	}
+	return required
+}
+
+// setBound applies a min, max or len rule: a length for strings, a number of items for lists
+// and a value for numbers.
+func setBound(schema *Schema, rule string, n int) {
+	lower, upper := rule == "min" || rule == "len", rule == "max" || rule == "len"
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = intPtr(n)
+		}
+		if upper {
+			schema.MaxLength = intPtr(n)
+		}
+	case "array":
+		if lower {
+			schema.MinItems = intPtr(n)
+		}
+		if upper {
+			schema.MaxItems = intPtr(n)
+		}
+	default:
+		f := float64(n)
+		if lower {
+			schema.Minimum = &f
+		}
+		if upper {
+			schema.Maximum = &f
+		}
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,127 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MidtransProvider integrates Midtrans Snap (https://docs.midtrans.com/reference/snap-api).
+type MidtransProvider struct {
+	ServerKey string // Server key, used for API auth and notification signatures
+	BaseURL   string // Snap API base URL (sandbox or production)
+}
+
+// NewMidtransProvider creates a MidtransProvider, or returns nil when no server key is configured.
+func NewMidtransProvider(serverKey string, production bool) Provider {
+	if serverKey == "" {
+		return nil
+	}
+	baseURL := "https://app.sandbox.midtrans.com"
+	if production {
+		baseURL = "https://app.midtrans.com"
+	}
+	return &MidtransProvider{ServerKey: serverKey, BaseURL: baseURL}
+}
+
+// Name returns "midtrans".
+func (p *MidtransProvider) Name() string { return "midtrans" }
+
+// CreateCheckout creates a Snap transaction. Our payment ID is sent as Midtrans' order_id,
+// which must be unique per payment attempt, so it doubles as the provider reference.
+func (p *MidtransProvider) CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error) {
+	payload := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     req.PaymentID,
+			"gross_amount": req.Amount.Round(0).IntPart(), // IDR has no minor units
+		},
+		"callbacks": map[string]string{
+			"finish": req.SuccessURL,
+		},
+		"custom_field1": req.OrderID,
+	}
+	if req.Email != "" {
+		payload["customer_details"] = map[string]string{"email": req.Email}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode midtrans request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/snap/v1/transactions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build midtrans request: %w", err)
+	}
+	httpReq.SetBasicAuth(p.ServerKey, "")
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call midtrans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("midtrans returned %d: %s", resp.StatusCode, respBody)
+	}
+
+	var snap struct {
+		Token       string `json:"token"`
+		RedirectURL string `json:"redirect_url"`
+	}
+	if err := json.Unmarshal(respBody, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode midtrans response: %w", err)
+	}
+	return &CheckoutSession{ProviderRef: req.PaymentID, RedirectURL: snap.RedirectURL}, nil
+}
+
+// ParseWebhook verifies a Midtrans HTTP notification's signature_key and maps its transaction status.
+func (p *MidtransProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	var notification struct {
+		OrderID           string `json:"order_id"`
+		StatusCode        string `json:"status_code"`
+		GrossAmount       string `json:"gross_amount"`
+		SignatureKey      string `json:"signature_key"`
+		TransactionStatus string `json:"transaction_status"`
+		FraudStatus       string `json:"fraud_status"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("failed to decode midtrans notification: %w", err)
+	}
+
+	// signature_key = SHA512(order_id + status_code + gross_amount + server_key)
+	sum := sha512.Sum512([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + p.ServerKey))
+	expected := hex.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(notification.SignatureKey)) != 1 {
+		return nil, fmt.Errorf("midtrans signature mismatch")
+	}
+
+	status := ""
+	switch notification.TransactionStatus {
+	case "settlement":
+		status = StatusSucceeded
+	case "capture":
+		// Card payments are only final when the fraud check accepted them
+		if notification.FraudStatus == "" || notification.FraudStatus == "accept" {
+			status = StatusSucceeded
+		}
+	case "deny", "cancel", "expire", "failure":
+		status = StatusFailed
+	case "pending":
+		status = StatusPending
+	}
+	if status == "" {
+		return nil, nil // Not a status change we act on
+	}
+
+	return &Event{ProviderRef: notification.OrderID, Status: status, Type: notification.TransactionStatus}, nil
+}
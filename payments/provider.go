@@ -0,0 +1,79 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Payment statuses reported by providers, normalized across gateways.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// CheckoutRequest describes what the customer should be charged for.
+type CheckoutRequest struct {
+	PaymentID   string          // Our payment ID; used as the idempotent reference at the provider when supported
+	OrderID     string          // Order being paid
+	Amount      decimal.Decimal // Total amount in major units (e.g., 12.50)
+	Currency    string          // ISO 4217 code (e.g., "usd", "idr")
+	Description string          // Line shown to the customer on the checkout page
+	Email       string          // Customer email, used for receipts
+	SuccessURL  string          // Where the customer is sent after paying
+	CancelURL   string          // Where the customer is sent after abandoning checkout
+}
+
+// CheckoutSession is the provider's answer to a checkout request.
+type CheckoutSession struct {
+	ProviderRef string // Provider-side identifier used to match webhook events to the payment
+	RedirectURL string // Hosted payment page the customer must be redirected to
+}
+
+// Event is a verified, normalized webhook notification.
+type Event struct {
+	ProviderRef string // Matches CheckoutSession.ProviderRef
+	Status      string // One of the Status* constants
+	Type        string // Raw provider event type, kept for logging
+}
+
+// Provider is implemented by every payment gateway integration.
+type Provider interface {
+	// Name returns the identifier clients use to pick the provider (e.g., "stripe").
+	Name() string
+	// CreateCheckout starts a hosted checkout for the request.
+	CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error)
+	// ParseWebhook verifies the authenticity of a webhook request and normalizes it.
+	// A nil event with a nil error means the notification is valid but irrelevant.
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
+
+// Registry holds the configured providers by name.
+type Registry map[string]Provider
+
+// NewRegistry creates a Registry from the given providers. Nil providers (not configured) are skipped.
+func NewRegistry(providers ...Provider) Registry {
+	registry := Registry{}
+	for _, p := range providers {
+		if p != nil {
+			registry[p.Name()] = p
+		}
+	}
+	return registry
+}
+
+// Get returns the provider registered under name.
+func (r Registry) Get(name string) (Provider, error) {
+	p, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("payment provider %q is not configured", name)
+	}
+	return p, nil
+}
+
+// httpClient is shared by the provider implementations.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
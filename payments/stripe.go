@@ -0,0 +1,187 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stripeWebhookTolerance is how old a signed webhook may be before it is rejected as a replay.
+const stripeWebhookTolerance = 5 * time.Minute
+
+// stripeMinorUnits lists the currencies whose amounts don't have two decimal places in their
+// minor units, by ISO 4217 code. Stripe takes amounts in minor units, e.g., cents.
+var stripeMinorUnits = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IDR": 0, "JPY": 0, "KMF": 0, "KRW": 0, "MGA": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// minorUnits returns the number of decimal places of the minor unit of currency; 2 unless
+// listed in stripeMinorUnits.
+func minorUnits(currency string) int32 {
+	if units, ok := stripeMinorUnits[strings.ToUpper(currency)]; ok {
+		return units
+	}
+	return 2
+}
+
+// StripeProvider integrates Stripe Checkout (https://stripe.com/docs/api/checkout/sessions).
+type StripeProvider struct {
+	SecretKey     string // API secret key (sk_...)
+	WebhookSecret string // Endpoint signing secret (whsec_...)
+	BaseURL       string // API base URL, overridable for tests
+}
+
+// NewStripeProvider creates a StripeProvider, or returns nil when no secret key is configured.
+func NewStripeProvider(secretKey, webhookSecret string) Provider {
+	if secretKey == "" {
+		return nil
+	}
+	return &StripeProvider{
+		SecretKey:     secretKey,
+		WebhookSecret: webhookSecret,
+		BaseURL:       "https://api.stripe.com",
+	}
+}
+
+// Name returns "stripe".
+func (p *StripeProvider) Name() string { return "stripe" }
+
+// CreateCheckout creates a Stripe Checkout Session for a single line item covering the order total.
+func (p *StripeProvider) CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", req.SuccessURL)
+	form.Set("cancel_url", req.CancelURL)
+	form.Set("client_reference_id", req.OrderID)
+	form.Set("metadata[order_id]", req.OrderID)
+	form.Set("metadata[payment_id]", req.PaymentID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", strings.ToLower(req.Currency))
+	form.Set("line_items[0][price_data][unit_amount]", req.Amount.Shift(minorUnits(req.Currency)).Round(0).String())
+	form.Set("line_items[0][price_data][product_data][name]", req.Description)
+	if req.Email != "" {
+		form.Set("customer_email", req.Email)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/v1/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build stripe request: %w", err)
+	}
+	httpReq.SetBasicAuth(p.SecretKey, "")
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", req.PaymentID) // Safe retries: Stripe returns the same session
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call stripe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stripe returned %d: %s", resp.StatusCode, body)
+	}
+
+	var session struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(body, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+	return &CheckoutSession{ProviderRef: session.ID, RedirectURL: session.URL}, nil
+}
+
+// ParseWebhook verifies the Stripe-Signature header and maps checkout session events to payment statuses.
+func (p *StripeProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if err := p.verifySignature(header.Get("Stripe-Signature"), body); err != nil {
+		return nil, err
+	}
+
+	var event struct {
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID            string `json:"id"`
+				PaymentStatus string `json:"payment_status"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe event: %w", err)
+	}
+
+	status := ""
+	switch event.Type {
+	case "checkout.session.completed":
+		// Delayed payment methods complete the session before the money arrives
+		if event.Data.Object.PaymentStatus == "paid" {
+			status = StatusSucceeded
+		}
+	case "checkout.session.async_payment_succeeded":
+		status = StatusSucceeded
+	case "checkout.session.async_payment_failed", "checkout.session.expired":
+		status = StatusFailed
+	}
+	if status == "" {
+		return nil, nil // Not an event we act on
+	}
+
+	return &Event{ProviderRef: event.Data.Object.ID, Status: status, Type: event.Type}, nil
+}
+
+// verifySignature implements https://stripe.com/docs/webhooks/signatures.
+func (p *StripeProvider) verifySignature(header string, body []byte) error {
+	if p.WebhookSecret == "" {
+		return fmt.Errorf("stripe webhook secret is not configured")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("malformed stripe signature header")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed stripe signature timestamp")
+	}
+	if time.Since(time.Unix(ts, 0)) > stripeWebhookTolerance {
+		return fmt.Errorf("stripe signature timestamp is too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("stripe signature mismatch")
+}
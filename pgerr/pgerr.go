@@ -0,0 +1,121 @@
+// Package pgerr classifies the constraint violations reported by the database drivers, so
+// callers can tell a duplicate or dangling value from a failure of the database itself.
+// PostgreSQL is the reference; the errors of the SQLite and MySQL dialects are classified
+// alike.
+package pgerr
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Kinds of constraint violations. Check for them with errors.Is on the result of Translate.
+var (
+	ErrUniqueViolation     = errors.New("unique violation")      // A unique or primary key value is taken
+	ErrForeignKeyViolation = errors.New("foreign key violation") // A referenced row is missing, or a referencing one remains
+	ErrCheckViolation      = errors.New("check violation")       // A CHECK constraint rejects the row
+	ErrNotNullViolation    = errors.New("not null violation")    // A required column is NULL
+)
+
+// Error is a constraint violation reported by a database driver.
+type Error struct {
+	Kind       error  // ErrUniqueViolation, ErrForeignKeyViolation, ErrCheckViolation or ErrNotNullViolation
+	Constraint string // Name of the violated constraint, when the driver reports it (PostgreSQL)
+	Err        error  // The error of the driver
+}
+
+// Error returns the message of the driver error.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the kind and the driver error, so both errors.Is(err, ErrUniqueViolation)
+// and errors.As(err, &pgErr) work.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Translate returns an *Error if err is or wraps a constraint violation, and err as is
+// otherwise.
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	var translated *Error
+	if errors.As(err, &translated) {
+		return translated
+	}
+	if kind, constraint := classify(err); kind != nil {
+		return &Error{Kind: kind, Constraint: constraint, Err: err}
+	}
+	return err
+}
+
+// IsUniqueViolation reports whether err was caused by a unique or primary key constraint.
+func IsUniqueViolation(err error) bool {
+	return errors.Is(Translate(err), ErrUniqueViolation)
+}
+
+// IsForeignKeyViolation reports whether err was caused by a foreign key constraint.
+func IsForeignKeyViolation(err error) bool {
+	return errors.Is(Translate(err), ErrForeignKeyViolation)
+}
+
+// classify returns the kind of the constraint violation err wraps, and the name of the
+// constraint if known; kind is nil if err isn't a constraint violation.
+func classify(err error) (kind error, constraint string) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return ErrUniqueViolation, pgErr.ConstraintName
+		case "23503": // foreign_key_violation
+			return ErrForeignKeyViolation, pgErr.ConstraintName
+		case "23514": // check_violation
+			return ErrCheckViolation, pgErr.ConstraintName
+		case "23502": // not_null_violation
+			return ErrNotNullViolation, pgErr.ConstraintName
+		}
+		return nil, ""
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return ErrUniqueViolation, ""
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return ErrForeignKeyViolation, ""
+		case sqlite3.SQLITE_CONSTRAINT_TRIGGER:
+			// RESTRICT actions are implemented as triggers, so they fail with a trigger constraint code
+			if strings.Contains(sqliteErr.Error(), "FOREIGN KEY") {
+				return ErrForeignKeyViolation, ""
+			}
+		case sqlite3.SQLITE_CONSTRAINT_CHECK:
+			return ErrCheckViolation, ""
+		case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+			return ErrNotNullViolation, ""
+		}
+		return nil, ""
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062: // ER_DUP_ENTRY
+			return ErrUniqueViolation, ""
+		case 1451, 1452: // A parent row is still referenced, or the referenced parent row doesn't exist
+			return ErrForeignKeyViolation, ""
+		case 3819: // ER_CHECK_CONSTRAINT_VIOLATED
+			return ErrCheckViolation, ""
+		case 1048, 1364: // ER_BAD_NULL_ERROR, ER_NO_DEFAULT_FOR_FIELD
+			return ErrNotNullViolation, ""
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,119 @@
+// Package quota counts the requests of API clients over fixed hourly and daily windows, so
+// each of them can be held to a quota. Clients are identified by subjects such as
+// "user:<id>", and their counts are reset at the top of every hour and at midnight UTC.
+package quota
+
+import (
+	"context"
+	"time"
+)
+
+// Limits are the quotas of a subject; a zero limit doesn't restrict its window.
+type Limits struct {
+	Hourly int // Requests per clock hour
+	Daily  int // Requests per UTC day
+}
+
+// Window is the usage of a subject in the current hour or day.
+type Window struct {
+	Limit     int       `json:"limit"`     // 0 if the window is unlimited
+	Used      int       `json:"used"`      // Requests counted so far, including rejected ones
+	Remaining int       `json:"remaining"` // Requests left; 0 if the window is unlimited
+	ResetsAt  time.Time `json:"resets_at"` // Start of the next window
+}
+
+// Exceeded reports whether the requests counted exceed the limit of the window.
+func (w Window) Exceeded() bool {
+	return w.Limit > 0 && w.Used > w.Limit
+}
+
+// Usage is the usage of a subject in its current windows.
+type Usage struct {
+	Subject string `json:"subject"`
+	Hourly  Window `json:"hourly"`
+	Daily   Window `json:"daily"`
+}
+
+// Exceeded returns the window whose limit is exceeded, the one resetting last if both are, and
+// whether there is one.
+func (u Usage) Exceeded() (Window, bool) {
+	switch {
+	case u.Daily.Exceeded():
+		return u.Daily, true
+	case u.Hourly.Exceeded():
+		return u.Hourly, true
+	}
+	return Window{}, false
+}
+
+// Tracker counts the requests of subjects in a Store. A nil *Tracker counts nothing.
+type Tracker struct {
+	store Store
+}
+
+// NewTracker returns a Tracker keeping its counts in store.
+func NewTracker(store Store) *Tracker {
+	return &Tracker{store: store}
+}
+
+// Use counts a request of subject and returns its usage afterwards.
+func (t *Tracker) Use(ctx context.Context, subject string, limits Limits) (Usage, error) {
+	return t.usage(ctx, subject, limits, func(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+		return t.store.Increment(ctx, key, expiresAt)
+	})
+}
+
+// Usage returns the usage of subject, without counting a request.
+func (t *Tracker) Usage(ctx context.Context, subject string, limits Limits) (Usage, error) {
+	return t.usage(ctx, subject, limits, func(ctx context.Context, key string, _ time.Time) (int, error) {
+		return t.store.Get(ctx, key)
+	})
+}
+
+// Reset forgets the requests of subject in its current windows.
+func (t *Tracker) Reset(ctx context.Context, subject string) error {
+	if t == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	hour, day := windows(now)
+	return t.store.Delete(ctx, key(subject, "hour", hour), key(subject, "day", day))
+}
+
+// usage reads the counts of the windows of subject with count.
+func (t *Tracker) usage(ctx context.Context, subject string, limits Limits, count func(ctx context.Context, key string, expiresAt time.Time) (int, error)) (Usage, error) {
+	now := time.Now().UTC()
+	hour, day := windows(now)
+	usage := Usage{
+		Subject: subject,
+		Hourly:  Window{Limit: limits.Hourly, ResetsAt: hour.Add(time.Hour)},
+		Daily:   Window{Limit: limits.Daily, ResetsAt: day.AddDate(0, 0, 1)},
+	}
+	if t == nil {
+		return usage, nil
+	}
+
+	var err error
+	if usage.Hourly.Used, err = count(ctx, key(subject, "hour", hour), usage.Hourly.ResetsAt); err != nil {
+		return Usage{}, err
+	}
+	if usage.Daily.Used, err = count(ctx, key(subject, "day", day), usage.Daily.ResetsAt); err != nil {
+		return Usage{}, err
+	}
+	for _, w := range []*Window{&usage.Hourly, &usage.Daily} {
+		if w.Limit > w.Used {
+			w.Remaining = w.Limit - w.Used
+		}
+	}
+	return usage, nil
+}
+
+// windows returns the starts of the hour and the UTC day of now.
+func windows(now time.Time) (hour, day time.Time) {
+	return now.Truncate(time.Hour), time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// key returns the store key of the count of subject in the window of kind starting at start.
+func key(subject, kind string, start time.Time) string {
+	return subject + ":" + kind + ":" + start.Format("2006010215")
+}
@@ -0,0 +1,57 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store in Redis, shared by all instances. Counts are kept as keys expiring
+// with their window.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore returns a RedisStore keeping its keys under prefix.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Increment implements Store.
+func (s *RedisStore) Increment(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+	pipe := s.client.TxPipeline()
+	count := pipe.Incr(ctx, s.prefix+key)
+	pipe.ExpireAt(ctx, s.prefix+key, expiresAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count request of %s: %w", key, err)
+	}
+	return int(count.Val()), nil
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, key string) (int, error) {
+	count, err := s.client.Get(ctx, s.prefix+key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read request count of %s: %w", key, err)
+	}
+	return count, nil
+}
+
+// Delete implements Store.
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	if err := s.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("failed to reset request counts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps the request counts of the windows of subjects, under keys such as
+// "user:<id>:hour:2024010112".
+type Store interface {
+	// Increment adds a request to the count of key, which is forgotten at expiresAt, and returns the new count.
+	Increment(ctx context.Context, key string, expiresAt time.Time) (int, error)
+	// Get returns the count of key, 0 if there is none.
+	Get(ctx context.Context, key string) (int, error)
+	// Delete forgets the counts of keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// MemoryStore is a Store in memory, for a single instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counts    map[string]memoryCount
+	lastSweep time.Time
+}
+
+type memoryCount struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counts: map[string]memoryCount{}}
+}
+
+// Increment implements Store.
+func (s *MemoryStore) Increment(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	c := s.counts[key]
+	if !c.expiresAt.After(now) {
+		c = memoryCount{expiresAt: expiresAt}
+	}
+	c.count++
+	s.counts[key] = c
+	return c.count, nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.counts[key]; ok && c.expiresAt.After(time.Now()) {
+		return c.count, nil
+	}
+	return 0, nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.counts, key)
+	}
+	return nil
+}
+
+// sweep drops the expired counts, at most once a minute, so subjects that stopped making
+// requests don't stay in memory.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.counts {
+		if !c.expiresAt.After(now) {
+			delete(s.counts, key)
+		}
+	}
+}
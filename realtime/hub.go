@@ -0,0 +1,119 @@
+// Package realtime pushes events, such as new notifications and announcements, to the clients
+// connected to the application (see controllers.RealtimeController). Clients are connected to
+// a single instance, so events only reach the clients of the instance they are published on.
+package realtime
+
+import (
+	"sync"
+)
+
+// Types of events.
+const (
+	EventNotification = "notification" // A notification was created for the user; Data is the models.Notification
+	EventAnnouncement = "announcement" // An admin broadcast a message to the tenant; Data is the models.Announcement
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it is dropped.
+const subscriberBuffer = 32
+
+// Event is a message pushed to clients.
+type Event struct {
+	ID   string      `json:"id"`   // ID of the notification or announcement, which clients resume the event stream from
+	Type string      `json:"type"` // One of the Event* constants
+	Data interface{} `json:"data"`
+}
+
+// Subscriber receives the events of a user, e.g., for one open connection.
+type Subscriber struct {
+	UserID   string
+	TenantID string
+	events   chan Event
+}
+
+// Events returns the channel the events of the subscriber are delivered on. It is closed once
+// the subscriber is unsubscribed or dropped for falling behind.
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+// Hub delivers events to the subscribers of the instance. Delivery never blocks: subscribers
+// that don't keep up are dropped, and their clients are expected to reconnect.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*Subscriber]struct{} // Subscribers by user ID
+}
+
+// NewHub creates and returns a new Hub instance without subscribers.
+func NewHub() *Hub {
+	return &Hub{subscribers: map[string]map[*Subscriber]struct{}{}}
+}
+
+// Subscribe returns a new subscriber to the events of the user with the given ID and tenant.
+// It must be unsubscribed once it is no longer needed.
+func (h *Hub) Subscribe(userID, tenantID string) *Subscriber {
+	s := &Subscriber{UserID: userID, TenantID: tenantID, events: make(chan Event, subscriberBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = map[*Subscriber]struct{}{}
+	}
+	h.subscribers[userID][s] = struct{}{}
+	return s
+}
+
+// Unsubscribe stops delivering events to s. Unsubscribing twice is harmless.
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(s)
+}
+
+// SendToUser delivers event to every subscriber of the user with the given ID.
+func (h *Hub) SendToUser(userID string, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subscribers[userID] {
+		h.deliver(s, event)
+	}
+}
+
+// Broadcast delivers event to every subscriber of the tenant with the given ID and returns how
+// many subscribers it was delivered to.
+func (h *Hub) Broadcast(tenantID string, event Event) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delivered := 0
+	for _, subscribers := range h.subscribers {
+		for s := range subscribers {
+			if s.TenantID == tenantID && h.deliver(s, event) {
+				delivered++
+			}
+		}
+	}
+	return delivered
+}
+
+// deliver queues event for s, dropping s if its buffer is full. The caller must hold h.mu.
+func (h *Hub) deliver(s *Subscriber, event Event) bool {
+	select {
+	case s.events <- event:
+		return true
+	default:
+		h.remove(s)
+		return false
+	}
+}
+
+// remove unsubscribes s and closes its channel. The caller must hold h.mu.
+func (h *Hub) remove(s *Subscriber) {
+	subscribers, ok := h.subscribers[s.UserID]
+	if _, subscribed := subscribers[s]; !ok || !subscribed {
+		return
+	}
+	delete(subscribers, s)
+	if len(subscribers) == 0 {
+		delete(h.subscribers, s.UserID)
+	}
+	close(s.events)
+}
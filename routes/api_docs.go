@@ -0,0 +1,380 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/bruteforce"
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/controllers"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/openapi"
+	"github.com/anpsniper/anpbayu-be/quota"
+	"github.com/anpsniper/anpbayu-be/scheduler"
+)
+
+// LoginResponse documents the body of successful POST /login and POST /login/otp/verify responses.
+type LoginResponse struct {
+	Status         string   `json:"status"` // "success"
+	Message        string   `json:"message"`
+	Token          string   `json:"token,omitempty"` // Omitted with TOKEN_DELIVERY=cookie, which sets an httpOnly cookie instead
+	UserID         string   `json:"user_id"`
+	TenantID       string   `json:"tenant_id"`
+	Username       string   `json:"username"`
+	Email          string   `json:"email"`
+	RoleID         string   `json:"role_id"`
+	Roles          []string `json:"roles"`
+	LastLoginLogID int      `json:"last_login_log_id"`    // Send back on logout
+	CSRFToken      string   `json:"csrf_token,omitempty"` // Only with TOKEN_DELIVERY=cookie: send it in X-CSRF-Token with the requests changing anything
+}
+
+// StatusResponse documents the body of the auth endpoints' other responses.
+type StatusResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// GraphQLResponse documents the body of POST /graphql responses.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data"` // Shaped by the query
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// GraphQLError documents an error of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"` // "code", e.g., "FORBIDDEN", and "fields" of validation errors
+}
+
+// Query parameters shared by the list endpoints.
+var (
+	pageParams = []openapi.Parameter{
+		openapi.Query("page", "integer", "Page number, starting at 1"),
+		openapi.Query("limit", "integer", "Page size"),
+	}
+	searchParam = openapi.Query("search", "string", "Case-insensitive search term")
+)
+
+// exportFormatDescription describes the format query parameter of the export endpoints.
+const exportFormatDescription = "Format of the file: csv (default), json, an array of the records, or xlsx. Files are streamed as they are read; one that is cut short (invalid JSON, for json) failed midway"
+
+// fieldsParam documents the fields query parameter of the endpoints returning records like record.
+func fieldsParam(record interface{}) openapi.Parameter {
+	return openapi.Query("fields", "string", "Comma-separated fields to return, among "+strings.Join(models.FieldsOf(record), ", ")+"; all by default. id is always returned")
+}
+
+// withPage returns a new list of params followed by the pagination parameters.
+func withPage(params ...openapi.Parameter) []openapi.Parameter {
+	return append(append([]openapi.Parameter{}, params...), pageParams...)
+}
+
+// apiDocs documents the routes of the API for the OpenAPI spec, keyed by method and path as
+// registered. Routes missing here are still listed in the spec, without request or response
+// types, so add an entry along with every new route.
+var apiDocs = map[string]openapi.Operation{
+	// Authentication
+	"POST /login": {Tag: "auth", Summary: "Log in with email and password", Body: controllers.LoginRequest{}, Response: LoginResponse{},
+		Description: "Client IPs and accounts failing to log in too often are answered with 429 and a Retry-After header while they are locked out " +
+			"(BRUTE_FORCE_IP_THRESHOLD and BRUTE_FORCE_ACCOUNT_THRESHOLD failures within BRUTE_FORCE_WINDOW_MINUTES)."},
+	"POST /login/otp": {Tag: "auth", Summary: "Send a login code by SMS to the phone number of a user", Body: controllers.OTPRequest{}, Response: StatusResponse{},
+		Description: "Answers the same whether or not the number is registered. A new code is sent at most once a minute; it expires after OTP_TTL_MINUTES. " +
+			"503 when no SMS gateway is configured (TWILIO_ACCOUNT_SID)."},
+	"POST /login/otp/verify": {Tag: "auth", Summary: "Log in with a login code received by SMS", Body: controllers.OTPLoginRequest{}, Response: LoginResponse{},
+		Description: "The code is invalidated once used or after OTP_MAX_ATTEMPTS wrong guesses."},
+	"POST /login/revoke": {Tag: "auth", Summary: "Log out everywhere from the \"this wasn't me\" link of a new login alert", Body: controllers.RevokeLoginRequest{}, Response: StatusResponse{},
+		Description: "Alerts of logins from a new device or country link to LOGIN_REVOKE_URL?token=..., whose page posts the token here. " +
+			"All sessions of the user are deleted and their tokens rejected."},
+	"POST /api/auth/logout": {Tag: "auth", Summary: "Log out, revoking the token and closing the login log entry", Body: controllers.LogoutRequest{}, Response: StatusResponse{}},
+	"GET /api/lstroles":     {Tag: "roles", Summary: "List roles for dropdowns", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Data: []models.LstRole{}},
+	"GET /ws": {Tag: "realtime", Summary: "WebSocket connection receiving the events of the authenticated user", Status: http.StatusSwitchingProtocols,
+		Query:       []openapi.Parameter{openapi.Query("access_token", "string", "JWT, for clients that can't send the Authorization header")},
+		Description: "Pushes JSON messages such as {\"type\": \"notification\", \"data\": {...}} and {\"type\": \"announcement\", \"data\": {...}}. The connection is closed when the token expires, and within a minute of logging out or the session being revoked."},
+	"POST /graphql": {Tag: "graphql", Summary: "Run a GraphQL query or mutation over users, roles, posts and products", Body: controllers.GraphQLRequest{}, Response: GraphQLResponse{},
+		Description: "Responds with {data, errors} as GraphQL does, with 200 OK even if some fields failed; " +
+			"the code of each error is in extensions.code. Users and roles require the users.manage and roles.manage permissions (or the admin role) and a client IP in ADMIN_IP_ALLOWLIST, as their REST routes do."},
+	"GET /api/dashboard": {Tag: "general", Summary: "Dashboard welcome message"},
+	"GET /api/profile":   {Tag: "general", Summary: "Account of the authenticated user, with their role, latest login and preferences", Data: models.UserProfile{}},
+	"GET /api/profile/logins": {Tag: "general", Summary: "List the logins of the authenticated user, newest first", Data: []models.UserLog{}, Paginated: true, Query: withPage(
+		openapi.Query("from", "string", "Earliest time of the logins, in RFC 3339 format"),
+		openapi.Query("to", "string", "Latest time of the logins, in RFC 3339 format"),
+	)},
+	"PUT /api/profile/preferences":     {Tag: "general", Summary: "Replace the preferences of the authenticated user with a JSON object", Body: map[string]interface{}{}},
+	"POST /api/profile/avatar":         {Tag: "general", Summary: "Upload the avatar of the authenticated user (JPEG, PNG, GIF or WebP)", Upload: "file", Data: controllers.AvatarResponse{}},
+	"DELETE /api/profile/avatar":       {Tag: "general", Summary: "Remove the avatar of the authenticated user", Data: controllers.AvatarResponse{}},
+	"GET /api/my-data":                 {Tag: "general", Summary: "Data of the authenticated user", Roles: []string{"user", "admin"}},
+	"GET /api/premium/special-content": {Tag: "general", Summary: "Premium content", Roles: []string{"admin", "premium_user"}},
+
+	// Health and metrics
+	"GET /health":   {Tag: "health", Summary: "Database health check"},
+	"GET /healthz":  {Tag: "health", Summary: "Liveness probe"},
+	"GET /readyz":   {Tag: "health", Summary: "Readiness probe of the database, migrations and cache"},
+	"GET /startupz": {Tag: "health", Summary: "Startup probe"},
+	"GET /metrics": {Tag: "health", Summary: "Database pool statistics and expired rows deleted per table",
+		Description: "Requires METRICS_TOKEN as bearer token when it is set; not served in production without it."},
+	"GET /metrics/prometheus": {Tag: "health", Summary: "Database statement durations by operation and database pool statistics, in the Prometheus text format", ContentType: "text/plain",
+		Description: "Requires METRICS_TOKEN as bearer token when it is set; not served in production without it."},
+
+	// API documentation
+	"GET /docs":              {Tag: "docs", Summary: "Swagger UI", ContentType: fiber.MIMETextHTML},
+	"GET /docs/openapi.json": {Tag: "docs", Summary: "This OpenAPI spec", Response: map[string]interface{}{}},
+
+	// Public feeds
+	"GET /feed": {Tag: "feed", Summary: "Published posts", Data: []models.FeedItem{}, Paginated: true,
+		Query: withPage(openapi.Query("render", "string", `"html" renders the Markdown content`))},
+	"GET /feed.rss":  {Tag: "feed", Summary: "RSS 2.0 feed of recent posts", ContentType: "application/rss+xml"},
+	"GET /feed.atom": {Tag: "feed", Summary: "Atom 1.0 feed of recent posts", ContentType: "application/atom+xml"},
+
+	"POST /webhooks/payments/:provider": {Tag: "payments", Summary: "Payment provider webhook, verified by the provider's signature"},
+
+	// Users
+	"GET /api/users": {Summary: "List users", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Data: []models.UserResponse{}, Paginated: true, Spreadsheet: true,
+		Query: withPage(searchParam, openapi.Query("role_id", "string", "Only users with this role"), openapi.Query("tag", "string", "Only users with this tag"), fieldsParam(models.UserResponse{}))},
+	"GET /api/users/export": {Summary: "Download the users matching the filters of the listing as a CSV, JSON or XLSX file, by username", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, ContentType: "text/csv",
+		Description: "Tags aren't exported.",
+		Query:       []openapi.Parameter{searchParam, openapi.Query("role_id", "string", "Only users with this role"), openapi.Query("tag", "string", "Only users with this tag"), openapi.Query("format", "string", exportFormatDescription)}},
+	"GET /api/users/:id":                  {Summary: "Get a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Data: models.User{}, Query: []openapi.Parameter{fieldsParam(models.User{})}},
+	"POST /api/users":                     {Summary: "Create a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Body: controllers.CreateUserRequest{}, Idempotent: true, Status: http.StatusCreated, Data: models.User{}},
+	"PUT /api/users/:id":                  {Summary: "Update a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Body: models.UpdateUserRequest{}},
+	"DELETE /api/users/:id":               {Summary: "Move a user to the trash", Roles: []string{"admin"}, Permission: models.PermissionUsersManage},
+	"GET /api/users/tags":                 {Summary: "List the tags attached to users, with their number of users", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Data: []models.UserTagCount{}},
+	"PUT /api/users/:id/tags":             {Summary: "Replace the tags of a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Body: controllers.UserTagsRequest{}, Data: []string{}},
+	"GET /api/users/:id/notes":            {Summary: "List the internal notes on a user, newest first", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Data: []models.UserNote{}, Paginated: true, Query: pageParams},
+	"POST /api/users/:id/notes":           {Summary: "Add an internal note on a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Body: controllers.UserNoteRequest{}, Status: http.StatusCreated, Data: models.UserNote{}},
+	"PUT /api/users/:id/notes/:noteId":    {Summary: "Edit an internal note on a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Body: controllers.UserNoteRequest{}, Data: models.UserNote{}},
+	"DELETE /api/users/:id/notes/:noteId": {Summary: "Delete an internal note on a user", Roles: []string{"admin"}, Permission: models.PermissionUsersManage},
+	"POST /api/users/tags/bulk":           {Summary: "Attach and detach tags on several users at once", Roles: []string{"admin"}, Permission: models.PermissionUsersManage, Body: controllers.TagUsersRequest{}},
+
+	// Roles
+	"GET /api/roles":        {Summary: "List roles", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Data: []models.Role{}, Paginated: true, Spreadsheet: true, Query: withPage(searchParam)},
+	"GET /api/roles/:id":    {Summary: "Get a role", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Data: models.Role{}},
+	"POST /api/roles":       {Summary: "Create a role", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Platform: true, Body: controllers.CreateRoleRequest{}, Status: http.StatusCreated, Data: models.Role{}},
+	"PUT /api/roles/:id":    {Summary: "Update a role", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Platform: true, Body: controllers.UpdateRoleRequest{}, Data: models.Role{}},
+	"DELETE /api/roles/:id": {Summary: "Move a role no user is assigned to to the trash", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Platform: true},
+	"GET /api/roles/matrix": {Summary: "Get the grid of the permissions granted to every role", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Data: models.PermissionMatrix{},
+		Description: "The admin role has every permission; its row is locked."},
+	"PUT /api/roles/matrix": {Summary: "Grant and revoke permissions of roles in one batch", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Platform: true, Body: controllers.UpdatePermissionMatrixRequest{}, Data: models.PermissionMatrix{},
+		Description: "Applies every change or none, and responds with the updated matrix. Changing the permissions of the admin role fails with 400 Bad Request."},
+	"GET /api/roles/export": {Summary: "Download every role with its permissions as a JSON file", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Response: models.RoleExport{},
+		Description: "The file can be posted as is to POST /api/roles/import in another environment. The admin role is exported with every permission."},
+	"POST /api/roles/import": {Summary: "Create the roles of an export, all or none of them", Roles: []string{"admin"}, Permission: models.PermissionRolesManage, Platform: true, Body: controllers.RoleImportRequest{}, Data: []models.RoleImportResult{},
+		Query:       []openapi.Parameter{openapi.Query("on_conflict", "string", "What to do with roles named like an existing one: skip (default), overwrite the existing role, or rename the imported one")},
+		Description: "Trashed roles count as existing; overwriting them fails with 409 Conflict until they are restored or purged."},
+
+	// Posts
+	"GET /api/posts": {Summary: "List posts", Data: []models.Post{}, Paginated: true, Query: withPage(searchParam,
+		openapi.Query("tag", "string", "Only posts with the tag of this slug"),
+		openapi.Query("category", "string", "Only posts in the category of this slug"),
+		openapi.Query("render", "string", `"html" renders the Markdown content`),
+		fieldsParam(models.Post{}))},
+	"GET /api/posts/:id": {Summary: "Get a post", Data: models.Post{},
+		Query: []openapi.Parameter{openapi.Query("render", "string", `"html" renders the Markdown content`), fieldsParam(models.Post{})}},
+	"POST /api/posts":       {Summary: "Create a post", Body: controllers.PostRequest{}, Status: http.StatusCreated, Data: models.Post{}},
+	"PUT /api/posts/:id":    {Summary: "Update a post (author or admin)", Body: controllers.PostRequest{}, Data: models.Post{}},
+	"DELETE /api/posts/:id": {Summary: "Move a post to the trash (author or admin)"},
+
+	"GET /api/posts/:id/attachments":                  {Tag: "attachments", Summary: "List the attachments of a post", Data: []models.Attachment{}},
+	"POST /api/posts/:id/attachments":                 {Tag: "attachments", Summary: "Upload an attachment (author or admin)", Upload: "file", Status: http.StatusCreated, Data: models.Attachment{}},
+	"DELETE /api/posts/:id/attachments/:attachmentId": {Tag: "attachments", Summary: "Delete an attachment (author or admin)"},
+
+	// Reports
+	"POST /api/posts/:id/report":    {Tag: "reports", Summary: "Report a post", Body: controllers.CreateReportRequest{}, Status: http.StatusCreated, Data: models.Report{}},
+	"POST /api/comments/:id/report": {Tag: "reports", Summary: "Report a comment", Body: controllers.CreateReportRequest{}, Status: http.StatusCreated, Data: models.Report{}},
+	"GET /api/reports": {Summary: "List reports", Roles: []string{"admin"}, Permission: models.PermissionReportsReview, Data: []models.Report{}, Paginated: true, Query: withPage(
+		openapi.Query("status", "string", `"open" (default), "resolved" or "dismissed"`),
+		openapi.Query("target_type", "string", `"post" or "comment"`))},
+	"PUT /api/reports/:id/resolve": {Summary: "Close a report", Roles: []string{"admin"}, Permission: models.PermissionReportsReview, Body: controllers.ResolveReportRequest{}, Data: models.Report{}},
+
+	// Tags
+	"GET /api/tags": {Summary: "List tags", Data: []models.Tag{}, Paginated: true, Query: withPage(searchParam)},
+	"GET /api/tags/cloud": {Summary: "Most used tags with their post counts", Data: []models.TagCloudItem{},
+		Query: []openapi.Parameter{openapi.Query("limit", "integer", "Number of tags, 50 by default")}},
+	"POST /api/tags":       {Summary: "Create a tag", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: controllers.TagRequest{}, Status: http.StatusCreated, Data: models.Tag{}},
+	"PUT /api/tags/:id":    {Summary: "Update a tag", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: controllers.TagRequest{}, Data: models.Tag{}},
+	"DELETE /api/tags/:id": {Summary: "Delete a tag", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage},
+
+	// Categories
+	"GET /api/categories":        {Summary: "List categories", Data: []models.Category{}, Paginated: true, Query: withPage(searchParam)},
+	"GET /api/categories/:id":    {Summary: "Get a category", Data: models.Category{}},
+	"POST /api/categories":       {Summary: "Create a category", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: controllers.CategoryRequest{}, Status: http.StatusCreated, Data: models.Category{}},
+	"PUT /api/categories/:id":    {Summary: "Update a category", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: controllers.CategoryRequest{}, Data: models.Category{}},
+	"DELETE /api/categories/:id": {Summary: "Delete a category", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage},
+
+	// Products
+	"GET /api/products": {Summary: "List products", Data: []models.Product{}, Paginated: true, Spreadsheet: true, Query: withPage(searchParam,
+		openapi.Query("category", "string", "Only products in this category"),
+		openapi.Query("stock_status", "string", `"in_stock", "low_stock" or "out_of_stock"`),
+		openapi.Query("min_price", "number", "Lowest price"),
+		openapi.Query("max_price", "number", "Highest price"),
+		openapi.Query("archived", "string", `"false" (default), "true" for archived products only or "all"; admins only`),
+		openapi.Query("sort", "string", `Sort fields, e.g., "-price,name"`),
+		fieldsParam(models.Product{}))},
+	"GET /api/products/:id":                   {Summary: "Get a product", Data: models.Product{}, Query: []openapi.Parameter{fieldsParam(models.Product{})}},
+	"POST /api/products":                      {Summary: "Create a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: models.ProductCreateRequest{}, Status: http.StatusCreated, Data: models.Product{}},
+	"PUT /api/products/:id":                   {Summary: "Update a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: controllers.UpdateProductRequest{}, Data: models.Product{}},
+	"DELETE /api/products/:id":                {Summary: "Move a product to the trash", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage},
+	"POST /api/products/:id/archive":          {Summary: "Archive a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Data: models.Product{}},
+	"POST /api/products/:id/unarchive":        {Summary: "Unarchive a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Data: models.Product{}},
+	"POST /api/products/:id/image":            {Summary: "Upload the image of a product (JPEG, PNG, GIF or WebP)", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Upload: "file", Data: models.Product{}},
+	"DELETE /api/products/:id/image":          {Summary: "Remove the image of a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Data: models.Product{}},
+	"GET /api/products/:id/stock-adjustments": {Summary: "List the stock movements of a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Data: []models.StockMovement{}, Paginated: true, Query: pageParams},
+	"POST /api/products/:id/stock-adjustments": {Summary: "Adjust the stock of a product", Roles: []string{"admin"}, Permission: models.PermissionCatalogManage, Body: controllers.StockAdjustmentRequest{},
+		Status: http.StatusCreated, Data: models.StockMovement{}},
+
+	// Orders and payments
+	"GET /api/orders":               {Summary: "List the orders of the authenticated user", Data: []models.Order{}, Paginated: true, Query: pageParams},
+	"GET /api/orders/:id":           {Summary: "Get an order (owner or admin)", Data: models.Order{}},
+	"POST /api/orders":              {Summary: "Place an order, reserving its stock", Body: controllers.CreateOrderRequest{}, Idempotent: true, Status: http.StatusCreated, Data: models.Order{}},
+	"POST /api/orders/:id/checkout": {Tag: "payments", Summary: "Start a hosted checkout for an order", Body: controllers.CheckoutRequest{}, Idempotent: true, Status: http.StatusCreated, Data: models.Payment{}},
+	"GET /api/orders/:id/payments":  {Tag: "payments", Summary: "List the payments of an order", Data: []models.Payment{}},
+
+	// Teams
+	"GET /api/teams":              {Summary: "List the teams of the authenticated user, with their role in each", Data: []models.Team{}, Paginated: true, Query: pageParams},
+	"GET /api/teams/:id":          {Summary: "Get a team (members or admin)", Data: models.Team{}},
+	"POST /api/teams":             {Summary: "Create a team, owned by the authenticated user", Body: controllers.TeamRequest{}, Status: http.StatusCreated, Data: models.Team{}},
+	"PUT /api/teams/:id":          {Summary: "Update a team (owners or admin)", Body: controllers.TeamRequest{}, Data: models.Team{}},
+	"DELETE /api/teams/:id":       {Summary: "Delete a team (owners or admin)"},
+	"GET /api/teams/:id/members":  {Summary: "List the members of a team, owners first (members or admin)", Data: []models.TeamMember{}},
+	"POST /api/teams/:id/members": {Summary: "Add a user to a team (owners or admin)", Body: controllers.AddTeamMemberRequest{}, Status: http.StatusCreated},
+	"PUT /api/teams/:id/members/:userId": {Summary: "Change the role of a team member (owners or admin)", Body: controllers.UpdateTeamMemberRequest{},
+		Description: "Fails with 409 Conflict when demoting the last owner."},
+	"DELETE /api/teams/:id/members/:userId": {Summary: "Remove a member from a team (owners or admin), or leave it",
+		Description: "Fails with 409 Conflict when removing the last owner."},
+
+	// Organizations
+	"GET /api/organizations":             {Summary: "List the organizations of the authenticated user, with their role in each", Data: []models.Organization{}, Paginated: true, Query: pageParams},
+	"GET /api/organizations/:id":         {Summary: "Get an organization (members or admin)", Data: models.Organization{}},
+	"POST /api/organizations":            {Summary: "Create an organization, with the authenticated user as its admin", Body: controllers.OrganizationRequest{}, Status: http.StatusCreated, Data: models.Organization{}},
+	"PUT /api/organizations/:id":         {Summary: "Update an organization (its admins or admin)", Body: controllers.OrganizationRequest{}, Data: models.Organization{}},
+	"DELETE /api/organizations/:id":      {Summary: "Delete an organization (its admins or admin)"},
+	"GET /api/organizations/:id/members": {Summary: "List the members of an organization with their roles in it (members or admin)", Data: []models.OrganizationMember{}},
+	"PUT /api/organizations/:id/members/:userId/role": {Summary: "Assign a role in an organization to a member (its admins or admin)", Body: controllers.MemberRoleRequest{},
+		Description: "Fails with 409 Conflict when it would leave the organization without a member with the admin role."},
+	"DELETE /api/organizations/:id/members/:userId": {Summary: "Remove a member from an organization (its admins or admin), or leave it",
+		Description: "Fails with 409 Conflict when it would leave the organization without a member with the admin role."},
+	"GET /api/organizations/:id/invitations": {Summary: "List the pending invitations of an organization (its admins or admin)", Data: []models.OrganizationInvitation{}},
+	"POST /api/organizations/:id/invitations": {Summary: "Invite an email address to an organization (its admins or admin)", Body: controllers.InvitationRequest{},
+		Status: http.StatusCreated, Data: models.OrganizationInvitation{},
+		Description: "The invitation is emailed when SMTP is configured, with a link to INVITATION_URL. The response carries its token; it is not shown again. " +
+			"Inviting an address again replaces its invitation."},
+	"DELETE /api/organizations/:id/invitations/:invitationId": {Summary: "Withdraw an invitation (its admins or admin)"},
+	"POST /api/organizations/invitations/accept": {Summary: "Accept an invitation to an organization", Body: controllers.AcceptInvitationRequest{}, Data: models.Organization{},
+		Description: "The invitation must have been sent to the email address of the authenticated user, or fails with 422 Unprocessable Entity."},
+
+	// Notifications
+	"GET /api/notifications": {Summary: "List the notifications of the authenticated user, newest first", Data: []models.Notification{}, Paginated: true,
+		Query: withPage(openapi.Query("unread", "boolean", "Only unread notifications"))},
+	"GET /api/notifications/unread-count": {Summary: "Count the unread notifications of the authenticated user", Data: controllers.UnreadCountResponse{}},
+	"POST /api/notifications/read-all":    {Summary: "Mark all notifications of the authenticated user read", Data: controllers.MarkAllReadResponse{}},
+	"POST /api/notifications/:id/read":    {Summary: "Mark a notification read"},
+	"GET /api/events": {Tag: "realtime", Summary: "Server-Sent Events stream of the notifications and announcements of the authenticated user", ContentType: "text/event-stream",
+		Query: []openapi.Parameter{
+			openapi.Query("access_token", "string", "JWT, for clients that can't send the Authorization header"),
+			openapi.Query("last_event_id", "string", "ID of the last event received, like the Last-Event-ID header"),
+			{Name: "Last-Event-ID", In: "header", Description: "ID of the last event received; the events missed since are sent first", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Description: "Events are named \"notification\" or \"announcement\" and carry their ID and JSON data. A comment is sent every 20 seconds to keep the connection open. The stream ends when the token expires, and within a minute of logging out or the session being revoked."},
+
+	// Trash
+	"GET /api/trash/:resource":              {Summary: "List trashed records of a resource", Roles: []string{"admin"}, Permission: models.PermissionTrashManage, Data: []models.TrashedRecord{}, Paginated: true, Query: pageParams},
+	"POST /api/trash/:resource/:id/restore": {Summary: "Restore a trashed record", Roles: []string{"admin"}, Permission: models.PermissionTrashManage, Description: "Roles can only be restored by the users of the platform tenant."},
+	"DELETE /api/trash/:resource/:id":       {Summary: "Delete a trashed record permanently", Roles: []string{"admin"}, Permission: models.PermissionTrashManage, Description: "Roles can only be purged by the users of the platform tenant."},
+
+	// Login history
+	"GET /api/user-logs": {Summary: "List the logins of users, newest first, with the duration of their sessions", Roles: []string{"admin"}, Permission: models.PermissionLogsView, Data: []models.UserLog{}, Paginated: true, Keyset: true, Spreadsheet: true,
+		Description: "Spreadsheets list the logins oldest first, like the export.", Query: withPage(
+			openapi.Query("user_id", "string", "Only the logins of this user"),
+			openapi.Query("from", "string", "Earliest time of the logins, in RFC 3339 format"),
+			openapi.Query("to", "string", "Latest time of the logins, in RFC 3339 format"),
+		)},
+	"GET /api/user-logs/export": {Summary: "Download the logins of users matching the filters of the listing as a CSV, JSON or XLSX file, oldest first", Roles: []string{"admin"}, Permission: models.PermissionLogsView, ContentType: "text/csv", Query: []openapi.Parameter{
+		openapi.Query("user_id", "string", "Only the logins of this user"),
+		openapi.Query("from", "string", "Earliest time of the logins, in RFC 3339 format"),
+		openapi.Query("to", "string", "Latest time of the logins, in RFC 3339 format"),
+		openapi.Query("format", "string", exportFormatDescription),
+	}},
+
+	// Analytics
+	"GET /api/analytics/logins": {Summary: "Logins, active users and average session duration per day or week", Roles: []string{"admin"}, Permission: models.PermissionLogsView, Data: models.LoginAnalytics{}, Query: []openapi.Parameter{
+		openapi.Query("group_by", "string", "day (default) or week; weeks start on Monday"),
+		openapi.Query("from", "string", "Start of the range, in RFC 3339 format; 30 days before to by default"),
+		openapi.Query("to", "string", "End of the range, in RFC 3339 format; now by default. Ranges span at most 366 days"),
+	}},
+
+	// Audit log
+	"GET /api/audit-logs": {Summary: "List the audit log, newest first", Roles: []string{"admin"}, Permission: models.PermissionLogsView, Data: []models.AuditLog{}, Paginated: true, Keyset: true, Spreadsheet: true, Query: withPage(
+		openapi.Query("actor_id", "string", "ID of the user who made the changes"),
+		openapi.Query("action", "string", "create, update or delete"),
+		openapi.Query("entity_type", "string", "user, role, post or product"),
+		openapi.Query("entity_id", "string", "ID of the changed record"),
+		openapi.Query("from", "string", "Earliest time of the changes, in RFC 3339 format"),
+		openapi.Query("to", "string", "Latest time of the changes, in RFC 3339 format"),
+	)},
+	"GET /api/failed-logins": {Summary: "List the failed login attempts, newest first", Roles: []string{"admin"}, Permission: models.PermissionLogsView, Data: []models.FailedLogin{}, Paginated: true, Query: withPage(
+		openapi.Query("account", "string", "Email or phone number tried"),
+		openapi.Query("ip_address", "string", "IP address of the client"),
+		openapi.Query("reason", "string", "unknown_account, wrong_tenant, invalid_password, invalid_code or locked_out"),
+		openapi.Query("from", "string", "Earliest time of the attempts, in RFC 3339 format"),
+		openapi.Query("to", "string", "Latest time of the attempts, in RFC 3339 format"),
+	)},
+
+	// Webhooks
+	"GET /api/webhooks":     {Summary: "List webhooks", Roles: []string{"admin"}, Permission: models.PermissionWebhooksManage, Data: []models.Webhook{}, Paginated: true, Query: pageParams},
+	"GET /api/webhooks/:id": {Summary: "Get a webhook", Roles: []string{"admin"}, Permission: models.PermissionWebhooksManage, Data: models.Webhook{}},
+	"POST /api/webhooks": {Summary: "Register a webhook", Roles: []string{"admin"}, Permission: models.PermissionWebhooksManage, Body: controllers.CreateWebhookRequest{}, Status: http.StatusCreated, Data: models.Webhook{},
+		Description: "The response carries the secret deliveries are signed with (X-Webhook-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of \"<unix time>.<body>\">); it is not shown again. Endpoints must be on public addresses: loopback, private and link-local ones are refused, and redirects aren't followed."},
+	"PUT /api/webhooks/:id":    {Summary: "Update a webhook", Roles: []string{"admin"}, Permission: models.PermissionWebhooksManage, Body: controllers.UpdateWebhookRequest{}, Data: models.Webhook{}},
+	"DELETE /api/webhooks/:id": {Summary: "Delete a webhook and its delivery history", Roles: []string{"admin"}, Permission: models.PermissionWebhooksManage},
+	"GET /api/webhooks/:id/deliveries": {Summary: "List the deliveries of a webhook, newest first", Roles: []string{"admin"}, Permission: models.PermissionWebhooksManage, Data: []models.WebhookDelivery{}, Paginated: true,
+		Query: withPage(openapi.Query("status", "string", "pending, succeeded or failed"))},
+
+	"POST /api/admin/config/reload": {Summary: "Reload the runtime settings", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Platform: true},
+	"GET /api/admin/tasks":          {Summary: "List the scheduled tasks with their next and last run", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Data: []scheduler.TaskStatus{}},
+	"GET /api/admin/retention":      {Summary: "Count the log entries the log-retention task would delete or anonymize if it ran now (dry run)", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Data: models.RetentionPreview{}},
+	"POST /api/admin/broadcasts": {Summary: "Push an announcement to the connected users of the tenant", Roles: []string{"admin"}, Permission: models.PermissionSystemManage,
+		Body: controllers.BroadcastRequest{}, Status: http.StatusCreated, Data: controllers.BroadcastResult{}},
+	"GET /api/admin/maintenance":       {Summary: "Get the state of maintenance mode", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Data: models.MaintenanceMode{}},
+	"GET /api/admin/throttled":         {Summary: "List the client IPs and accounts locked out after too many failed logins", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Platform: true, Data: []bruteforce.Source{}},
+	"GET /api/admin/quotas/:userId":    {Summary: "Show the request quota usage of a user of the tenant in the current hour and day", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Data: quota.Usage{}},
+	"DELETE /api/admin/quotas/:userId": {Summary: "Reset the request quota usage of a user of the tenant", Roles: []string{"admin"}, Permission: models.PermissionSystemManage},
+	"PUT /api/admin/maintenance": {Summary: "Enable or disable maintenance mode", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Platform: true, Body: controllers.MaintenanceRequest{}, Data: models.MaintenanceMode{},
+		Description: "While it is enabled, requests of everyone but admins are answered with 503 Service Unavailable and a Retry-After header " +
+			"(until ends_at, or 5 minutes). POST /login stays available, so admins can sign in."},
+	"POST /api/admin/tasks/:name/run": {Summary: "Run a scheduled task now", Roles: []string{"admin"}, Permission: models.PermissionSystemManage, Platform: true, Data: scheduler.TaskStatus{},
+		Description: "Responds once the task finished. Fails with 409 Conflict while the task is running already."},
+}
+
+// SetupDocsRoutes serves the OpenAPI spec of the API at /docs/openapi.json and Swagger UI at
+// /docs (DOCS_ENABLED). It must be called BEFORE the JWT middleware in main.go.
+func SetupDocsRoutes(app *fiber.App) {
+	if !config.AppConfig.DocsEnabled {
+		return
+	}
+	docsController := controllers.NewDocsController(config.AppConfig.SiteTitle, func() *openapi.Document {
+		return OpenAPISpec(app)
+	}, "/docs/openapi.json")
+	app.Get("/docs", docsController.GetUI)                // GET /docs
+	app.Get("/docs/openapi.json", docsController.GetSpec) // GET /docs/openapi.json
+}
+
+// OpenAPISpec describes the routes registered with app, using the documentation in apiDocs.
+// It is served by SetupDocsRoutes and written to a file by the "openapi export" command.
+func OpenAPISpec(app *fiber.App) *openapi.Document {
+	description := "Routes under /api require a JWT from POST /login, sent as a bearer token."
+	if middleware.TokenCookies() {
+		description = "Routes under /api require a JWT from POST /login, which sets it in the httpOnly cookie " +
+			config.AppConfig.AuthCookieName + "; API clients may send it as a bearer token instead."
+	}
+	info := openapi.Info{
+		Title:       config.AppConfig.SiteTitle,
+		Version:     "1.0.0",
+		Description: description,
+	}
+	return openapi.Build(info, app.GetRoutes(true), apiDocs, func(path string) bool {
+		return strings.HasPrefix(path, "/api/") || path == "/graphql" // Routes under /api and /graphql require a JWT, see server.New
+	})
+}
@@ -3,9 +3,20 @@ package routes
 import (
 	"net/http" // For http.StatusOK etc.
 
-	"github.com/anpsniper/anpbayu-be/controllers" // Import controllers package
-	"github.com/anpsniper/anpbayu-be/middleware"  // Import your custom middleware for RBAC
-	"github.com/anpsniper/anpbayu-be/services"    // Import services package
+	"github.com/anpsniper/anpbayu-be/bruteforce"    // Import bruteforce package for the login lockouts
+	"github.com/anpsniper/anpbayu-be/cache"         // Import cache package for the query cache
+	"github.com/anpsniper/anpbayu-be/config"        // Import config package for upload settings
+	"github.com/anpsniper/anpbayu-be/controllers"   // Import controllers package
+	"github.com/anpsniper/anpbayu-be/database"      // Import database package for the connection pool
+	"github.com/anpsniper/anpbayu-be/middleware"    // Import your custom middleware for RBAC
+	"github.com/anpsniper/anpbayu-be/models"        // Import models package for the permissions
+	"github.com/anpsniper/anpbayu-be/notifications" // Import notification channels
+	"github.com/anpsniper/anpbayu-be/payments"      // Import payment providers
+	"github.com/anpsniper/anpbayu-be/quota"         // Import quota package for the request quotas
+	"github.com/anpsniper/anpbayu-be/realtime"      // Import realtime package for pushed events
+	"github.com/anpsniper/anpbayu-be/scheduler"     // Import scheduler package for the scheduled tasks
+	"github.com/anpsniper/anpbayu-be/services"      // Import services package
+	"github.com/anpsniper/anpbayu-be/storage"       // Import storage package for uploaded files
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -13,19 +24,92 @@ import (
 // IMPORTANT: This function is called AFTER the JWT authentication middleware
 // in main.go. Therefore, all routes defined here will automatically require
 // a valid JWT. Role-based access control is then applied on top of that.
-func SetupAPIRoutes(app *fiber.App) {
+// Role and user lists are served from queryCache, unless it is nil, and role lookups from
+// roleCache, which changes to roles invalidate. Uploaded files are kept
+// in fileStorage. Admins can run the tasks of sched on demand. Events are pushed to the
+// clients connected to /ws through hub. Admins can list the sources locked out by guard.
+// authController, which serves the public login routes, handles logouts.
+func SetupAPIRoutes(app *fiber.App, db *database.DB, queryCache *cache.QueryCache, roleCache *services.RoleCache, fileStorage storage.Storage, sched *scheduler.Scheduler, hub *realtime.Hub, maintenanceMode *middleware.MaintenanceMode, guard *bruteforce.Guard, quotaTracker *quota.Tracker, authController *controllers.AuthController) {
 	// Initialize services
-	userService := services.NewUserService()
-	roleService := services.NewRoleService() // Initialize RoleService
+	// Notifications are shown in the app, pushed to connected clients and emailed when SMTP_HOST is set
+	mailer := notifications.NewEmailChannel(
+		config.AppConfig.SMTPHost, config.AppConfig.SMTPPort, config.AppConfig.SMTPUsername, config.AppConfig.SMTPPassword, config.AppConfig.MailFrom,
+	)
+	notificationService := services.NewNotificationService(db, notifications.NewRealtimeChannel(hub), mailer)
+	userService := services.NewCachedUserService(services.NewNotifyingUserService(services.NewUserService(db, roleCache), notificationService), queryCache)
+	roleService := services.NewCachedRoleService(services.NewRoleService(db), queryCache, roleCache) // Initialize RoleService
+	postService := services.NewPostService(db)
+	tagService := services.NewTagService(db)
+	categoryService := services.NewCategoryService(db)
+	attachmentService := services.NewAttachmentService(db)
+	reportService := services.NewReportService(db)
+	productService := services.NewNotifyingProductService(services.NewProductService(db), notificationService)
+	orderService := services.NewNotifyingOrderService(services.NewOrderService(db), notificationService)
+	trashService := services.NewCachedTrashService(services.NewTrashService(db), queryCache, roleCache)
+	auditService := services.NewAuditService(db)
+	webhookService := services.NewWebhookService(db)
+	teamService := services.NewTeamService(db)
+	userNoteService := services.NewUserNoteService(db)
+	analyticsService := services.NewAnalyticsService(db)
+	organizationService := services.NewOrganizationService(db, mailer, config.AppConfig.InvitationURL, config.AppConfig.InvitationTTL)
+
+	// Creating users, orders and payments is safe to retry with an Idempotency-Key header
+	idempotent := middleware.Idempotency(services.NewIdempotencyService(db))
+
+	// Uploads are screened for their size, blocked extensions and mismatching content types before they reach the controllers
+	uploads := middleware.Uploads(config.AppConfig.UploadMaxSize, config.AppConfig.UploadBlockedExtensions)
+
+	// Record IDs in the path must be UUIDs; anything else is rejected before it reaches a query
+	uuidParams := middleware.UUIDParams("id", "userId", "noteId", "attachmentId", "invitationId")
+
+	// Roles and settings are shared by every tenant; only the platform tenant's admins may change them
+	platformOnly := middleware.PlatformOnly()
+
+	// The admin route groups only accept requests from ADMIN_IP_ALLOWLIST (e.g., the office network)
+	adminNetworks := middleware.NewSwappable(middleware.IPFilter(config.Current().AdminIPAllowlist, nil))
+	config.OnReload(func(settings *config.Runtime) {
+		adminNetworks.Swap(middleware.IPFilter(settings.AdminIPAllowlist, nil))
+	})
 
 	// Initialize controllers with their dependencies.
-	authController := controllers.NewAuthController(userService) // NEW: Initialize AuthController
-	userController := controllers.NewUserController(userService)
+	userController := controllers.NewUserController(userService, fileStorage, config.AppConfig.UploadMaxSize)
+	profileController := controllers.NewProfileController(userService, fileStorage)
 	roleController := controllers.NewRoleController(roleService)
+	postController := controllers.NewPostController(postService)
+	tagController := controllers.NewTagController(tagService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	reportController := controllers.NewReportController(reportService)
+	productController := controllers.NewProductController(productService, fileStorage, config.AppConfig.UploadMaxSize)
+	attachmentController := controllers.NewAttachmentController(postService, attachmentService, fileStorage, config.AppConfig.UploadMaxSize)
+	orderController := controllers.NewOrderController(orderService, config.AppConfig.PaymentCurrency, config.AppConfig.ReservationTTL)
+	paymentController := newPaymentController(db, orderService)
+	trashController := controllers.NewTrashController(trashService)
+	auditController := controllers.NewAuditController(auditService)
+	failedLoginController := controllers.NewFailedLoginController(services.NewFailedLoginService(db))
+	webhookController := controllers.NewWebhookController(webhookService)
+	teamController := controllers.NewTeamController(teamService)
+	userNoteController := controllers.NewUserNoteController(userNoteService)
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+	organizationController := controllers.NewOrganizationController(organizationService, userService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	configController := controllers.NewConfigController()
+	schedulerController := controllers.NewSchedulerController(sched)
+	realtimeController := controllers.NewRealtimeController(hub, services.NewAnnouncementService(db), services.NewEventService(db), services.NewSessionService(db))
+	maintenanceController := controllers.NewMaintenanceController(services.NewSettingService(db), maintenanceMode)
+	throttleController := controllers.NewThrottleController(guard)
+	quotaController := controllers.NewQuotaController(quotaTracker, userService)
+	retentionController := controllers.NewRetentionController(services.NewMaintenanceService(db), config.AppConfig.LogRetention, config.AppConfig.LogRetentionMode)
+	graphQLController := controllers.NewGraphQLController(userService, roleService, postService, productService, fileStorage)
 
 	// Public route for authentication (no JWT middleware applied to this specific route)
 	app.Post("/login", authController.Login) // This should be outside the JWT-protected group
 
+	// WebSocket connection receiving the events of the user, such as new notifications
+	app.Get("/ws", realtimeController.Connect) // GET /ws?access_token=...
+
+	// GraphQL API over users, roles, posts and products (requires JWT; resolvers check the same permissions and admin networks as the REST routes)
+	app.Post("/graphql", graphQLController.Execute) // POST /graphql
+
 	// Group authenticated API routes under /api prefix.
 	// All routes within this group will automatically require a valid JWT
 	// because the JWT middleware is applied to `app` before this function is called.
@@ -34,9 +118,9 @@ func SetupAPIRoutes(app *fiber.App) {
 	// NEW: Logout route (requires JWT, any authenticated user can logout)
 	api.Post("/auth/logout", authController.Logout) // This will be protected by the global JWT middleware on `api` group
 
-	// NEW: Route for listing roles for dropdown, accessible by admin
-	// This is now directly under /api and has its own admin role check.
-	api.Get("/lstroles", middleware.HasRole("admin"), userController.GetAllRoles)
+	// NEW: Route for listing roles for dropdown, accessible to the user managers
+	// This is now directly under /api and has its own permission check.
+	api.Get("/lstroles", adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionUsersManage), userController.GetAllRoles)
 
 	// --- Public Protected Routes (requires JWT, no specific role check here) ---
 	// Accessible by any authenticated user (i.e., with a valid JWT).
@@ -51,41 +135,243 @@ func SetupAPIRoutes(app *fiber.App) {
 		})
 	})
 
-	// Example route to get authenticated user's profile information
-	// This route was previously in main.go's example.
-	api.Get("/profile", func(c *fiber.Ctx) error {
-		userID, _ := middleware.GetUserIDFromJWT(c)
-		userRoles, _ := middleware.GetUserRolesFromJWT(c)
-		return c.Status(http.StatusOK).JSON(fiber.Map{
-			"message": "Welcome to your profile!",
-			"user_id": userID,
-			"roles":   userRoles,
-		})
-	})
+	// Account of the authenticated user
+	api.Get("/profile", profileController.GetProfile)                    // GET /api/profile
+	api.Put("/profile/preferences", profileController.UpdatePreferences) // PUT /api/profile/preferences
+	api.Get("/profile/logins", profileController.GetLogins)              // GET /api/profile/logins?from=...&to=...
+	api.Post("/profile/avatar", uploads, userController.UploadAvatar)    // POST /api/profile/avatar (multipart "file")
+	api.Delete("/profile/avatar", userController.DeleteAvatar)           // DELETE /api/profile/avatar
 
-	// --- User Management Routes (Requires 'admin' role) ---
-	// All routes within this group will require the 'admin' role.
+	// --- User Management Routes (Requires the 'users.manage' permission) ---
+	// All routes within this group will require the permission (or the 'admin' role).
 	userManagement := api.Group("/users")
-	userManagement.Use(middleware.HasRole("admin")) // Apply role-based middleware for admin
+	userManagement.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionUsersManage)) // Apply permission-based middleware
 	{
-		userManagement.Get("/", userController.GetAllUsers)    // GET /api/users
-		userManagement.Get("/:id", userController.GetUserByID) // GET /api/users/:id
-		userManagement.Post("/", userController.CreateUser)    // POST /api/users
+		userManagement.Get("/", userController.GetAllUsers)                // GET /api/users
+		userManagement.Get("/export", userController.ExportUsers)          // GET /api/users/export?format=csv|json|xlsx
+		userManagement.Get("/tags", userController.GetUserTags)            // GET /api/users/tags
+		userManagement.Post("/tags/bulk", userController.TagUsers)         // POST /api/users/tags/bulk
+		userManagement.Get("/:id", uuidParams, userController.GetUserByID) // GET /api/users/:id
+		userManagement.Post("/", idempotent, userController.CreateUser)    // POST /api/users
 		// userManagement.Get("/lstroles", userController.GetAllRoles) // REMOVED: Moved to directly under /api
-		userManagement.Put("/:id", userController.UpdateUser)    // PUT /api/users/:id
-		userManagement.Delete("/:id", userController.DeleteUser) // DELETE /api/users/:id
+		userManagement.Put("/:id", uuidParams, userController.UpdateUser)                      // PUT /api/users/:id
+		userManagement.Delete("/:id", uuidParams, userController.DeleteUser)                   // DELETE /api/users/:id
+		userManagement.Put("/:id/tags", uuidParams, userController.SetUserTags)                // PUT /api/users/:id/tags
+		userManagement.Get("/:id/notes", uuidParams, userNoteController.GetNotes)              // GET /api/users/:id/notes
+		userManagement.Post("/:id/notes", uuidParams, userNoteController.CreateNote)           // POST /api/users/:id/notes
+		userManagement.Put("/:id/notes/:noteId", uuidParams, userNoteController.UpdateNote)    // PUT /api/users/:id/notes/:noteId
+		userManagement.Delete("/:id/notes/:noteId", uuidParams, userNoteController.DeleteNote) // DELETE /api/users/:id/notes/:noteId
 	}
 
-	// --- Role Management Routes (Requires 'admin' role) ---
-	// All routes within this group will require the 'admin' role.
+	// --- Role Management Routes (Requires the 'roles.manage' permission) ---
+	// All routes within this group will require the permission (or the 'admin' role).
 	roleManagement := api.Group("/roles")
-	roleManagement.Use(middleware.HasRole("admin")) // Apply role-based middleware for admin
+	roleManagement.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionRolesManage)) // Apply permission-based middleware
+	{
+		roleManagement.Get("/", roleController.GetAllRoles)                                // GET /api/roles (with search, pagination)
+		roleManagement.Get("/matrix", roleController.GetPermissionMatrix)                  // GET /api/roles/matrix
+		roleManagement.Put("/matrix", platformOnly, roleController.UpdatePermissionMatrix) // PUT /api/roles/matrix
+		roleManagement.Get("/export", roleController.ExportRoles)                          // GET /api/roles/export
+		roleManagement.Post("/import", platformOnly, roleController.ImportRoles)           // POST /api/roles/import?on_conflict=skip|overwrite|rename
+		roleManagement.Get("/:id", uuidParams, roleController.GetRoleByID)                 // GET /api/roles/:id
+		roleManagement.Post("/", platformOnly, roleController.CreateRole)                  // POST /api/roles
+		roleManagement.Put("/:id", uuidParams, platformOnly, roleController.UpdateRole)    // PUT /api/roles/:id
+		roleManagement.Delete("/:id", uuidParams, platformOnly, roleController.DeleteRole) // DELETE /api/roles/:id
+	}
+
+	// --- Post Routes (any authenticated user; authors or admins may modify) ---
+	postRoutes := api.Group("/posts")
+	{
+		postRoutes.Get("/", postController.GetAllPosts)                  // GET /api/posts?tag=go&category=news
+		postRoutes.Get("/:id", uuidParams, postController.GetPostByID)   // GET /api/posts/:id
+		postRoutes.Post("/", postController.CreatePost)                  // POST /api/posts
+		postRoutes.Put("/:id", uuidParams, postController.UpdatePost)    // PUT /api/posts/:id
+		postRoutes.Delete("/:id", uuidParams, postController.DeletePost) // DELETE /api/posts/:id
+
+		postRoutes.Get("/:id/attachments", uuidParams, attachmentController.GetAttachments)                    // GET /api/posts/:id/attachments
+		postRoutes.Post("/:id/attachments", uuidParams, uploads, attachmentController.UploadAttachment)        // POST /api/posts/:id/attachments (multipart "file")
+		postRoutes.Delete("/:id/attachments/:attachmentId", uuidParams, attachmentController.DeleteAttachment) // DELETE /api/posts/:id/attachments/:attachmentId
+
+		postRoutes.Post("/:id/report", uuidParams, reportController.ReportPost) // POST /api/posts/:id/report
+	}
+
+	// --- Comment Routes ---
+	commentRoutes := api.Group("/comments")
+	{
+		commentRoutes.Post("/:id/report", uuidParams, reportController.ReportComment) // POST /api/comments/:id/report
+	}
+
+	// --- Report Review Queue (Requires the 'reports.review' permission) ---
+	reportManagement := api.Group("/reports")
+	reportManagement.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionReportsReview))
+	{
+		reportManagement.Get("/", reportController.GetAllReports)                        // GET /api/reports?status=open
+		reportManagement.Put("/:id/resolve", uuidParams, reportController.ResolveReport) // PUT /api/reports/:id/resolve
+	}
+
+	// --- Tag Routes (listing for everyone, management requires the 'catalog.manage' permission) ---
+	manageCatalog := middleware.HasPermission(roleService, models.PermissionCatalogManage)
+	tagRoutes := api.Group("/tags")
+	{
+		tagRoutes.Get("/", tagController.GetAllTags)                                 // GET /api/tags
+		tagRoutes.Get("/cloud", tagController.GetTagCloud)                           // GET /api/tags/cloud
+		tagRoutes.Post("/", manageCatalog, tagController.CreateTag)                  // POST /api/tags
+		tagRoutes.Put("/:id", manageCatalog, uuidParams, tagController.UpdateTag)    // PUT /api/tags/:id
+		tagRoutes.Delete("/:id", manageCatalog, uuidParams, tagController.DeleteTag) // DELETE /api/tags/:id
+	}
+
+	// --- Category Routes (listing for everyone, management requires the 'catalog.manage' permission) ---
+	categoryRoutes := api.Group("/categories")
+	{
+		categoryRoutes.Get("/", categoryController.GetAllCategories)                                // GET /api/categories
+		categoryRoutes.Get("/:id", uuidParams, categoryController.GetCategoryByID)                  // GET /api/categories/:id
+		categoryRoutes.Post("/", manageCatalog, categoryController.CreateCategory)                  // POST /api/categories
+		categoryRoutes.Put("/:id", manageCatalog, uuidParams, categoryController.UpdateCategory)    // PUT /api/categories/:id
+		categoryRoutes.Delete("/:id", manageCatalog, uuidParams, categoryController.DeleteCategory) // DELETE /api/categories/:id
+	}
+
+	// --- Product Routes (listing for everyone, management requires the 'catalog.manage' permission) ---
+	productRoutes := api.Group("/products")
+	{
+		productRoutes.Get("/", productController.GetAllProducts)                                 // GET /api/products
+		productRoutes.Get("/:id", uuidParams, productController.GetProductByID)                  // GET /api/products/:id
+		productRoutes.Post("/", manageCatalog, productController.CreateProduct)                  // POST /api/products
+		productRoutes.Put("/:id", manageCatalog, uuidParams, productController.UpdateProduct)    // PUT /api/products/:id
+		productRoutes.Delete("/:id", manageCatalog, uuidParams, productController.DeleteProduct) // DELETE /api/products/:id
+
+		productRoutes.Post("/:id/archive", manageCatalog, uuidParams, productController.ArchiveProduct)     // POST /api/products/:id/archive
+		productRoutes.Post("/:id/unarchive", manageCatalog, uuidParams, productController.UnarchiveProduct) // POST /api/products/:id/unarchive
+
+		productRoutes.Post("/:id/image", manageCatalog, uuidParams, uploads, productController.UploadImage) // POST /api/products/:id/image (multipart "file")
+		productRoutes.Delete("/:id/image", manageCatalog, uuidParams, productController.DeleteImage)        // DELETE /api/products/:id/image
+
+		productRoutes.Get("/:id/stock-adjustments", manageCatalog, uuidParams, productController.GetStockMovements) // GET /api/products/:id/stock-adjustments
+		productRoutes.Post("/:id/stock-adjustments", manageCatalog, uuidParams, productController.AdjustStock)      // POST /api/products/:id/stock-adjustments
+	}
+
+	// --- Order Routes (any authenticated user; orders are only visible to their owner or an admin) ---
+	orderRoutes := api.Group("/orders")
+	{
+		orderRoutes.Get("/", orderController.GetMyOrders)                                     // GET /api/orders
+		orderRoutes.Get("/:id", uuidParams, orderController.GetOrderByID)                     // GET /api/orders/:id
+		orderRoutes.Post("/", idempotent, orderController.CreateOrder)                        // POST /api/orders
+		orderRoutes.Post("/:id/checkout", uuidParams, idempotent, paymentController.Checkout) // POST /api/orders/:id/checkout
+		orderRoutes.Get("/:id/payments", uuidParams, paymentController.GetOrderPayments)      // GET /api/orders/:id/payments
+	}
+
+	// --- Team Routes (any authenticated user; teams are visible to their members and managed by their owners or an admin) ---
+	teamRoutes := api.Group("/teams")
+	{
+		teamRoutes.Get("/", teamController.GetMyTeams)                                     // GET /api/teams
+		teamRoutes.Get("/:id", uuidParams, teamController.GetTeamByID)                     // GET /api/teams/:id
+		teamRoutes.Post("/", teamController.CreateTeam)                                    // POST /api/teams
+		teamRoutes.Put("/:id", uuidParams, teamController.UpdateTeam)                      // PUT /api/teams/:id
+		teamRoutes.Delete("/:id", uuidParams, teamController.DeleteTeam)                   // DELETE /api/teams/:id
+		teamRoutes.Get("/:id/members", uuidParams, teamController.GetMembers)              // GET /api/teams/:id/members
+		teamRoutes.Post("/:id/members", uuidParams, teamController.AddMember)              // POST /api/teams/:id/members
+		teamRoutes.Put("/:id/members/:userId", uuidParams, teamController.UpdateMember)    // PUT /api/teams/:id/members/:userId
+		teamRoutes.Delete("/:id/members/:userId", uuidParams, teamController.RemoveMember) // DELETE /api/teams/:id/members/:userId
+	}
+
+	// --- Organization Routes (any authenticated user; organizations are visible to their members and
+	// managed by their members with the "admin" role in them, or an admin) ---
+	organizationRoutes := api.Group("/organizations")
+	{
+		organizationRoutes.Get("/", organizationController.GetMyOrganizations)                                           // GET /api/organizations
+		organizationRoutes.Post("/", organizationController.CreateOrganization)                                          // POST /api/organizations
+		organizationRoutes.Post("/invitations/accept", organizationController.AcceptInvitation)                          // POST /api/organizations/invitations/accept
+		organizationRoutes.Get("/:id", uuidParams, organizationController.GetOrganizationByID)                           // GET /api/organizations/:id
+		organizationRoutes.Put("/:id", uuidParams, organizationController.UpdateOrganization)                            // PUT /api/organizations/:id
+		organizationRoutes.Delete("/:id", uuidParams, organizationController.DeleteOrganization)                         // DELETE /api/organizations/:id
+		organizationRoutes.Get("/:id/members", uuidParams, organizationController.GetMembers)                            // GET /api/organizations/:id/members
+		organizationRoutes.Put("/:id/members/:userId/role", uuidParams, organizationController.SetMemberRole)            // PUT /api/organizations/:id/members/:userId/role
+		organizationRoutes.Delete("/:id/members/:userId", uuidParams, organizationController.RemoveMember)               // DELETE /api/organizations/:id/members/:userId
+		organizationRoutes.Get("/:id/invitations", uuidParams, organizationController.GetInvitations)                    // GET /api/organizations/:id/invitations
+		organizationRoutes.Post("/:id/invitations", uuidParams, organizationController.Invite)                           // POST /api/organizations/:id/invitations
+		organizationRoutes.Delete("/:id/invitations/:invitationId", uuidParams, organizationController.DeleteInvitation) // DELETE /api/organizations/:id/invitations/:invitationId
+	}
+
+	// --- Notification Routes (any authenticated user, for their own notifications) ---
+	notificationRoutes := api.Group("/notifications")
+	{
+		notificationRoutes.Get("/", notificationController.GetNotifications)              // GET /api/notifications?unread=true
+		notificationRoutes.Get("/unread-count", notificationController.GetUnreadCount)    // GET /api/notifications/unread-count
+		notificationRoutes.Post("/read-all", notificationController.MarkAllRead)          // POST /api/notifications/read-all
+		notificationRoutes.Post("/:id/read", uuidParams, notificationController.MarkRead) // POST /api/notifications/:id/read
+	}
+
+	// --- Event Stream (any authenticated user) ---
+	// Server-Sent Events of the user's notifications and announcements, for clients that can't use /ws
+	api.Get("/events", realtimeController.Stream) // GET /api/events (Last-Event-ID header to resume)
+
+	// --- Trash Routes (Requires the 'trash.manage' permission) ---
+	// Deleting users, roles, posts and products only moves them to the trash.
+	trashManagement := api.Group("/trash")
+	trashManagement.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionTrashManage))
+	{
+		trashManagement.Get("/:resource", trashController.GetTrashed)                              // GET /api/trash/posts
+		trashManagement.Post("/:resource/:id/restore", uuidParams, trashController.RestoreTrashed) // POST /api/trash/posts/:id/restore
+		trashManagement.Delete("/:resource/:id", uuidParams, trashController.PurgeTrashed)         // DELETE /api/trash/posts/:id
+	}
+
+	// --- Login History Routes (Requires the 'logs.view' permission) ---
+	userLogRoutes := api.Group("/user-logs")
+	userLogRoutes.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionLogsView))
+	{
+		userLogRoutes.Get("/", userController.GetUserLogs)          // GET /api/user-logs?user_id=...&from=...&to=...
+		userLogRoutes.Get("/export", userController.ExportUserLogs) // GET /api/user-logs/export?from=...&to=...&format=csv|json|xlsx
+	}
+
+	// --- Analytics Routes (Requires the 'logs.view' permission) ---
+	analyticsRoutes := api.Group("/analytics")
+	analyticsRoutes.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionLogsView))
+	{
+		analyticsRoutes.Get("/logins", analyticsController.GetLoginAnalytics) // GET /api/analytics/logins?group_by=week&from=...&to=...
+	}
+
+	// --- Audit Log Routes (Requires the 'logs.view' permission) ---
+	// Every change to users, roles, posts and products is recorded with its actor.
+	auditRoutes := api.Group("/audit-logs")
+	auditRoutes.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionLogsView))
+	{
+		auditRoutes.Get("/", auditController.GetAuditLogs) // GET /api/audit-logs?entity_type=post&entity_id=...
+	}
+
+	// --- Failed Login Routes (Requires the 'logs.view' permission) ---
+	// Every failed login attempt is recorded with the account tried, the client IP and the reason.
+	failedLoginRoutes := api.Group("/failed-logins")
+	failedLoginRoutes.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionLogsView))
+	{
+		failedLoginRoutes.Get("/", failedLoginController.GetFailedLogins) // GET /api/failed-logins?ip_address=...&reason=...
+	}
+
+	// --- Webhook Routes (Requires the 'webhooks.manage' permission) ---
+	// Registered endpoints are notified of the events they subscribe to, see webhooks.
+	webhookManagement := api.Group("/webhooks")
+	webhookManagement.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionWebhooksManage))
+	{
+		webhookManagement.Get("/", webhookController.GetWebhooks)                             // GET /api/webhooks
+		webhookManagement.Get("/:id", uuidParams, webhookController.GetWebhookByID)           // GET /api/webhooks/:id
+		webhookManagement.Post("/", webhookController.CreateWebhook)                          // POST /api/webhooks
+		webhookManagement.Put("/:id", uuidParams, webhookController.UpdateWebhook)            // PUT /api/webhooks/:id
+		webhookManagement.Delete("/:id", uuidParams, webhookController.DeleteWebhook)         // DELETE /api/webhooks/:id
+		webhookManagement.Get("/:id/deliveries", uuidParams, webhookController.GetDeliveries) // GET /api/webhooks/:id/deliveries?status=failed
+	}
+
+	// --- Admin Routes (Requires the 'system.manage' permission) ---
+	adminRoutes := api.Group("/admin")
+	adminRoutes.Use(adminNetworks.Handler(), middleware.HasPermission(roleService, models.PermissionSystemManage))
 	{
-		roleManagement.Get("/", roleController.GetAllRoles)      // GET /api/roles (with search, pagination)
-		roleManagement.Get("/:id", roleController.GetRoleByID)   // GET /api/roles/:id
-		roleManagement.Post("/", roleController.CreateRole)      // POST /api/roles
-		roleManagement.Put("/:id", roleController.UpdateRole)    // PUT /api/roles/:id
-		roleManagement.Delete("/:id", roleController.DeleteRole) // DELETE /api/roles/:id
+		adminRoutes.Post("/config/reload", platformOnly, configController.ReloadConfig)         // POST /api/admin/config/reload
+		adminRoutes.Get("/tasks", schedulerController.GetTasks)                                 // GET /api/admin/tasks
+		adminRoutes.Post("/tasks/:name/run", platformOnly, schedulerController.RunTask)         // POST /api/admin/tasks/log-retention/run
+		adminRoutes.Get("/retention", retentionController.PreviewRetention)                     // GET /api/admin/retention
+		adminRoutes.Post("/broadcasts", realtimeController.Broadcast)                           // POST /api/admin/broadcasts
+		adminRoutes.Get("/maintenance", maintenanceController.GetMaintenanceMode)               // GET /api/admin/maintenance
+		adminRoutes.Put("/maintenance", platformOnly, maintenanceController.SetMaintenanceMode) // PUT /api/admin/maintenance
+		adminRoutes.Get("/throttled", platformOnly, throttleController.GetThrottled)            // GET /api/admin/throttled
+		adminRoutes.Get("/quotas/:userId", uuidParams, quotaController.GetUsage)                // GET /api/admin/quotas/:userId
+		adminRoutes.Delete("/quotas/:userId", uuidParams, quotaController.ResetUsage)           // DELETE /api/admin/quotas/:userId
 	}
 
 	// --- Example of a route accessible by multiple roles ---
@@ -108,3 +394,19 @@ func SetupAPIRoutes(app *fiber.App) {
 		})
 	}
 }
+
+// SetupWebhookRoutes sets up the payment provider webhooks. It must be called BEFORE the
+// JWT middleware in main.go: providers authenticate with signatures, not tokens.
+func SetupWebhookRoutes(app *fiber.App, db *database.DB) {
+	paymentController := newPaymentController(db, services.NewOrderService(db))
+	app.Post("/webhooks/payments/:provider", paymentController.HandleWebhook) // POST /webhooks/payments/stripe
+}
+
+// newPaymentController wires the configured payment providers into a PaymentController.
+func newPaymentController(db *database.DB, orderService services.OrderServiceInterface) *controllers.PaymentController {
+	providers := payments.NewRegistry(
+		payments.NewStripeProvider(config.AppConfig.StripeSecretKey, config.AppConfig.StripeWebhookSecret),
+		payments.NewMidtransProvider(config.AppConfig.MidtransServerKey, config.AppConfig.MidtransProduction),
+	)
+	return controllers.NewPaymentController(orderService, services.NewPaymentService(db), providers, config.AppConfig.PaymentReturnURL)
+}
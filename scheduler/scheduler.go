@@ -0,0 +1,216 @@
+// Package scheduler runs recurring tasks, such as cleanups and periodic checks, on cron
+// schedules (see github.com/robfig/cron) and lets administrators run them on demand.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Off is the schedule of tasks that only run on demand.
+const Off = "off"
+
+// Errors returned by Run.
+var (
+	ErrUnknownTask = errors.New("unknown task")
+	ErrRunning     = errors.New("task is already running")
+)
+
+// Func is the work of a task. It should return promptly once ctx is done.
+type Func func(ctx context.Context) error
+
+// TaskStatus describes a task and its last run.
+type TaskStatus struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`         // Standard cron expression (e.g., "0 3 * * *"), descriptor (e.g., "@hourly") or "off"
+	Running        bool       `json:"running"`          // A run is in progress
+	NextRunAt      *time.Time `json:"next_run_at"`      // Next scheduled run; nil for tasks that only run on demand
+	LastRunAt      *time.Time `json:"last_run_at"`      // Start of the last finished run; nil if it never ran
+	LastDurationMs int64      `json:"last_duration_ms"` // Duration of the last finished run
+	LastError      *string    `json:"last_error"`       // Error of the last finished run; nil if it succeeded
+}
+
+// task is a registered task with its state.
+type task struct {
+	name     string
+	schedule string
+	run      Func
+	entryID  cron.EntryID // Zero for tasks that only run on demand
+
+	running      bool
+	lastRunAt    *time.Time
+	lastDuration time.Duration
+	lastError    *string
+}
+
+// Scheduler runs tasks on their schedules. A task never runs twice at the same time: a
+// scheduled run is skipped while the previous one, or one started with Run, is in progress.
+// Every instance of the application runs its own scheduler, so tasks must be safe to run
+// concurrently on several instances.
+type Scheduler struct {
+	cron    *cron.Cron
+	timeout time.Duration // Upper bound of a run
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu    sync.Mutex
+	tasks []*task
+}
+
+// New creates and returns a new Scheduler instance, which aborts runs taking longer than timeout.
+func New(timeout time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:    cron.New(),
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Validate checks a schedule: a standard cron expression with five fields, a descriptor such
+// as "@daily" or "@every 10m", or Off.
+func Validate(schedule string) error {
+	if schedule == Off {
+		return nil
+	}
+	_, err := cron.ParseStandard(schedule)
+	return err
+}
+
+// Add registers a task named name that runs on schedule (see Validate).
+func (s *Scheduler) Add(name, schedule string, run Func) error {
+	t := &task{name: name, schedule: schedule, run: run}
+	if schedule != Off {
+		parsed, err := cron.ParseStandard(schedule)
+		if err != nil {
+			return err
+		}
+		t.entryID = s.cron.Schedule(parsed, cron.FuncJob(func() {
+			if err := s.execute(s.ctx, t); errors.Is(err, ErrRunning) {
+				slog.Warn("Skipping scheduled task, the previous run is still in progress", "task", name)
+			}
+		}))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, t)
+	return nil
+}
+
+// Start runs the tasks on their schedules in the background.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling runs and aborts the ones in progress, waiting for them to return.
+func (s *Scheduler) Stop() {
+	done := s.cron.Stop()
+	s.cancel()
+	<-done.Done()
+}
+
+// Tasks returns the status of every task, in the order they were added.
+func (s *Scheduler) Tasks() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]TaskStatus, len(s.tasks))
+	for i, t := range s.tasks {
+		statuses[i] = s.status(t)
+	}
+	return statuses
+}
+
+// Run runs the task named name now and waits for it to finish, returning its status and the
+// error of the run. It fails with ErrUnknownTask if there is no such task and with ErrRunning
+// if the task is running already. The run isn't aborted when ctx is done, only when the
+// scheduler stops.
+func (s *Scheduler) Run(ctx context.Context, name string) (TaskStatus, error) {
+	t := s.find(name)
+	if t == nil {
+		return TaskStatus{}, ErrUnknownTask
+	}
+	err := s.execute(context.WithoutCancel(ctx), t)
+	if errors.Is(err, ErrRunning) {
+		return TaskStatus{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status(t), err
+}
+
+// execute runs t unless it is running already, and records the outcome.
+func (s *Scheduler) execute(ctx context.Context, t *task) error {
+	s.mu.Lock()
+	if t.running {
+		s.mu.Unlock()
+		return ErrRunning
+	}
+	t.running = true
+	s.mu.Unlock()
+
+	// Runs are aborted by Stop and after the timeout, whichever comes first
+	runCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	stop := context.AfterFunc(s.ctx, cancel)
+	started := time.Now()
+	slog.Info("Running task", "task", t.name)
+	err := t.run(runCtx)
+	duration := time.Since(started)
+	stop()
+	cancel()
+
+	if err != nil {
+		slog.Error("Task failed", "task", t.name, "duration", duration, "error", err)
+	} else {
+		slog.Info("Task finished", "task", t.name, "duration", duration)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.running = false
+	t.lastRunAt = &started
+	t.lastDuration = duration
+	t.lastError = nil
+	if err != nil {
+		message := err.Error()
+		t.lastError = &message
+	}
+	return err
+}
+
+// find returns the task named name, or nil if there is none.
+func (s *Scheduler) find(name string) *task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// status returns the status of t. The caller must hold s.mu.
+func (s *Scheduler) status(t *task) TaskStatus {
+	status := TaskStatus{
+		Name:           t.name,
+		Schedule:       t.schedule,
+		Running:        t.running,
+		LastRunAt:      t.lastRunAt,
+		LastDurationMs: t.lastDuration.Milliseconds(),
+		LastError:      t.lastError,
+	}
+	if t.entryID != 0 {
+		if next := s.cron.Entry(t.entryID).Next; !next.IsZero() {
+			status.NextRunAt = &next
+		}
+	}
+	return status
+}
@@ -0,0 +1,94 @@
+package seeds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/shopspring/decimal"
+)
+
+// FakePassword is the password of the users generated by Fake.
+const FakePassword = "password123"
+
+// FakeOptions sizes the data generated by Fake.
+type FakeOptions struct {
+	Users    int
+	Posts    int
+	Products int
+	Seed     int64 // Seed of the generator, to generate the same data again; 0 picks a random one
+}
+
+// Fake generates realistic fixtures for load and UI testing: users of the "user" role, posts
+// written by them and products. Emails are under example.com and every user logs in with
+// FakePassword. Posts get a few tags each and most are published within the last year; the
+// rest are drafts. Names are unique among the generated records, but may match those of earlier
+// runs with another seed, which Apply then skips.
+func Fake(opts FakeOptions) (*Fixtures, error) {
+	if opts.Users < 0 || opts.Posts < 0 || opts.Products < 0 {
+		return nil, errors.New("the numbers of records to generate must not be negative")
+	}
+	if opts.Posts > 0 && opts.Users == 0 {
+		return nil, errors.New("posts need at least one user to be written by")
+	}
+
+	faker := gofakeit.New(opts.Seed)
+	fixtures := &Fixtures{}
+
+	for i := 0; i < opts.Users; i++ {
+		first, last := faker.FirstName(), faker.LastName()
+		handle := strings.ToLower(first + "." + last)
+		fixtures.Users = append(fixtures.Users, UserFixture{
+			Username: fmt.Sprintf("%s %s", first, last),
+			Email:    fmt.Sprintf("%s.%d@example.com", handle, i+1), // Numbered, as names repeat
+			Password: FakePassword,
+			Role:     "user",
+		})
+	}
+
+	tags := make([]string, 20) // A small pool, so tags are shared by many posts
+	for i := range tags {
+		tags[i] = strings.ToLower(faker.HackerNoun())
+	}
+	now := time.Now()
+	titles := map[string]bool{}
+	for i := 0; i < opts.Posts; i++ {
+		post := PostFixture{
+			Title:   unique(titles, strings.TrimSuffix(faker.Sentence(faker.Number(3, 8)), ".")),
+			Content: faker.Paragraph(faker.Number(2, 6), faker.Number(3, 6), 15, "\n\n"),
+			Author:  fixtures.Users[faker.Number(0, len(fixtures.Users)-1)].Email,
+		}
+		for j := faker.Number(0, 3); j > 0; j-- {
+			post.Tags = append(post.Tags, faker.RandomString(tags))
+		}
+		if faker.Number(1, 10) > 2 { // 80% published
+			publishedAt := faker.DateRange(now.AddDate(-1, 0, 0), now)
+			post.PublishedAt = &publishedAt
+		}
+		fixtures.Posts = append(fixtures.Posts, post)
+	}
+
+	names := map[string]bool{}
+	for i := 0; i < opts.Products; i++ {
+		fixtures.Products = append(fixtures.Products, ProductFixture{
+			Name:        unique(names, faker.ProductName()),
+			Description: faker.Sentence(faker.Number(8, 20)),
+			Price:       decimal.NewFromFloat(faker.Price(1, 500)).Round(2),
+			Stock:       faker.Number(0, 200),
+			Category:    faker.ProductCategory(),
+		})
+	}
+	return fixtures, nil
+}
+
+// unique returns name, numbered if it is in seen already, and adds it to seen.
+func unique(seen map[string]bool, name string) string {
+	candidate := name
+	for n := 2; seen[candidate]; n++ {
+		candidate = fmt.Sprintf("%s %d", name, n)
+	}
+	seen[candidate] = true
+	return candidate
+}
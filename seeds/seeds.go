@@ -0,0 +1,370 @@
+// Package seeds loads fixture files with roles, users, posts and products and inserts the
+// records that don't exist yet, so seeding can run on every start without duplicating data.
+package seeds
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// fixtureFiles holds the built-in fixtures, one directory per environment.
+//
+//go:embed fixtures/*/*
+var fixtureFiles embed.FS
+
+// RoleFixture is a role to seed, identified by its name.
+type RoleFixture struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// UserFixture is a user to seed, identified by its email.
+type UserFixture struct {
+	Username string `json:"username" yaml:"username"`
+	Email    string `json:"email" yaml:"email"`
+	Password string `json:"password" yaml:"password"` // Plain text; hashed before it is stored
+	Role     string `json:"role" yaml:"role"`         // Name of the user's role
+}
+
+// PostFixture is a post to seed, identified by its title.
+type PostFixture struct {
+	Title       string     `json:"title" yaml:"title"`
+	Content     string     `json:"content" yaml:"content"` // Markdown source
+	Author      string     `json:"author" yaml:"author"`   // Email of the user the post is written by
+	Tags        []string   `json:"tags" yaml:"tags"`
+	PublishedAt *time.Time `json:"published_at" yaml:"published_at"` // Nil for drafts
+}
+
+// ProductFixture is a product to seed, identified by its name.
+type ProductFixture struct {
+	Name        string          `json:"name" yaml:"name"`
+	Description string          `json:"description" yaml:"description"`
+	Price       decimal.Decimal `json:"price" yaml:"price"`
+	Stock       int             `json:"stock" yaml:"stock"`
+	Category    string          `json:"category" yaml:"category"`
+}
+
+// Fixtures are the records seeded for one environment, or from one file (see LoadFile).
+type Fixtures struct {
+	Roles    []RoleFixture    `json:"roles" yaml:"roles"`
+	Users    []UserFixture    `json:"users" yaml:"users"`
+	Posts    []PostFixture    `json:"posts" yaml:"posts"`
+	Products []ProductFixture `json:"products" yaml:"products"`
+}
+
+// Run loads the fixtures of env and seeds them. See Load for where the fixtures are read from.
+// Users and products are seeded into the tenant of ctx (see tenancy.ID).
+func Run(ctx context.Context, db *database.DB, env, dir string) error {
+	fixtures, err := Load(env, dir)
+	if err != nil {
+		return err
+	}
+	return Apply(ctx, db, fixtures)
+}
+
+// RunFile loads the fixtures of file and seeds them, see LoadFile.
+func RunFile(ctx context.Context, db *database.DB, file string) error {
+	fixtures, err := LoadFile(file)
+	if err != nil {
+		return err
+	}
+	return Apply(ctx, db, fixtures)
+}
+
+// Load reads the fixtures of env from dir/<env>, or from the built-in fixtures when dir is empty.
+// Each kind of record lives in its own file, roles, users, posts and products, with a .yaml,
+// .yml or .json extension; missing files are skipped. ${VAR} references in the files are
+// expanded from the environment before they are parsed.
+func Load(env, dir string) (*Fixtures, error) {
+	var fsys fs.FS
+	if dir == "" {
+		sub, err := fs.Sub(fixtureFiles, "fixtures")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	} else {
+		fsys = os.DirFS(dir)
+	}
+
+	if _, err := fs.Stat(fsys, env); err != nil {
+		return nil, fmt.Errorf("no fixtures for environment %q: %w", env, err)
+	}
+
+	fixtures := &Fixtures{}
+	if err := loadFile(fsys, env, "roles", &fixtures.Roles); err != nil {
+		return nil, err
+	}
+	if err := loadFile(fsys, env, "users", &fixtures.Users); err != nil {
+		return nil, err
+	}
+	if err := loadFile(fsys, env, "posts", &fixtures.Posts); err != nil {
+		return nil, err
+	}
+	if err := loadFile(fsys, env, "products", &fixtures.Products); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// loadFile decodes the first of env/name.yaml, env/name.yml and env/name.json that exists into out.
+func loadFile(fsys fs.FS, env, name string, out interface{}) error {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		file := path.Join(env, name+ext)
+		data, err := fs.ReadFile(fsys, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read fixture file %s: %w", file, err)
+		}
+
+		return decode(file, data, out)
+	}
+	return nil
+}
+
+// LoadFile reads fixtures of every kind from a single .yaml, .yml or .json file, with the
+// records under the keys roles, users, posts and products, e.g., for a demo environment.
+// ${VAR} references are expanded as in Load.
+func LoadFile(file string) (*Fixtures, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file %s: %w", file, err)
+	}
+	fixtures := &Fixtures{}
+	if err := decode(file, data, fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// decode expands the ${VAR} references in the fixture file data and parses it into out, as
+// JSON or YAML depending on the extension of its name.
+func decode(file string, data []byte, out interface{}) error {
+	data = []byte(os.ExpandEnv(string(data)))
+	var err error
+	if filepath.Ext(file) == ".json" {
+		err = json.Unmarshal(data, out)
+	} else {
+		err = yaml.Unmarshal(data, out)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse fixture file %s: %w", file, err)
+	}
+	return nil
+}
+
+// Apply inserts the fixtures that don't exist yet; existing records are left untouched.
+// Roles are seeded first so users can reference them by name, and users before the posts
+// they wrote. Users and products are inserted in bulk (see database.DB.BulkInsert), so large
+// generated fixtures (see Fake) are seeded quickly.
+func Apply(ctx context.Context, db *database.DB, fixtures *Fixtures) error {
+	for _, role := range fixtures.Roles {
+		if err := seedRole(ctx, db, role); err != nil {
+			return err
+		}
+	}
+	if err := seedUsers(ctx, db, fixtures.Users); err != nil {
+		return err
+	}
+	for _, post := range fixtures.Posts {
+		if err := seedPost(ctx, db, post); err != nil {
+			return err
+		}
+	}
+	return seedProducts(ctx, db, fixtures.Products)
+}
+
+// existingBatch is the number of keys looked up at once by existing.
+const existingBatch = 500
+
+// existing returns which of keys exist, looked up by query, whose %s is replaced with the
+// placeholders of an IN list of keys following args.
+func existing(ctx context.Context, db *database.DB, query string, args []interface{}, keys []string) (map[string]bool, error) {
+	found := map[string]bool{}
+	for start := 0; start < len(keys); start += existingBatch {
+		batch := keys[start:min(start+existingBatch, len(keys))]
+		batchArgs := append([]interface{}(nil), args...)
+		for _, key := range batch {
+			batchArgs = append(batchArgs, key)
+		}
+		rows, err := db.QueryContext(ctx, fmt.Sprintf(query, database.Placeholders(len(args)+1, len(batch))), batchArgs...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			found[key] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+func seedRole(ctx context.Context, db *database.DB, role RoleFixture) error {
+	var existingRoleID string
+	// Trashed roles count as existing; their name stays reserved until they are purged
+	err := db.QueryRowContext(ctx, "SELECT id FROM roles WHERE name = $1", role.Name).Scan(&existingRoleID)
+	if err == nil {
+		slog.Info("Role already exists", "role_name", role.Name, "role_id", existingRoleID)
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check for existing role %s: %w", role.Name, err)
+	}
+
+	newRoleID := uuid.New().String()
+	now := time.Now()
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO roles (id, name, description, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)",
+		newRoleID, role.Name, role.Description, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert role %s: %w", role.Name, err)
+	}
+	slog.Info("Role seeded successfully", "role_name", role.Name, "role_id", newRoleID)
+	return nil
+}
+
+// seedUsers inserts the users that don't exist yet, in bulk.
+func seedUsers(ctx context.Context, db *database.DB, users []UserFixture) error {
+	emails := make([]string, len(users))
+	for i, user := range users {
+		if user.Email == "" || user.Password == "" {
+			return fmt.Errorf("user fixture %q needs an email and a password", user.Username)
+		}
+		emails[i] = user.Email
+	}
+	seeded, err := existing(ctx, db, "SELECT email FROM users WHERE email IN (%s)", nil, emails)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing users: %w", err)
+	}
+
+	roleIDs := map[string]string{}
+	now := time.Now()
+	var rows [][]interface{}
+	for _, user := range users {
+		if seeded[user.Email] {
+			slog.Info("User already exists", "email", user.Email)
+			continue
+		}
+		seeded[user.Email] = true // Listed twice, seeded once
+
+		roleID, ok := roleIDs[user.Role]
+		if !ok {
+			err := db.QueryRowContext(ctx, "SELECT id FROM roles WHERE name = $1 AND "+database.NotDeleted(""), user.Role).Scan(&roleID)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("role '%s' of user %s not found", user.Role, user.Email)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to retrieve role '%s': %w", user.Role, err)
+			}
+			roleIDs[user.Role] = roleID
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), config.AppConfig.BcryptCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password for user %s: %w", user.Email, err)
+		}
+		rows = append(rows, []interface{}{uuid.New().String(), tenancy.ID(ctx), user.Username, user.Email, string(hashedPassword), roleID, now, now})
+	}
+
+	n, err := db.BulkInsert(ctx, "users", []string{"id", "tenant_id", "username", "email", "password_hash", "role_id", "created_at", "updated_at"}, rows)
+	if err != nil {
+		return fmt.Errorf("failed to insert users: %w", err)
+	}
+	if n > 0 {
+		slog.Info("Users seeded successfully", "count", n)
+	}
+	return nil
+}
+
+func seedPost(ctx context.Context, db *database.DB, post PostFixture) error {
+	var existingPostID string
+	err := db.QueryRowContext(ctx, "SELECT id FROM posts WHERE title = $1 AND tenant_id = $2", post.Title, tenancy.ID(ctx)).Scan(&existingPostID)
+	if err == nil {
+		slog.Info("Post already exists", "post_title", post.Title, "post_id", existingPostID)
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check for existing post %s: %w", post.Title, err)
+	}
+
+	var authorID string
+	err = db.QueryRowContext(ctx, "SELECT id FROM users WHERE email = $1 AND "+database.NotDeleted(""), post.Author).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("author %s of post %s not found", post.Author, post.Title)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve author %s: %w", post.Author, err)
+	}
+
+	// Created through the service, which also links the tags
+	newPost := &models.Post{UserID: authorID, Title: post.Title, Content: post.Content, Tags: post.Tags, PublishedAt: post.PublishedAt}
+	if err := services.NewPostService(db).CreatePost(ctx, newPost); err != nil {
+		return fmt.Errorf("failed to insert post %s: %w", post.Title, err)
+	}
+	slog.Info("Post seeded successfully", "post_title", post.Title, "post_id", newPost.ID)
+	return nil
+}
+
+// seedProducts inserts the products that don't exist yet in the tenant of ctx, in bulk.
+func seedProducts(ctx context.Context, db *database.DB, products []ProductFixture) error {
+	names := make([]string, len(products))
+	for i, product := range products {
+		names[i] = product.Name
+	}
+	seeded, err := existing(ctx, db, "SELECT name FROM products WHERE tenant_id = $1 AND name IN (%s)", []interface{}{tenancy.ID(ctx)}, names)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing products: %w", err)
+	}
+
+	now := time.Now()
+	var rows [][]interface{}
+	for _, product := range products {
+		if seeded[product.Name] {
+			slog.Info("Product already exists", "product_name", product.Name)
+			continue
+		}
+		seeded[product.Name] = true // Listed twice, seeded once
+		rows = append(rows, []interface{}{uuid.New().String(), tenancy.ID(ctx), product.Name, product.Description, product.Price, product.Stock, product.Category, now, now})
+	}
+
+	n, err := db.BulkInsert(ctx, "products", []string{"id", "tenant_id", "name", "description", "price", "stock", "category", "created_at", "updated_at"}, rows)
+	if err != nil {
+		return fmt.Errorf("failed to insert products: %w", err)
+	}
+	if n > 0 {
+		slog.Info("Products seeded successfully", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+// Package server builds the HTTP server of the application: the Fiber app with its
+// middleware and routes, and the storage and recurring tasks it relies on.
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/anpsniper/anpbayu-be/bruteforce"
+	"github.com/anpsniper/anpbayu-be/cache"
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/controllers"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/geoip"
+	"github.com/anpsniper/anpbayu-be/jobs"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/notifications"
+	"github.com/anpsniper/anpbayu-be/quota"
+	"github.com/anpsniper/anpbayu-be/realtime"
+	"github.com/anpsniper/anpbayu-be/routes"
+	"github.com/anpsniper/anpbayu-be/scheduler"
+	"github.com/anpsniper/anpbayu-be/services"
+	"github.com/anpsniper/anpbayu-be/sms"
+	"github.com/anpsniper/anpbayu-be/storage"
+)
+
+// New returns the Fiber app with every middleware and route registered, serving db (pool
+// only provides its statistics). The tasks of sched can be run on demand by admins and
+// notifications are pushed through hub. The Redis connection for rate limits and caching is
+// returned as well when configured, for the caller to close; it is nil otherwise.
+func New(db *database.DB, pool *pgxpool.Pool, sched *scheduler.Scheduler, hub *realtime.Hub) (*fiber.App, *cache.RedisStorage, error) {
+	fileStorage, err := NewFileStorage(&config.AppConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up file storage: %w", err)
+	}
+
+	// 4. Initialize Fiber app
+	// The body limit (BODY_LIMIT_MB) must leave room for the largest upload, see config.Validate.
+	app := fiber.New(fiber.Config{
+		BodyLimit:    int(config.AppConfig.BodyLimit),
+		ErrorHandler: controllers.ErrorHandler, // Maps the domain errors of the services to HTTP statuses
+	})
+
+	// Rate limit counters are kept in memory, or in Redis to share them between instances (RATE_LIMIT_STORE).
+	// Read-heavy lists are optionally cached in Redis too (CACHE_STORE).
+	var rateLimitStore fiber.Storage
+	var redisStore *cache.RedisStorage
+	var queryCache *cache.QueryCache
+	if config.AppConfig.RateLimitStore == "redis" || config.AppConfig.CacheStore == "redis" {
+		redisStore, err = cache.NewRedisStorage(config.AppConfig.RedisURL, "anpbayu:"+config.AppConfig.AppEnv+":")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+	}
+	if config.AppConfig.RateLimitStore == "redis" {
+		rateLimitStore = redisStore
+	}
+	if config.AppConfig.CacheStore == "redis" {
+		queryCache = cache.NewQueryCache(redisStore, config.AppConfig.CacheTTL)
+	}
+	// The roles, read by every user list and login, are kept in the memory of each instance (ROLE_CACHE_TTL_SECONDS)
+	roleCache := services.NewRoleCache(db, config.AppConfig.RoleCacheTTL)
+
+	// 5. Configure CORS and rate limiting from the runtime settings. Both are rebuilt when the
+	// settings are reloaded, on SIGHUP or through POST /api/admin/config/reload.
+	corsMiddleware := middleware.NewSwappable(middleware.CORS(config.Current()))
+	rateLimitMiddleware := middleware.NewSwappable(middleware.RateLimit(config.Current(), rateLimitStore))
+	loginRateLimitMiddleware := middleware.NewSwappable(middleware.LoginRateLimit(config.Current(), rateLimitStore))
+	adminWriteRateLimitMiddleware := middleware.NewSwappable(middleware.AdminWriteRateLimit(config.Current(), rateLimitStore))
+	ipFilterMiddleware := middleware.NewSwappable(middleware.IPFilter(config.Current().IPAllowlist, config.Current().IPDenylist))
+	config.OnReload(func(settings *config.Runtime) {
+		corsMiddleware.Swap(middleware.CORS(settings))
+		ipFilterMiddleware.Swap(middleware.IPFilter(settings.IPAllowlist, settings.IPDenylist))
+		rateLimitMiddleware.Swap(middleware.RateLimit(settings, rateLimitStore))
+		loginRateLimitMiddleware.Swap(middleware.LoginRateLimit(settings, rateLimitStore))
+		adminWriteRateLimitMiddleware.Swap(middleware.AdminWriteRateLimit(settings, rateLimitStore))
+	})
+
+	// Give every request an ID and a logger carrying it (X-Request-ID), see logging.FromContext
+	app.Use(middleware.RequestLogger())
+	app.Use(middleware.AccessLog(config.AppConfig.AccessLogSamplePercent, config.AppConfig.AccessLogExclude))
+	// Report 5xx responses, and answer panics with a JSON 500 after logging and reporting them
+	app.Use(middleware.ReportErrors())
+	app.Use(middleware.Recover())
+
+	// Take the client IP from X-Forwarded-For behind TRUSTED_PROXIES, and refuse the networks
+	// outside IP_ALLOWLIST or inside IP_DENYLIST, on every route
+	trustedProxies, _ := config.ParseNetworks(config.AppConfig.TrustedProxies) // Validated by config.LoadConfig
+	app.Use(middleware.RealIP(trustedProxies))
+
+	// 6. Health Check Endpoints (publicly accessible)
+	// /health pings the database and returns 503 when it is unreachable. The Kubernetes probes
+	// report whether the process is alive (/healthz), its dependencies are ready (/readyz) and
+	// its startup has finished (/startupz). They are registered before the IP filter and the
+	// rate limit, which would otherwise turn away load balancers and the kubelet.
+	healthController := controllers.NewHealthController(db, pool, redisStore)
+	app.Get("/health", healthController.GetHealth)
+	app.Get("/healthz", healthController.GetLiveness)
+	app.Get("/readyz", healthController.GetReadiness)
+	app.Get("/startupz", healthController.GetStartup)
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		healthController.MarkStarted()
+		return nil
+	})
+
+	app.Use(ipFilterMiddleware.Handler())
+
+	app.Use(corsMiddleware.Handler())
+
+	// Answer in the language of the Accept-Language header (en or id), see i18n
+	app.Use(middleware.Localize())
+
+	// Compress large responses, e.g., of list and export endpoints (COMPRESSION_LEVEL, COMPRESSION_MIN_SIZE_BYTES)
+	app.Use(middleware.Compress(config.AppConfig.CompressionLevel, config.AppConfig.CompressionMinSize))
+
+	// Answer GET requests with 304 Not Modified when the client's ETag (If-None-Match) is still current
+	app.Use(middleware.ETag())
+	app.Use(rateLimitMiddleware.Handler())
+
+	// Bound the database work of every request; controllers pass ctx.UserContext() to the services
+	app.Use(middleware.QueryDeadline(config.AppConfig.DBQueryTimeout))
+
+	// Runtime metrics such as database pool acquire counts and wait durations, and the expired
+	// rows deleted by the session-cleanup task. They tell a lot about the internals, so scrapers
+	// must send METRICS_TOKEN; without one, they are only served outside production.
+	// /metrics/prometheus serves them in the Prometheus format, with the durations of the
+	// database statements by operation.
+	metricsController := controllers.NewMetricsController(db.DB, pool, jobs.Cleanups, jobs.Retention)
+	if err := database.RegisterMetrics(prometheus.DefaultRegisterer, db, pool); err != nil {
+		return nil, nil, fmt.Errorf("failed to register database metrics: %w", err)
+	}
+	switch {
+	case config.AppConfig.MetricsToken != "":
+		metricsAuth := middleware.StaticToken(config.AppConfig.MetricsToken)
+		app.Get("/metrics", metricsAuth, metricsController.GetMetrics)
+		app.Get("/metrics/prometheus", metricsAuth, metricsController.GetPrometheusMetrics)
+	case !config.AppConfig.IsProduction():
+		app.Get("/metrics", metricsController.GetMetrics)
+		app.Get("/metrics/prometheus", metricsController.GetPrometheusMetrics)
+	}
+
+	// Uploaded files (post attachments, avatars and product images) are kept in UPLOAD_DIR or an
+	// S3 bucket (STORAGE_DRIVER) and downloaded from presigned URLs. Local files are served
+	// here, to requests carrying a valid signature.
+	if local, ok := fileStorage.(*storage.LocalStorage); ok {
+		app.Use("/uploads", middleware.SignedDownloads("/uploads", local))
+		app.Static("/uploads", config.AppConfig.UploadDir)
+	}
+
+	// Fail fast with 503 while the database is down (registered after the health checks and /metrics, which report it)
+	app.Use(middleware.DatabaseCircuit(db.Breaker()))
+
+	// Scope the request to the tenant of its subdomain (e.g., acme.example.com), see tenancy
+	app.Use(middleware.ResolveTenantFromSubdomain(services.NewTenantService(db), config.AppConfig.TenantBaseDomain))
+
+	// Initialize UserService and AuthController. Logins create sessions, which the JWT
+	// middleware checks below. Login codes are sent by SMS through Twilio (TWILIO_ACCOUNT_SID).
+	userService := services.NewUserService(db, roleCache)
+	sessionService := services.NewSessionService(db)
+	otpService := services.NewOTPService(db, config.AppConfig.OTPTTL, config.AppConfig.OTPMaxAttempts)
+	smsSender := sms.NewTwilioSender(config.AppConfig.TwilioAccountSID, config.AppConfig.TwilioAuthToken, config.AppConfig.TwilioFrom)
+
+	// Failed logins are counted per client IP and account, along with the rate limit counters
+	// (RATE_LIMIT_STORE), and the sources failing too often are locked out for a while.
+	var bruteForceStore bruteforce.Store = bruteforce.NewMemoryStore()
+	if config.AppConfig.RateLimitStore == "redis" {
+		bruteForceStore = bruteforce.NewRedisStore(redisStore.Client(), "anpbayu:"+config.AppConfig.AppEnv+":bruteforce:")
+	}
+	lockout := bruteforce.Policy{
+		Window:     config.AppConfig.BruteForceWindow,
+		Lockout:    config.AppConfig.BruteForceLockout,
+		MaxLockout: config.AppConfig.BruteForceMaxLockout,
+	}
+	ipPolicy, accountPolicy := lockout, lockout
+	ipPolicy.Threshold = config.AppConfig.BruteForceIPThreshold
+	accountPolicy.Threshold = config.AppConfig.BruteForceAccountThreshold
+	guard := bruteforce.NewGuard(bruteForceStore, ipPolicy, accountPolicy)
+
+	// Request quotas per user and hour or day (QUOTA_PER_HOUR, QUOTA_PER_DAY) are counted along
+	// with the rate limit counters too, and rebuilt like the rate limits on reloads.
+	var quotaStore quota.Store = quota.NewMemoryStore()
+	if config.AppConfig.RateLimitStore == "redis" {
+		quotaStore = quota.NewRedisStore(redisStore.Client(), "anpbayu:"+config.AppConfig.AppEnv+":quota:")
+	}
+	quotaTracker := quota.NewTracker(quotaStore)
+	quotaMiddleware := middleware.NewSwappable(middleware.Quota(config.Current(), quotaTracker))
+	config.OnReload(func(settings *config.Runtime) {
+		quotaMiddleware.Swap(middleware.Quota(settings, quotaTracker))
+	})
+
+	// Logins from a device or country the user didn't log in from before are alerted to them,
+	// in the app and by email (SMTP_HOST). Countries are looked up in GEOIP_DB_PATH.
+	locator, err := geoip.Open(config.AppConfig.GeoIPDBPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	notificationService := services.NewNotificationService(db, notifications.NewRealtimeChannel(hub), notifications.NewEmailChannel(
+		config.AppConfig.SMTPHost, config.AppConfig.SMTPPort, config.AppConfig.SMTPUsername, config.AppConfig.SMTPPassword, config.AppConfig.MailFrom,
+	))
+	deviceService := services.NewDeviceService(db, notificationService, locator, config.AppConfig.LoginRevokeURL)
+	// Tokens tell the frontend the permissions of the user's roles, so it can hide what the user
+	// can't do; the API still checks permissions against the database (see middleware.HasPermission).
+	// The claims of JWT_EXTRA_CLAIMS are added to and required of every token.
+	roleService := services.NewRoleService(db)
+	err = middleware.RegisterClaim(middleware.Claim{Name: "permissions", Value: func(ctx context.Context, subject middleware.TokenSubject) (interface{}, error) {
+		return roleService.GetPermissionsOfRoles(ctx, subject.Roles)
+	}})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := middleware.RegisterStaticClaims(config.AppConfig.JWTExtraClaims); err != nil {
+		return nil, nil, err
+	}
+	authController := controllers.NewAuthController(userService, sessionService, otpService, smsSender, config.AppConfig.OTPTTL, guard, deviceService, services.NewFailedLoginService(db))
+
+	// 7. Authentication Login Route (publicly accessible, handled by AuthController)
+	// This replaces the manual login handler that was here.
+	app.Post("/login", loginRateLimitMiddleware.Handler(), authController.Login) // Frontend should hit this endpoint directly
+	// SMS login: a one-time code is sent to the phone number of the user, then exchanged for a token
+	app.Post("/login/otp", loginRateLimitMiddleware.Handler(), authController.RequestOTP)
+	app.Post("/login/otp/verify", loginRateLimitMiddleware.Handler(), authController.LoginWithOTP)
+	// "This wasn't me" link of the alerts of logins from new devices or countries
+	app.Post("/login/revoke", loginRateLimitMiddleware.Handler(), authController.RevokeLogin)
+
+	// While maintenance mode is enabled, only admins are served (see PUT /api/admin/maintenance).
+	// The state is shared through the database and re-read every few seconds.
+	maintenanceMode := middleware.NewMaintenanceMode(services.NewSettingService(db), 5*time.Second)
+
+	// Public post feed (publicly accessible, only published posts are returned)
+	feedController := controllers.NewFeedController(services.NewPostService(db))
+	app.Get("/feed", maintenanceMode.Handler(), feedController.GetFeed)
+	app.Get("/feed.rss", maintenanceMode.Handler(), feedController.GetRSS)
+	app.Get("/feed.atom", maintenanceMode.Handler(), feedController.GetAtom)
+
+	// Payment provider webhooks (publicly accessible, verified by provider signatures)
+	routes.SetupWebhookRoutes(app, db)
+
+	// OpenAPI spec and Swagger UI (publicly accessible, DOCS_ENABLED)
+	routes.SetupDocsRoutes(app)
+
+	// Browsers can't send the Authorization header when opening WebSockets or event streams, so
+	// /ws and /api/events also take the token from ?access_token=
+	app.Use(middleware.TokenFromQuery("access_token", "/ws", "/api/events"))
+
+	// 8. JWT Middleware (Applies to all routes defined AFTER this point)
+	// This middleware will protect all subsequent routes unless explicitly overridden.
+	app.Use(middleware.JWT(sessionService))
+
+	// Authenticated requests act for the tenant of their token
+	app.Use(middleware.ResolveTenantFromJWT())
+	app.Use(middleware.LogAuthenticatedUser())
+	app.Use(middleware.IdentifyActor())
+	app.Use(maintenanceMode.Handler())
+
+	// Stricter limit for administrators' changes (ADMIN_WRITE_RATE_LIMIT_PER_MINUTE), on top of the global one
+	app.Use(adminWriteRateLimitMiddleware.Handler())
+	app.Use(quotaMiddleware.Handler())
+
+	// 9. Setup all API routes (these will now be protected by the JWT middleware,
+	// and some will have additional role-based checks via `middleware.HasRole`).
+	// The /api/auth/logout route will also be handled by the authController within SetupAPIRoutes.
+	routes.SetupAPIRoutes(app, db, queryCache, roleCache, fileStorage, sched, hub, maintenanceMode, guard, quotaTracker, authController)
+	return app, redisStore, nil
+}
+
+// NewFileStorage returns the storage of uploaded files selected by STORAGE_DRIVER. Local
+// download URLs are signed with the JWT secret.
+func NewFileStorage(cfg *config.Config) (storage.Storage, error) {
+	if cfg.StorageDriver == "s3" {
+		return storage.NewS3Storage(storage.S3Options{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+			URLTTL:          cfg.StorageURLTTL,
+			Timeout:         time.Minute, // Uploads of UPLOAD_MAX_SIZE_MB must fit in
+		})
+	}
+	return storage.NewLocalStorage(cfg.UploadDir, "/uploads", cfg.JWTSecret, cfg.StorageURLTTL), nil
+}
+
+// NewScheduler returns a scheduler with the recurring tasks on their schedules of the config.
+// Runs taking longer than 10 minutes are aborted. Notifications are pushed through hub.
+func NewScheduler(db *database.DB, hub *realtime.Hub) (*scheduler.Scheduler, error) {
+	cfg := config.AppConfig
+	maintenanceService := services.NewMaintenanceService(db)
+	notificationService := services.NewNotificationService(db, notifications.NewRealtimeChannel(hub), notifications.NewEmailChannel(
+		cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom,
+	))
+
+	sched := scheduler.New(10 * time.Minute)
+	tasks := []struct {
+		name     string
+		schedule string
+		run      scheduler.Func
+	}{
+		{"session-cleanup", cfg.ScheduleSessionCleanup, jobs.ExpiredCleanup(maintenanceService, jobs.Cleanups)},
+		{"log-retention", cfg.ScheduleLogRetention, jobs.LogRetention(maintenanceService, cfg.LogRetention, cfg.LogRetentionMode, jobs.Retention)},
+		{"post-publishing", cfg.SchedulePostPublishing, jobs.PostPublishing(services.NewPostService(db), notificationService)},
+		{"low-stock-check", cfg.ScheduleLowStockCheck, jobs.LowStockCheck(notificationService)},
+	}
+	for _, task := range tasks {
+		if err := sched.Add(task.name, task.schedule, task.run); err != nil {
+			return nil, fmt.Errorf("invalid schedule of task %s: %w", task.name, err)
+		}
+	}
+	return sched, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// AnalyticsServiceInterface defines the methods that any analytics service implementation must provide.
+type AnalyticsServiceInterface interface {
+	GetLoginAnalytics(ctx context.Context, from, to time.Time, groupBy string) (*models.LoginAnalytics, error)
+}
+
+// AnalyticsService computes usage statistics for admins.
+type AnalyticsService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewAnalyticsService creates and returns a new AnalyticsService instance using the given connection pool.
+func NewAnalyticsService(db *database.DB) *AnalyticsService {
+	return &AnalyticsService{db: db}
+}
+
+// loginPeriod accumulates the logins of a period.
+type loginPeriod struct {
+	logins        int
+	users         map[string]bool
+	sessions      int
+	sessionsTotal time.Duration
+}
+
+// GetLoginAnalytics summarizes the logins of the users of the request's tenant from from
+// (inclusive) to to (exclusive), grouped by models.AnalyticsGroupDay or AnalyticsGroupWeek.
+// The logins are aggregated here rather than in SQL, as the dialects don't share date functions;
+// callers bound the range.
+func (s *AnalyticsService) GetLoginAnalytics(ctx context.Context, from, to time.Time, groupBy string) (*models.LoginAnalytics, error) {
+	from, to = from.UTC(), to.UTC()
+	if groupBy != models.AnalyticsGroupDay && groupBy != models.AnalyticsGroupWeek {
+		return nil, invalidf("unsupported grouping: %s", groupBy)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT l.user_id, l.login_at, l.logout_at
+		FROM user_logs l
+		JOIN users u ON u.id = l.user_id
+		WHERE u.tenant_id = $1 AND l.login_at >= $2 AND l.login_at < $3`,
+		tenancy.ID(ctx), from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user logs: %w", err)
+	}
+	defer rows.Close()
+
+	total := &loginPeriod{users: map[string]bool{}}
+	periods := map[time.Time]*loginPeriod{}
+	for rows.Next() {
+		var userID string
+		var loginAt time.Time
+		var logoutAt sql.NullTime
+		if err := rows.Scan(&userID, &loginAt, &logoutAt); err != nil {
+			logging.FromContext(ctx).Error("Error scanning user log row", "error", err)
+			return nil, fmt.Errorf("failed to scan user log: %w", err)
+		}
+
+		start := periodStart(loginAt.UTC(), groupBy)
+		period := periods[start]
+		if period == nil {
+			period = &loginPeriod{users: map[string]bool{}}
+			periods[start] = period
+		}
+		for _, p := range []*loginPeriod{total, period} {
+			p.logins++
+			p.users[userID] = true
+			if logoutAt.Valid {
+				p.sessions++
+				p.sessionsTotal += logoutAt.Time.Sub(loginAt)
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user log rows: %w", err)
+	}
+
+	analytics := &models.LoginAnalytics{
+		From:                  from,
+		To:                    to,
+		GroupBy:               groupBy,
+		Logins:                total.logins,
+		ActiveUsers:           len(total.users),
+		AverageSessionSeconds: total.averageSessionSeconds(),
+		Periods:               []models.LoginPeriodStat{},
+	}
+	for start := periodStart(from, groupBy); start.Before(to); start = nextPeriod(start, groupBy) {
+		stat := models.LoginPeriodStat{Start: start}
+		if period := periods[start]; period != nil {
+			stat.Logins = period.logins
+			stat.ActiveUsers = len(period.users)
+			stat.AverageSessionSeconds = period.averageSessionSeconds()
+		}
+		analytics.Periods = append(analytics.Periods, stat)
+	}
+	return analytics, nil
+}
+
+// averageSessionSeconds returns the average duration of the sessions of p that were logged
+// out, nil if there are none.
+func (p *loginPeriod) averageSessionSeconds() *float64 {
+	if p.sessions == 0 {
+		return nil
+	}
+	average := p.sessionsTotal.Seconds() / float64(p.sessions)
+	return &average
+}
+
+// periodStart returns the start of the day or ISO week of t, in UTC.
+func periodStart(t time.Time, groupBy string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if groupBy == models.AnalyticsGroupWeek {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)) // Back to Monday
+	}
+	return day
+}
+
+// nextPeriod returns the start of the day or week after the one starting at start.
+func nextPeriod(start time.Time, groupBy string) time.Time {
+	if groupBy == models.AnalyticsGroupWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
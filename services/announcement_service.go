@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// AnnouncementServiceInterface defines the methods that any announcement service implementation must provide.
+type AnnouncementServiceInterface interface {
+	CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error
+}
+
+// AnnouncementService stores the announcements admins broadcast to their tenant.
+type AnnouncementService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewAnnouncementService creates and returns a new AnnouncementService instance using the given connection pool.
+func NewAnnouncementService(db *database.DB) *AnnouncementService {
+	return &AnnouncementService{db: db}
+}
+
+// CreateAnnouncement stores an announcement of the request's tenant, setting its ID and
+// creation time.
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	announcement.ID = uuid.New().String()
+	announcement.CreatedAt = time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO announcements (id, tenant_id, title, message, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		announcement.ID, tenancy.ID(ctx), announcement.Title, announcement.Message, announcement.CreatedBy, announcement.CreatedAt,
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating announcement", "title", announcement.Title, "error", err)
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return nil
+}
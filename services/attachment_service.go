@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/google/uuid"
+)
+
+// AttachmentServiceInterface defines the methods that any attachment service implementation must provide.
+type AttachmentServiceInterface interface {
+	GetAttachmentsByPostID(ctx context.Context, postID string) ([]models.Attachment, error)
+	GetAttachmentByID(ctx context.Context, id string) (*models.Attachment, error)
+	CreateAttachment(ctx context.Context, attachment *models.Attachment) error
+	DeleteAttachment(ctx context.Context, id string) error
+}
+
+// AttachmentService provides methods for attachment metadata, implementing AttachmentServiceInterface.
+// The file contents themselves are handled by a storage.Storage backend.
+type AttachmentService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewAttachmentService creates and returns a new AttachmentService instance using the given connection pool.
+func NewAttachmentService(db *database.DB) *AttachmentService {
+	return &AttachmentService{db: db}
+}
+
+// GetAttachmentsByPostID fetches all attachments of a post of the request's tenant, oldest first.
+func (s *AttachmentService) GetAttachmentsByPostID(ctx context.Context, postID string) ([]models.Attachment, error) {
+	query := `
+		SELECT id, post_id, user_id, file_name, content_type, size, storage_key, created_at
+		FROM attachments
+		WHERE post_id = $1 AND post_id IN (SELECT id FROM posts WHERE tenant_id = $2)
+		ORDER BY created_at ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, postID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		var a models.Attachment
+		err := rows.Scan(&a.ID, &a.PostID, &a.UserID, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning attachment row", "error", err)
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachment rows: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// GetAttachmentByID fetches a single attachment of a post of the request's tenant by its ID.
+func (s *AttachmentService) GetAttachmentByID(ctx context.Context, id string) (*models.Attachment, error) {
+	a := &models.Attachment{}
+	query := `
+		SELECT id, post_id, user_id, file_name, content_type, size, storage_key, created_at
+		FROM attachments
+		WHERE id = $1 AND post_id IN (SELECT id FROM posts WHERE tenant_id = $2)
+	`
+	err := s.db.QueryRowContext(ctx, query, id, tenancy.ID(ctx)).Scan(&a.ID, &a.PostID, &a.UserID, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Attachment not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching attachment", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch attachment by ID: %w", err)
+	}
+	return a, nil
+}
+
+// CreateAttachment inserts the metadata of an already stored file.
+// If ID is empty a new UUID is generated.
+func (s *AttachmentService) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+	attachment.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO attachments (id, post_id, user_id, file_name, content_type, size, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.db.ExecContext(ctx,
+		query,
+		attachment.ID,
+		attachment.PostID,
+		attachment.UserID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		attachment.CreatedAt,
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating attachment for post", "file_name", attachment.FileName, "post_id", attachment.PostID, "error", err)
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	return nil
+}
+
+// DeleteAttachment deletes an attachment's metadata by its ID.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = $1 AND post_id IN (SELECT id FROM posts WHERE tenant_id = $2)`, id, tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting attachment", "id", id, "error", err)
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("attachment with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
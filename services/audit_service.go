@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/audit"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// AuditServiceInterface defines the methods that any audit log service implementation must provide.
+type AuditServiceInterface interface {
+	GetAuditLogs(ctx context.Context, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int, int, error) // Returns entries, totalPages, totalItems
+	GetAuditLogsAfter(ctx context.Context, filter models.AuditLogFilter, after *models.Cursor, limit int) ([]models.AuditLog, *models.Cursor, error)
+}
+
+// AuditService queries the audit log. Entries are written by the services that make the
+// changes, see recordAudit.
+type AuditService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewAuditService creates and returns a new AuditService instance using the given connection pool.
+func NewAuditService(db *database.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// auditExecer runs the insert of an audit entry, on the connection pool or in the
+// transaction of the change it records.
+type auditExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordAudit records a change of an entity by the actor of ctx (see audit.WithActor) in the
+// audit log. before and after are snapshots of the entity, marshaled to JSON; before is nil
+// for creations and after for deletions. Call it within the transaction of the change, if any,
+// so both are committed together.
+func recordAudit(ctx context.Context, db auditExecer, action, entityType, entityID string, before, after interface{}) error {
+	beforeJSON, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	var actorID interface{}
+	if actor.UserID != "" {
+		actorID = actor.UserID
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO audit_logs (id, tenant_id, actor_id, action, entity_type, entity_id, before_data, after_data, ip_address, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		uuid.New().String(), tenancy.ID(ctx), actorID, action, entityType, entityID, beforeJSON, afterJSON, actor.IPAddress, actor.RequestID, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit log entry: %w", err)
+	}
+	return nil
+}
+
+// auditChange records a change that has already been made outside a transaction. A failure
+// can't undo the change, so it is logged instead of returned.
+func auditChange(ctx context.Context, db auditExecer, action, entityType, entityID string, before, after interface{}) {
+	if err := recordAudit(ctx, db, action, entityType, entityID, before, after); err != nil {
+		logging.FromContext(ctx).Error("Error recording audit log entry", "action", action, "entity_type", entityType, "entity_id", entityID, "error", err)
+	}
+}
+
+// auditSnapshot marshals a snapshot for the audit log; nil snapshots are stored as NULL.
+func auditSnapshot(snapshot interface{}) (interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetAuditLogs retrieves the audit log entries of the request's tenant matching filter, newest first.
+func (s *AuditService) GetAuditLogs(ctx context.Context, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int, int, error) {
+	entries := []models.AuditLog{}
+	var totalItems int
+
+	where, args := auditLogsWhere(ctx, filter)
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM audit_logs"+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count audit log entries: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	query := fmt.Sprintf("SELECT "+auditLogColumns+" FROM audit_logs%s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", where, len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query audit log entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning audit log row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating audit log rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return entries, totalPages, totalItems, nil
+}
+
+// GetAuditLogsAfter retrieves the audit log entries matching filter like GetAuditLogs, a page
+// of limit entries following the cursor after (the first page if nil) instead of at an offset,
+// and the cursor of the next page, nil on the last one.
+func (s *AuditService) GetAuditLogsAfter(ctx context.Context, filter models.AuditLogFilter, after *models.Cursor, limit int) ([]models.AuditLog, *models.Cursor, error) {
+	entries := []models.AuditLog{}
+
+	where, args := auditLogsWhere(ctx, filter)
+	if after != nil {
+		args = append(args, after.Time, after.ID)
+		where += fmt.Sprintf(" AND (created_at < $%d OR (created_at = $%d AND id < $%d))", len(args)-1, len(args)-1, len(args))
+	}
+
+	// One more entry than asked tells whether there's a next page
+	query := fmt.Sprintf("SELECT "+auditLogColumns+" FROM audit_logs%s ORDER BY created_at DESC, id DESC LIMIT $%d", where, len(args)+1)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit+1)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query audit log entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning audit log row", "error", err)
+			return nil, nil, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating audit log rows: %w", err)
+	}
+
+	if len(entries) <= limit {
+		return entries, nil, nil
+	}
+	entries = entries[:limit]
+	last := entries[limit-1]
+	return entries, &models.Cursor{Time: last.CreatedAt, ID: last.ID}, nil
+}
+
+const auditLogColumns = "id, tenant_id, actor_id, action, entity_type, entity_id, before_data, after_data, ip_address, request_id, created_at"
+
+// auditLogsWhere returns the WHERE clause selecting the audit log entries of the request's
+// tenant matching filter, and its arguments.
+func auditLogsWhere(ctx context.Context, filter models.AuditLogFilter) (string, []interface{}) {
+	where := " WHERE tenant_id = $1"
+	args := []interface{}{tenancy.ID(ctx)}
+	for _, condition := range []struct{ column, value string }{
+		{"actor_id", filter.ActorID},
+		{"action", filter.Action},
+		{"entity_type", filter.EntityType},
+		{"entity_id", filter.EntityID},
+	} {
+		if condition.value != "" {
+			args = append(args, condition.value)
+			where += fmt.Sprintf(" AND %s = $%d", condition.column, len(args))
+		}
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	return where, args
+}
+
+// scanAuditLog scans an audit log row selected as auditLogColumns.
+func scanAuditLog(row rowScanner) (*models.AuditLog, error) {
+	entry := &models.AuditLog{}
+	var actorID, ipAddress, requestID sql.NullString
+	var before, after []byte
+	if err := row.Scan(&entry.ID, &entry.TenantID, &actorID, &entry.Action, &entry.EntityType, &entry.EntityID, &before, &after, &ipAddress, &requestID, &entry.CreatedAt); err != nil {
+		return nil, err
+	}
+	if actorID.Valid {
+		entry.ActorID = &actorID.String
+	}
+	entry.Before, entry.After = before, after
+	entry.IPAddress, entry.RequestID = ipAddress.String, requestID.String
+	return entry, nil
+}
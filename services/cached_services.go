@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpsniper/anpbayu-be/cache"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// Cache groups of the lists served from the query cache. Every change to the records of a list
+// invalidates its group.
+const (
+	rolesCacheGroup = "roles" // Role lists
+	usersCacheGroup = "users" // User lists, which show role names too
+)
+
+// trashCacheGroups maps trash resources to the cache groups restoring their records changes.
+var trashCacheGroups = map[string][]string{
+	models.TrashResourceUsers: {usersCacheGroup},
+	models.TrashResourceRoles: {rolesCacheGroup, usersCacheGroup},
+}
+
+// listPage is a cached page of a paginated list.
+type listPage[T any] struct {
+	Items      []T
+	TotalPages int
+	TotalItems int
+}
+
+// CachedRoleService serves role lists from the query cache, and invalidates them and the role
+// cache when roles change.
+type CachedRoleService struct {
+	RoleServiceInterface
+	cache *cache.QueryCache
+	roles *RoleCache
+}
+
+// NewCachedRoleService wraps roleService with queryCache; a nil queryCache disables caching.
+// Changes to roles invalidate roleCache too.
+func NewCachedRoleService(roleService RoleServiceInterface, queryCache *cache.QueryCache, roleCache *RoleCache) *CachedRoleService {
+	return &CachedRoleService{RoleServiceInterface: roleService, cache: queryCache, roles: roleCache}
+}
+
+// GetAllRoles returns a page of roles, from the cache if possible.
+func (s *CachedRoleService) GetAllRoles(ctx context.Context, search string, page, limit int) ([]models.Role, int, int, error) {
+	key := fmt.Sprintf("list:%d:%d:%s", page, limit, search)
+	result, err := cache.Fetch(ctx, s.cache, rolesCacheGroup, key, func() (listPage[models.Role], error) {
+		roles, totalPages, totalItems, err := s.RoleServiceInterface.GetAllRoles(ctx, search, page, limit)
+		return listPage[models.Role]{Items: roles, TotalPages: totalPages, TotalItems: totalItems}, err
+	})
+	return result.Items, result.TotalPages, result.TotalItems, err
+}
+
+// CreateRole creates a role and invalidates the cached roles.
+func (s *CachedRoleService) CreateRole(ctx context.Context, role *models.Role) error {
+	if err := s.RoleServiceInterface.CreateRole(ctx, role); err != nil {
+		return err
+	}
+	s.roles.Invalidate()
+	s.cache.Invalidate(ctx, rolesCacheGroup)
+	return nil
+}
+
+// UpdateRole updates a role and invalidates the cached roles and user lists.
+func (s *CachedRoleService) UpdateRole(ctx context.Context, role *models.Role) error {
+	if err := s.RoleServiceInterface.UpdateRole(ctx, role); err != nil {
+		return err
+	}
+	s.roles.Invalidate()
+	s.cache.Invalidate(ctx, rolesCacheGroup, usersCacheGroup)
+	return nil
+}
+
+// DeleteRole deletes a role and invalidates the cached roles and user lists.
+func (s *CachedRoleService) DeleteRole(ctx context.Context, id string) error {
+	if err := s.RoleServiceInterface.DeleteRole(ctx, id); err != nil {
+		return err
+	}
+	s.roles.Invalidate()
+	s.cache.Invalidate(ctx, rolesCacheGroup, usersCacheGroup)
+	return nil
+}
+
+// ImportRoles imports roles and invalidates the cached roles and user lists, as overwritten
+// roles may be renamed.
+func (s *CachedRoleService) ImportRoles(ctx context.Context, roles []models.RoleDefinition, onConflict string) ([]models.RoleImportResult, error) {
+	results, err := s.RoleServiceInterface.ImportRoles(ctx, roles, onConflict)
+	if err != nil {
+		return nil, err
+	}
+	s.roles.Invalidate()
+	s.cache.Invalidate(ctx, rolesCacheGroup, usersCacheGroup)
+	return results, nil
+}
+
+// CachedUserService serves user lists from the query cache, and invalidates them when users
+// change. The role dropdown is served from the role cache by UserService.
+type CachedUserService struct {
+	UserServiceInterface
+	cache *cache.QueryCache
+}
+
+// NewCachedUserService wraps userService with queryCache; a nil queryCache disables caching.
+func NewCachedUserService(userService UserServiceInterface, queryCache *cache.QueryCache) *CachedUserService {
+	return &CachedUserService{UserServiceInterface: userService, cache: queryCache}
+}
+
+// GetAllUsers returns a page of the request tenant's users, from the cache if possible.
+func (s *CachedUserService) GetAllUsers(ctx context.Context, search string, roleID string, tag string, page, limit int) ([]models.User, int, int, error) {
+	key := fmt.Sprintf("list:%s:%s:%s:%d:%d:%s", tenancy.ID(ctx), roleID, tag, page, limit, search)
+	result, err := cache.Fetch(ctx, s.cache, usersCacheGroup, key, func() (listPage[models.User], error) {
+		users, totalPages, totalItems, err := s.UserServiceInterface.GetAllUsers(ctx, search, roleID, tag, page, limit)
+		return listPage[models.User]{Items: users, TotalPages: totalPages, TotalItems: totalItems}, err
+	})
+	return result.Items, result.TotalPages, result.TotalItems, err
+}
+
+// CreateUser creates a user and invalidates the cached user lists.
+func (s *CachedUserService) CreateUser(ctx context.Context, user *models.User) error {
+	if err := s.UserServiceInterface.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, usersCacheGroup)
+	return nil
+}
+
+// UpdateUser updates a user and invalidates the cached user lists.
+func (s *CachedUserService) UpdateUser(ctx context.Context, req *models.UpdateUserRequest) error {
+	if err := s.UserServiceInterface.UpdateUser(ctx, req); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, usersCacheGroup)
+	return nil
+}
+
+// DeleteUser deletes a user and invalidates the cached user lists.
+func (s *CachedUserService) DeleteUser(ctx context.Context, id string) error {
+	if err := s.UserServiceInterface.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, usersCacheGroup)
+	return nil
+}
+
+// SetUserTags replaces a user's tags and invalidates the cached user lists.
+func (s *CachedUserService) SetUserTags(ctx context.Context, id string, tags []string) ([]string, error) {
+	tags, err := s.UserServiceInterface.SetUserTags(ctx, id, tags)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, usersCacheGroup)
+	return tags, nil
+}
+
+// TagUsers changes the tags of several users and invalidates the cached user lists.
+func (s *CachedUserService) TagUsers(ctx context.Context, userIDs []string, add, remove []string) error {
+	if err := s.UserServiceInterface.TagUsers(ctx, userIDs, add, remove); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, usersCacheGroup)
+	return nil
+}
+
+// SetAvatar changes a user's avatar and invalidates the cached user lists.
+func (s *CachedUserService) SetAvatar(ctx context.Context, id string, key *string) (*string, error) {
+	previous, err := s.UserServiceInterface.SetAvatar(ctx, id, key)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, usersCacheGroup)
+	return previous, nil
+}
+
+// CachedTrashService invalidates the cached lists of the records it restores, and the role
+// cache when it restores roles.
+type CachedTrashService struct {
+	TrashServiceInterface
+	cache *cache.QueryCache
+	roles *RoleCache
+}
+
+// NewCachedTrashService wraps trashService with queryCache; a nil queryCache disables caching.
+func NewCachedTrashService(trashService TrashServiceInterface, queryCache *cache.QueryCache, roleCache *RoleCache) *CachedTrashService {
+	return &CachedTrashService{TrashServiceInterface: trashService, cache: queryCache, roles: roleCache}
+}
+
+// Restore restores a record and invalidates the cached lists of its resource.
+func (s *CachedTrashService) Restore(ctx context.Context, resource, id string) error {
+	if err := s.TrashServiceInterface.Restore(ctx, resource, id); err != nil {
+		return err
+	}
+	if resource == models.TrashResourceRoles {
+		s.roles.Invalidate()
+	}
+	s.cache.Invalidate(ctx, trashCacheGroups[resource]...)
+	return nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/google/uuid"
+)
+
+// CategoryServiceInterface defines the methods that any category service implementation must provide.
+type CategoryServiceInterface interface {
+	GetAllCategories(ctx context.Context, search string, page, limit int) ([]models.Category, int, int, error) // Returns categories, totalPages, totalItems
+	GetCategoryByID(ctx context.Context, id string) (*models.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error)
+	CreateCategory(ctx context.Context, category *models.Category) error
+	UpdateCategory(ctx context.Context, category *models.Category) error
+	DeleteCategory(ctx context.Context, id string) error
+}
+
+// CategoryService provides methods for category-related business logic, implementing CategoryServiceInterface.
+type CategoryService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewCategoryService creates and returns a new CategoryService instance using the given connection pool.
+func NewCategoryService(db *database.DB) *CategoryService {
+	return &CategoryService{db: db}
+}
+
+// GetAllCategories fetches all categories from the database with search and pagination.
+func (s *CategoryService) GetAllCategories(ctx context.Context, search string, page, limit int) ([]models.Category, int, int, error) {
+	var categories []models.Category
+	var totalItems int
+
+	countQuery := "SELECT COUNT(id) FROM categories WHERE 1=1"
+	selectQuery := "SELECT id, name, slug, COALESCE(description, ''), created_at, updated_at FROM categories WHERE 1=1"
+	args := []interface{}{}
+	argCounter := 1
+
+	if search != "" {
+		searchPattern := "%" + search + "%"
+		ilike := s.db.Dialect().ILike
+		countQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("description", argCounter+1))
+		selectQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("description", argCounter+1))
+		args = append(args, searchPattern, searchPattern)
+		argCounter += 2
+	}
+
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count categories: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	selectQuery += fmt.Sprintf(" ORDER BY name ASC LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category models.Category
+		err := rows.Scan(&category.ID, &category.Name, &category.Slug, &category.Description, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning category row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating category rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return categories, totalPages, totalItems, nil
+}
+
+// GetCategoryByID fetches a category by its ID.
+func (s *CategoryService) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
+	category := &models.Category{}
+	query := "SELECT id, name, slug, COALESCE(description, ''), created_at, updated_at FROM categories WHERE id = $1"
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&category.ID, &category.Name, &category.Slug, &category.Description, &category.CreatedAt, &category.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Category not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching category", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch category by ID: %w", err)
+	}
+	return category, nil
+}
+
+// GetCategoryBySlug fetches a category by its slug.
+func (s *CategoryService) GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	category := &models.Category{}
+	query := "SELECT id, name, slug, COALESCE(description, ''), created_at, updated_at FROM categories WHERE slug = $1"
+	err := s.db.QueryRowContext(ctx, query, slug).Scan(&category.ID, &category.Name, &category.Slug, &category.Description, &category.CreatedAt, &category.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Category not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching category by slug", "slug", slug, "error", err)
+		return nil, fmt.Errorf("failed to fetch category by slug: %w", err)
+	}
+	return category, nil
+}
+
+// CreateCategory inserts a new category into the database.
+func (s *CategoryService) CreateCategory(ctx context.Context, category *models.Category) error {
+	category.ID = uuid.New().String()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO categories (id, name, slug, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := s.db.ExecContext(ctx, query, category.ID, category.Name, category.Slug, category.Description, category.CreatedAt, category.UpdatedAt)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating category", "category_name", category.Name, "error", err)
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+	return nil
+}
+
+// UpdateCategory updates an existing category's information in the database.
+func (s *CategoryService) UpdateCategory(ctx context.Context, category *models.Category) error {
+	category.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE categories
+		SET name = $1, slug = $2, description = $3, updated_at = $4
+		WHERE id = $5
+	`
+	result, err := s.db.ExecContext(ctx, query, category.Name, category.Slug, category.Description, category.UpdatedAt, category.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating category", "category_id", category.ID, "error", err)
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("category with ID %s not found for update", category.ID)
+	}
+
+	return nil
+}
+
+// DeleteCategory deletes a category by its ID. Posts in the category are kept and
+// have their category_id cleared by ON DELETE SET NULL.
+func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
+	query := `DELETE FROM categories WHERE id = $1`
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting category", "id", id, "error", err)
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("category with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/geoip"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// DeviceServiceInterface defines the methods that any device service implementation must provide.
+type DeviceServiceInterface interface {
+	RecordLogin(ctx context.Context, user *models.User, userAgent, ip string) error
+	RevokeDevice(ctx context.Context, token string) error
+}
+
+// DeviceService remembers the devices and countries users log in from, implementing
+// DeviceServiceInterface. A login from a device or country the user didn't log in from before
+// is notified to them (and emailed when SMTP_HOST is set), with a "this wasn't me" link.
+type DeviceService struct {
+	db            *database.DB                 // Database connection pool
+	notifications NotificationServiceInterface // Sends the alerts
+	locator       *geoip.Locator               // Countries of the client IPs; nil if unknown
+	revokeURL     string                       // Page of the "this wasn't me" link, the token is appended
+}
+
+// NewDeviceService creates and returns a new DeviceService instance using the given
+// connection pool, which alerts users through notificationService.
+func NewDeviceService(db *database.DB, notificationService NotificationServiceInterface, locator *geoip.Locator, revokeURL string) *DeviceService {
+	return &DeviceService{db: db, notifications: notificationService, locator: locator, revokeURL: revokeURL}
+}
+
+// RecordLogin records a login of user from the client with userAgent (at most 255 characters)
+// and ip, and alerts the user if they didn't log in from its device or country before. The
+// first login of a user is only recorded: there is nothing to compare it with.
+func (s *DeviceService) RecordLogin(ctx context.Context, user *models.User, userAgent, ip string) error {
+	fingerprint := hashToken(userAgent)
+	country := s.locator.Country(ip)
+	now := time.Now()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT fingerprint, country FROM known_devices WHERE user_id = $1", user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching known devices", "user_id", user.ID, "error", err)
+		return fmt.Errorf("failed to fetch known devices: %w", err)
+	}
+	defer rows.Close()
+	var loggedInBefore, knownDevice, knownCountry bool
+	for rows.Next() {
+		var knownFingerprint, knownCountryCode string
+		if err := rows.Scan(&knownFingerprint, &knownCountryCode); err != nil {
+			return fmt.Errorf("failed to scan known device: %w", err)
+		}
+		loggedInBefore = true
+		knownDevice = knownDevice || knownFingerprint == fingerprint
+		knownCountry = knownCountry || knownCountryCode == country
+		if knownFingerprint == fingerprint && knownCountryCode == country {
+			_, err := s.db.ExecContext(ctx, "UPDATE known_devices SET last_seen_at = $1 WHERE user_id = $2 AND fingerprint = $3 AND country = $4",
+				now, user.ID, fingerprint, country)
+			if err != nil {
+				return fmt.Errorf("failed to update known device: %w", err)
+			}
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating known device rows: %w", err)
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("failed to generate revoke token: %w", err)
+	}
+	revokeToken := hex.EncodeToString(tokenBytes)
+	query := `
+		INSERT INTO known_devices (id, user_id, fingerprint, device, country, revoke_token, first_seen_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.db.ExecContext(ctx, query, uuid.New().String(), user.ID, fingerprint, userAgent, country, hashToken(revokeToken), now, now)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error recording known device", "user_id", user.ID, "error", err)
+		return fmt.Errorf("failed to record known device: %w", err)
+	}
+
+	// An unknown country only stands out when it is known
+	if !loggedInBefore || (knownDevice && (country == "" || knownCountry)) {
+		return nil
+	}
+	logging.FromContext(ctx).Info("Login from a new device or country", "user_id", user.ID, "new_device", !knownDevice, "country", country)
+	return s.alert(ctx, user, userAgent, country, ip, now, revokeToken)
+}
+
+// alert notifies user of their login from a new device or country.
+func (s *DeviceService) alert(ctx context.Context, user *models.User, userAgent, country, ip string, at time.Time, revokeToken string) error {
+	location := country
+	if location == "" {
+		location = "unknown"
+	}
+	if userAgent == "" {
+		userAgent = "unknown"
+	}
+	data, err := json.Marshal(map[string]string{"device": userAgent, "country": country, "ip_address": ip})
+	if err != nil {
+		return err
+	}
+	return s.notifications.Notify(ctx, user.ID, &models.Notification{
+		Type:  models.NotificationTypeNewLogin,
+		Title: "New login to your " + config.AppConfig.SiteTitle + " account",
+		Body: fmt.Sprintf("Hi %s, your account was just logged into from a new device or location.\n\n"+
+			"Device: %s\nCountry: %s\nIP address: %s\nTime: %s\n\n"+
+			"If this was you, you can ignore this message. If it wasn't, log out everywhere with the link below and change your password:\n%s",
+			user.Username, userAgent, location, ip, at.UTC().Format(time.RFC1123), s.revokeURL+"?token="+revokeToken),
+		Data: data,
+	})
+}
+
+// RevokeDevice handles the "this wasn't me" link of a login alert: the user of the login
+// with token is logged out everywhere, as their password may be known to someone else, and
+// the device is forgotten, so logging in from it is alerted again.
+func (s *DeviceService) RevokeDevice(ctx context.Context, token string) error {
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		var userID string
+		err := tx.QueryRowContext(ctx, "SELECT user_id FROM known_devices WHERE revoke_token = $1", hashToken(token)).Scan(&userID)
+		if err == sql.ErrNoRows {
+			return notFoundf("login to revoke not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch known device: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM known_devices WHERE revoke_token = $1", hashToken(token)); err != nil {
+			return fmt.Errorf("failed to delete known device: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = $1", userID); err != nil {
+			return fmt.Errorf("failed to delete sessions: %w", err)
+		}
+		// Tokens carrying the old version are rejected as well, see middleware.JWT
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET token_version = token_version + 1 WHERE id = $1", userID); err != nil {
+			return fmt.Errorf("failed to bump token version: %w", err)
+		}
+		logging.FromContext(ctx).Warn("Login revoked by its user, logged out everywhere", "user_id", userID)
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anpsniper/anpbayu-be/pgerr"
+)
+
+// Kinds of domain errors returned by services. Check for them with errors.Is; the error
+// handler of the API maps them to HTTP statuses.
+var (
+	ErrNotFound   = errors.New("not found")         // The record doesn't exist (404 Not Found)
+	ErrConflict   = errors.New("conflict")          // The record's state doesn't allow the change (409 Conflict)
+	ErrValidation = errors.New("validation failed") // The input is invalid (422 Unprocessable Entity)
+)
+
+// DomainError is an error of one of the kinds above, with a message describing the case.
+type DomainError struct {
+	Kind    error  // ErrNotFound, ErrConflict or ErrValidation
+	Message string // e.g., "user with ID ... not found for update"
+}
+
+// Error returns the message of the error.
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the kind of the error, so errors.Is(err, ErrNotFound) and the like work.
+func (e *DomainError) Unwrap() error {
+	return e.Kind
+}
+
+// notFoundf returns an ErrNotFound error with a formatted message.
+func notFoundf(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// conflictf returns an ErrConflict error with a formatted message.
+func conflictf(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// invalidf returns an ErrValidation error with a formatted message.
+func invalidf(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// AsDomainError returns the domain error err is or wraps. Constraint violations reported by the
+// database are translated into domain errors too, so a write that a service didn't check for
+// beforehand (e.g. a slug taken by a concurrent request) fails with a conflict or a validation
+// error rather than an internal one.
+func AsDomainError(err error) (*DomainError, bool) {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	var dbErr *pgerr.Error
+	if !errors.As(pgerr.Translate(err), &dbErr) {
+		return nil, false
+	}
+	switch dbErr.Kind {
+	case pgerr.ErrUniqueViolation:
+		return &DomainError{Kind: ErrConflict, Message: "a record with the same values already exists"}, true
+	case pgerr.ErrForeignKeyViolation:
+		return &DomainError{Kind: ErrConflict, Message: "the record references a missing record or is still referenced"}, true
+	default: // ErrCheckViolation, ErrNotNullViolation
+		return &DomainError{Kind: ErrValidation, Message: "the record has invalid or missing values"}, true
+	}
+}
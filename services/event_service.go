@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/realtime"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// EventServiceInterface defines the methods that any event service implementation must provide.
+type EventServiceInterface interface {
+	GetMissedEvents(ctx context.Context, userID, lastEventID string, limit int) ([]realtime.Event, error)
+}
+
+// EventService looks up the events pushed to a user (their notifications and the announcements
+// of their tenant) that a client missed while it was disconnected.
+type EventService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewEventService creates and returns a new EventService instance using the given connection pool.
+func NewEventService(db *database.DB) *EventService {
+	return &EventService{db: db}
+}
+
+// GetMissedEvents returns up to limit events of the user created after the event with the ID
+// lastEventID, oldest first. Nothing was missed if lastEventID is empty or unknown, e.g.,
+// because the notification was deleted with its user.
+func (s *EventService) GetMissedEvents(ctx context.Context, userID, lastEventID string, limit int) ([]realtime.Event, error) {
+	events := []realtime.Event{}
+	if _, err := uuid.Parse(lastEventID); err != nil {
+		return events, nil // Not an ID of an event this API sent
+	}
+
+	var since time.Time
+	err := s.db.QueryRowContext(ctx, `
+		SELECT created_at FROM notifications WHERE id = $1 AND user_id = $2
+		UNION ALL
+		SELECT created_at FROM announcements WHERE id = $1 AND tenant_id = $3`,
+		lastEventID, userID, tenancy.ID(ctx),
+	).Scan(&since)
+	if err == sql.ErrNoRows {
+		return events, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up last event: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+notificationColumns+" FROM notifications WHERE user_id = $1 AND created_at > $2 ORDER BY created_at LIMIT $3",
+		userID, since, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query missed notifications: %w", err)
+	}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		events = append(events, realtime.Event{ID: notification.ID, Type: realtime.EventNotification, Data: notification})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating missed notification rows: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT id, title, message, created_by, created_at FROM announcements
+		WHERE tenant_id = $1 AND created_at > $2 ORDER BY created_at LIMIT $3`,
+		tenancy.ID(ctx), since, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query missed announcements: %w", err)
+	}
+	for rows.Next() {
+		var announcement models.Announcement
+		if err := rows.Scan(&announcement.ID, &announcement.Title, &announcement.Message, &announcement.CreatedBy, &announcement.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan missed announcement: %w", err)
+		}
+		events = append(events, realtime.Event{ID: announcement.ID, Type: realtime.EventAnnouncement, Data: announcement})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating missed announcement rows: %w", err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return createdAt(events[i]).Before(createdAt(events[j])) })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// createdAt returns when the notification or announcement of event was created.
+func createdAt(event realtime.Event) time.Time {
+	switch data := event.Data.(type) {
+	case *models.Notification:
+		return data.CreatedAt
+	case models.Announcement:
+		return data.CreatedAt
+	}
+	return time.Time{}
+}
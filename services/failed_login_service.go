@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// FailedLoginServiceInterface defines the methods that any failed login service implementation must provide.
+type FailedLoginServiceInterface interface {
+	RecordFailedLogin(ctx context.Context, attempt *models.FailedLogin) error
+	GetFailedLogins(ctx context.Context, filter models.FailedLoginFilter, page, limit int) ([]models.FailedLogin, int, int, error) // Returns attempts, totalPages, totalItems
+}
+
+// FailedLoginService records the failed login attempts and queries them for incident response.
+type FailedLoginService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewFailedLoginService creates and returns a new FailedLoginService instance using the given connection pool.
+func NewFailedLoginService(db *database.DB) *FailedLoginService {
+	return &FailedLoginService{db: db}
+}
+
+// RecordFailedLogin records a failed login attempt on the request's tenant, setting its ID,
+// tenant and time.
+func (s *FailedLoginService) RecordFailedLogin(ctx context.Context, attempt *models.FailedLogin) error {
+	attempt.ID = uuid.New().String()
+	attempt.TenantID = tenancy.ID(ctx)
+	attempt.CreatedAt = time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO failed_logins (id, tenant_id, account, reason, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		attempt.ID, attempt.TenantID, attempt.Account, attempt.Reason, attempt.IPAddress, attempt.UserAgent, attempt.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record failed login: %w", err)
+	}
+	return nil
+}
+
+// GetFailedLogins retrieves the failed login attempts on the request's tenant matching filter, newest first.
+func (s *FailedLoginService) GetFailedLogins(ctx context.Context, filter models.FailedLoginFilter, page, limit int) ([]models.FailedLogin, int, int, error) {
+	attempts := []models.FailedLogin{}
+	var totalItems int
+
+	where := " WHERE tenant_id = $1"
+	args := []interface{}{tenancy.ID(ctx)}
+	for _, condition := range []struct{ column, value string }{
+		{"account", filter.Account},
+		{"ip_address", filter.IPAddress},
+		{"reason", filter.Reason},
+	} {
+		if condition.value != "" {
+			args = append(args, condition.value)
+			where += fmt.Sprintf(" AND %s = $%d", condition.column, len(args))
+		}
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM failed_logins"+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count failed logins: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	query := fmt.Sprintf(`
+		SELECT id, tenant_id, account, reason, ip_address, user_agent, created_at
+		FROM failed_logins%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query failed logins: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attempt models.FailedLogin
+		var ipAddress, userAgent sql.NullString
+		if err := rows.Scan(&attempt.ID, &attempt.TenantID, &attempt.Account, &attempt.Reason, &ipAddress, &userAgent, &attempt.CreatedAt); err != nil {
+			logging.FromContext(ctx).Error("Error scanning failed login row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan failed login: %w", err)
+		}
+		attempt.IPAddress, attempt.UserAgent = ipAddress.String, userAgent.String
+		attempts = append(attempts, attempt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating failed login rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return attempts, totalPages, totalItems, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// IdempotencyServiceInterface defines the methods that any idempotency key store must provide.
+type IdempotencyServiceInterface interface {
+	Reserve(ctx context.Context, userID, key, requestHash string) (*models.IdempotencyKey, error) // Returns the existing key, or nil if it was reserved now
+	Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error) // Returns the number of keys deleted
+}
+
+// IdempotencyService stores the idempotency keys of requests and their responses.
+type IdempotencyService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewIdempotencyService creates and returns a new IdempotencyService instance using the given connection pool.
+func NewIdempotencyService(db *database.DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Reserve claims key for a request of userID with the given hash. It returns nil if the key
+// was free and is now reserved, or the existing key, which may still be in progress, so the
+// caller can replay its response or reject the request.
+func (s *IdempotencyService) Reserve(ctx context.Context, userID, key, requestHash string) (*models.IdempotencyKey, error) {
+	insert := s.db.Dialect().InsertIgnore(
+		"INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, created_at) VALUES ($1, $2, $3, $4)",
+	)
+	result, err := s.db.ExecContext(ctx, insert, userID, key, requestHash, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	} else if inserted == 1 {
+		return nil, nil
+	}
+
+	existing := &models.IdempotencyKey{UserID: userID, Key: key}
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	err = s.db.QueryRowContext(ctx,
+		"SELECT request_hash, status_code, content_type, response_body, created_at FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2",
+		userID, key,
+	).Scan(&existing.RequestHash, &statusCode, &contentType, &existing.Body, &existing.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released by a failed request in the meantime; the client may retry
+		return nil, conflictf("idempotency key %s was released, retry the request", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	existing.StatusCode = int(statusCode.Int64)
+	existing.ContentType = contentType.String
+	return existing, nil
+}
+
+// Complete stores the response of the request that reserved key, to be replayed on retries.
+func (s *IdempotencyService) Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3 WHERE user_id = $4 AND idempotency_key = $5",
+		statusCode, contentType, body, userID, key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store response of idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release frees key after its request failed, so the client can retry it.
+func (s *IdempotencyService) Release(ctx context.Context, userID, key string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2", userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the keys created before before and returns how many were deleted.
+func (s *IdempotencyService) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// MaintenanceServiceInterface defines the methods that any maintenance service implementation must provide.
+type MaintenanceServiceInterface interface {
+	DeleteExpired(ctx context.Context, now time.Time) (map[string]int64, error) // Returns the number of rows deleted per table
+	PurgeLogs(ctx context.Context, before time.Time, mode string) ([]models.RetentionCount, error)
+	CountPurgeableLogs(ctx context.Context, before time.Time, mode string) ([]models.RetentionCount, error)
+}
+
+// MaintenanceService removes data of all tenants that is no longer needed, for the scheduled
+// tasks (see jobs.ExpiredCleanup and jobs.LogRetention).
+type MaintenanceService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewMaintenanceService creates and returns a new MaintenanceService instance using the given connection pool.
+func NewMaintenanceService(db *database.DB) *MaintenanceService {
+	return &MaintenanceService{db: db}
+}
+
+// expiringTables are the tables of records that are useless once they expire, with the column
+// holding their expiry. Tables of one-time tokens, such as password resets or email
+// verifications, belong here as well.
+var expiringTables = []struct {
+	table  string
+	column string
+}{
+	{"sessions", "expires_at"},
+	{"login_otps", "expires_at"},
+	{"organization_invitations", "expires_at"},
+}
+
+// DeleteExpired deletes the rows of the expiring tables that expired by now and returns how many
+// were deleted per table. On error, the counts of the tables cleaned up before are returned.
+func (s *MaintenanceService) DeleteExpired(ctx context.Context, now time.Time) (map[string]int64, error) {
+	deleted := make(map[string]int64, len(expiringTables))
+	for _, expiring := range expiringTables {
+		result, err := s.db.ExecContext(ctx, "DELETE FROM "+expiring.table+" WHERE "+expiring.column+" < $1", now)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete expired %s: %w", expiring.table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to check rows affected after deleting expired %s: %w", expiring.table, err)
+		}
+		deleted[expiring.table] = n
+	}
+	return deleted, nil
+}
+
+// logPurge is a table of log entries the retention policy applies to.
+type logPurge struct {
+	table string
+	where string // Condition on the entries to purge, recorded before $1
+	// SET clause removing the personal data of the entries in the "anonymize" mode, and condition
+	// on the entries still holding some; empty for entries that are deleted in every mode
+	anonymize  string
+	identified string
+}
+
+// logPurges are the tables of logs the retention policy applies to. Pending webhook deliveries
+// are kept, so they are still retried. Login logs are nothing but who logged in when, so they
+// are deleted in every mode.
+var logPurges = []logPurge{
+	{table: "user_logs", where: "login_at < $1"},
+	{
+		table:      "audit_logs",
+		where:      "created_at < $1",
+		anonymize:  "actor_id = NULL, ip_address = NULL, request_id = NULL",
+		identified: "(actor_id IS NOT NULL OR ip_address IS NOT NULL OR request_id IS NOT NULL)",
+	},
+	{table: "webhook_deliveries", where: "status <> 'pending' AND created_at < $1"},
+	{table: "failed_logins", where: "created_at < $1"},
+}
+
+// statement returns the statement applying the purge in mode, and the action it performs.
+func (p logPurge) statement(mode string) (string, string) {
+	if mode == models.RetentionAnonymize && p.anonymize != "" {
+		return "UPDATE " + p.table + " SET " + p.anonymize + " WHERE " + p.where + " AND " + p.identified, models.RetentionAnonymize
+	}
+	return "DELETE FROM " + p.table + " WHERE " + p.where, models.RetentionDelete
+}
+
+// count returns the query counting the entries the purge applies to in mode.
+func (p logPurge) count(mode string) string {
+	if mode == models.RetentionAnonymize && p.anonymize != "" {
+		return "SELECT COUNT(*) FROM " + p.table + " WHERE " + p.where + " AND " + p.identified
+	}
+	return "SELECT COUNT(*) FROM " + p.table + " WHERE " + p.where
+}
+
+// PurgeLogs deletes the login logs, audit logs, settled webhook deliveries and failed logins
+// recorded before before, or anonymizes the audit logs in the "anonymize" mode, and returns how
+// many entries were purged per table. On error, the counts of the tables purged before are returned.
+func (s *MaintenanceService) PurgeLogs(ctx context.Context, before time.Time, mode string) ([]models.RetentionCount, error) {
+	counts := make([]models.RetentionCount, 0, len(logPurges))
+	for _, purge := range logPurges {
+		query, action := purge.statement(mode)
+		result, err := s.db.ExecContext(ctx, query, before)
+		if err != nil {
+			return counts, fmt.Errorf("failed to purge %s: %w", purge.table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return counts, fmt.Errorf("failed to check rows affected after purging %s: %w", purge.table, err)
+		}
+		counts = append(counts, models.RetentionCount{Table: purge.table, Action: action, Rows: n})
+	}
+	return counts, nil
+}
+
+// CountPurgeableLogs returns how many entries per table PurgeLogs would delete or anonymize,
+// without changing them.
+func (s *MaintenanceService) CountPurgeableLogs(ctx context.Context, before time.Time, mode string) ([]models.RetentionCount, error) {
+	counts := make([]models.RetentionCount, 0, len(logPurges))
+	for _, purge := range logPurges {
+		_, action := purge.statement(mode)
+		count := models.RetentionCount{Table: purge.table, Action: action}
+		if err := s.db.QueryRowContext(ctx, purge.count(mode), before).Scan(&count.Rows); err != nil {
+			return nil, fmt.Errorf("failed to count purgeable %s: %w", purge.table, err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/notifications"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// NotificationServiceInterface defines the methods that any notification service implementation must provide.
+type NotificationServiceInterface interface {
+	Notify(ctx context.Context, userID string, notification *models.Notification) error
+	NotifyAdmins(ctx context.Context, notification models.Notification) error
+	NotifyLowStock(ctx context.Context, productID string, removed int) error
+	NotifyLowStockSummary(ctx context.Context) (int, error)                                                                         // Returns the number of tenants notified
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]models.Notification, int, int, error) // Returns notifications, totalPages, totalItems
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error) // Returns the number of notifications marked read
+}
+
+// NotificationService stores the notifications of users for the app (the in-app channel) and
+// hands them to the other configured channels, such as email.
+type NotificationService struct {
+	db       *database.DB            // Database connection pool
+	channels []notifications.Channel // Channels besides the app
+}
+
+// NewNotificationService creates and returns a new NotificationService instance using the
+// given connection pool, which also sends notifications through channels.
+func NewNotificationService(db *database.DB, channels ...notifications.Channel) *NotificationService {
+	return &NotificationService{db: db, channels: notifications.Channels(channels...)}
+}
+
+const notificationColumns = "id, user_id, type, title, body, data, read_at, created_at"
+
+// Notify stores notification for the user and sends it through the other channels in the
+// background, so slow channels don't hold up the request; their failures are only logged.
+func (s *NotificationService) Notify(ctx context.Context, userID string, notification *models.Notification) error {
+	recipient := notifications.Recipient{UserID: userID}
+	var tenantID string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT tenant_id, username, email FROM users WHERE id = $1 AND "+database.NotDeleted(""), userID,
+	).Scan(&tenantID, &recipient.Username, &recipient.Email)
+	if err == sql.ErrNoRows {
+		return notFoundf("user with ID %s not found for notification", userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch notification recipient: %w", err)
+	}
+
+	notification.ID = uuid.New().String()
+	notification.UserID = userID
+	notification.ReadAt = nil
+	notification.CreatedAt = time.Now()
+	var data interface{}
+	if len(notification.Data) > 0 {
+		data = string(notification.Data)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO notifications (id, tenant_id, user_id, type, title, body, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		notification.ID, tenantID, userID, notification.Type, notification.Title, notification.Body, data, notification.CreatedAt,
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating notification", "user_id", userID, "type", notification.Type, "error", err)
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	if len(s.channels) > 0 {
+		sent := *notification
+		go s.send(context.WithoutCancel(ctx), recipient, &sent)
+	}
+	return nil
+}
+
+// send delivers notification through every channel besides the app.
+func (s *NotificationService) send(ctx context.Context, recipient notifications.Recipient, notification *models.Notification) {
+	for _, channel := range s.channels {
+		if err := channel.Send(ctx, recipient, notification); err != nil {
+			logging.FromContext(ctx).Error("Error sending notification", "channel", channel.Name(), "notification_id", notification.ID, "user_id", recipient.UserID, "error", err)
+		}
+	}
+}
+
+// NotifyAdmins sends a copy of notification to every admin of the request's tenant.
+func (s *NotificationService) NotifyAdmins(ctx context.Context, notification models.Notification) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE r.name = $1 AND u.tenant_id = $2 AND `+database.NotDeleted("u"),
+		"admin", tenancy.ID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query admins: %w", err)
+	}
+	var adminIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan admin: %w", err)
+		}
+		adminIDs = append(adminIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating admin rows: %w", err)
+	}
+
+	for _, adminID := range adminIDs {
+		copied := notification
+		if err := s.Notify(ctx, adminID, &copied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyLowStock alerts the admins when removing removed units from a product's stock made it
+// fall to LowStockThreshold or below. Products that were already running low aren't reported
+// again.
+func (s *NotificationService) NotifyLowStock(ctx context.Context, productID string, removed int) error {
+	var name string
+	var stock int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT name, stock FROM products WHERE id = $1 AND tenant_id = $2", productID, tenancy.ID(ctx),
+	).Scan(&name, &stock)
+	if err == sql.ErrNoRows {
+		return nil // Deleted in the meantime
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch product stock: %w", err)
+	}
+	if stock > models.LowStockThreshold || stock+removed <= models.LowStockThreshold {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"product_id": productID, "stock": stock})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification data: %w", err)
+	}
+	return s.NotifyAdmins(ctx, models.Notification{
+		Type:  models.NotificationTypeLowStock,
+		Title: fmt.Sprintf("%s is running low", name),
+		Body:  fmt.Sprintf("Only %d left in stock of %s. Restock it before it sells out.", stock, name),
+		Data:  data,
+	})
+}
+
+// NotifyLowStockSummary alerts the admins of every tenant with products running low about how
+// many there are, as a daily reminder of what still needs restocking.
+func (s *NotificationService) NotifyLowStockSummary(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tenant_id, COUNT(*) FROM products
+		WHERE stock <= $1 AND archived_at IS NULL AND `+database.NotDeleted("")+`
+		GROUP BY tenant_id`,
+		models.LowStockThreshold,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query low stock products: %w", err)
+	}
+	counts := map[string]int{}
+	for rows.Next() {
+		var tenantID string
+		var count int
+		if err := rows.Scan(&tenantID, &count); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan low stock count: %w", err)
+		}
+		counts[tenantID] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating low stock rows: %w", err)
+	}
+
+	notified := 0
+	for tenantID, count := range counts {
+		data, err := json.Marshal(map[string]interface{}{"products": count})
+		if err != nil {
+			return notified, fmt.Errorf("failed to marshal notification data: %w", err)
+		}
+		err = s.NotifyAdmins(tenancy.WithTenant(ctx, tenantID), models.Notification{
+			Type:  models.NotificationTypeLowStock,
+			Title: fmt.Sprintf("%d products are running low", count),
+			Body:  fmt.Sprintf("%d products have %d or fewer units left in stock. Restock them before they sell out.", count, models.LowStockThreshold),
+			Data:  data,
+		})
+		if err != nil {
+			return notified, err
+		}
+		notified++
+	}
+	return notified, nil
+}
+
+// GetNotifications retrieves the notifications of a user, newest first, optionally only the
+// unread ones.
+func (s *NotificationService) GetNotifications(ctx context.Context, userID string, unreadOnly bool, page, limit int) ([]models.Notification, int, int, error) {
+	notificationList := []models.Notification{}
+	var totalItems int
+
+	where := " WHERE user_id = $1"
+	if unreadOnly {
+		where += " AND read_at IS NULL"
+	}
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM notifications"+where, userID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+notificationColumns+" FROM notifications"+where+" ORDER BY created_at DESC LIMIT $2 OFFSET $3",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning notification row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notificationList = append(notificationList, *notification)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating notification rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return notificationList, totalPages, totalItems, nil
+}
+
+// CountUnread returns the number of unread notifications of a user.
+func (s *NotificationService) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM notifications WHERE user_id = $1 AND read_at IS NULL", userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks a notification of a user read. Notifications that are already read keep
+// the time they were first read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE notifications SET read_at = $1 WHERE id = $2 AND user_id = $3 AND read_at IS NULL", time.Now(), id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after marking notification read: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// Already read, or not a notification of the user
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM notifications WHERE id = $1 AND user_id = $2", id, userID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check notification existence: %w", err)
+	}
+	if count == 0 {
+		return notFoundf("notification with ID %s not found", id)
+	}
+	return nil
+}
+
+// MarkAllRead marks all unread notifications of a user read.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL", time.Now(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected after marking notifications read: %w", err)
+	}
+	return marked, nil
+}
+
+// scanNotification scans a notification row selected with notificationColumns.
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	notification := &models.Notification{}
+	var data sql.NullString
+	var readAt sql.NullTime
+	err := row.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.Title, &notification.Body, &data, &readAt, &notification.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if data.Valid {
+		notification.Data = json.RawMessage(data.String)
+	}
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+	return notification, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// The services below notify users of the changes they make. Notifying is a side effect, so
+// its failures are logged and the change still succeeds.
+
+// NotifyingUserService welcomes the users it creates.
+type NotifyingUserService struct {
+	UserServiceInterface
+	notifications NotificationServiceInterface
+}
+
+// NewNotifyingUserService wraps userService with notifications from notificationService.
+func NewNotifyingUserService(userService UserServiceInterface, notificationService NotificationServiceInterface) *NotifyingUserService {
+	return &NotifyingUserService{UserServiceInterface: userService, notifications: notificationService}
+}
+
+// CreateUser creates a user and sends them a welcome notification.
+func (s *NotifyingUserService) CreateUser(ctx context.Context, user *models.User) error {
+	if err := s.UserServiceInterface.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	err := s.notifications.Notify(ctx, user.ID, &models.Notification{
+		Type:  models.NotificationTypeWelcome,
+		Title: "Welcome to " + config.AppConfig.SiteTitle,
+		Body:  fmt.Sprintf("Hi %s, your account has been created. You can now sign in with %s.", user.Username, user.Email),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error sending welcome notification", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// NotifyingProductService alerts the admins when a stock adjustment leaves a product running low.
+type NotifyingProductService struct {
+	ProductServiceInterface
+	notifications NotificationServiceInterface
+}
+
+// NewNotifyingProductService wraps productService with notifications from notificationService.
+func NewNotifyingProductService(productService ProductServiceInterface, notificationService NotificationServiceInterface) *NotifyingProductService {
+	return &NotifyingProductService{ProductServiceInterface: productService, notifications: notificationService}
+}
+
+// AdjustStock adjusts the stock of a product and alerts the admins if it fell to LowStockThreshold.
+func (s *NotifyingProductService) AdjustStock(ctx context.Context, productID string, delta int, reason, actorID string) (*models.StockMovement, error) {
+	movement, err := s.ProductServiceInterface.AdjustStock(ctx, productID, delta, reason, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if delta < 0 {
+		if err := s.notifications.NotifyLowStock(ctx, productID, -delta); err != nil {
+			logging.FromContext(ctx).Error("Error sending low stock notification", "product_id", productID, "error", err)
+		}
+	}
+	return movement, nil
+}
+
+// NotifyingOrderService alerts the admins when the stock reserved by an order leaves products running low.
+type NotifyingOrderService struct {
+	OrderServiceInterface
+	notifications NotificationServiceInterface
+}
+
+// NewNotifyingOrderService wraps orderService with notifications from notificationService.
+func NewNotifyingOrderService(orderService OrderServiceInterface, notificationService NotificationServiceInterface) *NotifyingOrderService {
+	return &NotifyingOrderService{OrderServiceInterface: orderService, notifications: notificationService}
+}
+
+// CreateOrder creates an order and alerts the admins of the products whose stock it made fall
+// to LowStockThreshold.
+func (s *NotifyingOrderService) CreateOrder(ctx context.Context, order *models.Order, items []models.OrderItemRequest) error {
+	if err := s.OrderServiceInterface.CreateOrder(ctx, order, items); err != nil {
+		return err
+	}
+	reserved := map[string]int{}
+	for _, item := range items {
+		reserved[item.ProductID] += item.Quantity
+	}
+	for productID, quantity := range reserved {
+		if err := s.notifications.NotifyLowStock(ctx, productID, quantity); err != nil {
+			logging.FromContext(ctx).Error("Error sending low stock notification", "product_id", productID, "error", err)
+		}
+	}
+	return nil
+}
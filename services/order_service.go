@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OrderServiceInterface defines the methods that any order service implementation must provide.
+type OrderServiceInterface interface {
+	GetOrdersByUser(ctx context.Context, userID string, page, limit int) ([]models.Order, int, int, error) // Returns orders, totalPages, totalItems
+	GetOrderByID(ctx context.Context, id string) (*models.Order, error)
+	CreateOrder(ctx context.Context, order *models.Order, items []models.OrderItemRequest) error
+	ReleaseExpiredReservations(ctx context.Context) (int, error) // Returns the number of orders released
+}
+
+// OrderService provides methods for order-related business logic, implementing OrderServiceInterface.
+type OrderService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewOrderService creates and returns a new OrderService instance using the given connection pool.
+func NewOrderService(db *database.DB) *OrderService {
+	return &OrderService{db: db}
+}
+
+const orderColumns = "id, user_id, status, total_amount, currency, reserved_until, created_at, updated_at"
+
+// GetOrdersByUser retrieves a user's orders, newest first.
+func (s *OrderService) GetOrdersByUser(ctx context.Context, userID string, page, limit int) ([]models.Order, int, int, error) {
+	orders := []models.Order{}
+	var totalItems int
+
+	tenantID := tenancy.ID(ctx)
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM orders WHERE user_id = $1 AND tenant_id = $2", userID, tenantID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+orderColumns+" FROM orders WHERE user_id = $1 AND tenant_id = $4 ORDER BY created_at DESC LIMIT $2 OFFSET $3",
+		userID, limit, offset, tenantID,
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning order row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, *order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating order rows: %w", err)
+	}
+
+	for i := range orders {
+		if orders[i].Items, err = s.loadOrderItems(ctx, orders[i].ID); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return orders, totalPages, totalItems, nil
+}
+
+// GetOrderByID fetches an order and its items by the order ID.
+func (s *OrderService) GetOrderByID(ctx context.Context, id string) (*models.Order, error) {
+	order, err := scanOrder(s.db.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM orders WHERE id = $1 AND tenant_id = $2", id, tenancy.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil // Order not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching order", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch order by ID: %w", err)
+	}
+
+	if order.Items, err = s.loadOrderItems(ctx, order.ID); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreateOrder inserts a pending order for the requested items and reserves their stock until
+// order.ReservedUntil. Product rows are locked (SELECT ... FOR UPDATE) in ID order for the whole
+// transaction, so concurrent orders for the same products are serialized and can't oversell,
+// and unit prices always match the stored line items.
+func (s *OrderService) CreateOrder(ctx context.Context, order *models.Order, items []models.OrderItemRequest) error {
+	order.ID = uuid.New().String()
+	order.Status = models.OrderStatusPending
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = time.Now()
+	order.Items = []models.OrderItem{}
+	order.TotalAmount = decimal.Zero
+
+	// Locking in a fixed order prevents deadlocks between orders sharing products
+	sorted := append([]models.OrderItemRequest(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+
+	tenantID := tenancy.ID(ctx)
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		for _, req := range sorted {
+			item := models.OrderItem{
+				ID:        uuid.New().String(),
+				OrderID:   order.ID,
+				ProductID: req.ProductID,
+				Quantity:  req.Quantity,
+			}
+			var stock int
+			var archived bool
+			err := tx.QueryRowContext(ctx, "SELECT name, price, stock, archived_at IS NOT NULL FROM products WHERE id = $1 AND tenant_id = $2 AND "+database.NotDeleted("")+tx.Dialect().ForUpdate(false), req.ProductID, tenantID).Scan(&item.ProductName, &item.UnitPrice, &stock, &archived)
+			if err == sql.ErrNoRows {
+				return invalidf("product with ID %s not found for order", req.ProductID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to fetch product for order: %w", err)
+			}
+			if archived {
+				return invalidf("product with ID %s is archived and can't be ordered", req.ProductID)
+			}
+			if stock < req.Quantity {
+				return conflictf("insufficient stock for product %s", req.ProductID)
+			}
+			order.Items = append(order.Items, item)
+			order.TotalAmount = order.TotalAmount.Add(item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity))))
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO orders (id, tenant_id, user_id, status, total_amount, currency, reserved_until, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, order.ID, tenantID, order.UserID, order.Status, order.TotalAmount, order.Currency, order.ReservedUntil, order.CreatedAt, order.UpdatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error creating order for user", "user_id", order.UserID, "error", err)
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+
+		for _, item := range order.Items {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO order_items (id, order_id, product_id, product_name, quantity, unit_price)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, item.ID, item.OrderID, item.ProductID, item.ProductName, item.Quantity, item.UnitPrice)
+			if err != nil {
+				logging.FromContext(ctx).Error("Error creating order item for order", "order_id", order.ID, "error", err)
+				return fmt.Errorf("failed to create order item: %w", err)
+			}
+
+			if err := changeStock(ctx, tx, item.ProductID, -item.Quantity, "reserved for order "+order.ID, order.UserID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReleaseExpiredReservations cancels unpaid orders whose reservation has expired and returns
+// their stock. Orders locked by a concurrent checkout or webhook are skipped until the next run.
+// It returns the number of orders released. The job runs for all tenants at once.
+func (s *OrderService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	orderIDs := []string{}
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id FROM orders
+			WHERE status IN ($1, $2) AND reserved_until IS NOT NULL AND reserved_until < $3
+			ORDER BY reserved_until ASC
+			LIMIT 100`+tx.Dialect().ForUpdate(true),
+			models.OrderStatusPending, models.OrderStatusFailed, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to query expired orders: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan expired order: %w", err)
+			}
+			orderIDs = append(orderIDs, id)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("error iterating expired order rows: %w", err)
+		}
+
+		for _, orderID := range orderIDs {
+			items, err := tx.QueryContext(ctx, `SELECT product_id, quantity FROM order_items WHERE order_id = $1 ORDER BY product_id ASC`, orderID)
+			if err != nil {
+				return fmt.Errorf("failed to query order items: %w", err)
+			}
+			quantities := map[string]int{}
+			productIDs := []string{}
+			for items.Next() {
+				var productID string
+				var quantity int
+				if err := items.Scan(&productID, &quantity); err != nil {
+					items.Close()
+					return fmt.Errorf("failed to scan order item: %w", err)
+				}
+				quantities[productID] = quantity
+				productIDs = append(productIDs, productID)
+			}
+			items.Close()
+
+			for _, productID := range productIDs {
+				if err := changeStock(ctx, tx, productID, quantities[productID], "reservation expired for order "+orderID, nil); err != nil {
+					return err
+				}
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE orders SET status = $1, reserved_until = NULL WHERE id = $2`, models.OrderStatusCancelled, orderID)
+			if err != nil {
+				return fmt.Errorf("failed to cancel expired order: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(orderIDs), nil
+}
+
+// changeStock applies delta to a product's stock inside tx and records it in stock_movements.
+// A nil actorID records the change as made by the system.
+func changeStock(ctx context.Context, tx *database.Tx, productID string, delta int, reason string, actorID interface{}) error {
+	now := time.Now()
+	_, err := tx.ExecContext(ctx, `UPDATE products SET stock = stock + $1, updated_at = $2 WHERE id = $3`, delta, now, productID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error changing stock for product", "product_id", productID, "error", err)
+		return fmt.Errorf("failed to change stock: %w", err)
+	}
+
+	var stockAfter int
+	if err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = $1`, productID).Scan(&stockAfter); err != nil {
+		return fmt.Errorf("failed to read changed stock: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_movements (id, product_id, delta, reason, actor_id, stock_after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, uuid.New().String(), productID, delta, reason, actorID, stockAfter, now)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error recording stock movement for product", "product_id", productID, "error", err)
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return nil
+}
+
+// loadOrderItems fetches the line items of an order.
+func (s *OrderService) loadOrderItems(ctx context.Context, orderID string) ([]models.OrderItem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, order_id, product_id, product_name, quantity, unit_price
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY product_name ASC
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.OrderItem{}
+	for rows.Next() {
+		var item models.OrderItem
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.ProductName, &item.Quantity, &item.UnitPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order item rows: %w", err)
+	}
+	return items, nil
+}
+
+// scanOrder scans an order row selected with orderColumns.
+func scanOrder(row rowScanner) (*models.Order, error) {
+	order := &models.Order{Items: []models.OrderItem{}}
+	var reservedUntil sql.NullTime
+	err := row.Scan(&order.ID, &order.UserID, &order.Status, &order.TotalAmount, &order.Currency, &reservedUntil, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if reservedUntil.Valid {
+		order.ReservedUntil = &reservedUntil.Time
+	}
+	return order, nil
+}
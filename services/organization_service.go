@@ -0,0 +1,621 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/notifications"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// OrganizationServiceInterface defines the methods that any organization service implementation must provide.
+type OrganizationServiceInterface interface {
+	GetOrganizations(ctx context.Context, userID string, page, limit int) ([]models.Organization, int, int, error) // Returns organizations, totalPages, totalItems
+	GetOrganizationByID(ctx context.Context, id string) (*models.Organization, error)
+	CreateOrganization(ctx context.Context, organization *models.Organization, creatorID string) error
+	UpdateOrganization(ctx context.Context, organization *models.Organization) error
+	DeleteOrganization(ctx context.Context, id string) error
+	GetMembers(ctx context.Context, organizationID string) ([]models.OrganizationMember, error)
+	GetMemberRole(ctx context.Context, organizationID, userID string) (string, error) // Returns "" if the user isn't a member
+	SetMemberRole(ctx context.Context, organizationID, userID, role string) error
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+	GetInvitations(ctx context.Context, organizationID string) ([]models.OrganizationInvitation, error)
+	Invite(ctx context.Context, invitation *models.OrganizationInvitation) error
+	DeleteInvitation(ctx context.Context, organizationID, id string) error
+	AcceptInvitation(ctx context.Context, token, userID, email string) (*models.Organization, error)
+}
+
+// OrganizationService manages the organizations of a tenant, their members and the invitations
+// to join them, implementing OrganizationServiceInterface.
+type OrganizationService struct {
+	db            *database.DB          // Database connection pool
+	mailer        notifications.Channel // Emails the invitations; nil when SMTP_HOST isn't set
+	invitationURL string                // Page of the invitation links, the token is appended
+	invitationTTL time.Duration         // How long an invitation can be accepted
+}
+
+// NewOrganizationService creates and returns a new OrganizationService instance using the given
+// connection pool. Invitations are emailed through mailer, unless it is nil.
+func NewOrganizationService(db *database.DB, mailer notifications.Channel, invitationURL string, invitationTTL time.Duration) *OrganizationService {
+	return &OrganizationService{db: db, mailer: mailer, invitationURL: invitationURL, invitationTTL: invitationTTL}
+}
+
+const organizationColumns = "o.id, o.tenant_id, o.name, o.slug, o.created_at, o.updated_at"
+
+// GetOrganizations retrieves the organizations of the request's tenant the user is a member of,
+// by name, with the user's role in each of them.
+func (s *OrganizationService) GetOrganizations(ctx context.Context, userID string, page, limit int) ([]models.Organization, int, int, error) {
+	organizations := []models.Organization{}
+	var totalItems int
+
+	tenantID := tenancy.ID(ctx)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(o.id) FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id AND m.user_id = $2
+		WHERE o.tenant_id = $1`,
+		tenantID, userID,
+	).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count organizations: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+organizationColumns+`, r.name
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id AND m.user_id = $2
+		JOIN roles r ON r.id = m.role_id
+		WHERE o.tenant_id = $1
+		ORDER BY o.name ASC
+		LIMIT $3 OFFSET $4`,
+		tenantID, userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var organization models.Organization
+		err := rows.Scan(&organization.ID, &organization.TenantID, &organization.Name, &organization.Slug,
+			&organization.CreatedAt, &organization.UpdatedAt, &organization.Role)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning organization row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		organizations = append(organizations, organization)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating organization rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return organizations, totalPages, totalItems, nil
+}
+
+// GetOrganizationByID fetches an organization of the request's tenant by its ID.
+func (s *OrganizationService) GetOrganizationByID(ctx context.Context, id string) (*models.Organization, error) {
+	organization := &models.Organization{}
+	err := s.db.QueryRowContext(ctx,
+		"SELECT "+organizationColumns+" FROM organizations o WHERE o.id = $1 AND o.tenant_id = $2", id, tenancy.ID(ctx),
+	).Scan(&organization.ID, &organization.TenantID, &organization.Name, &organization.Slug, &organization.CreatedAt, &organization.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil // Organization not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching organization", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch organization by ID: %w", err)
+	}
+	return organization, nil
+}
+
+// CreateOrganization creates an organization in the request's tenant, with creatorID as its
+// first member, who manages it (OrganizationAdminRole).
+func (s *OrganizationService) CreateOrganization(ctx context.Context, organization *models.Organization, creatorID string) error {
+	organization.ID = uuid.New().String()
+	organization.TenantID = tenancy.ID(ctx)
+	organization.Role = models.OrganizationAdminRole
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = time.Now()
+
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := checkOrganizationSlug(ctx, tx, organization); err != nil {
+			return err
+		}
+		roleID, err := roleIDByName(ctx, tx, models.OrganizationAdminRole)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO organizations (id, tenant_id, name, slug, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			organization.ID, organization.TenantID, organization.Name, organization.Slug, organization.CreatedAt, organization.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO organization_members (organization_id, user_id, role_id, created_at) VALUES ($1, $2, $3, $4)",
+			organization.ID, creatorID, roleID, organization.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add organization member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating organization", "organization_name", organization.Name, "error", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateOrganization changes the name and slug of an organization.
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, organization *models.Organization) error {
+	organization.UpdatedAt = time.Now()
+
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := checkOrganizationSlug(ctx, tx, organization); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"UPDATE organizations SET name = $1, slug = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5",
+			organization.Name, organization.Slug, organization.UpdatedAt, organization.ID, tenancy.ID(ctx),
+		)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error updating organization", "organization_id", organization.ID, "error", err)
+			return fmt.Errorf("failed to update organization: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check rows affected after update: %w", err)
+		}
+		if rowsAffected == 0 {
+			return notFoundf("organization with ID %s not found for update", organization.ID)
+		}
+		return nil
+	})
+}
+
+// DeleteOrganization deletes an organization by its ID. Its memberships and invitations are
+// removed by ON DELETE CASCADE.
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM organizations WHERE id = $1 AND tenant_id = $2", id, tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting organization", "id", id, "error", err)
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("organization with ID %s not found for deletion", id)
+	}
+	return nil
+}
+
+// GetMembers lists the members of an organization with their roles in it, by username. Users
+// in the trash are left out.
+func (s *OrganizationService) GetMembers(ctx context.Context, organizationID string) ([]models.OrganizationMember, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id, u.username, u.email, r.id, r.name, m.created_at
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id AND o.tenant_id = $2
+		JOIN users u ON u.id = m.user_id AND `+database.NotDeleted("u")+`
+		JOIN roles r ON r.id = m.role_id
+		WHERE m.organization_id = $1
+		ORDER BY u.username ASC`,
+		organizationID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organization members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.OrganizationMember{}
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Email, &member.RoleID, &member.RoleName, &member.JoinedAt); err != nil {
+			logging.FromContext(ctx).Error("Error scanning organization member row", "error", err)
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organization member rows: %w", err)
+	}
+	return members, nil
+}
+
+// GetMemberRole returns the name of the role of a user in an organization of the request's
+// tenant, or "" if the user isn't a member of it.
+func (s *OrganizationService) GetMemberRole(ctx context.Context, organizationID, userID string) (string, error) {
+	var role string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT r.name FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id AND o.tenant_id = $3
+		JOIN roles r ON r.id = m.role_id
+		WHERE m.organization_id = $1 AND m.user_id = $2`,
+		organizationID, userID, tenancy.ID(ctx),
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil // Not a member
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch organization member role: %w", err)
+	}
+	return role, nil
+}
+
+// SetMemberRole assigns the role with the given name to a member of an organization. The last
+// member managing the organization keeps OrganizationAdminRole.
+func (s *OrganizationService) SetMemberRole(ctx context.Context, organizationID, userID, role string) error {
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := lockOrganization(ctx, tx, organizationID); err != nil {
+			return err
+		}
+		current, err := organizationRole(ctx, tx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return notFoundf("user %s is not a member of the organization", userID)
+		}
+		roleID, err := roleIDByName(ctx, tx, role)
+		if err != nil {
+			return err
+		}
+		if current == models.OrganizationAdminRole && role != models.OrganizationAdminRole {
+			if err := keepOrganizationAdmin(ctx, tx, organizationID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE organization_members SET role_id = $1 WHERE organization_id = $2 AND user_id = $3", roleID, organizationID, userID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update organization member role: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveMember removes a user from an organization. The last member managing it can't be removed.
+func (s *OrganizationService) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := lockOrganization(ctx, tx, organizationID); err != nil {
+			return err
+		}
+		current, err := organizationRole(ctx, tx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return notFoundf("user %s is not a member of the organization", userID)
+		}
+		if current == models.OrganizationAdminRole {
+			if err := keepOrganizationAdmin(ctx, tx, organizationID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2", organizationID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove organization member: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetInvitations lists the pending invitations of an organization, newest first.
+func (s *OrganizationService) GetInvitations(ctx context.Context, organizationID string) ([]models.OrganizationInvitation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT i.id, i.organization_id, i.email, r.id, r.name, i.invited_by, i.expires_at, i.created_at
+		FROM organization_invitations i
+		JOIN organizations o ON o.id = i.organization_id AND o.tenant_id = $2
+		JOIN roles r ON r.id = i.role_id
+		WHERE i.organization_id = $1 AND i.expires_at > $3
+		ORDER BY i.created_at DESC`,
+		organizationID, tenancy.ID(ctx), time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organization invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.OrganizationInvitation{}
+	for rows.Next() {
+		var invitation models.OrganizationInvitation
+		err := rows.Scan(&invitation.ID, &invitation.OrganizationID, &invitation.Email, &invitation.RoleID, &invitation.RoleName,
+			&invitation.InvitedBy, &invitation.ExpiresAt, &invitation.CreatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning organization invitation row", "error", err)
+			return nil, fmt.Errorf("failed to scan organization invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organization invitation rows: %w", err)
+	}
+	return invitations, nil
+}
+
+// Invite invites invitation.Email to an organization with the role named invitation.RoleName,
+// replacing an earlier invitation of the address. The token of the acceptance link is set on
+// invitation, so it can be shown to the inviting user once, and emailed to the invitee when a
+// mailer is configured.
+func (s *OrganizationService) Invite(ctx context.Context, invitation *models.OrganizationInvitation) error {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	invitation.ID = uuid.New().String()
+	invitation.Email = strings.ToLower(invitation.Email)
+	invitation.Token = hex.EncodeToString(tokenBytes)
+	invitation.CreatedAt = time.Now()
+	invitation.ExpiresAt = invitation.CreatedAt.Add(s.invitationTTL)
+
+	var organizationName, inviterName string
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := lockOrganization(ctx, tx, invitation.OrganizationID); err != nil {
+			return err
+		}
+		roleID, err := roleIDByName(ctx, tx, invitation.RoleName)
+		if err != nil {
+			return err
+		}
+		invitation.RoleID = roleID
+
+		var members int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(m.user_id) FROM organization_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.organization_id = $1 AND LOWER(u.email) = $2`,
+			invitation.OrganizationID, invitation.Email,
+		).Scan(&members)
+		if err != nil {
+			return fmt.Errorf("failed to check organization members: %w", err)
+		}
+		if members > 0 {
+			return conflictf("%s is already a member of the organization", invitation.Email)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM organization_invitations WHERE organization_id = $1 AND email = $2", invitation.OrganizationID, invitation.Email,
+		); err != nil {
+			return fmt.Errorf("failed to delete earlier invitation: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO organization_invitations (id, organization_id, email, role_id, token, invited_by, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			invitation.ID, invitation.OrganizationID, invitation.Email, invitation.RoleID, hashToken(invitation.Token),
+			invitation.InvitedBy, invitation.ExpiresAt, invitation.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create invitation: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, "SELECT name FROM organizations WHERE id = $1", invitation.OrganizationID).Scan(&organizationName)
+		if err != nil {
+			return fmt.Errorf("failed to fetch organization name: %w", err)
+		}
+		if invitation.InvitedBy != nil {
+			err = tx.QueryRowContext(ctx, "SELECT username FROM users WHERE id = $1", *invitation.InvitedBy).Scan(&inviterName)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to fetch inviting user: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating invitation", "organization_id", invitation.OrganizationID, "error", err)
+		return err
+	}
+
+	if s.mailer == nil {
+		return nil
+	}
+	if inviterName == "" {
+		inviterName = "Someone"
+	}
+	err = s.mailer.Send(ctx, notifications.Recipient{Email: invitation.Email}, &models.Notification{
+		Type:  models.NotificationTypeInvitation,
+		Title: fmt.Sprintf("You are invited to join %s on %s", organizationName, config.AppConfig.SiteTitle),
+		Body: fmt.Sprintf("Hi, %s invited you to join %s on %s as %s.\n\n"+
+			"Accept the invitation with the link below by %s, logged in with this email address:\n%s",
+			inviterName, organizationName, config.AppConfig.SiteTitle, invitation.RoleName,
+			invitation.ExpiresAt.UTC().Format(time.RFC1123), s.invitationURL+"?token="+invitation.Token),
+	})
+	if err != nil {
+		// The invitation stands; its link can still be passed on by the inviting user
+		logging.FromContext(ctx).Warn("Failed to email invitation", "organization_id", invitation.OrganizationID, "error", err)
+	}
+	return nil
+}
+
+// DeleteInvitation withdraws a pending invitation to an organization.
+func (s *OrganizationService) DeleteInvitation(ctx context.Context, organizationID, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM organization_invitations
+		WHERE id = $1 AND organization_id = $2
+		AND organization_id IN (SELECT id FROM organizations WHERE tenant_id = $3)`,
+		id, organizationID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting invitation", "id", id, "error", err)
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("invitation with ID %s not found for deletion", id)
+	}
+	return nil
+}
+
+// AcceptInvitation makes the user with userID and email a member of the organization the
+// invitation with token was sent to, with its role, and returns the organization. Invitations
+// can only be accepted by a user of the tenant of the organization with the invited address;
+// accepting deletes them.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, token, userID, email string) (*models.Organization, error) {
+	var organization *models.Organization
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		var invitationID, organizationID, invitedEmail, roleID string
+		err := tx.QueryRowContext(ctx, `
+			SELECT i.id, i.organization_id, i.email, i.role_id FROM organization_invitations i
+			JOIN organizations o ON o.id = i.organization_id AND o.tenant_id = $2
+			WHERE i.token = $1 AND i.expires_at > $3`,
+			hashToken(token), tenancy.ID(ctx), time.Now(),
+		).Scan(&invitationID, &organizationID, &invitedEmail, &roleID)
+		if err == sql.ErrNoRows {
+			return notFoundf("invitation not found or expired")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch invitation: %w", err)
+		}
+		if !strings.EqualFold(invitedEmail, email) {
+			return invalidf("the invitation was sent to another email address")
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM organization_invitations WHERE id = $1", invitationID); err != nil {
+			return fmt.Errorf("failed to delete invitation: %w", err)
+		}
+		current, err := organizationRole(ctx, tx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" { // Accepting twice (e.g., from two tabs) keeps the membership
+			_, err = tx.ExecContext(ctx,
+				"INSERT INTO organization_members (organization_id, user_id, role_id, created_at) VALUES ($1, $2, $3, $4)",
+				organizationID, userID, roleID, time.Now(),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to add organization member: %w", err)
+			}
+		}
+
+		organization = &models.Organization{}
+		err = tx.QueryRowContext(ctx, `
+			SELECT `+organizationColumns+`, r.name FROM organizations o
+			JOIN organization_members m ON m.organization_id = o.id AND m.user_id = $2
+			JOIN roles r ON r.id = m.role_id
+			WHERE o.id = $1`,
+			organizationID, userID,
+		).Scan(&organization.ID, &organization.TenantID, &organization.Name, &organization.Slug,
+			&organization.CreatedAt, &organization.UpdatedAt, &organization.Role)
+		if err != nil {
+			return fmt.Errorf("failed to fetch organization: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("Invitation accepted", "organization_id", organization.ID, "user_id", userID)
+	return organization, nil
+}
+
+// checkOrganizationSlug fails with a conflict if another organization of the request's tenant
+// has the slug of organization.
+func checkOrganizationSlug(ctx context.Context, tx *database.Tx, organization *models.Organization) error {
+	var taken int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(id) FROM organizations WHERE tenant_id = $1 AND slug = $2 AND id <> $3",
+		tenancy.ID(ctx), organization.Slug, organization.ID,
+	).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check organization slug: %w", err)
+	}
+	if taken > 0 {
+		return conflictf("an organization with the slug %s already exists", organization.Slug)
+	}
+	return nil
+}
+
+// roleIDByName returns the ID of the role with the given name, failing validation if there
+// is none.
+func roleIDByName(ctx context.Context, tx *database.Tx, name string) (string, error) {
+	var id string
+	err := tx.QueryRowContext(ctx, "SELECT id FROM roles WHERE name = $1 AND "+database.NotDeleted(""), name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", invalidf("role %s does not exist", name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch role: %w", err)
+	}
+	return id, nil
+}
+
+// lockOrganization locks the row of an organization of the request's tenant for the rest of tx,
+// so concurrent membership changes can't leave it without a member managing it.
+func lockOrganization(ctx context.Context, tx *database.Tx, organizationID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx,
+		"SELECT id FROM organizations WHERE id = $1 AND tenant_id = $2"+tx.Dialect().ForUpdate(false), organizationID, tenancy.ID(ctx),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return notFoundf("organization with ID %s not found", organizationID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock organization: %w", err)
+	}
+	return nil
+}
+
+// organizationRole returns the name of the role of a user in an organization, or "" if the
+// user isn't a member of it.
+func organizationRole(ctx context.Context, tx *database.Tx, organizationID, userID string) (string, error) {
+	var role string
+	err := tx.QueryRowContext(ctx, `
+		SELECT r.name FROM organization_members m
+		JOIN roles r ON r.id = m.role_id
+		WHERE m.organization_id = $1 AND m.user_id = $2`,
+		organizationID, userID,
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch organization member role: %w", err)
+	}
+	return role, nil
+}
+
+// keepOrganizationAdmin fails with a conflict if an organization has a single member with
+// OrganizationAdminRole, who is about to lose it or leave.
+func keepOrganizationAdmin(ctx context.Context, tx *database.Tx, organizationID string) error {
+	var admins int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(m.user_id) FROM organization_members m
+		JOIN roles r ON r.id = m.role_id
+		WHERE m.organization_id = $1 AND r.name = $2`,
+		organizationID, models.OrganizationAdminRole,
+	).Scan(&admins)
+	if err != nil {
+		return fmt.Errorf("failed to count organization admins: %w", err)
+	}
+	if admins <= 1 {
+		return conflictf("an organization must keep at least one member with the %s role", models.OrganizationAdminRole)
+	}
+	return nil
+}
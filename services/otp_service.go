@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+)
+
+// otpResendInterval is how long a user has to wait before another login code is sent, so the
+// SMS login can't be used to flood a phone with messages (or run up the gateway's bill).
+const otpResendInterval = time.Minute
+
+// OTPServiceInterface defines the methods that any one-time code service implementation must provide.
+type OTPServiceInterface interface {
+	CreateOTP(ctx context.Context, userID string) (string, error)     // Empty while a code was sent less than a minute ago
+	VerifyOTP(ctx context.Context, userID, code string) (bool, error) // True at most once per code
+}
+
+// OTPService keeps the one-time login codes sent to users by SMS, implementing
+// OTPServiceInterface. A user has at most one pending code, of which only the SHA-256 hash is
+// stored. It expires after ttl, or after maxAttempts wrong guesses.
+type OTPService struct {
+	db          *database.DB // Database connection pool
+	ttl         time.Duration
+	maxAttempts int
+}
+
+// NewOTPService creates and returns a new OTPService instance using the given connection pool.
+func NewOTPService(db *database.DB, ttl time.Duration, maxAttempts int) *OTPService {
+	return &OTPService{db: db, ttl: ttl, maxAttempts: maxAttempts}
+}
+
+// CreateOTP generates a random 6-digit login code for the user, replacing their pending one,
+// and returns it to be sent. It returns an empty code, and keeps the pending one, if that was
+// created less than otpResendInterval ago.
+func (s *OTPService) CreateOTP(ctx context.Context, userID string) (string, error) {
+	var createdAt time.Time
+	err := s.db.QueryRowContext(ctx, "SELECT created_at FROM login_otps WHERE user_id = $1", userID).Scan(&createdAt)
+	if err != nil && err != sql.ErrNoRows {
+		logging.FromContext(ctx).Error("Error fetching login code", "user_id", userID, "error", err)
+		return "", fmt.Errorf("failed to fetch login code: %w", err)
+	}
+	now := time.Now()
+	if err == nil && now.Sub(createdAt) < otpResendInterval {
+		return "", nil
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	err = database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM login_otps WHERE user_id = $1", userID); err != nil {
+			return err
+		}
+		query := "INSERT INTO login_otps (id, user_id, code_hash, attempts, expires_at, created_at) VALUES ($1, $2, $3, 0, $4, $5)"
+		_, err := tx.ExecContext(ctx, query, uuid.New().String(), userID, hashToken(code), now.Add(s.ttl), now)
+		return err
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating login code", "user_id", userID, "error", err)
+		return "", fmt.Errorf("failed to create login code: %w", err)
+	}
+	return code, nil
+}
+
+// VerifyOTP reports whether code is the pending, unexpired login code of the user. A correct
+// code is deleted, so it can't be used twice; a wrong one counts as an attempt, and the code is
+// deleted once maxAttempts is reached.
+func (s *OTPService) VerifyOTP(ctx context.Context, userID, code string) (bool, error) {
+	var id, codeHash string
+	var attempts int
+	query := "SELECT id, code_hash, attempts FROM login_otps WHERE user_id = $1 AND expires_at > $2"
+	err := s.db.QueryRowContext(ctx, query, userID, time.Now()).Scan(&id, &codeHash, &attempts)
+	if err == sql.ErrNoRows {
+		return false, nil // No code sent, or expired
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching login code", "user_id", userID, "error", err)
+		return false, fmt.Errorf("failed to fetch login code: %w", err)
+	}
+
+	if attempts < s.maxAttempts && subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(codeHash)) == 1 {
+		// Only the request deleting the code logs in, should the code be sent twice concurrently
+		result, err := s.db.ExecContext(ctx, "DELETE FROM login_otps WHERE id = $1", id)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error deleting login code", "user_id", userID, "error", err)
+			return false, fmt.Errorf("failed to delete login code: %w", err)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("failed to check rows affected after deleting login code: %w", err)
+		}
+		return deleted == 1, nil
+	}
+
+	if attempts+1 >= s.maxAttempts {
+		_, err = s.db.ExecContext(ctx, "DELETE FROM login_otps WHERE id = $1", id)
+	} else {
+		_, err = s.db.ExecContext(ctx, "UPDATE login_otps SET attempts = attempts + 1 WHERE id = $1", id)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error counting login code attempt", "user_id", userID, "error", err)
+		return false, fmt.Errorf("failed to count login code attempt: %w", err)
+	}
+	return false, nil
+}
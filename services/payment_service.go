@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/anpsniper/anpbayu-be/webhooks"
+	"github.com/google/uuid"
+)
+
+// PaymentServiceInterface defines the methods that any payment service implementation must provide.
+type PaymentServiceInterface interface {
+	GetPaymentsByOrderID(ctx context.Context, orderID string) ([]models.Payment, error)
+	CreatePayment(ctx context.Context, payment *models.Payment) error
+	AttachCheckout(ctx context.Context, payment *models.Payment) error
+	ApplyPaymentEvent(ctx context.Context, provider, providerRef, status string) (*models.Payment, error)
+}
+
+// PaymentService provides methods for payment records, implementing PaymentServiceInterface.
+// Talking to the gateways themselves is done by the payments package.
+type PaymentService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewPaymentService creates and returns a new PaymentService instance using the given connection pool.
+func NewPaymentService(db *database.DB) *PaymentService {
+	return &PaymentService{db: db}
+}
+
+const paymentColumns = "id, order_id, provider, COALESCE(provider_ref, ''), status, amount, currency, COALESCE(checkout_url, ''), refund_due, created_at, updated_at"
+
+// GetPaymentsByOrderID fetches all checkout attempts of an order of the request's tenant, newest first.
+func (s *PaymentService) GetPaymentsByOrderID(ctx context.Context, orderID string) ([]models.Payment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE order_id = $1 AND order_id IN (SELECT id FROM orders WHERE tenant_id = $2) ORDER BY created_at DESC",
+		orderID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning payment row", "error", err)
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, *payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payment rows: %w", err)
+	}
+	return payments, nil
+}
+
+// CreatePayment inserts a pending payment before the provider is contacted,
+// so its ID can be handed to the provider as a reference.
+func (s *PaymentService) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	payment.ID = uuid.New().String()
+	payment.Status = models.PaymentStatusPending
+	payment.CreatedAt = time.Now()
+	payment.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO payments (id, order_id, provider, status, amount, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.db.ExecContext(ctx, query, payment.ID, payment.OrderID, payment.Provider, payment.Status, payment.Amount, payment.Currency, payment.CreatedAt, payment.UpdatedAt)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating payment for order", "order_id", payment.OrderID, "error", err)
+		return fmt.Errorf("failed to create payment: %w", err)
+	}
+	return nil
+}
+
+// AttachCheckout stores the provider reference and checkout URL returned by the provider.
+// A failed order becomes pending again, since the customer is retrying the payment.
+func (s *PaymentService) AttachCheckout(ctx context.Context, payment *models.Payment) error {
+	payment.UpdatedAt = time.Now()
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE payments SET provider_ref = $1, checkout_url = $2, updated_at = $3 WHERE id = $4`,
+			payment.ProviderRef, payment.CheckoutURL, payment.UpdatedAt, payment.ID,
+		)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error attaching checkout to payment", "payment_id", payment.ID, "error", err)
+			return fmt.Errorf("failed to attach checkout to payment: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE orders SET status = $1 WHERE id = $2 AND status = $3`, models.OrderStatusPending, payment.OrderID, models.OrderStatusFailed)
+		if err != nil {
+			return fmt.Errorf("failed to reopen order: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ApplyPaymentEvent records a provider-reported status on the matching payment and moves the
+// order along: a succeeded payment marks it paid, a failed one marks it failed unless it was
+// already paid. A payment succeeding for an order another payment already paid, e.g., when the
+// customer completed two checkouts, is flagged as refund due instead. Settled payments are
+// never changed, so redelivered webhooks are harmless.
+// Webhooks don't act for a tenant, so the payment is looked up across all tenants.
+func (s *PaymentService) ApplyPaymentEvent(ctx context.Context, provider, providerRef, status string) (*models.Payment, error) {
+	var payment *models.Payment
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		var err error
+		payment, err = scanPayment(tx.QueryRowContext(ctx,
+			"SELECT "+paymentColumns+" FROM payments WHERE provider = $1 AND provider_ref = $2"+tx.Dialect().ForUpdate(false),
+			provider, providerRef,
+		))
+		if err == sql.ErrNoRows {
+			return notFoundf("payment with %s reference %s not found", provider, providerRef)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch payment: %w", err)
+		}
+
+		if payment.Status != models.PaymentStatusPending || status == models.PaymentStatusPending {
+			return nil // Already settled, or nothing to change
+		}
+
+		payment.Status = status
+		payment.UpdatedAt = time.Now()
+		if _, err := tx.ExecContext(ctx, `UPDATE payments SET status = $1, updated_at = $2 WHERE id = $3`, payment.Status, payment.UpdatedAt, payment.ID); err != nil {
+			logging.FromContext(ctx).Error("Error updating payment", "payment_id", payment.ID, "error", err)
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		switch status {
+		case models.PaymentStatusSucceeded:
+			var previousStatus, tenantID string
+			err = tx.QueryRowContext(ctx, `SELECT status, tenant_id FROM orders WHERE id = $1`+tx.Dialect().ForUpdate(false), payment.OrderID).Scan(&previousStatus, &tenantID)
+			if err == nil && previousStatus == models.OrderStatusPaid {
+				logging.FromContext(ctx).Warn("order was paid twice; the payment needs a refund", "order_id", payment.OrderID, "payment_id", payment.ID)
+				payment.RefundDue = true
+				_, err = tx.ExecContext(ctx, `UPDATE payments SET refund_due = $1 WHERE id = $2`, true, payment.ID)
+				break
+			}
+			if err == nil && previousStatus == models.OrderStatusCancelled {
+				// The reservation expired and the stock was released before the money arrived
+				logging.FromContext(ctx).Warn("order was paid after its stock reservation expired; stock needs manual review", "order_id", payment.OrderID)
+			}
+			if err == nil {
+				_, err = tx.ExecContext(ctx, `UPDATE orders SET status = $1, reserved_until = NULL WHERE id = $2`, models.OrderStatusPaid, payment.OrderID)
+			}
+			if err == nil {
+				err = enqueueWebhookEvent(ctx, tx, tenantID, webhooks.EventOrderPaid, payment)
+			}
+		case models.PaymentStatusFailed:
+			_, err = tx.ExecContext(ctx, `UPDATE orders SET status = $1 WHERE id = $2 AND status = $3`, models.OrderStatusFailed, payment.OrderID, models.OrderStatusPending)
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("Error updating order after payment", "order_id", payment.OrderID, "payment_id", payment.ID, "error", err)
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// scanPayment scans a payment row selected with paymentColumns.
+func scanPayment(row rowScanner) (*models.Payment, error) {
+	payment := &models.Payment{}
+	err := row.Scan(
+		&payment.ID, &payment.OrderID, &payment.Provider, &payment.ProviderRef, &payment.Status,
+		&payment.Amount, &payment.Currency, &payment.CheckoutURL, &payment.RefundDue, &payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/anpsniper/anpbayu-be/utils"
+	"github.com/google/uuid"
+)
+
+// PostServiceInterface defines the methods that any post service implementation must provide.
+type PostServiceInterface interface {
+	GetAllPosts(ctx context.Context, search, tag, category string, fields models.Fields, page, limit int) ([]models.Post, int, int, error) // Returns posts, totalPages, totalItems
+	GetPostByID(ctx context.Context, id string) (*models.Post, error)
+	CreatePost(ctx context.Context, post *models.Post) error
+	UpdatePost(ctx context.Context, post *models.Post) error
+	DeletePost(ctx context.Context, id string) error
+	GetPublishedFeed(ctx context.Context, page, limit int) ([]models.FeedItem, int, int, error) // Returns feed items, totalPages, totalItems
+	ClaimLivePosts(ctx context.Context, now time.Time, limit int) ([]models.Post, error)
+}
+
+// PostService provides methods for post-related business logic, implementing PostServiceInterface.
+type PostService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewPostService creates and returns a new PostService instance using the given connection pool.
+func NewPostService(db *database.DB) *PostService {
+	return &PostService{db: db}
+}
+
+// GetAllPosts retrieves a list of posts with optional search, tag slug, category slug filtering and pagination.
+// The contents, the widest column, and the tags are only read when fields selects them (or
+// content_html, rendered from the contents).
+func (s *PostService) GetAllPosts(ctx context.Context, search, tag, category string, fields models.Fields, page, limit int) ([]models.Post, int, int, error) {
+	posts := []models.Post{}
+	var totalItems int
+
+	content := "p.content"
+	if !fields.Has("content") && !fields.Has("content_html") {
+		content = "''"
+	}
+	countQuery := "SELECT COUNT(p.id) FROM posts p LEFT JOIN categories c ON p.category_id = c.id WHERE p.tenant_id = $1 AND " + database.NotDeleted("p")
+	selectQuery := "SELECT p.id, p.user_id, p.title, " + content + ", p.category_id, c.name, p.published_at, p.created_at, p.updated_at FROM posts p LEFT JOIN categories c ON p.category_id = c.id WHERE p.tenant_id = $1 AND " + database.NotDeleted("p")
+	args := []interface{}{tenancy.ID(ctx)}
+	argCounter := 2
+
+	if search != "" {
+		searchPattern := "%" + search + "%"
+		ilike := s.db.Dialect().ILike
+		countQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("p.title", argCounter), ilike("p.content", argCounter+1))
+		selectQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("p.title", argCounter), ilike("p.content", argCounter+1))
+		args = append(args, searchPattern, searchPattern)
+		argCounter += 2
+	}
+
+	// Filter by tag slug (e.g., ?tag=go)
+	if tag != "" {
+		tagFilter := fmt.Sprintf(" AND EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON pt.tag_id = t.id WHERE pt.post_id = p.id AND t.slug = $%d)", argCounter)
+		countQuery += tagFilter
+		selectQuery += tagFilter
+		args = append(args, tag)
+		argCounter++
+	}
+
+	// Filter by category slug (e.g., ?category=news)
+	if category != "" {
+		countQuery += fmt.Sprintf(" AND c.slug = $%d", argCounter)
+		selectQuery += fmt.Sprintf(" AND c.slug = $%d", argCounter)
+		args = append(args, category)
+		argCounter++
+	}
+
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count posts: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	selectQuery += fmt.Sprintf(" ORDER BY p.created_at DESC LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning post row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, *post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating post rows: %w", err)
+	}
+
+	if fields.Has("tags") {
+		if err := s.loadPostTags(ctx, posts); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return posts, totalPages, totalItems, nil
+}
+
+// GetPostByID fetches a post by its ID, including its category name and tags.
+func (s *PostService) GetPostByID(ctx context.Context, id string) (*models.Post, error) {
+	query := `
+		SELECT p.id, p.user_id, p.title, p.content, p.category_id, c.name, p.published_at, p.created_at, p.updated_at
+		FROM posts p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.id = $1 AND p.tenant_id = $2 AND ` + database.NotDeleted("p") + `
+	`
+	post, err := scanPost(s.db.QueryRowContext(ctx, query, id, tenancy.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil // Post not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching post", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch post by ID: %w", err)
+	}
+
+	posts := []models.Post{*post}
+	if err := s.loadPostTags(ctx, posts); err != nil {
+		return nil, err
+	}
+	return &posts[0], nil
+}
+
+// CreatePost inserts a new post and links its tags, creating any tags that don't exist yet.
+func (s *PostService) CreatePost(ctx context.Context, post *models.Post) error {
+	post.ID = uuid.New().String()
+	post.CreatedAt = time.Now()
+	post.UpdatedAt = time.Now()
+
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		query := `
+			INSERT INTO posts (id, tenant_id, user_id, title, content, category_id, published_at, announced_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`
+		var announcedAt *time.Time // Scheduled posts are announced by ClaimLivePosts once they go live
+		if !isScheduled(post.PublishedAt, post.CreatedAt) {
+			announcedAt = post.PublishedAt
+		}
+		_, err := tx.ExecContext(ctx, query, post.ID, tenancy.ID(ctx), post.UserID, post.Title, post.Content, post.CategoryID, post.PublishedAt, announcedAt, post.CreatedAt, post.UpdatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error creating post", "post_title", post.Title, "error", err)
+			return fmt.Errorf("failed to create post: %w", err)
+		}
+
+		if err := setPostTags(ctx, tx, post.ID, post.Tags); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, models.AuditActionCreate, models.AuditEntityPost, post.ID, nil, post)
+	})
+}
+
+// UpdatePost updates a post's title, content, category and publication time and replaces its tags.
+func (s *PostService) UpdatePost(ctx context.Context, post *models.Post) error {
+	before, err := s.GetPostByID(ctx, post.ID) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	post.UpdatedAt = time.Now()
+
+	// Rescheduled posts are announced again when they go live; posts published right away
+	// by the update need no announcement
+	announcement := "COALESCE(announced_at, $5)"
+	if isScheduled(post.PublishedAt, post.UpdatedAt) {
+		announcement = "NULL"
+	}
+
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		query := `
+			UPDATE posts
+			SET title = $1, content = $2, category_id = $3, published_at = $4, updated_at = $5, announced_at = ` + announcement + `
+			WHERE id = $6 AND tenant_id = $7 AND ` + database.NotDeleted("") + `
+		`
+		result, err := tx.ExecContext(ctx, query, post.Title, post.Content, post.CategoryID, post.PublishedAt, post.UpdatedAt, post.ID, tenancy.ID(ctx))
+		if err != nil {
+			logging.FromContext(ctx).Error("Error updating post", "post_id", post.ID, "error", err)
+			return fmt.Errorf("failed to update post: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check rows affected after update: %w", err)
+		}
+		if rowsAffected == 0 {
+			return notFoundf("post with ID %s not found for update", post.ID)
+		}
+
+		if err := setPostTags(ctx, tx, post.ID, post.Tags); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, models.AuditActionUpdate, models.AuditEntityPost, post.ID, before, post)
+	})
+}
+
+// isScheduled reports whether a post with the given publication time is a draft or scheduled
+// to go live after now.
+func isScheduled(publishedAt *time.Time, now time.Time) bool {
+	return publishedAt == nil || publishedAt.After(now)
+}
+
+// ClaimLivePosts marks up to limit scheduled posts of all tenants that went live by now as
+// announced and returns them (ID, author, title and publication time), so their authors can
+// be told. Each post is claimed once, also when several instances claim at the same time.
+func (s *PostService) ClaimLivePosts(ctx context.Context, now time.Time, limit int) ([]models.Post, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, title, published_at FROM posts
+		WHERE announced_at IS NULL AND published_at IS NOT NULL AND published_at <= $1 AND `+database.NotDeleted("")+`
+		ORDER BY published_at
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query live posts: %w", err)
+	}
+	var candidates []models.Post
+	for rows.Next() {
+		var post models.Post
+		if err := rows.Scan(&post.ID, &post.UserID, &post.Title, &post.PublishedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan live post: %w", err)
+		}
+		candidates = append(candidates, post)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating live post rows: %w", err)
+	}
+
+	claimed := []models.Post{}
+	for _, post := range candidates {
+		result, err := s.db.ExecContext(ctx, "UPDATE posts SET announced_at = $1 WHERE id = $2 AND announced_at IS NULL", now, post.ID)
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim live post: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return claimed, fmt.Errorf("failed to check rows affected after claiming live post: %w", err)
+		} else if n == 1 {
+			claimed = append(claimed, post) // Not claimed by another instance in the meantime
+		}
+	}
+	return claimed, nil
+}
+
+// DeletePost moves a post to the trash by setting its deleted_at.
+// Trashed posts can be restored or purged through the trash endpoints.
+func (s *PostService) DeletePost(ctx context.Context, id string) error {
+	before, err := s.GetPostByID(ctx, id) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	deleted, err := database.SoftDelete(ctx, s.db, "posts", id)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting post", "id", id, "error", err)
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+	if !deleted {
+		return notFoundf("post with ID %s not found for deletion", id)
+	}
+
+	auditChange(ctx, s.db, models.AuditActionDelete, models.AuditEntityPost, id, before, nil)
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPost scans a post row selected as
+// (id, user_id, title, content, category_id, category name, published_at, created_at, updated_at).
+func scanPost(row rowScanner) (*models.Post, error) {
+	post := &models.Post{Tags: []string{}}
+	var categoryID, categoryName sql.NullString
+	var publishedAt sql.NullTime
+	err := row.Scan(&post.ID, &post.UserID, &post.Title, &post.Content, &categoryID, &categoryName, &publishedAt, &post.CreatedAt, &post.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if categoryID.Valid {
+		post.CategoryID = &categoryID.String
+	}
+	if publishedAt.Valid {
+		post.PublishedAt = &publishedAt.Time
+	}
+	post.CategoryName = categoryName.String
+	return post, nil
+}
+
+// loadPostTags fills in the Tags field of every post with a single query.
+func (s *PostService) loadPostTags(ctx context.Context, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(posts))
+	index := make(map[string]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		index[post.ID] = i
+	}
+
+	query := `
+		SELECT pt.post_id, t.name
+		FROM post_tags pt
+		JOIN tags t ON pt.tag_id = t.id
+		WHERE pt.post_id IN (` + database.Placeholders(1, len(ids)) + `)
+		ORDER BY t.name ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return fmt.Errorf("failed to query post tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, name string
+		if err := rows.Scan(&postID, &name); err != nil {
+			return fmt.Errorf("failed to scan post tag: %w", err)
+		}
+		if i, ok := index[postID]; ok {
+			posts[i].Tags = append(posts[i].Tags, name)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating post tag rows: %w", err)
+	}
+	return nil
+}
+
+// setPostTags replaces the tags linked to a post. Tags are matched by slug and
+// created on the fly when they don't exist yet.
+func setPostTags(ctx context.Context, tx *database.Tx, postID string, tagNames []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM post_tags WHERE post_id = $1`, postID); err != nil {
+		return fmt.Errorf("failed to clear post tags: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range tagNames {
+		name = strings.TrimSpace(name)
+		slug := utils.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		// The insert is skipped when a tag with the slug already exists; either way it's looked up afterwards.
+		now := time.Now()
+		_, err := tx.ExecContext(ctx, tx.Dialect().InsertIgnore(`
+			INSERT INTO tags (id, name, slug, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+		`), uuid.New().String(), name, slug, now)
+		if err != nil {
+			return fmt.Errorf("failed to upsert tag %s: %w", name, err)
+		}
+
+		var tagID string
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM tags WHERE slug = $1`, slug).Scan(&tagID); err != nil {
+			return fmt.Errorf("failed to look up tag %s: %w", name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2)`, postID, tagID); err != nil {
+			return fmt.Errorf("failed to link tag %s to post: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// GetPublishedFeed retrieves published posts (published_at in the past), newest first,
+// together with their author's username, category, tags and comment count.
+func (s *PostService) GetPublishedFeed(ctx context.Context, page, limit int) ([]models.FeedItem, int, int, error) {
+	var totalItems int
+	now := time.Now()
+	tenantID := tenancy.ID(ctx)
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(id) FROM posts WHERE tenant_id = $2 AND `+database.NotDeleted("")+` AND published_at IS NOT NULL AND published_at <= $1`, now, tenantID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count published posts: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	query := `
+		SELECT
+			p.id, p.title, p.content, u.username, c.name, p.published_at,
+			(SELECT COUNT(cm.id) FROM comments cm WHERE cm.post_id = p.id) AS comment_count
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.tenant_id = $4 AND ` + database.NotDeleted("p") + ` AND p.published_at IS NOT NULL AND p.published_at <= $1
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.QueryContext(ctx, query, now, limit, offset, tenantID)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query published posts: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.FeedItem{}
+	posts := []models.Post{} // Used to load tags in one query
+	for rows.Next() {
+		var item models.FeedItem
+		var categoryName sql.NullString
+		err := rows.Scan(&item.ID, &item.Title, &item.Content, &item.AuthorUsername, &categoryName, &item.PublishedAt, &item.CommentCount)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning feed row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan feed item: %w", err)
+		}
+		item.CategoryName = categoryName.String
+		items = append(items, item)
+		posts = append(posts, models.Post{ID: item.ID, Tags: []string{}})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating feed rows: %w", err)
+	}
+
+	if err := s.loadPostTags(ctx, posts); err != nil {
+		return nil, 0, 0, err
+	}
+	for i := range items {
+		items[i].Tags = posts[i].Tags
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return items, totalPages, totalItems, nil
+}
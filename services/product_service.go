@@ -0,0 +1,468 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/google/uuid"
+)
+
+// ProductServiceInterface defines the methods that any product service implementation must provide.
+type ProductServiceInterface interface {
+	GetAllProducts(ctx context.Context, filter models.ProductFilter, page, limit int) ([]models.Product, int, int, error) // Returns products, totalPages, totalItems
+	GetProductByID(ctx context.Context, id string) (*models.Product, error)
+	CreateProduct(ctx context.Context, product *models.Product) error
+	UpdateProduct(ctx context.Context, product *models.Product) error
+	DeleteProduct(ctx context.Context, id string) error
+	ArchiveProduct(ctx context.Context, id string) (*models.Product, error)
+	UnarchiveProduct(ctx context.Context, id string) (*models.Product, error)
+	SetProductImage(ctx context.Context, id string, key *string) (*string, error) // Returns the storage key of the replaced image
+	AdjustStock(ctx context.Context, productID string, delta int, reason, actorID string) (*models.StockMovement, error)
+	GetStockMovements(ctx context.Context, productID string, page, limit int) ([]models.StockMovement, int, int, error) // Returns movements, totalPages, totalItems
+}
+
+// ProductService provides methods for product-related business logic, implementing ProductServiceInterface.
+type ProductService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewProductService creates and returns a new ProductService instance using the given connection pool.
+func NewProductService(db *database.DB) *ProductService {
+	return &ProductService{db: db}
+}
+
+// productSortColumns whitelists the fields products can be sorted by, mapped to their SQL columns.
+var productSortColumns = map[string]string{
+	"name":       "name",
+	"price":      "price",
+	"stock":      "stock",
+	"category":   "category",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// ValidateProductSort checks that every sort field (optionally prefixed with "-") is whitelisted.
+func ValidateProductSort(sort []string) error {
+	for _, field := range sort {
+		if _, ok := productSortColumns[strings.TrimPrefix(field, "-")]; !ok {
+			return invalidf("invalid sort field: %s", field)
+		}
+	}
+	return nil
+}
+
+// productOrderBy builds an ORDER BY clause from whitelisted sort fields, always ending with id
+// so pagination is stable when the requested columns have ties.
+func productOrderBy(sort []string) string {
+	clauses := []string{}
+	for _, field := range sort {
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+		}
+		if column, ok := productSortColumns[strings.TrimPrefix(field, "-")]; ok {
+			clauses = append(clauses, column+" "+direction)
+		}
+	}
+	if len(clauses) == 0 {
+		clauses = append(clauses, "name ASC")
+	}
+	return " ORDER BY " + strings.Join(append(clauses, "id ASC"), ", ")
+}
+
+const (
+	productColumns           = "id, name, " + productDescriptionColumn + ", price, stock, COALESCE(category, ''), archived_at, image_key, version, created_at, updated_at"
+	productDescriptionColumn = "COALESCE(description, '')"
+)
+
+// productColumnsFor returns productColumns, with empty descriptions in place of the
+// descriptions, the widest column, unless fields selects them.
+func productColumnsFor(fields models.Fields) string {
+	if fields.Has("description") {
+		return productColumns
+	}
+	return strings.Replace(productColumns, productDescriptionColumn, "''", 1)
+}
+
+// GetAllProducts retrieves a list of products matching the filter, sorted and paginated.
+// Every user-supplied value is bound as a parameter; only whitelisted column names are interpolated.
+func (s *ProductService) GetAllProducts(ctx context.Context, filter models.ProductFilter, page, limit int) ([]models.Product, int, int, error) {
+	products := []models.Product{}
+	var totalItems int
+
+	where := " WHERE tenant_id = $1 AND " + database.NotDeleted("")
+	args := []interface{}{tenancy.ID(ctx)}
+	argCounter := 2
+
+	switch filter.Archived {
+	case models.ArchivedOnly:
+		where += " AND archived_at IS NOT NULL"
+	case models.ArchivedInclude:
+	default:
+		where += " AND archived_at IS NULL"
+	}
+
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		ilike := s.db.Dialect().ILike
+		where += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("description", argCounter+1))
+		args = append(args, searchPattern, searchPattern)
+		argCounter += 2
+	}
+
+	if filter.Category != "" {
+		where += fmt.Sprintf(" AND category = $%d", argCounter)
+		args = append(args, filter.Category)
+		argCounter++
+	}
+
+	if filter.MinPrice != nil {
+		where += fmt.Sprintf(" AND price >= $%d", argCounter)
+		args = append(args, *filter.MinPrice)
+		argCounter++
+	}
+
+	if filter.MaxPrice != nil {
+		where += fmt.Sprintf(" AND price <= $%d", argCounter)
+		args = append(args, *filter.MaxPrice)
+		argCounter++
+	}
+
+	switch filter.StockStatus {
+	case models.StockStatusInStock:
+		where += " AND stock > 0"
+	case models.StockStatusOutOfStock:
+		where += " AND stock = 0"
+	case models.StockStatusLowStock:
+		where += fmt.Sprintf(" AND stock > 0 AND stock <= $%d", argCounter)
+		args = append(args, models.LowStockThreshold)
+		argCounter++
+	}
+
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM products"+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	selectQuery := "SELECT " + productColumnsFor(filter.Fields) + " FROM products" + where +
+		productOrderBy(filter.Sort) + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning product row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, *product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating product rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return products, totalPages, totalItems, nil
+}
+
+// GetProductByID fetches a product by its ID.
+func (s *ProductService) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
+	product, err := scanProduct(s.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1 AND tenant_id = $2 AND "+database.NotDeleted(""), id, tenancy.ID(ctx)))
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Product not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching product", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch product by ID: %w", err)
+	}
+	return product, nil
+}
+
+// CreateProduct inserts a new product into the database.
+func (s *ProductService) CreateProduct(ctx context.Context, product *models.Product) error {
+	product.ID = uuid.New().String()
+	product.CreatedAt = time.Now()
+	product.UpdatedAt = time.Now()
+	product.Version = 1
+
+	query := `
+		INSERT INTO products (id, tenant_id, name, description, price, stock, category, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.db.ExecContext(ctx, query, product.ID, tenancy.ID(ctx), product.Name, product.Description, product.Price, product.Stock, product.Category, product.CreatedAt, product.UpdatedAt)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating product", "product_name", product.Name, "error", err)
+		return fmt.Errorf("failed to create product: %w", err)
+	}
+	auditChange(ctx, s.db, models.AuditActionCreate, models.AuditEntityProduct, product.ID, nil, product)
+	return nil
+}
+
+// UpdateProduct updates a product's name, description, price and category.
+// Stock is deliberately not updated here; use AdjustStock so every change is recorded.
+// product.Version must be the version the changes are based on; the update fails with a
+// conflict error when the product has been modified since, and bumps product.Version on success.
+func (s *ProductService) UpdateProduct(ctx context.Context, product *models.Product) error {
+	before, err := s.GetProductByID(ctx, product.ID) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	product.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE products
+		SET name = $1, description = $2, price = $3, category = $4, updated_at = $5, version = version + 1
+		WHERE id = $6 AND version = $7 AND tenant_id = $8 AND ` + database.NotDeleted("") + `
+	`
+	result, err := s.db.ExecContext(ctx, query, product.Name, product.Description, product.Price, product.Category, product.UpdatedAt, product.ID, product.Version, tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating product", "product_id", product.ID, "error", err)
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after update: %w", err)
+	}
+	if rowsAffected == 0 {
+		exists, err := database.Exists(ctx, s.db, "products", product.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check product existence: %w", err)
+		}
+		if exists {
+			return conflictf("product with ID %s was modified concurrently", product.ID)
+		}
+		return notFoundf("product with ID %s not found for update", product.ID)
+	}
+
+	product.Version++
+	auditChange(ctx, s.db, models.AuditActionUpdate, models.AuditEntityProduct, product.ID, before, product)
+	return nil
+}
+
+// DeleteProduct moves a product to the trash by setting its deleted_at.
+// Trashed products stay referenced by historic orders; purging one that has been ordered fails.
+func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
+	before, err := s.GetProductByID(ctx, id) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	deleted, err := database.SoftDelete(ctx, s.db, "products", id)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting product", "id", id, "error", err)
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	if !deleted {
+		return notFoundf("product with ID %s not found for deletion", id)
+	}
+
+	auditChange(ctx, s.db, models.AuditActionDelete, models.AuditEntityProduct, id, before, nil)
+	return nil
+}
+
+// ArchiveProduct hides a product from listings and new orders while keeping it for historic orders.
+// Archiving an already archived product keeps its original archived_at.
+func (s *ProductService) ArchiveProduct(ctx context.Context, id string) (*models.Product, error) {
+	return s.setArchived(ctx, id, `UPDATE products SET archived_at = COALESCE(archived_at, $2), updated_at = $2 WHERE id = $1 AND tenant_id = $3 AND `+database.NotDeleted(""))
+}
+
+// UnarchiveProduct makes an archived product available again.
+func (s *ProductService) UnarchiveProduct(ctx context.Context, id string) (*models.Product, error) {
+	return s.setArchived(ctx, id, `UPDATE products SET archived_at = NULL, updated_at = $2 WHERE id = $1 AND tenant_id = $3 AND `+database.NotDeleted(""))
+}
+
+// setArchived runs an archive state update with the product ID as $1, the current time as $2
+// and the request's tenant as $3, and returns the updated product.
+func (s *ProductService) setArchived(ctx context.Context, id, query string) (*models.Product, error) {
+	before, err := s.GetProductByID(ctx, id) // Snapshot for the audit log
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.ExecContext(ctx, query, id, time.Now(), tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error changing archive state of product", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to change archive state of product: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rows affected after archive change: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, notFoundf("product with ID %s not found for archiving", id)
+	}
+
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	auditChange(ctx, s.db, models.AuditActionUpdate, models.AuditEntityProduct, id, before, product)
+	return product, nil
+}
+
+// SetProductImage sets the storage key of a product's image, or removes the image when key
+// is nil. It returns the key of the replaced image (nil if there was none), so the caller can
+// delete the file once the change is committed.
+func (s *ProductService) SetProductImage(ctx context.Context, id string, key *string) (*string, error) {
+	var previous *string
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		err := tx.QueryRowContext(ctx, "SELECT image_key FROM products WHERE id = $1 AND tenant_id = $2 AND "+database.NotDeleted("")+tx.Dialect().ForUpdate(false), id, tenancy.ID(ctx)).Scan(&previous)
+		if err == sql.ErrNoRows {
+			return notFoundf("product with ID %s not found for image change", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read image of product: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET image_key = $1, updated_at = $2 WHERE id = $3", key, time.Now(), id); err != nil {
+			logging.FromContext(ctx).Error("Error changing image of product", "id", id, "error", err)
+			return fmt.Errorf("failed to change image of product: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// AdjustStock atomically adds delta (which may be negative) to a product's stock and records
+// the movement in stock_movements within the same transaction. Stock can never drop below zero.
+func (s *ProductService) AdjustStock(ctx context.Context, productID string, delta int, reason, actorID string) (*models.StockMovement, error) {
+	movement := &models.StockMovement{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		Delta:     delta,
+		Reason:    reason,
+		ActorID:   actorID,
+		CreatedAt: time.Now(),
+	}
+
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		// The single UPDATE both locks the row and applies the change, so concurrent
+		// adjustments are serialized and the non-negative check can't be raced.
+		result, err := tx.ExecContext(ctx, `
+			UPDATE products
+			SET stock = stock + $1, updated_at = $3
+			WHERE id = $2 AND tenant_id = $4 AND `+database.NotDeleted("")+` AND stock + $1 >= 0
+		`, delta, productID, movement.CreatedAt, tenancy.ID(ctx))
+		if err != nil {
+			logging.FromContext(ctx).Error("Error adjusting stock for product", "product_id", productID, "error", err)
+			return fmt.Errorf("failed to adjust stock: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check rows affected after stock adjustment: %w", err)
+		}
+		if rowsAffected == 0 {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND tenant_id = $2 AND `+database.NotDeleted("")+`)`, productID, tenancy.ID(ctx)).Scan(&exists); err != nil {
+				return fmt.Errorf("failed to check product existence: %w", err)
+			}
+			if !exists {
+				return notFoundf("product with ID %s not found for stock adjustment", productID)
+			}
+			return conflictf("insufficient stock for product %s", productID)
+		}
+
+		// The row stays locked by the UPDATE, so this reads the stock it left behind
+		if err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = $1`, productID).Scan(&movement.StockAfter); err != nil {
+			return fmt.Errorf("failed to read adjusted stock: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (id, product_id, delta, reason, actor_id, stock_after, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, movement.ID, movement.ProductID, movement.Delta, movement.Reason, movement.ActorID, movement.StockAfter, movement.CreatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error recording stock movement for product", "product_id", productID, "error", err)
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// GetStockMovements retrieves the stock history of a product, newest first.
+func (s *ProductService) GetStockMovements(ctx context.Context, productID string, page, limit int) ([]models.StockMovement, int, int, error) {
+	// Movements belong to the tenant of their product
+	tenantID := tenancy.ID(ctx)
+	var totalItems int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(id) FROM stock_movements WHERE product_id = $1 AND product_id IN (SELECT id FROM products WHERE tenant_id = $2)`, productID, tenantID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, product_id, delta, reason, actor_id, stock_after, created_at
+		FROM stock_movements
+		WHERE product_id = $1 AND product_id IN (SELECT id FROM products WHERE tenant_id = $4)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, productID, limit, offset, tenantID)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []models.StockMovement{}
+	for rows.Next() {
+		var m models.StockMovement
+		var actorID sql.NullString // NULL for changes made by the system
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.Delta, &m.Reason, &actorID, &m.StockAfter, &m.CreatedAt); err != nil {
+			logging.FromContext(ctx).Error("Error scanning stock movement row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		m.ActorID = actorID.String
+		movements = append(movements, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating stock movement rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return movements, totalPages, totalItems, nil
+}
+
+// scanProduct scans a product row selected with productColumns.
+func scanProduct(row rowScanner) (*models.Product, error) {
+	product := &models.Product{}
+	var archivedAt sql.NullTime
+	err := row.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Stock, &product.Category, &archivedAt, &product.ImageKey, &product.Version, &product.CreatedAt, &product.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if archivedAt.Valid {
+		product.ArchivedAt = &archivedAt.Time
+	}
+	return product, nil
+}
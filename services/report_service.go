@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/google/uuid"
+)
+
+// reportTargetTables maps the reportable target types to their tables.
+// Only these table names are ever interpolated into SQL.
+var reportTargetTables = map[string]string{
+	models.ReportTargetPost:    "posts",
+	models.ReportTargetComment: "comments",
+}
+
+// ReportServiceInterface defines the methods that any report service implementation must provide.
+type ReportServiceInterface interface {
+	GetAllReports(ctx context.Context, status, targetType string, page, limit int) ([]models.Report, int, int, error) // Returns reports, totalPages, totalItems
+	GetReportByID(ctx context.Context, id string) (*models.Report, error)
+	TargetExists(ctx context.Context, targetType, targetID string) (bool, error)
+	HasOpenReport(ctx context.Context, targetType, targetID, reporterID string) (bool, error)
+	CreateReport(ctx context.Context, report *models.Report) error
+	ResolveReport(ctx context.Context, report *models.Report) error
+}
+
+// ReportService provides methods for content reports, implementing ReportServiceInterface.
+type ReportService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewReportService creates and returns a new ReportService instance using the given connection pool.
+func NewReportService(db *database.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+const reportColumns = "id, target_type, target_id, reporter_id, reason, COALESCE(details, ''), status, resolved_by, COALESCE(resolution_note, ''), resolved_at, created_at, updated_at"
+
+// GetAllReports retrieves the reports of the request's tenant filtered by status and target type,
+// oldest first so the queue is worked in order.
+func (s *ReportService) GetAllReports(ctx context.Context, status, targetType string, page, limit int) ([]models.Report, int, int, error) {
+	reports := []models.Report{}
+	var totalItems int
+
+	countQuery := "SELECT COUNT(id) FROM reports WHERE tenant_id = $1"
+	selectQuery := "SELECT " + reportColumns + " FROM reports WHERE tenant_id = $1"
+	args := []interface{}{tenancy.ID(ctx)}
+	argCounter := 2
+
+	if status != "" {
+		countQuery += fmt.Sprintf(" AND status = $%d", argCounter)
+		selectQuery += fmt.Sprintf(" AND status = $%d", argCounter)
+		args = append(args, status)
+		argCounter++
+	}
+
+	if targetType != "" {
+		countQuery += fmt.Sprintf(" AND target_type = $%d", argCounter)
+		selectQuery += fmt.Sprintf(" AND target_type = $%d", argCounter)
+		args = append(args, targetType)
+		argCounter++
+	}
+
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	selectQuery += fmt.Sprintf(" ORDER BY created_at ASC LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query reports: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning report row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, *report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating report rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return reports, totalPages, totalItems, nil
+}
+
+// GetReportByID fetches a report by its ID.
+func (s *ReportService) GetReportByID(ctx context.Context, id string) (*models.Report, error) {
+	report, err := scanReport(s.db.QueryRowContext(ctx, "SELECT "+reportColumns+" FROM reports WHERE id = $1 AND tenant_id = $2", id, tenancy.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil // Report not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching report", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch report by ID: %w", err)
+	}
+	return report, nil
+}
+
+// TargetExists reports whether the post or comment being reported exists in the request's tenant.
+// Comments belong to the tenant of their post.
+func (s *ReportService) TargetExists(ctx context.Context, targetType, targetID string) (bool, error) {
+	table, ok := reportTargetTables[targetType]
+	if !ok {
+		return false, invalidf("unsupported report target type: %s", targetType)
+	}
+
+	query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE id = $1"
+	if database.SoftDeleteTables[table] {
+		query += " AND " + database.NotDeleted("")
+	}
+	if database.TenantTables[table] {
+		query += " AND tenant_id = $2"
+	} else {
+		query += " AND post_id IN (SELECT id FROM posts WHERE tenant_id = $2)"
+	}
+	var exists bool
+	err := s.db.QueryRowContext(ctx, query+")", targetID, tenancy.ID(ctx)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s existence: %w", targetType, err)
+	}
+	return exists, nil
+}
+
+// HasOpenReport reports whether the user already has an open report on the target.
+func (s *ReportService) HasOpenReport(ctx context.Context, targetType, targetID, reporterID string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM reports
+			WHERE target_type = $1 AND target_id = $2 AND reporter_id = $3 AND tenant_id = $4 AND status = 'open'
+		)
+	`
+	if err := s.db.QueryRowContext(ctx, query, targetType, targetID, reporterID, tenancy.ID(ctx)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check for open report: %w", err)
+	}
+	return exists, nil
+}
+
+// CreateReport inserts a new report into the database.
+func (s *ReportService) CreateReport(ctx context.Context, report *models.Report) error {
+	report.ID = uuid.New().String()
+	report.Status = models.ReportStatusOpen
+	report.CreatedAt = time.Now()
+	report.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO reports (id, tenant_id, target_type, target_id, reporter_id, reason, details, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := s.db.ExecContext(ctx,
+		query,
+		report.ID,
+		tenancy.ID(ctx),
+		report.TargetType,
+		report.TargetID,
+		report.ReporterID,
+		report.Reason,
+		report.Details,
+		report.Status,
+		report.CreatedAt,
+		report.UpdatedAt,
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating report", "target_type", report.TargetType, "target_id", report.TargetID, "error", err)
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	return nil
+}
+
+// ResolveReport closes an open report with the status, resolver and note set on the given report.
+func (s *ReportService) ResolveReport(ctx context.Context, report *models.Report) error {
+	now := time.Now()
+	report.ResolvedAt = &now
+	report.UpdatedAt = now
+
+	query := `
+		UPDATE reports
+		SET status = $1, resolved_by = $2, resolution_note = $3, resolved_at = $4, updated_at = $5
+		WHERE id = $6 AND tenant_id = $7 AND status = 'open'
+	`
+	result, err := s.db.ExecContext(ctx, query, report.Status, report.ResolvedBy, report.ResolutionNote, report.ResolvedAt, report.UpdatedAt, report.ID, tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error resolving report", "report_id", report.ID, "error", err)
+		return fmt.Errorf("failed to resolve report: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after resolve: %w", err)
+	}
+	if rowsAffected == 0 {
+		return conflictf("report with ID %s has already been closed", report.ID)
+	}
+
+	return nil
+}
+
+// scanReport scans a report row selected with reportColumns.
+func scanReport(row rowScanner) (*models.Report, error) {
+	report := &models.Report{}
+	var resolvedBy sql.NullString
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&report.ID, &report.TargetType, &report.TargetID, &report.ReporterID, &report.Reason, &report.Details,
+		&report.Status, &resolvedBy, &report.ResolutionNote, &resolvedAt, &report.CreatedAt, &report.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedBy.Valid {
+		report.ResolvedBy = &resolvedBy.String
+	}
+	if resolvedAt.Valid {
+		report.ResolvedAt = &resolvedAt.Time
+	}
+	return report, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/cache"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// RoleCache keeps the roles in the memory of the instance, for the lookups made on every user
+// list and login (the name of the user's role) and by the role dropdown, which would otherwise
+// each read the roles table. The services changing roles invalidate it (see CachedRoleService);
+// other instances see the changes once the TTL (ROLE_CACHE_TTL_SECONDS) ends. A RoleCache with
+// a zero TTL reads the roles table every time.
+type RoleCache struct {
+	db     *database.DB
+	tables *cache.Memory[string, *roleTable]
+}
+
+// roleTable is a snapshot of the roles table.
+type roleTable struct {
+	byID    map[string]models.Role // All roles, including deleted ones, which users may still have
+	options []models.LstRole       // The roles that aren't deleted, by name
+}
+
+// roleTableKey is the key of the snapshot in the cache, which holds nothing else.
+const roleTableKey = "roles"
+
+// NewRoleCache returns a cache of the roles read from db, kept for ttl.
+func NewRoleCache(db *database.DB, ttl time.Duration) *RoleCache {
+	return &RoleCache{db: db, tables: cache.NewMemory[string, *roleTable](ttl)}
+}
+
+// Options returns the roles of the role dropdown: those that aren't deleted, by name.
+func (c *RoleCache) Options(ctx context.Context) ([]models.LstRole, error) {
+	table, err := c.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.LstRole(nil), table.options...), nil
+}
+
+// Role returns the role with the given ID, even if it was deleted, or nil if there's none.
+// A role missing from the cache, e.g., created through another instance since it was loaded,
+// reloads it.
+func (c *RoleCache) Role(ctx context.Context, id string) (*models.Role, error) {
+	table, err := c.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+	role, ok := table.byID[id]
+	if !ok {
+		c.Invalidate()
+		if table, err = c.table(ctx); err != nil {
+			return nil, err
+		}
+		if role, ok = table.byID[id]; !ok {
+			return nil, nil
+		}
+	}
+	return &role, nil
+}
+
+// IDsMatching returns the IDs of the roles, deleted or not, whose name contains search,
+// ignoring case.
+func (c *RoleCache) IDsMatching(ctx context.Context, search string) ([]string, error) {
+	table, err := c.table(ctx)
+	if err != nil {
+		return nil, err
+	}
+	search = strings.ToLower(search)
+	var ids []string
+	for id, role := range table.byID {
+		if strings.Contains(strings.ToLower(role.Name), search) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Invalidate drops the cached roles, so the next lookup reads the roles table.
+func (c *RoleCache) Invalidate() {
+	c.tables.Invalidate()
+}
+
+// table returns the snapshot of the roles table, from the cache if possible.
+func (c *RoleCache) table(ctx context.Context) (*roleTable, error) {
+	return c.tables.Fetch(roleTableKey, func() (*roleTable, error) {
+		return c.load(ctx)
+	})
+}
+
+// load reads the roles table.
+func (c *RoleCache) load(ctx context.Context) (*roleTable, error) {
+	ctx = database.WithOperation(ctx, "roles.load_cache")
+	rows, err := c.db.QueryContext(ctx, "SELECT id, name, description, version, created_at, updated_at, deleted_at FROM roles ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	table := &roleTable{byID: map[string]models.Role{}, options: []models.LstRole{}}
+	for rows.Next() {
+		var role models.Role
+		var deletedAt sql.NullTime
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.Version, &role.CreatedAt, &role.UpdatedAt, &deletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		table.byID[role.ID] = role
+		if !deletedAt.Valid {
+			table.options = append(table.options, models.LstRole{ID: role.ID, Name: role.Name})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rows: %w", err)
+	}
+	return table, nil
+}
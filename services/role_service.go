@@ -1,62 +1,68 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"slices"
 	"time"
 
 	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
 	"github.com/anpsniper/anpbayu-be/models"
 	"github.com/google/uuid"
 )
 
 // RoleServiceInterface defines the methods that any role service implementation must provide.
 type RoleServiceInterface interface {
-	GetAllRoles(search string, page, limit int) ([]models.Role, int, int, error) // Returns roles, totalPages, totalItems
-	GetRoleByID(id string) (*models.Role, error)
-	GetRoleByName(name string) (*models.Role, error) // Added for convenience
-	CreateRole(role *models.Role) error
-	UpdateRole(role *models.Role) error
-	DeleteRole(id string) error
+	GetAllRoles(ctx context.Context, search string, page, limit int) ([]models.Role, int, int, error) // Returns roles, totalPages, totalItems
+	GetRoleByID(ctx context.Context, id string) (*models.Role, error)
+	GetRoleByName(ctx context.Context, name string) (*models.Role, error) // Added for convenience
+	CreateRole(ctx context.Context, role *models.Role) error
+	UpdateRole(ctx context.Context, role *models.Role) error
+	DeleteRole(ctx context.Context, id string) error
+	GetPermissionMatrix(ctx context.Context) (*models.PermissionMatrix, error)
+	UpdatePermissions(ctx context.Context, changes []models.PermissionChange) error
+	HasPermission(ctx context.Context, roleNames []string, permission string) (bool, error)
+	GetPermissionsOfRoles(ctx context.Context, roleNames []string) ([]string, error)
+	ExportRoles(ctx context.Context) ([]models.RoleDefinition, error)
+	ImportRoles(ctx context.Context, roles []models.RoleDefinition, onConflict string) ([]models.RoleImportResult, error)
 }
 
 // RoleService provides methods for role-related business logic, implementing RoleServiceInterface.
 type RoleService struct {
-	// No fields needed here if we're using a global DB connection from the database package.
+	db *database.DB // Database connection pool
 }
 
-// NewRoleService creates and returns a new RoleService instance.
-func NewRoleService() *RoleService {
-	return &RoleService{}
+// NewRoleService creates and returns a new RoleService instance using the given connection pool.
+func NewRoleService(db *database.DB) *RoleService {
+	return &RoleService{db: db}
 }
 
 // GetAllRoles fetches all roles from the database with search and pagination.
-func (s *RoleService) GetAllRoles(search string, page, limit int) ([]models.Role, int, int, error) {
-	if database.DB == nil {
-		return nil, 0, 0, fmt.Errorf("database connection is not initialized")
-	}
-
+func (s *RoleService) GetAllRoles(ctx context.Context, search string, page, limit int) ([]models.Role, int, int, error) {
+	ctx = database.WithOperation(ctx, "roles.list")
 	var roles []models.Role
 	var totalItems int
 
 	// Build the base query
-	countQuery := "SELECT COUNT(id) FROM roles WHERE 1=1"
-	selectQuery := "SELECT id, name, description, created_at, updated_at FROM roles WHERE 1=1"
+	countQuery := "SELECT COUNT(id) FROM roles WHERE " + database.NotDeleted("")
+	selectQuery := "SELECT id, name, description, version, created_at, updated_at FROM roles WHERE " + database.NotDeleted("")
 	args := []interface{}{}
 	argCounter := 1
 
 	// Add search condition if provided
 	if search != "" {
 		searchPattern := "%" + search + "%"
-		countQuery += fmt.Sprintf(" AND (name ILIKE $%d OR description ILIKE $%d)", argCounter, argCounter+1)
-		selectQuery += fmt.Sprintf(" AND (name ILIKE $%d OR description ILIKE $%d)", argCounter, argCounter+1)
+		ilike := s.db.Dialect().ILike
+		countQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("description", argCounter+1))
+		selectQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("description", argCounter+1))
 		args = append(args, searchPattern, searchPattern)
 		argCounter += 2
 	}
 
 	// Get total items
-	err := database.DB.QueryRow(countQuery, args...).Scan(&totalItems)
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("failed to count roles: %w", err)
 	}
@@ -66,7 +72,7 @@ func (s *RoleService) GetAllRoles(search string, page, limit int) ([]models.Role
 	selectQuery += fmt.Sprintf(" ORDER BY name ASC LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
 	args = append(args, limit, offset)
 
-	rows, err := database.DB.Query(selectQuery, args...)
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("failed to query roles: %w", err)
 	}
@@ -74,9 +80,9 @@ func (s *RoleService) GetAllRoles(search string, page, limit int) ([]models.Role
 
 	for rows.Next() {
 		var role models.Role
-		err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UpdatedAt)
+		err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.Version, &role.CreatedAt, &role.UpdatedAt)
 		if err != nil {
-			log.Printf("Error scanning role row: %v", err)
+			logging.FromContext(ctx).Error("Error scanning role row", "error", err)
 			return nil, 0, 0, fmt.Errorf("failed to scan role: %w", err)
 		}
 		roles = append(roles, role)
@@ -95,61 +101,56 @@ func (s *RoleService) GetAllRoles(search string, page, limit int) ([]models.Role
 }
 
 // GetRoleByID fetches a role by its ID.
-func (s *RoleService) GetRoleByID(id string) (*models.Role, error) {
-	if database.DB == nil {
-		return nil, fmt.Errorf("database connection is not initialized")
-	}
-
+func (s *RoleService) GetRoleByID(ctx context.Context, id string) (*models.Role, error) {
+	ctx = database.WithOperation(ctx, "roles.get")
 	role := &models.Role{}
-	query := "SELECT id, name, description, created_at, updated_at FROM roles WHERE id = $1"
-	err := database.DB.QueryRow(query, id).Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UpdatedAt)
+	query := "SELECT id, name, description, version, created_at, updated_at FROM roles WHERE id = $1 AND " + database.NotDeleted("")
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name, &role.Description, &role.Version, &role.CreatedAt, &role.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // Role not found
 	}
 	if err != nil {
-		log.Printf("Error fetching role by ID %s: %v", id, err)
+		logging.FromContext(ctx).Error("Error fetching role", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to fetch role by ID: %w", err)
 	}
 	return role, nil
 }
 
 // GetRoleByName fetches a role by its name.
-func (s *RoleService) GetRoleByName(name string) (*models.Role, error) {
-	if database.DB == nil {
-		return nil, fmt.Errorf("database connection is not initialized")
-	}
-
+func (s *RoleService) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	ctx = database.WithOperation(ctx, "roles.get_by_name")
 	role := &models.Role{}
-	query := "SELECT id, name, description, created_at, updated_at FROM roles WHERE name = $1"
-	err := database.DB.QueryRow(query, name).Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UpdatedAt)
+	query := "SELECT id, name, description, version, created_at, updated_at FROM roles WHERE name = $1 AND " + database.NotDeleted("")
+	err := s.db.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description, &role.Version, &role.CreatedAt, &role.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // Role not found
 	}
 	if err != nil {
-		log.Printf("Error fetching role by name %s: %v", name, err)
+		logging.FromContext(ctx).Error("Error fetching role by name", "name", name, "error", err)
 		return nil, fmt.Errorf("failed to fetch role by name: %w", err)
 	}
 	return role, nil
 }
 
 // CreateRole inserts a new role into the database.
-func (s *RoleService) CreateRole(role *models.Role) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
-	}
-
+func (s *RoleService) CreateRole(ctx context.Context, role *models.Role) error {
+	ctx = database.WithOperation(ctx, "roles.create")
 	// Generate a new UUID for the role
 	role.ID = uuid.New().String()
 	role.CreatedAt = time.Now()
 	role.UpdatedAt = time.Now()
+	role.Version = 1
+	if err := s.checkNotTrashed(ctx, role.Name); err != nil {
+		return err
+	}
 
 	query := `
 		INSERT INTO roles (id, name, description, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5)
 	`
-	_, err := database.DB.Exec(
+	_, err := s.db.ExecContext(ctx,
 		query,
 		role.ID,
 		role.Name,
@@ -158,34 +159,55 @@ func (s *RoleService) CreateRole(role *models.Role) error {
 		role.UpdatedAt,
 	)
 	if err != nil {
-		log.Printf("Error creating role %s: %v", role.Name, err)
+		logging.FromContext(ctx).Error("Error creating role", "role_name", role.Name, "error", err)
 		return fmt.Errorf("failed to create role: %w", err)
 	}
+	auditChange(ctx, s.db, models.AuditActionCreate, models.AuditEntityRole, role.ID, nil, role)
 	return nil
 }
 
-// UpdateRole updates an existing role's information in the database.
-func (s *RoleService) UpdateRole(role *models.Role) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
+// checkNotTrashed fails with a conflict error when the name is held by a role in the trash: it
+// stays reserved until the role is purged, while GetRoleByName no longer finds the role.
+func (s *RoleService) checkNotTrashed(ctx context.Context, name string) error {
+	trashed, err := database.Trashed(ctx, s.db, "roles", "name", name)
+	if err != nil {
+		return fmt.Errorf("failed to check trashed roles: %w", err)
+	}
+	if trashed {
+		return conflictf("role %s is in the trash; restore or purge it first", name)
 	}
+	return nil
+}
 
+// UpdateRole updates an existing role's information in the database.
+// role.Version must be the version the changes are based on; the update fails with a
+// conflict error when the role has been modified since, and bumps role.Version on success.
+func (s *RoleService) UpdateRole(ctx context.Context, role *models.Role) error {
+	ctx = database.WithOperation(ctx, "roles.update")
+	before, err := s.GetRoleByID(ctx, role.ID) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	if err := s.checkNotTrashed(ctx, role.Name); err != nil {
+		return err
+	}
 	role.UpdatedAt = time.Now() // Update the timestamp
 
 	query := `
 		UPDATE roles
-		SET name = $1, description = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, description = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5 AND ` + database.NotDeleted("") + `
 	`
-	result, err := database.DB.Exec(
+	result, err := s.db.ExecContext(ctx,
 		query,
 		role.Name,
 		role.Description,
 		role.UpdatedAt,
 		role.ID,
+		role.Version,
 	)
 	if err != nil {
-		log.Printf("Error updating role %s: %v", role.ID, err)
+		logging.FromContext(ctx).Error("Error updating role", "role_id", role.ID, "error", err)
 		return fmt.Errorf("failed to update role: %w", err)
 	}
 
@@ -194,32 +216,476 @@ func (s *RoleService) UpdateRole(role *models.Role) error {
 		return fmt.Errorf("failed to check rows affected after update: %w", err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("role with ID %s not found for update", role.ID)
+		exists, err := database.Exists(ctx, s.db, "roles", role.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check role existence: %w", err)
+		}
+		if exists {
+			return conflictf("role with ID %s was modified concurrently", role.ID)
+		}
+		return notFoundf("role with ID %s not found for update", role.ID)
 	}
 
+	role.Version++
+
+	// Tokens carry role names, so those of the role's users are outdated by a rename (see middleware.JWT)
+	if before != nil && before.Name != role.Name {
+		if _, err := s.db.ExecContext(ctx, "UPDATE users SET token_version = token_version + 1 WHERE role_id = $1", role.ID); err != nil {
+			logging.FromContext(ctx).Error("Error outdating tokens of renamed role", "role_id", role.ID, "error", err)
+			return fmt.Errorf("failed to outdate tokens of role %s: %w", role.ID, err)
+		}
+	}
+	auditChange(ctx, s.db, models.AuditActionUpdate, models.AuditEntityRole, role.ID, before, role)
 	return nil
 }
 
-// DeleteRole deletes a role from the database by its ID.
-func (s *RoleService) DeleteRole(id string) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
+// DeleteRole moves a role to the trash by setting its deleted_at.
+// Trashed roles can be restored or purged through the trash endpoints. Roles still assigned to
+// users can't be deleted, as the users would be left with a role that no longer applies.
+func (s *RoleService) DeleteRole(ctx context.Context, id string) error {
+	ctx = database.WithOperation(ctx, "roles.delete")
+	before, err := s.GetRoleByID(ctx, id) // Snapshot for the audit log
+	if err != nil {
+		return err
 	}
-
-	query := `DELETE FROM roles WHERE id = $1`
-	result, err := database.DB.Exec(query, id)
+	var assigned bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE role_id = $1 AND "+database.NotDeleted("")+")", id).Scan(&assigned); err != nil {
+		logging.FromContext(ctx).Error("Error checking users of role", "id", id, "error", err)
+		return fmt.Errorf("failed to check users of role: %w", err)
+	}
+	if assigned {
+		return conflictf("role with ID %s is still assigned to users", id)
+	}
+	deleted, err := database.SoftDelete(ctx, s.db, "roles", id)
 	if err != nil {
-		log.Printf("Error deleting role by ID %s: %v", id, err)
+		logging.FromContext(ctx).Error("Error deleting role", "id", id, "error", err)
 		return fmt.Errorf("failed to delete role: %w", err)
 	}
+	if !deleted {
+		return notFoundf("role with ID %s not found for deletion", id)
+	}
 
-	rowsAffected, err := result.RowsAffected()
+	auditChange(ctx, s.db, models.AuditActionDelete, models.AuditEntityRole, id, before, nil)
+	return nil
+}
+
+// GetPermissionMatrix returns the permissions granted to every role, by role name.
+func (s *RoleService) GetPermissionMatrix(ctx context.Context) (*models.PermissionMatrix, error) {
+	ctx = database.WithOperation(ctx, "roles.get_permission_matrix")
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name FROM roles WHERE "+database.NotDeleted("")+" ORDER BY name")
 	if err != nil {
-		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+		return nil, fmt.Errorf("failed to query roles: %w", err)
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("role with ID %s not found for deletion", id)
+	defer rows.Close()
+
+	matrix := &models.PermissionMatrix{Permissions: models.Permissions, Roles: []models.RolePermissions{}}
+	for rows.Next() {
+		var row models.RolePermissions
+		if err := rows.Scan(&row.RoleID, &row.RoleName); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		row.Locked = row.RoleName == models.AdminRole
+		row.Grants = make(map[string]bool, len(models.Permissions))
+		for _, permission := range models.Permissions {
+			row.Grants[permission.Name] = row.Locked
+		}
+		matrix.Roles = append(matrix.Roles, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rows: %w", err)
+	}
+	byID := make(map[string]*models.RolePermissions, len(matrix.Roles))
+	for i := range matrix.Roles {
+		byID[matrix.Roles[i].RoleID] = &matrix.Roles[i]
+	}
+
+	grants, err := s.db.QueryContext(ctx, "SELECT role_id, permission FROM role_permissions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role permissions: %w", err)
+	}
+	defer grants.Close()
+	for grants.Next() {
+		var roleID, permission string
+		if err := grants.Scan(&roleID, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		// Grants of trashed roles and of permissions no longer in the catalog are left out
+		if row, ok := byID[roleID]; ok && models.IsPermission(permission) {
+			row.Grants[permission] = true
+		}
+	}
+	if err = grants.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role permission rows: %w", err)
+	}
+	return matrix, nil
+}
+
+// UpdatePermissions grants and revokes permissions of roles, all or none of them. The
+// permissions of the admin role can't be changed. Every role whose permissions changed is
+// recorded in the audit log with its permissions before and after.
+func (s *RoleService) UpdatePermissions(ctx context.Context, changes []models.PermissionChange) error {
+	ctx = database.WithOperation(ctx, "roles.update_permissions")
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		roles := map[string]*rolePermissionsSnapshot{}
+		for _, change := range changes {
+			if !models.IsPermission(change.Permission) {
+				return invalidf("unknown permission %q", change.Permission)
+			}
+			if _, ok := roles[change.RoleID]; ok {
+				continue
+			}
+			snapshot, err := loadRolePermissions(ctx, tx, change.RoleID)
+			if err != nil {
+				return err
+			}
+			if snapshot == nil {
+				return notFoundf("role with ID %s not found", change.RoleID)
+			}
+			if snapshot.Name == models.AdminRole {
+				return invalidf("the permissions of the %s role can't be changed", models.AdminRole)
+			}
+			roles[change.RoleID] = snapshot
+		}
+
+		now := time.Now()
+		grant := tx.Dialect().InsertIgnore("INSERT INTO role_permissions (role_id, permission, created_at) VALUES ($1, $2, $3)")
+		for _, change := range changes {
+			var err error
+			if change.Granted {
+				_, err = tx.ExecContext(ctx, grant, change.RoleID, change.Permission, now)
+			} else {
+				_, err = tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role_id = $1 AND permission = $2", change.RoleID, change.Permission)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update permission %s of role %s: %w", change.Permission, change.RoleID, err)
+			}
+		}
+
+		for roleID, before := range roles {
+			after, err := loadRolePermissions(ctx, tx, roleID)
+			if err != nil {
+				return err
+			}
+			if slices.Equal(before.Permissions, after.Permissions) {
+				continue
+			}
+			if err := recordAudit(ctx, tx, models.AuditActionUpdate, models.AuditEntityRole, roleID, before, after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HasPermission reports whether one of the roles named roleNames, such as those of a token,
+// has permission. The admin role has every permission.
+func (s *RoleService) HasPermission(ctx context.Context, roleNames []string, permission string) (bool, error) {
+	ctx = database.WithOperation(ctx, "roles.has_permission")
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+	if slices.Contains(roleNames, models.AdminRole) {
+		return true, nil
+	}
+	args := []interface{}{permission}
+	for _, name := range roleNames {
+		args = append(args, name)
+	}
+	var granted int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM role_permissions rp JOIN roles r ON r.id = rp.role_id
+		WHERE rp.permission = $1 AND `+database.NotDeleted("r")+` AND r.name IN (`+database.Placeholders(2, len(roleNames))+`)`,
+		args...,
+	).Scan(&granted)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission %s: %w", permission, err)
 	}
+	return granted > 0, nil
+}
+
+// GetPermissionsOfRoles returns the permissions the roles named roleNames have together, in
+// the order of models.Permissions. The admin role has every permission.
+func (s *RoleService) GetPermissionsOfRoles(ctx context.Context, roleNames []string) ([]string, error) {
+	ctx = database.WithOperation(ctx, "roles.permissions_of_roles")
+	permissions := []string{}
+	if len(roleNames) == 0 {
+		return permissions, nil
+	}
+	granted := map[string]bool{}
+	if slices.Contains(roleNames, models.AdminRole) {
+		for _, permission := range models.Permissions {
+			granted[permission.Name] = true
+		}
+	} else {
+		args := make([]interface{}, len(roleNames))
+		for i, name := range roleNames {
+			args[i] = name
+		}
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT DISTINCT rp.permission FROM role_permissions rp JOIN roles r ON r.id = rp.role_id
+			WHERE `+database.NotDeleted("r")+` AND r.name IN (`+database.Placeholders(1, len(roleNames))+`)`,
+			args...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch permissions of roles: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var permission string
+			if err := rows.Scan(&permission); err != nil {
+				return nil, fmt.Errorf("failed to scan permission: %w", err)
+			}
+			granted[permission] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch permissions of roles: %w", err)
+		}
+	}
+	for _, permission := range models.Permissions {
+		if granted[permission.Name] {
+			permissions = append(permissions, permission.Name)
+		}
+	}
+	return permissions, nil
+}
 
+// rolePermissionsSnapshot is a role with its permissions, as recorded in the audit log by
+// UpdatePermissions and ImportRoles.
+type rolePermissionsSnapshot struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"` // Sorted
+}
+
+// loadRolePermissions returns the role with the given ID and its permissions, or nil if it
+// doesn't exist or is trashed.
+func loadRolePermissions(ctx context.Context, tx *database.Tx, roleID string) (*rolePermissionsSnapshot, error) {
+	snapshot := &rolePermissionsSnapshot{ID: roleID, Permissions: []string{}}
+	var description sql.NullString
+	err := tx.QueryRowContext(ctx, "SELECT name, description FROM roles WHERE id = $1 AND "+database.NotDeleted(""), roleID).Scan(&snapshot.Name, &description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch role %s: %w", roleID, err)
+	}
+	snapshot.Description = description.String
+
+	rows, err := tx.QueryContext(ctx, "SELECT permission FROM role_permissions WHERE role_id = $1 ORDER BY permission", roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query permissions of role %s: %w", roleID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		snapshot.Permissions = append(snapshot.Permissions, permission)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating permission rows: %w", err)
+	}
+	return snapshot, nil
+}
+
+// ExportRoles returns every role with its permissions, by name, for ImportRoles to recreate
+// them in another environment. The admin role is exported with every permission.
+func (s *RoleService) ExportRoles(ctx context.Context) ([]models.RoleDefinition, error) {
+	ctx = database.WithOperation(ctx, "roles.export")
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, description FROM roles WHERE "+database.NotDeleted("")+" ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []models.RoleDefinition{}
+	indexes := map[string]int{}
+	for rows.Next() {
+		var id string
+		var description sql.NullString
+		role := models.RoleDefinition{Permissions: []string{}}
+		if err := rows.Scan(&id, &role.Name, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		role.Description = description.String
+		if role.Name == models.AdminRole {
+			for _, permission := range models.Permissions {
+				role.Permissions = append(role.Permissions, permission.Name)
+			}
+		}
+		indexes[id] = len(roles)
+		roles = append(roles, role)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rows: %w", err)
+	}
+
+	grants, err := s.db.QueryContext(ctx, "SELECT role_id, permission FROM role_permissions ORDER BY permission")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role permissions: %w", err)
+	}
+	defer grants.Close()
+	for grants.Next() {
+		var roleID, permission string
+		if err := grants.Scan(&roleID, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		if i, ok := indexes[roleID]; ok && roles[i].Name != models.AdminRole && models.IsPermission(permission) {
+			roles[i].Permissions = append(roles[i].Permissions, permission)
+		}
+	}
+	if err = grants.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role permission rows: %w", err)
+	}
+	return roles, nil
+}
+
+// ImportRoles creates the roles of an export (see ExportRoles), all or none of them. Roles
+// named like an existing one are skipped, overwritten or created under another name depending
+// on onConflict (one of the models.RoleConflict* constants). Trashed roles count as existing:
+// they are skipped, renamed around, or must be restored or purged before they are overwritten.
+// The permissions of the admin role are left out, as it has every permission.
+func (s *RoleService) ImportRoles(ctx context.Context, roles []models.RoleDefinition, onConflict string) ([]models.RoleImportResult, error) {
+	ctx = database.WithOperation(ctx, "roles.import")
+	switch onConflict {
+	case models.RoleConflictSkip, models.RoleConflictOverwrite, models.RoleConflictRename:
+	default:
+		return nil, invalidf("unknown conflict resolution %q", onConflict)
+	}
+	names := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if names[role.Name] {
+			return nil, invalidf("role %s is imported twice", role.Name)
+		}
+		names[role.Name] = true
+		for _, permission := range role.Permissions {
+			if !models.IsPermission(permission) {
+				return nil, invalidf("unknown permission %q of role %s", permission, role.Name)
+			}
+		}
+	}
+
+	results := make([]models.RoleImportResult, 0, len(roles))
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		for _, role := range roles {
+			result := models.RoleImportResult{Name: role.Name, SavedAs: role.Name}
+			var deletedAt sql.NullTime
+			err := tx.QueryRowContext(ctx, "SELECT id, deleted_at FROM roles WHERE name = $1", role.Name).Scan(&result.RoleID, &deletedAt)
+			switch {
+			case err == sql.ErrNoRows:
+				result.Action = models.RoleImportCreated
+				result.RoleID, err = importRole(ctx, tx, role.Name, role)
+			case err != nil:
+				return fmt.Errorf("failed to fetch role %s: %w", role.Name, err)
+			case onConflict == models.RoleConflictSkip:
+				result.Action = models.RoleImportSkipped
+			case onConflict == models.RoleConflictOverwrite:
+				if deletedAt.Valid {
+					return conflictf("role %s is in the trash; restore or purge it before overwriting it", role.Name)
+				}
+				result.Action = models.RoleImportOverwritten
+				err = overwriteRole(ctx, tx, result.RoleID, role)
+			default:
+				result.Action = models.RoleImportRenamed
+				if result.SavedAs, err = freeRoleName(ctx, tx, role.Name); err == nil {
+					result.RoleID, err = importRole(ctx, tx, result.SavedAs, role)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// importRole creates the role name with the description and permissions of role, and returns its ID.
+func importRole(ctx context.Context, tx *database.Tx, name string, role models.RoleDefinition) (string, error) {
+	id := uuid.New().String()
+	now := time.Now()
+	_, err := tx.ExecContext(ctx, "INSERT INTO roles (id, name, description, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)",
+		id, name, role.Description, now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to create role %s: %w", name, err)
+	}
+	if err := setRolePermissions(ctx, tx, id, name, role.Permissions); err != nil {
+		return "", err
+	}
+	after, err := loadRolePermissions(ctx, tx, id)
+	if err != nil {
+		return "", err
+	}
+	return id, recordAudit(ctx, tx, models.AuditActionCreate, models.AuditEntityRole, id, nil, after)
+}
+
+// overwriteRole gives the existing role with the given ID the description and permissions of role.
+func overwriteRole(ctx context.Context, tx *database.Tx, id string, role models.RoleDefinition) error {
+	before, err := loadRolePermissions(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE roles SET description = $1, updated_at = $2, version = version + 1 WHERE id = $3",
+		role.Description, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update role %s: %w", role.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role_id = $1", id); err != nil {
+		return fmt.Errorf("failed to clear permissions of role %s: %w", role.Name, err)
+	}
+	if err := setRolePermissions(ctx, tx, id, role.Name, role.Permissions); err != nil {
+		return err
+	}
+	after, err := loadRolePermissions(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, models.AuditActionUpdate, models.AuditEntityRole, id, before, after)
+}
+
+// setRolePermissions grants permissions to the role with the given ID and name, unless it is
+// the admin role, which has every permission.
+func setRolePermissions(ctx context.Context, tx *database.Tx, id, name string, permissions []string) error {
+	if name == models.AdminRole {
+		return nil
+	}
+	now := time.Now()
+	for _, permission := range permissions {
+		_, err := tx.ExecContext(ctx, "INSERT INTO role_permissions (role_id, permission, created_at) VALUES ($1, $2, $3)", id, permission, now)
+		if err != nil {
+			return fmt.Errorf("failed to grant permission %s to role %s: %w", permission, name, err)
+		}
+	}
 	return nil
 }
+
+// maxRoleRenames bounds the names freeRoleName tries.
+const maxRoleRenames = 100
+
+// freeRoleName returns the first name of the form "<name>-2", "<name>-3" and so on that no
+// role, trashed or not, has. name is shortened to fit the 50 characters of role names.
+func freeRoleName(ctx context.Context, tx *database.Tx, name string) (string, error) {
+	for i := 2; i < maxRoleRenames+2; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		candidate := name
+		if runes := []rune(name); len(runes)+len(suffix) > 50 {
+			candidate = string(runes[:50-len(suffix)])
+		}
+		candidate += suffix
+
+		var taken int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM roles WHERE name = $1", candidate).Scan(&taken); err != nil {
+			return "", fmt.Errorf("failed to check role name %s: %w", candidate, err)
+		}
+		if taken == 0 {
+			return candidate, nil
+		}
+	}
+	return "", conflictf("no free name found for role %s", name)
+}
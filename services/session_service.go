@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// SessionServiceInterface defines the methods that any session service implementation must provide.
+type SessionServiceInterface interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, token string) (*models.Session, error) // Nil if the session doesn't exist or expired
+	DeleteSession(ctx context.Context, token string) error
+}
+
+// SessionService keeps the sessions of logged in users, implementing SessionServiceInterface.
+// Sessions are looked up by their token, of which only the SHA-256 hash is stored, so the
+// tokens can't be taken from the database.
+type SessionService struct {
+	db     *database.DB   // Database connection pool
+	insert *database.Stmt // insertSessionQuery, prepared
+}
+
+// NewSessionService creates and returns a new SessionService instance using the given connection pool.
+func NewSessionService(db *database.DB) *SessionService {
+	return &SessionService{db: db, insert: db.Prepared(context.Background(), insertSessionQuery)}
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of token, as stored in sessions.token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession stores the session of session.Token, setting its ID.
+func (s *SessionService) CreateSession(ctx context.Context, session *models.Session) error {
+	if err := insertSession(ctx, s.insert, session); err != nil {
+		logging.FromContext(ctx).Error("Error creating session", "user_id", session.UserID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// insertSessionQuery stores a session, see insertSession.
+const insertSessionQuery = "INSERT INTO sessions (id, user_id, token, device, ip_address, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+
+// insertSession stores the session of session.Token with insert, a prepared insertSessionQuery,
+// setting its ID.
+func insertSession(ctx context.Context, insert *database.Stmt, session *models.Session) error {
+	session.ID = uuid.New().String()
+	_, err := insert.ExecContext(ctx, session.ID, session.UserID, hashToken(session.Token), session.Device, session.IPAddress, session.ExpiresAt, session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetSession fetches the session of token, without the token itself, along with the current
+// token version of its user. It returns nil if the session doesn't exist, e.g., because the
+// user logged out, or has expired, and if the user has been deleted.
+func (s *SessionService) GetSession(ctx context.Context, token string) (*models.Session, error) {
+	session := &models.Session{}
+	query := `
+		SELECT s.id, s.user_id, COALESCE(s.device, ''), COALESCE(s.ip_address, ''), s.expires_at, s.created_at, u.token_version
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token = $1 AND s.expires_at > $2 AND ` + database.NotDeleted("u") + `
+	`
+	err := s.db.QueryRowContext(ctx, query, hashToken(token), time.Now()).Scan(
+		&session.ID, &session.UserID, &session.Device, &session.IPAddress, &session.ExpiresAt, &session.CreatedAt, &session.UserTokenVersion,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil // Logged out, expired or user deleted
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching session", "error", err)
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	return session, nil
+}
+
+// DeleteSession deletes the session of token, logging it out. Deleting a session that doesn't
+// exist is not an error.
+func (s *SessionService) DeleteSession(ctx context.Context, token string) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE token = $1", hashToken(token)); err != nil {
+		logging.FromContext(ctx).Error("Error deleting session", "error", err)
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// SettingServiceInterface defines the methods that any setting service must provide.
+type SettingServiceInterface interface {
+	GetMaintenanceMode(ctx context.Context) (*models.MaintenanceMode, error) // Returns a disabled mode if it was never set
+	SetMaintenanceMode(ctx context.Context, mode *models.MaintenanceMode) error
+}
+
+// SettingService handles the settings that can be changed through the API, such as maintenance
+// mode. Unlike the runtime settings of config, they are stored in the database, so a change
+// reaches every instance and survives restarts. They apply to all tenants.
+type SettingService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewSettingService creates and returns a new SettingService instance using the given connection pool.
+func NewSettingService(db *database.DB) *SettingService {
+	return &SettingService{db: db}
+}
+
+// GetMaintenanceMode retrieves the state of maintenance mode.
+func (s *SettingService) GetMaintenanceMode(ctx context.Context) (*models.MaintenanceMode, error) {
+	mode := &models.MaintenanceMode{}
+	if err := s.get(ctx, models.SettingMaintenanceMode, mode); err != nil {
+		return nil, err
+	}
+	return mode, nil
+}
+
+// SetMaintenanceMode enables or disables maintenance mode, setting mode.UpdatedAt.
+func (s *SettingService) SetMaintenanceMode(ctx context.Context, mode *models.MaintenanceMode) error {
+	before, err := s.GetMaintenanceMode(ctx) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	mode.UpdatedAt = time.Now()
+	if err := s.set(ctx, models.SettingMaintenanceMode, mode, mode.UpdatedAt); err != nil {
+		return err
+	}
+	auditChange(ctx, s.db, models.AuditActionUpdate, models.AuditEntitySetting, models.SettingMaintenanceMode, before, mode)
+	return nil
+}
+
+// get unmarshals the value of the setting name into v, leaving v as it is if it isn't set.
+func (s *SettingService) get(ctx context.Context, name string, v interface{}) error {
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM settings WHERE name = $1", name).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get setting %s: %w", name, err)
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return fmt.Errorf("failed to decode setting %s: %w", name, err)
+	}
+	return nil
+}
+
+// set stores v as the value of the setting name, creating the setting if needed.
+func (s *SettingService) set(ctx context.Context, name string, v interface{}, updatedAt time.Time) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode setting %s: %w", name, err)
+	}
+
+	insert := s.db.Dialect().InsertIgnore("INSERT INTO settings (name, value, updated_at) VALUES ($1, $2, $3)")
+	result, err := s.db.ExecContext(ctx, insert, name, string(value), updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set setting %s: %w", name, err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to set setting %s: %w", name, err)
+	} else if inserted == 1 {
+		return nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, "UPDATE settings SET value = $1, updated_at = $2 WHERE name = $3", string(value), updatedAt, name); err != nil {
+		return fmt.Errorf("failed to set setting %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/google/uuid"
+)
+
+// TagServiceInterface defines the methods that any tag service implementation must provide.
+type TagServiceInterface interface {
+	GetAllTags(ctx context.Context, search string, page, limit int) ([]models.Tag, int, int, error) // Returns tags, totalPages, totalItems
+	GetTagByID(ctx context.Context, id string) (*models.Tag, error)
+	GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error)
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	UpdateTag(ctx context.Context, tag *models.Tag) error
+	DeleteTag(ctx context.Context, id string) error
+	GetTagCloud(ctx context.Context, limit int) ([]models.TagCloudItem, error)
+}
+
+// TagService provides methods for tag-related business logic, implementing TagServiceInterface.
+type TagService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewTagService creates and returns a new TagService instance using the given connection pool.
+func NewTagService(db *database.DB) *TagService {
+	return &TagService{db: db}
+}
+
+// GetAllTags fetches all tags from the database with search and pagination.
+func (s *TagService) GetAllTags(ctx context.Context, search string, page, limit int) ([]models.Tag, int, int, error) {
+	var tags []models.Tag
+	var totalItems int
+
+	countQuery := "SELECT COUNT(id) FROM tags WHERE 1=1"
+	selectQuery := "SELECT id, name, slug, created_at, updated_at FROM tags WHERE 1=1"
+	args := []interface{}{}
+	argCounter := 1
+
+	if search != "" {
+		searchPattern := "%" + search + "%"
+		ilike := s.db.Dialect().ILike
+		countQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("slug", argCounter+1))
+		selectQuery += fmt.Sprintf(" AND (%s OR %s)", ilike("name", argCounter), ilike("slug", argCounter+1))
+		args = append(args, searchPattern, searchPattern)
+		argCounter += 2
+	}
+
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count tags: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	selectQuery += fmt.Sprintf(" ORDER BY name ASC LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag models.Tag
+		err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning tag row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating tag rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return tags, totalPages, totalItems, nil
+}
+
+// GetTagByID fetches a tag by its ID.
+func (s *TagService) GetTagByID(ctx context.Context, id string) (*models.Tag, error) {
+	tag := &models.Tag{}
+	query := "SELECT id, name, slug, created_at, updated_at FROM tags WHERE id = $1"
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Tag not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching tag", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch tag by ID: %w", err)
+	}
+	return tag, nil
+}
+
+// GetTagBySlug fetches a tag by its slug.
+func (s *TagService) GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	tag := &models.Tag{}
+	query := "SELECT id, name, slug, created_at, updated_at FROM tags WHERE slug = $1"
+	err := s.db.QueryRowContext(ctx, query, slug).Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Tag not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching tag by slug", "slug", slug, "error", err)
+		return nil, fmt.Errorf("failed to fetch tag by slug: %w", err)
+	}
+	return tag, nil
+}
+
+// CreateTag inserts a new tag into the database.
+func (s *TagService) CreateTag(ctx context.Context, tag *models.Tag) error {
+	tag.ID = uuid.New().String()
+	tag.CreatedAt = time.Now()
+	tag.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO tags (id, name, slug, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := s.db.ExecContext(ctx, query, tag.ID, tag.Name, tag.Slug, tag.CreatedAt, tag.UpdatedAt)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating tag", "tag_name", tag.Name, "error", err)
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// UpdateTag updates an existing tag's name and slug in the database.
+func (s *TagService) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	tag.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE tags
+		SET name = $1, slug = $2, updated_at = $3
+		WHERE id = $4
+	`
+	result, err := s.db.ExecContext(ctx, query, tag.Name, tag.Slug, tag.UpdatedAt, tag.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating tag", "tag_id", tag.ID, "error", err)
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("tag with ID %s not found for update", tag.ID)
+	}
+
+	return nil
+}
+
+// DeleteTag deletes a tag by its ID. Its post_tags links are removed by ON DELETE CASCADE.
+func (s *TagService) DeleteTag(ctx context.Context, id string) error {
+	query := `DELETE FROM tags WHERE id = $1`
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting tag", "id", id, "error", err)
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("tag with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
+
+// GetTagCloud returns the most used tags together with the number of posts each one is attached to.
+// Tags are shared by all tenants, but only the live posts of the request's tenant are counted;
+// tags that are not attached to any of them are omitted.
+func (s *TagService) GetTagCloud(ctx context.Context, limit int) ([]models.TagCloudItem, error) {
+	query := `
+		SELECT t.id, t.name, t.slug, COUNT(pt.post_id) AS post_count
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON pt.post_id = p.id AND p.tenant_id = $2 AND ` + database.NotDeleted("p") + `
+		GROUP BY t.id, t.name, t.slug
+		ORDER BY post_count DESC, t.name ASC
+		LIMIT $1
+	`
+	rows, err := s.db.QueryContext(ctx, query, limit, tenancy.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag cloud: %w", err)
+	}
+	defer rows.Close()
+
+	cloud := []models.TagCloudItem{}
+	for rows.Next() {
+		var item models.TagCloudItem
+		if err := rows.Scan(&item.ID, &item.Name, &item.Slug, &item.PostCount); err != nil {
+			logging.FromContext(ctx).Error("Error scanning tag cloud row", "error", err)
+			return nil, fmt.Errorf("failed to scan tag cloud item: %w", err)
+		}
+		cloud = append(cloud, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag cloud rows: %w", err)
+	}
+
+	return cloud, nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// TeamServiceInterface defines the methods that any team service implementation must provide.
+type TeamServiceInterface interface {
+	GetTeams(ctx context.Context, userID string, page, limit int) ([]models.Team, int, int, error) // Returns teams, totalPages, totalItems
+	GetTeamByID(ctx context.Context, id string) (*models.Team, error)
+	CreateTeam(ctx context.Context, team *models.Team, ownerID string) error
+	UpdateTeam(ctx context.Context, team *models.Team) error
+	DeleteTeam(ctx context.Context, id string) error
+	GetMembers(ctx context.Context, teamID string) ([]models.TeamMember, error)
+	GetMemberRole(ctx context.Context, teamID, userID string) (string, error) // Returns "" if the user isn't a member
+	AddMember(ctx context.Context, teamID, userID, role string) error
+	UpdateMemberRole(ctx context.Context, teamID, userID, role string) error
+	RemoveMember(ctx context.Context, teamID, userID string) error
+}
+
+// TeamService manages the teams of a tenant and their members.
+type TeamService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewTeamService creates and returns a new TeamService instance using the given connection pool.
+func NewTeamService(db *database.DB) *TeamService {
+	return &TeamService{db: db}
+}
+
+const teamColumns = "t.id, t.tenant_id, t.name, t.description, t.created_at, t.updated_at"
+
+// teamScope returns a condition restricting a query to the rows whose teamColumn is a team
+// the user bound to placeholder $userArg is a member of. Resources shared within teams
+// (e.g., posts with a team_id) add it to their queries:
+//
+//	query += " AND (p.user_id = $1 OR " + teamScope("p.team_id", 1) + ")"
+func teamScope(teamColumn string, userArg int) string {
+	return fmt.Sprintf("%s IN (SELECT team_id FROM team_members WHERE user_id = $%d)", teamColumn, userArg)
+}
+
+// GetTeams retrieves the teams of the request's tenant the user is a member of, by name,
+// with the user's role in each of them.
+func (s *TeamService) GetTeams(ctx context.Context, userID string, page, limit int) ([]models.Team, int, int, error) {
+	teams := []models.Team{}
+	var totalItems int
+
+	tenantID := tenancy.ID(ctx)
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(t.id) FROM teams t WHERE t.tenant_id = $1 AND "+teamScope("t.id", 2), tenantID, userID,
+	).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count teams: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+teamColumns+`, m.role
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.id AND m.user_id = $2
+		WHERE t.tenant_id = $1
+		ORDER BY t.name ASC
+		LIMIT $3 OFFSET $4`,
+		tenantID, userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var team models.Team
+		err := rows.Scan(&team.ID, &team.TenantID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt, &team.Role)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning team row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, team)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return teams, totalPages, totalItems, nil
+}
+
+// GetTeamByID fetches a team of the request's tenant by its ID.
+func (s *TeamService) GetTeamByID(ctx context.Context, id string) (*models.Team, error) {
+	team := &models.Team{}
+	err := s.db.QueryRowContext(ctx,
+		"SELECT "+teamColumns+" FROM teams t WHERE t.id = $1 AND t.tenant_id = $2", id, tenancy.ID(ctx),
+	).Scan(&team.ID, &team.TenantID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil // Team not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching team", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch team by ID: %w", err)
+	}
+	return team, nil
+}
+
+// CreateTeam creates a team in the request's tenant, with ownerID as its first owner.
+func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team, ownerID string) error {
+	team.ID = uuid.New().String()
+	team.TenantID = tenancy.ID(ctx)
+	team.Role = models.TeamRoleOwner
+	team.CreatedAt = time.Now()
+	team.UpdatedAt = time.Now()
+
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO teams (id, tenant_id, name, description, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			team.ID, team.TenantID, team.Name, team.Description, team.CreatedAt, team.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create team: %w", err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO team_members (team_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)",
+			team.ID, ownerID, models.TeamRoleOwner, team.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add team owner: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating team", "team_name", team.Name, "error", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateTeam changes the name and description of a team.
+func (s *TeamService) UpdateTeam(ctx context.Context, team *models.Team) error {
+	team.UpdatedAt = time.Now()
+
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE teams SET name = $1, description = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5",
+		team.Name, team.Description, team.UpdatedAt, team.ID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating team", "team_id", team.ID, "error", err)
+		return fmt.Errorf("failed to update team: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("team with ID %s not found for update", team.ID)
+	}
+	return nil
+}
+
+// DeleteTeam deletes a team by its ID. Its memberships are removed by ON DELETE CASCADE.
+func (s *TeamService) DeleteTeam(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM teams WHERE id = $1 AND tenant_id = $2", id, tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting team", "id", id, "error", err)
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("team with ID %s not found for deletion", id)
+	}
+	return nil
+}
+
+// GetMembers lists the members of a team, owners first. Users in the trash are left out.
+func (s *TeamService) GetMembers(ctx context.Context, teamID string) ([]models.TeamMember, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id, u.username, u.email, m.role, m.created_at
+		FROM team_members m
+		JOIN teams t ON t.id = m.team_id AND t.tenant_id = $2
+		JOIN users u ON u.id = m.user_id AND `+database.NotDeleted("u")+`
+		WHERE m.team_id = $1
+		ORDER BY CASE WHEN m.role = $3 THEN 0 ELSE 1 END, u.username ASC`,
+		teamID, tenancy.ID(ctx), models.TeamRoleOwner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.TeamMember{}
+	for rows.Next() {
+		var member models.TeamMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Email, &member.Role, &member.JoinedAt); err != nil {
+			logging.FromContext(ctx).Error("Error scanning team member row", "error", err)
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team member rows: %w", err)
+	}
+	return members, nil
+}
+
+// GetMemberRole returns the role of a user in a team of the request's tenant, or "" if the user
+// isn't a member of it.
+func (s *TeamService) GetMemberRole(ctx context.Context, teamID, userID string) (string, error) {
+	var role string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT m.role FROM team_members m
+		JOIN teams t ON t.id = m.team_id AND t.tenant_id = $3
+		WHERE m.team_id = $1 AND m.user_id = $2`,
+		teamID, userID, tenancy.ID(ctx),
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil // Not a member
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch team member role: %w", err)
+	}
+	return role, nil
+}
+
+// AddMember adds a user of the request's tenant to a team with the given role.
+func (s *TeamService) AddMember(ctx context.Context, teamID, userID, role string) error {
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := lockTeam(ctx, tx, teamID); err != nil {
+			return err
+		}
+
+		var exists int
+		err := tx.QueryRowContext(ctx,
+			"SELECT COUNT(id) FROM users WHERE id = $1 AND tenant_id = $2 AND "+database.NotDeleted(""), userID, tenancy.ID(ctx),
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check user: %w", err)
+		}
+		if exists == 0 {
+			return notFoundf("user with ID %s not found", userID)
+		}
+
+		current, err := memberRole(ctx, tx, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if current != "" {
+			return conflictf("user %s is already a member of the team", userID)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO team_members (team_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)",
+			teamID, userID, role, time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add team member: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateMemberRole changes the role of a member of a team. The last owner can't be demoted.
+func (s *TeamService) UpdateMemberRole(ctx context.Context, teamID, userID, role string) error {
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := lockTeam(ctx, tx, teamID); err != nil {
+			return err
+		}
+		current, err := memberRole(ctx, tx, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return notFoundf("user %s is not a member of the team", userID)
+		}
+		if current == models.TeamRoleOwner && role != models.TeamRoleOwner {
+			if err := keepOwner(ctx, tx, teamID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE team_members SET role = $1 WHERE team_id = $2 AND user_id = $3", role, teamID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update team member role: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveMember removes a user from a team. The last owner can't be removed.
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID string) error {
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := lockTeam(ctx, tx, teamID); err != nil {
+			return err
+		}
+		current, err := memberRole(ctx, tx, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return notFoundf("user %s is not a member of the team", userID)
+		}
+		if current == models.TeamRoleOwner {
+			if err := keepOwner(ctx, tx, teamID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove team member: %w", err)
+		}
+		return nil
+	})
+}
+
+// lockTeam locks the row of a team of the request's tenant for the rest of tx, so concurrent
+// membership changes can't leave the team without an owner.
+func lockTeam(ctx context.Context, tx *database.Tx, teamID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx,
+		"SELECT id FROM teams WHERE id = $1 AND tenant_id = $2"+tx.Dialect().ForUpdate(false), teamID, tenancy.ID(ctx),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return notFoundf("team with ID %s not found", teamID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock team: %w", err)
+	}
+	return nil
+}
+
+// memberRole returns the role of a user in a team, or "" if the user isn't a member of it.
+func memberRole(ctx context.Context, tx *database.Tx, teamID, userID string) (string, error) {
+	var role string
+	err := tx.QueryRowContext(ctx, "SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch team member role: %w", err)
+	}
+	return role, nil
+}
+
+// keepOwner fails with a conflict if a team has a single owner, who is about to be demoted or removed.
+func keepOwner(ctx context.Context, tx *database.Tx, teamID string) error {
+	var owners int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(user_id) FROM team_members WHERE team_id = $1 AND role = $2", teamID, models.TeamRoleOwner,
+	).Scan(&owners)
+	if err != nil {
+		return fmt.Errorf("failed to count team owners: %w", err)
+	}
+	if owners <= 1 {
+		return conflictf("a team must keep at least one owner")
+	}
+	return nil
+}
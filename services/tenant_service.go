@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// TenantServiceInterface defines the methods that any tenant service implementation must provide.
+type TenantServiceInterface interface {
+	GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+}
+
+// TenantService provides methods for tenant lookups, implementing TenantServiceInterface.
+type TenantService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewTenantService creates and returns a new TenantService instance using the given connection pool.
+func NewTenantService(db *database.DB) *TenantService {
+	return &TenantService{db: db}
+}
+
+// GetTenantBySlug fetches a tenant by the subdomain it is served under.
+func (s *TenantService) GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	tenant := &models.Tenant{}
+	query := "SELECT id, name, slug, created_at, updated_at FROM tenants WHERE slug = $1"
+	err := s.db.QueryRowContext(ctx, query, slug).Scan(&tenant.ID, &tenant.Name, &tenant.Slug, &tenant.CreatedAt, &tenant.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Tenant not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching tenant by slug", "slug", slug, "error", err)
+		return nil, fmt.Errorf("failed to fetch tenant by slug: %w", err)
+	}
+	return tenant, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/pgerr"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// trashResource describes where the records of a trash resource live.
+type trashResource struct {
+	table       string // Soft delete table, see database.SoftDeleteTables
+	labelColumn string // Column shown as the record's label
+}
+
+// trashResources maps the resources that can be browsed in the trash to their tables.
+// Only these table and column names are ever interpolated into SQL.
+var trashResources = map[string]trashResource{
+	models.TrashResourceUsers:    {table: "users", labelColumn: "email"},
+	models.TrashResourceRoles:    {table: "roles", labelColumn: "name"},
+	models.TrashResourcePosts:    {table: "posts", labelColumn: "title"},
+	models.TrashResourceProducts: {table: "products", labelColumn: "name"},
+}
+
+// TrashServiceInterface defines the methods that any trash service implementation must provide.
+type TrashServiceInterface interface {
+	IsTrashResource(resource string) bool
+	GetTrashed(ctx context.Context, resource string, page, limit int) ([]models.TrashedRecord, int, int, error) // Returns records, totalPages, totalItems
+	Restore(ctx context.Context, resource, id string) error
+	Purge(ctx context.Context, resource, id string) error
+}
+
+// TrashService lists, restores and permanently deletes soft deleted records.
+type TrashService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewTrashService creates and returns a new TrashService instance using the given connection pool.
+func NewTrashService(db *database.DB) *TrashService {
+	return &TrashService{db: db}
+}
+
+// IsTrashResource reports whether resource can be browsed in the trash.
+func (s *TrashService) IsTrashResource(resource string) bool {
+	_, ok := trashResources[resource]
+	return ok
+}
+
+// GetTrashed fetches the soft deleted records of a resource, most recently deleted first.
+// Records of tenant owned resources are limited to the request's tenant.
+func (s *TrashService) GetTrashed(ctx context.Context, resource string, page, limit int) ([]models.TrashedRecord, int, int, error) {
+	res, ok := trashResources[resource]
+	if !ok {
+		return nil, 0, 0, invalidf("unsupported trash resource: %s", resource)
+	}
+
+	where := " WHERE deleted_at IS NOT NULL"
+	args := []interface{}{}
+	if database.TenantTables[res.table] {
+		where += " AND tenant_id = $1"
+		args = append(args, tenancy.ID(ctx))
+	}
+
+	var totalItems int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM "+res.table+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count trashed %s: %w", resource, err)
+	}
+
+	offset := (page - 1) * limit
+	query := fmt.Sprintf("SELECT id, %s, deleted_at FROM %s%s ORDER BY deleted_at DESC, id ASC LIMIT $%d OFFSET $%d",
+		res.labelColumn, res.table, where, len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query trashed %s: %w", resource, err)
+	}
+	defer rows.Close()
+
+	records := []models.TrashedRecord{}
+	for rows.Next() {
+		record := models.TrashedRecord{Type: resource}
+		if err := rows.Scan(&record.ID, &record.Label, &record.DeletedAt); err != nil {
+			logging.FromContext(ctx).Error("Error scanning trashed row", "resource", resource, "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan trashed record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating trashed %s rows: %w", resource, err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	if totalPages == 0 && totalItems > 0 {
+		totalPages = 1
+	}
+
+	return records, totalPages, totalItems, nil
+}
+
+// Restore takes a record out of the trash.
+func (s *TrashService) Restore(ctx context.Context, resource, id string) error {
+	res, ok := trashResources[resource]
+	if !ok {
+		return invalidf("unsupported trash resource: %s", resource)
+	}
+
+	restored, err := database.Restore(ctx, s.db, res.table, id)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error restoring trashed record", "resource", resource, "id", id, "error", err)
+		return fmt.Errorf("failed to restore record: %w", err)
+	}
+	if !restored {
+		return notFoundf("trashed record with ID %s not found", id)
+	}
+	return nil
+}
+
+// Purge permanently deletes a record from the trash.
+// Records still referenced by other rows (e.g. ordered products, roles with users) can't be purged.
+func (s *TrashService) Purge(ctx context.Context, resource, id string) error {
+	res, ok := trashResources[resource]
+	if !ok {
+		return invalidf("unsupported trash resource: %s", resource)
+	}
+
+	purged, err := database.Purge(ctx, s.db, res.table, id)
+	if pgerr.IsForeignKeyViolation(err) {
+		return conflictf("record with ID %s is still referenced", id)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error purging trashed record", "resource", resource, "id", id, "error", err)
+		return fmt.Errorf("failed to purge record: %w", err)
+	}
+	if !purged {
+		return notFoundf("trashed record with ID %s not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+)
+
+// UserNoteServiceInterface defines the methods that any user note service implementation must provide.
+type UserNoteServiceInterface interface {
+	GetNotes(ctx context.Context, userID string, page, limit int) ([]models.UserNote, int, int, error) // Returns notes, totalPages, totalItems
+	GetNoteByID(ctx context.Context, userID, id string) (*models.UserNote, error)
+	CreateNote(ctx context.Context, note *models.UserNote) error
+	UpdateNote(ctx context.Context, note *models.UserNote) error
+	DeleteNote(ctx context.Context, userID, id string) error
+}
+
+// UserNoteService manages the internal notes of the support staff on user accounts. Notes
+// belong to the tenant of the user they are about, and every change is recorded in the
+// audit log.
+type UserNoteService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewUserNoteService creates and returns a new UserNoteService instance using the given connection pool.
+func NewUserNoteService(db *database.DB) *UserNoteService {
+	return &UserNoteService{db: db}
+}
+
+const userNoteColumns = "n.id, n.user_id, n.author_id, COALESCE(a.username, ''), n.content, n.created_at, n.updated_at"
+
+// userNoteFrom joins the notes with their author; the WHERE clause must restrict n.user_id to
+// a user of the request's tenant, see checkUsers.
+const userNoteFrom = " FROM user_notes n LEFT JOIN users a ON a.id = n.author_id"
+
+// GetNotes retrieves the notes on a user of the request's tenant, newest first.
+func (s *UserNoteService) GetNotes(ctx context.Context, userID string, page, limit int) ([]models.UserNote, int, int, error) {
+	notes := []models.UserNote{}
+	var totalItems int
+
+	if err := checkUsers(ctx, s.db, []string{userID}); err != nil {
+		return nil, 0, 0, err
+	}
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM user_notes WHERE user_id = $1", userID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count user notes: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+userNoteColumns+userNoteFrom+" WHERE n.user_id = $1 ORDER BY n.created_at DESC LIMIT $2 OFFSET $3",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query user notes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		note, err := scanUserNote(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning user note row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan user note: %w", err)
+		}
+		notes = append(notes, *note)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating user note rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return notes, totalPages, totalItems, nil
+}
+
+// GetNoteByID fetches a note on a user of the request's tenant by its ID.
+func (s *UserNoteService) GetNoteByID(ctx context.Context, userID, id string) (*models.UserNote, error) {
+	return s.getNote(ctx, s.db, userID, id)
+}
+
+// getNote fetches a note on the connection pool or in a transaction; it returns (nil, nil) if
+// the note doesn't exist or isn't about a user of the request's tenant.
+func (s *UserNoteService) getNote(ctx context.Context, db rowQueryer, userID, id string) (*models.UserNote, error) {
+	if err := checkUsers(ctx, db, []string{userID}); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	note, err := scanUserNote(db.QueryRowContext(ctx,
+		"SELECT "+userNoteColumns+userNoteFrom+" WHERE n.id = $1 AND n.user_id = $2", id, userID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil // Note not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching user note", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch user note by ID: %w", err)
+	}
+	return note, nil
+}
+
+// CreateNote adds a note on a user of the request's tenant, written by note.AuthorID.
+func (s *UserNoteService) CreateNote(ctx context.Context, note *models.UserNote) error {
+	note.ID = uuid.New().String()
+	note.CreatedAt = time.Now()
+	note.UpdatedAt = note.CreatedAt
+
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := checkUsers(ctx, tx, []string{note.UserID}); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO user_notes (id, user_id, author_id, content, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			note.ID, note.UserID, note.AuthorID, note.Content, note.CreatedAt, note.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create user note: %w", err)
+		}
+		if note.AuthorID != nil {
+			if err := tx.QueryRowContext(ctx, "SELECT username FROM users WHERE id = $1", *note.AuthorID).Scan(&note.AuthorName); err != nil {
+				return fmt.Errorf("failed to fetch user note author: %w", err)
+			}
+		}
+		return recordAudit(ctx, tx, models.AuditActionCreate, models.AuditEntityUserNote, note.ID, nil, note)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating user note", "user_id", note.UserID, "error", err)
+		return err
+	}
+
+	logging.FromContext(ctx).Info("User note created", "id", note.ID, "user_id", note.UserID)
+	return nil
+}
+
+// UpdateNote changes the content of a note on a user of the request's tenant.
+func (s *UserNoteService) UpdateNote(ctx context.Context, note *models.UserNote) error {
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		before, err := s.getNote(ctx, tx, note.UserID, note.ID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return notFoundf("user note with ID %s not found for update", note.ID)
+		}
+
+		after := *before
+		after.Content, after.UpdatedAt = note.Content, time.Now()
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE user_notes SET content = $1, updated_at = $2 WHERE id = $3", after.Content, after.UpdatedAt, after.ID,
+		); err != nil {
+			return fmt.Errorf("failed to update user note: %w", err)
+		}
+		if err := recordAudit(ctx, tx, models.AuditActionUpdate, models.AuditEntityUserNote, after.ID, before, &after); err != nil {
+			return err
+		}
+		*note = after
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating user note", "id", note.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteNote deletes a note on a user of the request's tenant.
+func (s *UserNoteService) DeleteNote(ctx context.Context, userID, id string) error {
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		before, err := s.getNote(ctx, tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return notFoundf("user note with ID %s not found for deletion", id)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_notes WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete user note: %w", err)
+		}
+		return recordAudit(ctx, tx, models.AuditActionDelete, models.AuditEntityUserNote, id, before, nil)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting user note", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// scanUserNote scans a note row selected as userNoteColumns.
+func scanUserNote(row rowScanner) (*models.UserNote, error) {
+	note := &models.UserNote{}
+	var authorID sql.NullString
+	if err := row.Scan(&note.ID, &note.UserID, &authorID, &note.AuthorName, &note.Content, &note.CreatedAt, &note.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if authorID.Valid {
+		note.AuthorID = &authorID.String
+	}
+	return note, nil
+}
@@ -1,87 +1,103 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"log"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/anpsniper/anpbayu-be/database" // Import the database package
-	"github.com/anpsniper/anpbayu-be/models"   // Import the models package
-	"github.com/google/uuid"                   // For generating UUIDs
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models" // Import the models package
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/anpsniper/anpbayu-be/webhooks"
+	"github.com/google/uuid" // For generating UUIDs
 	"golang.org/x/crypto/bcrypt"
 )
 
 // UserServiceInterface defines the methods that any user service implementation must provide.
 // This allows for dependency inversion and easier testing (e.g., by mocking the service).
 type UserServiceInterface interface {
-	GetAllUsers(search string, roleID string, page, limit int) ([]models.User, int, int, error) // Returns users, totalPages, totalItems
-	GetUserByID(id string) (*models.User, error)
-	GetUserByEmail(email string) (*models.User, error)
-	CreateUser(user *models.User) error
-	UpdateUser(req *models.UpdateUserRequest) error
-	DeleteUser(id string) error
-	GetAllRoles() ([]models.LstRole, error)
-	CreateUserLoginLog(userID string) (int, error) // NEW: Method to create a login log
-	UpdateUserLogoutLog(logID int) error           // NEW: Method to update a logout log
+	GetAllUsers(ctx context.Context, search string, roleID string, tag string, page, limit int) ([]models.User, int, int, error) // Returns users, totalPages, totalItems
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByPhone(ctx context.Context, phone string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, req *models.UpdateUserRequest) error
+	DeleteUser(ctx context.Context, id string) error
+	SetAvatar(ctx context.Context, id string, key *string) (*string, error) // Returns the storage key of the replaced avatar
+	GetAllRoles(ctx context.Context) ([]models.LstRole, error)
+	CreateLoginSession(ctx context.Context, session *models.Session) (int, error)                                      // Returns the ID of the login log entry
+	UpdateUserLogoutLog(ctx context.Context, userID string, logID int) error                                           // NEW: Method to update a logout log
+	GetUserLogs(ctx context.Context, filter models.UserLogFilter, page, limit int) ([]models.UserLog, int, int, error) // Returns logs, totalPages, totalItems
+	GetUserLogsAfter(ctx context.Context, filter models.UserLogFilter, after *models.Cursor, limit int) ([]models.UserLog, *models.Cursor, error)
+	ExportUserLogs(ctx context.Context, filter models.UserLogFilter, fn func(*models.UserLog) error) error
+	ExportUsers(ctx context.Context, search string, roleID string, tag string, fn func(*models.User) error) error
+	GetUserProfile(ctx context.Context, id string) (*models.UserProfile, error)
+	UpdateUserPreferences(ctx context.Context, id string, preferences json.RawMessage) error
+	GetUserTags(ctx context.Context) ([]models.UserTagCount, error)
+	SetUserTags(ctx context.Context, id string, tags []string) ([]string, error) // Returns the normalized tags
+	TagUsers(ctx context.Context, userIDs []string, add, remove []string) error
 }
 
 // UserService provides methods for user-related business logic, implementing UserServiceInterface.
 type UserService struct {
-	// No fields needed here if we're using a global DB connection from the database package.
-	// If you were to pass the DB connection, it would be: db *sql.DB
+	db    *database.DB // Database connection pool
+	roles *RoleCache   // Roles of the users, looked up in memory instead of joined
+
+	// Prepared statements of the login path
+	getByEmail    *database.Stmt // userByEmailQuery
+	insertSession *database.Stmt // insertSessionQuery
+	insertLog     *database.Stmt // insertUserLogQuery
 }
 
-// NewUserService creates and returns a new UserService instance.
+// NewUserService creates and returns a new UserService instance using the given connection pool.
+// The roles of the users are looked up in roles, and the queries of the login path are prepared.
 // It returns the concrete *UserService type, which satisfies the UserServiceInterface.
-func NewUserService() *UserService { // Changed return type to *UserService
-	return &UserService{}
+func NewUserService(db *database.DB, roles *RoleCache) *UserService { // Changed return type to *UserService
+	ctx := context.Background()
+	return &UserService{
+		db:            db,
+		roles:         roles,
+		getByEmail:    db.Prepared(ctx, userByEmailQuery),
+		insertSession: db.Prepared(ctx, insertSessionQuery),
+		insertLog:     db.PreparedInsert(ctx, insertUserLogQuery),
+	}
 }
 
-// GetAllUsers retrieves a list of users with optional search, role filtering, and pagination.
-func (s *UserService) GetAllUsers(search string, roleID string, page, limit int) ([]models.User, int, int, error) {
-	if database.DB == nil {
-		return nil, 0, 0, fmt.Errorf("database connection is not initialized")
-	}
+// insertUserLogQuery logs a login in the login history of a user.
+const insertUserLogQuery = "INSERT INTO user_logs (user_id, login_at) VALUES ($1, $2)"
 
+// GetAllUsers retrieves a list of users with optional search, role and tag filtering, and pagination.
+func (s *UserService) GetAllUsers(ctx context.Context, search string, roleID string, tag string, page, limit int) ([]models.User, int, int, error) {
+	ctx = database.WithOperation(ctx, "users.list")
 	var users []models.User
 	var totalItems int
 
-	// Build the base query
-	countQuery := "SELECT COUNT(a.id) FROM users a LEFT JOIN roles b ON a.role_id = b.id WHERE 1=1"
-	selectQuery := "SELECT a.id, a.username, a.email, a.role_id, b.name AS role_name, a.created_at, a.updated_at FROM users a LEFT JOIN roles b ON a.role_id = b.id WHERE 1=1"
-	args := []interface{}{}
-	argCounter := 1
-
-	// Add search condition if provided (applies to username, email, or role name)
-	if search != "" {
-		searchPattern := "%" + search + "%"
-		countQuery += fmt.Sprintf(" AND (a.username ILIKE $%d OR a.email ILIKE $%d OR b.name ILIKE $%d)", argCounter, argCounter+1, argCounter+2)
-		selectQuery += fmt.Sprintf(" AND (a.username ILIKE $%d OR a.email ILIKE $%d OR b.name ILIKE $%d)", argCounter, argCounter+1, argCounter+2)
-		args = append(args, searchPattern, searchPattern, searchPattern)
-		argCounter += 3 // Increment by 3 for 3 placeholders
-	}
-
-	// Add roleID filter if provided
-	if roleID != "" {
-		countQuery += fmt.Sprintf(" AND a.role_id = $%d", argCounter)
-		selectQuery += fmt.Sprintf(" AND a.role_id = $%d", argCounter)
-		args = append(args, roleID)
-		argCounter++ // Increment for the new placeholder
+	// Role names are looked up in the role cache rather than joined
+	where, args, err := s.usersWhere(ctx, search, roleID, tag)
+	if err != nil {
+		return nil, 0, 0, err
 	}
+	countQuery := "SELECT COUNT(a.id) FROM users a" + where
+	selectQuery := "SELECT a.id, a.username, a.email, a.role_id, a.phone, a.avatar_key, a.version, a.created_at, a.updated_at FROM users a" + where
 
 	// Get total items
-	err := database.DB.QueryRow(countQuery, args...).Scan(&totalItems)
+	err = s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
 	// Calculate pagination offsets
 	offset := (page - 1) * limit
-	selectQuery += fmt.Sprintf(" ORDER BY a.username ASC LIMIT $%d OFFSET $%d", argCounter, argCounter+1)
+	selectQuery += fmt.Sprintf(" ORDER BY a.username ASC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 	args = append(args, limit, offset)
 
-	rows, err := database.DB.Query(selectQuery, args...)
+	rows, err := s.db.QueryContext(ctx, selectQuery, args...)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("failed to query users: %w", err)
 	}
@@ -89,10 +105,9 @@ func (s *UserService) GetAllUsers(search string, roleID string, page, limit int)
 
 	for rows.Next() {
 		var user models.User
-		// Scan directly into user.RoleName
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.RoleID, &user.RoleName, &user.CreatedAt, &user.UpdatedAt)
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.RoleID, &user.Phone, &user.AvatarKey, &user.Version, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
-			log.Printf("Error scanning user row: %v", err)
+			logging.FromContext(ctx).Error("Error scanning user row", "error", err)
 			return nil, 0, 0, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
@@ -101,6 +116,20 @@ func (s *UserService) GetAllUsers(search string, roleID string, page, limit int)
 	if err = rows.Err(); err != nil {
 		return nil, 0, 0, fmt.Errorf("error iterating user rows: %w", err)
 	}
+	// Once the rows are read, as loading the role cache may need another connection, of which
+	// SQLite has only one
+	for i := range users {
+		role, err := s.roles.Role(ctx, users[i].RoleID)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if role != nil {
+			users[i].RoleName = role.Name
+		}
+	}
+	if err := s.loadUserTags(ctx, users); err != nil {
+		return nil, 0, 0, err
+	}
 
 	totalPages := (totalItems + limit - 1) / limit
 	if totalPages == 0 && totalItems > 0 { // Handle case where totalItems < limit
@@ -110,34 +139,112 @@ func (s *UserService) GetAllUsers(search string, roleID string, page, limit int)
 	return users, totalPages, totalItems, nil
 }
 
-// CreateUserLoginLog creates a new login log entry for a user.
-// It returns the ID of the newly created log entry, which can be used for logout.
-func (s *UserService) CreateUserLoginLog(userID string) (int, error) {
-	if database.DB == nil {
-		return 0, fmt.Errorf("database connection is not initialized")
+// usersWhere returns the WHERE clause selecting the users of the request's tenant a matching
+// the filters of GetAllUsers, and its arguments.
+func (s *UserService) usersWhere(ctx context.Context, search string, roleID string, tag string) (string, []interface{}, error) {
+	where := " WHERE a.tenant_id = $1 AND " + database.NotDeleted("a")
+	args := []interface{}{tenancy.ID(ctx)}
+
+	// Add search condition if provided (applies to username, email, or role name)
+	if search != "" {
+		searchPattern := "%" + search + "%"
+		ilike := s.db.Dialect().ILike
+		condition := fmt.Sprintf("%s OR %s", ilike("a.username", len(args)+1), ilike("a.email", len(args)+2))
+		args = append(args, searchPattern, searchPattern)
+		roleIDs, err := s.roles.IDsMatching(ctx, search)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(roleIDs) > 0 {
+			condition += fmt.Sprintf(" OR a.role_id IN (%s)", database.Placeholders(len(args)+1, len(roleIDs)))
+			for _, id := range roleIDs {
+				args = append(args, id)
+			}
+		}
+		where += " AND (" + condition + ")"
+	}
+
+	// Add roleID filter if provided
+	if roleID != "" {
+		args = append(args, roleID)
+		where += fmt.Sprintf(" AND a.role_id = $%d", len(args))
+	}
+
+	// Add tag filter if provided
+	if tag != "" {
+		args = append(args, strings.ToLower(strings.TrimSpace(tag)))
+		where += fmt.Sprintf(" AND a.id IN (SELECT user_id FROM user_tags WHERE tag = $%d)", len(args))
 	}
+	return where, args, nil
+}
 
+// ExportUsers calls fn with every user of the request's tenant matching the filters of
+// GetAllUsers, by username, without loading them all in memory. Their role names are joined,
+// as the role cache may not be loaded while the rows are read (see GetAllUsers), and their tags
+// are left out. It stops at the first error of fn and returns it.
+func (s *UserService) ExportUsers(ctx context.Context, search string, roleID string, tag string, fn func(*models.User) error) error {
+	ctx = database.WithOperation(ctx, "users.export")
+	where, args, err := s.usersWhere(ctx, search, roleID, tag)
+	if err != nil {
+		return err
+	}
+	query := "SELECT a.id, a.username, a.email, a.phone, a.role_id, COALESCE(r.name, ''), a.created_at, a.updated_at FROM users a LEFT JOIN roles r ON r.id = a.role_id" +
+		where + " ORDER BY a.username ASC, a.id ASC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Phone, &user.RoleID, &user.RoleName, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating user rows: %w", err)
+	}
+	return nil
+}
+
+// CreateLoginSession stores the session of a login (see SessionService.CreateSession) and logs
+// the login in the user's login history, in one transaction, so neither exists without the
+// other. It returns the ID of the new log entry, which can be used for logout.
+func (s *UserService) CreateLoginSession(ctx context.Context, session *models.Session) (int, error) {
+	ctx = database.WithOperation(ctx, "users.create_login_session")
 	var logID int
-	query := `INSERT INTO user_logs (user_id, login_at) VALUES ($1, NOW()) RETURNING id`
-	err := database.DB.QueryRow(query, userID).Scan(&logID)
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := insertSession(ctx, tx.Stmt(ctx, s.insertSession), session); err != nil {
+			return err
+		}
+		id, err := tx.Stmt(ctx, s.insertLog).InsertReturningID(ctx, session.UserID, session.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create user login log: %w", err)
+		}
+		logID = int(id)
+		return nil
+	})
 	if err != nil {
-		log.Printf("ERROR: Failed to create user login log for user %s: %v", userID, err)
-		return 0, fmt.Errorf("failed to create user login log: %w. Please check if 'user_logs' table exists and its schema matches (id SERIAL PRIMARY KEY, user_id UUID NOT NULL, login_at TIMESTAMP WITH TIME ZONE, logout_at TIMESTAMP WITH TIME ZONE)", err)
+		logging.FromContext(ctx).Error("Failed to create login session for user", "user_id", session.UserID, "error", err)
+		return 0, err
 	}
-	log.Printf("INFO: Successfully created login log for user %s with ID: %d", userID, logID)
+	logging.FromContext(ctx).Info("Created login log", "user_id", session.UserID, "log_id", logID)
 	return logID, nil
 }
 
 // UpdateUserLogoutLog updates the logout_at timestamp for a specific user log entry.
-func (s *UserService) UpdateUserLogoutLog(logID int) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
-	}
-
-	query := `UPDATE user_logs SET logout_at = NOW() WHERE id = $1`
-	result, err := database.DB.Exec(query, logID)
+// Only entries of userID, a user of the request's tenant, are updated; the log ID comes from
+// the client, so the entries of other users are reported as not found.
+func (s *UserService) UpdateUserLogoutLog(ctx context.Context, userID string, logID int) error {
+	ctx = database.WithOperation(ctx, "users.log_logout")
+	query := `UPDATE user_logs SET logout_at = $1 WHERE id = $2 AND user_id = $3 AND user_id IN (SELECT id FROM users WHERE tenant_id = $4)`
+	result, err := s.db.ExecContext(ctx, query, time.Now(), logID, userID, tenancy.ID(ctx))
 	if err != nil {
-		log.Printf("ERROR: Failed to update user logout log for log ID %d: %v", logID, err)
+		logging.FromContext(ctx).Error("Failed to update user logout log", "log_id", logID, "error", err)
 		return fmt.Errorf("failed to update user logout log: %w. Please check if 'user_logs' table exists and its schema matches (id SERIAL PRIMARY KEY, user_id UUID NOT NULL, login_at TIMESTAMP WITH TIME ZONE, logout_at TIMESTAMP WITH TIME ZONE)", err)
 	}
 
@@ -146,162 +253,438 @@ func (s *UserService) UpdateUserLogoutLog(logID int) error {
 		return fmt.Errorf("failed to check rows affected after logout log update: %w", err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("user log entry with ID %d not found for logout update", logID)
+		return notFoundf("user log entry with ID %d not found for logout update", logID)
+	}
+	logging.FromContext(ctx).Info("Updated logout log", "log_id", logID)
+	return nil
+}
+
+// GetAllRoles returns the roles of the role dropdown, from the role cache.
+func (s *UserService) GetAllRoles(ctx context.Context) ([]models.LstRole, error) {
+	return s.roles.Options(ctx)
+}
+
+// GetUserByID fetches a user by their ID, including their associated role.
+func (s *UserService) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	ctx = database.WithOperation(ctx, "users.get")
+	user := &models.User{}
+
+	query := `
+		SELECT
+			u.id, u.tenant_id, u.username, u.email, u.phone, u.password_hash, u.role_id, u.avatar_key, u.version, u.token_version, u.created_at, u.updated_at
+		FROM
+			users u
+		WHERE
+			u.id = $1 AND u.tenant_id = $2 AND ` + database.NotDeleted("u") + `
+	`
+	err := s.db.QueryRowContext(ctx, query, id, tenancy.ID(ctx)).Scan(
+		&user.ID, &user.TenantID, &user.Username, &user.Email, &user.Phone, &user.Password, &user.RoleID, &user.AvatarKey, &user.Version, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // User not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching user", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch user by ID: %w", err)
+	}
+
+	if err := s.loadRole(ctx, user); err != nil {
+		return nil, err
+	}
+	users := []models.User{*user}
+	if err := s.loadUserTags(ctx, users); err != nil {
+		return nil, err
+	}
+	user.Tags = users[0].Tags
+	return user, nil
+}
+
+// loadRole sets the role of user, and its name, from the role cache.
+func (s *UserService) loadRole(ctx context.Context, user *models.User) error {
+	role, err := s.roles.Role(ctx, user.RoleID)
+	if err != nil {
+		return err
+	}
+	if role != nil {
+		user.Role, user.RoleName = role, role.Name
 	}
-	log.Printf("INFO: Successfully updated logout log for ID: %d", logID)
 	return nil
 }
 
-func (s *UserService) GetAllRoles() ([]models.LstRole, error) {
-	if database.DB == nil {
-		return nil, fmt.Errorf("database connection is not initialized")
+// GetUserLogs retrieves the logins of the users of the request's tenant matching filter,
+// newest first, with the duration of the sessions that were logged out.
+func (s *UserService) GetUserLogs(ctx context.Context, filter models.UserLogFilter, page, limit int) ([]models.UserLog, int, int, error) {
+	ctx = database.WithOperation(ctx, "user_logs.list")
+	logs := []models.UserLog{}
+	var totalItems int
+
+	where, args := userLogsWhere(ctx, filter)
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(l.id)"+userLogsFrom+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count user logs: %w", err)
 	}
 
-	var roles []models.LstRole
+	offset := (page - 1) * limit
+	query := fmt.Sprintf("SELECT "+userLogColumns+userLogsFrom+"%s ORDER BY l.login_at DESC, l.id DESC LIMIT $%d OFFSET $%d", where, len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query user logs: %w", err)
+	}
+	defer rows.Close()
 
-	// Query only for ID and Name, as models.LstRole likely only contains these fields.
-	query := `SELECT id, name FROM roles ORDER BY name ASC`
+	for rows.Next() {
+		log, err := scanUserLog(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning user log row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan user log: %w", err)
+		}
+		logs = append(logs, *log)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating user log rows: %w", err)
+	}
 
-	rows, err := database.DB.Query(query)
+	totalPages := (totalItems + limit - 1) / limit
+	return logs, totalPages, totalItems, nil
+}
+
+// GetUserLogsAfter retrieves the logins matching filter like GetUserLogs, a page of limit
+// logins following the cursor after (the first page if nil) instead of at an offset, and the
+// cursor of the next page, nil on the last one. It stays fast however deep the page, using the
+// (login_at, id) index.
+func (s *UserService) GetUserLogsAfter(ctx context.Context, filter models.UserLogFilter, after *models.Cursor, limit int) ([]models.UserLog, *models.Cursor, error) {
+	ctx = database.WithOperation(ctx, "user_logs.list_after")
+	logs := []models.UserLog{}
+
+	where, args := userLogsWhere(ctx, filter)
+	if after != nil {
+		id, err := strconv.Atoi(after.ID)
+		if err != nil {
+			return nil, nil, invalidf("malformed cursor")
+		}
+		args = append(args, after.Time, id)
+		where += fmt.Sprintf(" AND (l.login_at < $%d OR (l.login_at = $%d AND l.id < $%d))", len(args)-1, len(args)-1, len(args))
+	}
+
+	// One more login than asked tells whether there's a next page
+	query := fmt.Sprintf("SELECT "+userLogColumns+userLogsFrom+"%s ORDER BY l.login_at DESC, l.id DESC LIMIT $%d", where, len(args)+1)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit+1)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query roles: %w", err)
+		return nil, nil, fmt.Errorf("failed to query user logs: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var role models.LstRole
-		// Scan only ID and Name
-		err := rows.Scan(&role.ID, &role.Name)
+		log, err := scanUserLog(rows)
 		if err != nil {
-			log.Printf("Error scanning role row: %v", err)
-			return nil, fmt.Errorf("failed to scan role: %w", err)
+			logging.FromContext(ctx).Error("Error scanning user log row", "error", err)
+			return nil, nil, fmt.Errorf("failed to scan user log: %w", err)
 		}
-		roles = append(roles, role)
+		logs = append(logs, *log)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating user log rows: %w", err)
+	}
+
+	if len(logs) <= limit {
+		return logs, nil, nil
+	}
+	logs = logs[:limit]
+	last := logs[limit-1]
+	return logs, &models.Cursor{Time: last.LoginAt, ID: strconv.Itoa(last.ID)}, nil
+}
+
+// ExportUserLogs calls fn with every login of the users of the request's tenant matching
+// filter, oldest first, without loading them all in memory. It stops at the first error of fn
+// and returns it.
+func (s *UserService) ExportUserLogs(ctx context.Context, filter models.UserLogFilter, fn func(*models.UserLog) error) error {
+	ctx = database.WithOperation(ctx, "user_logs.export")
+	where, args := userLogsWhere(ctx, filter)
+	rows, err := s.db.QueryContext(ctx, "SELECT "+userLogColumns+userLogsFrom+where+" ORDER BY l.login_at ASC", args...)
+	if err != nil {
+		return fmt.Errorf("failed to query user logs: %w", err)
 	}
+	defer rows.Close()
 
+	for rows.Next() {
+		log, err := scanUserLog(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan user log: %w", err)
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating role rows: %w", err)
+		return fmt.Errorf("error iterating user log rows: %w", err)
 	}
+	return nil
+}
+
+const (
+	userLogColumns = "l.id, l.user_id, u.username, l.login_at, l.logout_at"
+	userLogsFrom   = " FROM user_logs l JOIN users u ON u.id = l.user_id"
+)
 
-	return roles, nil
+// userLogsWhere returns the WHERE clause selecting the logins of the request's tenant matching
+// filter from userLogsFrom, and its arguments.
+func userLogsWhere(ctx context.Context, filter models.UserLogFilter) (string, []interface{}) {
+	where := " WHERE u.tenant_id = $1"
+	args := []interface{}{tenancy.ID(ctx)}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		where += fmt.Sprintf(" AND l.user_id = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(" AND l.login_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(" AND l.login_at < $%d", len(args))
+	}
+	return where, args
 }
 
-// GetUserByID fetches a user by their ID, including their associated role.
-func (s *UserService) GetUserByID(id string) (*models.User, error) {
-	if database.DB == nil {
-		return nil, fmt.Errorf("database connection is not initialized")
+// scanUserLog scans a login row selected as userLogColumns, computing the duration of the
+// session if it was logged out.
+func scanUserLog(row rowScanner) (*models.UserLog, error) {
+	log := &models.UserLog{}
+	var logoutAt sql.NullTime
+	if err := row.Scan(&log.ID, &log.UserID, &log.Username, &log.LoginAt, &logoutAt); err != nil {
+		return nil, err
+	}
+	if logoutAt.Valid {
+		duration := int64(logoutAt.Time.Sub(log.LoginAt).Seconds())
+		log.LogoutAt, log.DurationSeconds = &logoutAt.Time, &duration
 	}
+	return log, nil
+}
 
-	user := &models.User{}
-	role := &models.Role{} // To store role data
+// GetUserProfile fetches the profile of a user of the request's tenant, with their role, their
+// latest login and their preferences. It returns (nil, nil) if the user doesn't exist or was
+// deleted.
+func (s *UserService) GetUserProfile(ctx context.Context, id string) (*models.UserProfile, error) {
+	ctx = database.WithOperation(ctx, "users.get_profile")
+	profile := &models.UserProfile{}
+	var lastLoginAt sql.NullTime
+	var preferences sql.NullString
 
 	query := `
 		SELECT
-			u.id, u.username, u.email, u.password_hash, u.role_id, u.created_at, u.updated_at,
-			r.id, r.name, r.description, r.created_at, r.updated_at
+			u.id, u.username, u.email, u.phone, u.role_id, r.name, u.avatar_key, u.preferences, u.created_at, u.updated_at
 		FROM
 			users u
 		JOIN
 			roles r ON u.role_id = r.id
 		WHERE
-			u.id = $1
+			u.id = $1 AND u.tenant_id = $2 AND ` + database.NotDeleted("u") + `
 	`
-	err := database.DB.QueryRow(query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.RoleID, &user.CreatedAt, &user.UpdatedAt,
-		&role.ID, &user.RoleName, &role.Description, &role.CreatedAt, &role.UpdatedAt, // FIX: Scan r.name into user.RoleName
+	err := s.db.QueryRowContext(ctx, query, id, tenancy.ID(ctx)).Scan(
+		&profile.ID, &profile.Username, &profile.Email, &profile.Phone, &profile.RoleID, &profile.RoleName, &profile.AvatarKey, &preferences, &profile.CreatedAt, &profile.UpdatedAt,
 	)
-
 	if err == sql.ErrNoRows {
 		return nil, nil // User not found
 	}
 	if err != nil {
-		log.Printf("Error fetching user by ID %s: %v", id, err)
-		return nil, fmt.Errorf("failed to fetch user by ID: %w", err)
+		logging.FromContext(ctx).Error("Error fetching user profile", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch user profile: %w", err)
 	}
 
-	user.Role = role // Assign the fetched role to the user
-	return user, nil
+	err = s.db.QueryRowContext(ctx,
+		"SELECT login_at FROM user_logs WHERE user_id = $1 ORDER BY login_at DESC LIMIT 1", id,
+	).Scan(&lastLoginAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to fetch latest login: %w", err)
+	}
+	if lastLoginAt.Valid {
+		profile.LastLoginAt = &lastLoginAt.Time
+	}
+	profile.Preferences = json.RawMessage("{}")
+	if preferences.Valid {
+		profile.Preferences = json.RawMessage(preferences.String)
+	}
+	return profile, nil
+}
+
+// UpdateUserPreferences replaces the preferences of a user of the request's tenant with
+// preferences, a JSON object.
+func (s *UserService) UpdateUserPreferences(ctx context.Context, id string, preferences json.RawMessage) error {
+	ctx = database.WithOperation(ctx, "users.update_preferences")
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE users SET preferences = $1 WHERE id = $2 AND tenant_id = $3 AND "+database.NotDeleted(""),
+		string(preferences), id, tenancy.ID(ctx),
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating user preferences", "id", id, "error", err)
+		return fmt.Errorf("failed to update user preferences: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating user preferences: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("user with ID %s not found", id)
+	}
+	return nil
 }
 
+// userByEmailQuery fetches a user by their email, see GetUserByEmail.
+var userByEmailQuery = `
+	SELECT
+		u.id, u.tenant_id, u.username, u.email, u.phone, u.password_hash, u.role_id, u.avatar_key, u.version, u.token_version, u.created_at, u.updated_at
+	FROM
+		users u
+	WHERE
+		u.email = $1 AND ` + database.NotDeleted("u") + `
+`
+
 // GetUserByEmail fetches a user by their email, including their associated role.
-func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
-	if database.DB == nil {
-		return nil, fmt.Errorf("database connection is not initialized")
+// Emails are unique across tenants, so the lookup isn't tenant scoped: login uses it to find
+// the tenant of the user.
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx = database.WithOperation(ctx, "users.get_by_email")
+	user := &models.User{}
+
+	err := s.getByEmail.QueryRowContext(ctx, email).Scan(
+		&user.ID, &user.TenantID, &user.Username, &user.Email, &user.Phone, &user.Password, &user.RoleID, &user.AvatarKey, &user.Version, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // User not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching user by email", "email", email, "error", err)
+		return nil, fmt.Errorf("failed to fetch user by email: %w", err)
+	}
+
+	if err := s.loadRole(ctx, user); err != nil {
+		return nil, err
 	}
+	return user, nil
+}
 
+// GetUserByPhone fetches a user by their phone number, including their associated role.
+// Like emails, phone numbers are unique across tenants, so the lookup isn't tenant scoped.
+func (s *UserService) GetUserByPhone(ctx context.Context, phone string) (*models.User, error) {
+	ctx = database.WithOperation(ctx, "users.get_by_phone")
 	user := &models.User{}
-	role := &models.Role{} // To store role data
 
 	query := `
 		SELECT
-			u.id, u.username, u.email, u.password_hash, u.role_id, u.created_at, u.updated_at,
-			r.id, r.name, r.description, r.created_at, r.updated_at
+			u.id, u.tenant_id, u.username, u.email, u.phone, u.password_hash, u.role_id, u.avatar_key, u.version, u.token_version, u.created_at, u.updated_at
 		FROM
 			users u
-		JOIN
-			roles r ON u.role_id = r.id
 		WHERE
-			u.email = $1
+			u.phone = $1 AND ` + database.NotDeleted("u") + `
 	`
-	err := database.DB.QueryRow(query, email).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.RoleID, &user.CreatedAt, &user.UpdatedAt,
-		&role.ID, &user.RoleName, &role.Description, &role.CreatedAt, &role.UpdatedAt, // FIX: Scan r.name into user.RoleName
+	err := s.db.QueryRowContext(ctx, query, phone).Scan(
+		&user.ID, &user.TenantID, &user.Username, &user.Email, &user.Phone, &user.Password, &user.RoleID, &user.AvatarKey, &user.Version, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // User not found
 	}
 	if err != nil {
-		log.Printf("Error fetching user by email %s: %v", email, err)
-		return nil, fmt.Errorf("failed to fetch user by email: %w", err)
+		logging.FromContext(ctx).Error("Error fetching user by phone", "error", err)
+		return nil, fmt.Errorf("failed to fetch user by phone: %w", err)
 	}
 
-	user.Role = role // Assign the fetched role to the user
+	if err := s.loadRole(ctx, user); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
-// CreateUser inserts a new user into the database.
-func (s *UserService) CreateUser(user *models.User) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
-	}
-
+// CreateUser inserts a new user into the database, along with its audit log entry and the
+// webhook deliveries announcing it, in one transaction.
+func (s *UserService) CreateUser(ctx context.Context, user *models.User) error {
+	ctx = database.WithOperation(ctx, "users.create")
 	// Generate a new UUID for the user
 	user.ID = uuid.New().String()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	user.Version = 1
+	user.TenantID = tenancy.ID(ctx)
+	if err := s.checkNotTrashed(ctx, user.Email, user.Phone); err != nil {
+		return err
+	}
+
+	return database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		query := `
+			INSERT INTO users (id, tenant_id, username, email, phone, password_hash, role_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`
+		_, err := tx.ExecContext(ctx,
+			query,
+			user.ID,
+			user.TenantID,
+			user.Username,
+			user.Email,
+			user.Phone,
+			user.Password, // This should be the hashed password
+			user.RoleID,
+			user.CreatedAt,
+			user.UpdatedAt,
+		)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error creating user", "email", user.Email, "error", err)
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if err := recordAudit(ctx, tx, models.AuditActionCreate, models.AuditEntityUser, user.ID, nil, user); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(ctx, tx, user.TenantID, webhooks.EventUserCreated, user)
+	})
+}
 
-	query := `
-		INSERT INTO users (id, username, email, password_hash, role_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
-	_, err := database.DB.Exec(
-		query,
-		user.ID,
-		user.Username,
-		user.Email,
-		user.Password, // This should be the hashed password
-		user.RoleID,
-		user.CreatedAt,
-		user.UpdatedAt,
-	)
+// checkNotTrashed fails with a conflict error when the email or phone number is held by a user
+// in the trash: they stay reserved until the user is purged, while the lookups by email and
+// phone number no longer find the user.
+func (s *UserService) checkNotTrashed(ctx context.Context, email string, phone *string) error {
+	trashed, err := database.Trashed(ctx, s.db, "users", "email", email)
 	if err != nil {
-		log.Printf("Error creating user %s: %v", user.Email, err)
-		return fmt.Errorf("failed to create user: %w", err)
+		return fmt.Errorf("failed to check trashed users: %w", err)
+	}
+	if trashed {
+		return conflictf("email %s belongs to a user in the trash; restore or purge the user first", email)
+	}
+	if phone == nil || *phone == "" {
+		return nil
+	}
+	if trashed, err = database.Trashed(ctx, s.db, "users", "phone", *phone); err != nil {
+		return fmt.Errorf("failed to check trashed users: %w", err)
+	}
+	if trashed {
+		return conflictf("phone number %s belongs to a user in the trash; restore or purge the user first", *phone)
 	}
 	return nil
 }
 
 // UpdateUser updates an existing user's information in the database.
-// It updates username, email, and role_id. Password is not updated here.
-func (s *UserService) UpdateUser(req *models.UpdateUserRequest) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
+// It updates username, email, and role_id, and the password and phone number when provided.
+// When req.Version is set, the update fails with a conflict error if the user has been modified since.
+func (s *UserService) UpdateUser(ctx context.Context, req *models.UpdateUserRequest) error {
+	ctx = database.WithOperation(ctx, "users.update")
+	before, err := s.GetUserByID(ctx, req.ID) // Snapshot for the audit log
+	if err != nil {
+		return err
+	}
+	if err := s.checkNotTrashed(ctx, req.Email, req.Phone); err != nil {
+		return err
 	}
 
 	// Start building the query and arguments
-	// Always update username, email, role_id, and updated_at
-	query := "UPDATE users SET username = $1, email = $2, role_id = $3, updated_at = $4"
+	// Always update username, email, role_id, updated_at and bump the version.
+	// Changing the role or password also bumps the token version, so tokens issued before are
+	// rejected (see middleware.JWT). It is assigned first, as MySQL evaluates the assignments in
+	// order and would compare the new role_id otherwise.
+	tokenVersion := "token_version = token_version + CASE WHEN role_id = $3 THEN 0 ELSE 1 END"
+	if req.Password != nil && *req.Password != "" {
+		tokenVersion = "token_version = token_version + 1"
+	}
+	query := "UPDATE users SET " + tokenVersion + ", username = $1, email = $2, role_id = $3, updated_at = $4, version = version + 1"
 	args := []interface{}{
 		req.Username,
 		req.Email,
@@ -321,13 +704,31 @@ func (s *UserService) UpdateUser(req *models.UpdateUserRequest) error {
 		argCounter++
 	}
 
+	// An empty phone number removes it
+	if req.Phone != nil {
+		var phone *string
+		if *req.Phone != "" {
+			phone = req.Phone
+		}
+		query += fmt.Sprintf(", phone = $%d", argCounter)
+		args = append(args, phone)
+		argCounter++
+	}
+
 	// Add the WHERE clause
-	query += fmt.Sprintf(" WHERE id = $%d", argCounter)
-	args = append(args, req.ID)
+	query += fmt.Sprintf(" WHERE id = $%d AND tenant_id = $%d AND ", argCounter, argCounter+1) + database.NotDeleted("")
+	args = append(args, req.ID, tenancy.ID(ctx))
+	argCounter += 2
+
+	// Only apply the optimistic lock when the client sent the version it read
+	if req.Version != nil {
+		query += fmt.Sprintf(" AND version = $%d", argCounter)
+		args = append(args, *req.Version)
+	}
 
-	result, err := database.DB.Exec(query, args...)
+	result, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("Error updating user %s: %v", req.ID, err)
+		logging.FromContext(ctx).Error("Error updating user", "id", req.ID, "error", err)
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
@@ -336,40 +737,257 @@ func (s *UserService) UpdateUser(req *models.UpdateUserRequest) error {
 		return fmt.Errorf("failed to check rows affected after update: %w", err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("user with ID %s not found for update", req.ID)
+		if req.Version != nil {
+			exists, err := database.Exists(ctx, s.db, "users", req.ID)
+			if err != nil {
+				return fmt.Errorf("failed to check user existence: %w", err)
+			}
+			if exists {
+				return conflictf("user with ID %s was modified concurrently", req.ID)
+			}
+		}
+		return notFoundf("user with ID %s not found for update", req.ID)
 	}
 
+	after, err := s.GetUserByID(ctx, req.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching updated user for the audit log", "id", req.ID, "error", err)
+	}
+	auditChange(ctx, s.db, models.AuditActionUpdate, models.AuditEntityUser, req.ID, before, after)
 	return nil
 }
 
+// HashPassword hashes a password with the configured bcrypt cost (BCRYPT_COST).
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), config.AppConfig.BcryptCost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 	return string(bytes), nil
 }
 
-// DeleteUser deletes a user from the database by their ID.
-func (s *UserService) DeleteUser(id string) error {
-	if database.DB == nil {
-		return fmt.Errorf("database connection is not initialized")
+// DeleteUser moves a user to the trash by setting its deleted_at.
+// Trashed users can be restored or purged through the trash endpoints.
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	ctx = database.WithOperation(ctx, "users.delete")
+	before, err := s.GetUserByID(ctx, id) // Snapshot for the audit log
+	if err != nil {
+		return err
 	}
-
-	query := `DELETE FROM users WHERE id = $1`
-	result, err := database.DB.Exec(query, id)
+	deleted, err := database.SoftDelete(ctx, s.db, "users", id)
 	if err != nil {
-		log.Printf("Error deleting user by ID %s: %v", id, err)
+		logging.FromContext(ctx).Error("Error deleting user", "id", id, "error", err)
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
+	if !deleted {
+		return notFoundf("user with ID %s not found for deletion", id)
+	}
 
-	rowsAffected, err := result.RowsAffected()
+	auditChange(ctx, s.db, models.AuditActionDelete, models.AuditEntityUser, id, before, nil)
+	return nil
+}
+
+// SetAvatar sets the storage key of a user's avatar, or removes the avatar when key is nil.
+// It returns the key of the replaced avatar (nil if there was none), so the caller can delete
+// the file once the change is committed.
+func (s *UserService) SetAvatar(ctx context.Context, id string, key *string) (*string, error) {
+	ctx = database.WithOperation(ctx, "users.set_avatar")
+	var previous *string
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		err := tx.QueryRowContext(ctx, "SELECT avatar_key FROM users WHERE id = $1 AND tenant_id = $2 AND "+database.NotDeleted("")+tx.Dialect().ForUpdate(false), id, tenancy.ID(ctx)).Scan(&previous)
+		if err == sql.ErrNoRows {
+			return notFoundf("user with ID %s not found for avatar change", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read avatar of user: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET avatar_key = $1, updated_at = $2 WHERE id = $3", key, time.Now(), id); err != nil {
+			logging.FromContext(ctx).Error("Error changing avatar of user", "id", id, "error", err)
+			return fmt.Errorf("failed to change avatar of user: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+		return nil, err
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("user with ID %s not found for deletion", id)
+	return previous, nil
+}
+
+// GetUserTags lists the tags attached to users of the request's tenant, with the number of
+// users each one is attached to, most used first.
+func (s *UserService) GetUserTags(ctx context.Context) ([]models.UserTagCount, error) {
+	ctx = database.WithOperation(ctx, "users.list_tags")
+	query := `
+		SELECT ut.tag, COUNT(ut.user_id) AS user_count
+		FROM user_tags ut
+		JOIN users u ON u.id = ut.user_id AND u.tenant_id = $1 AND ` + database.NotDeleted("u") + `
+		GROUP BY ut.tag
+		ORDER BY user_count DESC, ut.tag ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, tenancy.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user tags: %w", err)
 	}
+	defer rows.Close()
 
+	tags := []models.UserTagCount{}
+	for rows.Next() {
+		var tag models.UserTagCount
+		if err := rows.Scan(&tag.Tag, &tag.UserCount); err != nil {
+			logging.FromContext(ctx).Error("Error scanning user tag row", "error", err)
+			return nil, fmt.Errorf("failed to scan user tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user tag rows: %w", err)
+	}
+	return tags, nil
+}
+
+// SetUserTags replaces the tags of a user of the request's tenant and returns them normalized.
+func (s *UserService) SetUserTags(ctx context.Context, id string, tags []string) ([]string, error) {
+	ctx = database.WithOperation(ctx, "users.set_tags")
+	tags = normalizeUserTags(tags)
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := checkUsers(ctx, tx, []string{id}); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_tags WHERE user_id = $1", id); err != nil {
+			return fmt.Errorf("failed to clear user tags: %w", err)
+		}
+		now := time.Now()
+		for _, tag := range tags {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO user_tags (user_id, tag, created_at) VALUES ($1, $2, $3)", id, tag, now); err != nil {
+				return fmt.Errorf("failed to tag user: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error setting user tags", "user_id", id, "error", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// TagUsers attaches the tags of add to, and detaches the tags of remove from, every user with
+// one of userIDs. All of them must be users of the request's tenant, or none are changed.
+func (s *UserService) TagUsers(ctx context.Context, userIDs []string, add, remove []string) error {
+	ctx = database.WithOperation(ctx, "users.tag")
+	add, remove = normalizeUserTags(add), normalizeUserTags(remove)
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		if err := checkUsers(ctx, tx, userIDs); err != nil {
+			return err
+		}
+		now := time.Now()
+		insert := tx.Dialect().InsertIgnore("INSERT INTO user_tags (user_id, tag, created_at) VALUES ($1, $2, $3)")
+		for _, userID := range userIDs {
+			for _, tag := range add {
+				if _, err := tx.ExecContext(ctx, insert, userID, tag, now); err != nil {
+					return fmt.Errorf("failed to tag user: %w", err)
+				}
+			}
+		}
+		if len(remove) > 0 {
+			args := make([]interface{}, 0, len(userIDs)+len(remove))
+			for _, userID := range userIDs {
+				args = append(args, userID)
+			}
+			for _, tag := range remove {
+				args = append(args, tag)
+			}
+			query := "DELETE FROM user_tags WHERE user_id IN (" + database.Placeholders(1, len(userIDs)) + ")" +
+				" AND tag IN (" + database.Placeholders(len(userIDs)+1, len(remove)) + ")"
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("failed to untag users: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error tagging users", "users", len(userIDs), "error", err)
+		return err
+	}
+	logging.FromContext(ctx).Info("Tagged users", "users", len(userIDs), "added", add, "removed", remove)
+	return nil
+}
+
+// loadUserTags fills in the Tags field of every user with a single query.
+func (s *UserService) loadUserTags(ctx context.Context, users []models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(users))
+	index := make(map[string]int, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+		index[user.ID] = i
+		users[i].Tags = []string{}
+	}
+
+	query := "SELECT user_id, tag FROM user_tags WHERE user_id IN (" + database.Placeholders(1, len(ids)) + ") ORDER BY tag ASC"
+	rows, err := s.db.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return fmt.Errorf("failed to query user tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, tag string
+		if err := rows.Scan(&userID, &tag); err != nil {
+			return fmt.Errorf("failed to scan user tag: %w", err)
+		}
+		if i, ok := index[userID]; ok {
+			users[i].Tags = append(users[i].Tags, tag)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating user tag rows: %w", err)
+	}
+	return nil
+}
+
+// rowQueryer runs a single-row query, on the connection pool or in a transaction.
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// checkUsers fails with ErrNotFound unless every one of ids is a user of the request's tenant
+// outside the trash.
+func checkUsers(ctx context.Context, db rowQueryer, ids []string) error {
+	args := []interface{}{tenancy.ID(ctx)}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	var found int
+	query := "SELECT COUNT(id) FROM users WHERE tenant_id = $1 AND " + database.NotDeleted("") + " AND id IN (" + database.Placeholders(2, len(ids)) + ")"
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&found); err != nil {
+		return fmt.Errorf("failed to check users: %w", err)
+	}
+	if found < len(ids) && len(ids) == 1 {
+		return notFoundf("user with ID %s not found", ids[0])
+	}
+	if found < len(ids) {
+		return notFoundf("%d of the users were not found", len(ids)-found)
+	}
 	return nil
 }
+
+// normalizeUserTags lowercases and trims tags, and drops empty and repeated ones.
+func normalizeUserTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/logging"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+	"github.com/anpsniper/anpbayu-be/webhooks"
+)
+
+// WebhookServiceInterface defines the methods that any webhook service implementation must provide.
+type WebhookServiceInterface interface {
+	GetWebhooks(ctx context.Context, page, limit int) ([]models.Webhook, int, int, error) // Returns webhooks, totalPages, totalItems
+	GetWebhookByID(ctx context.Context, id string) (*models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, webhookID, status string, page, limit int) ([]models.WebhookDelivery, int, int, error) // Returns deliveries, totalPages, totalItems
+	DeliverDue(ctx context.Context, sender WebhookSender, maxAttempts, limit int) (int, error)                                // Returns the number of deliveries attempted
+}
+
+// WebhookSender sends the requests of webhook deliveries, see webhooks.Sender.
+type WebhookSender interface {
+	Send(ctx context.Context, req webhooks.Request) (int, error)
+}
+
+// WebhookService manages the webhook endpoints of a tenant and delivers the events queued for
+// them (see enqueueWebhookEvent).
+type WebhookService struct {
+	db *database.DB // Database connection pool
+}
+
+// NewWebhookService creates and returns a new WebhookService instance using the given connection pool.
+func NewWebhookService(db *database.DB) *WebhookService {
+	return &WebhookService{db: db}
+}
+
+// Failed deliveries are retried after webhookRetryBase, doubled per failed attempt up to
+// webhookRetryMaxDelay. A claimed delivery whose instance stops before recording the
+// outcome is attempted again after webhookClaimLease, which exceeds the longest send.
+const (
+	webhookRetryBase     = 30 * time.Second
+	webhookRetryMaxDelay = 6 * time.Hour
+	webhookClaimLease    = 5 * time.Minute
+)
+
+const (
+	webhookColumns         = "id, tenant_id, url, events, active, created_at, updated_at"
+	webhookDeliveryColumns = "id, webhook_id, event, payload, status, attempts, next_attempt_at, response_status, last_error, created_at, delivered_at"
+)
+
+// GetWebhooks retrieves the webhooks of the request's tenant, newest first.
+func (s *WebhookService) GetWebhooks(ctx context.Context, page, limit int) ([]models.Webhook, int, int, error) {
+	webhookList := []models.Webhook{}
+	var totalItems int
+
+	tenantID := tenancy.ID(ctx)
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM webhooks WHERE tenant_id = $1", tenantID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+webhookColumns+" FROM webhooks WHERE tenant_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3",
+		tenantID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning webhook row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhookList = append(webhookList, *webhook)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating webhook rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return webhookList, totalPages, totalItems, nil
+}
+
+// GetWebhookByID fetches a webhook of the request's tenant by its ID. The secret is left out.
+func (s *WebhookService) GetWebhookByID(ctx context.Context, id string) (*models.Webhook, error) {
+	webhook, err := scanWebhook(s.db.QueryRowContext(ctx,
+		"SELECT "+webhookColumns+" FROM webhooks WHERE id = $1 AND tenant_id = $2", id, tenancy.ID(ctx),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil // Webhook not found
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error fetching webhook", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to fetch webhook by ID: %w", err)
+	}
+	return webhook, nil
+}
+
+// CreateWebhook registers an endpoint for the request's tenant with a new signing secret,
+// which is set on webhook so it can be shown to the admin once.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return err
+	}
+	webhook.ID = uuid.New().String()
+	webhook.TenantID = tenancy.ID(ctx)
+	webhook.Secret = secret
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = time.Now()
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO webhooks (id, tenant_id, url, secret, events, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		webhook.ID, webhook.TenantID, webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","), webhook.Active, webhook.CreatedAt, webhook.UpdatedAt,
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error creating webhook", "url", webhook.URL, "error", err)
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhook changes the URL, events and active flag of a webhook. Deliveries already
+// queued keep going to the endpoint's current URL.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE webhooks SET url = $1, events = $2, active = $3, updated_at = $4 WHERE id = $5 AND tenant_id = $6",
+		webhook.URL, strings.Join(webhook.Events, ","), webhook.Active, webhook.UpdatedAt, webhook.ID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error updating webhook", "webhook_id", webhook.ID, "error", err)
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("webhook with ID %s not found for update", webhook.ID)
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook along with its delivery history.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2", id, tenancy.ID(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Error deleting webhook", "id", id, "error", err)
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFoundf("webhook with ID %s not found for deletion", id)
+	}
+	return nil
+}
+
+// GetDeliveries retrieves the delivery history of a webhook of the request's tenant, newest
+// first, optionally only the deliveries with the given status.
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookID, status string, page, limit int) ([]models.WebhookDelivery, int, int, error) {
+	webhook, err := s.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if webhook == nil {
+		return nil, 0, 0, notFoundf("webhook with ID %s not found", webhookID)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	var totalItems int
+
+	where := " WHERE webhook_id = $1"
+	args := []interface{}{webhookID}
+	if status != "" {
+		where += " AND status = $2"
+		args = append(args, status)
+	}
+
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(id) FROM webhook_deliveries"+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error scanning webhook delivery row", "error", err)
+			return nil, 0, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("error iterating webhook delivery rows: %w", err)
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	return deliveries, totalPages, totalItems, nil
+}
+
+// DeliverDue attempts up to limit pending deliveries that are due, for all tenants, sending
+// them concurrently. Failed attempts are retried with exponential backoff until maxAttempts
+// have been made; then the delivery is marked failed. Deliveries are claimed before they are
+// sent, so instances running the job concurrently don't send the same delivery twice.
+func (s *WebhookService) DeliverDue(ctx context.Context, sender WebhookSender, maxAttempts, limit int) (int, error) {
+	claimed, err := s.claimDueDeliveries(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	errs := make([]error, len(claimed))
+	var wg sync.WaitGroup
+	for i := range claimed {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			errs[i] = s.attemptDelivery(ctx, sender, delivery, maxAttempts)
+		}(&claimed[i])
+	}
+	wg.Wait()
+	return len(claimed), errors.Join(errs...)
+}
+
+// attemptDelivery sends a claimed delivery to its endpoint and records the outcome.
+func (s *WebhookService) attemptDelivery(ctx context.Context, sender WebhookSender, delivery *models.WebhookDelivery, maxAttempts int) error {
+	var url, secret string
+	var active bool
+	err := s.db.QueryRowContext(ctx, "SELECT url, secret, active FROM webhooks WHERE id = $1", delivery.WebhookID).Scan(&url, &secret, &active)
+	if err == sql.ErrNoRows {
+		return nil // Deleted along with its deliveries in the meantime
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch webhook of delivery %s: %w", delivery.ID, err)
+	}
+
+	var status int
+	var sendErr error
+	if active {
+		status, sendErr = sender.Send(ctx, webhooks.Request{
+			URL:        url,
+			Secret:     secret,
+			Event:      delivery.Event,
+			DeliveryID: delivery.ID,
+			Payload:    delivery.Payload,
+		})
+	} else {
+		sendErr = errors.New("webhook was deactivated")
+	}
+	return s.recordAttempt(ctx, delivery, status, sendErr, active && delivery.Attempts+1 < maxAttempts)
+}
+
+// claimDueDeliveries selects the due pending deliveries and postpones them by
+// webhookClaimLease, so no other run picks them up while they are being sent.
+func (s *WebhookService) claimDueDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	claimed := []models.WebhookDelivery{}
+	err := database.WithTx(ctx, s.db, func(tx *database.Tx) error {
+		now := time.Now()
+		rows, err := tx.QueryContext(ctx,
+			"SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at ASC LIMIT $3"+tx.Dialect().ForUpdate(true),
+			models.WebhookDeliveryPending, now, limit,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to query due webhook deliveries: %w", err)
+		}
+		for rows.Next() {
+			delivery, err := scanWebhookDelivery(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan webhook delivery: %w", err)
+			}
+			claimed = append(claimed, *delivery)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating webhook delivery rows: %w", err)
+		}
+
+		for _, delivery := range claimed {
+			_, err := tx.ExecContext(ctx, "UPDATE webhook_deliveries SET next_attempt_at = $1 WHERE id = $2", now.Add(webhookClaimLease), delivery.ID)
+			if err != nil {
+				return fmt.Errorf("failed to claim webhook delivery: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// recordAttempt stores the outcome of an attempt to send delivery. If it failed, the next
+// attempt is scheduled when retry is true, and the delivery is marked failed otherwise.
+func (s *WebhookService) recordAttempt(ctx context.Context, delivery *models.WebhookDelivery, status int, sendErr error, retry bool) error {
+	delivery.Attempts++
+	delivery.ResponseStatus, delivery.LastError, delivery.NextAttemptAt = nil, nil, nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	now := time.Now()
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case !retry:
+		delivery.Status = models.WebhookDeliveryFailed
+	default:
+		next := now.Add(database.Backoff(delivery.Attempts-1, webhookRetryBase, webhookRetryMaxDelay))
+		delivery.NextAttemptAt = &next
+	}
+	if sendErr != nil {
+		message := sendErr.Error()
+		delivery.LastError = &message
+		logging.FromContext(ctx).Warn("Webhook delivery attempt failed",
+			"delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "event", delivery.Event, "attempt", delivery.Attempts, "error", sendErr)
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, response_status = $4, last_error = $5, delivered_at = $6
+		WHERE id = $7`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt, delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// webhookQueue runs the queries of enqueueWebhookEvent, on the connection pool or in the
+// transaction of the change that raised the event.
+type webhookQueue interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// enqueueWebhookEvent queues a delivery of event, with data as its payload, for every active
+// webhook of tenantID subscribed to it. The deliveries are sent by the webhook delivery job
+// (see WebhookService.DeliverDue). Call it within the transaction of the change, if any, so
+// events are only sent for changes that were committed.
+func enqueueWebhookEvent(ctx context.Context, db webhookQueue, tenantID, event string, data interface{}) error {
+	rows, err := db.QueryContext(ctx, "SELECT id, events FROM webhooks WHERE tenant_id = $1 AND active = $2", tenantID, true)
+	if err != nil {
+		return fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	var webhookIDs []string
+	for rows.Next() {
+		var id, events string
+		if err := rows.Scan(&id, &events); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		for _, subscribed := range splitWebhookEvents(events) {
+			if subscribed == event {
+				webhookIDs = append(webhookIDs, id)
+				break
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating webhook rows: %w", err)
+	}
+	if len(webhookIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(struct {
+		Event     string      `json:"event"`
+		CreatedAt time.Time   `json:"created_at"`
+		Data      interface{} `json:"data"`
+	}{event, now, data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	for _, webhookID := range webhookIDs {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (id, webhook_id, event, payload, status, attempts, next_attempt_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, 0, $6, $7)`,
+			uuid.New().String(), webhookID, event, string(payload), models.WebhookDeliveryPending, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// notifyWebhooks queues event for a change that has already been made outside a transaction.
+// A failure can't undo the change, so it is logged instead of returned.
+func notifyWebhooks(ctx context.Context, db webhookQueue, tenantID, event string, data interface{}) {
+	if err := enqueueWebhookEvent(ctx, db, tenantID, event, data); err != nil {
+		logging.FromContext(ctx).Error("Error queuing webhook event", "event", event, "error", err)
+	}
+}
+
+// scanWebhook scans a webhook row selected with webhookColumns.
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events string
+	err := row.Scan(&webhook.ID, &webhook.TenantID, &webhook.URL, &events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Events = splitWebhookEvents(events)
+	return webhook, nil
+}
+
+// scanWebhookDelivery scans a delivery row selected with webhookDeliveryColumns.
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var payload string
+	var nextAttemptAt, deliveredAt sql.NullTime
+	var responseStatus sql.NullInt64
+	var lastError sql.NullString
+	err := row.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.Event, &payload, &delivery.Status, &delivery.Attempts,
+		&nextAttemptAt, &responseStatus, &lastError, &delivery.CreatedAt, &deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = json.RawMessage(payload)
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	if lastError.Valid {
+		delivery.LastError = &lastError.String
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, nil
+}
+
+// splitWebhookEvents parses the comma-separated events column.
+func splitWebhookEvents(events string) []string {
+	list := []string{}
+	for _, event := range strings.Split(events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			list = append(list, event)
+		}
+	}
+	return list
+}
@@ -0,0 +1,20 @@
+// Package sms sends text messages, such as the one-time codes of the SMS login, through an
+// SMS gateway. Twilio is the only gateway implemented; others only need to implement Sender.
+package sms
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Sender is implemented by every SMS gateway.
+type Sender interface {
+	// Name returns the identifier of the gateway (e.g., "twilio"), used in logs.
+	Name() string
+	// Send delivers body to the phone number to, in E.164 format (e.g., "+6281234567890").
+	Send(ctx context.Context, to, body string) error
+}
+
+// httpClient is shared by the sender implementations.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
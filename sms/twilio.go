@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TwilioSender sends messages with the Twilio Messaging API (https://www.twilio.com/docs/messaging/api/message-resource).
+type TwilioSender struct {
+	AccountSID string // Account SID (AC...)
+	AuthToken  string // Auth token of the account
+	From       string // Twilio phone number messages are sent from, in E.164 format
+	BaseURL    string // API base URL, overridable for tests
+}
+
+// NewTwilioSender creates a TwilioSender, or returns nil when no account is configured.
+func NewTwilioSender(accountSID, authToken, from string) Sender {
+	if accountSID == "" {
+		return nil
+	}
+	return &TwilioSender{
+		AccountSID: accountSID,
+		AuthToken:  authToken,
+		From:       from,
+		BaseURL:    "https://api.twilio.com",
+	}
+}
+
+// Name returns "twilio".
+func (s *TwilioSender) Name() string { return "twilio" }
+
+// Send creates a message resource, which Twilio queues for delivery.
+func (s *TwilioSender) Send(ctx context.Context, to, body string) error {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", s.From)
+	form.Set("Body", body)
+
+	endpoint := s.BaseURL + "/2010-04-01/Accounts/" + url.PathEscape(s.AccountSID) + "/Messages.json"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build twilio request: %w", err)
+	}
+	httpReq.SetBasicAuth(s.AccountSID, s.AuthToken)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to call twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("twilio returned %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unsignedPayload is used as the payload hash of requests whose body isn't signed: uploads
+// are streamed rather than hashed up front, and presigned URLs carry no body.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Options configures an S3Storage.
+type S3Options struct {
+	Endpoint        string        // e.g., "https://s3.eu-west-1.amazonaws.com" or "http://localhost:9000" for MinIO; AWS S3 of Region if empty
+	Region          string        // e.g., "us-east-1"
+	Bucket          string        // Bucket the files are stored in
+	AccessKeyID     string        // Access key of the credentials requests are signed with
+	SecretAccessKey string        // Secret key of the credentials requests are signed with
+	PathStyle       bool          // Address the bucket in the path ("host/bucket/key") rather than the host ("bucket.host/key"), as MinIO needs
+	URLTTL          time.Duration // How long presigned URLs stay valid at least
+	Timeout         time.Duration // Timeout of a request to the S3 API
+}
+
+// S3Storage stores files in a bucket of Amazon S3 or an S3 compatible service such as MinIO.
+// Requests are signed with AWS Signature Version 4; files are downloaded from presigned URLs.
+type S3Storage struct {
+	options  S3Options
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Storage creates and returns a new S3Storage instance.
+func NewS3Storage(options S3Options) (*S3Storage, error) {
+	if options.Endpoint == "" {
+		options.Endpoint = "https://s3." + options.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", options.Endpoint)
+	}
+	if max := 7 * 24 * time.Hour; 2*options.URLTTL > max {
+		options.URLTTL = max / 2 // S3 doesn't accept presigned URLs valid for longer than a week
+	}
+	return &S3Storage{
+		options:  options,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: options.Timeout},
+	}, nil
+}
+
+// Save uploads the content of r to the object key.
+func (s *S3Storage) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload file %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open downloads the object key.
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object key. S3 answers deletions of missing objects with success too.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// URL returns a presigned GET URL of the object key.
+func (s *S3Storage) URL(key string) string {
+	signedAt := signingWindow(time.Now(), s.options.URLTTL)
+	u := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.options.AccessKeyID+"/"+s.scope(signedAt))
+	query.Set("X-Amz-Date", signedAt.UTC().Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(2*s.options.URLTTL/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	u.RawQuery = canonicalQuery(query)
+
+	signature := s.signature(signedAt, http.MethodGet, u, map[string]string{"host": u.Host}, unsignedPayload)
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String()
+}
+
+// newRequest returns a request of method for the object key, to be signed by do.
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if key == "" || strings.Contains(key, "..") {
+		return nil, fmt.Errorf("invalid storage key: %q", key)
+	}
+	return http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+}
+
+// do signs req with the Authorization header and sends it. Responses other than 2xx are
+// returned as errors, with the message of S3's error document.
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	now := time.Now()
+	req.Header.Set("X-Amz-Date", now.UTC().Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	signature := s.signature(now, req.Method, req.URL, headers, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.options.AccessKeyID, s.scope(now), signedHeaders(headers), signature))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("S3 answered %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of the object key, with the bucket in the host or the path.
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.options.PathStyle {
+		base += "/" + s.options.Bucket
+	} else {
+		u.Host = s.options.Bucket + "." + u.Host
+	}
+	u.Path = base + "/" + key
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// scope returns the credential scope of requests signed at t.
+func (s *S3Storage) scope(t time.Time) string {
+	return t.UTC().Format("20060102") + "/" + s.options.Region + "/s3/aws4_request"
+}
+
+// signature returns the Signature Version 4 signature of a request signed at t, with the
+// given canonical (lowercase) headers and payload hash. The query of u must already be
+// canonical, see canonicalQuery.
+func (s *S3Storage) signature(t time.Time, method string, u *url.URL, headers map[string]string, payloadHash string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		u.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders(headers),
+		payloadHash,
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.UTC().Format("20060102T150405Z"),
+		s.scope(t),
+		hex.EncodeToString(hashed[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretAccessKey), t.UTC().Format("20060102"))
+	key = hmacSHA256(key, s.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// signedHeaders returns the sorted, semicolon separated names of headers.
+func signedHeaders(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ";")
+}
+
+// canonicalQuery encodes query sorted by name, with names and values encoded as
+// Signature Version 4 requires.
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte of s but the unreserved characters, and slashes
+// unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Storage abstracts where uploaded files are kept, so the rest of the application
+// only deals with opaque keys (e.g., "posts/<post-id>/<uuid>.png").
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error // Save writes size bytes of r under key, replacing any existing file
+	Open(ctx context.Context, key string) (io.ReadCloser, error)                             // Open returns a reader for the file stored under key
+	Delete(ctx context.Context, key string) error                                            // Delete removes the file stored under key; missing files are not an error
+	URL(key string) string                                                                   // URL returns a presigned URL the file can be downloaded from for a limited time
+}
+
+// signingWindow returns the time presigned URLs created at now are signed at. URLs are
+// signed at the start of their TTL window and stay valid for two windows, so the same file
+// gets the same URL for a while (keeping the ETags of responses listing it stable) and every
+// URL is valid for at least ttl after it was handed out.
+func signingWindow(now time.Time, ttl time.Duration) time.Time {
+	return now.Truncate(ttl)
+}
+
+// LocalStorage stores files on the local filesystem below BaseDir. They are served under
+// BaseURL by the application, which checks the signature of their URLs with Verify.
+type LocalStorage struct {
+	BaseDir    string        // Directory the files are written to (e.g., "./uploads")
+	BaseURL    string        // URL prefix the directory is served under (e.g., "/uploads")
+	URLTTL     time.Duration // How long presigned URLs stay valid at least
+	signingKey []byte
+}
+
+// NewLocalStorage creates and returns a new LocalStorage instance, whose download URLs are
+// signed with signingKey and valid for at least urlTTL.
+// The base directory is created on demand when the first file is saved.
+func NewLocalStorage(baseDir, baseURL, signingKey string, urlTTL time.Duration) *LocalStorage {
+	return &LocalStorage{
+		BaseDir:    baseDir,
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		URLTTL:     urlTTL,
+		signingKey: []byte(signingKey),
+	}
+}
+
+// Save writes the content of r to BaseDir/key.
+func (s *LocalStorage) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	f, err := os.Create(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", key, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(fullPath) // Don't leave partially written files behind
+		return fmt.Errorf("failed to write file %s: %w", key, err)
+	}
+	return nil
+}
+
+// Open returns a reader for BaseDir/key.
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete removes BaseDir/key.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file %s: %w", key, err)
+	}
+	return nil
+}
+
+// URL returns BaseURL/key with the expiry and signature Verify checks,
+// e.g., "/uploads/posts/1/2.png?expires=1700000000&signature=...".
+func (s *LocalStorage) URL(key string) string {
+	expires := signingWindow(time.Now(), s.URLTTL).Add(2 * s.URLTTL).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(key, expires))
+	return s.BaseURL + "/" + key + "?" + query.Encode()
+}
+
+// Verify reports whether expires and signature are those of an unexpired URL of key.
+func (s *LocalStorage) Verify(key, expires, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expiresAt)))
+}
+
+// sign returns the hex HMAC-SHA256 of key and its expiry.
+func (s *LocalStorage) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path resolves a key to a filesystem path, rejecting keys that would escape BaseDir.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return filepath.Join(s.BaseDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// superuserRole is the role given to accounts created by create-superuser.
+const superuserRole = "admin"
+
+// superuser is the account create-superuser provisions, with the rules of CreateUserRequest.
+type superuser struct {
+	Username string `validate:"required,max=100"`
+	Email    string `validate:"required,email,max=255"`
+	Password string `validate:"required,min=8,max=72"` // bcrypt ignores everything after 72 bytes
+}
+
+func createSuperuserCommand() *cobra.Command {
+	var account superuser
+	var passwordStdin bool
+	cmd := &cobra.Command{
+		Use:   "create-superuser",
+		Short: "Create an account with the admin role",
+		Long: `Create an account with the admin role.
+
+Values not given as flags are asked for when run in a terminal, the password without echoing
+it. In scripts, pass the password on standard input with --password-stdin rather than with
+--password, which other processes can see.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if passwordStdin {
+				if account.Password != "" {
+					return errors.New("--password and --password-stdin are mutually exclusive")
+				}
+				password, err := io.ReadAll(io.LimitReader(os.Stdin, 1<<10))
+				if err != nil {
+					return fmt.Errorf("failed to read password from standard input: %w", err)
+				}
+				account.Password = strings.TrimRight(string(password), "\r\n")
+			}
+			if err := promptSuperuser(&account); err != nil {
+				return err
+			}
+			if err := validator.New().Struct(account); err != nil {
+				return fmt.Errorf("invalid account: %w", err)
+			}
+			return withDatabase(func(db *database.DB) error {
+				return createSuperuser(context.Background(), db, account)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&account.Username, "username", "", "username of the account")
+	cmd.Flags().StringVar(&account.Email, "email", "", "email the account signs in with")
+	cmd.Flags().StringVar(&account.Password, "password", "", "password of the account (at least 8 characters)")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the password from standard input")
+	return cmd
+}
+
+// promptSuperuser asks for the fields of account that are still empty, when stdin is a terminal.
+func promptSuperuser(account *superuser) error {
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	missing := func(flag string) error {
+		return fmt.Errorf("--%s is required when not run in a terminal", flag)
+	}
+	reader := bufio.NewReader(os.Stdin)
+
+	if account.Username == "" {
+		if !interactive {
+			return missing("username")
+		}
+		account.Username = promptLine(reader, "Username: ")
+	}
+	if account.Email == "" {
+		if !interactive {
+			return missing("email")
+		}
+		account.Email = promptLine(reader, "Email: ")
+	}
+	if account.Password == "" {
+		if !interactive {
+			return missing("password-stdin")
+		}
+		password, err := promptPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		confirmation, err := promptPassword("Password (again): ")
+		if err != nil {
+			return err
+		}
+		if password != confirmation {
+			return errors.New("the passwords don't match")
+		}
+		account.Password = password
+	}
+	return nil
+}
+
+func promptLine(reader *bufio.Reader, prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	line, _ := reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+func promptPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// createSuperuser inserts account with the admin role, which must have been seeded already.
+func createSuperuser(ctx context.Context, db *database.DB, account superuser) error {
+	role, err := services.NewRoleService(db).GetRoleByName(ctx, superuserRole)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return fmt.Errorf("role %q not found; run the seed command first", superuserRole)
+	}
+
+	userService := services.NewUserService(db, services.NewRoleCache(db, 0))
+	existing, err := userService.GetUserByEmail(ctx, account.Email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("a user with email %s already exists", account.Email)
+	}
+
+	hashedPassword, err := services.HashPassword(account.Password)
+	if err != nil {
+		return err
+	}
+	user := models.NewUser(account.Username, account.Email, hashedPassword, role.ID)
+	if err := userService.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	slog.Info("Admin account created", "user_id", user.ID, "email", user.Email)
+	return nil
+}
@@ -0,0 +1,38 @@
+// Package tenancy carries the tenant a request acts for through its context.
+// Services scope every query on tenant-owned tables (see database.TenantTables) to ID(ctx).
+package tenancy
+
+import (
+	"context"
+)
+
+// DefaultTenantID is the tenant of requests that don't resolve to a specific tenant.
+// Rows that existed before multi-tenancy was introduced belong to it.
+const DefaultTenantID = "00000000-0000-0000-0000-000000000001"
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx that acts for the tenant with the given ID.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant ctx was explicitly resolved to, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// ID returns the tenant ctx acts for, falling back to DefaultTenantID.
+func ID(ctx context.Context) string {
+	if tenantID, ok := FromContext(ctx); ok {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+// IsPlatform reports whether ctx acts for the default tenant, which runs the platform: only its
+// users may change what is shared by every tenant, such as roles and settings.
+func IsPlatform(ctx context.Context) bool {
+	return ID(ctx) == DefaultTenantID
+}
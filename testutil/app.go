@@ -0,0 +1,74 @@
+// Package testutil helps writing tests of the controllers: mocks of the service interfaces,
+// a Fiber app set up like the server's, tokens for authenticated requests, and helpers to
+// send requests and check their responses.
+package testutil
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/controllers"
+	"github.com/anpsniper/anpbayu-be/middleware"
+	"github.com/anpsniper/anpbayu-be/tenancy"
+)
+
+// JWTSecret signs the tokens of Token, unless JWT_SECRET is set.
+const JWTSecret = "testutil-jwt-secret"
+
+var configOnce sync.Once
+var configErr error
+
+// NewApp returns a Fiber app set up like the server's (see server.New): errors are answered by
+// controllers.ErrorHandler, requests get an ID and a logger, panics are recovered and messages
+// are localized. Routes registered after RequireJWT need a token from Token. The application
+// configuration is loaded with its defaults, once per test binary.
+func NewApp(t testing.TB) *fiber.App {
+	t.Helper()
+	loadConfig(t)
+	app := fiber.New(fiber.Config{ErrorHandler: controllers.ErrorHandler})
+	app.Use(middleware.RequestLogger())
+	app.Use(middleware.Recover())
+	app.Use(middleware.Localize())
+	return app
+}
+
+// loadConfig loads the application configuration with its defaults, once per test binary.
+func loadConfig(t testing.TB) {
+	t.Helper()
+	configOnce.Do(func() {
+		if configErr = config.LoadConfig(); configErr == nil && config.AppConfig.JWTSecret == "" {
+			config.AppConfig.JWTSecret = JWTSecret
+		}
+	})
+	if configErr != nil {
+		t.Fatalf("testutil: failed to load configuration: %v", configErr)
+	}
+}
+
+// RequireJWT makes the routes registered with app from here on require a token, as the routes
+// under /api do in the server. Sessions aren't checked, so tokens of Token are accepted.
+func RequireJWT(app *fiber.App) {
+	app.Use(middleware.JWT(nil))
+	app.Use(middleware.ResolveTenantFromJWT())
+}
+
+// Token returns a bearer token of the user userID with roles, in the default tenant. Send it
+// with Request.Token.
+func Token(t testing.TB, userID string, roles ...string) string {
+	t.Helper()
+	token, err := middleware.GenerateJWT(context.Background(), middleware.TokenSubject{
+		UserID:       userID,
+		TenantID:     tenancy.DefaultTenantID,
+		Email:        userID + "@example.com",
+		Roles:        roles,
+		TokenVersion: 1,
+	})
+	if err != nil {
+		t.Fatalf("testutil: failed to generate token: %v", err)
+	}
+	return token
+}
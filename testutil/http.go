@@ -0,0 +1,135 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/openapi"
+)
+
+// Request is a request sent to an app by Do.
+type Request struct {
+	Method  string
+	Path    string            // e.g., "/api/users?page=2"
+	Body    interface{}       // Encoded as JSON unless it is a []byte or string; no body if nil
+	Token   string            // Sent as bearer token if set, see Token
+	Headers map[string]string // Further headers, e.g., Accept-Language
+}
+
+// Response is the response of an app to a Request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Envelope is the JSON body of the responses of the controllers.
+type Envelope struct {
+	Success     bool                 `json:"success"`
+	Message     string               `json:"message"`
+	Data        json.RawMessage      `json:"data"`
+	Errors      []openapi.FieldError `json:"errors"` // Fields rejected by validation (422)
+	CurrentPage int                  `json:"currentPage"`
+	TotalPages  int                  `json:"totalPages"`
+	TotalItems  int                  `json:"totalItems"`
+}
+
+// Do sends req to app and returns its response.
+func Do(t testing.TB, app *fiber.App, req Request) *Response {
+	t.Helper()
+	var body io.Reader
+	contentType := ""
+	switch b := req.Body.(type) {
+	case nil:
+	case []byte:
+		body = bytes.NewReader(b)
+	case string:
+		body = bytes.NewBufferString(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("testutil: failed to encode request body: %v", err)
+		}
+		body = bytes.NewReader(data)
+		contentType = fiber.MIMEApplicationJSON
+	}
+
+	httpReq := httptest.NewRequest(req.Method, req.Path, body)
+	if contentType != "" {
+		httpReq.Header.Set(fiber.HeaderContentType, contentType)
+	}
+	if req.Token != "" {
+		httpReq.Header.Set(fiber.HeaderAuthorization, "Bearer "+req.Token)
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp, err := app.Test(httpReq, -1) // No timeout, so breakpoints don't fail the test
+	if err != nil {
+		t.Fatalf("testutil: %s %s failed: %v", req.Method, req.Path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("testutil: failed to read response body: %v", err)
+	}
+	return &Response{Status: resp.StatusCode, Header: resp.Header, Body: data}
+}
+
+// AssertStatus fails the test unless the response has status want.
+func (r *Response) AssertStatus(t testing.TB, want int) *Response {
+	t.Helper()
+	if r.Status != want {
+		t.Fatalf("status = %d, want %d; body: %s", r.Status, want, r.Body)
+	}
+	return r
+}
+
+// Decode decodes the JSON body of the response into v.
+func (r *Response) Decode(t testing.TB, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("testutil: failed to decode response body %s: %v", r.Body, err)
+	}
+}
+
+// Envelope decodes the body of the response as the envelope of the controllers.
+func (r *Response) Envelope(t testing.TB) Envelope {
+	t.Helper()
+	var envelope Envelope
+	r.Decode(t, &envelope)
+	return envelope
+}
+
+// Data decodes the "data" field of the envelope of the response into v and fails the test
+// unless the response is successful.
+func (r *Response) Data(t testing.TB, v interface{}) {
+	t.Helper()
+	envelope := r.Envelope(t)
+	if !envelope.Success {
+		t.Fatalf("response isn't successful: %s", r.Body)
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		t.Fatalf("testutil: failed to decode data %s: %v", envelope.Data, err)
+	}
+}
+
+// AssertFieldError fails the test unless the response is a 422 rejecting field with code,
+// e.g., AssertFieldError(t, "email", "required").
+func (r *Response) AssertFieldError(t testing.TB, field, code string) {
+	t.Helper()
+	r.AssertStatus(t, http.StatusUnprocessableEntity)
+	for _, fieldErr := range r.Envelope(t).Errors {
+		if fieldErr.Field == field && fieldErr.Code == code {
+			return
+		}
+	}
+	t.Fatalf("no %q error of field %q in %s", code, field, r.Body)
+}
@@ -0,0 +1,164 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/anpsniper/anpbayu-be/config"
+	"github.com/anpsniper/anpbayu-be/database"
+	"github.com/anpsniper/anpbayu-be/realtime"
+	"github.com/anpsniper/anpbayu-be/seeds"
+	"github.com/anpsniper/anpbayu-be/server"
+)
+
+// PostgresImage is the image of the containers started by StartPostgres.
+const PostgresImage = "postgres:16-alpine"
+
+// StartPostgres starts a throwaway Postgres container with the Docker CLI and returns its
+// connection URL once it accepts connections. The container is removed when the test ends.
+// The test is skipped when Docker, or its daemon, isn't available. With TEST_DATABASE_URL set, that database
+// is returned instead and nothing is started, e.g., for a Postgres service of the CI.
+func StartPostgres(t testing.TB) string {
+	t.Helper()
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		return dsn
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("testutil: docker not found, set TEST_DATABASE_URL to run against an existing database")
+	}
+	if err := exec.Command("docker", "info").Run(); err != nil {
+		t.Skip("testutil: docker daemon not reachable, set TEST_DATABASE_URL to run against an existing database")
+	}
+
+	// Publish the port on a random free host port, which docker port looks up
+	out, err := exec.Command("docker", "run", "--detach", "--rm",
+		"--env", "POSTGRES_USER=postgres", "--env", "POSTGRES_PASSWORD=postgres", "--env", "POSTGRES_DB=anpbayu_test",
+		"--publish", "127.0.0.1::5432", PostgresImage).Output()
+	if err != nil {
+		t.Fatalf("testutil: failed to start %s: %v", PostgresImage, commandError(err))
+	}
+	containerID := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		_ = exec.Command("docker", "rm", "--force", "--volumes", containerID).Run()
+	})
+
+	out, err = exec.Command("docker", "port", containerID, "5432/tcp").Output()
+	if err != nil {
+		t.Fatalf("testutil: failed to look up the Postgres port: %v", commandError(err))
+	}
+	hostPort := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	dsn := fmt.Sprintf("postgres://postgres:postgres@%s/anpbayu_test?sslmode=disable", hostPort)
+	waitForPostgres(t, dsn, time.Minute)
+	return dsn
+}
+
+// waitForPostgres waits until the database of dsn answers a ping, failing the test after timeout.
+// The image's entrypoint only listens on TCP once the database is initialized.
+func waitForPostgres(t testing.TB, dsn string, timeout time.Duration) {
+	t.Helper()
+	dialect, err := database.DialectFor("postgres")
+	if err != nil {
+		t.Fatalf("testutil: %v", err)
+	}
+	db, err := sql.Open(dialect.DriverName(), dsn)
+	if err != nil {
+		t.Fatalf("testutil: failed to open %s: %v", dsn, err)
+	}
+	defer db.Close()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("testutil: Postgres didn't come up within %s: %v", timeout, err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// commandError adds the stderr output of a failed command to err.
+func commandError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// Integration is the application served against a real database, for black-box tests of the
+// API, see NewIntegration.
+type Integration struct {
+	App *fiber.App
+	DB  *database.DB
+}
+
+// NewIntegration starts Postgres (see StartPostgres), applies the migrations, seeds the fixtures
+// of env (e.g., "development", see seeds) and returns the app the server serves, with every
+// middleware and route:
+//
+//	it := testutil.NewIntegration(t, "development")
+//	token := it.Login(t, "admin@example.com", "password123")
+//	testutil.Do(t, it.App, testutil.Request{Method: "GET", Path: "/api/users", Token: token}).
+//		AssertStatus(t, http.StatusOK)
+//
+// The database and uploads directory are set in config.AppConfig, so tests using it must not
+// run in parallel. Everything is closed when the test ends.
+func NewIntegration(t testing.TB, env string) *Integration {
+	t.Helper()
+	loadConfig(t)
+	dsn := StartPostgres(t)
+
+	config.AppConfig.DBDialect = "postgres"
+	config.AppConfig.DBURL = dsn
+	config.AppConfig.UploadDir = t.TempDir()
+	db, pool, err := database.InitDatabase(&config.AppConfig)
+	if err != nil {
+		t.Fatalf("testutil: failed to set up database: %v", err)
+	}
+	t.Cleanup(func() { database.CloseDatabase(db, pool) })
+
+	if err := seeds.Run(context.Background(), db, env, config.AppConfig.SeedDir); err != nil {
+		t.Fatalf("testutil: failed to seed fixtures of %s: %v", env, err)
+	}
+
+	hub := realtime.NewHub()
+	sched, err := server.NewScheduler(db, hub)
+	if err != nil {
+		t.Fatalf("testutil: failed to schedule tasks: %v", err)
+	}
+	app, redisStore, err := server.New(db, pool, sched, hub)
+	if err != nil {
+		t.Fatalf("testutil: failed to build app: %v", err)
+	}
+	if redisStore != nil {
+		t.Cleanup(func() { redisStore.Close() })
+	}
+	return &Integration{App: app, DB: db}
+}
+
+// Login logs in with email and password through POST /login and returns the token to send with
+// Request.Token, failing the test unless the login succeeds.
+func (it *Integration) Login(t testing.TB, email, password string) string {
+	t.Helper()
+	var login struct {
+		Token string `json:"token"`
+	}
+	Do(t, it.App, Request{
+		Method: fiber.MethodPost,
+		Path:   "/login",
+		Body:   map[string]string{"email": email, "password": password},
+	}).AssertStatus(t, fiber.StatusOK).Decode(t, &login)
+	return login.Token
+}
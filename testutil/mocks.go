@@ -0,0 +1,346 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/anpsniper/anpbayu-be/models"
+	"github.com/anpsniper/anpbayu-be/services"
+)
+
+// Compile-time checks that the mocks implement the service interfaces
+var (
+	_ services.UserServiceInterface = (*UserServiceMock)(nil)
+	_ services.RoleServiceInterface = (*RoleServiceMock)(nil)
+)
+
+// calls counts the calls of the methods of a mock.
+type calls struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *calls) record(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	c.counts[method]++
+}
+
+// Calls returns how often method (e.g., "GetUserByID") was called.
+func (c *calls) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[method]
+}
+
+// UserServiceMock implements services.UserServiceInterface with the functions set in its
+// fields, e.g.:
+//
+//	users := &testutil.UserServiceMock{
+//		GetUserByIDFunc: func(ctx context.Context, id string) (*models.User, error) {
+//			return &models.User{ID: id}, nil
+//		},
+//	}
+//
+// Calling a method whose function isn't set panics, so tests notice unexpected calls.
+type UserServiceMock struct {
+	calls
+	GetAllUsersFunc           func(ctx context.Context, search string, roleID string, tag string, page, limit int) ([]models.User, int, int, error)
+	GetUserByIDFunc           func(ctx context.Context, id string) (*models.User, error)
+	GetUserByEmailFunc        func(ctx context.Context, email string) (*models.User, error)
+	GetUserByPhoneFunc        func(ctx context.Context, phone string) (*models.User, error)
+	CreateUserFunc            func(ctx context.Context, user *models.User) error
+	UpdateUserFunc            func(ctx context.Context, req *models.UpdateUserRequest) error
+	DeleteUserFunc            func(ctx context.Context, id string) error
+	SetAvatarFunc             func(ctx context.Context, id string, key *string) (*string, error)
+	GetAllRolesFunc           func(ctx context.Context) ([]models.LstRole, error)
+	CreateLoginSessionFunc    func(ctx context.Context, session *models.Session) (int, error)
+	UpdateUserLogoutLogFunc   func(ctx context.Context, userID string, logID int) error
+	GetUserLogsFunc           func(ctx context.Context, filter models.UserLogFilter, page, limit int) ([]models.UserLog, int, int, error)
+	GetUserLogsAfterFunc      func(ctx context.Context, filter models.UserLogFilter, after *models.Cursor, limit int) ([]models.UserLog, *models.Cursor, error)
+	ExportUserLogsFunc        func(ctx context.Context, filter models.UserLogFilter, fn func(*models.UserLog) error) error
+	ExportUsersFunc           func(ctx context.Context, search string, roleID string, tag string, fn func(*models.User) error) error
+	GetUserProfileFunc        func(ctx context.Context, id string) (*models.UserProfile, error)
+	UpdateUserPreferencesFunc func(ctx context.Context, id string, preferences json.RawMessage) error
+	GetUserTagsFunc           func(ctx context.Context) ([]models.UserTagCount, error)
+	SetUserTagsFunc           func(ctx context.Context, id string, tags []string) ([]string, error)
+	TagUsersFunc              func(ctx context.Context, userIDs []string, add, remove []string) error
+}
+
+func (m *UserServiceMock) GetAllUsers(ctx context.Context, search string, roleID string, tag string, page, limit int) ([]models.User, int, int, error) {
+	m.record("GetAllUsers")
+	if m.GetAllUsersFunc == nil {
+		panic("UserServiceMock.GetAllUsers called but GetAllUsersFunc is not set")
+	}
+	return m.GetAllUsersFunc(ctx, search, roleID, tag, page, limit)
+}
+
+func (m *UserServiceMock) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	m.record("GetUserByID")
+	if m.GetUserByIDFunc == nil {
+		panic("UserServiceMock.GetUserByID called but GetUserByIDFunc is not set")
+	}
+	return m.GetUserByIDFunc(ctx, id)
+}
+
+func (m *UserServiceMock) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.record("GetUserByEmail")
+	if m.GetUserByEmailFunc == nil {
+		panic("UserServiceMock.GetUserByEmail called but GetUserByEmailFunc is not set")
+	}
+	return m.GetUserByEmailFunc(ctx, email)
+}
+
+func (m *UserServiceMock) GetUserByPhone(ctx context.Context, phone string) (*models.User, error) {
+	m.record("GetUserByPhone")
+	if m.GetUserByPhoneFunc == nil {
+		panic("UserServiceMock.GetUserByPhone called but GetUserByPhoneFunc is not set")
+	}
+	return m.GetUserByPhoneFunc(ctx, phone)
+}
+
+func (m *UserServiceMock) CreateUser(ctx context.Context, user *models.User) error {
+	m.record("CreateUser")
+	if m.CreateUserFunc == nil {
+		panic("UserServiceMock.CreateUser called but CreateUserFunc is not set")
+	}
+	return m.CreateUserFunc(ctx, user)
+}
+
+func (m *UserServiceMock) UpdateUser(ctx context.Context, req *models.UpdateUserRequest) error {
+	m.record("UpdateUser")
+	if m.UpdateUserFunc == nil {
+		panic("UserServiceMock.UpdateUser called but UpdateUserFunc is not set")
+	}
+	return m.UpdateUserFunc(ctx, req)
+}
+
+func (m *UserServiceMock) DeleteUser(ctx context.Context, id string) error {
+	m.record("DeleteUser")
+	if m.DeleteUserFunc == nil {
+		panic("UserServiceMock.DeleteUser called but DeleteUserFunc is not set")
+	}
+	return m.DeleteUserFunc(ctx, id)
+}
+
+func (m *UserServiceMock) SetAvatar(ctx context.Context, id string, key *string) (*string, error) {
+	m.record("SetAvatar")
+	if m.SetAvatarFunc == nil {
+		panic("UserServiceMock.SetAvatar called but SetAvatarFunc is not set")
+	}
+	return m.SetAvatarFunc(ctx, id, key)
+}
+
+func (m *UserServiceMock) GetAllRoles(ctx context.Context) ([]models.LstRole, error) {
+	m.record("GetAllRoles")
+	if m.GetAllRolesFunc == nil {
+		panic("UserServiceMock.GetAllRoles called but GetAllRolesFunc is not set")
+	}
+	return m.GetAllRolesFunc(ctx)
+}
+
+func (m *UserServiceMock) CreateLoginSession(ctx context.Context, session *models.Session) (int, error) {
+	m.record("CreateLoginSession")
+	if m.CreateLoginSessionFunc == nil {
+		panic("UserServiceMock.CreateLoginSession called but CreateLoginSessionFunc is not set")
+	}
+	return m.CreateLoginSessionFunc(ctx, session)
+}
+
+func (m *UserServiceMock) UpdateUserLogoutLog(ctx context.Context, userID string, logID int) error {
+	m.record("UpdateUserLogoutLog")
+	if m.UpdateUserLogoutLogFunc == nil {
+		panic("UserServiceMock.UpdateUserLogoutLog called but UpdateUserLogoutLogFunc is not set")
+	}
+	return m.UpdateUserLogoutLogFunc(ctx, userID, logID)
+}
+
+func (m *UserServiceMock) GetUserLogs(ctx context.Context, filter models.UserLogFilter, page, limit int) ([]models.UserLog, int, int, error) {
+	m.record("GetUserLogs")
+	if m.GetUserLogsFunc == nil {
+		panic("UserServiceMock.GetUserLogs called but GetUserLogsFunc is not set")
+	}
+	return m.GetUserLogsFunc(ctx, filter, page, limit)
+}
+
+func (m *UserServiceMock) GetUserLogsAfter(ctx context.Context, filter models.UserLogFilter, after *models.Cursor, limit int) ([]models.UserLog, *models.Cursor, error) {
+	m.record("GetUserLogsAfter")
+	if m.GetUserLogsAfterFunc == nil {
+		panic("UserServiceMock.GetUserLogsAfter called but GetUserLogsAfterFunc is not set")
+	}
+	return m.GetUserLogsAfterFunc(ctx, filter, after, limit)
+}
+
+func (m *UserServiceMock) ExportUserLogs(ctx context.Context, filter models.UserLogFilter, fn func(*models.UserLog) error) error {
+	m.record("ExportUserLogs")
+	if m.ExportUserLogsFunc == nil {
+		panic("UserServiceMock.ExportUserLogs called but ExportUserLogsFunc is not set")
+	}
+	return m.ExportUserLogsFunc(ctx, filter, fn)
+}
+
+func (m *UserServiceMock) ExportUsers(ctx context.Context, search string, roleID string, tag string, fn func(*models.User) error) error {
+	m.record("ExportUsers")
+	if m.ExportUsersFunc == nil {
+		panic("UserServiceMock.ExportUsers called but ExportUsersFunc is not set")
+	}
+	return m.ExportUsersFunc(ctx, search, roleID, tag, fn)
+}
+
+func (m *UserServiceMock) GetUserProfile(ctx context.Context, id string) (*models.UserProfile, error) {
+	m.record("GetUserProfile")
+	if m.GetUserProfileFunc == nil {
+		panic("UserServiceMock.GetUserProfile called but GetUserProfileFunc is not set")
+	}
+	return m.GetUserProfileFunc(ctx, id)
+}
+
+func (m *UserServiceMock) UpdateUserPreferences(ctx context.Context, id string, preferences json.RawMessage) error {
+	m.record("UpdateUserPreferences")
+	if m.UpdateUserPreferencesFunc == nil {
+		panic("UserServiceMock.UpdateUserPreferences called but UpdateUserPreferencesFunc is not set")
+	}
+	return m.UpdateUserPreferencesFunc(ctx, id, preferences)
+}
+
+func (m *UserServiceMock) GetUserTags(ctx context.Context) ([]models.UserTagCount, error) {
+	m.record("GetUserTags")
+	if m.GetUserTagsFunc == nil {
+		panic("UserServiceMock.GetUserTags called but GetUserTagsFunc is not set")
+	}
+	return m.GetUserTagsFunc(ctx)
+}
+
+func (m *UserServiceMock) SetUserTags(ctx context.Context, id string, tags []string) ([]string, error) {
+	m.record("SetUserTags")
+	if m.SetUserTagsFunc == nil {
+		panic("UserServiceMock.SetUserTags called but SetUserTagsFunc is not set")
+	}
+	return m.SetUserTagsFunc(ctx, id, tags)
+}
+
+func (m *UserServiceMock) TagUsers(ctx context.Context, userIDs []string, add, remove []string) error {
+	m.record("TagUsers")
+	if m.TagUsersFunc == nil {
+		panic("UserServiceMock.TagUsers called but TagUsersFunc is not set")
+	}
+	return m.TagUsersFunc(ctx, userIDs, add, remove)
+}
+
+// RoleServiceMock implements services.RoleServiceInterface with the functions set in its
+// fields, like UserServiceMock.
+type RoleServiceMock struct {
+	calls
+	GetAllRolesFunc           func(ctx context.Context, search string, page, limit int) ([]models.Role, int, int, error)
+	GetRoleByIDFunc           func(ctx context.Context, id string) (*models.Role, error)
+	GetRoleByNameFunc         func(ctx context.Context, name string) (*models.Role, error)
+	CreateRoleFunc            func(ctx context.Context, role *models.Role) error
+	UpdateRoleFunc            func(ctx context.Context, role *models.Role) error
+	DeleteRoleFunc            func(ctx context.Context, id string) error
+	GetPermissionMatrixFunc   func(ctx context.Context) (*models.PermissionMatrix, error)
+	UpdatePermissionsFunc     func(ctx context.Context, changes []models.PermissionChange) error
+	HasPermissionFunc         func(ctx context.Context, roleNames []string, permission string) (bool, error)
+	GetPermissionsOfRolesFunc func(ctx context.Context, roleNames []string) ([]string, error)
+	ExportRolesFunc           func(ctx context.Context) ([]models.RoleDefinition, error)
+	ImportRolesFunc           func(ctx context.Context, roles []models.RoleDefinition, onConflict string) ([]models.RoleImportResult, error)
+}
+
+func (m *RoleServiceMock) GetAllRoles(ctx context.Context, search string, page, limit int) ([]models.Role, int, int, error) {
+	m.record("GetAllRoles")
+	if m.GetAllRolesFunc == nil {
+		panic("RoleServiceMock.GetAllRoles called but GetAllRolesFunc is not set")
+	}
+	return m.GetAllRolesFunc(ctx, search, page, limit)
+}
+
+func (m *RoleServiceMock) GetRoleByID(ctx context.Context, id string) (*models.Role, error) {
+	m.record("GetRoleByID")
+	if m.GetRoleByIDFunc == nil {
+		panic("RoleServiceMock.GetRoleByID called but GetRoleByIDFunc is not set")
+	}
+	return m.GetRoleByIDFunc(ctx, id)
+}
+
+func (m *RoleServiceMock) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	m.record("GetRoleByName")
+	if m.GetRoleByNameFunc == nil {
+		panic("RoleServiceMock.GetRoleByName called but GetRoleByNameFunc is not set")
+	}
+	return m.GetRoleByNameFunc(ctx, name)
+}
+
+func (m *RoleServiceMock) CreateRole(ctx context.Context, role *models.Role) error {
+	m.record("CreateRole")
+	if m.CreateRoleFunc == nil {
+		panic("RoleServiceMock.CreateRole called but CreateRoleFunc is not set")
+	}
+	return m.CreateRoleFunc(ctx, role)
+}
+
+func (m *RoleServiceMock) UpdateRole(ctx context.Context, role *models.Role) error {
+	m.record("UpdateRole")
+	if m.UpdateRoleFunc == nil {
+		panic("RoleServiceMock.UpdateRole called but UpdateRoleFunc is not set")
+	}
+	return m.UpdateRoleFunc(ctx, role)
+}
+
+func (m *RoleServiceMock) DeleteRole(ctx context.Context, id string) error {
+	m.record("DeleteRole")
+	if m.DeleteRoleFunc == nil {
+		panic("RoleServiceMock.DeleteRole called but DeleteRoleFunc is not set")
+	}
+	return m.DeleteRoleFunc(ctx, id)
+}
+
+func (m *RoleServiceMock) GetPermissionMatrix(ctx context.Context) (*models.PermissionMatrix, error) {
+	m.record("GetPermissionMatrix")
+	if m.GetPermissionMatrixFunc == nil {
+		panic("RoleServiceMock.GetPermissionMatrix called but GetPermissionMatrixFunc is not set")
+	}
+	return m.GetPermissionMatrixFunc(ctx)
+}
+
+func (m *RoleServiceMock) UpdatePermissions(ctx context.Context, changes []models.PermissionChange) error {
+	m.record("UpdatePermissions")
+	if m.UpdatePermissionsFunc == nil {
+		panic("RoleServiceMock.UpdatePermissions called but UpdatePermissionsFunc is not set")
+	}
+	return m.UpdatePermissionsFunc(ctx, changes)
+}
+
+func (m *RoleServiceMock) HasPermission(ctx context.Context, roleNames []string, permission string) (bool, error) {
+	m.record("HasPermission")
+	if m.HasPermissionFunc == nil {
+		panic("RoleServiceMock.HasPermission called but HasPermissionFunc is not set")
+	}
+	return m.HasPermissionFunc(ctx, roleNames, permission)
+}
+
+func (m *RoleServiceMock) GetPermissionsOfRoles(ctx context.Context, roleNames []string) ([]string, error) {
+	m.record("GetPermissionsOfRoles")
+	if m.GetPermissionsOfRolesFunc == nil {
+		panic("RoleServiceMock.GetPermissionsOfRoles called but GetPermissionsOfRolesFunc is not set")
+	}
+	return m.GetPermissionsOfRolesFunc(ctx, roleNames)
+}
+
+func (m *RoleServiceMock) ExportRoles(ctx context.Context) ([]models.RoleDefinition, error) {
+	m.record("ExportRoles")
+	if m.ExportRolesFunc == nil {
+		panic("RoleServiceMock.ExportRoles called but ExportRolesFunc is not set")
+	}
+	return m.ExportRolesFunc(ctx)
+}
+
+func (m *RoleServiceMock) ImportRoles(ctx context.Context, roles []models.RoleDefinition, onConflict string) ([]models.RoleImportResult, error) {
+	m.record("ImportRoles")
+	if m.ImportRolesFunc == nil {
+		panic("RoleServiceMock.ImportRoles called but ImportRolesFunc is not set")
+	}
+	return m.ImportRolesFunc(ctx, roles, onConflict)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/anpsniper/anpbayu-be/config"
+)
+
+// listen serves app on the configured port. It serves HTTPS with the configured certificate
+// files, or with certificates obtained from Let's Encrypt in autocert mode, and plain HTTP
+// when neither is configured (e.g., behind a reverse proxy that terminates TLS).
+func listen(app *fiber.App, cfg *config.Config) error {
+	addr := ":" + cfg.AppPort
+
+	if len(cfg.TLSAutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+
+		// Answer HTTP-01 challenges and redirect everything else to HTTPS
+		go func() {
+			slog.Info("ACME challenge and redirect server starting", "port", cfg.TLSHTTPPort)
+			if err := http.ListenAndServe(":"+cfg.TLSHTTPPort, manager.HTTPHandler(nil)); err != nil {
+				slog.Error("ACME challenge server stopped", "error", err)
+			}
+		}()
+
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		ln, err := tls.Listen(app.Config().Network, addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+		slog.Info("GoFiber API server starting with HTTPS from Let's Encrypt", "port", cfg.AppPort, "domains", cfg.TLSAutocertDomains)
+		return app.Listener(ln)
+	}
+
+	if cfg.TLSCertFile != "" {
+		slog.Info("GoFiber API server starting with HTTPS", "port", cfg.AppPort)
+		return app.ListenTLS(addr, cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+
+	slog.Info("GoFiber API server starting", "port", cfg.AppPort)
+	return app.Listen(addr)
+}
@@ -1,8 +1,9 @@
 package utils
 
 import (
-	"log"
-	"time"
+	"log/slog"
+	"strings"
+	"unicode"
 )
 
 // ExampleHelperFunction is a placeholder for a general utility function.
@@ -12,7 +13,24 @@ import (
 // - Common validation logic (if not handled by specific libraries like Zod on frontend)
 // - Error handling utilities
 func ExampleHelperFunction(message string) {
-	log.Printf("Helper function called at %s with message: %s", time.Now().Format(time.RFC3339), message)
+	slog.Info("Helper function called", "message", message)
+}
+
+// Slugify converts a display name into a lowercase, hyphen-separated identifier
+// that is safe to use in URLs and query parameters (e.g., "Go Tips" -> "go-tips").
+func Slugify(name string) string {
+	var b strings.Builder
+	lastHyphen := true // Avoid a leading hyphen
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			lastHyphen = false
+		} else if !lastHyphen {
+			b.WriteRune('-')
+			lastHyphen = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
 }
 
 // GenerateRandomString can be another helper function for generating random strings,
@@ -0,0 +1,194 @@
+// Package webhooks sends the outgoing webhooks of the API: HTTP POST requests notifying the
+// endpoints registered by admins of events such as a user being created or an order being paid.
+// Every request is signed with the secret of its endpoint, so receivers can verify it came
+// from the API and wasn't altered.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Event types endpoints can subscribe to.
+const (
+	EventUserCreated = "user.created" // A user was created; the data is the user
+	EventOrderPaid   = "order.paid"   // The payment of an order succeeded; the data is the payment
+)
+
+// Events lists the event types endpoints can subscribe to.
+var Events = []string{EventUserCreated, EventOrderPaid}
+
+// IsEvent reports whether event is one of Events.
+func IsEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers of webhook requests.
+const (
+	HeaderEvent     = "X-Webhook-Event"     // Event type of the delivery
+	HeaderDelivery  = "X-Webhook-Delivery"  // ID of the delivery; the same for all of its attempts
+	HeaderSignature = "X-Webhook-Signature" // See Sign
+)
+
+// maxResponseBody bounds how much of a receiver's response is read, so the connection can be
+// reused. Responses aren't kept: the delivery history only records their status.
+const maxResponseBody = 1024
+
+// ErrForbiddenAddress is returned for endpoints on loopback, private, link-local or other
+// internal addresses, such as the metadata service of cloud providers. Admins of any tenant
+// register endpoints, which mustn't let them reach the API's own network.
+var ErrForbiddenAddress = errors.New("webhook endpoint address is not public")
+
+// nonPublicPrefixes are the ranges isPublic refuses besides loopback, link-local, multicast and
+// private addresses: "this network" and the shared address space of carrier-grade NAT, where
+// some cloud providers serve their metadata.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// isPublic reports whether addr may be the address of a webhook endpoint.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL returns ErrForbiddenAddress for endpoint URLs whose host is obviously internal: a
+// non-public IP address or localhost. Other host names are checked once resolved, by the
+// Sender, as they may resolve to other addresses by then.
+func CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublic(addr) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// denyInternal refuses connections to non-public addresses. As the Control of a net.Dialer it
+// sees the address a host name resolved to, just before connecting.
+func denyInternal(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewSecret returns a random signing secret for a new endpoint.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of body sent at timestamp (Unix seconds), in the format
+// "t=<timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">", as Stripe signs its webhooks.
+// Receivers should recompute it and reject old timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Request is one attempt to deliver an event to an endpoint.
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Payload    []byte // JSON body
+}
+
+// Sender posts webhook requests.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a Sender whose requests time out after timeout. Unless allowPrivate is set,
+// for receivers running next to the API in development, it refuses to connect to non-public
+// addresses. Redirects aren't followed, as they could lead anywhere: they fail like any other
+// status but 2xx.
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = denyInternal
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would connect to the endpoints itself, past denyInternal
+	transport.DialContext = dialer.DialContext
+	return &Sender{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send posts req and returns the status of the response, or 0 if there was none. Any status
+// other than 2xx is an error, so the delivery is retried.
+func (s *Sender) Send(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "anpbayu-webhooks/1.0")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, time.Now().Unix(), req.Payload))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody)) // Lets the connection be reused
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}